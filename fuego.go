@@ -0,0 +1,161 @@
+// Package fuego runs API test scenarios from Go code, e.g. inside standard go tests:
+//
+//	func TestOrdersAPI(t *testing.T) {
+//		report, err := fuego.Run(context.Background(), fuego.Options{
+//			Paths:       []string{"testdata/orders.yaml"},
+//			Environment: "staging",
+//		})
+//		if err != nil {
+//			t.Fatal(err)
+//		}
+//		for _, failure := range report.Failures() {
+//			t.Error(failure)
+//		}
+//	}
+package fuego
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/execution"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+)
+
+// Report is the result of a run
+type Report = reporting.Report
+
+// Scenario is a parsed test scenario
+type Scenario = scenario.Scenario
+
+// Options configures a programmatic run
+type Options struct {
+	// Paths are scenario files or directories to load
+	Paths []string
+	// Scenarios are already parsed or built scenarios, run after the ones loaded from Paths
+	Scenarios []*Scenario
+	// Labels keeps only scenarios whose metadata labels match every selector, e.g. "component=payments"
+	Labels []string
+
+	// Config is used as is when set; otherwise ConfigFile is loaded (defaults and FUEGO_* overrides apply)
+	Config     *config.Config
+	ConfigFile string
+	// Environment selects an environment from the config
+	Environment string
+
+	// Format and OutputFile write a report like the CLI does; by default nothing is printed
+	Format     string
+	OutputFile string
+	Verbose    bool
+	// IncludeBody keeps response bodies in step results; they are dropped by default
+	IncludeBody bool
+	// Reports are written in addition to Format, e.g. {Format: "junit", File: "junit.xml"}
+	Reports []reporting.ReportOutput
+	// ReportTemplate is the Go template file used by the "template" format
+	ReportTemplate string
+
+	SnapshotDir     string
+	UpdateSnapshots bool
+	Repeat          int
+	StopOnFailure   bool
+	// ArtifactsDir keeps the response body of every failing step
+	ArtifactsDir string
+	// Seed reproduces the random choices of an earlier run (see Report.Seed)
+	Seed int64
+	// OnEvent receives run, scenario and step events as they happen, e.g. for live output; it is
+	// called concurrently when test groups run in parallel
+	OnEvent reporting.EventHandler
+	// FailOn overrides global.fail_on: failures below this metadata severity are marked as
+	// warnings (see Report.SeverityGate)
+	FailOn string
+}
+
+// Run executes scenarios and returns the report. A non-nil error means the run itself could not
+// complete (invalid scenario, config or report output, cancelled context); failing scenarios are
+// reported through the Report.
+func Run(ctx context.Context, options Options) (*Report, error) {
+	cfg := options.Config
+	if cfg == nil {
+		loaded, err := config.LoadConfig(options.ConfigFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+		cfg = loaded
+	}
+
+	if options.Environment != "" {
+		if _, exists := cfg.GetEnvironment(options.Environment); !exists {
+			return nil, fmt.Errorf("environment %s is not defined in config", options.Environment)
+		}
+		cfg = cfg.MergeEnvironment(options.Environment)
+	}
+
+	failOn := cfg.Global.FailOn
+	if options.FailOn != "" {
+		failOn = options.FailOn
+	}
+	if failOn != "" && !reporting.IsSeverity(failOn) {
+		return nil, fmt.Errorf("invalid fail-on severity %q", failOn)
+	}
+
+	var scenarios []*Scenario
+	if len(options.Paths) > 0 {
+		loadOptions := scenario.LoadOptions{AgeKeyFile: os.ExpandEnv(cfg.Encryption.AgeKeyFile)}
+		loaded, err := scenario.LoadScenariosWithOptions(loadOptions, options.Paths...)
+		if err != nil {
+			return nil, err
+		}
+		scenarios = append(scenarios, loaded...)
+	}
+	scenarios = append(scenarios, options.Scenarios...)
+
+	var selectors []scenario.LabelSelector
+	for _, spec := range options.Labels {
+		selector, err := scenario.ParseLabelSelector(spec)
+		if err != nil {
+			return nil, err
+		}
+		selectors = append(selectors, selector)
+	}
+	scenarios = scenario.FilterByLabels(scenarios, selectors)
+	if len(scenarios) == 0 {
+		return nil, fmt.Errorf("no scenarios to run")
+	}
+
+	format := options.Format
+	if format == "" {
+		format = "none"
+	}
+
+	reporter := reporting.NewReporter(reporting.ReportConfig{
+		Format:      format,
+		OutputFile:  options.OutputFile,
+		Verbose:     options.Verbose,
+		IncludeBody: options.IncludeBody,
+		Outputs:     options.Reports,
+		Template:    options.ReportTemplate,
+		FailOn:      failOn,
+	})
+	if options.OnEvent != nil {
+		reporter.Subscribe(options.OnEvent)
+	}
+	engine := execution.NewEngineWithOptions(cfg, reporter, execution.Options{
+		SnapshotDir:     options.SnapshotDir,
+		UpdateSnapshots: options.UpdateSnapshots,
+		Repeat:          options.Repeat,
+		StopOnFailure:   options.StopOnFailure,
+		ArtifactsDir:    options.ArtifactsDir,
+		Seed:            options.Seed,
+	})
+
+	err := engine.ExecuteScenariosContext(ctx, scenarios)
+	return reporter.GetReport(), err
+}
+
+// LoadScenarios parses scenario files and directories without running them
+func LoadScenarios(paths ...string) ([]*Scenario, error) {
+	return scenario.LoadScenarios(paths...)
+}
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/bench"
+	"github.com/nulln0ne/fuego/pkg/execution"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/spf13/cobra"
+)
+
+var benchCmd = &cobra.Command{
+	Use:   "bench [scenario file or directory]",
+	Short: "Compare step latencies with a stored baseline",
+	Long: `Run the scenarios --runs times and compare the latency distribution of every
+step with a baseline. A step regresses when its median grew by more than
+--threshold and the difference is statistically significant (Mann-Whitney U
+test, p below --alpha); the command then exits non-zero, making it usable as a
+per-PR performance gate.
+
+Without a baseline file (or with --update-baseline) the run is stored as the
+new baseline instead.
+
+Examples:
+  fuego bench --baseline baseline.json test.yaml
+  fuego bench --baseline baseline.json --runs 50 --threshold 0.05 tests/
+  fuego bench --baseline baseline.json --update-baseline tests/`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runBench,
+}
+
+var (
+	benchBaseline       string
+	benchRuns           int
+	benchThreshold      float64
+	benchAlpha          float64
+	benchUpdateBaseline bool
+	benchEnvironment    string
+	benchOutputFile     string
+)
+
+func init() {
+	rootCmd.AddCommand(benchCmd)
+
+	benchCmd.Flags().StringVar(&benchBaseline, "baseline", "baseline.json", "baseline file to compare with (created when missing)")
+	benchCmd.Flags().IntVarP(&benchRuns, "runs", "n", 20, "number of times to run every scenario")
+	benchCmd.Flags().Float64Var(&benchThreshold, "threshold", 0.1, "median latency growth counted as a regression (0.1 = 10%)")
+	benchCmd.Flags().Float64Var(&benchAlpha, "alpha", 0.05, "significance level a regression must reach")
+	benchCmd.Flags().BoolVar(&benchUpdateBaseline, "update-baseline", false, "store this run as the baseline instead of comparing")
+	benchCmd.Flags().StringVarP(&benchEnvironment, "env", "e", "", "environment to use for variable substitution")
+	benchCmd.Flags().StringVarP(&benchOutputFile, "output", "o", "", "write the comparison as JSON to this file")
+
+	registerCompletions(benchCmd)
+}
+
+func runBench(cmd *cobra.Command, args []string) error {
+	if benchRuns < 2 {
+		return fmt.Errorf("--runs must be at least 2 to compare distributions")
+	}
+
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if benchEnvironment != "" {
+		cfg = cfg.MergeEnvironment(benchEnvironment)
+	}
+
+	scenarios, err := loadScenarios(args)
+	if err != nil {
+		return err
+	}
+
+	baseline, err := bench.LoadBaseline(benchBaseline)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	fmt.Printf("Running %d scenario(s) %d times...\n", len(scenarios), benchRuns)
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "none"})
+	engine := execution.NewEngineWithOptions(cfg, reporter, execution.Options{Repeat: benchRuns})
+	if err := engine.ExecuteScenarios(scenarios); err != nil {
+		return err
+	}
+	report := reporter.GetReport()
+	if report.Summary.Failed > 0 {
+		return fmt.Errorf("%d benchmark run(s) failed; fix failing scenarios before comparing latencies", report.Summary.Failed)
+	}
+
+	if baseline == nil || benchUpdateBaseline {
+		if err := bench.NewBaseline(report, benchRuns).Save(benchBaseline); err != nil {
+			return err
+		}
+		fmt.Printf("Baseline written to %s\n", benchBaseline)
+		return nil
+	}
+
+	comparisons := bench.Compare(*baseline, bench.Collect(report), benchThreshold, benchAlpha)
+
+	fmt.Printf("\n%-48s %10s %10s %8s %8s  %s\n", "STEP", "BASE P50", "P50", "CHANGE", "P", "VERDICT")
+	for _, c := range comparisons {
+		fmt.Printf("%-48s %10v %10v %+7.1f%% %8.3f  %s\n", c.Step,
+			c.Baseline.P50.Round(10*time.Microsecond), c.Current.P50.Round(10*time.Microsecond), c.Change*100, c.PValue, c.Verdict)
+	}
+
+	if benchOutputFile != "" {
+		data, err := json.MarshalIndent(comparisons, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal comparison: %w", err)
+		}
+		if err := os.WriteFile(benchOutputFile, data, 0644); err != nil {
+			return fmt.Errorf("failed to write comparison: %w", err)
+		}
+	}
+
+	if regressions := bench.Regressions(comparisons); len(regressions) > 0 {
+		return fmt.Errorf("%d step(s) regressed by more than %.0f%% (p < %g)", len(regressions), benchThreshold*100, benchAlpha)
+	}
+	fmt.Println("\nNo significant regressions")
+	return nil
+}
@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/spf13/cobra"
+)
+
+// Shell completion comes from cobra's completion command (fuego completion bash|zsh|fish|powershell);
+// these functions add the dynamic values.
+
+// completeScenarioFiles offers YAML and JSON files and directories for scenario arguments
+func completeScenarioFiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	return []string{"yaml", "yml", "json"}, cobra.ShellCompDirectiveFilterFileExt
+}
+
+// completeEnvironments offers the environment names of the config file
+func completeEnvironments(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	// Completion does not run the command, so the config has not been read yet
+	initConfig()
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	names := make([]string, 0, len(cfg.Env))
+	for name := range cfg.Env {
+		if strings.HasPrefix(name, toComplete) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeLabels offers the key=value metadata labels of the scenarios given as arguments, or of
+// the current directory
+func completeLabels(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+	// Files that do not load, like a .fuego.yaml config next to the scenarios, are skipped
+	var files []string
+	for _, arg := range args {
+		if info, err := os.Stat(arg); err == nil && info.IsDir() {
+			for _, pattern := range []string{"*.yaml", "*.yml", "*.json"} {
+				matches, _ := filepath.Glob(filepath.Join(arg, pattern))
+				files = append(files, matches...)
+			}
+		} else {
+			files = append(files, arg)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var labels []string
+	for _, file := range files {
+		sc, err := scenario.LoadScenario(file)
+		if err != nil {
+			continue
+		}
+		for key, value := range sc.Metadata.Labels {
+			label := key + "=" + value
+			if !seen[label] && strings.HasPrefix(label, toComplete) {
+				seen[label] = true
+				labels = append(labels, label)
+			}
+		}
+	}
+	sort.Strings(labels)
+	return labels, cobra.ShellCompDirectiveNoFileComp
+}
+
+// registerCompletions sets the completion of a command's scenario arguments and of the flags it
+// has among env, label and format
+func registerCompletions(cmd *cobra.Command, formats ...string) {
+	cmd.ValidArgsFunction = completeScenarioFiles
+	completions := map[string]cobra.CompletionFunc{
+		"env":   completeEnvironments,
+		"env-a": completeEnvironments,
+		"env-b": completeEnvironments,
+		"label": completeLabels,
+	}
+	if len(formats) > 0 {
+		completions["format"] = cobra.FixedCompletions(formats, cobra.ShellCompDirectiveNoFileComp)
+	}
+	for name, complete := range completions {
+		if cmd.Flags().Lookup(name) != nil {
+			cobra.CheckErr(cmd.RegisterFlagCompletionFunc(name, complete))
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nulln0ne/fuego/pkg/openapi"
+	"github.com/spf13/cobra"
+)
+
+var contractCmd = &cobra.Command{
+	Use:   "contract",
+	Short: "Compare scenarios with an API contract",
+}
+
+var contractCheckCmd = &cobra.Command{
+	Use:   "check [scenario files or directories...]",
+	Short: "Report where scenarios and an OpenAPI spec disagree",
+	Long: `Cross-reference the HTTP steps of scenarios with an OpenAPI spec without
+running them, and fail when they diverge:
+
+  unknown-path         the step URL matches no path of the spec
+  unknown-method       the path exists but not with the step method
+  unknown-parameter    a query parameter the operation does not declare
+  undocumented-status  a status the step expects that the operation does
+                       not document, exactly, as a range (4XX) or default
+
+Templated path segments such as {{order_id}} match any path parameter, and a
+leading {{base_url}} is ignored, as is the base path of the spec servers.
+
+Examples:
+  fuego contract check --spec openapi.yaml tests/
+  fuego contract check --spec openapi.yaml orders.yaml payments.yaml`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runContractCheck,
+}
+
+var contractSpec string
+
+func init() {
+	rootCmd.AddCommand(contractCmd)
+	contractCmd.AddCommand(contractCheckCmd)
+
+	contractCheckCmd.Flags().StringVar(&contractSpec, "spec", "", "OpenAPI spec to check the scenarios against")
+	_ = contractCheckCmd.MarkFlagRequired("spec")
+
+	registerCompletions(contractCheckCmd)
+}
+
+func runContractCheck(cmd *cobra.Command, args []string) error {
+	spec, err := openapi.Load(contractSpec)
+	if err != nil {
+		return err
+	}
+	scenarios, err := loadScenarios(args)
+	if err != nil {
+		return fmt.Errorf("failed to load scenarios: %w", err)
+	}
+
+	drifts := openapi.CheckContract(spec, scenarios)
+	sort.SliceStable(drifts, func(i, j int) bool {
+		if drifts[i].File != drifts[j].File {
+			return drifts[i].File < drifts[j].File
+		}
+		return drifts[i].Line < drifts[j].Line
+	})
+	for _, drift := range drifts {
+		fmt.Printf("%s [%s]\n", drift, drift.Kind)
+	}
+
+	if len(drifts) > 0 {
+		return fmt.Errorf("%d difference(s) between the scenarios and %s", len(drifts), contractSpec)
+	}
+	fmt.Printf("%d scenario(s) match %s\n", len(scenarios), contractSpec)
+	return nil
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/openapi"
+)
+
+// newCoverage counts requests against the spec at path, or per scenario URL when path is "-"
+func newCoverage(path string) (*openapi.Coverage, error) {
+	if path == "" || path == "-" {
+		return openapi.NewCoverage(nil), nil
+	}
+	spec, err := openapi.Load(path)
+	if err != nil {
+		return nil, fmt.Errorf("coverage: %w", err)
+	}
+	return openapi.NewCoverage(spec), nil
+}
+
+// reportCoverage prints the coverage of a run and writes it as JSON to out when set
+func reportCoverage(report openapi.CoverageReport, out string) error {
+	if report.Spec {
+		fmt.Printf("\nAPI coverage: %d/%d operations (%.1f%%)\n", report.Covered, report.Total, report.Percent)
+	} else {
+		fmt.Printf("\nAPI coverage: %d endpoint(s) requested\n", report.Covered)
+	}
+	for _, operation := range report.Operations {
+		if operation.Requests == 0 {
+			continue
+		}
+		statuses := make([]string, len(operation.Statuses))
+		for i, status := range operation.Statuses {
+			statuses[i] = fmt.Sprint(status)
+		}
+		fmt.Printf("  %-7s %s  %d request(s), status %s\n", operation.Method, operation.Path, operation.Requests, strings.Join(statuses, ", "))
+	}
+	if len(report.Untested) > 0 {
+		fmt.Printf("Untested operations:\n")
+		for _, name := range report.Untested {
+			fmt.Printf("  %s\n", name)
+		}
+	}
+	if len(report.Unmatched) > 0 {
+		fmt.Printf("Requests to paths the spec does not have:\n")
+		for _, request := range report.Unmatched {
+			fmt.Printf("  %s\n", request)
+		}
+	}
+
+	if out == "" {
+		return nil
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal coverage report: %w", err)
+	}
+	if err := os.WriteFile(out, data, 0644); err != nil {
+		return fmt.Errorf("failed to write coverage report: %w", err)
+	}
+	return nil
+}
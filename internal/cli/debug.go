@@ -0,0 +1,281 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/execution"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/nulln0ne/fuego/pkg/variables"
+	"github.com/spf13/cobra"
+)
+
+var debugCmd = &cobra.Command{
+	Use:   "debug [scenario file]",
+	Short: "Step through a scenario interactively",
+	Long: `Execute a scenario step by step. After each step (or only after failures,
+once you "continue") an interactive prompt lets you inspect variables and the
+last response, edit the scenario file and re-run the step, or carry on.
+
+Commands:
+  n, next            run the next step and pause again
+  c, continue        run until the next failing step
+  r, rerun           reload the scenario file and run the current step again
+  v, vars [name]     show all variables, or one variable
+  set name=value     set a variable for the following steps
+  p, response        show the last response
+  q, quit            stop the run
+  h, help            show this help
+
+Examples:
+  fuego debug test.yaml
+  fuego debug --env staging test.yaml`,
+	Args: cobra.ExactArgs(1),
+	RunE: runDebug,
+}
+
+var debugEnvironment string
+
+func init() {
+	rootCmd.AddCommand(debugCmd)
+
+	debugCmd.Flags().StringVarP(&debugEnvironment, "env", "e", "", "environment to use for variable substitution")
+
+	registerCompletions(debugCmd)
+}
+
+func runDebug(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if debugEnvironment != "" {
+		cfg = cfg.MergeEnvironment(debugEnvironment)
+	}
+
+	sc, err := scenario.LoadScenario(args[0])
+	if err != nil {
+		return err
+	}
+
+	debugger := &replDebugger{
+		path:     args[0],
+		in:       bufio.NewReader(cmd.InOrStdin()),
+		out:      cmd.OutOrStdout(),
+		stepping: true,
+	}
+	fmt.Fprintf(debugger.out, "Debugging %s (%s). Type \"help\" for commands.\n", sc.Name, args[0])
+
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "console"})
+	engine := execution.NewEngineWithOptions(cfg, reporter, execution.Options{Debugger: debugger})
+
+	return engine.ExecuteScenarios([]*scenario.Scenario{sc})
+}
+
+// replDebugger pauses after steps and reads commands from an interactive prompt
+type replDebugger struct {
+	path string
+	in   *bufio.Reader
+	out  io.Writer
+
+	// stepping pauses after every step; otherwise only after failures
+	stepping bool
+}
+
+func (d *replDebugger) AfterStep(step *scenario.Step, result reporting.StepResult, varContext *variables.Context) (execution.DebugAction, *scenario.Step) {
+	d.printResult(result)
+
+	if !d.stepping && result.Status != "failed" {
+		return execution.DebugContinue, nil
+	}
+
+	for {
+		fmt.Fprint(d.out, "(fuego) ")
+		line, err := d.in.ReadString('\n')
+		if err != nil && line == "" {
+			// Input closed: finish the run without pausing again
+			d.stepping = false
+			return execution.DebugContinue, nil
+		}
+
+		command, argument, _ := strings.Cut(strings.TrimSpace(line), " ")
+		argument = strings.TrimSpace(argument)
+
+		switch command {
+		case "", "n", "next":
+			d.stepping = true
+			return execution.DebugContinue, nil
+		case "c", "continue":
+			d.stepping = false
+			return execution.DebugContinue, nil
+		case "r", "rerun":
+			replacement, err := d.reloadStep(step.Name)
+			if err != nil {
+				fmt.Fprintf(d.out, "Error: %v\n", err)
+				continue
+			}
+			return execution.DebugRerun, replacement
+		case "v", "vars":
+			d.printVariables(varContext, argument)
+		case "set":
+			name, value, ok := strings.Cut(argument, "=")
+			if !ok {
+				fmt.Fprintln(d.out, "usage: set name=value")
+				continue
+			}
+			varContext.SetLocal(strings.TrimSpace(name), parseDebugValue(strings.TrimSpace(value)))
+		case "p", "response":
+			d.printResponse(result.Response)
+		case "q", "quit":
+			return execution.DebugAbort, nil
+		case "h", "help":
+			fmt.Fprintln(d.out, "n(ext), c(ontinue), r(erun), v(ars) [name], set name=value, p/response, q(uit)")
+		default:
+			fmt.Fprintf(d.out, "unknown command %q, type \"help\"\n", command)
+		}
+	}
+}
+
+func (d *replDebugger) printResult(result reporting.StepResult) {
+	marker := "✓"
+	switch result.Status {
+	case "failed":
+		marker = "✗"
+	case "skipped":
+		marker = "⊖"
+	}
+	fmt.Fprintf(d.out, "%s %s (%v)\n", marker, result.Step.Name, result.Duration)
+
+	if result.Error != "" {
+		fmt.Fprintf(d.out, "  Error: %s\n", result.Error)
+	}
+	for _, assertion := range result.Assertions {
+		if !assertion.Passed {
+			fmt.Fprintf(d.out, "  ✗ %s\n", assertion.Message)
+		}
+	}
+}
+
+func (d *replDebugger) printVariables(varContext *variables.Context, name string) {
+	if name != "" {
+		value, exists := varContext.GetNested(name)
+		if !exists {
+			fmt.Fprintf(d.out, "%s is not set\n", name)
+			return
+		}
+		fmt.Fprintf(d.out, "%s = %s\n", name, formatDebugValue(value))
+		return
+	}
+
+	all := varContext.GetAll()
+	names := make([]string, 0, len(all))
+	for k := range all {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		fmt.Fprintf(d.out, "%s = %s\n", k, formatDebugValue(all[k]))
+	}
+}
+
+func (d *replDebugger) printResponse(response interface{}) {
+	responseMap, ok := response.(map[string]interface{})
+	if !ok {
+		fmt.Fprintln(d.out, "no response")
+		return
+	}
+
+	fmt.Fprintf(d.out, "Status: %v\n", responseMap["status_code"])
+	if headers, ok := responseMap["headers"].(map[string][]string); ok {
+		names := make([]string, 0, len(headers))
+		for name := range headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(d.out, "%s: %s\n", name, strings.Join(headers[name], ", "))
+		}
+	}
+	switch body := responseMap["body"].(type) {
+	case map[string]interface{}, []interface{}:
+		fmt.Fprintf(d.out, "\n%s\n", formatDebugValue(body))
+	default:
+		fmt.Fprintf(d.out, "\n%v\n", responseMap["body_text"])
+	}
+}
+
+var iterationSuffix = regexp.MustCompile(` \(data \d+\)$`)
+
+// reloadStep re-reads the scenario file and returns the current version of the named step
+func (d *replDebugger) reloadStep(name string) (*scenario.Step, error) {
+	sc, err := scenario.LoadScenario(d.path)
+	if err != nil {
+		return nil, err
+	}
+
+	baseName := iterationSuffix.ReplaceAllString(name, "")
+	for _, step := range allSteps(sc) {
+		switch step.Name {
+		case name:
+			return step, nil
+		case baseName:
+			// Data-driven step iteration: keep the iteration name and bound data item
+			iteration := *step
+			iteration.Name = name
+			iteration.DataDriven = nil
+			return &iteration, nil
+		}
+	}
+
+	return nil, fmt.Errorf("step %q not found in %s", name, d.path)
+}
+
+func allSteps(sc *scenario.Scenario) []*scenario.Step {
+	var steps []*scenario.Step
+	addSteps := func(list []scenario.Step) {
+		for i := range list {
+			steps = append(steps, &list[i])
+		}
+	}
+
+	if sc.Before != nil {
+		addSteps(sc.Before.Steps)
+	}
+	addSteps(sc.Setup)
+	addSteps(sc.Steps)
+	for _, test := range sc.Tests {
+		addSteps(test.Steps)
+	}
+	addSteps(sc.Teardown)
+	if sc.After != nil {
+		addSteps(sc.After.Steps)
+	}
+
+	return steps
+}
+
+func formatDebugValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(data)
+}
+
+// parseDebugValue accepts JSON literals and falls back to a plain string
+func parseDebugValue(value string) interface{} {
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(value), &parsed); err == nil {
+		return parsed
+	}
+	return value
+}
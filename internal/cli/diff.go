@@ -0,0 +1,138 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nulln0ne/fuego/pkg/execution"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff [scenario file or directory]",
+	Short: "Compare GET responses across two environments",
+	Long: `Execute every GET step against two environments and report structural
+differences between the JSON responses. Only GET steps are sent, so neither
+environment is modified.
+
+Paths use json_path notation and may contain * wildcards for array items.
+
+Examples:
+  fuego diff --env-a staging --env-b production test.yaml
+  fuego diff --env-a staging --env-b production --ignore updated_at --ignore items.*.etag tests/`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runDiff,
+}
+
+var (
+	diffEnvA   string
+	diffEnvB   string
+	diffIgnore []string
+	diffFormat string
+)
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVar(&diffEnvA, "env-a", "", "first environment to compare")
+	diffCmd.Flags().StringVar(&diffEnvB, "env-b", "", "second environment to compare")
+	diffCmd.Flags().StringArrayVar(&diffIgnore, "ignore", nil, "JSON path to ignore when comparing (repeatable)")
+	diffCmd.Flags().StringVarP(&diffFormat, "format", "f", "console", "output format (console, json)")
+
+	_ = diffCmd.MarkFlagRequired("env-a")
+	_ = diffCmd.MarkFlagRequired("env-b")
+
+	registerCompletions(diffCmd, "console", "json")
+}
+
+func runDiff(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	for _, env := range []string{diffEnvA, diffEnvB} {
+		if _, exists := cfg.GetEnvironment(env); !exists {
+			return fmt.Errorf("environment %s is not defined in config", env)
+		}
+	}
+
+	scenarios, err := loadScenarios(args)
+	if err != nil {
+		return err
+	}
+
+	// MergeEnvironment writes into the global header and variable maps, so each side merges
+	// into a config of its own
+	cfgB, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	reporter := reporting.NewReporter(reporting.ReportConfig{})
+	engineA := execution.NewEngine(cfg.MergeEnvironment(diffEnvA), reporter)
+	engineB := execution.NewEngine(cfgB.MergeEnvironment(diffEnvB), reporter)
+
+	results := make(map[string][]execution.StepDiff)
+	mismatches := 0
+	for _, sc := range scenarios {
+		stepDiffs, err := execution.DiffScenario(engineA, engineB, sc, diffIgnore)
+		if err != nil {
+			return fmt.Errorf("failed to diff scenario %s: %w", sc.Name, err)
+		}
+		results[sc.Name] = stepDiffs
+
+		for _, stepDiff := range stepDiffs {
+			if !stepDiff.Equal() {
+				mismatches++
+			}
+		}
+
+		if diffFormat != "json" {
+			printScenarioDiff(sc.Name, stepDiffs)
+		}
+	}
+
+	if diffFormat == "json" {
+		jsonData, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal diff to JSON: %w", err)
+		}
+		fmt.Println(string(jsonData))
+	}
+
+	if mismatches > 0 {
+		return fmt.Errorf("%d step(s) differ between %s and %s", mismatches, diffEnvA, diffEnvB)
+	}
+
+	return nil
+}
+
+func printScenarioDiff(name string, stepDiffs []execution.StepDiff) {
+	fmt.Printf("\n=== %s (%s vs %s) ===\n", name, diffEnvA, diffEnvB)
+
+	for _, stepDiff := range stepDiffs {
+		stepName := stepDiff.Step
+		if stepDiff.Group != "" {
+			stepName = stepDiff.Group + " / " + stepDiff.Step
+		}
+
+		if stepDiff.Equal() {
+			fmt.Printf("  = %s\n", stepName)
+			continue
+		}
+
+		fmt.Printf("  ≠ %s\n", stepName)
+		if stepDiff.Error != "" {
+			fmt.Printf("    Error: %s\n", stepDiff.Error)
+			continue
+		}
+		if stepDiff.StatusA != stepDiff.StatusB {
+			fmt.Printf("    status: %d != %d\n", stepDiff.StatusA, stepDiff.StatusB)
+		}
+		for _, difference := range stepDiff.Differences {
+			fmt.Printf("    %s\n", difference)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/nulln0ne/fuego/pkg/manpage"
+	"github.com/nulln0ne/fuego/pkg/version"
+	"github.com/spf13/cobra"
+)
+
+var docsCmd = &cobra.Command{
+	Use:   "docs",
+	Short: "Generate documentation for the fuego commands",
+}
+
+var docsManCmd = &cobra.Command{
+	Use:   "man",
+	Short: "Write man pages for fuego and all its commands",
+	Long: `Write a man page per command (fuego.1, fuego-run.1, ...) from the same help
+text the commands print, for packaging or a local MANPATH.
+
+Examples:
+  fuego docs man                        Write the pages to ./man
+  fuego docs man -o /usr/local/share/man/man1
+  man -l man/fuego-run.1                Read a page without installing it`,
+	Args: cobra.NoArgs,
+	RunE: runDocsMan,
+}
+
+var docsManDir string
+
+func init() {
+	rootCmd.AddCommand(docsCmd)
+	docsCmd.AddCommand(docsManCmd)
+
+	docsManCmd.Flags().StringVarP(&docsManDir, "out", "o", "man", "directory to write the pages to")
+}
+
+func runDocsMan(cmd *cobra.Command, args []string) error {
+	header := manpage.Header{Source: "fuego " + version.Get().Version, Manual: "Fuego Manual"}
+	written, err := manpage.WriteTree(rootCmd, header, docsManDir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Wrote %d man pages to %s\n", len(written), docsManDir)
+	return nil
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/nulln0ne/fuego/pkg/execution"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export scenarios to other formats",
+}
+
+var exportCurlCmd = &cobra.Command{
+	Use:   "curl [scenario file or directory]",
+	Short: "Export HTTP steps as curl commands",
+	Long: `Render every HTTP step as a curl command line without executing it.
+
+Templates are interpolated with scenario, environment and config variables.
+Values that only exist at runtime (captures from earlier responses) are left
+as template placeholders.
+
+Examples:
+  fuego export curl test.yaml
+  fuego export curl --env staging -o requests.sh tests/`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runExportCurl,
+}
+
+var (
+	exportEnvironment string
+	exportOutputFile  string
+)
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+	exportCmd.AddCommand(exportCurlCmd)
+
+	exportCurlCmd.Flags().StringVarP(&exportEnvironment, "env", "e", "", "environment to use for variable substitution")
+	exportCurlCmd.Flags().StringVarP(&exportOutputFile, "output", "o", "", "output file path")
+
+	registerCompletions(exportCurlCmd)
+}
+
+func runExportCurl(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+
+	if exportEnvironment != "" {
+		cfg = cfg.MergeEnvironment(exportEnvironment)
+	}
+
+	scenarios, err := loadScenarios(args)
+	if err != nil {
+		return err
+	}
+
+	var out io.Writer = os.Stdout
+	if exportOutputFile != "" {
+		file, err := os.Create(exportOutputFile)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	engine := execution.NewEngine(cfg, reporting.NewReporter(reporting.ReportConfig{}))
+	for _, sc := range scenarios {
+		commands, err := engine.ExportCurl(sc)
+		if err != nil {
+			return fmt.Errorf("failed to export scenario %s: %w", sc.Name, err)
+		}
+
+		fmt.Fprintf(out, "# Scenario: %s\n", sc.Name)
+		for _, command := range commands {
+			if command.Group != "" {
+				fmt.Fprintf(out, "# %s / %s\n", command.Group, command.Step)
+			} else {
+				fmt.Fprintf(out, "# %s\n", command.Step)
+			}
+			fmt.Fprintln(out, command.Command)
+		}
+		fmt.Fprintln(out)
+	}
+
+	return nil
+}
@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/execution"
+	"github.com/nulln0ne/fuego/pkg/fuzz"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/spf13/cobra"
+)
+
+var fuzzCmd = &cobra.Command{
+	Use:   "fuzz [scenario file or directory]",
+	Short: "Mutate request fields and report responses that deviate from a baseline",
+	Long: `Run the steps leading up to --target once as written, as the baseline, and
+then once per payload of the built-in corpora with a --field of the target
+step replaced. Payloads whose response deviates from the baseline are
+reported: server errors (5xx), failed requests such as timeouts, and
+successful responses that fail a json_schema check the baseline passed.
+Client errors are the expected answer to bad input and are not reported.
+
+Fields are query.NAME, headers.NAME, path_params.NAME, or json.PATH for a
+dotted path into a JSON body. The corpora are numbers (boundary values),
+strings (empty, long and unusual strings) and injection (SQL, script,
+template, path and command injection payloads).
+
+Fuzzing sends hostile input; point it at test environments only.
+
+Examples:
+  fuego fuzz --target "Get order" --field query.id orders.yaml
+  fuego fuzz --target "Create order" --field json.items.0.quantity --corpus numbers orders.yaml
+  fuego fuzz --target "Search" --field query.q --field headers.Accept-Language -o findings.json tests/`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runFuzz,
+}
+
+var (
+	fuzzTarget      string
+	fuzzFields      []string
+	fuzzCorpora     []string
+	fuzzTimeout     time.Duration
+	fuzzEnvironment string
+	fuzzOutputFile  string
+)
+
+func init() {
+	rootCmd.AddCommand(fuzzCmd)
+
+	fuzzCmd.Flags().StringVar(&fuzzTarget, "target", "", "name of the step to fuzz")
+	fuzzCmd.Flags().StringArrayVar(&fuzzFields, "field", nil, "field of the target step to mutate (repeatable)")
+	fuzzCmd.Flags().StringSliceVar(&fuzzCorpora, "corpus", nil, "corpora to use: "+strings.Join(fuzz.CorpusNames(), ", ")+" (default all)")
+	fuzzCmd.Flags().DurationVar(&fuzzTimeout, "timeout", 10*time.Second, "request timeout, unless the config sets one; slower responses are reported")
+	fuzzCmd.Flags().StringVarP(&fuzzEnvironment, "env", "e", "", "environment to use for variable substitution")
+	fuzzCmd.Flags().StringVarP(&fuzzOutputFile, "output", "o", "", "write the findings as JSON to this file")
+	cobra.CheckErr(fuzzCmd.MarkFlagRequired("target"))
+	cobra.CheckErr(fuzzCmd.MarkFlagRequired("field"))
+
+	registerCompletions(fuzzCmd)
+}
+
+func runFuzz(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if fuzzEnvironment != "" {
+		cfg = cfg.MergeEnvironment(fuzzEnvironment)
+	}
+	if cmd.Flags().Changed("timeout") || cfg.Defaults.HTTPTimeout == 0 {
+		cfg.Defaults.HTTPTimeout = fuzzTimeout
+	}
+
+	payloads, err := fuzz.Corpora(fuzzCorpora...)
+	if err != nil {
+		return err
+	}
+
+	scenarios, err := loadScenarios(args)
+	if err != nil {
+		return err
+	}
+
+	var findings []fuzz.Finding
+	targets := 0
+	for _, sc := range scenarios {
+		target, err := fuzz.NewTarget(sc, fuzzTarget)
+		if err != nil {
+			continue
+		}
+		targets++
+
+		found, err := fuzzStep(cfg, target, payloads)
+		if err != nil {
+			return fmt.Errorf("%s: %w", sc.Name, err)
+		}
+		findings = append(findings, found...)
+	}
+	if targets == 0 {
+		return fmt.Errorf("no scenario has a step named %s", fuzzTarget)
+	}
+
+	if len(findings) > 0 {
+		fmt.Printf("\n%-24s %-10s %-32s %s\n", "FIELD", "CORPUS", "PAYLOAD", "DEVIATION")
+		for _, finding := range findings {
+			fmt.Printf("%-24s %-10s %-32s %s\n", finding.Field, finding.Payload.Corpus, shortPayload(finding.Payload.Value), finding.Deviation)
+		}
+	}
+
+	if fuzzOutputFile != "" {
+		data, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal findings: %w", err)
+		}
+		if err := os.WriteFile(fuzzOutputFile, data, 0644); err != nil {
+			return fmt.Errorf("failed to write findings: %w", err)
+		}
+	}
+
+	if len(findings) > 0 {
+		return fmt.Errorf("%d payload(s) made %s deviate from the baseline", len(findings), fuzzTarget)
+	}
+	fmt.Println("\nNo deviations from the baseline")
+	return nil
+}
+
+// fuzzStep runs the baseline and then every payload against every field
+func fuzzStep(cfg *config.Config, target *fuzz.Target, payloads []fuzz.Payload) ([]fuzz.Finding, error) {
+	baselines, err := fuzzRun(cfg, target.Baseline())
+	if err != nil {
+		return nil, err
+	}
+	if len(baselines) == 0 {
+		return nil, fmt.Errorf("step %s did not run in the baseline", fuzzTarget)
+	}
+	baseline := baselines[0]
+	if baseline.Error != "" {
+		return nil, fmt.Errorf("baseline request failed: %s", baseline.Error)
+	}
+
+	var findings []fuzz.Finding
+	for _, field := range fuzzFields {
+		fmt.Printf("Fuzzing %s with %d payloads...\n", field, len(payloads))
+		for _, payload := range payloads {
+			step, err := fuzz.Mutate(target.Step(), field, payload.Value)
+			if err != nil {
+				return nil, err
+			}
+			observations, err := fuzzRun(cfg, target.With(step))
+			if err != nil {
+				return nil, err
+			}
+			for _, observed := range observations {
+				if deviation := fuzz.Deviation(baseline, observed); deviation != "" {
+					findings = append(findings, fuzz.Finding{Field: field, Payload: payload, Observed: observed, Deviation: deviation})
+					break
+				}
+			}
+		}
+	}
+	return findings, nil
+}
+
+func fuzzRun(cfg *config.Config, sc *scenario.Scenario) ([]fuzz.Observation, error) {
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "none"})
+	if err := execution.NewEngine(cfg, reporter).ExecuteScenarios([]*scenario.Scenario{sc}); err != nil {
+		return nil, err
+	}
+	return fuzz.Observe(reporter.GetReport(), fuzzTarget), nil
+}
+
+// shortPayload keeps long payloads readable in the findings table
+func shortPayload(value interface{}) string {
+	text := fmt.Sprintf("%q", fmt.Sprint(value))
+	if len(text) > 30 {
+		text = fmt.Sprintf("%s... (%d bytes)", text[:16], len(fmt.Sprint(value)))
+	}
+	return text
+}
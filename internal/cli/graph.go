@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/graph"
+	"github.com/spf13/cobra"
+)
+
+var graphCmd = &cobra.Command{
+	Use:   "graph [scenario file]",
+	Short: "Draw the steps of a scenario as a diagram",
+	Long: `Draw the steps of a scenario in the order they run, grouped by phase (setup,
+steps, test groups, teardown), with on_failure, on_success and cleanup
+branches, depends_on edges and the variables a step captures for later steps.
+
+The format follows the extension of --out: .svg renders a standalone SVG
+(Graphviz is not needed), .dot or .gv writes Graphviz DOT. Without --out the
+DOT source is printed.
+
+Examples:
+  fuego graph checkout.yaml --out checkout.svg
+  fuego graph checkout.yaml | dot -Tpng -o checkout.png`,
+	Args: cobra.ExactArgs(1),
+	RunE: runGraph,
+}
+
+var (
+	graphOut    string
+	graphFormat string
+)
+
+func init() {
+	rootCmd.AddCommand(graphCmd)
+
+	graphCmd.Flags().StringVarP(&graphOut, "out", "o", "", "file to write the diagram to (default: DOT on stdout)")
+	graphCmd.Flags().StringVar(&graphFormat, "format", "", "dot or svg (default: from the --out extension)")
+
+	registerCompletions(graphCmd, "dot", "svg")
+}
+
+func runGraph(cmd *cobra.Command, args []string) error {
+	scenarios, err := loadScenarios(args)
+	if err != nil {
+		return fmt.Errorf("failed to load scenario: %w", err)
+	}
+	if len(scenarios) != 1 {
+		return fmt.Errorf("%s holds %d scenarios, graph draws one scenario file", args[0], len(scenarios))
+	}
+
+	format := strings.ToLower(graphFormat)
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(graphOut)) {
+		case ".svg":
+			format = "svg"
+		case "", ".dot", ".gv":
+			format = "dot"
+		default:
+			return fmt.Errorf("cannot tell the format of %s, pass --format dot or --format svg", graphOut)
+		}
+	}
+
+	g := graph.Build(scenarios[0])
+	var output string
+	switch format {
+	case "dot":
+		output = g.DOT()
+	case "svg":
+		output = g.SVG()
+	default:
+		return fmt.Errorf("unknown graph format %q (dot, svg)", graphFormat)
+	}
+
+	if graphOut == "" {
+		fmt.Print(output)
+		return nil
+	}
+	if err := os.WriteFile(graphOut, []byte(output), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", graphOut, err)
+	}
+	fmt.Printf("Wrote %d step(s) of %s to %s\n", len(g.Nodes), g.Name, graphOut)
+	return nil
+}
@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/history"
+	"github.com/spf13/cobra"
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show pass-rate and latency trends from stored runs",
+	Long: `Show per-scenario pass rate and duration trends from the results store written
+by "fuego run --history", and detect regressions of the latest run against a
+baseline run (the previous run by default).
+
+The store is a JSON lines file or an http(s) endpoint that accepts POSTed runs
+and returns all runs as a JSON array on GET.
+
+Examples:
+  fuego history
+  fuego history --store https://results.example.com/fuego --last 20
+  fuego history --baseline 20240101T120000.000Z --threshold 0.1`,
+	Args: cobra.NoArgs,
+	RunE: runHistory,
+}
+
+var (
+	historyStore     string
+	historyLast      int
+	historyBaseline  string
+	historyThreshold float64
+	historyFormat    string
+)
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().StringVar(&historyStore, "store", history.DefaultPath, "results store file or http(s) endpoint")
+	historyCmd.Flags().IntVar(&historyLast, "last", 0, "only consider the last N runs (0 = all)")
+	historyCmd.Flags().StringVar(&historyBaseline, "baseline", "", "run ID to compare the latest run against (default: previous run)")
+	historyCmd.Flags().Float64Var(&historyThreshold, "threshold", 0.2, "relative latency increase reported as a regression")
+	historyCmd.Flags().StringVarP(&historyFormat, "format", "f", "console", "output format (console, json)")
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	records, err := history.Open(historyStore).Load()
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("no runs recorded in %s", historyStore)
+	}
+
+	all := records
+	if historyLast > 0 && len(records) > historyLast {
+		records = records[len(records)-historyLast:]
+	}
+
+	trends := history.Trends(records)
+	latest := records[len(records)-1]
+
+	var baseline history.Record
+	hasBaseline := false
+	if historyBaseline != "" {
+		baseline, hasBaseline = history.Find(all, historyBaseline)
+		if !hasBaseline {
+			return fmt.Errorf("baseline run %s not found", historyBaseline)
+		}
+	} else if len(records) > 1 {
+		baseline, hasBaseline = records[len(records)-2], true
+	}
+
+	var regressions []history.Regression
+	if hasBaseline {
+		regressions = history.Compare(baseline, latest, historyThreshold)
+	}
+
+	if historyFormat == "json" {
+		jsonData, err := json.MarshalIndent(map[string]interface{}{
+			"runs":        len(records),
+			"latest":      latest.ID,
+			"baseline":    baseline.ID,
+			"trends":      trends,
+			"regressions": regressions,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal history to JSON: %w", err)
+		}
+		fmt.Println(string(jsonData))
+		return nil
+	}
+
+	fmt.Printf("=== History (%d runs, latest %s) ===\n", len(records), latest.ID)
+	for _, trend := range trends {
+		fmt.Printf("%-40s %6.1f%% passed  avg %-12v last %-12v %s\n",
+			trend.Scenario, trend.PassRate, trend.AvgDuration, trend.LastDuration, statusTrail(trend.Statuses))
+	}
+
+	if !hasBaseline {
+		return nil
+	}
+
+	fmt.Printf("\n=== Regressions vs %s ===\n", baseline.ID)
+	if len(regressions) == 0 {
+		fmt.Println("none")
+		return nil
+	}
+	for _, regression := range regressions {
+		name := regression.Scenario
+		if regression.Step != "" {
+			name += " / " + regression.Step
+		}
+		fmt.Printf("  ✗ %s: %s\n", name, regression.Message)
+	}
+
+	return nil
+}
+
+// statusTrail renders one marker per run, oldest first
+func statusTrail(statuses []string) string {
+	var b strings.Builder
+	for _, status := range statuses {
+		switch status {
+		case "failed":
+			b.WriteString("✗")
+		case "skipped":
+			b.WriteString("⊖")
+		default:
+			b.WriteString("✓")
+		}
+	}
+	return b.String()
+}
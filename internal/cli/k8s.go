@@ -0,0 +1,196 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/execution"
+	"github.com/nulln0ne/fuego/pkg/k8s"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/version"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var k8sCmd = &cobra.Command{
+	Use:   "k8s",
+	Short: "Run scenarios as Kubernetes Jobs and CronJobs",
+}
+
+var k8sRunCmd = &cobra.Command{
+	Use:   "run [scenario files or directories...] [-- fuego run flags]",
+	Short: "Run scenarios inside a cluster and fetch the report",
+	Long: `Package scenario files and directories into a ConfigMap and run them in a Job
+with the fuego image, wait for it to finish and print its report like fuego run.
+Directories are packaged with every file they contain (includes, data files),
+and the config file in use is packaged too. Everything must fit in the 1 MiB a
+ConfigMap holds.
+
+With --schedule a CronJob runs the scenarios on a schedule instead, e.g. for
+environment monitoring; fuego returns once it is created, and the report of
+each run is in the logs of its job. Secrets given with --secret are exposed to
+the container as environment variables, for ${VAR} credentials in the config
+and FUEGO_* overrides.
+
+kubectl must be installed; its current context is used unless --context is set.
+Flags after -- are passed to fuego run in the container.
+
+Examples:
+  fuego k8s run -n qa --env staging tests/smoke/
+  fuego k8s run --schedule "*/15 * * * *" --name smoke --secret api-token tests/smoke/
+  fuego k8s run --dry-run tests/ > fuego.yaml
+  fuego k8s run tests/ -- --label severity=critical --repeat 3`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runK8s,
+}
+
+var (
+	k8sNamespace   string
+	k8sContext     string
+	k8sImage       string
+	k8sName        string
+	k8sSchedule    string
+	k8sEnvironment string
+	k8sLabels      []string
+	k8sSecrets     []string
+	k8sTimeout     time.Duration
+	k8sKeep        bool
+	k8sDryRun      bool
+	k8sFormat      string
+	k8sOutput      string
+	k8sKubectl     string
+)
+
+func init() {
+	rootCmd.AddCommand(k8sCmd)
+	k8sCmd.AddCommand(k8sRunCmd)
+
+	k8sRunCmd.Flags().StringVarP(&k8sNamespace, "namespace", "n", "", "namespace to run in (default the namespace of the kubeconfig context)")
+	k8sRunCmd.Flags().StringVar(&k8sContext, "context", "", "kubeconfig context to use")
+	k8sRunCmd.Flags().StringVar(&k8sImage, "image", k8s.DefaultImage(version.Get().Version), "fuego image to run")
+	k8sRunCmd.Flags().StringVar(&k8sName, "name", "", "name of the ConfigMap and Job or CronJob (default fuego-<timestamp>)")
+	k8sRunCmd.Flags().StringVar(&k8sSchedule, "schedule", "", "create a CronJob running on this cron schedule instead of a Job")
+	k8sRunCmd.Flags().StringVarP(&k8sEnvironment, "env", "e", "", "environment to use for variable substitution")
+	k8sRunCmd.Flags().StringArrayVar(&k8sLabels, "label", nil, "run only scenarios whose metadata label matches key=value[,value] (repeatable)")
+	k8sRunCmd.Flags().StringArrayVar(&k8sSecrets, "secret", nil, "expose the keys of this Secret as environment variables (repeatable)")
+	k8sRunCmd.Flags().DurationVar(&k8sTimeout, "timeout", 30*time.Minute, "deadline of a run, after which the job fails")
+	k8sRunCmd.Flags().BoolVar(&k8sKeep, "keep", false, "keep the Job and ConfigMap after the run")
+	k8sRunCmd.Flags().BoolVar(&k8sDryRun, "dry-run", false, "print the manifests instead of applying them")
+	k8sRunCmd.Flags().StringVarP(&k8sFormat, "format", "f", "console", "format of the fetched report (console, json, html, markdown, github, junit, csv)")
+	k8sRunCmd.Flags().StringVarP(&k8sOutput, "output", "o", "", "write the fetched report to this file")
+	k8sRunCmd.Flags().StringVar(&k8sKubectl, "kubectl", "kubectl", "kubectl binary to use")
+
+	registerCompletions(k8sRunCmd, reporting.Formats...)
+}
+
+func runK8s(cmd *cobra.Command, args []string) error {
+	paths, runArgs := args, []string(nil)
+	if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+		paths, runArgs = args[:dash], args[dash:]
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no scenario files or directories given")
+	}
+	if k8sEnvironment != "" {
+		runArgs = append([]string{"--env", k8sEnvironment}, runArgs...)
+	}
+	for _, label := range k8sLabels {
+		runArgs = append(runArgs, "--label", label)
+	}
+
+	bundle, err := k8s.NewBundle(paths, viper.ConfigFileUsed())
+	if err != nil {
+		return err
+	}
+
+	name := k8sName
+	if name == "" {
+		name = "fuego-" + time.Now().Format("20060102-150405")
+	}
+	spec := k8s.Spec{
+		Name:      name,
+		Namespace: k8sNamespace,
+		Image:     k8sImage,
+		Schedule:  k8sSchedule,
+		Args:      runArgs,
+		Secrets:   k8sSecrets,
+		Timeout:   k8sTimeout,
+	}
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	manifests := spec.Manifests(bundle)
+
+	if k8sDryRun {
+		content, err := k8s.WriteYAML(manifests)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(content)
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	kubectl := &k8s.Kubectl{Path: k8sKubectl, Context: k8sContext, Namespace: k8sNamespace}
+	if err := kubectl.Apply(ctx, manifests); err != nil {
+		return err
+	}
+
+	if k8sSchedule != "" {
+		fmt.Printf("Created CronJob %s (%s) with %d file(s)\n", name, k8sSchedule, len(bundle.Files))
+		fmt.Printf("Reports are in the logs of its jobs: kubectl logs job/<job>; remove it with kubectl delete cronjob,configmap %s\n", name)
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "Started Job %s with %d file(s), waiting for it to finish...\n", name, len(bundle.Files))
+	if !k8sKeep {
+		defer func() {
+			// The run may have been interrupted, so cleanup gets a context of its own
+			cleanup, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := kubectl.Delete(cleanup, name, "job", "configmap"); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to delete job %s: %v\n", name, err)
+			}
+		}()
+	}
+
+	status, err := kubectl.WaitJob(ctx, name, 2*time.Second)
+	if err != nil {
+		return err
+	}
+	logs, err := kubectl.Logs(ctx, name)
+	if err != nil {
+		return err
+	}
+	report, err := k8s.ReportFromLogs(logs)
+	if err != nil {
+		if status.Reason != "" {
+			return fmt.Errorf("job %s failed (%s: %s) without a report:\n%s", name, status.Reason, status.Message, logs)
+		}
+		return fmt.Errorf("job %s: %w:\n%s", name, err, logs)
+	}
+
+	reportConfig := reporting.ReportConfig{Format: k8sFormat, OutputFile: k8sOutput}
+	if cfg, err := config.LoadConfig(viper.ConfigFileUsed()); err == nil {
+		reportConfig.Storage = execution.ReportStorage(cfg.Reports)
+	}
+	if err := reporting.WriteReport(report, reportConfig); err != nil {
+		return err
+	}
+	// The exit code of fuego in the container decides, so --fail-on applies as it does locally
+	switch {
+	case status.Succeeded:
+		return nil
+	case !report.Passed():
+		return fmt.Errorf("%d scenario(s) failed in job %s", report.Summary.Failed, name)
+	default:
+		return fmt.Errorf("job %s failed: %s", name, status.Message)
+	}
+}
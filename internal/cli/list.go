@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/spf13/cobra"
+)
+
+var listCmd = &cobra.Command{
+	Use:   "list [scenario files or directories...]",
+	Short: "List scenarios with their test groups, steps, tags and required variables",
+	Long: `List the scenarios found in files and directories without running them: their
+groups and step counts, tags and labels, matrix combinations, and the
+variables their templates use but no part of the scenario sets, which have to
+come from the config, an environment or the command line.
+
+--format json prints the same as a JSON array, for building CI shards.
+
+Examples:
+  fuego list tests/
+  fuego list --label component=payments --format json tests/`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runList,
+}
+
+var (
+	listFormat string
+	listLabels []string
+)
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+
+	listCmd.Flags().StringVarP(&listFormat, "format", "f", "text", "output format (text, json)")
+	listCmd.Flags().StringArrayVar(&listLabels, "label", nil, "list only scenarios whose metadata label matches key=value[,value] (repeatable, all must match)")
+
+	registerCompletions(listCmd, "text", "json")
+}
+
+func runList(cmd *cobra.Command, args []string) error {
+	scenarios, err := loadScenarios(args)
+	if err != nil {
+		return err
+	}
+	if len(listLabels) > 0 {
+		selectors := make([]scenario.LabelSelector, 0, len(listLabels))
+		for _, spec := range listLabels {
+			selector, err := scenario.ParseLabelSelector(spec)
+			if err != nil {
+				return err
+			}
+			selectors = append(selectors, selector)
+		}
+		scenarios = scenario.FilterByLabels(scenarios, selectors)
+	}
+
+	inventories := make([]scenario.Inventory, 0, len(scenarios))
+	for _, sc := range scenarios {
+		inventories = append(inventories, scenario.Inventorize(sc))
+	}
+
+	switch listFormat {
+	case "json":
+		data, err := json.MarshalIndent(inventories, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal scenario list: %w", err)
+		}
+		fmt.Println(string(data))
+	case "text":
+		printInventories(inventories)
+	default:
+		return fmt.Errorf("unknown list format %q (text, json)", listFormat)
+	}
+	return nil
+}
+
+func printInventories(inventories []scenario.Inventory) {
+	steps := 0
+	for _, inventory := range inventories {
+		steps += inventory.Steps
+		fmt.Printf("%s (%s)\n", inventory.Name, inventory.File)
+		if len(inventory.Tags) > 0 {
+			fmt.Printf("  tags: %s\n", strings.Join(inventory.Tags, ", "))
+		}
+		if len(inventory.Labels) > 0 {
+			fmt.Printf("  labels: %s\n", formatLabels(inventory.Labels))
+		}
+		if inventory.Matrix > 0 {
+			fmt.Printf("  matrix: %d combination(s)\n", inventory.Matrix)
+		}
+		width := 0
+		for _, group := range inventory.Groups {
+			width = max(width, len(group.Name))
+		}
+		for _, group := range inventory.Groups {
+			skipped := ""
+			if group.Skip {
+				skipped = " (skipped)"
+			}
+			fmt.Printf("  %-*s  %d step(s)%s\n", width, group.Name, group.Steps, skipped)
+		}
+		if len(inventory.Variables) > 0 {
+			fmt.Printf("  requires: %s\n", strings.Join(inventory.Variables, ", "))
+		}
+	}
+	fmt.Printf("\n%d scenario(s), %d step(s)\n", len(inventories), steps)
+}
+
+func formatLabels(labels map[string]string) string {
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, key := range keys {
+		pairs[i] = key + "=" + labels[key]
+	}
+	return strings.Join(pairs, ", ")
+}
@@ -0,0 +1,248 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/execution"
+	"github.com/nulln0ne/fuego/pkg/load"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/spf13/cobra"
+)
+
+var loadCmd = &cobra.Command{
+	Use:   "load [scenario file or directory]",
+	Short: "Run scenarios under a load profile",
+	Long: `Run the scenarios repeatedly at a controlled rate and report every stage of
+the load profile. One iteration runs every scenario once; an iteration fails when
+any scenario fails.
+
+Profiles:
+  constant  --rate for --duration
+  soak      --rate for --duration (hours), reported hour by hour
+  stress    from --rate, adding --step every --stage-duration up to --max-rate,
+            until a stop condition is violated (the breaking point)
+  spike     --rate for --stage-duration, a burst at --max-rate for
+            --spike-duration, then --rate again to check recovery
+
+While the run is going, a terminal shows live requests per second, error rate
+and latency percentiles per step (--no-live for plain stage lines), and
+--dashboard serves the same numbers as a web page with a button that stops the
+run.
+
+Stop conditions (--max-error-rate, --max-p95) end the run after the first stage
+violating them. A stopped soak, spike or constant run exits non-zero; for
+stress, the stage that stopped the run is reported as the breaking point.
+
+Examples:
+  fuego load --profile soak --rate 2 --duration 8h --max-error-rate 0.01 tests/
+  fuego load --profile stress --rate 10 --step 10 --max-rate 200 --stage-duration 1m --max-error-rate 0.05 test.yaml
+  fuego load --profile spike --rate 5 --max-rate 100 --stage-duration 2m --spike-duration 30s -o load.json test.yaml
+  fuego load --profile soak --rate 1 --duration 2h --dashboard localhost:8089 tests/`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runLoad,
+}
+
+var (
+	loadProfile       string
+	loadEnvironment   string
+	loadOutputFile    string
+	loadRate          float64
+	loadDuration      time.Duration
+	loadStep          float64
+	loadMaxRate       float64
+	loadStageDuration time.Duration
+	loadSpikeDuration time.Duration
+	loadMaxErrorRate  float64
+	loadMaxP95        time.Duration
+	loadMaxInFlight   int
+	loadDashboard     string
+	loadNoLive        bool
+)
+
+func init() {
+	rootCmd.AddCommand(loadCmd)
+
+	loadCmd.Flags().StringVar(&loadProfile, "profile", "constant", "load profile ("+strings.Join(load.ProfileNames, ", ")+")")
+	loadCmd.Flags().StringVarP(&loadEnvironment, "env", "e", "", "environment to use for variable substitution")
+	loadCmd.Flags().StringVarP(&loadOutputFile, "output", "o", "", "write the stage results as JSON to this file")
+	loadCmd.Flags().Float64Var(&loadRate, "rate", 1, "iterations per second (start rate for stress, base rate for spike)")
+	loadCmd.Flags().DurationVar(&loadDuration, "duration", time.Minute, "run duration for constant and soak")
+	loadCmd.Flags().Float64Var(&loadStep, "step", 0, "rate added per stress stage (defaults to --rate)")
+	loadCmd.Flags().Float64Var(&loadMaxRate, "max-rate", 0, "last stress stage rate, or the spike burst rate")
+	loadCmd.Flags().DurationVar(&loadStageDuration, "stage-duration", time.Minute, "duration of stress stages and of spike warm-up and recovery")
+	loadCmd.Flags().DurationVar(&loadSpikeDuration, "spike-duration", 30*time.Second, "duration of the spike burst")
+	loadCmd.Flags().Float64Var(&loadMaxErrorRate, "max-error-rate", 0, "stop after a stage whose share of failed iterations exceeds this (0-1)")
+	loadCmd.Flags().DurationVar(&loadMaxP95, "max-p95", 0, "stop after a stage whose p95 iteration duration exceeds this")
+	loadCmd.Flags().StringVar(&loadDashboard, "dashboard", "", "serve a live web dashboard on this address (localhost:8089 without a value)")
+	loadCmd.Flags().Lookup("dashboard").NoOptDefVal = "localhost:8089"
+	loadCmd.Flags().BoolVar(&loadNoLive, "no-live", false, "print one line per stage instead of the live terminal dashboard")
+	loadCmd.Flags().IntVar(&loadMaxInFlight, "max-in-flight", 100, "most iterations running at once; further ticks are dropped (0 = unlimited)")
+
+	registerCompletions(loadCmd)
+	cobra.CheckErr(loadCmd.RegisterFlagCompletionFunc("profile", cobra.FixedCompletions(load.ProfileNames, cobra.ShellCompDirectiveNoFileComp)))
+}
+
+func runLoad(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if loadEnvironment != "" {
+		cfg = cfg.MergeEnvironment(loadEnvironment)
+	}
+	// Notifications fire once per run, not once per iteration
+	cfg.Webhooks = nil
+	cfg.Integrations = config.IntegrationsConfig{}
+
+	profile, err := load.NewProfile(loadProfile, load.Settings{
+		Rate:          loadRate,
+		Duration:      loadDuration,
+		Step:          loadStep,
+		MaxRate:       loadMaxRate,
+		StageDuration: loadStageDuration,
+		SpikeDuration: loadSpikeDuration,
+		Stop:          load.StopConditions{MaxErrorRate: loadMaxErrorRate, MaxP95: loadMaxP95},
+	})
+	if err != nil {
+		return err
+	}
+	if err := profile.Validate(); err != nil {
+		return err
+	}
+
+	scenarios, err := loadScenarios(args)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	metrics := load.NewMetrics(load.DefaultWindow)
+	if loadDashboard != "" {
+		server := &http.Server{Addr: loadDashboard, Handler: load.NewDashboard(metrics, stop)}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fmt.Fprintf(os.Stderr, "Dashboard failed: %v\n", err)
+			}
+		}()
+		defer server.Close()
+		fmt.Printf("Dashboard on http://%s\n", loadDashboard)
+	}
+
+	var mu sync.Mutex
+	lines := []string{
+		fmt.Sprintf("Load profile %s: %d stage(s)", profile.Name, len(profile.Stages)),
+		fmt.Sprintf("%-12s %8s %10s %8s %8s %8s %10s %10s %10s", "STAGE", "TARGET", "ITERATIONS", "FAILED", "DROPPED", "ERRORS", "RATE", "P50", "P95"),
+	}
+	live := !loadNoLive && reporting.IsTerminal(os.Stdout)
+	if !live {
+		fmt.Println(strings.Join(lines, "\n"))
+	}
+
+	options := load.Options{
+		MaxInFlight:  loadMaxInFlight,
+		StageStarted: metrics.SetStage,
+		StageFinished: func(stage load.StageResult) {
+			mu.Lock()
+			defer mu.Unlock()
+			stageLines := formatLoadStage(stage)
+			lines = append(lines, stageLines...)
+			if !live {
+				fmt.Println(strings.Join(stageLines, "\n"))
+			}
+		},
+	}
+
+	done := make(chan struct{})
+	if live {
+		go func() {
+			ticker := time.NewTicker(time.Second)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					return
+				case <-ticker.C:
+					mu.Lock()
+					load.RenderTerminal(os.Stdout, lines, metrics.Snapshot())
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	result, err := load.Run(ctx, profile, iterateScenarios(cfg, scenarios, metrics), options)
+	close(done)
+	if live {
+		mu.Lock()
+		load.RenderTerminal(os.Stdout, lines, metrics.Snapshot())
+		mu.Unlock()
+	}
+	if err != nil && ctx.Err() == nil {
+		return err
+	}
+
+	if loadOutputFile != "" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal load results: %w", err)
+		}
+		if err := os.WriteFile(loadOutputFile, data, 0644); err != nil {
+			return fmt.Errorf("failed to write load results: %w", err)
+		}
+	}
+
+	if result.StoppedBy == "" {
+		fmt.Printf("Completed in %v\n", result.Duration.Round(time.Second))
+		return nil
+	}
+	if profile.Name == "stress" {
+		fmt.Printf("Breaking point: %s\n", result.StoppedBy)
+		return nil
+	}
+	return fmt.Errorf("load run stopped: %s", result.StoppedBy)
+}
+
+// iterateScenarios runs every scenario once per iteration with a fresh engine, so iterations do
+// not share variables or reports. Every finished step is recorded in metrics.
+func iterateScenarios(cfg *config.Config, scenarios []*scenario.Scenario, metrics *load.Metrics) load.Iteration {
+	return func(ctx context.Context) bool {
+		reporter := reporting.NewReporter(reporting.ReportConfig{Format: "none"})
+		reporter.Subscribe(func(event reporting.Event) {
+			if event.Type == reporting.EventStepFinished && event.Step != nil && event.Step.Status != "skipped" {
+				metrics.Record(event.Step.LogicalName(), event.Step.Duration, event.Step.Status == "failed")
+			}
+		})
+		engine := execution.NewEngine(cfg, reporter)
+		if err := engine.ExecuteScenariosContext(ctx, scenarios); err != nil {
+			return false
+		}
+		for _, result := range reporter.GetReport().Scenarios {
+			if result.Status == "failed" {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func formatLoadStage(stage load.StageResult) []string {
+	lines := []string{fmt.Sprintf("%-12s %7g/s %10d %8d %8d %7.1f%% %8.1f/s %10v %10v",
+		stage.Stage.Name, stage.Stage.Rate, stage.Iterations, stage.Failed, stage.Dropped,
+		stage.ErrorRate*100, stage.Rate, stage.P50.Round(time.Millisecond), stage.P95.Round(time.Millisecond))}
+	if stage.Violation != "" {
+		lines = append(lines, "  stop condition violated: "+stage.Violation)
+	}
+	return lines
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/spf13/cobra"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate [scenario files...]",
+	Short: "Rewrite scenarios in the legacy format to the current format",
+	Long: `Rewrite scenario files to the current format (version ` + scenario.CurrentVersion + `): request steps
+become http steps, with captures for the variables they extracted, setup steps
+move to before, teardown steps to after and top-level steps to a "main" test
+group. Comments are kept.
+
+Steps that cannot be migrated are kept as they are and reported on stderr, as
+are changes in behavior to review.
+
+Without --write the migrated scenario is printed.
+
+Examples:
+  fuego migrate old.yaml
+  fuego migrate --write scenarios/*.yaml`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runMigrate,
+}
+
+var migrateWrite bool
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.Flags().BoolVarP(&migrateWrite, "write", "w", false, "rewrite the files in place")
+
+	registerCompletions(migrateCmd)
+}
+
+func runMigrate(cmd *cobra.Command, args []string) error {
+	if !migrateWrite && len(args) > 1 {
+		return fmt.Errorf("migrating %d files prints nothing useful, pass --write to rewrite them in place", len(args))
+	}
+
+	for _, path := range args {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		migrated, notes, err := scenario.Migrate(content)
+		if err != nil {
+			return fmt.Errorf("failed to migrate %s: %w", path, err)
+		}
+		for _, note := range notes {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", path, note)
+		}
+
+		if !migrateWrite {
+			fmt.Print(string(migrated))
+			continue
+		}
+		if err := os.WriteFile(path, migrated, 0644); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Migrated %s\n", path)
+	}
+	return nil
+}
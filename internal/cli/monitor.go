@@ -0,0 +1,183 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/execution"
+	"github.com/nulln0ne/fuego/pkg/monitor"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/spf13/cobra"
+)
+
+var monitorCmd = &cobra.Command{
+	Use:   "monitor [scenario files or directories...]",
+	Short: "Run scenarios on an interval as synthetic checks",
+	Long: `Run scenarios every --interval as lightweight synthetic checks until stopped
+with Ctrl+C. Every check is summarized on one line; rolling success rates and
+latency percentiles per scenario are served as Prometheus metrics on
+--metrics-addr (/metrics, and /status as JSON).
+
+A scenario failing --alert-after checks in a row fires an alert, and a resolved
+alert follows when it passes again. Alerts are POSTed as JSON (X-Fuego-Event:
+alert) to --webhook URLs and to the config webhooks listing the alert event. A
+check that cannot run at all, e.g. because preflight checks failed, counts as a
+failure of every scenario.
+
+Examples:
+  fuego monitor --interval 5m scenarios/
+  fuego monitor --interval 1m --env production --label severity=critical scenarios/
+  fuego monitor --alert-after 2 --webhook https://hooks.example.com/fuego scenarios/`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runMonitor,
+}
+
+var (
+	monitorInterval    time.Duration
+	monitorEnvironment string
+	monitorLabels      []string
+	monitorMetricsAddr string
+	monitorWindow      int
+	monitorAlertAfter  int
+	monitorWebhooks    []string
+)
+
+func init() {
+	rootCmd.AddCommand(monitorCmd)
+
+	monitorCmd.Flags().DurationVar(&monitorInterval, "interval", 5*time.Minute, "time between the starts of checks")
+	monitorCmd.Flags().StringVarP(&monitorEnvironment, "env", "e", "", "environment to use for variable substitution")
+	monitorCmd.Flags().StringArrayVar(&monitorLabels, "label", nil, "check only scenarios whose metadata label matches key=value[,value] (repeatable, all must match)")
+	monitorCmd.Flags().StringVar(&monitorMetricsAddr, "metrics-addr", ":9469", "address serving Prometheus metrics (empty to disable)")
+	monitorCmd.Flags().IntVar(&monitorWindow, "window", 20, "recent checks per scenario the success rate and latency percentiles cover")
+	monitorCmd.Flags().IntVar(&monitorAlertAfter, "alert-after", 3, "consecutive failed checks of a scenario that fire an alert")
+	monitorCmd.Flags().StringArrayVar(&monitorWebhooks, "webhook", nil, "POST alerts as JSON to this URL (repeatable)")
+
+	registerCompletions(monitorCmd)
+}
+
+func runMonitor(cmd *cobra.Command, args []string) error {
+	cfg, err := loadConfig()
+	if err != nil {
+		return err
+	}
+	if monitorEnvironment != "" {
+		if _, exists := cfg.GetEnvironment(monitorEnvironment); !exists {
+			return fmt.Errorf("environment %s is not defined in config", monitorEnvironment)
+		}
+		cfg = cfg.MergeEnvironment(monitorEnvironment)
+	}
+
+	var notifiers []monitor.Notifier
+	for _, webhook := range cfg.Webhooks {
+		headers := make(map[string]string, len(webhook.Headers))
+		for name, value := range webhook.Headers {
+			headers[name] = os.ExpandEnv(value)
+		}
+		notifiers = append(notifiers, reporting.NewWebhookSink(webhook.URL, headers, webhook.Events, webhook.Timeout))
+	}
+	for _, url := range monitorWebhooks {
+		notifiers = append(notifiers, reporting.NewWebhookSink(url, nil, []string{"alert"}, 0))
+	}
+	// Checks only notify through alerts, not with every report
+	cfg.Webhooks = nil
+	cfg.Integrations = config.IntegrationsConfig{}
+
+	scenarios, err := loadScenarios(args)
+	if err != nil {
+		return err
+	}
+	if len(monitorLabels) > 0 {
+		selectors := make([]scenario.LabelSelector, 0, len(monitorLabels))
+		for _, spec := range monitorLabels {
+			selector, err := scenario.ParseLabelSelector(spec)
+			if err != nil {
+				return err
+			}
+			selectors = append(selectors, selector)
+		}
+		scenarios = scenario.FilterByLabels(scenarios, selectors)
+	}
+	if len(scenarios) == 0 {
+		return fmt.Errorf("no scenarios to monitor")
+	}
+	names := make([]string, len(scenarios))
+	for i, sc := range scenarios {
+		names[i] = sc.Name
+	}
+
+	monitored := monitor.New(names, monitor.Options{
+		Interval:   monitorInterval,
+		Window:     monitorWindow,
+		AlertAfter: monitorAlertAfter,
+		Notifiers:  notifiers,
+	})
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if monitorMetricsAddr != "" {
+		server := &http.Server{Addr: monitorMetricsAddr, Handler: monitored.Handler(), ReadHeaderTimeout: 10 * time.Second}
+		go func() {
+			if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				fmt.Fprintf(os.Stderr, "Metrics server failed: %v\n", err)
+			}
+		}()
+		defer server.Close()
+		fmt.Printf("Metrics on http://%s/metrics\n", monitorMetricsAddr)
+	}
+	fmt.Printf("Monitoring %d scenario(s) every %v (press Ctrl+C to stop)\n", len(scenarios), monitorInterval)
+
+	// Every check runs with a fresh engine, so checks do not share variables or reports
+	check := func(ctx context.Context) (*reporting.Report, error) {
+		reporter := reporting.NewReporter(reporting.ReportConfig{Format: "none"})
+		err := execution.NewEngine(cfg, reporter).ExecuteScenariosContext(ctx, scenarios)
+		return reporter.GetReport(), err
+	}
+	return monitored.Run(ctx, check, printMonitorResult)
+}
+
+func printMonitorResult(result monitor.Result) {
+	timestamp := result.Time.Format("15:04:05")
+	if result.Err != nil {
+		fmt.Printf("%s check failed: %v\n", timestamp, result.Err)
+	} else {
+		passed, failed := 0, 0
+		var failedNames []string
+		for _, scenario := range result.Report.Scenarios {
+			switch scenario.Status {
+			case "passed":
+				passed++
+			case "failed":
+				failed++
+				if scenario.Scenario != nil {
+					failedNames = append(failedNames, scenario.Scenario.Name)
+				}
+			}
+		}
+		line := fmt.Sprintf("%s %d passed, %d failed (%v)", timestamp, passed, failed, result.Report.Duration.Round(time.Millisecond))
+		if len(failedNames) > 0 {
+			line += ": " + strings.Join(failedNames, ", ")
+		}
+		fmt.Println(line)
+	}
+
+	for _, alert := range result.Alerts {
+		if alert.Status == monitor.AlertFiring {
+			fmt.Fprintf(os.Stderr, "ALERT %s failed %d checks in a row: %s\n", alert.Scenario, alert.ConsecutiveFailures, strings.Join(alert.Failures, "; "))
+		} else {
+			fmt.Fprintf(os.Stderr, "RESOLVED %s passes again after failing since %s\n", alert.Scenario, alert.Since.Format("15:04:05"))
+		}
+	}
+	for _, err := range result.NotifyErrors {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/record"
+	"github.com/spf13/cobra"
+)
+
+var recordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Record HTTP traffic through a local proxy as a scenario",
+	Long: `Run a local forward proxy and write the requests relayed through it as a
+fuego scenario when the proxy is stopped with Ctrl+C.
+
+Point a client or browser at the proxy (e.g. HTTP_PROXY=http://localhost:8888)
+and exercise the API. Every distinct request becomes an HTTP step checking the
+status code it received; repeated identical requests are recorded once.
+Headers the HTTP client sets itself (User-Agent, Accept-Encoding, Cookie, ...)
+are left out. HTTPS traffic is tunnelled but not recorded.
+
+Examples:
+  fuego record --port 8888 --out recorded.yaml
+  fuego record --host api.example.com --drop-header Authorization
+  fuego record --name "Checkout flow" -o checkout.json`,
+	Args: cobra.NoArgs,
+	RunE: runRecord,
+}
+
+var (
+	recordPort           int
+	recordOutputFile     string
+	recordName           string
+	recordHosts          []string
+	recordDropHeaders    []string
+	recordKeepDuplicates bool
+)
+
+func init() {
+	rootCmd.AddCommand(recordCmd)
+
+	recordCmd.Flags().IntVar(&recordPort, "port", 8888, "port the proxy listens on")
+	recordCmd.Flags().StringVarP(&recordOutputFile, "out", "o", "recorded.yaml", "scenario file to write (.json for JSON)")
+	recordCmd.Flags().StringVar(&recordName, "name", "Recorded scenario", "name of the recorded scenario")
+	recordCmd.Flags().StringSliceVar(&recordHosts, "host", nil, "only record requests to these hosts (repeatable)")
+	recordCmd.Flags().StringSliceVar(&recordDropHeaders, "drop-header", nil, "request headers to leave out of recorded steps (repeatable)")
+	recordCmd.Flags().BoolVar(&recordKeepDuplicates, "keep-duplicates", false, "record repeated identical requests every time")
+}
+
+func runRecord(cmd *cobra.Command, args []string) error {
+	recorder := record.NewRecorder(record.Options{
+		Hosts:          recordHosts,
+		DropHeaders:    recordDropHeaders,
+		KeepDuplicates: recordKeepDuplicates,
+	})
+
+	server := &http.Server{Addr: fmt.Sprintf(":%d", recordPort), Handler: recorder}
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	fmt.Printf("Recording on http://localhost:%d (press Ctrl+C to stop and write %s)\n", recordPort, recordOutputFile)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("recording proxy failed: %w", err)
+		}
+	case <-stop:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	server.Shutdown(ctx)
+
+	exchanges := recorder.Exchanges()
+	if len(exchanges) == 0 {
+		fmt.Println("No requests were recorded")
+		return nil
+	}
+	if err := recorder.Scenario(recordName).WriteFile(recordOutputFile); err != nil {
+		return err
+	}
+	fmt.Printf("Recorded %d request(s) to %s\n", len(exchanges), recordOutputFile)
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/nulln0ne/fuego/pkg/config"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -59,3 +60,12 @@ func initConfig() {
 		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
 }
+
+// loadConfig loads the config file picked up by viper (--config, ./.fuego.yaml or ~/.fuego.yaml)
+func loadConfig() (*config.Config, error) {
+	cfg, err := config.LoadConfig(viper.ConfigFileUsed())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+	return cfg, nil
+}
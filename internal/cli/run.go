@@ -1,13 +1,21 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/nulln0ne/fuego/pkg/config"
 	"github.com/nulln0ne/fuego/pkg/execution"
+	"github.com/nulln0ne/fuego/pkg/history"
+	"github.com/nulln0ne/fuego/pkg/openapi"
 	"github.com/nulln0ne/fuego/pkg/reporting"
 	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/nulln0ne/fuego/pkg/snapshot"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -20,8 +28,30 @@ var runCmd = &cobra.Command{
 Examples:
   fuego run test.yaml          Run a single test scenario
   fuego run tests/             Run all test scenarios in directory
-  fuego run --parallel tests/  Run tests in parallel`,
-	Args: cobra.MinimumNArgs(1),
+  fuego run --parallel tests/  Run tests in parallel
+  fuego run --repeat 20 test.yaml  Detect flaky steps over 20 runs
+  fuego run --summary summary.json tests/  Write a summary for CI gating
+  fuego run --history tests/   Record the run for "fuego history"
+  fuego run --trace=trace.log test.yaml  Dump request/response wire data
+  fuego run --coverage=openapi.yaml tests/  Report the spec operations the run exercised
+  fuego run --artifacts-dir artifacts tests/  Keep failing response bodies
+  fuego run --no-progress tests/  Plain output on an interactive terminal
+  fuego run --quiet --ci tests/  Only failures, in stable CI-friendly output
+  fuego run --report json=out.json --report junit=junit.xml tests/  Extra reports from one run
+  fuego run --report-template summary.tmpl -o summary.md tests/  Render a custom report
+  fuego run --report jsonl=results.jsonl tests/  Stream step results while the run progresses
+  fuego run --webhook https://dashboard.example.com/runs tests/  Push results to a dashboard
+  fuego run --label component=payments --label severity=critical,high tests/  Run a subset
+  fuego run --shard 2/5 --shard-durations last.json tests/  Run the second of five CI shards
+  fuego run --fail-on critical tests/  Exit non-zero only when critical scenarios fail
+  fuego run -f json -o last.json tests/ && fuego run --rerun-failed last.json  Rerun only what failed`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		// The failed scenarios of a report know their files
+		if rerunFailed != "" {
+			return nil
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
 	RunE: runScenarios,
 }
 
@@ -31,6 +61,41 @@ var (
 	environment  string
 	outputFormat string
 	outputFile   string
+
+	updateSnapshots bool
+	snapshotDir     string
+
+	repeat        int
+	stopOnFailure bool
+
+	summaryFile  string
+	historyPath  string
+	tracePath    string
+	coverageSpec string
+	coverageOut  string
+
+	artifactsDir  string
+	keepTmpDirs   bool
+	correlationID string
+	freezeTime    string
+	seed          int64
+	noProgress    bool
+	quiet         bool
+	ciOutput      bool
+	reports       []string
+	reportTmpl    string
+	webhooks      []string
+	labels        []string
+	failOn        string
+	includeBody   bool
+	thinkTime     string
+	pacing        string
+	rerunFailed   string
+
+	shardSpec      string
+	shardIndex     int
+	shardTotal     int
+	shardDurations string
 )
 
 func init() {
@@ -39,8 +104,45 @@ func init() {
 	runCmd.Flags().BoolVarP(&parallel, "parallel", "p", false, "run tests in parallel")
 	runCmd.Flags().IntVarP(&timeout, "timeout", "t", 30, "timeout in seconds for each test")
 	runCmd.Flags().StringVarP(&environment, "env", "e", "", "environment to use for variable substitution")
-	runCmd.Flags().StringVarP(&outputFormat, "format", "f", "console", "output format (console, json, html, markdown)")
+	runCmd.Flags().StringVarP(&outputFormat, "format", "f", "console", "output format (console, json, jsonl, html, markdown, github, junit, csv)")
 	runCmd.Flags().StringVarP(&outputFile, "output", "o", "", "output file path")
+	runCmd.Flags().BoolVar(&includeBody, "include-body", false, "keep response bodies in reports (dropped by default to save memory on large runs)")
+	runCmd.Flags().StringVar(&reportTmpl, "report-template", "", "render the report with a Go template file instead of --format (also enables --report template=path)")
+	runCmd.Flags().StringArrayVar(&labels, "label", nil, "run only scenarios whose metadata label matches key=value[,value] (repeatable, all must match)")
+	runCmd.Flags().StringVar(&failOn, "fail-on", "", "exit non-zero only when scenarios of this metadata severity or higher fail; others are warnings (overrides global.fail_on)")
+	runCmd.Flags().StringArrayVar(&webhooks, "webhook", nil, "POST the final report as JSON to this URL (repeatable; headers and per-scenario events via config webhooks)")
+	runCmd.Flags().StringArrayVar(&reports, "report", nil, "also write a report as format=path (repeatable, e.g. --report junit=junit.xml)")
+	runCmd.Flags().BoolVar(&updateSnapshots, "update-snapshots", false, "overwrite stored snapshots with current responses")
+	runCmd.Flags().StringVar(&snapshotDir, "snapshot-dir", snapshot.DefaultDir, "directory for response snapshots")
+	runCmd.Flags().IntVar(&repeat, "repeat", 1, "run every scenario N times and report flaky steps")
+	runCmd.Flags().BoolVar(&stopOnFailure, "stop-on-failure", false, "stop repeating at the first failed run")
+	runCmd.Flags().StringVar(&summaryFile, "summary", "", "write a machine-readable summary.json to this path")
+	runCmd.Flags().StringVar(&historyPath, "history", "", "append the run to a results store file or http(s) endpoint")
+	runCmd.Flags().Lookup("history").NoOptDefVal = history.DefaultPath
+	runCmd.Flags().StringVar(&tracePath, "trace", "", "dump redacted request/response wire data to stderr, or to a file with --trace=file")
+	runCmd.Flags().Lookup("trace").NoOptDefVal = "-"
+	runCmd.Flags().StringVar(&coverageSpec, "coverage", "", "report the operations of an OpenAPI spec the run exercised (--coverage=openapi.yaml), or the request URLs of the scenarios without a spec")
+	runCmd.Flags().Lookup("coverage").NoOptDefVal = "-"
+	runCmd.Flags().StringVar(&coverageOut, "coverage-out", "", "also write the coverage report as JSON to this path")
+	runCmd.Flags().StringVar(&artifactsDir, "artifacts-dir", "", "save the response body of every failing step to this directory")
+	runCmd.Flags().BoolVar(&keepTmpDirs, "keep-tmpdir", false, "keep the temporary directory of every scenario ({{tmpdir}}, where relative save_response paths go) after it ends")
+	runCmd.Flags().StringVar(&correlationID, "correlation-id", "", "send a generated request ID header with every request (default header X-Request-ID)")
+	runCmd.Flags().Lookup("correlation-id").NoOptDefVal = "X-Request-ID"
+	runCmd.Flags().StringVar(&thinkTime, "think-time", "", "wait between steps, e.g. 2s or a random 1s-3s (overrides global.think_time)")
+	runCmd.Flags().StringVar(&pacing, "pacing", "", "least time between the starts of iterations and repeated runs, e.g. 500ms (overrides global.pacing)")
+	runCmd.Flags().StringVar(&freezeTime, "freeze-time", "", "fix the time seen by time builtins (RFC 3339 timestamp or date; the run start without a value)")
+	runCmd.Flags().Lookup("freeze-time").NoOptDefVal = "now"
+	runCmd.Flags().BoolVarP(&quiet, "quiet", "q", false, "console output lists only the summary and failures")
+	runCmd.Flags().BoolVar(&ciOutput, "ci", false, "CI-friendly console output: no colors or symbols, scenarios sorted by name, absolute timestamps")
+	runCmd.Flags().BoolVar(&noProgress, "no-progress", false, "disable live progress bars and colors on interactive terminals")
+	runCmd.Flags().StringVar(&rerunFailed, "rerun-failed", "", "run only the scenarios and steps that failed in this JSON report, with the steps they depend on")
+	runCmd.Flags().StringVar(&shardSpec, "shard", "", "run one part of the scenarios, as index/total (e.g. 2/5); every shard of a suite computes the same split")
+	runCmd.Flags().IntVar(&shardIndex, "shard-index", 0, "1-based shard to run, with --shard-total")
+	runCmd.Flags().IntVar(&shardTotal, "shard-total", 0, "number of shards the scenarios are split into")
+	runCmd.Flags().StringVar(&shardDurations, "shard-durations", "", "balance shards by the scenario durations of this JSON report instead of by count")
+	runCmd.Flags().Int64Var(&seed, "seed", 0, "seed for data sampling, shuffling and generated data (the seed of every run is reported)")
+
+	registerCompletions(runCmd, reporting.Formats...)
 }
 
 func runScenarios(cmd *cobra.Command, args []string) error {
@@ -55,46 +157,284 @@ func runScenarios(cmd *cobra.Command, args []string) error {
 		cfg = cfg.MergeEnvironment(environment)
 	}
 
+	if freezeTime != "" {
+		if _, err := config.ParseFreezeTime(freezeTime); err != nil {
+			return fmt.Errorf("invalid --freeze-time: %w", err)
+		}
+		cfg.Global.FreezeTime = freezeTime
+	}
+
+	if thinkTime != "" {
+		cfg.Global.ThinkTime = thinkTime
+	}
+	if pacing != "" {
+		cfg.Global.Pacing = pacing
+	}
+	if _, _, err := scenario.Pause(cfg.Global.ThinkTime).Range(); err != nil {
+		return fmt.Errorf("invalid think time: %w", err)
+	}
+	if _, _, err := scenario.Pause(cfg.Global.Pacing).Range(); err != nil {
+		return fmt.Errorf("invalid pacing: %w", err)
+	}
+
+	if failOn != "" {
+		cfg.Global.FailOn = failOn
+	}
+	if cfg.Global.FailOn != "" && !reporting.IsSeverity(cfg.Global.FailOn) {
+		return fmt.Errorf("invalid fail-on severity %q (expected blocker, critical, high, medium, low, minor or trivial)", cfg.Global.FailOn)
+	}
+
+	for _, url := range webhooks {
+		cfg.Webhooks = append(cfg.Webhooks, config.WebhookConfig{URL: url})
+	}
+
+	if correlationID != "" {
+		cfg.Global.CorrelationID.Enabled = true
+		cfg.Global.CorrelationID.Header = correlationID
+	}
+
+	var outputs []reporting.ReportOutput
+	for _, spec := range reports {
+		output, err := reporting.ParseReportOutput(spec)
+		if err != nil {
+			return err
+		}
+		outputs = append(outputs, output)
+	}
+
+	format := outputFormat
+	if reportTmpl != "" {
+		// Fail before running anything when the template is broken
+		if _, err := reporting.ParseReportTemplate(reportTmpl); err != nil {
+			return err
+		}
+		format = "template"
+	}
+	for _, output := range outputs {
+		if output.Format == "template" && reportTmpl == "" {
+			return fmt.Errorf("--report template=... requires --report-template")
+		}
+	}
+
+	// Progress bars and colors only make sense for console output on a terminal
+	interactive := format == "console" && outputFile == "" && !noProgress && !quiet && !ciOutput && reporting.IsTerminal(os.Stdout)
+
 	// Create reporter
 	reporterConfig := reporting.ReportConfig{
-		Format:     outputFormat,
-		OutputFile: outputFile,
-		Verbose:    viper.GetBool("verbose"),
+		Format:      format,
+		OutputFile:  outputFile,
+		Verbose:     viper.GetBool("verbose"),
+		IncludeBody: includeBody,
+		Color:       interactive && reporting.ColorEnabled(os.Stdout),
+		Quiet:       quiet,
+		CI:          ciOutput,
+		Outputs:     outputs,
+		Template:    reportTmpl,
+		FailOn:      cfg.Global.FailOn,
+		Storage:     execution.ReportStorage(cfg.Reports),
 	}
 	reporter := reporting.NewReporter(reporterConfig)
 
-	// Create execution engine
-	engine := execution.NewEngine(cfg, reporter)
+	options := execution.Options{
+		SnapshotDir:     snapshotDir,
+		UpdateSnapshots: updateSnapshots,
+		Repeat:          repeat,
+		StopOnFailure:   stopOnFailure,
+		ArtifactsDir:    artifactsDir,
+		KeepTmpDirs:     keepTmpDirs,
+		Seed:            seed,
+	}
+	if interactive {
+		options.Progress = reporting.NewProgress(os.Stdout)
+	}
 
-	// Load scenarios
-	var scenarios []*scenario.Scenario
-	for _, arg := range args {
-		stat, err := os.Stat(arg)
+	switch tracePath {
+	case "":
+	case "-":
+		options.Trace = os.Stderr
+	default:
+		traceFile, err := os.Create(tracePath)
 		if err != nil {
-			return fmt.Errorf("failed to access %s: %w", arg, err)
+			return fmt.Errorf("failed to create trace file: %w", err)
 		}
+		defer traceFile.Close()
+		options.Trace = traceFile
+	}
 
-		if stat.IsDir() {
-			dirScenarios, err := scenario.LoadScenariosFromDir(arg)
-			if err != nil {
-				return fmt.Errorf("failed to load scenarios from directory %s: %w", arg, err)
+	var coverage *openapi.Coverage
+	if coverageSpec != "" || coverageOut != "" {
+		if coverage, err = newCoverage(coverageSpec); err != nil {
+			return err
+		}
+		options.OnResponse = func(step, sent *scenario.Step, status int) {
+			if coverageSpec == "" || coverageSpec == "-" {
+				coverage.Record(step.Request.Method, step.Request.URL, status)
+			} else {
+				coverage.Record(sent.Request.Method, sent.Request.URL, status)
+			}
+		}
+	}
+
+	// Create execution engine
+	engine := execution.NewEngineWithOptions(cfg, reporter, options)
+
+	var previous *reporting.Report
+	if rerunFailed != "" {
+		previous, err = reporting.LoadReport(rerunFailed)
+		if err != nil {
+			return err
+		}
+		if previous.Passed() {
+			fmt.Printf("No failed scenarios in %s\n", rerunFailed)
+			return nil
+		}
+		if len(args) == 0 {
+			args = previous.SourceFiles()
+			if len(args) == 0 {
+				return fmt.Errorf("%s does not record scenario files, pass them as arguments", rerunFailed)
 			}
-			scenarios = append(scenarios, dirScenarios...)
-		} else {
-			sc, err := scenario.LoadScenario(arg)
+		}
+	}
+
+	// Load scenarios
+	scenarios, err := loadScenarios(args)
+	if err != nil {
+		return err
+	}
+
+	if previous != nil {
+		scenarios = scenario.FilterFailed(scenarios, previous.FailedSteps())
+		if len(scenarios) == 0 {
+			return fmt.Errorf("none of the scenarios that failed in %s were found", rerunFailed)
+		}
+	}
+
+	if len(labels) > 0 {
+		selectors := make([]scenario.LabelSelector, 0, len(labels))
+		for _, spec := range labels {
+			selector, err := scenario.ParseLabelSelector(spec)
 			if err != nil {
-				return fmt.Errorf("failed to load scenario %s: %w", arg, err)
+				return err
 			}
-			scenarios = append(scenarios, sc)
+			selectors = append(selectors, selector)
+		}
+		scenarios = scenario.FilterByLabels(scenarios, selectors)
+		if len(scenarios) == 0 {
+			return fmt.Errorf("no scenarios match --label %s", strings.Join(labels, " --label "))
+		}
+	}
+
+	if shardSpec != "" || shardTotal > 0 || shardIndex > 0 {
+		if scenarios, err = selectShard(scenarios); err != nil {
+			return err
+		}
+		if len(scenarios) == 0 {
+			fmt.Println("No scenarios in this shard")
+			return nil
 		}
 	}
 
-	if len(scenarios) == 0 {
-		return fmt.Errorf("no scenarios found")
+	if !quiet {
+		fmt.Printf("Found %d scenario(s) to execute\n", len(scenarios))
 	}
 
-	fmt.Printf("Found %d scenario(s) to execute\n", len(scenarios))
+	// Ctrl+C stops the run after the registered cleanups; a second Ctrl+C exits immediately
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
 
 	// Execute scenarios
-	return engine.ExecuteScenarios(scenarios)
+	if err := engine.ExecuteScenariosContext(ctx, scenarios); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("run interrupted")
+		}
+		return err
+	}
+
+	if coverage != nil {
+		if err := reportCoverage(coverage.Report(), coverageOut); err != nil {
+			return err
+		}
+	}
+
+	if summaryFile != "" {
+		if err := reporter.WriteSummary(summaryFile); err != nil {
+			return err
+		}
+	}
+
+	if historyPath != "" {
+		record := history.NewRecord(reporter.GetReport(), environment)
+		if err := history.Open(historyPath).Append(record); err != nil {
+			return fmt.Errorf("failed to record run history: %w", err)
+		}
+	}
+
+	if cfg.Global.FailOn != "" {
+		blocking, warnings := reporter.GetReport().SeverityGate()
+		for _, failure := range warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s failed (severity %s is below %s)\n", failure.Scenario, severityLabel(failure.Severity), cfg.Global.FailOn)
+		}
+		if len(blocking) > 0 {
+			return fmt.Errorf("%d scenario(s) of severity %s or higher failed", len(blocking), cfg.Global.FailOn)
+		}
+	}
+
+	return nil
+}
+
+// selectShard narrows the scenarios to the shard of --shard or --shard-index/--shard-total
+func selectShard(scenarios []*scenario.Scenario) ([]*scenario.Scenario, error) {
+	shard := scenario.Shard{Index: shardIndex, Total: shardTotal}
+	if shardSpec != "" {
+		if shardIndex > 0 || shardTotal > 0 {
+			return nil, fmt.Errorf("use either --shard or --shard-index/--shard-total")
+		}
+		var err error
+		if shard, err = scenario.ParseShard(shardSpec); err != nil {
+			return nil, err
+		}
+	}
+	if err := shard.Validate(); err != nil {
+		return nil, err
+	}
+
+	var durations map[string]time.Duration
+	if shardDurations != "" {
+		previous, err := reporting.LoadReport(shardDurations)
+		if err != nil {
+			return nil, err
+		}
+		durations = previous.ScenarioDurations()
+	}
+	return shard.Select(scenarios, durations), nil
+}
+
+func severityLabel(severity string) string {
+	if severity == "" {
+		return "none"
+	}
+	return severity
+}
+
+func loadScenarios(args []string) ([]*scenario.Scenario, error) {
+	// Encrypted scenarios may need the age key file of the config, also for commands without one
+	var options scenario.LoadOptions
+	if cfg, err := config.LoadConfig(viper.ConfigFileUsed()); err == nil {
+		options.AgeKeyFile = os.ExpandEnv(cfg.Encryption.AgeKeyFile)
+	}
+
+	scenarios, err := scenario.LoadScenariosWithOptions(options, args...)
+	if err != nil {
+		return nil, err
+	}
+	for _, sc := range scenarios {
+		for _, deprecation := range sc.Deprecations() {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %s\n", sc.SourceFile, deprecation)
+		}
+	}
+	return scenarios, nil
 }
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nulln0ne/fuego/pkg/schema"
+	"github.com/spf13/cobra"
+)
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema [scenario|config]",
+	Short: "Print the JSON Schema for scenario or config files",
+	Long: `Print a JSON Schema describing the scenario or configuration file format.
+
+The schema can be used by yaml-language-server (VS Code, IntelliJ) to provide
+validation and autocompletion. Reference it from the top of a scenario file:
+
+  # yaml-language-server: $schema=./fuego-scenario.schema.json
+
+Examples:
+  fuego schema                                   Print the scenario schema
+  fuego schema config                            Print the config schema
+  fuego schema scenario -o fuego-scenario.schema.json`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: []string{"scenario", "config"},
+	RunE:      runSchema,
+}
+
+var schemaOutputFile string
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+
+	schemaCmd.Flags().StringVarP(&schemaOutputFile, "output", "o", "", "output file path")
+}
+
+func runSchema(cmd *cobra.Command, args []string) error {
+	kind := "scenario"
+	if len(args) > 0 {
+		kind = args[0]
+	}
+
+	var doc map[string]interface{}
+	switch kind {
+	case "scenario":
+		doc = schema.Scenario()
+	case "config":
+		doc = schema.Config()
+	default:
+		return fmt.Errorf("unknown schema kind: %s (expected scenario or config)", kind)
+	}
+
+	data, err := schema.Marshal(doc)
+	if err != nil {
+		return fmt.Errorf("failed to marshal schema: %w", err)
+	}
+
+	if schemaOutputFile != "" {
+		return os.WriteFile(schemaOutputFile, append(data, '\n'), 0644)
+	}
+
+	fmt.Println(string(data))
+	return nil
+}
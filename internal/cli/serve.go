@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/server"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve an HTTP API to start runs and stream their events",
+	Long: `Serve an HTTP API that starts runs of the scenarios under --root and streams
+their events while they execute, so developer portals and other tools can
+trigger suites on demand and show live progress.
+
+  POST   /api/v1/runs             start a run, e.g. {"paths": ["orders/"], "environment": "staging"}
+  GET    /api/v1/runs             list runs, newest first
+  GET    /api/v1/runs/{id}        status of a run, with the report once finished
+  GET    /api/v1/runs/{id}/events events as Server-Sent Events (the same events as --format jsonl)
+  DELETE /api/v1/runs/{id}        cancel a run
+
+Request paths are relative to --root and, symlinks followed, must stay inside
+it; files that scenarios include or read data from are not checked. The config
+file is read for every run. Set --token (or FUEGO_SERVE_TOKEN) to require
+"Authorization: Bearer <token>" on API requests.
+
+Examples:
+  fuego serve --root tests/ --addr :8080
+  curl -X POST localhost:8080/api/v1/runs -d '{"paths": ["smoke.yaml"]}'
+  curl -N localhost:8080/api/v1/runs/<id>/events`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+var (
+	serveAddr    string
+	serveRoot    string
+	serveToken   string
+	serveMaxRuns int
+	serveHistory int
+)
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+
+	serveCmd.Flags().StringVar(&serveAddr, "addr", "localhost:8080", "address to listen on")
+	serveCmd.Flags().StringVar(&serveRoot, "root", ".", "directory request paths are resolved in")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "bearer token API requests must send (default $FUEGO_SERVE_TOKEN)")
+	serveCmd.Flags().IntVar(&serveMaxRuns, "max-runs", 2, "runs executing at once; more are queued")
+	serveCmd.Flags().IntVar(&serveHistory, "history", 100, "finished runs kept in memory")
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	if info, err := os.Stat(serveRoot); err != nil || !info.IsDir() {
+		return fmt.Errorf("root %s is not a directory", serveRoot)
+	}
+	token := serveToken
+	if token == "" {
+		token = os.Getenv("FUEGO_SERVE_TOKEN")
+	}
+
+	api := server.New(server.Options{
+		Root:       serveRoot,
+		ConfigFile: viper.ConfigFileUsed(),
+		Token:      token,
+		MaxRuns:    serveMaxRuns,
+		History:    serveHistory,
+	})
+	httpServer := &http.Server{Addr: serveAddr, Handler: api.Handler(), ReadHeaderTimeout: 10 * time.Second}
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- httpServer.ListenAndServe()
+	}()
+
+	fmt.Printf("Serving scenarios from %s on http://%s (press Ctrl+C to stop)\n", serveRoot, serveAddr)
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stop)
+
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("server failed: %w", err)
+		}
+	case <-stop:
+	}
+
+	// Cancelling the runs first ends their event streams, so shutdown does not wait for them
+	api.Cancel()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return httpServer.Shutdown(ctx)
+}
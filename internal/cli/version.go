@@ -0,0 +1,137 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/nulln0ne/fuego/pkg/version"
+	"github.com/spf13/cobra"
+)
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the version and build information",
+	Long: `Print the fuego version, the commit and date it was built from, and the Go
+version and platform. Release builds get these from ldflags (see make build);
+go install builds report the module version and VCS stamp.
+
+Examples:
+  fuego version                  fuego v1.4.0 (commit 1a2b3c4, built ..., go1.24.6 linux/amd64)
+  fuego version --short          v1.4.0
+  fuego version --format json    Build information as JSON`,
+	Args: cobra.NoArgs,
+	RunE: runVersion,
+}
+
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Replace the fuego binary with the latest release",
+	Long: `Download the release binary for this platform from GitHub and replace the
+running fuego binary with it. The download is verified against the release's
+checksums.txt; releases without one are not installed. Binaries installed with
+Homebrew or Scoop are left to the package manager.
+
+Releases of an internal fork or mirror are fetched with --repo and, for GitHub
+Enterprise, --api-url. GITHUB_TOKEN is sent for private repositories.
+
+Examples:
+  fuego self-update                      Update to the latest release
+  fuego self-update --check              Only report whether an update is available
+  fuego self-update --version v1.3.2     Install a specific release
+  fuego self-update --repo acme/fuego --api-url https://github.acme.com/api/v3`,
+	Args: cobra.NoArgs,
+	RunE: runSelfUpdate,
+}
+
+var (
+	versionShort  bool
+	versionFormat string
+
+	updateCheck   bool
+	updateVersion string
+	updateForce   bool
+	updateRepo    string
+	updateAPIURL  string
+)
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(selfUpdateCmd)
+
+	info := version.Get()
+	rootCmd.Version = info.Version
+	rootCmd.SetVersionTemplate(info.String() + "\n")
+
+	versionCmd.Flags().BoolVar(&versionShort, "short", false, "print only the version")
+	versionCmd.Flags().StringVarP(&versionFormat, "format", "f", "text", "output format (text, json)")
+
+	selfUpdateCmd.Flags().BoolVar(&updateCheck, "check", false, "only check whether a newer release exists")
+	selfUpdateCmd.Flags().StringVar(&updateVersion, "version", "", "release tag to install (default latest)")
+	selfUpdateCmd.Flags().BoolVar(&updateForce, "force", false, "install even when the release is not newer, or the binary is managed by a package manager")
+	selfUpdateCmd.Flags().StringVar(&updateRepo, "repo", version.DefaultRepository, "GitHub repository to fetch releases from")
+	selfUpdateCmd.Flags().StringVar(&updateAPIURL, "api-url", version.DefaultAPIURL, "GitHub API URL")
+}
+
+func runVersion(cmd *cobra.Command, args []string) error {
+	info := version.Get()
+	switch {
+	case versionShort:
+		fmt.Println(info.Version)
+	case versionFormat == "json":
+		data, err := json.MarshalIndent(info, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	case versionFormat == "text":
+		fmt.Println(info)
+	default:
+		return fmt.Errorf("unknown format: %s (expected text or json)", versionFormat)
+	}
+	return nil
+}
+
+func runSelfUpdate(cmd *cobra.Command, args []string) error {
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate the fuego binary: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(executable); err == nil {
+		executable = resolved
+	}
+	if manager := version.PackageManager(executable); manager != "" && !updateForce && !updateCheck {
+		upgrade := map[string]string{"brew": "brew upgrade fuego", "scoop": "scoop update fuego"}[manager]
+		return fmt.Errorf("%s was installed with %s, update it with `%s` (or use --force)", executable, manager, upgrade)
+	}
+
+	updater := &version.Updater{APIURL: updateAPIURL, Repository: updateRepo, Token: os.Getenv("GITHUB_TOKEN")}
+	ctx := context.Background()
+	release, err := updater.Release(ctx, updateVersion)
+	if err != nil {
+		return fmt.Errorf("failed to find the release: %w", err)
+	}
+
+	current := version.Get().Version
+	newer := version.IsNewer(current, release.Tag)
+	if updateCheck {
+		if newer {
+			fmt.Printf("fuego %s is available (installed: %s)\n", release.Tag, current)
+		} else {
+			fmt.Printf("fuego %s is up to date\n", current)
+		}
+		return nil
+	}
+	if !newer && updateVersion == "" && !updateForce {
+		fmt.Printf("fuego %s is up to date\n", current)
+		return nil
+	}
+
+	if err := updater.Install(ctx, release, executable); err != nil {
+		return err
+	}
+	fmt.Printf("Updated %s from %s to %s\n", executable, current, release.Tag)
+	return nil
+}
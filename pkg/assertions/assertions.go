@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/nulln0ne/fuego/pkg/snapshot"
 	"github.com/nulln0ne/fuego/pkg/variables"
 	"github.com/xeipuuv/gojsonschema"
 )
@@ -24,7 +25,10 @@ type Result struct {
 }
 
 type Engine struct {
-	varContext *variables.Context
+	varContext   *variables.Context
+	snapshots    *snapshot.Store
+	snapshotPath []string
+	responses    func(name string) (interface{}, bool)
 }
 
 func NewEngine(varContext *variables.Context) *Engine {
@@ -33,6 +37,14 @@ func NewEngine(varContext *variables.Context) *Engine {
 	}
 }
 
+// WithSnapshots enables snapshot assertions, naming snapshot files after the given segments
+// (typically scenario and step name)
+func (e *Engine) WithSnapshots(store *snapshot.Store, segments ...string) *Engine {
+	e.snapshots = store
+	e.snapshotPath = segments
+	return e
+}
+
 func (e *Engine) RunAssertions(assertions []scenario.Assertion, response interface{}) ([]Result, error) {
 	results := make([]Result, 0, len(assertions))
 
@@ -59,6 +71,17 @@ func (e *Engine) runAssertion(assertion scenario.Assertion, response interface{}
 		result.Duration = time.Since(startTime)
 	}()
 
+	switch assertion.Type {
+	case "snapshot":
+		return e.runSnapshotAssertion(assertion, response, result)
+	case "compare":
+		return e.runCompareAssertion(assertion, response, result)
+	case "certificate":
+		if assertion.Field == "covers" {
+			return e.runCertificateCoverage(assertion, response, result)
+		}
+	}
+
 	// Interpolate expected value if it's a string template
 	expectedValue := assertion.Value
 	if expectedStr, ok := assertion.Value.(string); ok {
@@ -136,13 +159,82 @@ func (e *Engine) extractValue(assertion scenario.Assertion, response interface{}
 		return e.extractResponseTime(response)
 	case "size":
 		return e.extractResponseSize(response)
+	case "charset":
+		return e.extractCharset(response)
+	case "content_encoding", "encoded_size":
+		return e.extractEncoding(response, assertion.Type)
+	case "compression_ratio":
+		return e.extractCompressionRatio(response)
+	case "certificate":
+		return e.extractCertificate(response, assertion.Field)
+	case "error":
+		return e.extractTransportError(response, assertion.Field)
+	case "stream":
+		return e.extractStream(response, assertion.Field)
+	case "parallel":
+		return e.extractParallel(response, assertion.Field)
+	case "steps":
+		return e.extractSteps(response, assertion.Field)
+	case "step":
+		return e.extractStep(response, assertion.Field)
 	case "json_schema":
 		return e.extractBody(response) // For JSON schema validation, we validate the entire body
+	case "attempts", "retries", "faults":
+		return e.extractRetryStat(response, assertion.Type)
+	case "variable":
+		return e.extractVariable(assertion.Field)
 	default:
 		return nil, fmt.Errorf("unsupported assertion type: %s", assertion.Type)
 	}
 }
 
+// runSnapshotAssertion compares the response body with a stored snapshot. The assertion value
+// is either true or a map with optional "name" and "ignore" (list of JSON paths) keys.
+func (e *Engine) runSnapshotAssertion(assertion scenario.Assertion, response interface{}, result Result) (Result, error) {
+	if e.snapshots == nil {
+		result.Passed = false
+		result.Message = "snapshot assertions are not enabled"
+		return result, nil
+	}
+
+	name := assertion.Field
+	var ignore []string
+	if options, ok := assertion.Value.(map[string]interface{}); ok {
+		if optionName, ok := options["name"].(string); ok && name == "" {
+			name = optionName
+		}
+		switch paths := options["ignore"].(type) {
+		case []interface{}:
+			for _, path := range paths {
+				ignore = append(ignore, fmt.Sprintf("%v", path))
+			}
+		case []string:
+			ignore = paths
+		}
+	}
+
+	body, err := e.extractBody(response)
+	if err != nil {
+		result.Passed = false
+		result.Message = fmt.Sprintf("Failed to extract value: %v", err)
+		return result, nil
+	}
+
+	segments := append(append([]string{}, e.snapshotPath...), name)
+	passed, message, err := e.snapshots.Match(e.snapshots.Path(segments...), fmt.Sprintf("%v", body), ignore)
+	if err != nil {
+		return result, err
+	}
+
+	result.Passed = passed
+	result.Message = message
+	if assertion.Description != "" {
+		result.Message = assertion.Description + ": " + message
+	}
+
+	return result, nil
+}
+
 func (e *Engine) extractStatusCode(response interface{}) (interface{}, error) {
 	respMap, ok := response.(map[string]interface{})
 	if !ok {
@@ -274,7 +366,87 @@ func (e *Engine) extractResponseSize(response interface{}) (interface{}, error)
 	return size, nil
 }
 
+// extractRetryStat reads how often a retried step was attempted, retried or hit by an injected
+// fault; the engine evaluates these assertions once all attempts are done
+func (e *Engine) extractRetryStat(response interface{}, stat string) (interface{}, error) {
+	respMap, ok := response.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("invalid response format")
+	}
+	value, exists := respMap[stat]
+	if !exists {
+		return nil, fmt.Errorf("%s is only known after all attempts of a step", stat)
+	}
+	return value, nil
+}
+
+// extractVariable reads a variable captured or computed earlier instead of the response; a dotted
+// field reaches into maps and lists, e.g. order.items.0.price
+func (e *Engine) extractVariable(field string) (interface{}, error) {
+	if field == "" {
+		return nil, fmt.Errorf("variable assertions need the variable name as field")
+	}
+	name, path, _ := strings.Cut(field, ".")
+	value, exists := e.varContext.Get(name)
+	if !exists {
+		return nil, fmt.Errorf("variable %s is not set", name)
+	}
+	return e.getNestedValue(value, path)
+}
+
+func (e *Engine) extractCharset(response interface{}) (interface{}, error) {
+	respMap, ok := response.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("response is not a map")
+	}
+
+	charset, exists := respMap["charset"]
+	if !exists {
+		return nil, fmt.Errorf("charset not found in response")
+	}
+
+	return charset, nil
+}
+
+// extractEncoding reads the Content-Encoding of the response ("" when the body was sent as is)
+// or the size of the body as it was sent
+func (e *Engine) extractEncoding(response interface{}, field string) (interface{}, error) {
+	respMap, ok := response.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("response is not a map")
+	}
+
+	value, exists := respMap[field]
+	if !exists {
+		return nil, fmt.Errorf("%s not found in response", field)
+	}
+
+	return value, nil
+}
+
+// extractCompressionRatio divides the decompressed body size by the size it was sent with, so
+// 4 means the body shrank to a quarter; bodies sent as is, and empty bodies, have a ratio of 1
+func (e *Engine) extractCompressionRatio(response interface{}) (interface{}, error) {
+	respMap, ok := response.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("response is not a map")
+	}
+
+	size, sizeOK := respMap["size"].(int64)
+	encodedSize, encodedOK := respMap["encoded_size"].(int64)
+	if !sizeOK || !encodedOK {
+		return nil, fmt.Errorf("body sizes not found in response")
+	}
+	if encodedSize == 0 {
+		return 1.0, nil
+	}
+
+	return float64(size) / float64(encodedSize), nil
+}
+
 func (e *Engine) getNestedValue(data interface{}, path string) (interface{}, error) {
+	// Accept JSONPath-style roots ("$.user.id")
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
 	if path == "" {
 		return data, nil
 	}
@@ -341,7 +513,14 @@ func (e *Engine) compare(actual, expected interface{}, operator string) (bool, s
 		return e.compareLength(actual, expected)
 	case "json_schema":
 		return e.compareJSONSchema(actual, expected)
+	case "within":
+		return e.compareWithin(actual, expected)
+	case "timezone":
+		return e.compareTimezone(actual, expected)
 	default:
+		if fn, exists := lookupOperator(operator); exists {
+			return fn(actual, expected)
+		}
 		return false, fmt.Sprintf("unsupported operator: %s", operator)
 	}
 }
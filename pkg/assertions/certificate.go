@@ -0,0 +1,75 @@
+package assertions
+
+import (
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+)
+
+// extractCertificate reads a field of the server certificate: subject, issuer, sans, not_before,
+// not_after or days_until_expiry
+func (e *Engine) extractCertificate(response interface{}, field string) (interface{}, error) {
+	certificate, err := responseCertificate(response)
+	if err != nil {
+		return nil, err
+	}
+	if field == "" {
+		return nil, fmt.Errorf("certificate assertions need a field such as days_until_expiry or issuer")
+	}
+	value, exists := certificate[field]
+	if !exists {
+		return nil, fmt.Errorf("unknown certificate field %s", field)
+	}
+	return value, nil
+}
+
+// runCertificateCoverage passes when the SANs of the server certificate cover the host named by
+// the assertion value, with wildcards matching a single label as TLS clients do
+func (e *Engine) runCertificateCoverage(assertion scenario.Assertion, response interface{}, result Result) (Result, error) {
+	host, err := e.varContext.InterpolateString(fmt.Sprintf("%v", assertion.Value))
+	if err != nil {
+		result.Message = fmt.Sprintf("Failed to interpolate expected value: %v", err)
+		return result, nil
+	}
+	certificate, err := responseCertificate(response)
+	if err != nil {
+		result.Message = fmt.Sprintf("Failed to extract value: %v", err)
+		return result, nil
+	}
+	sans, _ := certificate["sans"].([]string)
+
+	result.Expected = host
+	result.Actual = sans
+	for _, san := range sans {
+		if sanCovers(san, host) {
+			result.Passed = true
+			result.Message = fmt.Sprintf("certificate covers %s with %s", host, san)
+			return result, nil
+		}
+	}
+	result.Message = fmt.Sprintf("certificate does not cover %s, its SANs are %s", host, strings.Join(sans, ", "))
+	return result, nil
+}
+
+func responseCertificate(response interface{}) (map[string]interface{}, error) {
+	respMap, ok := response.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("response is not a map")
+	}
+	certificate, ok := respMap["certificate"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("response was not received over TLS")
+	}
+	return certificate, nil
+}
+
+func sanCovers(san, host string) bool {
+	san, host = strings.ToLower(san), strings.ToLower(strings.TrimSuffix(host, "."))
+	if net.ParseIP(host) != nil || !strings.HasPrefix(san, "*.") {
+		return san == host
+	}
+	label, parent, found := strings.Cut(host, ".")
+	return found && label != "" && parent == san[2:]
+}
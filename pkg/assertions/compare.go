@@ -0,0 +1,115 @@
+package assertions
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/diff"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+)
+
+// WithResponses enables compare assertions, which look up the responses of earlier steps (stored
+// with store_as) by name
+func (e *Engine) WithResponses(lookup func(name string) (interface{}, bool)) *Engine {
+	e.responses = lookup
+	return e
+}
+
+// runCompareAssertion compares the response body with the one stored under a name by an earlier
+// step. The value is the name, or a map with "response" and optional "ignore" (list of JSON
+// paths) keys; field narrows both bodies to a JSON path. eq (the default) expects no
+// differences, ne expects some.
+func (e *Engine) runCompareAssertion(assertion scenario.Assertion, response interface{}, result Result) (Result, error) {
+	fail := func(message string) (Result, error) {
+		result.Passed = false
+		result.Message = message
+		return result, nil
+	}
+
+	var name string
+	var ignore []string
+	switch value := assertion.Value.(type) {
+	case string:
+		name = value
+	case map[string]interface{}:
+		name, _ = value["response"].(string)
+		switch paths := value["ignore"].(type) {
+		case []interface{}:
+			for _, path := range paths {
+				ignore = append(ignore, fmt.Sprintf("%v", path))
+			}
+		case []string:
+			ignore = paths
+		}
+	}
+	if name == "" {
+		return fail("compare assertions need the name of a stored response as value")
+	}
+	if e.responses == nil {
+		return fail("compare assertions are not enabled")
+	}
+	stored, exists := e.responses(name)
+	if !exists {
+		return fail(fmt.Sprintf("no response stored as %s", name))
+	}
+
+	before, err := e.comparable(stored, assertion.Field)
+	if err != nil {
+		return fail(fmt.Sprintf("Failed to extract value from %s: %v", name, err))
+	}
+	current, err := e.comparable(response, assertion.Field)
+	if err != nil {
+		return fail(fmt.Sprintf("Failed to extract value: %v", err))
+	}
+
+	differences := diff.Compare(before, current, ignore)
+	result.Expected = name
+	result.Actual = len(differences)
+
+	var message string
+	switch assertion.Operator {
+	case "", "eq", "equals", "==":
+		result.Passed = len(differences) == 0
+		message = fmt.Sprintf("response matches %s", name)
+		if !result.Passed {
+			described := make([]string, len(differences))
+			for i, difference := range differences {
+				described[i] = difference.String()
+			}
+			message = fmt.Sprintf("response differs from %s (A) in %d place(s): %s", name, len(differences), strings.Join(described, "; "))
+		}
+	case "ne", "not_equals", "!=":
+		result.Passed = len(differences) > 0
+		message = fmt.Sprintf("response differs from %s in %d place(s)", name, len(differences))
+		if !result.Passed {
+			message = fmt.Sprintf("response is unchanged from %s", name)
+		}
+	default:
+		return fail(fmt.Sprintf("unsupported operator for compare: %s", assertion.Operator))
+	}
+
+	result.Message = message
+	if assertion.Description != "" {
+		result.Message = assertion.Description + ": " + message
+	}
+	return result, nil
+}
+
+// comparable decodes a JSON response body, narrowed to path when set; other bodies compare as text
+func (e *Engine) comparable(response interface{}, path string) (interface{}, error) {
+	body, err := e.extractBody(response)
+	if err != nil {
+		return nil, err
+	}
+	text := fmt.Sprintf("%v", body)
+
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(text), &decoded); err != nil {
+		if path != "" {
+			return nil, fmt.Errorf("body is not JSON")
+		}
+		return text, nil
+	}
+	return e.getNestedValue(decoded, path)
+}
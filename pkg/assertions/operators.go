@@ -0,0 +1,52 @@
+package assertions
+
+import (
+	"fmt"
+	"sync"
+)
+
+// OperatorFunc compares an extracted value with the expected value and describes the outcome
+type OperatorFunc func(actual, expected interface{}) (bool, string)
+
+var builtinOperators = map[string]bool{
+	"eq": true, "equals": true, "==": true,
+	"ne": true, "not_equals": true, "!=": true,
+	"gt": true, ">": true, "gte": true, ">=": true,
+	"lt": true, "<": true, "lte": true, "<=": true,
+	"contains": true, "not_contains": true,
+	"matches": true, "regex": true,
+	"starts_with": true, "ends_with": true,
+	"length": true, "json_schema": true,
+	"within": true, "timezone": true,
+}
+
+var (
+	operatorsMu sync.RWMutex
+	operators   = make(map[string]OperatorFunc)
+)
+
+// RegisterOperator adds a custom assertion operator. Built-in operators cannot be replaced.
+func RegisterOperator(name string, fn OperatorFunc) error {
+	if name == "" || fn == nil {
+		return fmt.Errorf("operator name and function are required")
+	}
+	if builtinOperators[name] {
+		return fmt.Errorf("operator %s is built in", name)
+	}
+
+	operatorsMu.Lock()
+	defer operatorsMu.Unlock()
+	if _, exists := operators[name]; exists {
+		return fmt.Errorf("operator %s is already registered", name)
+	}
+	operators[name] = fn
+
+	return nil
+}
+
+func lookupOperator(name string) (OperatorFunc, bool) {
+	operatorsMu.RLock()
+	defer operatorsMu.RUnlock()
+	fn, exists := operators[name]
+	return fn, exists
+}
@@ -0,0 +1,28 @@
+package assertions
+
+import (
+	"fmt"
+	"strings"
+)
+
+// extractParallel reads a stat of the copies of a parallel step: count, succeeded (2xx),
+// errors, statuses, status_counts.<status> or duration in milliseconds
+func (e *Engine) extractParallel(response interface{}, field string) (interface{}, error) {
+	respMap, ok := response.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("response is not a map")
+	}
+	stats, ok := respMap["parallel"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("parallel assertions need a step with parallel copies")
+	}
+	if field == "" {
+		return nil, fmt.Errorf("parallel assertions need a field such as succeeded or status_counts.201")
+	}
+	value, err := e.getNestedValue(stats, field)
+	if err != nil && strings.HasPrefix(field, "status_counts.") {
+		// No copy got the status
+		return 0, nil
+	}
+	return value, err
+}
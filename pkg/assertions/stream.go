@@ -0,0 +1,22 @@
+package assertions
+
+import (
+	"fmt"
+)
+
+// extractStream reads a field of a streamed response: first_chunk and duration in
+// milliseconds, chunk_count, ended, or chunks.N for the content of chunk N (from 0)
+func (e *Engine) extractStream(response interface{}, field string) (interface{}, error) {
+	respMap, ok := response.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("response is not a map")
+	}
+	stream, ok := respMap["stream"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("the response was not read as a stream; set stream on the request")
+	}
+	if field == "" {
+		return nil, fmt.Errorf("stream assertions need a field such as first_chunk, chunk_count or chunks.0")
+	}
+	return e.getNestedValue(stream, field)
+}
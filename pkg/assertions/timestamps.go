@@ -0,0 +1,201 @@
+package assertions
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timestampLayouts are tried in order when parsing a timestamp; layouts without a zone are read in
+// the location given by the assertion, UTC by default
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02 15:04:05.999999999",
+	time.RFC1123,
+	time.RFC1123Z,
+	time.RFC850,
+	time.ANSIC,
+	"2006-01-02",
+}
+
+// parseTimestamp reads RFC 3339 and ISO 8601 variants, HTTP dates and unix seconds or
+// milliseconds; numbers above 1e12 are taken as milliseconds
+func parseTimestamp(value interface{}, location *time.Location) (time.Time, error) {
+	var seconds float64
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case int:
+		seconds = float64(v)
+	case int64:
+		seconds = float64(v)
+	case float64:
+		seconds = v
+	case string:
+		text := strings.TrimSpace(v)
+		if number, err := strconv.ParseFloat(text, 64); err == nil {
+			seconds = number
+			break
+		}
+		for _, layout := range timestampLayouts {
+			if t, err := time.ParseInLocation(layout, text, location); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("%q is not a recognised timestamp", v)
+	default:
+		return time.Time{}, fmt.Errorf("%v is not a timestamp", value)
+	}
+
+	if math.Abs(seconds) > 1e12 {
+		seconds /= 1000
+	}
+	whole, fraction := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(fraction*1e9)).UTC(), nil
+}
+
+// parseTolerance reads a duration such as 30s or 5m, or a number of seconds
+func parseTolerance(value interface{}) (time.Duration, error) {
+	switch v := value.(type) {
+	case int:
+		return time.Duration(v) * time.Second, nil
+	case float64:
+		return time.Duration(v * float64(time.Second)), nil
+	case string:
+		if seconds, err := strconv.ParseFloat(v, 64); err == nil {
+			return time.Duration(seconds * float64(time.Second)), nil
+		}
+		return time.ParseDuration(v)
+	}
+	return 0, fmt.Errorf("tolerance %v must be a duration or a number of seconds", value)
+}
+
+// parseLocation reads an IANA zone name, UTC, Z or a fixed offset such as +02:00
+func parseLocation(name string) (*time.Location, error) {
+	switch strings.ToUpper(name) {
+	case "", "UTC", "Z":
+		return time.UTC, nil
+	case "LOCAL":
+		return time.Local, nil
+	}
+	if name[0] == '+' || name[0] == '-' {
+		t, err := time.Parse("-07:00", name)
+		if err != nil {
+			if t, err = time.Parse("-0700", name); err != nil {
+				return nil, fmt.Errorf("invalid UTC offset %q", name)
+			}
+		}
+		_, offset := t.Zone()
+		return time.FixedZone(name, offset), nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown timezone %q", name)
+	}
+	return location, nil
+}
+
+// compareWithin passes when a timestamp is no further than a tolerance from the current time.
+// The expected value is the tolerance, or a map with max, of (a reference timestamp used instead
+// of now) and timezone (the location of timestamps without an offset).
+func (e *Engine) compareWithin(actual, expected interface{}) (bool, string) {
+	tolerance := expected
+	location := time.UTC
+	reference := time.Now()
+	referenceName := "now"
+
+	if options, ok := expected.(map[string]interface{}); ok {
+		tolerance = options["max"]
+		if zone, ok := options["timezone"]; ok {
+			parsed, err := parseLocation(fmt.Sprintf("%v", zone))
+			if err != nil {
+				return false, err.Error()
+			}
+			location = parsed
+		}
+		if of, ok := options["of"]; ok {
+			if text, ok := of.(string); ok && e.varContext != nil {
+				interpolated, err := e.varContext.InterpolateString(text)
+				if err != nil {
+					return false, fmt.Sprintf("failed to interpolate reference timestamp: %v", err)
+				}
+				of = interpolated
+			}
+			parsed, err := parseTimestamp(of, location)
+			if err != nil {
+				return false, fmt.Sprintf("invalid reference timestamp: %v", err)
+			}
+			reference = parsed
+			referenceName = parsed.Format(time.RFC3339Nano)
+		}
+	}
+
+	max, err := parseTolerance(tolerance)
+	if err != nil {
+		return false, err.Error()
+	}
+	t, err := parseTimestamp(actual, location)
+	if err != nil {
+		return false, err.Error()
+	}
+
+	skew := t.Sub(reference)
+	direction := "ahead of"
+	if skew < 0 {
+		skew = -skew
+		direction = "behind"
+	}
+	skew = skew.Round(time.Millisecond)
+	if skew <= max {
+		return true, fmt.Sprintf("timestamp %v is within %s of %s", actual, max, referenceName)
+	}
+	return false, fmt.Sprintf("timestamp %v is %s %s %s, more than %s", actual, skew, direction, referenceName, max)
+}
+
+// compareTimezone passes when a timestamp carries the UTC offset of the expected zone at that
+// instant; timestamps without an offset never match
+func (e *Engine) compareTimezone(actual, expected interface{}) (bool, string) {
+	location, err := parseLocation(fmt.Sprintf("%v", expected))
+	if err != nil {
+		return false, err.Error()
+	}
+
+	if _, isString := actual.(string); !isString {
+		return false, fmt.Sprintf("timestamp %v has no UTC offset", actual)
+	}
+	if _, err := strconv.ParseFloat(fmt.Sprintf("%v", actual), 64); err == nil {
+		return false, fmt.Sprintf("timestamp %v has no UTC offset", actual)
+	}
+
+	// Zone-less timestamps are parsed in a location no real zone uses, so they can be told apart
+	sentinel := time.FixedZone("none", -(24*3600 - 1))
+	t, err := parseTimestamp(actual, sentinel)
+	if err != nil {
+		return false, err.Error()
+	}
+	if t.Location() == sentinel {
+		return false, fmt.Sprintf("timestamp %v has no UTC offset", actual)
+	}
+
+	_, offset := t.Zone()
+	_, want := t.In(location).Zone()
+	if offset == want {
+		return true, fmt.Sprintf("timestamp %v is in timezone %v", actual, expected)
+	}
+	return false, fmt.Sprintf("expected timestamp in timezone %v (UTC%s) but got UTC%s", expected, formatOffset(want), formatOffset(offset))
+}
+
+func formatOffset(seconds int) string {
+	sign := "+"
+	if seconds < 0 {
+		sign = "-"
+		seconds = -seconds
+	}
+	return fmt.Sprintf("%s%02d:%02d", sign, seconds/3600, seconds/60%60)
+}
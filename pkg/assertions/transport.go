@@ -0,0 +1,26 @@
+package assertions
+
+import (
+	"fmt"
+)
+
+// extractTransportError reads a field of the error of a request that got no response: message
+// (the default), kind or timeout. A request that got a response fails the assertion.
+func (e *Engine) extractTransportError(response interface{}, field string) (interface{}, error) {
+	respMap, ok := response.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("response is not a map")
+	}
+	transportErr, ok := respMap["error"].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a transport error but the request got a response with status %v", respMap["status_code"])
+	}
+	if field == "" {
+		field = "message"
+	}
+	value, exists := transportErr[field]
+	if !exists {
+		return nil, fmt.Errorf("unknown error field %s, use message, kind or timeout", field)
+	}
+	return value, nil
+}
@@ -0,0 +1,51 @@
+package assertions
+
+import (
+	"fmt"
+	"strings"
+)
+
+// extractSteps reads a total of the step results of a scenario: count, passed, failed, skipped,
+// max_duration, total_duration or slowest
+func (e *Engine) extractSteps(response interface{}, field string) (interface{}, error) {
+	totals, err := summaryPart(response, "steps")
+	if err != nil {
+		return nil, err
+	}
+	value, exists := totals[field]
+	if !exists {
+		return nil, fmt.Errorf("unknown steps field %q", field)
+	}
+	return value, nil
+}
+
+// extractStep reads the status, duration or runs of a step by name, e.g. "Create order.status"
+func (e *Engine) extractStep(response interface{}, field string) (interface{}, error) {
+	steps, err := summaryPart(response, "step")
+	if err != nil {
+		return nil, err
+	}
+	dot := strings.LastIndex(field, ".")
+	if dot < 0 {
+		return nil, fmt.Errorf("step assertions need a field such as %q", "Create order.status")
+	}
+	name, attribute := field[:dot], field[dot+1:]
+	step, exists := steps[name].(map[string]interface{})
+	if !exists {
+		return nil, fmt.Errorf("no step named %q ran", name)
+	}
+	value, exists := step[attribute]
+	if !exists {
+		return nil, fmt.Errorf("unknown step field %q, use status, duration or runs", attribute)
+	}
+	return value, nil
+}
+
+func summaryPart(response interface{}, part string) (map[string]interface{}, error) {
+	respMap, _ := response.(map[string]interface{})
+	summary, ok := respMap[part].(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%s assertions are only available in the verify block of a scenario", part)
+	}
+	return summary, nil
+}
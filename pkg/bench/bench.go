@@ -0,0 +1,186 @@
+// Package bench compares the step latency distributions of a benchmark run with a stored
+// baseline. A step regresses when its median grew by more than a threshold and the difference is
+// statistically significant (two-sided Mann-Whitney U test), so noise between runs does not fail
+// performance gates.
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/reporting"
+)
+
+// Baseline holds the latency samples of every step of a reference run
+type Baseline struct {
+	CreatedAt time.Time                  `json:"created_at"`
+	Runs      int                        `json:"runs"`
+	Steps     map[string][]time.Duration `json:"steps"` // keyed by "scenario / step"
+}
+
+// Collect groups the latencies of executed steps by scenario and logical step name
+func Collect(report *reporting.Report) map[string][]time.Duration {
+	samples := make(map[string][]time.Duration)
+	for _, scenario := range report.Scenarios {
+		if scenario.Scenario == nil {
+			continue
+		}
+		for _, step := range scenario.Steps {
+			if step.Step == nil || step.Response == nil || step.Status == "skipped" {
+				continue
+			}
+			key := scenario.Scenario.Name + " / " + step.LogicalName()
+			samples[key] = append(samples[key], step.Latency())
+		}
+	}
+	return samples
+}
+
+// NewBaseline builds a baseline from the report of a benchmark run
+func NewBaseline(report *reporting.Report, runs int) Baseline {
+	return Baseline{CreatedAt: time.Now().UTC(), Runs: runs, Steps: Collect(report)}
+}
+
+// LoadBaseline reads a baseline file
+func LoadBaseline(path string) (*Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return nil, fmt.Errorf("failed to parse baseline %s: %w", path, err)
+	}
+	return &baseline, nil
+}
+
+// Save writes the baseline as JSON
+func (b Baseline) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal baseline: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write baseline %s: %w", path, err)
+	}
+	return nil
+}
+
+// Comparison is the verdict for one step
+type Comparison struct {
+	Step     string                 `json:"step"`
+	Baseline reporting.LatencyStats `json:"baseline"`
+	Current  reporting.LatencyStats `json:"current"`
+	Change   float64                `json:"change"`  // relative change of the median, 0.1 = +10%
+	PValue   float64                `json:"p_value"` // probability of a difference this large by chance
+	Verdict  string                 `json:"verdict"` // regression, improvement, unchanged, new
+}
+
+// Compare compares every step of the current run with the baseline. A step is a regression when
+// its median grew by more than threshold (0.1 = 10%) with a p-value below alpha, and an
+// improvement when it shrank by as much with the same significance.
+func Compare(baseline Baseline, current map[string][]time.Duration, threshold, alpha float64) []Comparison {
+	names := make([]string, 0, len(current))
+	for name := range current {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	comparisons := make([]Comparison, 0, len(names))
+	for _, name := range names {
+		comparison := Comparison{Step: name, Current: reporting.NewLatencyStats(name, current[name]), PValue: 1}
+
+		before, exists := baseline.Steps[name]
+		if !exists || len(before) == 0 {
+			comparison.Verdict = "new"
+			comparisons = append(comparisons, comparison)
+			continue
+		}
+
+		comparison.Baseline = reporting.NewLatencyStats(name, before)
+		if comparison.Baseline.P50 > 0 {
+			comparison.Change = float64(comparison.Current.P50)/float64(comparison.Baseline.P50) - 1
+		}
+		comparison.PValue = MannWhitney(before, current[name])
+
+		significant := comparison.PValue < alpha
+		switch {
+		case significant && comparison.Change > threshold:
+			comparison.Verdict = "regression"
+		case significant && comparison.Change < -threshold:
+			comparison.Verdict = "improvement"
+		default:
+			comparison.Verdict = "unchanged"
+		}
+		comparisons = append(comparisons, comparison)
+	}
+	return comparisons
+}
+
+// Regressions returns the comparisons with a regression verdict
+func Regressions(comparisons []Comparison) []Comparison {
+	var regressions []Comparison
+	for _, comparison := range comparisons {
+		if comparison.Verdict == "regression" {
+			regressions = append(regressions, comparison)
+		}
+	}
+	return regressions
+}
+
+// MannWhitney returns the two-sided p-value of the Mann-Whitney U test for two samples, using the
+// normal approximation with tie correction. Samples too small to test return 1.
+func MannWhitney(a, b []time.Duration) float64 {
+	n1, n2 := float64(len(a)), float64(len(b))
+	if len(a) < 2 || len(b) < 2 {
+		return 1
+	}
+
+	type ranked struct {
+		value time.Duration
+		first bool
+	}
+	all := make([]ranked, 0, len(a)+len(b))
+	for _, v := range a {
+		all = append(all, ranked{value: v, first: true})
+	}
+	for _, v := range b {
+		all = append(all, ranked{value: v})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].value < all[j].value })
+
+	// Tied values share the average of their ranks
+	rankSum, tieTerm := 0.0, 0.0
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].value == all[i].value {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].first {
+				rankSum += rank
+			}
+		}
+		ties := float64(j - i)
+		tieTerm += ties*ties*ties - ties
+		i = j
+	}
+
+	u := rankSum - n1*(n1+1)/2
+	n := n1 + n2
+	mean := n1 * n2 / 2
+	variance := n1 * n2 / 12 * ((n + 1) - tieTerm/(n*(n-1)))
+	if variance <= 0 {
+		return 1
+	}
+	z := (math.Abs(u-mean) - 0.5) / math.Sqrt(variance)
+	if z < 0 {
+		z = 0
+	}
+	return math.Erfc(z / math.Sqrt2)
+}
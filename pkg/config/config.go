@@ -16,16 +16,54 @@ type Config struct {
 	Env      map[string]EnvConfig `yaml:"environments" mapstructure:"environments"`
 	Secrets  SecretsConfig        `yaml:"secrets" mapstructure:"secrets"`
 	Plugins  []PluginConfig       `yaml:"plugins" mapstructure:"plugins"`
+	Webhooks []WebhookConfig      `yaml:"webhooks" mapstructure:"webhooks"`
+	// Preflight checks must pass before any scenario runs
+	Preflight []PreflightCheck `yaml:"preflight" mapstructure:"preflight"`
+	// Services are the backends steps address by name with `service:`
+	Services map[string]ServiceConfig `yaml:"services" mapstructure:"services"`
+
+	Integrations IntegrationsConfig `yaml:"integrations" mapstructure:"integrations"`
+	// Data configures remote data sources
+	Data DataConfig `yaml:"data" mapstructure:"data"`
+	// Encryption configures the keys of SOPS-encrypted scenario and data files
+	Encryption EncryptionConfig `yaml:"encryption" mapstructure:"encryption"`
+	// Reports configures where reports written to object store URLs are uploaded
+	Reports ReportsConfig `yaml:"reports" mapstructure:"reports"`
 }
 
 type GlobalConfig struct {
 	BaseURL   string            `yaml:"base_url" mapstructure:"base_url"`
 	Headers   map[string]string `yaml:"headers" mapstructure:"headers"`
+	Query     map[string]string `yaml:"query" mapstructure:"query"` // appended to every request, e.g. api_key
 	Timeout   time.Duration     `yaml:"timeout" mapstructure:"timeout"`
 	Retries   int               `yaml:"retries" mapstructure:"retries"`
 	Variables map[string]any    `yaml:"variables" mapstructure:"variables"`
 	Setup     []string          `yaml:"setup" mapstructure:"setup"`
 	Teardown  []string          `yaml:"teardown" mapstructure:"teardown"`
+
+	CorrelationID CorrelationConfig `yaml:"correlation_id" mapstructure:"correlation_id"`
+	// FreezeTime fixes the time seen by time builtins: an RFC 3339 timestamp, a date, or "now"
+	// for the start of the run
+	FreezeTime string `yaml:"freeze_time" mapstructure:"freeze_time"`
+	// FailOn is the least severe metadata severity (e.g. critical) whose failures fail the run;
+	// failures of less severe or unlabeled scenarios only produce warnings
+	FailOn string `yaml:"fail_on" mapstructure:"fail_on"`
+	// ThinkTime and Pacing apply to scenarios that set none: a duration ("2s") or a random
+	// range ("1s-3s") waited between steps, and the least time between iteration starts
+	ThinkTime string `yaml:"think_time" mapstructure:"think_time"`
+	Pacing    string `yaml:"pacing" mapstructure:"pacing"`
+	// Middleware names request middleware registered from Go (e.g. a partner's request signing)
+	// that runs on every request
+	Middleware []string `yaml:"middleware" mapstructure:"middleware"`
+}
+
+// CorrelationConfig injects a generated request ID header so failures can be matched with
+// server-side logs
+type CorrelationConfig struct {
+	Enabled  bool   `yaml:"enabled" mapstructure:"enabled"`
+	Header   string `yaml:"header" mapstructure:"header"`     // defaults to X-Request-ID
+	Scope    string `yaml:"scope" mapstructure:"scope"`       // step (new ID per step, default) or scenario
+	Variable string `yaml:"variable" mapstructure:"variable"` // variable holding the ID, defaults to correlation_id
 }
 
 type DefaultConfig struct {
@@ -34,12 +72,134 @@ type DefaultConfig struct {
 	RetryDelay     time.Duration `yaml:"retry_delay" mapstructure:"retry_delay"`
 	FollowRedirect bool          `yaml:"follow_redirect" mapstructure:"follow_redirect"`
 	VerifySSL      bool          `yaml:"verify_ssl" mapstructure:"verify_ssl"`
+	// IPVersion connects over IPv4 (4) or IPv6 (6) only; either is used when unset
+	IPVersion int `yaml:"ip_version" mapstructure:"ip_version"`
+	// NetworkRetries resends requests that failed to connect or lost their connection, for the
+	// NetworkRetryMethods (GET, HEAD, OPTIONS, PUT, DELETE and TRACE when empty)
+	NetworkRetries      int                  `yaml:"network_retries" mapstructure:"network_retries"`
+	NetworkRetryMethods []string             `yaml:"network_retry_methods" mapstructure:"network_retry_methods"`
+	CircuitBreaker      CircuitBreakerConfig `yaml:"circuit_breaker" mapstructure:"circuit_breaker"`
+}
+
+// CircuitBreakerConfig fails requests to a host immediately, as host unavailable, once that many
+// requests in a row could not reach it, instead of letting every remaining step time out
+type CircuitBreakerConfig struct {
+	Failures int           `yaml:"failures" mapstructure:"failures"` // disabled when 0
+	Cooldown time.Duration `yaml:"cooldown" mapstructure:"cooldown"` // before the host is tried again, 30s by default
 }
 
 type EnvConfig struct {
 	BaseURL   string            `yaml:"base_url" mapstructure:"base_url"`
 	Headers   map[string]string `yaml:"headers" mapstructure:"headers"`
+	Query     map[string]string `yaml:"query" mapstructure:"query"`
 	Variables map[string]any    `yaml:"variables" mapstructure:"variables"`
+	// Services override the base URL, headers, query or auth of services per environment
+	Services map[string]ServiceConfig `yaml:"services" mapstructure:"services"`
+}
+
+// ServiceConfig is a backend that steps address with `service: <name>`: relative step URLs
+// resolve against its base URL, and its headers, query parameters and auth apply unless the step
+// sets its own
+type ServiceConfig struct {
+	BaseURL string            `yaml:"base_url" mapstructure:"base_url"`
+	Headers map[string]string `yaml:"headers" mapstructure:"headers"`
+	Query   map[string]string `yaml:"query" mapstructure:"query"`
+	Auth    *ServiceAuth      `yaml:"auth" mapstructure:"auth"`
+	// Middleware runs on requests to the service, before the step's own
+	Middleware []string `yaml:"middleware" mapstructure:"middleware"`
+}
+
+// ServiceAuth authenticates requests to a service like a step's auth block; credentials expand
+// ${VAR} environment variables
+type ServiceAuth struct {
+	Type     string `yaml:"type" mapstructure:"type"` // basic, bearer or api_key
+	Username string `yaml:"username" mapstructure:"username"`
+	Password string `yaml:"password" mapstructure:"password"`
+	Token    string `yaml:"token" mapstructure:"token"`
+	Header   string `yaml:"header" mapstructure:"header"` // api_key header, defaults to Authorization
+}
+
+// DataConfig configures data sources whose path is an http(s) or s3 URL. They are cached in
+// CacheDir, the user cache directory by default, and fetched again only when they changed.
+type DataConfig struct {
+	CacheDir string             `yaml:"cache_dir" mapstructure:"cache_dir"`
+	Remote   []RemoteDataConfig `yaml:"remote" mapstructure:"remote"`
+}
+
+// ReportsConfig holds the credentials of reports whose output file is an s3://, gs:// or
+// azblob:// URL
+type ReportsConfig struct {
+	Storage ReportStorageConfig `yaml:"storage" mapstructure:"storage"`
+}
+
+// ReportStorageConfig holds object store credentials; they expand ${VAR} environment variables
+// and empty fields fall back to each store's standard environment variables
+type ReportStorageConfig struct {
+	S3    *S3Config    `yaml:"s3" mapstructure:"s3"`
+	GCS   *GCSConfig   `yaml:"gcs" mapstructure:"gcs"`
+	Azure *AzureConfig `yaml:"azure" mapstructure:"azure"`
+}
+
+// GCSConfig holds Google Cloud Storage credentials; empty fields fall back to
+// GOOGLE_OAUTH_ACCESS_TOKEN and GOOGLE_APPLICATION_CREDENTIALS
+type GCSConfig struct {
+	AccessToken string `yaml:"access_token" mapstructure:"access_token"`
+	KeyFile     string `yaml:"key_file" mapstructure:"key_file"` // service account key in JSON
+	Endpoint    string `yaml:"endpoint" mapstructure:"endpoint"` // emulators such as fake-gcs-server
+}
+
+// AzureConfig holds Azure Blob Storage credentials; empty fields fall back to
+// AZURE_STORAGE_ACCOUNT, AZURE_STORAGE_KEY and AZURE_STORAGE_SAS_TOKEN
+type AzureConfig struct {
+	Account  string `yaml:"account" mapstructure:"account"`
+	Key      string `yaml:"key" mapstructure:"key"`
+	SASToken string `yaml:"sas_token" mapstructure:"sas_token"`
+	Endpoint string `yaml:"endpoint" mapstructure:"endpoint"` // emulators such as Azurite
+}
+
+// EncryptionConfig names the age key file SOPS-encrypted files are decrypted with, in addition
+// to SOPS_AGE_KEY, SOPS_AGE_KEY_FILE and the sops default key file. The path expands ${VAR}
+// environment variables.
+type EncryptionConfig struct {
+	AgeKeyFile string `yaml:"age_key_file" mapstructure:"age_key_file"`
+}
+
+// RemoteDataConfig authenticates the data URLs starting with Prefix; credentials expand ${VAR}
+// environment variables
+type RemoteDataConfig struct {
+	Prefix  string            `yaml:"prefix" mapstructure:"prefix"`
+	Headers map[string]string `yaml:"headers" mapstructure:"headers"`
+	Auth    *ServiceAuth      `yaml:"auth" mapstructure:"auth"`
+	S3      *S3Config         `yaml:"s3" mapstructure:"s3"`
+}
+
+// S3Config holds S3 credentials; empty fields fall back to the AWS_* environment variables
+type S3Config struct {
+	Region          string `yaml:"region" mapstructure:"region"`
+	AccessKeyID     string `yaml:"access_key_id" mapstructure:"access_key_id"`
+	SecretAccessKey string `yaml:"secret_access_key" mapstructure:"secret_access_key"`
+	SessionToken    string `yaml:"session_token" mapstructure:"session_token"`
+	Endpoint        string `yaml:"endpoint" mapstructure:"endpoint"` // S3-compatible stores such as MinIO
+}
+
+// PreflightCheck is a request the environment must answer before a run starts. It is written as
+// a URL, or as a mapping that sets the method, headers or expected status.
+type PreflightCheck struct {
+	Name    string            `yaml:"name" mapstructure:"name"`
+	URL     string            `yaml:"url" mapstructure:"url"` // relative URLs are resolved against global.base_url
+	Method  string            `yaml:"method" mapstructure:"method"`
+	Headers map[string]string `yaml:"headers" mapstructure:"headers"`
+	Status  int               `yaml:"status" mapstructure:"status"` // any status below 400 when 0
+}
+
+// UnmarshalYAML accepts a bare URL as well as a mapping
+func (c *PreflightCheck) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*c = PreflightCheck{URL: node.Value}
+		return nil
+	}
+	type plain PreflightCheck
+	return node.Decode((*plain)(c))
 }
 
 type SecretsConfig struct {
@@ -47,6 +207,78 @@ type SecretsConfig struct {
 	Config   map[string]interface{} `yaml:"config" mapstructure:"config"`
 }
 
+// WebhookConfig posts run results to an HTTP endpoint. Header values expand ${VAR} environment
+// variables so tokens stay out of the config file.
+type WebhookConfig struct {
+	URL     string            `yaml:"url" mapstructure:"url"`
+	Headers map[string]string `yaml:"headers" mapstructure:"headers"`
+	Events  []string          `yaml:"events" mapstructure:"events"` // run (default), scenario and/or alert
+	Timeout time.Duration     `yaml:"timeout" mapstructure:"timeout"`
+}
+
+// IntegrationsConfig pushes results to test-management systems after a run. Scenarios are mapped
+// to test cases through a metadata label; credentials expand ${VAR} environment variables.
+type IntegrationsConfig struct {
+	TestRail *TestRailConfig `yaml:"testrail" mapstructure:"testrail"`
+	Xray     *XrayConfig     `yaml:"xray" mapstructure:"xray"`
+	Zephyr   *ZephyrConfig   `yaml:"zephyr" mapstructure:"zephyr"`
+}
+
+type TestRailConfig struct {
+	URL    string `yaml:"url" mapstructure:"url"`
+	User   string `yaml:"user" mapstructure:"user"`
+	APIKey string `yaml:"api_key" mapstructure:"api_key"`
+	// RunID receives the results; without it a run is created in ProjectID (and SuiteID)
+	RunID     int    `yaml:"run_id" mapstructure:"run_id"`
+	ProjectID int    `yaml:"project_id" mapstructure:"project_id"`
+	SuiteID   int    `yaml:"suite_id" mapstructure:"suite_id"`
+	RunName   string `yaml:"run_name" mapstructure:"run_name"`
+	Label     string `yaml:"label" mapstructure:"label"` // defaults to testrail_id, e.g. C123
+}
+
+type XrayConfig struct {
+	URL          string `yaml:"url" mapstructure:"url"` // defaults to Xray cloud
+	ClientID     string `yaml:"client_id" mapstructure:"client_id"`
+	ClientSecret string `yaml:"client_secret" mapstructure:"client_secret"`
+	ProjectKey   string `yaml:"project_key" mapstructure:"project_key"`
+	// TestExecution adds results to an existing test execution; otherwise one is created
+	TestExecution string `yaml:"test_execution" mapstructure:"test_execution"`
+	Label         string `yaml:"label" mapstructure:"label"` // defaults to xray_test, e.g. PROJ-42
+}
+
+type ZephyrConfig struct {
+	URL        string `yaml:"url" mapstructure:"url"` // defaults to Zephyr Scale cloud
+	Token      string `yaml:"token" mapstructure:"token"`
+	ProjectKey string `yaml:"project_key" mapstructure:"project_key"`
+	TestCycle  string `yaml:"test_cycle" mapstructure:"test_cycle"`
+	Label      string `yaml:"label" mapstructure:"label"` // defaults to zephyr_test, e.g. PROJ-T12
+}
+
+func (c IntegrationsConfig) validate() error {
+	if tr := c.TestRail; tr != nil {
+		if tr.URL == "" || tr.User == "" || tr.APIKey == "" {
+			return fmt.Errorf("testrail: url, user and api_key are required")
+		}
+		if tr.RunID == 0 && tr.ProjectID == 0 {
+			return fmt.Errorf("testrail: run_id or project_id is required")
+		}
+	}
+	if xray := c.Xray; xray != nil {
+		if xray.ClientID == "" || xray.ClientSecret == "" {
+			return fmt.Errorf("xray: client_id and client_secret are required")
+		}
+		if xray.TestExecution == "" && xray.ProjectKey == "" {
+			return fmt.Errorf("xray: test_execution or project_key is required")
+		}
+	}
+	if zephyr := c.Zephyr; zephyr != nil {
+		if zephyr.Token == "" || zephyr.ProjectKey == "" || zephyr.TestCycle == "" {
+			return fmt.Errorf("zephyr: token, project_key and test_cycle are required")
+		}
+	}
+	return nil
+}
+
 type PluginConfig struct {
 	Name    string                 `yaml:"name" mapstructure:"name"`
 	Path    string                 `yaml:"path" mapstructure:"path"`
@@ -77,6 +309,44 @@ func LoadConfig(configFile string) (*Config, error) {
 		return nil, fmt.Errorf("failed to load environment overrides: %w", err)
 	}
 
+	if _, err := ParseFreezeTime(config.Global.FreezeTime); err != nil {
+		return nil, fmt.Errorf("invalid global.freeze_time: %w", err)
+	}
+
+	if v := config.Defaults.IPVersion; v != 0 && v != 4 && v != 6 {
+		return nil, fmt.Errorf("invalid defaults.ip_version %d (expected 4 or 6)", v)
+	}
+
+	if err := config.Integrations.validate(); err != nil {
+		return nil, fmt.Errorf("invalid integrations: %w", err)
+	}
+
+	for i, remote := range config.Data.Remote {
+		if remote.Prefix == "" {
+			return nil, fmt.Errorf("data.remote[%d]: prefix is required", i)
+		}
+		if auth := remote.Auth; auth != nil && auth.Type != "basic" && auth.Type != "bearer" && auth.Type != "api_key" {
+			return nil, fmt.Errorf("data.remote[%d]: unknown auth type %q (expected basic, bearer or api_key)", i, auth.Type)
+		}
+	}
+
+	for i, check := range config.Preflight {
+		if check.URL == "" {
+			return nil, fmt.Errorf("preflight[%d]: url is required", i)
+		}
+	}
+
+	for i, webhook := range config.Webhooks {
+		if webhook.URL == "" {
+			return nil, fmt.Errorf("webhooks[%d]: url is required", i)
+		}
+		for _, event := range webhook.Events {
+			if event != "run" && event != "scenario" && event != "alert" {
+				return nil, fmt.Errorf("webhooks[%d]: unknown event %q (expected run, scenario or alert)", i, event)
+			}
+		}
+	}
+
 	return config, nil
 }
 
@@ -113,6 +383,23 @@ func loadEnvOverrides(config *Config) error {
 	return nil
 }
 
+// ParseFreezeTime parses a freeze_time value; an empty value returns the zero time
+func ParseFreezeTime(value string) (time.Time, error) {
+	switch value {
+	case "":
+		return time.Time{}, nil
+	case "now":
+		return time.Now(), nil
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, expected RFC 3339 (2006-01-02T15:04:05Z), a date (2006-01-02) or now", value)
+}
+
 func (c *Config) GetEnvironment(env string) (EnvConfig, bool) {
 	envConfig, exists := c.Env[env]
 	return envConfig, exists
@@ -133,13 +420,62 @@ func (c *Config) MergeEnvironment(env string) *Config {
 			merged.Global.Headers[k] = v
 		}
 
+		merged.Global.Query = make(map[string]string, len(c.Global.Query)+len(envConfig.Query))
+		for k, v := range c.Global.Query {
+			merged.Global.Query[k] = v
+		}
+		for k, v := range envConfig.Query {
+			merged.Global.Query[k] = v
+		}
+
 		if merged.Global.Variables == nil {
 			merged.Global.Variables = make(map[string]any)
 		}
 		for k, v := range envConfig.Variables {
 			merged.Global.Variables[k] = v
 		}
+
+		merged.Services = make(map[string]ServiceConfig, len(c.Services)+len(envConfig.Services))
+		for name, service := range c.Services {
+			merged.Services[name] = service
+		}
+		for name, override := range envConfig.Services {
+			merged.Services[name] = mergeService(merged.Services[name], override)
+		}
 	}
 
 	return &merged
 }
+
+// mergeService overlays the values an environment sets on a service
+func mergeService(service, override ServiceConfig) ServiceConfig {
+	if override.BaseURL != "" {
+		service.BaseURL = override.BaseURL
+	}
+	if override.Auth != nil {
+		service.Auth = override.Auth
+	}
+	if override.Middleware != nil {
+		service.Middleware = override.Middleware
+	}
+
+	headers := make(map[string]string, len(service.Headers)+len(override.Headers))
+	for k, v := range service.Headers {
+		headers[k] = v
+	}
+	for k, v := range override.Headers {
+		headers[k] = v
+	}
+	service.Headers = headers
+
+	query := make(map[string]string, len(service.Query)+len(override.Query))
+	for k, v := range service.Query {
+		query[k] = v
+	}
+	for k, v := range override.Query {
+		query[k] = v
+	}
+	service.Query = query
+
+	return service
+}
@@ -0,0 +1,182 @@
+package data
+
+import (
+	"bufio"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// CSVOptions configures how a CSV data source is read
+type CSVOptions struct {
+	Delimiter string `yaml:"delimiter,omitempty" json:"delimiter,omitempty"` // one character, or "tab"; default ","
+	Quote     string `yaml:"quote,omitempty" json:"quote,omitempty"`         // one character, or "none"; default "
+	Escape    string `yaml:"escape,omitempty" json:"escape,omitempty"`       // escapes the next character in quoted values; default a doubled quote
+	// Header names the columns of a file without a header row; NoHeader without Header names
+	// them column1, column2, ...
+	Header   []string `yaml:"header,omitempty" json:"header,omitempty"`
+	NoHeader bool     `yaml:"no_header,omitempty" json:"no_header,omitempty"`
+	Columns  []string `yaml:"columns,omitempty" json:"columns,omitempty"` // keep only these columns
+	Exclude  []string `yaml:"exclude,omitempty" json:"exclude,omitempty"` // drop these columns
+	// Raw keeps values as strings instead of inferring numbers and booleans, so IDs like 007
+	// keep their zeros
+	Raw bool `yaml:"raw,omitempty" json:"raw,omitempty"`
+}
+
+type recordReader interface {
+	Read() ([]string, error)
+}
+
+// reader returns the standard CSV reader, or a reader for custom quote and escape characters
+func (o *CSVOptions) reader(r io.Reader) (recordReader, error) {
+	delimiter, err := optionChar("delimiter", o.Delimiter, ',', map[string]rune{"tab": '\t', `\t`: '\t'})
+	if err != nil {
+		return nil, err
+	}
+	quote, err := optionChar("quote", o.Quote, '"', map[string]rune{"none": 0})
+	if err != nil {
+		return nil, err
+	}
+	escape, err := optionChar("escape", o.Escape, quote, nil)
+	if err != nil {
+		return nil, err
+	}
+	if quote != 0 && (delimiter == quote || delimiter == escape) {
+		return nil, fmt.Errorf("delimiter must differ from the quote and escape characters")
+	}
+
+	if quote == '"' && escape == '"' {
+		reader := csv.NewReader(r)
+		reader.Comma = delimiter
+		return reader, nil
+	}
+	return &quotedReader{in: bufio.NewReader(r), delimiter: delimiter, quote: quote, escape: escape, line: 1}, nil
+}
+
+func optionChar(name, value string, fallback rune, names map[string]rune) (rune, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	if char, named := names[value]; named {
+		return char, nil
+	}
+	if utf8.RuneCountInString(value) != 1 || value == "\n" || value == "\r" {
+		return 0, fmt.Errorf("%s must be a single character, got %q", name, value)
+	}
+	char, _ := utf8.DecodeRuneInString(value)
+	return char, nil
+}
+
+// selectColumns returns which columns to keep by index
+func (o *CSVOptions) selectColumns(headers []string) ([]bool, error) {
+	index := make(map[string]int, len(headers))
+	for i, header := range headers {
+		index[header] = i
+	}
+	for _, name := range append(append([]string{}, o.Columns...), o.Exclude...) {
+		if _, exists := index[name]; !exists {
+			return nil, fmt.Errorf("column %q is not in the header %s", name, strings.Join(headers, ","))
+		}
+	}
+
+	keep := make([]bool, len(headers))
+	for i := range keep {
+		keep[i] = len(o.Columns) == 0
+	}
+	for _, name := range o.Columns {
+		keep[index[name]] = true
+	}
+	for _, name := range o.Exclude {
+		keep[index[name]] = false
+	}
+	return keep, nil
+}
+
+func numberedColumns(count int) []string {
+	headers := make([]string, count)
+	for i := range headers {
+		headers[i] = fmt.Sprintf("column%d", i+1)
+	}
+	return headers
+}
+
+// quotedReader reads delimited records whose quote and escape characters encoding/csv does not
+// support, such as 'single quotes' or backslash escapes. A quote of 0 disables quoting.
+type quotedReader struct {
+	in        *bufio.Reader
+	delimiter rune
+	quote     rune
+	escape    rune
+	line      int
+}
+
+func (r *quotedReader) Read() ([]string, error) {
+	var record []string
+	var field strings.Builder
+	quoted, started := false, false
+
+	for {
+		c, _, err := r.in.ReadRune()
+		if err == io.EOF {
+			if quoted {
+				return nil, fmt.Errorf("line %d: quoted value is not closed", r.line)
+			}
+			if !started && len(record) == 0 {
+				return nil, io.EOF
+			}
+			return append(record, field.String()), nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		started = true
+
+		switch {
+		case quoted && c == r.escape && r.escape != r.quote:
+			next, _, err := r.in.ReadRune()
+			if err != nil {
+				return nil, fmt.Errorf("line %d: escape character at the end of the file", r.line)
+			}
+			field.WriteRune(next)
+		case quoted && c == r.quote:
+			// A doubled quote is a literal quote when the quote is its own escape
+			if next, _, err := r.in.ReadRune(); err == nil {
+				if next == r.quote && r.escape == r.quote {
+					field.WriteRune(c)
+					continue
+				}
+				r.in.UnreadRune()
+			}
+			quoted = false
+		case quoted && c == '\r':
+			// \r\n in quoted values reads as \n, as encoding/csv does
+			if next, err := r.in.Peek(1); err == nil && next[0] == '\n' {
+				continue
+			}
+			field.WriteRune(c)
+		case quoted:
+			if c == '\n' {
+				r.line++
+			}
+			field.WriteRune(c)
+		case c == r.quote && r.quote != 0 && field.Len() == 0:
+			quoted = true
+		case c == r.delimiter:
+			record = append(record, field.String())
+			field.Reset()
+		case c == '\r':
+			// dropped, lines may end with \r\n
+		case c == '\n':
+			r.line++
+			if len(record) == 0 && field.Len() == 0 {
+				started = false
+				continue // blank lines are skipped like encoding/csv does
+			}
+			return append(record, field.String()), nil
+		default:
+			field.WriteRune(c)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package data
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/sops"
+)
+
+// gzipMagic starts every gzip stream
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// dataFile is an open data file, decompressed when it is gzipped
+type dataFile struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (f *dataFile) Close() error {
+	var firstErr error
+	for i := len(f.closers) - 1; i >= 0; i-- {
+		if err := f.closers[i].Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// openDataFile opens a data file. Gzipped files are recognized by their content, so exports
+// such as users.jsonl.gz load without unpacking them first, and so are files SOPS encrypted as a
+// whole, which are decrypted. A UTF-8 byte order mark, which Windows tools like Excel write, is
+// skipped.
+func (dl *DataLoader) openDataFile(path string) (io.ReadCloser, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	buffered := skipBOM(bufio.NewReader(file))
+	if start, _ := buffered.Peek(64); sops.IsEncryptedBinary(start) {
+		defer file.Close()
+		content, err := io.ReadAll(buffered)
+		if err != nil {
+			return nil, err
+		}
+		if content, err = sops.DecryptBinary(content, dl.ageKeyFile); err != nil {
+			return nil, fmt.Errorf("failed to decrypt %s: %w", path, err)
+		}
+		return io.NopCloser(bytes.NewReader(content)), nil
+	}
+	if magic, _ := buffered.Peek(len(gzipMagic)); string(magic) != string(gzipMagic) {
+		return &dataFile{Reader: buffered, closers: []io.Closer{file}}, nil
+	}
+
+	decompressed, err := gzip.NewReader(buffered)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to decompress %s: %w", path, err)
+	}
+	return &dataFile{Reader: skipBOM(bufio.NewReader(decompressed)), closers: []io.Closer{file, decompressed}}, nil
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+func skipBOM(r *bufio.Reader) *bufio.Reader {
+	if start, _ := r.Peek(len(utf8BOM)); bytes.Equal(start, utf8BOM) {
+		r.Discard(len(utf8BOM))
+	}
+	return r
+}
+
+// isJSONLines reports whether a path names a JSON Lines file, compressed or not
+func isJSONLines(path string) bool {
+	path = strings.TrimSuffix(strings.ToLower(path), ".gz")
+	return strings.HasSuffix(path, ".jsonl") || strings.HasSuffix(path, ".ndjson")
+}
+
+// loadJSONLines loads a JSON Lines (NDJSON) file with one object per line. Blank lines are
+// skipped.
+func (dl *DataLoader) loadJSONLines(path string) ([]map[string]interface{}, error) {
+	fullPath := dl.resolvePath(path)
+
+	file, err := dl.openDataFile(fullPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open JSON Lines file %s: %w", fullPath, err)
+	}
+	defer file.Close()
+
+	var result []map[string]interface{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		var item map[string]interface{}
+		if err := json.Unmarshal([]byte(text), &item); err != nil || item == nil {
+			if err == nil {
+				err = fmt.Errorf("not an object")
+			}
+			return nil, fmt.Errorf("%s line %d: %w", fullPath, line, err)
+		}
+		result = append(result, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read JSON Lines file %s: %w", fullPath, err)
+	}
+	return result, nil
+}
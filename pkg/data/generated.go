@@ -0,0 +1,189 @@
+package data
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/expr"
+)
+
+var (
+	firstNames = []string{"Ada", "Alan", "Grace", "Linus", "Margaret", "Dennis", "Barbara", "Ken", "Frances", "Donald", "Radia", "Edsger", "Hedy", "John", "Katherine", "Tim"}
+	lastNames  = []string{"Lovelace", "Turing", "Hopper", "Torvalds", "Hamilton", "Ritchie", "Liskov", "Thompson", "Allen", "Knuth", "Perlman", "Dijkstra", "Lamarr", "Backus", "Johnson", "Berners-Lee"}
+	words      = []string{"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel", "india", "juliet", "kilo", "lima", "mike", "november", "oscar", "papa"}
+	cities     = []string{"Berlin", "Paris", "London", "Madrid", "Rome", "Vienna", "Warsaw", "Prague", "Lisbon", "Dublin", "Oslo", "Helsinki"}
+	countries  = []string{"DE", "FR", "GB", "ES", "IT", "AT", "PL", "CZ", "PT", "IE", "NO", "FI"}
+)
+
+const alphanumeric = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+// loadGenerated builds rows from generator expressions, e.g.
+//
+//	id: seq(1000)
+//	age: randomInt(18, 90)
+//	country: choice('DE', 'FR', 'US')
+//	email: lower(firstName()) + row + '@example.com'
+//
+// Expressions can use `row` (1-based) and `index` (0-based) and any expression function.
+func (dl *DataLoader) loadGenerated(source DataSource) ([]map[string]interface{}, error) {
+	if source.Rows <= 0 {
+		return nil, fmt.Errorf("generated data source needs rows > 0")
+	}
+	if len(source.Fields) == 0 {
+		return nil, fmt.Errorf("generated data source needs at least one field")
+	}
+
+	// Fields are generated in name order so a fixed seed always produces the same rows
+	names := make([]string, 0, len(source.Fields))
+	for name := range source.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	generators := make(map[string]*expr.Expr, len(names))
+	for _, name := range names {
+		compiled, err := expr.Compile(source.Fields[name])
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", name, err)
+		}
+		generators[name] = compiled
+	}
+
+	seed := source.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	result := make([]map[string]interface{}, 0, source.Rows)
+	for index := 0; index < source.Rows; index++ {
+		env := expr.Env{
+			Resolve: expr.MapResolver(map[string]interface{}{"row": index + 1, "index": index}),
+			Funcs:   generatorFuncs(rng, index),
+		}
+
+		row := make(map[string]interface{}, len(names))
+		for _, name := range names {
+			value, err := generators[name].Eval(env)
+			if err != nil {
+				return nil, fmt.Errorf("field %s, row %d: %w", name, index+1, err)
+			}
+			row[name] = value
+		}
+		result = append(result, row)
+	}
+
+	return result, nil
+}
+
+// generatorFuncs returns the generator functions for one row
+func generatorFuncs(rng *rand.Rand, index int) map[string]expr.Func {
+	pick := func(values []string) expr.Func {
+		return func(args ...interface{}) (interface{}, error) {
+			return values[rng.Intn(len(values))], nil
+		}
+	}
+
+	return map[string]expr.Func{
+		"seq": func(args ...interface{}) (interface{}, error) {
+			start, step := 1.0, 1.0
+			if len(args) > 0 {
+				if n, ok := expr.ToNumber(args[0]); ok {
+					start = n
+				}
+			}
+			if len(args) > 1 {
+				if n, ok := expr.ToNumber(args[1]); ok {
+					step = n
+				}
+			}
+			return int(start + float64(index)*step), nil
+		},
+		"randomInt": func(args ...interface{}) (interface{}, error) {
+			min, max, err := numberRange(args, 0, 100)
+			if err != nil {
+				return nil, err
+			}
+			return int(min) + rng.Intn(int(max)-int(min)+1), nil
+		},
+		"randomFloat": func(args ...interface{}) (interface{}, error) {
+			min, max, err := numberRange(args, 0, 1)
+			if err != nil {
+				return nil, err
+			}
+			return min + rng.Float64()*(max-min), nil
+		},
+		"randomBool": func(args ...interface{}) (interface{}, error) {
+			return rng.Intn(2) == 1, nil
+		},
+		"randomString": func(args ...interface{}) (interface{}, error) {
+			length := 8
+			if len(args) > 0 {
+				if n, ok := expr.ToNumber(args[0]); ok {
+					length = int(n)
+				}
+			}
+			b := make([]byte, length)
+			for i := range b {
+				b[i] = alphanumeric[rng.Intn(len(alphanumeric))]
+			}
+			return string(b), nil
+		},
+		"choice": func(args ...interface{}) (interface{}, error) {
+			if len(args) == 1 {
+				if list, ok := args[0].([]interface{}); ok {
+					args = list
+				}
+			}
+			if len(args) == 0 {
+				return nil, fmt.Errorf("expects at least 1 value")
+			}
+			return args[rng.Intn(len(args))], nil
+		},
+		"uuid": func(args ...interface{}) (interface{}, error) {
+			b := make([]byte, 16)
+			rng.Read(b)
+			b[6] = (b[6] & 0x0f) | 0x40
+			b[8] = (b[8] & 0x3f) | 0x80
+			return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+		},
+		"firstName": pick(firstNames),
+		"lastName":  pick(lastNames),
+		"name": func(args ...interface{}) (interface{}, error) {
+			return firstNames[rng.Intn(len(firstNames))] + " " + lastNames[rng.Intn(len(lastNames))], nil
+		},
+		"email": func(args ...interface{}) (interface{}, error) {
+			first := strings.ToLower(firstNames[rng.Intn(len(firstNames))])
+			last := strings.ToLower(lastNames[rng.Intn(len(lastNames))])
+			return fmt.Sprintf("%s.%s%d@example.com", first, last, index+1), nil
+		},
+		"word":    pick(words),
+		"city":    pick(cities),
+		"country": pick(countries),
+		"phone": func(args ...interface{}) (interface{}, error) {
+			return fmt.Sprintf("+1555%07d", rng.Intn(10000000)), nil
+		},
+	}
+}
+
+func numberRange(args []interface{}, defaultMin, defaultMax float64) (float64, float64, error) {
+	min, max := defaultMin, defaultMax
+	if len(args) == 2 {
+		var ok bool
+		if min, ok = expr.ToNumber(args[0]); !ok {
+			return 0, 0, fmt.Errorf("%v is not a number", args[0])
+		}
+		if max, ok = expr.ToNumber(args[1]); !ok {
+			return 0, 0, fmt.Errorf("%v is not a number", args[1])
+		}
+	} else if len(args) != 0 {
+		return 0, 0, fmt.Errorf("expects a min and max")
+	}
+	if max < min {
+		return 0, 0, fmt.Errorf("max %v is lower than min %v", max, min)
+	}
+	return min, max, nil
+}
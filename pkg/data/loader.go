@@ -1,25 +1,40 @@
 package data
 
 import (
-	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
-	"os"
 	"path/filepath"
 	"strconv"
+
+	"github.com/nulln0ne/fuego/pkg/sops"
 )
 
 // DataSource represents different types of data sources
 type DataSource struct {
-	Type string      `yaml:"type" json:"type"` // csv, json, inline
+	Type string      `yaml:"type" json:"type"` // csv, json, jsonl (ndjson), inline, generated
 	Path string      `yaml:"path,omitempty" json:"path,omitempty"`
 	Data interface{} `yaml:"data,omitempty" json:"data,omitempty"`
+	// Generated sources: number of rows, generator expression per field and random seed (0 = random)
+	Rows   int               `yaml:"rows,omitempty" json:"rows,omitempty"`
+	Fields map[string]string `yaml:"fields,omitempty" json:"fields,omitempty"`
+	Seed   int64             `yaml:"seed,omitempty" json:"seed,omitempty"`
+	// CSV configures how csv sources are read
+	CSV *CSVOptions `yaml:"csv,omitempty" json:"csv,omitempty"`
+	// Transforms: rows are joined with another source, filtered by an expression over their
+	// fields, reduced to the selected fields and renamed, in that order
+	Join   *Join             `yaml:"join,omitempty" json:"join,omitempty"`
+	Filter string            `yaml:"filter,omitempty" json:"filter,omitempty"`
+	Select []string          `yaml:"select,omitempty" json:"select,omitempty"`
+	Rename map[string]string `yaml:"rename,omitempty" json:"rename,omitempty"`
 }
 
 // DataLoader handles loading data from various sources
 type DataLoader struct {
 	baseDir string
+	remote  Remote
+	// ageKeyFile decrypts SOPS-encrypted data files in addition to the age keys of the environment
+	ageKeyFile string
 }
 
 // NewDataLoader creates a new data loader with a base directory
@@ -29,43 +44,99 @@ func NewDataLoader(baseDir string) *DataLoader {
 	}
 }
 
-// LoadData loads data from the specified source
+// SetAgeKeyFile sets the age key file encrypted data files are decrypted with, e.g. the
+// encryption.age_key_file of the config
+func (dl *DataLoader) SetAgeKeyFile(path string) {
+	dl.ageKeyFile = path
+}
+
+// LoadData loads data from the specified source. Sources that join another are loaded with
+// LoadSources.
 func (dl *DataLoader) LoadData(source DataSource) ([]map[string]interface{}, error) {
+	rows, err := dl.loadRows(source)
+	if err != nil {
+		return nil, err
+	}
+	return source.transform(rows, nil)
+}
+
+// loadRows reads the rows of a source before its transforms
+func (dl *DataLoader) loadRows(source DataSource) ([]map[string]interface{}, error) {
+	if IsRemote(source.Path) {
+		local, err := dl.fetch(source.Path)
+		if err != nil {
+			return nil, err
+		}
+		source.Path = local
+	}
+
 	switch source.Type {
 	case "csv":
-		return dl.loadCSV(source.Path)
+		return dl.loadCSV(source.Path, source.CSV)
 	case "json":
+		if isJSONLines(source.Path) {
+			return dl.loadJSONLines(source.Path)
+		}
 		return dl.loadJSON(source.Path)
+	case "jsonl", "ndjson":
+		return dl.loadJSONLines(source.Path)
 	case "inline":
 		return dl.loadInline(source.Data)
+	case "generated":
+		return dl.loadGenerated(source)
 	default:
 		return nil, fmt.Errorf("unsupported data source type: %s", source.Type)
 	}
 }
 
 // loadCSV loads data from a CSV file
-func (dl *DataLoader) loadCSV(path string) ([]map[string]interface{}, error) {
+func (dl *DataLoader) loadCSV(path string, options *CSVOptions) ([]map[string]interface{}, error) {
 	fullPath := dl.resolvePath(path)
+	if options == nil {
+		options = &CSVOptions{}
+	}
 
-	file, err := os.Open(fullPath)
+	file, err := dl.openDataFile(fullPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open CSV file %s: %w", fullPath, err)
 	}
 	defer file.Close()
 
-	reader := csv.NewReader(file)
+	reader, err := options.reader(file)
+	if err != nil {
+		return nil, fmt.Errorf("CSV file %s: %w", fullPath, err)
+	}
 
 	// Read header row
-	headers, err := reader.Read()
+	headers := options.Header
+	var pending []string
+	if len(headers) == 0 {
+		first, err := reader.Read()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV headers: %w", err)
+		}
+		if options.NoHeader {
+			headers = numberedColumns(len(first))
+			pending = first
+		} else {
+			headers = first
+		}
+	}
+	keep, err := options.selectColumns(headers)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read CSV headers: %w", err)
+		return nil, fmt.Errorf("CSV file %s: %w", fullPath, err)
 	}
 
 	var data []map[string]interface{}
 
 	// Read data rows
 	for {
-		record, err := reader.Read()
+		// The first row of a file without a header has been read already
+		record, err := pending, error(nil)
+		if record == nil {
+			record, err = reader.Read()
+		}
+		pending = nil
 		if err == io.EOF {
 			break
 		}
@@ -79,7 +150,14 @@ func (dl *DataLoader) loadCSV(path string) ([]map[string]interface{}, error) {
 
 		row := make(map[string]interface{})
 		for i, value := range record {
-			row[headers[i]] = dl.parseCSVValue(value)
+			if !keep[i] {
+				continue
+			}
+			if options.Raw {
+				row[headers[i]] = value
+			} else {
+				row[headers[i]] = dl.parseCSVValue(value)
+			}
 		}
 		data = append(data, row)
 	}
@@ -91,15 +169,24 @@ func (dl *DataLoader) loadCSV(path string) ([]map[string]interface{}, error) {
 func (dl *DataLoader) loadJSON(path string) ([]map[string]interface{}, error) {
 	fullPath := dl.resolvePath(path)
 
-	file, err := os.Open(fullPath)
+	file, err := dl.openDataFile(fullPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open JSON file %s: %w", fullPath, err)
 	}
 	defer file.Close()
 
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JSON file %s: %w", fullPath, err)
+	}
+	if sops.IsEncrypted(content) {
+		if content, err = sops.Decrypt(content, dl.ageKeyFile); err != nil {
+			return nil, fmt.Errorf("failed to decrypt JSON file %s: %w", fullPath, err)
+		}
+	}
+
 	var data interface{}
-	decoder := json.NewDecoder(file)
-	if err := decoder.Decode(&data); err != nil {
+	if err := json.Unmarshal(content, &data); err != nil {
 		return nil, fmt.Errorf("failed to parse JSON file %s: %w", fullPath, err)
 	}
 
@@ -0,0 +1,162 @@
+package data
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/objectstore"
+)
+
+// Remote configures data sources whose path is an http(s) or s3 URL. They are downloaded to
+// CacheDir and only downloaded again when their ETag or modification time changed.
+type Remote struct {
+	CacheDir string // defaults to fuego/data in the user cache directory
+	Sources  []RemoteSource
+	Client   *http.Client // defaults to a client with a one minute timeout
+}
+
+// RemoteSource authenticates the URLs that start with Prefix; the longest matching prefix wins
+type RemoteSource struct {
+	Prefix  string
+	Headers map[string]string
+	S3      objectstore.S3Credentials
+}
+
+// cacheEntry is stored next to a cached file
+type cacheEntry struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// SetRemote configures how remote data sources are fetched
+func (dl *DataLoader) SetRemote(remote Remote) {
+	dl.remote = remote
+}
+
+// IsRemote reports whether a data source path is a URL to fetch
+func IsRemote(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") || objectstore.IsS3URL(location)
+}
+
+// fetch returns the path of an up-to-date local copy of a remote data file. The copy keeps the
+// file name of the URL, so .gz and .jsonl files are recognized.
+func (dl *DataLoader) fetch(location string) (string, error) {
+	cacheDir := dl.remote.CacheDir
+	if cacheDir == "" {
+		userCache, err := os.UserCacheDir()
+		if err != nil {
+			return "", fmt.Errorf("no cache directory for remote data: %w", err)
+		}
+		cacheDir = filepath.Join(userCache, "fuego", "data")
+	}
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create data cache %s: %w", cacheDir, err)
+	}
+
+	sum := sha256.Sum256([]byte(location))
+	name := "data"
+	if parsed, err := url.Parse(location); err == nil && path.Base(parsed.Path) != "/" && path.Base(parsed.Path) != "." {
+		// Characters like : are valid in URLs but not in Windows file names
+		name = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(`<>:"\|?*`, r) || r < ' ' {
+				return '_'
+			}
+			return r
+		}, path.Base(parsed.Path))
+	}
+	cached := filepath.Join(cacheDir, hex.EncodeToString(sum[:8])+"-"+name)
+	metaPath := cached + ".meta.json"
+
+	var entry cacheEntry
+	if _, err := os.Stat(cached); err == nil {
+		if content, err := os.ReadFile(metaPath); err == nil {
+			json.Unmarshal(content, &entry)
+		}
+	}
+
+	req, err := dl.remoteRequest(location)
+	if err != nil {
+		return "", err
+	}
+	if entry.ETag != "" {
+		req.Header.Set("If-None-Match", entry.ETag)
+	} else if entry.LastModified != "" {
+		req.Header.Set("If-Modified-Since", entry.LastModified)
+	}
+
+	client := dl.remote.Client
+	if client == nil {
+		client = &http.Client{Timeout: time.Minute}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch %s: %w", location, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && entry.URL != "":
+		return cached, nil
+	case resp.StatusCode != http.StatusOK:
+		return "", fmt.Errorf("failed to fetch %s: %s", location, resp.Status)
+	}
+
+	// Written to a temporary file first so an interrupted download never looks cached
+	tmp, err := os.CreateTemp(cacheDir, ".download-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, resp.Body); err != nil {
+		tmp.Close()
+		return "", fmt.Errorf("failed to download %s: %w", location, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), cached); err != nil {
+		return "", err
+	}
+
+	entry = cacheEntry{URL: location, ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+	if content, err := json.Marshal(entry); err == nil {
+		os.WriteFile(metaPath, content, 0644)
+	}
+	return cached, nil
+}
+
+// remoteRequest builds the request for a URL with the credentials of its source
+func (dl *DataLoader) remoteRequest(location string) (*http.Request, error) {
+	var source RemoteSource
+	for _, candidate := range dl.remote.Sources {
+		if strings.HasPrefix(location, candidate.Prefix) && len(candidate.Prefix) >= len(source.Prefix) {
+			source = candidate
+		}
+	}
+
+	var req *http.Request
+	var err error
+	if objectstore.IsS3URL(location) {
+		req, err = objectstore.NewS3Request(http.MethodGet, location, nil, source.S3)
+	} else {
+		req, err = http.NewRequest(http.MethodGet, location, nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range source.Headers {
+		req.Header.Set(name, value)
+	}
+	return req, nil
+}
@@ -0,0 +1,184 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/expr"
+)
+
+// Join combines each row of a source with the rows of another source whose Key field equals its
+// On field. A row matching several rows appears once per match.
+type Join struct {
+	Source string `yaml:"source" json:"source"`
+	On     string `yaml:"on" json:"on"`                       // field of this source
+	Key    string `yaml:"key,omitempty" json:"key,omitempty"` // field of the joined source, defaults to On
+	// As nests the joined row under this field; without it the joined fields are added to the
+	// row, except those the row already has
+	As   string `yaml:"as,omitempty" json:"as,omitempty"`
+	Type string `yaml:"type,omitempty" json:"type,omitempty"` // inner (default) drops rows without a match, left keeps them
+}
+
+// LoadSources loads named data sources, loading the sources others join first
+func (dl *DataLoader) LoadSources(sources map[string]DataSource) (map[string][]map[string]interface{}, error) {
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	loaded := make(map[string][]map[string]interface{}, len(sources))
+	loading := make(map[string]bool)
+	var load func(name string) error
+	load = func(name string) error {
+		if _, done := loaded[name]; done {
+			return nil
+		}
+		if loading[name] {
+			return fmt.Errorf("data source '%s' joins itself through another source", name)
+		}
+		loading[name] = true
+
+		source := sources[name]
+		if join := source.Join; join != nil {
+			if _, exists := sources[join.Source]; !exists {
+				return fmt.Errorf("data source '%s' joins unknown source '%s'", name, join.Source)
+			}
+			if err := load(join.Source); err != nil {
+				return err
+			}
+		}
+
+		rows, err := dl.loadRows(source)
+		if err == nil {
+			rows, err = source.transform(rows, loaded)
+		}
+		if err != nil {
+			return fmt.Errorf("Failed to load data source '%s': %v", name, err)
+		}
+		loaded[name] = rows
+		return nil
+	}
+
+	for _, name := range names {
+		if err := load(name); err != nil {
+			return nil, err
+		}
+	}
+	return loaded, nil
+}
+
+// transform applies the join, filter, select and rename of a source, in that order
+func (source DataSource) transform(rows []map[string]interface{}, loaded map[string][]map[string]interface{}) ([]map[string]interface{}, error) {
+	if source.Join != nil {
+		joined, exists := loaded[source.Join.Source]
+		if !exists {
+			return nil, fmt.Errorf("join source '%s' is not loaded", source.Join.Source)
+		}
+		var err error
+		if rows, err = source.Join.apply(rows, joined); err != nil {
+			return nil, err
+		}
+	}
+
+	if source.Filter != "" {
+		filter, err := expr.Compile(source.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("filter: %w", err)
+		}
+		kept := rows[:0:0]
+		for i, row := range rows {
+			keep, err := filter.Eval(expr.Env{Resolve: expr.MapResolver(row)})
+			if err != nil {
+				return nil, fmt.Errorf("filter, row %d: %w", i+1, err)
+			}
+			if expr.Truthy(keep) {
+				kept = append(kept, row)
+			}
+		}
+		rows = kept
+	}
+
+	if len(source.Select) == 0 && len(source.Rename) == 0 {
+		return rows, nil
+	}
+	shaped := make([]map[string]interface{}, len(rows))
+	for i, row := range rows {
+		fields := row
+		if len(source.Select) > 0 {
+			fields = make(map[string]interface{}, len(source.Select))
+			for _, name := range source.Select {
+				if value, exists := row[name]; exists {
+					fields[name] = value
+				}
+			}
+		}
+		renamed := make(map[string]interface{}, len(fields))
+		for name, value := range fields {
+			if newName, exists := source.Rename[name]; exists {
+				name = newName
+			}
+			renamed[name] = value
+		}
+		shaped[i] = renamed
+	}
+	return shaped, nil
+}
+
+func (j *Join) apply(rows, joined []map[string]interface{}) ([]map[string]interface{}, error) {
+	if j.On == "" {
+		return nil, fmt.Errorf("join needs the field to join on")
+	}
+	key := j.Key
+	if key == "" {
+		key = j.On
+	}
+	left := false
+	switch strings.ToLower(j.Type) {
+	case "", "inner":
+	case "left":
+		left = true
+	default:
+		return nil, fmt.Errorf("unknown join type %q (expected inner or left)", j.Type)
+	}
+
+	// Keys are compared as text, so 1 from a CSV file matches 1.0 from a JSON file
+	index := make(map[string][]map[string]interface{})
+	for _, row := range joined {
+		if value, exists := row[key]; exists && value != nil {
+			index[expr.ToString(value)] = append(index[expr.ToString(value)], row)
+		}
+	}
+
+	var result []map[string]interface{}
+	for _, row := range rows {
+		var matches []map[string]interface{}
+		if value, exists := row[j.On]; exists && value != nil {
+			matches = index[expr.ToString(value)]
+		}
+		if len(matches) == 0 {
+			if left {
+				result = append(result, row)
+			}
+			continue
+		}
+		for _, match := range matches {
+			combined := make(map[string]interface{}, len(row)+len(match))
+			for name, value := range row {
+				combined[name] = value
+			}
+			if j.As != "" {
+				combined[j.As] = match
+			} else {
+				for name, value := range match {
+					if _, exists := combined[name]; !exists {
+						combined[name] = value
+					}
+				}
+			}
+			result = append(result, combined)
+		}
+	}
+	return result, nil
+}
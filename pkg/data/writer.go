@@ -0,0 +1,139 @@
+package data
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// WriteRows writes data rows to a file that can be loaded again as a data source: CSV for .csv
+// paths, with the union of the row keys as sorted columns, JSON otherwise
+func WriteRows(path string, rows []map[string]interface{}) error {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+
+	if !strings.EqualFold(filepath.Ext(path), ".csv") {
+		if rows == nil {
+			rows = []map[string]interface{}{}
+		}
+		content, err := json.MarshalIndent(rows, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode rows: %w", err)
+		}
+		return os.WriteFile(path, append(content, '\n'), 0644)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	seen := make(map[string]bool)
+	var columns []string
+	for _, row := range rows {
+		for column := range row {
+			if !seen[column] {
+				seen[column] = true
+				columns = append(columns, column)
+			}
+		}
+	}
+	sort.Strings(columns)
+
+	writer := csv.NewWriter(file)
+	if len(columns) > 0 {
+		writer.Write(columns)
+	}
+	for _, row := range rows {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			if value, ok := row[column]; ok && value != nil {
+				record[i] = fmt.Sprint(value)
+			}
+		}
+		writer.Write(record)
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
+// Sink appends rows to files: CSV for .csv paths, JSON Lines otherwise. A file is truncated when the sink first writes to it, so every run writes a fresh dataset that
+// can be loaded again as a data source.
+type Sink struct {
+	mu      sync.Mutex
+	columns map[string][]string // CSV columns of each file written so far
+}
+
+// NewSink creates a sink that has not written any file yet
+func NewSink() *Sink {
+	return &Sink{columns: make(map[string][]string)}
+}
+
+// Append adds a row to a file. CSV columns are the sorted fields of the first row; later rows
+// leave out fields that are not columns.
+func (s *Sink) Append(path string, row map[string]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	columns, started := s.columns[path]
+	flags := os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	if !started {
+		if dir := filepath.Dir(path); dir != "" {
+			if err := os.MkdirAll(dir, 0755); err != nil {
+				return err
+			}
+		}
+		flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	}
+	file, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if !strings.EqualFold(filepath.Ext(path), ".csv") {
+		line, err := json.Marshal(row)
+		if err != nil {
+			return fmt.Errorf("failed to encode row: %w", err)
+		}
+		if _, err := file.Write(append(line, '\n')); err != nil {
+			return err
+		}
+		s.columns[path] = nil
+		return file.Close()
+	}
+
+	writer := csv.NewWriter(file)
+	if !started {
+		for column := range row {
+			columns = append(columns, column)
+		}
+		sort.Strings(columns)
+		writer.Write(columns)
+	}
+	record := make([]string, len(columns))
+	for i, column := range columns {
+		if value, ok := row[column]; ok && value != nil {
+			record[i] = fmt.Sprint(value)
+		}
+	}
+	writer.Write(record)
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return err
+	}
+	s.columns[path] = columns
+	return file.Close()
+}
@@ -0,0 +1,241 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Difference describes a single structural mismatch between two JSON documents
+type Difference struct {
+	Path string      `json:"path"`
+	Kind string      `json:"kind"` // added, removed, changed, type
+	A    interface{} `json:"a,omitempty"`
+	B    interface{} `json:"b,omitempty"`
+}
+
+func (d Difference) String() string {
+	path := d.Path
+	if path == "" {
+		path = "(root)"
+	}
+
+	switch d.Kind {
+	case "added":
+		return fmt.Sprintf("%s: only in B (%v)", path, d.B)
+	case "removed":
+		return fmt.Sprintf("%s: only in A (%v)", path, d.A)
+	case "type":
+		return fmt.Sprintf("%s: type mismatch %T vs %T", path, d.A, d.B)
+	default:
+		return fmt.Sprintf("%s: %v != %v", path, d.A, d.B)
+	}
+}
+
+// Compare walks two decoded JSON values and returns their differences in path order.
+// Paths use the same dot notation as json_path assertions (user.id, items.0.name);
+// ignore patterns may use * to match any single segment (items.*.updated_at).
+func Compare(a, b interface{}, ignore []string) []Difference {
+	var diffs []Difference
+	compareValues("", normalize(a), normalize(b), ignore, &diffs)
+	return diffs
+}
+
+// CompareJSON parses two JSON documents and compares them structurally
+func CompareJSON(a, b []byte, ignore []string) ([]Difference, error) {
+	var va, vb interface{}
+	if err := json.Unmarshal(a, &va); err != nil {
+		return nil, fmt.Errorf("failed to parse first document: %w", err)
+	}
+	if err := json.Unmarshal(b, &vb); err != nil {
+		return nil, fmt.Errorf("failed to parse second document: %w", err)
+	}
+	return Compare(va, vb, ignore), nil
+}
+
+// Remove deletes every path matching the ignore patterns from a decoded JSON value
+func Remove(value interface{}, ignore []string) interface{} {
+	return removePaths("", normalize(value), ignore)
+}
+
+// Ignored reports whether a path matches any of the ignore patterns
+func Ignored(path string, ignore []string) bool {
+	for _, pattern := range ignore {
+		if matchPath(strings.TrimPrefix(pattern, "$."), path) {
+			return true
+		}
+	}
+	return false
+}
+
+func compareValues(path string, a, b interface{}, ignore []string, diffs *[]Difference) {
+	if Ignored(path, ignore) {
+		return
+	}
+
+	switch va := a.(type) {
+	case map[string]interface{}:
+		vb, ok := b.(map[string]interface{})
+		if !ok {
+			*diffs = append(*diffs, Difference{Path: path, Kind: "type", A: a, B: b})
+			return
+		}
+
+		keys := make(map[string]struct{}, len(va)+len(vb))
+		for k := range va {
+			keys[k] = struct{}{}
+		}
+		for k := range vb {
+			keys[k] = struct{}{}
+		}
+		sorted := make([]string, 0, len(keys))
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		for _, k := range sorted {
+			childPath := joinPath(path, k)
+			av, inA := va[k]
+			bv, inB := vb[k]
+			switch {
+			case !inB:
+				if !Ignored(childPath, ignore) {
+					*diffs = append(*diffs, Difference{Path: childPath, Kind: "removed", A: av})
+				}
+			case !inA:
+				if !Ignored(childPath, ignore) {
+					*diffs = append(*diffs, Difference{Path: childPath, Kind: "added", B: bv})
+				}
+			default:
+				compareValues(childPath, av, bv, ignore, diffs)
+			}
+		}
+	case []interface{}:
+		vb, ok := b.([]interface{})
+		if !ok {
+			*diffs = append(*diffs, Difference{Path: path, Kind: "type", A: a, B: b})
+			return
+		}
+
+		for i := 0; i < len(va) || i < len(vb); i++ {
+			childPath := joinPath(path, strconv.Itoa(i))
+			switch {
+			case i >= len(vb):
+				if !Ignored(childPath, ignore) {
+					*diffs = append(*diffs, Difference{Path: childPath, Kind: "removed", A: va[i]})
+				}
+			case i >= len(va):
+				if !Ignored(childPath, ignore) {
+					*diffs = append(*diffs, Difference{Path: childPath, Kind: "added", B: vb[i]})
+				}
+			default:
+				compareValues(childPath, va[i], vb[i], ignore, diffs)
+			}
+		}
+	default:
+		if reflect.TypeOf(a) != reflect.TypeOf(b) {
+			*diffs = append(*diffs, Difference{Path: path, Kind: "type", A: a, B: b})
+			return
+		}
+		if !reflect.DeepEqual(a, b) {
+			*diffs = append(*diffs, Difference{Path: path, Kind: "changed", A: a, B: b})
+		}
+	}
+}
+
+func removePaths(path string, value interface{}, ignore []string) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			childPath := joinPath(path, k)
+			if Ignored(childPath, ignore) {
+				continue
+			}
+			result[k] = removePaths(childPath, child, ignore)
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for i, child := range v {
+			childPath := joinPath(path, strconv.Itoa(i))
+			if Ignored(childPath, ignore) {
+				continue
+			}
+			result = append(result, removePaths(childPath, child, ignore))
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// normalize round-trips values through JSON so maps, slices and numbers share one representation
+func normalize(value interface{}) interface{} {
+	switch value.(type) {
+	case nil, string, bool, float64, map[string]interface{}, []interface{}:
+		if !containsForeignTypes(value) {
+			return value
+		}
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return value
+	}
+	return normalized
+}
+
+func containsForeignTypes(value interface{}) bool {
+	switch v := value.(type) {
+	case nil, string, bool, float64:
+		return false
+	case map[string]interface{}:
+		for _, child := range v {
+			if containsForeignTypes(child) {
+				return true
+			}
+		}
+		return false
+	case []interface{}:
+		for _, child := range v {
+			if containsForeignTypes(child) {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+func joinPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+func matchPath(pattern, path string) bool {
+	patternParts := strings.Split(pattern, ".")
+	pathParts := strings.Split(path, ".")
+	if len(patternParts) != len(pathParts) {
+		return false
+	}
+
+	for i, part := range patternParts {
+		if part != "*" && part != pathParts[i] {
+			return false
+		}
+	}
+	return true
+}
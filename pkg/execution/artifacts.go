@@ -0,0 +1,168 @@
+package execution
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/nulln0ne/fuego/pkg/variables"
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+var artifactExtensions = map[string]string{
+	"application/json":         ".json",
+	"application/pdf":          ".pdf",
+	"application/xml":          ".xml",
+	"text/xml":                 ".xml",
+	"text/html":                ".html",
+	"text/plain":               ".txt",
+	"text/csv":                 ".csv",
+	"image/png":                ".png",
+	"image/jpeg":               ".jpg",
+	"image/gif":                ".gif",
+	"application/zip":          ".zip",
+	"application/octet-stream": ".bin",
+}
+
+// saveArtifacts writes the response body to the step's save_response path, relative paths under
+// the scenario's temporary directory, and, when an artifacts directory is configured, keeps the
+// body of every failing step for post-mortem inspection. Files in the temporary directory are
+// only listed as artifacts when --keep-tmpdir keeps them, so reports do not link removed files.
+func (e *Engine) saveArtifacts(step *scenario.Step, result *reporting.StepResult, varContext *variables.Context) {
+	response, ok := result.Response.(map[string]interface{})
+	if !ok {
+		return
+	}
+
+	body, contentType := responseBody(response)
+
+	if step.SaveResponse != "" {
+		path, err := varContext.InterpolateString(step.SaveResponse)
+		if err == nil {
+			path = e.inTmpDir(path)
+			err = writeArtifact(path, body)
+		}
+		if err != nil {
+			e.failArtifact(result, fmt.Errorf("failed to save response: %w", err))
+		} else if e.options.KeepTmpDirs || !e.underTmpDir(path) {
+			result.Artifacts = append(result.Artifacts, reporting.Artifact{
+				Name: "response", Path: path, ContentType: contentType, Size: int64(len(body)),
+			})
+		}
+	}
+
+	if e.options.ArtifactsDir == "" || result.Status != "failed" {
+		return
+	}
+
+	path := filepath.Join(e.options.ArtifactsDir, e.artifactName(step.Name, contentType))
+	if err := writeArtifact(path, body); err != nil {
+		result.Error = joinErrors(result.Error, fmt.Sprintf("failed to save failing response: %v", err))
+		return
+	}
+	result.Artifacts = append(result.Artifacts, reporting.Artifact{
+		Name: "failing response", Path: path, ContentType: contentType, Size: int64(len(body)),
+	})
+}
+
+func (e *Engine) failArtifact(result *reporting.StepResult, err error) {
+	result.Status = "failed"
+	result.Error = joinErrors(result.Error, err.Error())
+}
+
+// artifactName returns a unique file name for a failing response of the current scenario
+func (e *Engine) artifactName(stepName, contentType string) string {
+	base := strings.Trim(unsafeFileChars.ReplaceAllString(e.currentScenario+"-"+stepName, "_"), "_")
+
+	e.artifactMu.Lock()
+	defer e.artifactMu.Unlock()
+	if e.artifactNames == nil {
+		e.artifactNames = make(map[string]int)
+	}
+	e.artifactNames[base]++
+	if count := e.artifactNames[base]; count > 1 {
+		base = fmt.Sprintf("%s-%d", base, count)
+	}
+
+	return base + artifactExtension(contentType)
+}
+
+// responseBody returns the raw response body and its content type
+func responseBody(response map[string]interface{}) ([]byte, string) {
+	contentType := ""
+	switch headers := response["headers"].(type) {
+	case map[string][]string:
+		for name, values := range headers {
+			if strings.EqualFold(name, "Content-Type") && len(values) > 0 {
+				contentType = values[0]
+			}
+		}
+	case map[string]interface{}:
+		for name, value := range headers {
+			if strings.EqualFold(name, "Content-Type") {
+				contentType = fmt.Sprint(value)
+			}
+		}
+	}
+
+	switch body := response["body"].(type) {
+	case []byte:
+		return body, contentType
+	case string:
+		return []byte(body), contentType
+	case nil:
+		if text, ok := response["body_text"].(string); ok {
+			return []byte(text), contentType
+		}
+		return nil, contentType
+	default:
+		encoded, err := json.MarshalIndent(body, "", "  ")
+		if err != nil {
+			return []byte(fmt.Sprint(body)), contentType
+		}
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		return encoded, contentType
+	}
+}
+
+func artifactExtension(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ".txt"
+	}
+	if ext, ok := artifactExtensions[mediaType]; ok {
+		return ext
+	}
+	if strings.HasSuffix(mediaType, "+json") {
+		return ".json"
+	}
+	if strings.HasSuffix(mediaType, "+xml") {
+		return ".xml"
+	}
+	return ".bin"
+}
+
+func writeArtifact(path string, body []byte) error {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return err
+		}
+	}
+	return os.WriteFile(path, body, 0644)
+}
+
+func joinErrors(existing, message string) string {
+	if existing == "" {
+		return message
+	}
+	return existing + "; " + message
+}
@@ -0,0 +1,19 @@
+package execution
+
+import (
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/protocols"
+)
+
+// certificateMap exposes a server certificate to certificate assertions and captures
+func certificateMap(certificate *protocols.Certificate) map[string]interface{} {
+	return map[string]interface{}{
+		"subject":           certificate.Subject,
+		"issuer":            certificate.Issuer,
+		"sans":              certificate.SANs,
+		"not_before":        certificate.NotBefore.UTC().Format(time.RFC3339),
+		"not_after":         certificate.NotAfter.UTC().Format(time.RFC3339),
+		"days_until_expiry": int(time.Until(certificate.NotAfter).Hours() / 24),
+	}
+}
@@ -0,0 +1,68 @@
+package execution
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/nulln0ne/fuego/pkg/variables"
+)
+
+// registeredCleanup is a cleanup step together with the variables (captured IDs) it was
+// registered with
+type registeredCleanup struct {
+	step       *scenario.Step
+	varContext *variables.Context
+}
+
+// registerCleanup remembers the cleanup of a step that reached the server, so whatever it created
+// is removed even when a later step fails or the run is cancelled
+func (e *Engine) registerCleanup(step *scenario.Step, result reporting.StepResult, varContext *variables.Context) {
+	if step.Cleanup == nil || result.Response == nil {
+		return
+	}
+
+	cleanup := *step.Cleanup
+	cleanup.Cleanup = nil
+	if cleanup.Name == "" {
+		cleanup.Name = "Clean up " + step.Name
+	}
+
+	e.cleanupMu.Lock()
+	defer e.cleanupMu.Unlock()
+	e.cleanups = append(e.cleanups, registeredCleanup{step: &cleanup, varContext: varContext.Clone()})
+}
+
+// runCleanups runs the registered cleanups in reverse order of registration, also after failures
+// and cancellation. A failing cleanup fails the scenario, since it leaves test data behind.
+func (e *Engine) runCleanups(result *reporting.ScenarioResult) {
+	e.cleanupMu.Lock()
+	cleanups := e.cleanups
+	e.cleanups = nil
+	e.cleanupMu.Unlock()
+	if len(cleanups) == 0 {
+		return
+	}
+
+	// Cleanups must reach the server even when the run was cancelled
+	ctx := e.ctx
+	e.ctx = context.WithoutCancel(ctx)
+	defer func() { e.ctx = ctx }()
+
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		cleanup := cleanups[i]
+		stepResult := e.runStepWithRetry(cleanup.step, cleanup.varContext)
+		e.reporter.Emit(reporting.Event{Type: reporting.EventStepFinished, Scenario: e.currentScenario, Run: e.run, Step: &stepResult})
+		result.Steps = append(result.Steps, stepResult)
+
+		if stepResult.Status == "failed" && result.Status != "failed" {
+			result.Status = "failed"
+			result.Error = fmt.Sprintf("Cleanup step '%s' failed", cleanup.step.Name)
+		}
+	}
+
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime)
+}
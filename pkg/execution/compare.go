@@ -0,0 +1,28 @@
+package execution
+
+import (
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+)
+
+// storeResponse keeps the response of a step with store_as for the compare assertions of later
+// steps in the scenario; a later step storing the same name replaces it
+func (e *Engine) storeResponse(step *scenario.Step, result reporting.StepResult) {
+	if step.StoreAs == "" || result.Response == nil {
+		return
+	}
+
+	e.responsesMu.Lock()
+	defer e.responsesMu.Unlock()
+	if e.responses == nil {
+		e.responses = make(map[string]interface{})
+	}
+	e.responses[step.StoreAs] = result.Response
+}
+
+func (e *Engine) storedResponse(name string) (interface{}, bool) {
+	e.responsesMu.Lock()
+	defer e.responsesMu.Unlock()
+	response, exists := e.responses[name]
+	return response, exists
+}
@@ -0,0 +1,70 @@
+package execution
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/nulln0ne/fuego/pkg/variables"
+)
+
+const (
+	defaultCorrelationHeader   = "X-Request-ID"
+	defaultCorrelationVariable = "correlation_id"
+)
+
+func (e *Engine) correlationHeader() string {
+	if header := e.config.Global.CorrelationID.Header; header != "" {
+		return header
+	}
+	return defaultCorrelationHeader
+}
+
+func (e *Engine) correlationVariable() string {
+	if variable := e.config.Global.CorrelationID.Variable; variable != "" {
+		return variable
+	}
+	return defaultCorrelationVariable
+}
+
+// assignCorrelationID stores the correlation ID of the step in its variables: a new ID per step,
+// or the scenario's ID with scope "scenario"
+func (e *Engine) assignCorrelationID(varContext *variables.Context) {
+	if !e.config.Global.CorrelationID.Enabled {
+		return
+	}
+
+	id := e.scenarioCorrelationID
+	if id == "" {
+		id = variables.NewUUID()
+	}
+	varContext.SetStep(e.correlationVariable(), id)
+}
+
+// injectCorrelationID adds the correlation header to a request unless the step sets it itself
+func (e *Engine) injectCorrelationID(step *scenario.Step, varContext *variables.Context) {
+	if !e.config.Global.CorrelationID.Enabled {
+		return
+	}
+
+	id, exists := varContext.Get(e.correlationVariable())
+	if !exists {
+		return
+	}
+
+	header := e.correlationHeader()
+	step.Request.Headers = scenario.MergeHeaders(map[string]string{header: fmt.Sprint(id)}, step.Request.Headers)
+}
+
+// sentCorrelationID returns the correlation header value a request was sent with
+func (e *Engine) sentCorrelationID(step *scenario.Step) string {
+	if step == nil || !e.config.Global.CorrelationID.Enabled {
+		return ""
+	}
+	for name, value := range step.Request.Headers {
+		if strings.EqualFold(name, e.correlationHeader()) && value != scenario.Unset {
+			return value
+		}
+	}
+	return ""
+}
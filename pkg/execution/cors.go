@@ -0,0 +1,136 @@
+package execution
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/protocols"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/nulln0ne/fuego/pkg/variables"
+)
+
+// corsPreflight turns a step into the OPTIONS preflight a browser would send before it: only
+// Origin and the Access-Control-Request-* headers, no body and no credentials
+func corsPreflight(step *scenario.Step, varContext *variables.Context) error {
+	cors := *step.Request.CORS
+	var err error
+	if cors.Origin, err = varContext.InterpolateString(cors.Origin); err != nil {
+		return fmt.Errorf("failed to interpolate CORS origin: %w", err)
+	}
+	if cors.Origin == "" {
+		return fmt.Errorf("CORS checks need an origin")
+	}
+	if cors.Method == "" {
+		cors.Method = step.Request.Method
+	}
+	if cors.Method == "" {
+		cors.Method = http.MethodGet
+	}
+	cors.Method = strings.ToUpper(cors.Method)
+	if cors.Headers == nil {
+		for name, value := range step.Request.Headers {
+			if value != scenario.Unset && !corsSafelisted(name, value) {
+				cors.Headers = append(cors.Headers, http.CanonicalHeaderKey(name))
+			}
+		}
+		sort.Strings(cors.Headers)
+	}
+
+	headers := scenario.Params{"Origin": cors.Origin, "Access-Control-Request-Method": cors.Method}
+	if len(cors.Headers) > 0 {
+		headers["Access-Control-Request-Headers"] = strings.ToLower(strings.Join(cors.Headers, ","))
+	}
+	step.Request.Method = http.MethodOptions
+	step.Request.Headers = headers
+	step.Request.Body = nil
+	step.Request.Auth = nil
+	step.Request.Cookies = nil
+	step.Request.CORS = &cors
+	return nil
+}
+
+// corsSafelisted reports whether browsers send a header without asking the server first
+func corsSafelisted(name, value string) bool {
+	switch strings.ToLower(name) {
+	case "accept", "accept-language", "content-language":
+		return true
+	case "content-type":
+		mediaType, _, _ := strings.Cut(strings.ToLower(value), ";")
+		switch strings.TrimSpace(mediaType) {
+		case "application/x-www-form-urlencoded", "multipart/form-data", "text/plain":
+			return true
+		}
+	}
+	return false
+}
+
+// verifyCORS checks the Access-Control-* headers of a preflight response the way a browser
+// would, and reports every reason the real request would be blocked
+func verifyCORS(cors *scenario.CORSPreflight, response *protocols.HTTPResponse) error {
+	headers := http.Header(response.Headers)
+	var problems []string
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		problems = append(problems, fmt.Sprintf("preflight returned %d", response.StatusCode))
+	}
+
+	allowOrigin := headers.Get("Access-Control-Allow-Origin")
+	switch {
+	case allowOrigin == "":
+		problems = append(problems, "no Access-Control-Allow-Origin")
+	case allowOrigin == "*" && cors.Credentials:
+		problems = append(problems, "Access-Control-Allow-Origin is * but credentials need the origin itself")
+	case allowOrigin != "*" && allowOrigin != cors.Origin:
+		problems = append(problems, fmt.Sprintf("Access-Control-Allow-Origin is %s, not %s", allowOrigin, cors.Origin))
+	}
+
+	if cors.Credentials && headers.Get("Access-Control-Allow-Credentials") != "true" {
+		problems = append(problems, "Access-Control-Allow-Credentials is not true")
+	}
+
+	allowMethods := corsList(headers.Values("Access-Control-Allow-Methods"))
+	safelistedMethod := cors.Method == http.MethodGet || cors.Method == http.MethodHead || cors.Method == http.MethodPost
+	if !safelistedMethod && !allowMethods[strings.ToLower(cors.Method)] && !(allowMethods["*"] && !cors.Credentials) {
+		problems = append(problems, fmt.Sprintf("method %s is not in Access-Control-Allow-Methods", cors.Method))
+	}
+
+	// A * wildcard never covers Authorization
+	allowHeaders := corsList(headers.Values("Access-Control-Allow-Headers"))
+	for _, header := range cors.Headers {
+		name := strings.ToLower(header)
+		wildcard := allowHeaders["*"] && !cors.Credentials && name != "authorization"
+		if !allowHeaders[name] && !wildcard {
+			problems = append(problems, fmt.Sprintf("header %s is not in Access-Control-Allow-Headers", header))
+		}
+	}
+
+	if cors.MaxAge > 0 {
+		maxAge, err := strconv.Atoi(headers.Get("Access-Control-Max-Age"))
+		if err != nil {
+			problems = append(problems, "no Access-Control-Max-Age")
+		} else if maxAge < cors.MaxAge {
+			problems = append(problems, fmt.Sprintf("Access-Control-Max-Age is %d, less than %d", maxAge, cors.MaxAge))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("CORS preflight from %s for %s: %s", cors.Origin, cors.Method, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// corsList reads comma-separated header values into a lower-case set
+func corsList(values []string) map[string]bool {
+	set := make(map[string]bool)
+	for _, value := range values {
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+				set[item] = true
+			}
+		}
+	}
+	return set
+}
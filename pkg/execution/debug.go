@@ -0,0 +1,51 @@
+package execution
+
+import (
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/nulln0ne/fuego/pkg/variables"
+)
+
+// DebugAction tells the engine how to proceed after a step was executed under a debugger
+type DebugAction int
+
+const (
+	// DebugContinue records the result and moves on to the next step
+	DebugContinue DebugAction = iota
+	// DebugRerun executes the step again, using the replacement step when one is returned
+	DebugRerun
+	// DebugAbort skips every remaining step and scenario
+	DebugAbort
+)
+
+// Debugger is consulted after every executed step. It may inspect and modify the variable context.
+type Debugger interface {
+	AfterStep(step *scenario.Step, result reporting.StepResult, varContext *variables.Context) (DebugAction, *scenario.Step)
+}
+
+// debugStep runs a step under the configured debugger until it asks to continue or abort
+func (e *Engine) debugStep(step *scenario.Step, varContext *variables.Context) reporting.StepResult {
+	for {
+		result := e.runStep(step, varContext)
+		applyKnownFailure(&result, step.KnownFailure)
+
+		// Parallel test groups consult the debugger one at a time, and no longer once it aborted
+		e.debugMu.Lock()
+		action, replacement := DebugContinue, (*scenario.Step)(nil)
+		if !e.aborted.Load() {
+			action, replacement = e.options.Debugger.AfterStep(step, result, varContext)
+			if action == DebugAbort {
+				e.aborted.Store(true)
+			}
+		}
+		e.debugMu.Unlock()
+
+		if action == DebugRerun {
+			if replacement != nil {
+				step = replacement
+			}
+			continue
+		}
+		return result
+	}
+}
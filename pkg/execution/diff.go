@@ -0,0 +1,86 @@
+package execution
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/diff"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+)
+
+// StepDiff holds the comparison of one GET step executed against two environments
+type StepDiff struct {
+	Group       string            `json:"group,omitempty"`
+	Step        string            `json:"step"`
+	URLA        string            `json:"url_a"`
+	URLB        string            `json:"url_b"`
+	StatusA     int               `json:"status_a"`
+	StatusB     int               `json:"status_b"`
+	Differences []diff.Difference `json:"differences,omitempty"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// Equal reports whether both environments produced the same status and body
+func (d StepDiff) Equal() bool {
+	return d.Error == "" && d.StatusA == d.StatusB && len(d.Differences) == 0
+}
+
+// DiffScenario executes each GET step of a scenario with both engines and compares the responses.
+// Non-GET steps are skipped so parity checks never mutate either environment. JSON bodies are
+// compared structurally, skipping the ignore paths; other bodies are compared as text.
+func DiffScenario(a, b *Engine, sc *scenario.Scenario, ignore []string) ([]StepDiff, error) {
+	stepsA, err := a.resolveHTTPSteps(sc)
+	if err != nil {
+		return nil, fmt.Errorf("environment A: %w", err)
+	}
+	stepsB, err := b.resolveHTTPSteps(sc)
+	if err != nil {
+		return nil, fmt.Errorf("environment B: %w", err)
+	}
+
+	var results []StepDiff
+	for i := range stepsA {
+		stepA, stepB := stepsA[i], stepsB[i]
+		if !strings.EqualFold(stepA.Step.Request.Method, "GET") {
+			continue
+		}
+
+		result := StepDiff{
+			Group: stepA.Group,
+			Step:  stepA.Name,
+			URLA:  stepA.Step.Request.URL,
+			URLB:  stepB.Step.Request.URL,
+		}
+
+		respA, err := a.httpClient.Execute(stepA.Step)
+		if err != nil {
+			result.Error = fmt.Sprintf("environment A: %v", err)
+			results = append(results, result)
+			continue
+		}
+		respB, err := b.httpClient.Execute(stepB.Step)
+		if err != nil {
+			result.Error = fmt.Sprintf("environment B: %v", err)
+			results = append(results, result)
+			continue
+		}
+
+		result.StatusA = respA.StatusCode
+		result.StatusB = respB.StatusCode
+
+		if json.Valid(respA.Body) && json.Valid(respB.Body) {
+			differences, err := diff.CompareJSON(respA.Body, respB.Body, ignore)
+			if err != nil {
+				result.Error = err.Error()
+			}
+			result.Differences = differences
+		} else if respA.BodyText != respB.BodyText {
+			result.Differences = []diff.Difference{{Kind: "changed", A: respA.BodyText, B: respB.BodyText}}
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
@@ -1,130 +1,325 @@
 package execution
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"math/rand"
+	"os"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/nulln0ne/fuego/pkg/assertions"
 	"github.com/nulln0ne/fuego/pkg/config"
 	"github.com/nulln0ne/fuego/pkg/data"
+	"github.com/nulln0ne/fuego/pkg/expr"
+	"github.com/nulln0ne/fuego/pkg/integrations"
 	"github.com/nulln0ne/fuego/pkg/protocols"
 	"github.com/nulln0ne/fuego/pkg/reporting"
 	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/nulln0ne/fuego/pkg/snapshot"
 	"github.com/nulln0ne/fuego/pkg/variables"
 )
 
 type Engine struct {
 	config     *config.Config
+	options    Options
 	reporter   *reporting.Reporter
 	varContext *variables.Context
 	httpClient *protocols.HTTPClient
 	dataLoader *data.DataLoader
+	snapshots  *snapshot.Store
+
+	// currentScenario is the name of the scenario being executed, used to namespace snapshots
+	currentScenario string
+	// httpDefaults are the request header and query defaults of the current scenario
+	httpDefaults *scenario.HTTPConfig
+	// seed of the run, reported so random choices can be reproduced
+	seed int64
+	// scenarioCorrelationID is shared by all steps of the scenario with correlation scope "scenario"
+	scenarioCorrelationID string
+	// ctx is the context of the current run, passed on to custom step executors
+	ctx context.Context
+	// run is the repetition of the running scenario (0 without --repeat), reported in events
+	run int
+	// aborted is set when a debugger stops the run; remaining steps are skipped. Test groups
+	// running in parallel take turns at the debugger under debugMu.
+	aborted atomic.Bool
+	debugMu sync.Mutex
+	// artifactNames counts failing-response artifacts per name so repeated steps get unique files
+	artifactNames map[string]int
+	artifactMu    sync.Mutex
+	// faultRand rolls which requests get an injected fault
+	faultRand *rand.Rand
+	faultMu   sync.Mutex
+	// thinkTime is waited between the steps of the current scenario; stepsStarted counts its
+	// steps so the first one starts right away. pacing spaces its data-driven iterations.
+	thinkTime    scenario.Pause
+	stepsStarted int32
+	pacing       scenario.Pause
+	// pauseRand picks think times and pacing within their ranges
+	pauseRand *rand.Rand
+	pauseMu   sync.Mutex
+	// cleanups are registered by steps of the current scenario that created something
+	cleanups  []registeredCleanup
+	cleanupMu sync.Mutex
+	// protoSchemas caches the .proto files steps decode responses with, by path
+	protoSchemas sync.Map
+	// responses are the responses of the current scenario stored with store_as
+	responses   map[string]interface{}
+	responsesMu sync.Mutex
+	// tmpDir is the temporary directory of the current scenario run, where relative files go
+	tmpDir string
+	// outputs appends the rows of step output blocks to their files
+	outputs *data.Sink
+}
+
+// Options controls run-wide engine behavior that is not part of the config file
+type Options struct {
+	SnapshotDir     string
+	UpdateSnapshots bool
+
+	// Repeat runs every scenario this many times to detect flaky steps
+	Repeat int
+	// StopOnFailure ends repeated runs as soon as any run fails
+	StopOnFailure bool
+
+	// Debugger is consulted after every step when set
+	Debugger Debugger
+
+	// Trace receives redacted wire dumps of every request, preceded by the step name
+	Trace io.Writer
+
+	// ArtifactsDir receives the response body of every failing step when set
+	ArtifactsDir string
+
+	// Seed drives data sampling, shuffling and generated data; 0 picks a seed, which is reported
+	Seed int64
+
+	// Progress shows a live progress bar per scenario when set; it follows the reporter's events
+	Progress *reporting.Progress
+
+	// KeepTmpDirs keeps the temporary directory of every scenario ({{tmpdir}}) instead of
+	// removing it when the scenario ends
+	KeepTmpDirs bool
+
+	// OnResponse is called after every HTTP response with the step as written, the step as sent
+	// and the response status; scenarios running in parallel call it concurrently
+	OnResponse func(step, sent *scenario.Step, status int)
 }
 
 func NewEngine(cfg *config.Config, reporter *reporting.Reporter) *Engine {
+	return NewEngineWithOptions(cfg, reporter, Options{})
+}
+
+func NewEngineWithOptions(cfg *config.Config, reporter *reporting.Reporter, options Options) *Engine {
 	varContext := variables.NewContext()
 	varContext.AddBuiltins()
+	if frozen, err := config.ParseFreezeTime(cfg.Global.FreezeTime); err == nil && !frozen.IsZero() {
+		varContext.SetClock(variables.FrozenClock(frozen))
+	}
+
+	if options.Trace != nil {
+		options.Trace = protocols.NewSyncWriter(options.Trace)
+	}
 
 	// Add global variables from config
 	for k, v := range cfg.Global.Variables {
-		varContext.SetGlobal(k, v)
+		varContext.DeclareGlobal(k, v)
+		varContext.DeclareNamespaced(variables.NamespaceConfig, k, v)
 	}
 
 	// Create HTTP client
 	httpClient := protocols.NewHTTPClient(protocols.HTTPClientConfig{
-		BaseURL:         cfg.Global.BaseURL,
-		Headers:         cfg.Global.Headers,
-		Timeout:         cfg.Defaults.HTTPTimeout,
-		VerifySSL:       cfg.Defaults.VerifySSL,
-		FollowRedirects: cfg.Defaults.FollowRedirect,
+		BaseURL:             cfg.Global.BaseURL,
+		Headers:             cfg.Global.Headers,
+		Query:               cfg.Global.Query,
+		Timeout:             cfg.Defaults.HTTPTimeout,
+		VerifySSL:           cfg.Defaults.VerifySSL,
+		FollowRedirects:     cfg.Defaults.FollowRedirect,
+		Trace:               options.Trace,
+		IPVersion:           cfg.Defaults.IPVersion,
+		Middleware:          cfg.Global.Middleware,
+		NetworkRetries:      cfg.Defaults.NetworkRetries,
+		NetworkRetryMethods: cfg.Defaults.NetworkRetryMethods,
+		RetryDelay:          cfg.Defaults.RetryDelay,
+		CircuitBreaker: protocols.CircuitBreakerConfig{
+			Failures: cfg.Defaults.CircuitBreaker.Failures,
+			Cooldown: cfg.Defaults.CircuitBreaker.Cooldown,
+		},
 	})
 
 	// Create data loader (using current working directory as base)
 	dataLoader := data.NewDataLoader(".")
+	dataLoader.SetRemote(remoteData(cfg.Data))
+	dataLoader.SetAgeKeyFile(os.ExpandEnv(cfg.Encryption.AgeKeyFile))
+
+	for _, webhook := range cfg.Webhooks {
+		headers := make(map[string]string, len(webhook.Headers))
+		for name, value := range webhook.Headers {
+			headers[name] = os.ExpandEnv(value)
+		}
+		reporter.AddSink(reporting.NewWebhookSink(webhook.URL, headers, webhook.Events, webhook.Timeout))
+	}
+	for _, sink := range integrations.Sinks(cfg.Integrations) {
+		reporter.AddSink(sink)
+	}
+	if options.Progress != nil {
+		reporter.Subscribe(options.Progress.HandleEvent)
+	}
 
 	return &Engine{
 		config:     cfg,
+		options:    options,
 		reporter:   reporter,
 		varContext: varContext,
 		httpClient: httpClient,
 		dataLoader: dataLoader,
+		snapshots:  snapshot.NewStore(options.SnapshotDir, options.UpdateSnapshots),
+		ctx:        context.Background(),
+		seed:       newRunSeed(options.Seed),
+		outputs:    data.NewSink(),
 	}
 }
 
+// Seed returns the seed of the run
+func (e *Engine) Seed() int64 {
+	return e.seed
+}
+
 func (e *Engine) ExecuteScenarios(scenarios []*scenario.Scenario) error {
+	return e.ExecuteScenariosContext(context.Background(), scenarios)
+}
+
+// ExecuteScenariosContext runs scenarios until ctx is cancelled. Once it is, the remaining steps
+// are skipped and the registered cleanups of the current scenario still run; the report is still
+// generated for the scenarios that ran.
+func (e *Engine) ExecuteScenariosContext(ctx context.Context, scenarios []*scenario.Scenario) error {
+	e.ctx = ctx
 	e.reporter.Start()
+	e.reporter.SetSeed(e.seed)
+
+	// A down environment would fail every step; stop before the first one
+	if len(e.config.Preflight) > 0 {
+		results := e.runPreflight()
+		e.reporter.SetPreflight(results)
+		if err := preflightError(results); err != nil {
+			e.recordClientStats()
+			if reportErr := e.reporter.GenerateReport(); reportErr != nil {
+				return reportErr
+			}
+			return err
+		}
+	}
+
+	repeat := e.options.Repeat
+	if repeat < 1 {
+		repeat = 1
+	}
 
+	total := 0
 	for _, sc := range scenarios {
-		result := e.executeScenario(sc)
-		e.reporter.AddScenarioResult(result)
+		total += len(scenario.ExpandMatrix(sc))
 	}
+	e.reporter.Emit(reporting.Event{Type: reporting.EventRunStarted, Scenarios: total * repeat})
+
+	// Repeated runs of a scenario are spaced by its pacing
+	pacers := make(map[string]*pacer)
+
+runs:
+	for run := 1; run <= repeat; run++ {
+		for _, sc := range scenarios {
+			// Matrix scenarios run and report once per combination
+			for _, expanded := range scenario.ExpandMatrix(sc) {
+				if ctx.Err() != nil || e.aborted.Load() {
+					break runs
+				}
 
-	return e.reporter.GenerateReport()
+				e.run = 0
+				if repeat > 1 {
+					e.run = run
+				}
+				if pacers[expanded.Name] == nil {
+					pacers[expanded.Name] = e.newPacer(e.scenarioPacing(expanded))
+				}
+				pacers[expanded.Name].wait()
+				e.reporter.Emit(reporting.Event{Type: reporting.EventScenarioStarted, Scenario: expanded.Name, Run: e.run, Steps: plannedSteps(expanded)})
+				result := e.executeScenario(expanded)
+				result.Run = e.run
+				e.reporter.AddScenarioResult(result)
+
+				if result.Status == "failed" && repeat > 1 && e.options.StopOnFailure {
+					break runs
+				}
+			}
+		}
+	}
+
+	e.recordClientStats()
+	if err := e.reporter.GenerateReport(); err != nil {
+		return err
+	}
+	return ctx.Err()
+}
+
+// plannedSteps counts the steps a scenario declares, for progress display
+func plannedSteps(sc *scenario.Scenario) int {
+	count := len(sc.Setup) + len(sc.Steps) + len(sc.Teardown)
+	for _, group := range []*scenario.TestGroup{sc.Before, sc.After} {
+		if group != nil {
+			count += len(group.Steps)
+		}
+	}
+	for _, test := range sc.Tests {
+		count += len(test.Steps)
+	}
+	return count
 }
 
 func (e *Engine) executeScenario(sc *scenario.Scenario) reporting.ScenarioResult {
+	if err := e.enterTmpDir(sc); err != nil {
+		now := time.Now()
+		return reporting.ScenarioResult{Scenario: sc, Status: "failed", Error: err.Error(), StartTime: now, EndTime: now}
+	}
+	defer e.leaveTmpDir()
+
+	result := e.runScenario(sc)
+	e.runCleanups(&result)
+	return result
+}
+
+func (e *Engine) runScenario(sc *scenario.Scenario) reporting.ScenarioResult {
 	result := reporting.ScenarioResult{
 		Scenario:  sc,
 		StartTime: time.Now(),
 		Steps:     make([]reporting.StepResult, 0),
 		Variables: make(map[string]interface{}),
 	}
+	e.enterScenario(sc)
 
 	// Create scenario-specific variable context
-	scenarioContext := e.varContext.Clone()
-
-	// Add environment variables
-	for k, v := range sc.Env {
-		scenarioContext.SetGlobal(k, v)
-	}
-
-	// Add scenario variables
-	for k, v := range sc.Variables {
-		scenarioContext.SetLocal(k, v)
-	}
-
-	// Apply environment-specific configuration if specified
-	if sc.Config != nil && sc.Config.Environment != "" {
-		if envConfig, exists := e.config.GetEnvironment(sc.Config.Environment); exists {
-			for k, v := range envConfig.Variables {
-				scenarioContext.SetLocal(k, v)
-			}
-		}
-	}
+	scenarioContext := e.newScenarioContext(sc)
 
 	// Load data sources
-	loadedData := make(map[string][]map[string]interface{})
-	for name, scenarioDataSource := range sc.Data {
-		// Convert scenario.DataSource to data.DataSource
-		dataSource := data.DataSource{
-			Type: scenarioDataSource.Type,
-			Path: scenarioDataSource.Path,
-			Data: scenarioDataSource.Data,
-		}
-
-		dataItems, err := e.dataLoader.LoadData(dataSource)
-		if err != nil {
-			result.Status = "failed"
-			result.Error = fmt.Sprintf("Failed to load data source '%s': %v", name, err)
-			result.EndTime = time.Now()
-			result.Duration = result.EndTime.Sub(result.StartTime)
-			return result
-		}
-		loadedData[name] = dataItems
-		// Also make data available as variables
-		scenarioContext.SetLocal(name, dataItems)
+	if err := e.loadScenarioData(sc, scenarioContext); err != nil {
+		result.Status = "failed"
+		result.Error = err.Error()
+		result.EndTime = time.Now()
+		result.Duration = result.EndTime.Sub(result.StartTime)
+		return result
 	}
 
 	// Execute before hook
 	if sc.Before != nil {
 		for _, step := range sc.Before.Steps {
-			stepResult := e.executeStep(&step, scenarioContext)
-			result.Steps = append(result.Steps, stepResult)
-			if stepResult.Status == "failed" {
+			stepResults := e.executeStepIterations(&step, scenarioContext)
+			result.Steps = append(result.Steps, setGroup(stepResults, "before")...)
+			if anyFailed(stepResults) {
 				result.Status = "failed"
 				result.Error = fmt.Sprintf("Before hook step '%s' failed", step.Name)
 				result.EndTime = time.Now()
@@ -137,9 +332,9 @@ func (e *Engine) executeScenario(sc *scenario.Scenario) reporting.ScenarioResult
 	// Execute setup steps (legacy)
 	if len(sc.Setup) > 0 {
 		for _, step := range sc.Setup {
-			stepResult := e.executeStep(&step, scenarioContext)
-			result.Steps = append(result.Steps, stepResult)
-			if stepResult.Status == "failed" && sc.Config != nil && sc.Config.FailFast {
+			stepResults := e.executeStepIterations(&step, scenarioContext)
+			result.Steps = append(result.Steps, setGroup(stepResults, "setup")...)
+			if anyFailed(stepResults) && sc.Config != nil && sc.Config.FailFast {
 				result.Status = "failed"
 				result.Error = fmt.Sprintf("Setup step '%s' failed", step.Name)
 				result.EndTime = time.Now()
@@ -152,10 +347,10 @@ func (e *Engine) executeScenario(sc *scenario.Scenario) reporting.ScenarioResult
 	// Execute main steps (legacy format)
 	if len(sc.Steps) > 0 {
 		for _, step := range sc.Steps {
-			stepResult := e.executeStep(&step, scenarioContext)
-			result.Steps = append(result.Steps, stepResult)
+			stepResults := e.executeStepIterations(&step, scenarioContext)
+			result.Steps = append(result.Steps, stepResults...)
 
-			if stepResult.Status == "failed" && sc.Config != nil && sc.Config.FailFast {
+			if anyFailed(stepResults) && sc.Config != nil && sc.Config.FailFast {
 				result.Status = "failed"
 				result.Error = fmt.Sprintf("Step '%s' failed", step.Name)
 				break
@@ -169,9 +364,16 @@ func (e *Engine) executeScenario(sc *scenario.Scenario) reporting.ScenarioResult
 			// Execute tests concurrently
 			e.executeTestsConcurrently(sc.Tests, scenarioContext, &result)
 		} else {
-			// Execute tests sequentially
-			for testName, test := range sc.Tests {
-				e.executeTestGroup(test, testName, scenarioContext, &result)
+			// Execute tests sequentially, in name order so reports are stable across runs
+			names := make([]string, 0, len(sc.Tests))
+			for testName := range sc.Tests {
+				names = append(names, testName)
+			}
+			sort.Strings(names)
+			for _, testName := range names {
+				first := len(result.Steps)
+				e.executeTestGroup(sc.Tests[testName], testName, scenarioContext, &result)
+				setGroup(result.Steps[first:], testName)
 			}
 		}
 	}
@@ -179,19 +381,30 @@ func (e *Engine) executeScenario(sc *scenario.Scenario) reporting.ScenarioResult
 	// Execute teardown steps (legacy)
 	if len(sc.Teardown) > 0 {
 		for _, step := range sc.Teardown {
-			stepResult := e.executeStep(&step, scenarioContext)
-			result.Steps = append(result.Steps, stepResult)
+			result.Steps = append(result.Steps, setGroup(e.executeStepIterations(&step, scenarioContext), "teardown")...)
 		}
 	}
 
 	// Execute after hook
 	if sc.After != nil {
 		for _, step := range sc.After.Steps {
-			stepResult := e.executeStep(&step, scenarioContext)
-			result.Steps = append(result.Steps, stepResult)
+			result.Steps = append(result.Steps, setGroup(e.executeStepIterations(&step, scenarioContext), "after")...)
 		}
 	}
 
+	// Aggregate latency per logical step and enforce declared SLOs
+	result.Latency = e.aggregateLatency(result.Steps)
+	for _, stats := range result.Latency {
+		if len(stats.Violations) > 0 && result.Status != "failed" {
+			result.Status = "failed"
+			result.Error = fmt.Sprintf("Latency SLO for step '%s' violated: %s", stats.Step, strings.Join(stats.Violations, "; "))
+		}
+	}
+
+	if len(sc.Verify) > 0 {
+		result.Steps = append(result.Steps, e.verifyScenario(sc, result.Steps, scenarioContext))
+	}
+
 	// Determine overall scenario status
 	if result.Status == "" {
 		result.Status = "passed"
@@ -210,23 +423,125 @@ func (e *Engine) executeScenario(sc *scenario.Scenario) reporting.ScenarioResult
 	return result
 }
 
+func (e *Engine) aggregateLatency(steps []reporting.StepResult) []reporting.LatencyStats {
+	slos := make(map[string]map[string]time.Duration)
+	for _, step := range steps {
+		if step.Step != nil && len(step.Step.SLO) > 0 {
+			slos[step.LogicalName()] = step.Step.SLO
+		}
+	}
+
+	stats := reporting.ComputeLatency(steps)
+	for i := range stats {
+		if slo, exists := slos[stats[i].Step]; exists {
+			stats[i].CheckSLO(slo)
+		}
+	}
+
+	return stats
+}
+
+func (e *Engine) newScenarioContext(sc *scenario.Scenario) *variables.Context {
+	scenarioContext := e.varContext.Clone()
+	if e.tmpDir != "" {
+		scenarioContext.SetLocal(tmpdirVariable, e.tmpDir)
+	}
+
+	// Add environment variables
+	for k, v := range sc.Env {
+		scenarioContext.DeclareGlobal(k, v)
+		scenarioContext.DeclareNamespaced(variables.NamespaceScenario, k, v)
+	}
+
+	// Add scenario variables
+	for k, v := range sc.Variables {
+		scenarioContext.DeclareLocal(k, v)
+		scenarioContext.DeclareNamespaced(variables.NamespaceScenario, k, v)
+	}
+
+	// Apply environment-specific configuration if specified
+	if sc.Config != nil && sc.Config.Environment != "" {
+		if envConfig, exists := e.config.GetEnvironment(sc.Config.Environment); exists {
+			for k, v := range envConfig.Variables {
+				scenarioContext.DeclareLocal(k, v)
+				scenarioContext.DeclareNamespaced(variables.NamespaceConfig, k, v)
+			}
+		}
+	}
+
+	return scenarioContext
+}
+
+func (e *Engine) loadScenarioData(sc *scenario.Scenario, scenarioContext *variables.Context) error {
+	sources := make(map[string]data.DataSource, len(sc.Data))
+	for name, scenarioDataSource := range sc.Data {
+		// Convert scenario.DataSource to data.DataSource
+		dataSource := data.DataSource{
+			Type:   scenarioDataSource.Type,
+			Path:   scenarioDataSource.Path,
+			Data:   scenarioDataSource.Data,
+			Rows:   scenarioDataSource.Rows,
+			Fields: scenarioDataSource.Fields,
+			Seed:   scenarioDataSource.Seed,
+			CSV:    (*data.CSVOptions)(scenarioDataSource.CSV),
+			Join:   (*data.Join)(scenarioDataSource.Join),
+			Filter: scenarioDataSource.Filter,
+			Select: scenarioDataSource.Select,
+			Rename: scenarioDataSource.Rename,
+		}
+		if dataSource.Seed == 0 {
+			dataSource.Seed = e.derivedSeed(sc.Name, "data", name)
+		}
+		sources[name] = dataSource
+	}
+
+	loaded, err := e.dataLoader.LoadSources(sources)
+	if err != nil {
+		return err
+	}
+	for name, dataItems := range loaded {
+		// Make data available as variables
+		scenarioContext.SetLocal(name, dataItems)
+		scenarioContext.SetNamespaced(variables.NamespaceData, name, dataItems)
+	}
+
+	return nil
+}
+
+// executeTestsConcurrently runs the groups at the same time, each into a result of its own, and
+// merges them in name order so reports are stable across runs
 func (e *Engine) executeTestsConcurrently(tests map[string]*scenario.TestGroup, varContext *variables.Context, result *reporting.ScenarioResult) {
 	var wg sync.WaitGroup
-	var mu sync.Mutex
+	groupResults := make(map[string]*reporting.ScenarioResult, len(tests))
 
 	for testName, test := range tests {
+		groupResult := &reporting.ScenarioResult{Scenario: result.Scenario}
+		groupResults[testName] = groupResult
+		testVarContext := varContext.Clone()
+
 		wg.Add(1)
 		go func(name string, t *scenario.TestGroup) {
 			defer wg.Done()
-			testVarContext := varContext.Clone()
-
-			mu.Lock()
-			e.executeTestGroup(t, name, testVarContext, result)
-			mu.Unlock()
+			e.executeTestGroup(t, name, testVarContext, groupResult)
+			setGroup(groupResult.Steps, name)
 		}(testName, test)
 	}
 
 	wg.Wait()
+
+	names := make([]string, 0, len(groupResults))
+	for name := range groupResults {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		groupResult := groupResults[name]
+		result.Steps = append(result.Steps, groupResult.Steps...)
+		result.Warnings = append(result.Warnings, groupResult.Warnings...)
+		if groupResult.Status == "failed" && result.Status != "failed" {
+			result.Status, result.Error = groupResult.Status, groupResult.Error
+		}
+	}
 }
 
 func (e *Engine) executeTestGroup(test *scenario.TestGroup, testName string, varContext *variables.Context, result *reporting.ScenarioResult) {
@@ -236,9 +551,22 @@ func (e *Engine) executeTestGroup(test *scenario.TestGroup, testName string, var
 
 	// Add test-level environment variables
 	for k, v := range test.Env {
-		varContext.SetLocal(k, v)
+		varContext.DeclareLocal(k, v)
+		varContext.DeclareNamespaced(variables.NamespaceTest, k, v)
 	}
 
+	if len(test.Matrix) > 0 {
+		e.executeMatrixTestGroup(test, testName, varContext, result)
+		return
+	}
+
+	// on_failure or on_success follows the steps of the group
+	first := len(result.Steps)
+	defer func() {
+		branch := e.executeBranch(test.OnFailure, test.OnSuccess, result.Steps[first:], varContext)
+		result.Steps = append(result.Steps, branch...)
+	}()
+
 	// Check if test group is data-driven
 	if test.DataDriven != nil {
 		e.executeDataDrivenTestGroup(test, testName, varContext, result)
@@ -246,16 +574,72 @@ func (e *Engine) executeTestGroup(test *scenario.TestGroup, testName string, var
 	}
 
 	// Execute test steps
+	expectedFailures := 0
 	for _, step := range test.Steps {
-		stepResult := e.executeStep(&step, varContext)
-		result.Steps = append(result.Steps, stepResult)
+		stepResults := e.executeStepIterations(&step, varContext)
+		for i := range stepResults {
+			if test.KnownFailure != "" && stepResults[i].Status == "failed" {
+				applyKnownFailure(&stepResults[i], test.KnownFailure)
+				expectedFailures++
+			}
+		}
+		result.Steps = append(result.Steps, stepResults...)
 
-		if stepResult.Status == "failed" && !test.ContinueOnFail {
+		if anyFailed(stepResults) && !test.ContinueOnFail {
 			result.Status = "failed"
 			result.Error = fmt.Sprintf("Test '%s' step '%s' failed", testName, step.Name)
 			break
 		}
 	}
+
+	flagUnexpectedGroupPass(test, testName, expectedFailures, result)
+}
+
+// setGroup records the test group or hook the step results ran in and returns them
+func setGroup(stepResults []reporting.StepResult, group string) []reporting.StepResult {
+	for i := range stepResults {
+		stepResults[i].Group = group
+	}
+	return stepResults
+}
+
+// flagUnexpectedGroupPass warns when a test group marked as a known failure ran without failing
+func flagUnexpectedGroupPass(test *scenario.TestGroup, testName string, expectedFailures int, result *reporting.ScenarioResult) {
+	if test.KnownFailure == "" || expectedFailures > 0 {
+		return
+	}
+	result.Warnings = append(result.Warnings, fmt.Sprintf("Test '%s' is marked as known failure %s but passed", testName, test.KnownFailure))
+}
+
+// executeMatrixTestGroup runs the group once per matrix combination, labelling each step result
+// with the combination. A failing combination does not stop the others.
+func (e *Engine) executeMatrixTestGroup(test *scenario.TestGroup, testName string, varContext *variables.Context, result *reporting.ScenarioResult) {
+	for _, combination := range test.Matrix.Expand() {
+		combinationContext := varContext.Clone()
+		for k, v := range combination.Values {
+			combinationContext.SetLocal(k, v)
+			combinationContext.SetNamespaced(variables.NamespaceTest, k, v)
+		}
+
+		group := *test
+		group.Matrix = nil
+
+		first := len(result.Steps)
+		e.executeTestGroup(&group, testName+" "+combination.Label, combinationContext, result)
+		for i := first; i < len(result.Steps); i++ {
+			labelStepResult(&result.Steps[i], combination.Label)
+		}
+	}
+}
+
+// labelStepResult appends a matrix label to the step name, keeping the data item suffix last
+func labelStepResult(stepResult *reporting.StepResult, label string) {
+	if stepResult.Step == nil {
+		return
+	}
+	name := stepResult.LogicalName()
+	suffix := strings.TrimPrefix(stepResult.Step.Name, name)
+	stepResult.Step.Name = name + " " + label + suffix
 }
 
 func (e *Engine) executeDataDrivenTestGroup(test *scenario.TestGroup, testName string, varContext *variables.Context, result *reporting.ScenarioResult) {
@@ -274,105 +658,376 @@ func (e *Engine) executeDataDrivenTestGroup(test *scenario.TestGroup, testName s
 		return
 	}
 
+	dataItems, err := selectDataItems(test.DataDriven, dataItems, varContext, e.derivedSeed(e.currentScenario, "test", testName))
+	if err != nil {
+		result.Status = "failed"
+		result.Error = fmt.Sprintf("Test group '%s': %v", testName, err)
+		return
+	}
+
 	// Execute test steps for each data item
+	expectedFailures := 0
+	var failedRows []map[string]interface{}
+	defer func() {
+		flagUnexpectedGroupPass(test, testName, expectedFailures, result)
+		e.writeFailedRows(test.DataDriven, failedRows, varContext, result)
+	}()
+
+	iterations := e.newPacer(e.pacing)
 	for i, dataItem := range dataItems {
-		// Create a new context for this iteration
-		iterationContext := varContext.Clone()
-
-		// Set the data item variable
-		iterationContext.SetStep(test.DataDriven.Variable, dataItem)
-
-		// Execute test steps
-		for _, step := range test.Steps {
-			stepResult := e.executeStep(&step, iterationContext)
-			stepResult.Step.Name = fmt.Sprintf("%s (data %d)", step.Name, i+1)
-			result.Steps = append(result.Steps, stepResult)
+		iterations.wait()
+
+		var itemResults []reporting.StepResult
+		var failedStep string
+		var itemExpected int
+		for attempt := 1; ; attempt++ {
+			itemResults, failedStep, itemExpected = e.executeDataItem(test, i, dataItem, varContext)
+			if failedStep == "" {
+				if attempt > 1 {
+					result.Warnings = append(result.Warnings, fmt.Sprintf("Test '%s' data item %d passed on attempt %d", testName, i+1, attempt))
+				}
+				break
+			}
+			if attempt > test.DataDriven.Retries || e.aborted.Load() || e.ctx.Err() != nil {
+				break
+			}
+		}
+		expectedFailures += itemExpected
+		result.Steps = append(result.Steps, itemResults...)
 
-			if stepResult.Status == "failed" && !test.ContinueOnFail {
+		if failedStep != "" {
+			failedRows = append(failedRows, dataItem)
+			if !test.ContinueOnFail {
 				result.Status = "failed"
-				result.Error = fmt.Sprintf("Test '%s' step '%s' failed on data item %d", testName, step.Name, i+1)
+				result.Error = fmt.Sprintf("Test '%s' step '%s' failed on data item %d", testName, failedStep, i+1)
 				return
 			}
 		}
 	}
 }
 
-func (e *Engine) executeDataDrivenStep(step *scenario.Step, varContext *variables.Context) reporting.StepResult {
+// executeDataItem runs the steps of a data-driven group for one data item. It returns their
+// results, the first step that failed, and the count of known failures.
+func (e *Engine) executeDataItem(test *scenario.TestGroup, index int, dataItem map[string]interface{}, varContext *variables.Context) ([]reporting.StepResult, string, int) {
+	// Create a new context for this iteration
+	iterationContext := varContext.Clone()
+
+	// Set the data item variable
+	iterationContext.SetStep(test.DataDriven.Variable, dataItem)
+	iterationContext.SetNamespaced(variables.NamespaceData, test.DataDriven.Variable, dataItem)
+
+	var results []reporting.StepResult
+	failedStep := ""
+	expectedFailures := 0
+	for _, step := range test.Steps {
+		stepResults := e.executeStepIterations(&step, iterationContext)
+		for j := range stepResults {
+			// Data-driven steps inside the group keep their own iteration labels
+			if stepResults[j].Iteration == 0 {
+				stepResults[j].Step.Name = fmt.Sprintf("%s (data %d)", step.Name, index+1)
+				stepResults[j].Iteration = index + 1
+			}
+			if test.KnownFailure != "" && stepResults[j].Status == "failed" {
+				applyKnownFailure(&stepResults[j], test.KnownFailure)
+				expectedFailures++
+			}
+		}
+		results = append(results, stepResults...)
+
+		if anyFailed(stepResults) && failedStep == "" {
+			failedStep = step.Name
+			if !test.ContinueOnFail {
+				break
+			}
+		}
+	}
+	return results, failedStep, expectedFailures
+}
+
+// executeDataDrivenStep runs the step once per data item and returns a result for every item, so
+// a failing row is reported even when later rows pass
+func (e *Engine) executeDataDrivenStep(step *scenario.Step, varContext *variables.Context) []reporting.StepResult {
+	failed := func(message string) []reporting.StepResult {
+		stepCopy := *step
+		now := time.Now()
+		return []reporting.StepResult{{Step: &stepCopy, StartTime: now, EndTime: now, Status: "failed", Error: message}}
+	}
+
 	// Get data source
 	dataSource, exists := varContext.Get(step.DataDriven.Source)
 	if !exists {
-		return reporting.StepResult{
-			Step:      step,
-			StartTime: time.Now(),
-			EndTime:   time.Now(),
-			Status:    "failed",
-			Error:     fmt.Sprintf("Data source '%s' not found for step '%s'", step.DataDriven.Source, step.Name),
-		}
+		return failed(fmt.Sprintf("Data source '%s' not found for step '%s'", step.DataDriven.Source, step.Name))
 	}
 
 	dataItems, ok := dataSource.([]map[string]interface{})
 	if !ok {
-		return reporting.StepResult{
-			Step:      step,
-			StartTime: time.Now(),
-			EndTime:   time.Now(),
-			Status:    "failed",
-			Error:     fmt.Sprintf("Data source '%s' is not a valid data array", step.DataDriven.Source),
-		}
+		return failed(fmt.Sprintf("Data source '%s' is not a valid data array", step.DataDriven.Source))
 	}
 
-	// Execute step for each data item - for now, we'll execute and return the last result
-	// In a real implementation, you might want to collect all results
-	var lastResult reporting.StepResult
-	for i, dataItem := range dataItems {
-		// Create a new context for this iteration
-		iterationContext := varContext.Clone()
+	dataItems, err := selectDataItems(step.DataDriven, dataItems, varContext, e.derivedSeed(e.currentScenario, "step", step.Name))
+	if err != nil {
+		return failed(fmt.Sprintf("Step '%s': %v", step.Name, err))
+	}
 
-		// Set the data item variable
-		iterationContext.SetStep(step.DataDriven.Variable, dataItem)
+	results := make([]reporting.StepResult, 0, len(dataItems))
+	var failedRows []map[string]interface{}
+	iterations := e.newPacer(e.pacing)
+	for i, dataItem := range dataItems {
+		iterations.wait()
 
 		// Create a modified step without data-driven config to avoid infinite recursion
 		modifiedStep := *step
 		modifiedStep.DataDriven = nil
 		modifiedStep.Name = fmt.Sprintf("%s (data %d)", step.Name, i+1)
 
-		lastResult = e.executeStep(&modifiedStep, iterationContext)
+		var stepResult reporting.StepResult
+		for attempt := 1; ; attempt++ {
+			// Create a new context for this iteration
+			iterationContext := varContext.Clone()
 
-		// If step fails and we don't want to continue, break
-		if lastResult.Status == "failed" {
-			break
+			// Set the data item variable
+			iterationContext.SetStep(step.DataDriven.Variable, dataItem)
+			iterationContext.SetNamespaced(variables.NamespaceData, step.DataDriven.Variable, dataItem)
+
+			stepResult = e.executeStep(&modifiedStep, iterationContext)
+			if stepResult.Status != "failed" || attempt > step.DataDriven.Retries || e.aborted.Load() || e.ctx.Err() != nil {
+				break
+			}
+		}
+		stepResult.Iteration = i + 1
+		if stepResult.Status == "failed" {
+			failedRows = append(failedRows, dataItem)
 		}
+		results = append(results, stepResult)
 	}
 
-	return lastResult
+	if step.DataDriven.FailedRows != "" {
+		if err := writeFailedRows(step.DataDriven.FailedRows, failedRows, varContext); err != nil {
+			return append(results, failed(err.Error())...)
+		}
+	}
+	return results
+}
+
+// writeFailedRows writes the rows of a data-driven test group that still failed after retries
+func (e *Engine) writeFailedRows(cfg *scenario.DataDrivenConfig, rows []map[string]interface{}, varContext *variables.Context, result *reporting.ScenarioResult) {
+	if cfg.FailedRows == "" {
+		return
+	}
+	if err := writeFailedRows(cfg.FailedRows, rows, varContext); err != nil {
+		result.Warnings = append(result.Warnings, err.Error())
+	}
+}
+
+func writeFailedRows(path string, rows []map[string]interface{}, varContext *variables.Context) error {
+	path, err := varContext.InterpolateString(path)
+	if err == nil {
+		err = data.WriteRows(path, rows)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write failed rows: %w", err)
+	}
+	return nil
+}
+
+// executeStepIterations runs a step and returns its results: one per data item for data-driven
+// steps, a single result otherwise
+func (e *Engine) executeStepIterations(step *scenario.Step, varContext *variables.Context) []reporting.StepResult {
+	var results []reporting.StepResult
+	if step.DataDriven != nil && !e.aborted.Load() {
+		results = e.executeDataDrivenStep(step, varContext)
+	} else {
+		results = []reporting.StepResult{e.executeStep(step, varContext)}
+	}
+	return append(results, e.executeBranch(step.OnFailure, step.OnSuccess, results, varContext)...)
+}
+
+// executeBranch runs the on_failure steps when one of results failed, or the on_success steps
+// when they all passed. Branch steps share the variables of the flow, so their captures are
+// visible to the steps that follow; when every result was skipped neither branch runs.
+func (e *Engine) executeBranch(onFailure, onSuccess []scenario.Step, results []reporting.StepResult, varContext *variables.Context) []reporting.StepResult {
+	if len(onFailure) == 0 && len(onSuccess) == 0 {
+		return nil
+	}
+
+	branch := onSuccess
+	if anyFailed(results) {
+		branch = onFailure
+	} else {
+		ran := false
+		for _, result := range results {
+			if result.Status != "skipped" {
+				ran = true
+				break
+			}
+		}
+		if !ran {
+			return nil
+		}
+	}
+
+	var branchResults []reporting.StepResult
+	for _, step := range branch {
+		branchResults = append(branchResults, e.executeStepIterations(&step, varContext)...)
+	}
+	return branchResults
+}
+
+func anyFailed(results []reporting.StepResult) bool {
+	for _, result := range results {
+		if result.Status == "failed" {
+			return true
+		}
+	}
+	return false
+}
+
+// selectDataItems applies the filter, sample and shuffle settings of a data-driven config. The
+// config's own seed wins over the run-derived one.
+func selectDataItems(cfg *scenario.DataDrivenConfig, dataItems []map[string]interface{}, varContext *variables.Context, runSeed int64) ([]map[string]interface{}, error) {
+	selected := dataItems
+
+	if cfg.Filter != "" {
+		filter, err := expr.Compile(cfg.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("invalid data filter: %w", err)
+		}
+
+		selected = make([]map[string]interface{}, 0, len(dataItems))
+		for i, dataItem := range dataItems {
+			keep, err := filter.Eval(expr.Env{Resolve: dataItemResolver(cfg.Variable, dataItem, varContext)})
+			if err != nil {
+				return nil, fmt.Errorf("data filter failed on row %d: %w", i+1, err)
+			}
+			if expr.Truthy(keep) {
+				selected = append(selected, dataItem)
+			}
+		}
+	}
+
+	if cfg.Sample <= 0 && !cfg.Shuffle {
+		return selected, nil
+	}
+
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = runSeed
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	order := rng.Perm(len(selected))
+	if cfg.Sample > 0 && cfg.Sample < len(order) {
+		order = order[:cfg.Sample]
+		if !cfg.Shuffle {
+			// Sampled rows keep their file order
+			sort.Ints(order)
+		}
+	}
+
+	result := make([]map[string]interface{}, 0, len(order))
+	for _, index := range order {
+		result = append(result, selected[index])
+	}
+	return result, nil
+}
+
+// dataItemResolver resolves filter names against the row's fields, the row bound to its
+// data-driven variable, and then the scenario variables
+func dataItemResolver(variable string, dataItem map[string]interface{}, varContext *variables.Context) expr.Resolver {
+	return func(name string) (interface{}, bool) {
+		if value, ok := expr.Lookup(dataItem, name); ok {
+			return value, true
+		}
+		if name == variable {
+			return dataItem, true
+		}
+		if strings.HasPrefix(name, variable+".") {
+			return expr.Lookup(dataItem, strings.TrimPrefix(name, variable+"."))
+		}
+		return varContext.GetNested(name)
+	}
 }
 
 func (e *Engine) executeStep(step *scenario.Step, varContext *variables.Context) reporting.StepResult {
+	var result reporting.StepResult
+	switch {
+	case e.aborted.Load() || e.ctx.Err() != nil:
+		stepCopy := *step
+		now := time.Now()
+		result = reporting.StepResult{Step: &stepCopy, Status: "skipped", Error: "aborted", StartTime: now, EndTime: now}
+	case e.options.Debugger != nil:
+		result = e.debugStep(step, varContext)
+	default:
+		e.think(step)
+		result = e.runStepWithRetry(step, varContext)
+		e.writeOutput(step, &result, varContext)
+		applyKnownFailure(&result, step.KnownFailure)
+		e.registerCleanup(step, result, varContext)
+		e.storeResponse(step, result)
+	}
+
+	e.reporter.Emit(reporting.Event{Type: reporting.EventStepFinished, Scenario: e.currentScenario, Run: e.run, Step: &result})
+	return result
+}
+
+// applyKnownFailure turns the failure of a step marked with a known bug into an expected failure,
+// and flags a pass as unexpected so the marker gets removed once the bug is fixed
+func applyKnownFailure(result *reporting.StepResult, ticket string) {
+	if ticket == "" {
+		return
+	}
+
+	switch result.Status {
+	case "failed":
+		result.Status = "expected_failure"
+		result.KnownFailure = ticket
+	case "passed":
+		result.Status = "unexpected_pass"
+		result.KnownFailure = ticket
+	}
+}
+
+func (e *Engine) runStep(step *scenario.Step, varContext *variables.Context) reporting.StepResult {
+	// Results keep their own copy of the step so they never alias loop variables
+	stepCopy := *step
 	result := reporting.StepResult{
-		Step:      step,
+		Step:      &stepCopy,
 		StartTime: time.Now(),
 		Variables: make(map[string]interface{}),
 	}
+	// Results report only the variables the step set or changed
+	before := varContext.Snapshot()
 
 	// Add step variables
 	for k, v := range step.Variables {
 		varContext.SetStep(k, v)
+		varContext.SetNamespaced(variables.NamespaceStep, k, v)
 	}
 
-	// If this is just a variable-setting step, mark as passed.
+	// If this is just a variable-setting step, mark as passed. Its assertions can still check
+	// variables set so far.
 	if step.Type == "" && step.HTTP == nil {
 		result.Status = "passed"
+		if len(step.Assertions) > 0 {
+			assertionResults, err := e.newAssertionEngine(step, varContext).RunAssertions(step.Assertions, nil)
+			if err != nil {
+				result.Status = "failed"
+				result.Error = fmt.Sprintf("Assertion error: %v", err)
+			}
+			result.Assertions = assertionResults
+			for _, assertionResult := range assertionResults {
+				if !assertionResult.Passed {
+					result.Status = "failed"
+					break
+				}
+			}
+		}
 		result.EndTime = time.Now()
 		result.Duration = result.EndTime.Sub(result.StartTime)
-		result.Variables = varContext.GetAll()
+		result.Variables = varContext.Changed(before)
 		return result
 	}
 
-	// Check if step is data-driven
-	if step.DataDriven != nil {
-		return e.executeDataDrivenStep(step, varContext)
-	}
-
 	// Check condition if specified
 	if step.Condition != "" {
 		passed, err := e.evaluateCondition(step.Condition, varContext)
@@ -393,18 +1048,41 @@ func (e *Engine) executeStep(step *scenario.Step, varContext *variables.Context)
 		}
 	}
 
+	if e.options.Trace != nil {
+		fmt.Fprintf(e.options.Trace, "\n=== %s / %s ===\n", e.currentScenario, step.Name)
+	}
+
+	e.assignCorrelationID(varContext)
+
+	timing := &reporting.StepTiming{}
+	result.Timing = timing
+
+	var fault *scenario.Fault
+	if step.HTTP != nil || step.Type == "http" {
+		if fault = e.pickFault(step); fault != nil {
+			result.Faults = []string{fault.String()}
+		}
+	}
+
 	// Handle new HTTP step format
-	if step.HTTP != nil {
-		response, err := e.executeHTTPStepNew(step, varContext)
+	if step.Parallel != nil && (step.HTTP != nil || step.Type == "http") {
+		e.runParallelRequests(step, varContext, timing, fault, &result)
+	} else if step.HTTP != nil {
+		sentStep, response, err := e.executeHTTPStepNew(step, varContext, timing, fault)
+		result.CorrelationID = e.sentCorrelationID(sentStep)
+		if err != nil && expectsTransportError(step) {
+			response, err = transportErrorResponse(err, timing.Request)
+		}
 		if err != nil {
 			result.Status = "failed"
 			result.Error = err.Error()
+			result.Curl = e.curlCommand(sentStep)
 		} else {
 			result.Response = response
 			result.Status = "passed"
 
 			// Process captures
-			e.processCaptures(step.Capture, response, varContext)
+			timed(&timing.Captures, func() { e.processCaptures(step.Capture, response, varContext) })
 
 			// Run checks (new format assertions)
 			if len(step.Check) > 0 || len(step.HTTP.Check) > 0 {
@@ -418,34 +1096,61 @@ func (e *Engine) executeStep(step *scenario.Step, varContext *variables.Context)
 						checks[k] = v
 					}
 				}
-				assertionResults := e.processChecks(checks, response, varContext)
+				var assertionResults []assertions.Result
+				timed(&timing.Assertions, func() { assertionResults = e.processChecks(step, checks, response, varContext) })
 				result.Assertions = assertionResults
+			}
 
-				// Check if any assertion failed
-				for _, assertionResult := range assertionResults {
-					if !assertionResult.Passed {
-						result.Status = "failed"
-						break
-					}
+			// Full assertions (field, operator) complement the check map
+			if len(step.Assertions) > 0 {
+				var assertionResults []assertions.Result
+				var err error
+				timed(&timing.Assertions, func() {
+					assertionResults, err = e.newAssertionEngine(step, varContext).RunAssertions(step.Assertions, response)
+				})
+				if err != nil {
+					result.Status = "failed"
+					result.Error = fmt.Sprintf("Assertion error: %v", err)
+				}
+				result.Assertions = append(result.Assertions, assertionResults...)
+			}
+
+			// Check if any assertion failed
+			for _, assertionResult := range result.Assertions {
+				if !assertionResult.Passed {
+					result.Status = "failed"
+					break
 				}
 			}
+
+			if result.Status == "failed" {
+				result.Curl = e.curlCommand(sentStep)
+			}
 		}
 	} else {
 		// Execute based on step type (legacy format)
 		switch step.Type {
 		case "http":
-			response, err := e.executeHTTPStep(step, varContext)
+			sentStep, response, err := e.executeHTTPStep(step, varContext, timing, fault)
+			result.CorrelationID = e.sentCorrelationID(sentStep)
+			if err != nil && expectsTransportError(step) {
+				response, err = transportErrorResponse(err, timing.Request)
+			}
 			if err != nil {
 				result.Status = "failed"
 				result.Error = err.Error()
+				result.Curl = e.curlCommand(sentStep)
 			} else {
 				result.Response = response
 				result.Status = "passed"
 
 				// Run assertions
 				if len(step.Assertions) > 0 {
-					assertionEngine := assertions.NewEngine(varContext)
-					assertionResults, err := assertionEngine.RunAssertions(step.Assertions, response)
+					var assertionResults []assertions.Result
+					var err error
+					timed(&timing.Assertions, func() {
+						assertionResults, err = e.newAssertionEngine(step, varContext).RunAssertions(step.Assertions, response)
+					})
 					if err != nil {
 						result.Status = "failed"
 						result.Error = fmt.Sprintf("Assertion error: %v", err)
@@ -463,21 +1168,88 @@ func (e *Engine) executeStep(step *scenario.Step, varContext *variables.Context)
 				}
 
 				// Extract variables from response
-				e.extractVariables(step, response, varContext)
+				timed(&timing.Captures, func() { e.extractVariables(step, response, varContext) })
+
+				if result.Status == "failed" {
+					result.Curl = e.curlCommand(sentStep)
+				}
 			}
 		default:
-			result.Status = "failed"
-			result.Error = fmt.Sprintf("Unsupported step type: %s", step.Type)
+			executor, exists := protocols.LookupStepType(step.Type)
+			if !exists {
+				result.Status = "failed"
+				result.Error = fmt.Sprintf("Unsupported step type: %s", step.Type)
+				break
+			}
+			e.executeCustomStep(step, executor, varContext, &result, timing)
 		}
 	}
 
+	e.saveArtifacts(step, &result, varContext)
+
 	result.EndTime = time.Now()
 	result.Duration = result.EndTime.Sub(result.StartTime)
-	result.Variables = varContext.GetAll()
+	result.Variables = varContext.Changed(before)
 
 	return result
 }
 
+// executeCustomStep runs a step type registered through protocols.RegisterStepType and applies the
+// step's assertions, checks and captures to the returned response
+func (e *Engine) executeCustomStep(step *scenario.Step, executor protocols.StepExecutor, varContext *variables.Context, result *reporting.StepResult, timing *reporting.StepTiming) {
+	sentStep := *step
+	if step.Config != nil {
+		var interpolated interface{}
+		var err error
+		timed(&timing.Interpolation, func() { interpolated, err = varContext.InterpolateInterface(step.Config) })
+		if err != nil {
+			result.Status = "failed"
+			result.Error = fmt.Sprintf("failed to interpolate step config: %v", err)
+			return
+		}
+		sentStep.Config, _ = interpolated.(map[string]interface{})
+	}
+
+	var response map[string]interface{}
+	var err error
+	timed(&timing.Request, func() { response, err = executor.Execute(e.ctx, &sentStep) })
+	if err != nil {
+		result.Status = "failed"
+		result.Error = err.Error()
+		return
+	}
+
+	result.Response = response
+	result.Status = "passed"
+
+	assertionsStarted := time.Now()
+	if len(step.Assertions) > 0 {
+		assertionResults, err := e.newAssertionEngine(step, varContext).RunAssertions(step.Assertions, response)
+		if err != nil {
+			timing.Assertions += time.Since(assertionsStarted)
+			result.Status = "failed"
+			result.Error = fmt.Sprintf("Assertion error: %v", err)
+			return
+		}
+		result.Assertions = append(result.Assertions, assertionResults...)
+	}
+	if len(step.Check) > 0 {
+		result.Assertions = append(result.Assertions, e.processChecks(step, step.Check, response, varContext)...)
+	}
+	timing.Assertions += time.Since(assertionsStarted)
+	for _, assertionResult := range result.Assertions {
+		if !assertionResult.Passed {
+			result.Status = "failed"
+			break
+		}
+	}
+
+	timed(&timing.Captures, func() {
+		e.processCaptures(step.Capture, response, varContext)
+		e.extractVariables(step, response, varContext)
+	})
+}
+
 func (e *Engine) evaluateCondition(condition string, varContext *variables.Context) (bool, error) {
 	interpolated, err := varContext.InterpolateString(condition)
 	if err != nil {
@@ -500,7 +1272,121 @@ func (e *Engine) evaluateCondition(condition string, varContext *variables.Conte
 	return false, fmt.Errorf("unsupported condition value: '%s'", interpolated)
 }
 
-func (e *Engine) executeHTTPStep(step *scenario.Step, varContext *variables.Context) (interface{}, error) {
+// executeHTTPStep sends the step's request; fault, when not nil, is injected into it
+func (e *Engine) executeHTTPStep(step *scenario.Step, varContext *variables.Context, timing *reporting.StepTiming, fault *scenario.Fault) (*scenario.Step, interface{}, error) {
+	var interpolatedStep *scenario.Step
+	var err error
+	timed(&timing.Interpolation, func() { interpolatedStep, err = e.interpolateHTTPStep(step, varContext) })
+	if err != nil {
+		return nil, nil, err
+	}
+	e.injectCorrelationID(interpolatedStep, varContext)
+
+	// Execute HTTP request
+	var response *protocols.HTTPResponse
+	timed(&timing.Request, func() {
+		if fault != nil && fault.Delay > 0 {
+			time.Sleep(fault.Delay)
+		}
+		response, err = e.httpClient.Execute(interpolatedStep)
+	})
+	if err != nil {
+		return interpolatedStep, nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	if fault != nil && fault.Drop {
+		return interpolatedStep, nil, &protocols.TransportError{
+			Kind: protocols.ErrorDropped,
+			Err:  fmt.Errorf("HTTP request failed: connection dropped (injected fault)"),
+		}
+	}
+	if e.options.OnResponse != nil {
+		e.options.OnResponse(step, interpolatedStep, response.StatusCode)
+	}
+	if fault != nil {
+		// An injected delay counts as network time for response time assertions
+		response.Duration += fault.Delay
+	}
+	if interpolatedStep.Request.CORS != nil {
+		if err := verifyCORS(interpolatedStep.Request.CORS, response); err != nil {
+			return interpolatedStep, nil, err
+		}
+	}
+	if interpolatedStep.Request.Revalidate {
+		timed(&timing.Request, func() { err = e.revalidate(interpolatedStep, response) })
+		if err != nil {
+			return interpolatedStep, nil, err
+		}
+	}
+	if interpolatedStep.Request.Stability != nil {
+		timed(&timing.Request, func() { err = e.checkStability(interpolatedStep, response) })
+		if err != nil {
+			return interpolatedStep, nil, err
+		}
+	}
+
+	// Convert response to map for easy access
+	responseMap := map[string]interface{}{
+		"status_code": response.StatusCode,
+		"headers":     response.Headers,
+		"body":        response.Body,
+		"body_text":   response.BodyText,
+		"charset":     response.Charset,
+		"duration":    response.Duration,
+		"size":        response.Size,
+
+		"content_encoding": response.ContentEncoding,
+		"encoded_size":     response.EncodedSize,
+	}
+	if response.Certificate != nil {
+		responseMap["certificate"] = certificateMap(response.Certificate)
+	}
+	if response.Stream != nil {
+		responseMap["stream"] = streamMap(response.Stream)
+	}
+	if interpolatedStep.Request.Protobuf != nil {
+		if err := e.decodeProtobuf(interpolatedStep.Request.Protobuf, responseMap); err != nil {
+			return interpolatedStep, nil, err
+		}
+	}
+	if fault != nil {
+		applyFault(fault, responseMap)
+	}
+
+	return interpolatedStep, responseMap, nil
+}
+
+// enterScenario records the scenario whose steps are about to run
+func (e *Engine) enterScenario(sc *scenario.Scenario) {
+	e.currentScenario = sc.Name
+	e.scenarioCorrelationID = ""
+	if e.config.Global.CorrelationID.Enabled && e.config.Global.CorrelationID.Scope == "scenario" {
+		e.scenarioCorrelationID = variables.NewUUID()
+	}
+	e.httpDefaults = nil
+	if sc.Config != nil {
+		e.httpDefaults = sc.Config.HTTP
+	}
+	e.cleanups = nil
+	e.responses = nil
+	e.thinkTime = e.scenarioThinkTime(sc)
+	e.pacing = e.scenarioPacing(sc)
+	atomic.StoreInt32(&e.stepsStarted, 0)
+}
+
+func (e *Engine) interpolateHTTPStep(step *scenario.Step, varContext *variables.Context) (*scenario.Step, error) {
+	step, err := e.applyService(step)
+	if err != nil {
+		return nil, err
+	}
+
+	// Apply scenario-level header and query defaults
+	if e.httpDefaults != nil {
+		withDefaults := *step
+		withDefaults.Request.Headers = scenario.MergeHeaders(e.httpDefaults.Headers, step.Request.Headers)
+		withDefaults.Request.Query = scenario.MergeQuery(e.httpDefaults.Query, step.Request.Query)
+		step = &withDefaults
+	}
+
 	// Interpolate request values
 	interpolatedStep := *step
 
@@ -513,6 +1399,20 @@ func (e *Engine) executeHTTPStep(step *scenario.Step, varContext *variables.Cont
 		interpolatedStep.Request.URL = url
 	}
 
+	// Substitute path parameters, escaped
+	if len(step.Request.PathParams) > 0 {
+		params, err := varContext.InterpolateMap(step.Request.PathParams)
+		if err != nil {
+			return nil, fmt.Errorf("failed to interpolate path parameters: %w", err)
+		}
+		url, err := expandPathParams(interpolatedStep.Request.URL, params)
+		if err != nil {
+			return nil, err
+		}
+		interpolatedStep.Request.URL = url
+		interpolatedStep.Request.PathParams = params
+	}
+
 	// Interpolate headers
 	if len(step.Request.Headers) > 0 {
 		headers, err := varContext.InterpolateMap(step.Request.Headers)
@@ -540,23 +1440,13 @@ func (e *Engine) executeHTTPStep(step *scenario.Step, varContext *variables.Cont
 		interpolatedStep.Request.Body = body
 	}
 
-	// Execute HTTP request
-	response, err := e.httpClient.Execute(&interpolatedStep)
-	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
-	}
-
-	// Convert response to map for easy access
-	responseMap := map[string]interface{}{
-		"status_code": response.StatusCode,
-		"headers":     response.Headers,
-		"body":        response.Body,
-		"body_text":   response.BodyText,
-		"duration":    response.Duration,
-		"size":        response.Size,
+	if step.Request.CORS != nil {
+		if err := corsPreflight(&interpolatedStep, varContext); err != nil {
+			return nil, err
+		}
 	}
 
-	return responseMap, nil
+	return &interpolatedStep, nil
 }
 
 func (e *Engine) extractVariables(step *scenario.Step, response interface{}, varContext *variables.Context) {
@@ -581,37 +1471,61 @@ func (e *Engine) extractVariables(step *scenario.Step, response interface{}, var
 		if !ok {
 			// If the value is not a string, treat it as a literal value to be set.
 			varContext.SetStep(varName, extractor)
+			varContext.SetNamespaced(variables.NamespaceStep, varName, extractor)
 			continue
 		}
 
 		value, err := variables.ExtractFromResponse(responseMap, extractorStr)
 		if err == nil {
 			varContext.SetStep(varName, value)
+			varContext.SetNamespaced(variables.NamespaceStep, varName, value)
 		}
 	}
 }
 
-func (e *Engine) executeHTTPStepNew(step *scenario.Step, varContext *variables.Context) (interface{}, error) {
-	// Convert new format to legacy format for HTTP client compatibility
+func (e *Engine) executeHTTPStepNew(step *scenario.Step, varContext *variables.Context, timing *reporting.StepTiming, fault *scenario.Fault) (*scenario.Step, interface{}, error) {
+	return e.executeHTTPStep(toLegacyHTTPStep(step), varContext, timing, fault)
+}
+
+// timed adds the time fn takes to *bucket
+func timed(bucket *time.Duration, fn func()) {
+	started := time.Now()
+	fn()
+	*bucket += time.Since(started)
+}
+
+// toLegacyHTTPStep converts the new HTTP step format to the legacy format for HTTP client compatibility
+func toLegacyHTTPStep(step *scenario.Step) *scenario.Step {
 	legacyStep := &scenario.Step{
 		Name: step.Name,
 		Type: "http",
 		Request: scenario.Request{
-			Method:  step.HTTP.Method,
-			URL:     step.HTTP.URL,
-			Headers: step.HTTP.Headers,
-			Query:   step.HTTP.Query,
-			Body:    step.HTTP.Body,
+			Method:     step.HTTP.Method,
+			URL:        step.HTTP.URL,
+			Service:    step.HTTP.Service,
+			Headers:    step.HTTP.Headers,
+			Query:      step.HTTP.Query,
+			PathParams: step.HTTP.PathParams,
+			Middleware: step.HTTP.Middleware,
+			Protobuf:   step.HTTP.Protobuf,
+			Revalidate: step.HTTP.Revalidate,
+			CORS:       step.HTTP.CORS,
+			Stability:  step.HTTP.Stability,
+			Stream:     step.HTTP.Stream,
+			Body:       step.HTTP.Body,
 		},
+		Timeout: step.Timeout,
 	}
 
 	// Handle JSON body
 	if step.HTTP.JSON != nil {
 		legacyStep.Request.Body = step.HTTP.JSON
-		if legacyStep.Request.Headers == nil {
-			legacyStep.Request.Headers = make(map[string]string)
+		headers := make(map[string]string, len(step.HTTP.Headers)+1)
+		for k, v := range step.HTTP.Headers {
+			headers[k] = v
 		}
-		legacyStep.Request.Headers["Content-Type"] = "application/json"
+		headers["Content-Type"] = "application/json"
+		legacyStep.Request.Headers = headers
 	}
 
 	// Handle authentication
@@ -619,7 +1533,7 @@ func (e *Engine) executeHTTPStepNew(step *scenario.Step, varContext *variables.C
 		legacyStep.Request.Auth = step.HTTP.Auth
 	}
 
-	return e.executeHTTPStep(legacyStep, varContext)
+	return legacyStep
 }
 
 func (e *Engine) processCaptures(captures map[string]scenario.Capture, response interface{}, varContext *variables.Context) {
@@ -638,6 +1552,10 @@ func (e *Engine) processCaptures(captures map[string]scenario.Capture, response
 			value, err = variables.ExtractFromResponse(responseMap, "json:"+capture.JSONPath)
 		case capture.Header != "":
 			value, err = variables.ExtractFromResponse(responseMap, "header:"+capture.Header)
+		case capture.Cookie != "":
+			value, err = variables.ExtractFromResponse(responseMap, "cookie:"+capture.Cookie)
+		case capture.Links:
+			value, err = variables.ExtractFromResponse(responseMap, "links")
 		case capture.Regex != "":
 			bodyText, ok := responseMap["body_text"].(string)
 			if !ok {
@@ -663,13 +1581,18 @@ func (e *Engine) processCaptures(captures map[string]scenario.Capture, response
 
 		if err == nil {
 			varContext.SetLocal(name, value)
+			varContext.SetNamespaced(variables.NamespaceCaptures, name, value)
 		}
 	}
 }
 
-func (e *Engine) processChecks(checks map[string]interface{}, response interface{}, varContext *variables.Context) []assertions.Result {
+func (e *Engine) newAssertionEngine(step *scenario.Step, varContext *variables.Context) *assertions.Engine {
+	return assertions.NewEngine(varContext).WithSnapshots(e.snapshots, e.currentScenario, step.Name).WithResponses(e.storedResponse)
+}
+
+func (e *Engine) processChecks(step *scenario.Step, checks map[string]interface{}, response interface{}, varContext *variables.Context) []assertions.Result {
 	var results []assertions.Result
-	assertionEngine := assertions.NewEngine(varContext)
+	assertionEngine := e.newAssertionEngine(step, varContext)
 
 	// Convert checks to assertions format
 	var assertionList []scenario.Assertion
@@ -685,6 +1608,10 @@ func (e *Engine) processChecks(checks map[string]interface{}, response interface
 		case "status":
 			assertion.Type = "status_code"
 		}
+		if transportCheck, exists := transportCheckTypes[checkType]; exists {
+			assertion = transportCheck
+			assertion.Value = expectedValue
+		}
 
 		assertionList = append(assertionList, assertion)
 	}
@@ -703,3 +1630,15 @@ func (e *Engine) processChecks(checks map[string]interface{}, response interface
 
 	return results
 }
+
+// recordClientStats adds the traffic of the HTTP client to the report
+func (e *Engine) recordClientStats() {
+	metrics := e.httpClient.Metrics()
+	e.reporter.SetClientStats(reporting.ClientStats{
+		Requests:          metrics.Requests,
+		BytesSent:         metrics.BytesSent,
+		BytesReceived:     metrics.BytesReceived,
+		NewConnections:    metrics.NewConnections,
+		ReusedConnections: metrics.ReusedConnections,
+	})
+}
@@ -0,0 +1,183 @@
+package execution
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/nulln0ne/fuego/pkg/variables"
+)
+
+// CurlCommand is a single HTTP step rendered as a curl invocation
+type CurlCommand struct {
+	Group   string `json:"group,omitempty"`
+	Step    string `json:"step"`
+	Command string `json:"command"`
+}
+
+// resolvedStep is an HTTP step in legacy format with its templates interpolated
+type resolvedStep struct {
+	Group string
+	Name  string
+	Step  *scenario.Step
+}
+
+// ExportCurl renders every HTTP step of a scenario as a curl command without executing it.
+// Templates are interpolated with scenario, environment and config variables; values that are
+// only known at runtime (captures) are left as-is. Data-driven steps are bound to their first row.
+func (e *Engine) ExportCurl(sc *scenario.Scenario) ([]CurlCommand, error) {
+	steps, err := e.resolveHTTPSteps(sc)
+	if err != nil {
+		return nil, err
+	}
+
+	commands := make([]CurlCommand, 0, len(steps))
+	for _, resolved := range steps {
+		command, err := e.httpClient.Curl(resolved.Step)
+		if err != nil {
+			return nil, fmt.Errorf("step '%s': %w", resolved.Name, err)
+		}
+
+		commands = append(commands, CurlCommand{
+			Group:   resolved.Group,
+			Step:    resolved.Name,
+			Command: command,
+		})
+	}
+
+	return commands, nil
+}
+
+// resolveHTTPSteps walks a scenario in execution order (test groups sorted by name) and
+// interpolates every HTTP step without sending any requests
+func (e *Engine) resolveHTTPSteps(sc *scenario.Scenario) ([]resolvedStep, error) {
+	// Matrix scenarios and groups are resolved with their first combination
+	sc = scenario.ExpandMatrix(sc)[0]
+	e.enterScenario(sc)
+	scenarioContext := e.newScenarioContext(sc)
+	if err := e.loadScenarioData(sc, scenarioContext); err != nil {
+		return nil, err
+	}
+
+	var resolved []resolvedStep
+	exportSteps := func(group string, steps []scenario.Step, dataDriven *scenario.DataDrivenConfig, varContext *variables.Context) error {
+		if dataDriven != nil {
+			varContext = e.bindFirstDataItem(dataDriven, varContext)
+		}
+
+		for _, step := range steps {
+			stepContext := varContext
+			if step.DataDriven != nil {
+				stepContext = e.bindFirstDataItem(step.DataDriven, varContext)
+			}
+
+			// Variable-only steps feed later templates
+			if step.Type == "" && step.HTTP == nil {
+				for k, v := range step.Variables {
+					varContext.SetStep(k, v)
+					varContext.SetNamespaced(variables.NamespaceStep, k, v)
+				}
+				continue
+			}
+
+			httpStep := &step
+			if step.HTTP != nil {
+				httpStep = toLegacyHTTPStep(&step)
+			} else if step.Type != "http" {
+				continue
+			}
+
+			interpolated, err := e.interpolateHTTPStep(httpStep, stepContext)
+			if err != nil {
+				return fmt.Errorf("step '%s': %w", step.Name, err)
+			}
+
+			resolved = append(resolved, resolvedStep{
+				Group: group,
+				Name:  step.Name,
+				Step:  interpolated,
+			})
+		}
+
+		return nil
+	}
+
+	if sc.Before != nil {
+		if err := exportSteps("before", sc.Before.Steps, nil, scenarioContext); err != nil {
+			return nil, err
+		}
+	}
+	if err := exportSteps("setup", sc.Setup, nil, scenarioContext); err != nil {
+		return nil, err
+	}
+	if err := exportSteps("", sc.Steps, nil, scenarioContext); err != nil {
+		return nil, err
+	}
+
+	testNames := make([]string, 0, len(sc.Tests))
+	for name := range sc.Tests {
+		testNames = append(testNames, name)
+	}
+	sort.Strings(testNames)
+	for _, name := range testNames {
+		test := sc.Tests[name]
+		if test.Skip {
+			continue
+		}
+		testContext := scenarioContext.Clone()
+		for k, v := range test.Env {
+			testContext.DeclareLocal(k, v)
+			testContext.DeclareNamespaced(variables.NamespaceTest, k, v)
+		}
+		if combinations := test.Matrix.Expand(); len(combinations) > 0 {
+			for k, v := range combinations[0].Values {
+				testContext.SetLocal(k, v)
+				testContext.SetNamespaced(variables.NamespaceTest, k, v)
+			}
+		}
+		if err := exportSteps(name, test.Steps, test.DataDriven, testContext); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := exportSteps("teardown", sc.Teardown, nil, scenarioContext); err != nil {
+		return nil, err
+	}
+	if sc.After != nil {
+		if err := exportSteps("after", sc.After.Steps, nil, scenarioContext); err != nil {
+			return nil, err
+		}
+	}
+
+	return resolved, nil
+}
+
+func (e *Engine) bindFirstDataItem(dataDriven *scenario.DataDrivenConfig, varContext *variables.Context) *variables.Context {
+	bound := varContext.Clone()
+	if dataSource, exists := varContext.Get(dataDriven.Source); exists {
+		if dataItems, ok := dataSource.([]map[string]interface{}); ok {
+			if selected, err := selectDataItems(dataDriven, dataItems, varContext, e.seed); err == nil {
+				dataItems = selected
+			}
+			if len(dataItems) > 0 {
+				bound.SetStep(dataDriven.Variable, dataItems[0])
+				bound.SetNamespaced(variables.NamespaceData, dataDriven.Variable, dataItems[0])
+			}
+		}
+	}
+	return bound
+}
+
+func (e *Engine) curlCommand(step *scenario.Step) string {
+	if step == nil {
+		return ""
+	}
+
+	// Reports travel to webhooks, integrations and object stores, so credentials are masked
+	command, err := e.httpClient.RedactedCurl(step)
+	if err != nil {
+		return ""
+	}
+
+	return command
+}
@@ -0,0 +1,43 @@
+package execution
+
+import (
+	"fmt"
+
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/nulln0ne/fuego/pkg/variables"
+)
+
+// writeOutput appends a row of the step's output fields to its output file when the step passed.
+// Unlike save_response, relative paths are kept relative to the working directory: the file is a
+// dataset meant to outlive the run. Fields can use step.duration_ms and step.status_code besides
+// the variables of the step.
+func (e *Engine) writeOutput(step *scenario.Step, result *reporting.StepResult, varContext *variables.Context) {
+	if step.Output == nil || result.Status != "passed" {
+		return
+	}
+
+	varContext.SetNamespaced(variables.NamespaceStep, "duration_ms", result.Duration.Milliseconds())
+	if response, ok := result.Response.(map[string]interface{}); ok {
+		varContext.SetNamespaced(variables.NamespaceStep, "status_code", response["status_code"])
+	}
+
+	path, err := varContext.InterpolateString(step.Output.Path)
+	if err != nil {
+		e.failArtifact(result, fmt.Errorf("failed to write output: %w", err))
+		return
+	}
+	row := make(map[string]interface{}, len(step.Output.Fields))
+	for field, template := range step.Output.Fields {
+		value, err := varContext.Value(template)
+		if err != nil {
+			e.failArtifact(result, fmt.Errorf("failed to write output field %s: %w", field, err))
+			return
+		}
+		row[field] = value
+	}
+
+	if err := e.outputs.Append(path, row); err != nil {
+		e.failArtifact(result, fmt.Errorf("failed to write output to %s: %w", path, err))
+	}
+}
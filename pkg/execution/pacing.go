@@ -0,0 +1,82 @@
+package execution
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+)
+
+// pacer spaces the starts of consecutive iterations by at least the pacing of the scenario
+type pacer struct {
+	engine *Engine
+	pacing scenario.Pause
+	last   time.Time
+}
+
+func (e *Engine) newPacer(pacing scenario.Pause) *pacer {
+	return &pacer{engine: e, pacing: pacing}
+}
+
+// wait blocks until the next iteration may start
+func (p *pacer) wait() {
+	if !p.last.IsZero() {
+		p.engine.pause(p.engine.pauseDuration(p.pacing) - time.Since(p.last))
+	}
+	p.last = time.Now()
+}
+
+// scenarioPacing is the pacing of a scenario, falling back to global.pacing
+func (e *Engine) scenarioPacing(sc *scenario.Scenario) scenario.Pause {
+	if sc.Config != nil && sc.Config.Pacing != "" {
+		return sc.Config.Pacing
+	}
+	return scenario.Pause(e.config.Global.Pacing)
+}
+
+// scenarioThinkTime is the think time of a scenario, falling back to global.think_time
+func (e *Engine) scenarioThinkTime(sc *scenario.Scenario) scenario.Pause {
+	if sc.Config != nil && sc.Config.ThinkTime != "" {
+		return sc.Config.ThinkTime
+	}
+	return scenario.Pause(e.config.Global.ThinkTime)
+}
+
+// think waits the think time before a step, unless it is the first step of the scenario
+func (e *Engine) think(step *scenario.Step) {
+	if atomic.AddInt32(&e.stepsStarted, 1) == 1 {
+		return
+	}
+	thinkTime := e.thinkTime
+	if step.ThinkTime != "" {
+		thinkTime = step.ThinkTime
+	}
+	e.pause(e.pauseDuration(thinkTime))
+}
+
+// pauseDuration picks the wait of a pause from a source seeded by the run seed
+func (e *Engine) pauseDuration(pause scenario.Pause) time.Duration {
+	if pause == "" {
+		return 0
+	}
+	e.pauseMu.Lock()
+	defer e.pauseMu.Unlock()
+	if e.pauseRand == nil {
+		e.pauseRand = rand.New(rand.NewSource(e.derivedSeed("pauses")))
+	}
+	return pause.Duration(e.pauseRand)
+}
+
+// pause sleeps for d, returning early when the run is cancelled
+func (e *Engine) pause(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-e.ctx.Done():
+	}
+}
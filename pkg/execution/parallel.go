@@ -0,0 +1,268 @@
+package execution
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/assertions"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/nulln0ne/fuego/pkg/variables"
+)
+
+// parallelCopy is one of the requests of a parallel step
+type parallelCopy struct {
+	varContext *variables.Context
+	fault      *scenario.Fault
+	timing     reporting.StepTiming
+	sent       *scenario.Step
+	response   map[string]interface{}
+	err        error
+}
+
+// runParallelRequests sends the copies of a parallel step at the same moment, each with its own
+// variables and data binding, and checks every response on its own and all of them together.
+// Captures, parallel assertions and the report see the first successful response, with the stats
+// of all copies under "parallel".
+func (e *Engine) runParallelRequests(step *scenario.Step, varContext *variables.Context, timing *reporting.StepTiming, fault *scenario.Fault, result *reporting.StepResult) {
+	send := e.executeHTTPStep
+	if step.HTTP != nil {
+		send = e.executeHTTPStepNew
+	}
+
+	// Faults are picked up front as their random source is not safe for concurrent use
+	copies := make([]*parallelCopy, step.Parallel.Copies())
+	result.Faults = nil
+	for i := range copies {
+		c := &parallelCopy{varContext: varContext.Clone(), fault: fault}
+		if i > 0 {
+			c.fault = e.pickFault(step)
+		}
+		if c.fault != nil {
+			result.Faults = append(result.Faults, fmt.Sprintf("copy %d: %s", i+1, c.fault))
+		}
+		// Clones leave out step variables, which the copies share with the step they belong to
+		for k, v := range varContext.StepVariables() {
+			c.varContext.SetStep(k, v)
+		}
+		for k, v := range varContext.Namespaced(variables.NamespaceStep) {
+			c.varContext.SetNamespaced(variables.NamespaceStep, k, v)
+		}
+		c.varContext.SetStep("parallel_index", i)
+		if len(step.Parallel.Data) > 0 {
+			for k, v := range step.Parallel.Data[i%len(step.Parallel.Data)] {
+				c.varContext.SetStep(k, v)
+				c.varContext.SetNamespaced(variables.NamespaceStep, k, v)
+			}
+		}
+		copies[i] = c
+	}
+
+	// Every copy waits for the start signal, so the requests leave as close together as possible
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, c := range copies {
+		wg.Add(1)
+		go func(c *parallelCopy) {
+			defer wg.Done()
+			<-start
+			var response interface{}
+			c.sent, response, c.err = send(step, c.varContext, &c.timing, c.fault)
+			if c.err != nil && expectsTransportError(step) {
+				c.response, c.err = transportErrorResponse(c.err, c.timing.Request)
+				return
+			}
+			c.response, _ = response.(map[string]interface{})
+		}(c)
+	}
+	started := time.Now()
+	close(start)
+	wg.Wait()
+	timing.Request += time.Since(started)
+	stats := parallelStats(copies, time.Since(started))
+
+	result.CorrelationID = e.sentCorrelationID(copies[0].sent)
+	result.Status = "passed"
+	var failures []string
+	var first *parallelCopy
+
+	perCopy, together := splitParallelAssertions(step.Assertions)
+	checks := mergedChecks(step)
+	assertionsStarted := time.Now()
+	for i, c := range copies {
+		if c.err != nil {
+			failures = append(failures, fmt.Sprintf("copy %d: %v", i+1, c.err))
+			continue
+		}
+		if first == nil || !succeeded(first.response) && succeeded(c.response) {
+			first = c
+		}
+
+		var copyResults []assertions.Result
+		if len(checks) > 0 {
+			copyResults = e.processChecks(step, checks, c.response, c.varContext)
+		}
+		if len(perCopy) > 0 {
+			assertionResults, err := e.newAssertionEngine(step, c.varContext).RunAssertions(perCopy, c.response)
+			if err != nil {
+				failures = append(failures, fmt.Sprintf("copy %d: assertion error: %v", i+1, err))
+			}
+			copyResults = append(copyResults, assertionResults...)
+		}
+		for _, copyResult := range copyResults {
+			copyResult.Message = fmt.Sprintf("copy %d: %s", i+1, copyResult.Message)
+			result.Assertions = append(result.Assertions, copyResult)
+		}
+	}
+
+	for _, path := range step.Parallel.Unique {
+		if duplicate := duplicateValues(copies, path); duplicate != "" {
+			failures = append(failures, duplicate)
+		}
+	}
+
+	response := map[string]interface{}{}
+	if first != nil {
+		for k, v := range first.response {
+			response[k] = v
+		}
+	}
+	response["parallel"] = stats
+	if len(together) > 0 {
+		assertionResults, err := e.newAssertionEngine(step, varContext).RunAssertions(together, response)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("assertion error: %v", err))
+		}
+		result.Assertions = append(result.Assertions, assertionResults...)
+	}
+	timing.Assertions += time.Since(assertionsStarted)
+	result.Response = response
+
+	timed(&timing.Captures, func() { e.processCaptures(step.Capture, response, varContext) })
+
+	for _, assertionResult := range result.Assertions {
+		if !assertionResult.Passed {
+			result.Status = "failed"
+			break
+		}
+	}
+	if len(failures) > 0 {
+		result.Status = "failed"
+		result.Error = strings.Join(failures, "; ")
+	}
+	if result.Status == "failed" {
+		sent := copies[0].sent
+		if first != nil {
+			sent = first.sent
+		}
+		result.Curl = e.curlCommand(sent)
+	}
+}
+
+func succeeded(response map[string]interface{}) bool {
+	status, _ := response["status_code"].(int)
+	return status >= 200 && status < 300
+}
+
+// parallelStats sums up the outcome of the copies for assertions of type parallel
+func parallelStats(copies []*parallelCopy, duration time.Duration) map[string]interface{} {
+	statuses := make([]interface{}, len(copies))
+	statusCounts := make(map[string]interface{})
+	successes, transportErrors := 0, 0
+	for i, c := range copies {
+		status, ok := c.response["status_code"].(int)
+		if c.err != nil || !ok {
+			transportErrors++
+			statuses[i] = 0
+			continue
+		}
+		statuses[i] = status
+		key := strconv.Itoa(status)
+		count, _ := statusCounts[key].(int)
+		statusCounts[key] = count + 1
+		if succeeded(c.response) {
+			successes++
+		}
+	}
+
+	return map[string]interface{}{
+		"count":         len(copies),
+		"succeeded":     successes,
+		"errors":        transportErrors,
+		"statuses":      statuses,
+		"status_counts": statusCounts,
+		"duration":      duration.Milliseconds(),
+	}
+}
+
+// duplicateValues describes the values of path that more than one response returned, or is
+// empty when they all differ. Responses without the path are left out.
+func duplicateValues(copies []*parallelCopy, path string) string {
+	seen := make(map[string][]int)
+	var order []string
+	for i, c := range copies {
+		if c.response == nil {
+			continue
+		}
+		value, err := variables.ExtractFromResponse(map[string]interface{}{"body_text": c.response["body_text"]}, "json:"+path)
+		if err != nil {
+			continue
+		}
+		key := fmt.Sprintf("%v", value)
+		if _, exists := seen[key]; !exists {
+			order = append(order, key)
+		}
+		seen[key] = append(seen[key], i+1)
+	}
+
+	var duplicates []string
+	for _, key := range order {
+		if copyNumbers := seen[key]; len(copyNumbers) > 1 {
+			sort.Ints(copyNumbers)
+			duplicates = append(duplicates, fmt.Sprintf("%s (copies %s)", key, joinInts(copyNumbers)))
+		}
+	}
+	if len(duplicates) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("parallel: duplicate %s: %s", path, strings.Join(duplicates, ", "))
+}
+
+func joinInts(numbers []int) string {
+	parts := make([]string, len(numbers))
+	for i, n := range numbers {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// splitParallelAssertions separates the assertions on all copies together from the ones every
+// response is checked against
+func splitParallelAssertions(all []scenario.Assertion) (perCopy, together []scenario.Assertion) {
+	for _, assertion := range all {
+		if assertion.Type == "parallel" {
+			together = append(together, assertion)
+		} else {
+			perCopy = append(perCopy, assertion)
+		}
+	}
+	return perCopy, together
+}
+
+// mergedChecks combines the step's check map with the one of its http block, which wins
+func mergedChecks(step *scenario.Step) map[string]interface{} {
+	checks := make(map[string]interface{}, len(step.Check))
+	for k, v := range step.Check {
+		checks[k] = v
+	}
+	if step.HTTP != nil {
+		for k, v := range step.HTTP.Check {
+			checks[k] = v
+		}
+	}
+	return checks
+}
@@ -0,0 +1,57 @@
+package execution
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// pathParamPattern matches the :name and {name} placeholders of a URL path segment
+var pathParamPattern = regexp.MustCompile(`/:([A-Za-z_][A-Za-z0-9_]*)|\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// expandPathParams substitutes path parameters into the placeholders of rawURL, escaping each
+// value so IDs containing /, ?, # or spaces stay within their segment. A placeholder without a
+// parameter is left as written; a parameter without a placeholder is an error.
+func expandPathParams(rawURL string, params map[string]string) (string, error) {
+	if len(params) == 0 {
+		return rawURL, nil
+	}
+
+	// Only the path is expanded, so a port or a query value is never mistaken for a placeholder
+	prefix, path := "", rawURL
+	if scheme := strings.Index(rawURL, "://"); scheme >= 0 {
+		host := rawURL[scheme+3:]
+		if slash := strings.Index(host, "/"); slash >= 0 {
+			prefix, path = rawURL[:scheme+3+slash], host[slash:]
+		} else {
+			prefix, path = rawURL, ""
+		}
+	}
+	suffix := ""
+	if end := strings.IndexAny(path, "?#"); end >= 0 {
+		path, suffix = path[:end], path[end:]
+	}
+
+	used := make(map[string]bool, len(params))
+	path = pathParamPattern.ReplaceAllStringFunc(path, func(placeholder string) string {
+		match := pathParamPattern.FindStringSubmatch(placeholder)
+		name, lead := match[2], ""
+		if match[1] != "" {
+			name, lead = match[1], "/"
+		}
+		value, exists := params[name]
+		if !exists {
+			return placeholder
+		}
+		used[name] = true
+		return lead + url.PathEscape(value)
+	})
+
+	for name := range params {
+		if !used[name] {
+			return "", fmt.Errorf("path parameter %s has no :%s or {%s} placeholder in %s", name, name, name, rawURL)
+		}
+	}
+	return prefix + path + suffix, nil
+}
@@ -0,0 +1,81 @@
+package execution
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+)
+
+// ErrEnvironmentUnavailable is returned when a preflight check failed and no scenario was run
+var ErrEnvironmentUnavailable = errors.New("environment unavailable")
+
+// runPreflight sends the preflight checks of the config concurrently, with the run's base URL
+// and headers, and reports their results in config order
+func (e *Engine) runPreflight() []reporting.PreflightResult {
+	checks := e.config.Preflight
+	results := make([]reporting.PreflightResult, len(checks))
+
+	var wg sync.WaitGroup
+	for i := range checks {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = e.preflightCheck(checks[i])
+		}(i)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func (e *Engine) preflightCheck(check config.PreflightCheck) reporting.PreflightResult {
+	name := check.Name
+	if name == "" {
+		name = check.URL
+	}
+	method := check.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	result := reporting.PreflightResult{Name: name, URL: check.URL}
+
+	step := &scenario.Step{
+		Name:    name,
+		Type:    "http",
+		Request: scenario.Request{Method: method, URL: check.URL, Headers: check.Headers},
+	}
+	started := time.Now()
+	response, err := e.httpClient.Execute(step)
+	result.Duration = time.Since(started)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.StatusCode = response.StatusCode
+	switch {
+	case check.Status != 0 && response.StatusCode != check.Status:
+		result.Error = fmt.Sprintf("expected status %d, got %d", check.Status, response.StatusCode)
+	case check.Status == 0 && response.StatusCode >= 400:
+		result.Error = fmt.Sprintf("unhealthy status %d", response.StatusCode)
+	default:
+		result.Passed = true
+	}
+	return result
+}
+
+// preflightError summarizes the failed checks
+func preflightError(results []reporting.PreflightResult) error {
+	for _, result := range results {
+		if !result.Passed {
+			return fmt.Errorf("%w: preflight check %s failed: %s", ErrEnvironmentUnavailable, result.Name, result.Error)
+		}
+	}
+	return nil
+}
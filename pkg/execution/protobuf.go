@@ -0,0 +1,56 @@
+package execution
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/protobuf"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+)
+
+// decodeProtobuf replaces the body text of a response with the JSON of its decoded protobuf
+// message; the raw bytes stay in body
+func (e *Engine) decodeProtobuf(config *scenario.ProtobufResponse, response map[string]interface{}) error {
+	schema, err := e.protoSchema(config.File)
+	if err != nil {
+		return err
+	}
+	message, err := schema.Message(config.Message)
+	if err != nil {
+		return fmt.Errorf("%s: %w", config.File, err)
+	}
+
+	body, _ := response["body"].([]byte)
+	if headers, ok := response["headers"].(map[string][]string); ok {
+		if strings.HasPrefix(http.Header(headers).Get("Content-Type"), "application/grpc") {
+			if body, err = protobuf.UnframeGRPC(body); err != nil {
+				return fmt.Errorf("response is not a gRPC message: %w", err)
+			}
+		}
+	}
+
+	decoded, err := schema.Decode(message, body)
+	if err != nil {
+		return fmt.Errorf("response does not decode as %s: %w", message.Name, err)
+	}
+	text, err := json.Marshal(decoded)
+	if err != nil {
+		return fmt.Errorf("failed to encode decoded %s: %w", message.Name, err)
+	}
+	response["body_text"] = string(text)
+	return nil
+}
+
+func (e *Engine) protoSchema(path string) (*protobuf.Schema, error) {
+	if schema, ok := e.protoSchemas.Load(path); ok {
+		return schema.(*protobuf.Schema), nil
+	}
+	schema, err := protobuf.Load(path)
+	if err != nil {
+		return nil, err
+	}
+	e.protoSchemas.Store(path, schema)
+	return schema, nil
+}
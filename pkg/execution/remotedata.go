@@ -0,0 +1,74 @@
+package execution
+
+import (
+	"encoding/base64"
+	"os"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/data"
+	"github.com/nulln0ne/fuego/pkg/objectstore"
+)
+
+// remoteData turns the data config into the credentials of remote data sources
+func remoteData(cfg config.DataConfig) data.Remote {
+	remote := data.Remote{CacheDir: cfg.CacheDir}
+	for _, source := range cfg.Remote {
+		headers := make(map[string]string, len(source.Headers)+1)
+		for name, value := range source.Headers {
+			headers[name] = os.ExpandEnv(value)
+		}
+		if auth := source.Auth; auth != nil {
+			switch auth.Type {
+			case "basic":
+				credentials := os.ExpandEnv(auth.Username) + ":" + os.ExpandEnv(auth.Password)
+				headers["Authorization"] = "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))
+			case "bearer":
+				headers["Authorization"] = "Bearer " + os.ExpandEnv(auth.Token)
+			case "api_key":
+				header := auth.Header
+				if header == "" {
+					header = "Authorization"
+				}
+				headers[header] = os.ExpandEnv(auth.Token)
+			}
+		}
+
+		remote.Sources = append(remote.Sources, data.RemoteSource{Prefix: source.Prefix, Headers: headers, S3: s3Credentials(source.S3)})
+	}
+	return remote
+}
+
+// ReportStorage turns the reports config into the credentials of reports uploaded to object
+// stores
+func ReportStorage(cfg config.ReportsConfig) objectstore.Credentials {
+	storage := objectstore.Credentials{S3: s3Credentials(cfg.Storage.S3)}
+	if gcs := cfg.Storage.GCS; gcs != nil {
+		storage.GCS = objectstore.GCSCredentials{
+			AccessToken: os.ExpandEnv(gcs.AccessToken),
+			KeyFile:     os.ExpandEnv(gcs.KeyFile),
+			Endpoint:    gcs.Endpoint,
+		}
+	}
+	if azure := cfg.Storage.Azure; azure != nil {
+		storage.Azure = objectstore.AzureCredentials{
+			Account:  os.ExpandEnv(azure.Account),
+			Key:      os.ExpandEnv(azure.Key),
+			SASToken: os.ExpandEnv(azure.SASToken),
+			Endpoint: azure.Endpoint,
+		}
+	}
+	return storage
+}
+
+func s3Credentials(cfg *config.S3Config) objectstore.S3Credentials {
+	if cfg == nil {
+		return objectstore.S3Credentials{}
+	}
+	return objectstore.S3Credentials{
+		AccessKeyID:     os.ExpandEnv(cfg.AccessKeyID),
+		SecretAccessKey: os.ExpandEnv(cfg.SecretAccessKey),
+		SessionToken:    os.ExpandEnv(cfg.SessionToken),
+		Region:          cfg.Region,
+		Endpoint:        cfg.Endpoint,
+	}
+}
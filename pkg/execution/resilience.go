@@ -0,0 +1,168 @@
+package execution
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/nulln0ne/fuego/pkg/variables"
+)
+
+// retryCheckTypes are checks about the attempts of a step rather than its response; they are
+// evaluated once, after the last attempt
+var retryCheckTypes = map[string]bool{"attempts": true, "retries": true, "faults": true}
+
+// runStepWithRetry runs a step until it passes or its retry budget is spent, then applies the
+// checks on its attempts. The result is the one of the last attempt, timed from the first.
+func (e *Engine) runStepWithRetry(step *scenario.Step, varContext *variables.Context) reporting.StepResult {
+	attemptStep, retryChecks := splitRetryChecks(step)
+
+	started := time.Now()
+	var result reporting.StepResult
+	var faults []string
+	attempts := 0
+	for {
+		attempts++
+		result = e.runStep(attemptStep, varContext)
+		for _, fault := range result.Faults {
+			faults = append(faults, fmt.Sprintf("attempt %d: %s", attempts, fault))
+		}
+
+		if !e.shouldRetry(step, result, attempts, varContext) {
+			break
+		}
+		time.Sleep(e.retryDelay(step.Retry, attempts))
+	}
+
+	stepCopy := *step
+	result.Step = &stepCopy
+	if step.Retry != nil {
+		result.Attempts = attempts
+	}
+	result.Faults = faults
+	result.StartTime = started
+	result.Duration = result.EndTime.Sub(started)
+
+	if len(retryChecks) > 0 && result.Status != "skipped" {
+		stats := map[string]interface{}{"attempts": attempts, "retries": attempts - 1, "faults": len(faults)}
+		checkResults, err := e.newAssertionEngine(step, varContext).RunAssertions(retryChecks, stats)
+		if err != nil {
+			result.Status = "failed"
+			result.Error = fmt.Sprintf("Assertion error: %v", err)
+		}
+		result.Assertions = append(result.Assertions, checkResults...)
+		for _, checkResult := range checkResults {
+			if !checkResult.Passed {
+				result.Status = "failed"
+				break
+			}
+		}
+	}
+
+	return result
+}
+
+func (e *Engine) shouldRetry(step *scenario.Step, result reporting.StepResult, attempts int, varContext *variables.Context) bool {
+	if step.Retry == nil || result.Status != "failed" || attempts > step.Retry.Count || e.ctx.Err() != nil {
+		return false
+	}
+	if step.Retry.Condition != "" {
+		retry, err := e.evaluateCondition(step.Retry.Condition, varContext)
+		return err == nil && retry
+	}
+	return true
+}
+
+// retryDelay is the pause before the next attempt: the step's delay (or defaults.retry_delay),
+// grown linearly or exponentially with the attempts made so far when backoff is set
+func (e *Engine) retryDelay(retry *scenario.RetryConfig, attempts int) time.Duration {
+	delay := retry.Delay
+	if delay == 0 {
+		delay = e.config.Defaults.RetryDelay
+	}
+	switch retry.Backoff {
+	case "linear":
+		return delay * time.Duration(attempts)
+	case "exponential":
+		return delay << (attempts - 1)
+	default:
+		return delay
+	}
+}
+
+// splitRetryChecks separates the checks and assertions on attempts, retries and faults from the
+// ones each attempt's response is checked against
+func splitRetryChecks(step *scenario.Step) (*scenario.Step, []scenario.Assertion) {
+	var retryChecks []scenario.Assertion
+	for name, value := range step.Check {
+		if retryCheckTypes[name] {
+			retryChecks = append(retryChecks, scenario.Assertion{Type: name, Operator: "eq", Value: value})
+		}
+	}
+	for _, assertion := range step.Assertions {
+		if retryCheckTypes[assertion.Type] {
+			retryChecks = append(retryChecks, assertion)
+		}
+	}
+	if len(retryChecks) == 0 {
+		return step, nil
+	}
+
+	attemptStep := *step
+	attemptStep.Check = make(map[string]interface{}, len(step.Check))
+	for name, value := range step.Check {
+		if !retryCheckTypes[name] {
+			attemptStep.Check[name] = value
+		}
+	}
+	attemptStep.Assertions = nil
+	for _, assertion := range step.Assertions {
+		if !retryCheckTypes[assertion.Type] {
+			attemptStep.Assertions = append(attemptStep.Assertions, assertion)
+		}
+	}
+	return &attemptStep, retryChecks
+}
+
+// pickFault decides which of the step's faults, or the scenario's when the step declares none,
+// is injected into its next request. Rolls come from a source seeded by the run seed, so
+// --seed reproduces a sequential run's faults.
+func (e *Engine) pickFault(step *scenario.Step) *scenario.Fault {
+	faults := step.Faults
+	if len(faults) == 0 && e.httpDefaults != nil {
+		faults = e.httpDefaults.Faults
+	}
+	if len(faults) == 0 {
+		return nil
+	}
+
+	e.faultMu.Lock()
+	if e.faultRand == nil {
+		e.faultRand = rand.New(rand.NewSource(e.derivedSeed("faults")))
+	}
+	roll := e.faultRand.Float64() * 100
+	e.faultMu.Unlock()
+
+	for i := range faults {
+		if roll < faults[i].Percent {
+			return &faults[i]
+		}
+		roll -= faults[i].Percent
+	}
+	return nil
+}
+
+// applyFault replaces the status and body of a response hit by an injected fault
+func applyFault(fault *scenario.Fault, response map[string]interface{}) {
+	if fault.Status == 0 {
+		return
+	}
+	response["status_code"] = fault.Status
+	response["body"] = []byte(fault.Body)
+	response["body_text"] = fault.Body
+	response["size"] = int64(len(fault.Body))
+	response["content_encoding"] = ""
+	response["encoded_size"] = int64(len(fault.Body))
+}
@@ -0,0 +1,52 @@
+package execution
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/nulln0ne/fuego/pkg/protocols"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+)
+
+// revalidate repeats a request once per validator of its response (ETag, Last-Modified) as a
+// conditional request and fails unless each one is answered with 304 Not Modified. Validators
+// are sent one at a time so a server that honours only one of them is caught.
+func (e *Engine) revalidate(step *scenario.Step, response *protocols.HTTPResponse) error {
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("revalidation needs a 200 response to revalidate, got %d", response.StatusCode)
+	}
+
+	headers := http.Header(response.Headers)
+	conditions := [][2]string{
+		{"If-None-Match", headers.Get("ETag")},
+		{"If-Modified-Since", headers.Get("Last-Modified")},
+	}
+	sent := 0
+	for _, condition := range conditions {
+		header, validator := condition[0], condition[1]
+		if validator == "" {
+			continue
+		}
+		sent++
+
+		conditional := *step
+		conditional.Request.Headers = make(scenario.Params, len(step.Request.Headers)+1)
+		for key, value := range step.Request.Headers {
+			conditional.Request.Headers[key] = value
+		}
+		conditional.Request.Headers[header] = validator
+
+		revalidated, err := e.httpClient.Execute(&conditional)
+		if err != nil {
+			return fmt.Errorf("revalidation with %s failed: %w", header, err)
+		}
+		if revalidated.StatusCode != http.StatusNotModified {
+			return fmt.Errorf("revalidation with %s: %s returned %d, expected 304", header, validator, revalidated.StatusCode)
+		}
+	}
+
+	if sent == 0 {
+		return fmt.Errorf("revalidation needs an ETag or Last-Modified header, the response has neither")
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package execution
+
+import (
+	"hash/fnv"
+	"time"
+)
+
+// newRunSeed returns the seed of a run: the one requested, or a fresh one that is reported so the
+// run can be reproduced with --seed
+func newRunSeed(requested int64) int64 {
+	if requested != 0 {
+		return requested
+	}
+	return time.Now().UnixNano()
+}
+
+// derivedSeed returns a stable seed for one random source of the run, so the values a source
+// produces do not depend on the order (or parallelism) in which sources are used
+func (e *Engine) derivedSeed(parts ...string) int64 {
+	h := fnv.New64a()
+	var seed [8]byte
+	for i := range seed {
+		seed[i] = byte(e.seed >> (8 * i))
+	}
+	h.Write(seed[:])
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+
+	derived := int64(h.Sum64() &^ (1 << 63))
+	if derived == 0 {
+		// 0 means "random" to data sources
+		derived = 1
+	}
+	return derived
+}
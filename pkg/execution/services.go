@@ -0,0 +1,49 @@
+package execution
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nulln0ne/fuego/pkg/protocols"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+)
+
+// applyService resolves a step addressed to a config service: a relative URL is joined to the
+// service base URL, and the service headers, query parameters and auth apply where the step sets
+// none of its own; its middleware runs before the step's. Auth credentials expand ${VAR} environment variables.
+func (e *Engine) applyService(step *scenario.Step) (*scenario.Step, error) {
+	name := step.Request.Service
+	if name == "" {
+		return step, nil
+	}
+	service, exists := e.config.Services[name]
+	if !exists {
+		return nil, fmt.Errorf("unknown service %s (define it under services in the config)", name)
+	}
+
+	resolved := *step
+	if !protocols.IsAbsoluteURL(step.Request.URL) {
+		if service.BaseURL == "" {
+			return nil, fmt.Errorf("service %s has no base_url", name)
+		}
+		resolved.Request.URL = protocols.JoinURL(service.BaseURL, step.Request.URL)
+	}
+	resolved.Request.Headers = scenario.MergeHeaders(service.Headers, step.Request.Headers)
+	resolved.Request.Query = scenario.MergeQuery(service.Query, step.Request.Query)
+	if len(service.Middleware) > 0 {
+		resolved.Request.Middleware = append(append([]string(nil), service.Middleware...), step.Request.Middleware...)
+	}
+
+	if step.Request.Auth == nil && service.Auth != nil {
+		resolved.Request.Auth = &scenario.AuthConfig{
+			Type:     service.Auth.Type,
+			Username: os.ExpandEnv(service.Auth.Username),
+			Password: os.ExpandEnv(service.Auth.Password),
+			Token:    os.ExpandEnv(service.Auth.Token),
+		}
+		if service.Auth.Header != "" {
+			resolved.Request.Auth.Config = map[string]interface{}{"header": service.Auth.Header}
+		}
+	}
+	return &resolved, nil
+}
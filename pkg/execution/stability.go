@@ -0,0 +1,141 @@
+package execution
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/protocols"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/nulln0ne/fuego/pkg/variables"
+)
+
+// checkStability repeats a request until it was sent stability.Calls times and compares the
+// value at stability.Path across all responses, the first one included. Every call is made even
+// after a mismatch, so the error shows how the values are spread over the calls.
+func (e *Engine) checkStability(step *scenario.Step, first *protocols.HTTPResponse) error {
+	stability := step.Request.Stability
+	values := make([]interface{}, 0, stability.Calls)
+	extract := func(call int, response *protocols.HTTPResponse) error {
+		value, err := variables.ExtractFromResponse(map[string]interface{}{"body_text": response.BodyText}, "json:"+stability.Path)
+		if err != nil {
+			return fmt.Errorf("stability: call %d: %s: %w", call, stability.Path, err)
+		}
+		values = append(values, value)
+		return nil
+	}
+
+	if err := extract(1, first); err != nil {
+		return err
+	}
+	for call := 2; call <= stability.Calls; call++ {
+		if stability.Interval > 0 {
+			time.Sleep(stability.Interval)
+		}
+		response, err := e.httpClient.Execute(step)
+		if err != nil {
+			return fmt.Errorf("stability: call %d failed: %w", call, err)
+		}
+		if err := extract(call, response); err != nil {
+			return err
+		}
+	}
+
+	if stability.Mode == "" || stability.Mode == scenario.StabilityIdentical {
+		return identicalValues(stability.Path, values)
+	}
+	strict := stability.Mode == scenario.StabilityIncreasing
+	for i := 1; i < len(values); i++ {
+		order, err := compareOrdered(values[i-1], values[i])
+		if err != nil {
+			return fmt.Errorf("stability of %s: calls %d and %d: %w", stability.Path, i, i+1, err)
+		}
+		if order > 0 || (strict && order == 0) {
+			return fmt.Errorf("stability of %s: call %d returned %s after %s from call %d, expected %s values",
+				stability.Path, i+1, formatStable(values[i]), formatStable(values[i-1]), i, strings.ReplaceAll(stability.Mode, "_", "-"))
+		}
+	}
+	return nil
+}
+
+// identicalValues fails when the values differ, listing each distinct value with its calls
+func identicalValues(path string, values []interface{}) error {
+	var distinct []interface{}
+	calls := make(map[int][]string)
+	for i, value := range values {
+		index := -1
+		for j, seen := range distinct {
+			if reflect.DeepEqual(seen, value) {
+				index = j
+				break
+			}
+		}
+		if index < 0 {
+			index = len(distinct)
+			distinct = append(distinct, value)
+		}
+		calls[index] = append(calls[index], strconv.Itoa(i+1))
+	}
+	if len(distinct) == 1 {
+		return nil
+	}
+
+	parts := make([]string, len(distinct))
+	for i, value := range distinct {
+		parts[i] = fmt.Sprintf("%s (calls %s)", formatStable(value), strings.Join(calls[i], ", "))
+	}
+	return fmt.Errorf("stability of %s: %d calls returned %d different values: %s", path, len(values), len(distinct), strings.Join(parts, ", "))
+}
+
+// compareOrdered compares numbers, timestamps and other strings, returning -1, 0 or 1
+func compareOrdered(a, b interface{}) (int, error) {
+	if x, ok := orderedNumber(a); ok {
+		if y, ok := orderedNumber(b); ok {
+			return compareFloats(x, y), nil
+		}
+	}
+	x, aString := a.(string)
+	y, bString := b.(string)
+	if !aString || !bString {
+		return 0, fmt.Errorf("cannot order %s and %s", formatStable(a), formatStable(b))
+	}
+	if first, err := time.Parse(time.RFC3339Nano, x); err == nil {
+		if second, err := time.Parse(time.RFC3339Nano, y); err == nil {
+			return first.Compare(second), nil
+		}
+	}
+	return strings.Compare(x, y), nil
+}
+
+// orderedNumber reads JSON numbers and numeric strings, e.g. counters serialized as strings
+func orderedNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		return n, err == nil
+	}
+	return 0, false
+}
+
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func formatStable(value interface{}) string {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(encoded)
+}
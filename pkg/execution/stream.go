@@ -0,0 +1,21 @@
+package execution
+
+import (
+	"github.com/nulln0ne/fuego/pkg/protocols"
+)
+
+// streamMap exposes how a streamed body arrived to stream assertions and captures, with times in
+// milliseconds
+func streamMap(stream *protocols.StreamStats) map[string]interface{} {
+	chunks := make([]interface{}, len(stream.Contents))
+	for i, content := range stream.Contents {
+		chunks[i] = content
+	}
+	return map[string]interface{}{
+		"first_chunk": stream.FirstChunk.Milliseconds(),
+		"duration":    stream.Duration.Milliseconds(),
+		"chunk_count": stream.Chunks,
+		"chunks":      chunks,
+		"ended":       stream.Ended,
+	}
+}
@@ -0,0 +1,54 @@
+package execution
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+)
+
+// tmpdirVariable holds the temporary directory of the running scenario
+const tmpdirVariable = "tmpdir"
+
+// enterTmpDir creates the temporary directory of a scenario run, exposed as {{tmpdir}}. Every run
+// of a scenario gets its own, so repeated, sharded or concurrent runs never share files.
+func (e *Engine) enterTmpDir(sc *scenario.Scenario) error {
+	name := strings.Trim(unsafeFileChars.ReplaceAllString(sc.Name, "_"), "_")
+	if len(name) > 40 {
+		name = name[:40]
+	}
+	dir, err := os.MkdirTemp("", "fuego-"+name+"-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	e.tmpDir = dir
+	return nil
+}
+
+// leaveTmpDir removes the temporary directory of the scenario unless the options keep it
+func (e *Engine) leaveTmpDir() {
+	if e.tmpDir != "" && !e.options.KeepTmpDirs {
+		os.RemoveAll(e.tmpDir)
+	}
+	e.tmpDir = ""
+}
+
+// inTmpDir resolves a relative path written by a step under the scenario's temporary directory
+func (e *Engine) inTmpDir(path string) string {
+	if e.tmpDir == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(e.tmpDir, path)
+}
+
+// underTmpDir reports whether a path is inside the scenario's temporary directory, which is
+// removed when the scenario ends
+func (e *Engine) underTmpDir(path string) bool {
+	if e.tmpDir == "" {
+		return false
+	}
+	rel, err := filepath.Rel(e.tmpDir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
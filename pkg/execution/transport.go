@@ -0,0 +1,57 @@
+package execution
+
+import (
+	"errors"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/protocols"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+)
+
+// transportCheckTypes are the checks on the transport error of a request that got no response:
+// error is its kind, error_contains a part of its message and timeout whether it timed out
+var transportCheckTypes = map[string]scenario.Assertion{
+	"error":          {Type: "error", Field: "kind", Operator: "eq"},
+	"error_contains": {Type: "error", Field: "message", Operator: "contains"},
+	"timeout":        {Type: "error", Field: "timeout", Operator: "eq"},
+}
+
+// expectsTransportError reports whether a step asserts on a transport error, which then makes
+// the failed request the outcome its checks and assertions are evaluated against
+func expectsTransportError(step *scenario.Step) bool {
+	for _, assertion := range step.Assertions {
+		if assertion.Type == "error" {
+			return true
+		}
+	}
+	checks := []map[string]interface{}{step.Check}
+	if step.HTTP != nil {
+		checks = append(checks, step.HTTP.Check)
+	}
+	for _, check := range checks {
+		for name := range check {
+			if _, exists := transportCheckTypes[name]; exists {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// transportErrorResponse turns a request that failed without a response into the response
+// assertions see, holding the error and how long the request took. Other errors are returned
+// unchanged.
+func transportErrorResponse(err error, duration time.Duration) (map[string]interface{}, error) {
+	var transportErr *protocols.TransportError
+	if !errors.As(err, &transportErr) {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"error": map[string]interface{}{
+			"message": err.Error(),
+			"kind":    transportErr.Kind,
+			"timeout": transportErr.Timeout(),
+		},
+		"duration": duration,
+	}, nil
+}
@@ -0,0 +1,80 @@
+package execution
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/nulln0ne/fuego/pkg/variables"
+)
+
+// verifyStepName names the result of a scenario's verify block in reports
+const verifyStepName = "Verify"
+
+// verifyScenario checks the verify block of a scenario once all its steps ran. Variable
+// assertions see every variable of the scenario, steps assertions the totals of the step results
+// and step assertions the results of one step by name.
+func (e *Engine) verifyScenario(sc *scenario.Scenario, steps []reporting.StepResult, varContext *variables.Context) reporting.StepResult {
+	step := &scenario.Step{Name: verifyStepName, Assertions: sc.Verify}
+	result := reporting.StepResult{Step: step, Status: "passed", StartTime: time.Now()}
+
+	assertionResults, err := e.newAssertionEngine(step, varContext).RunAssertions(sc.Verify, stepsSummary(steps))
+	if err != nil {
+		result.Status = "failed"
+		result.Error = fmt.Sprintf("Assertion error: %v", err)
+	}
+	result.Assertions = assertionResults
+	for _, assertionResult := range assertionResults {
+		if !assertionResult.Passed {
+			result.Status = "failed"
+			break
+		}
+	}
+
+	result.EndTime = time.Now()
+	result.Duration = result.EndTime.Sub(result.StartTime)
+	e.reporter.Emit(reporting.Event{Type: reporting.EventStepFinished, Scenario: e.currentScenario, Run: e.run, Step: &result})
+	return result
+}
+
+// stepsSummary sums up step results for verify assertions, with durations in milliseconds. The
+// iterations of a step count once by name, as failed when any of them failed and with the
+// duration of the slowest.
+func stepsSummary(steps []reporting.StepResult) map[string]interface{} {
+	totals := map[string]interface{}{"count": len(steps), "passed": 0, "failed": 0, "skipped": 0}
+	byName := make(map[string]interface{})
+	var slowest, total time.Duration
+	slowestName := ""
+
+	for _, step := range steps {
+		switch step.Status {
+		case "passed", "failed", "skipped":
+			totals[step.Status] = totals[step.Status].(int) + 1
+		}
+		total += step.Duration
+		if step.Duration > slowest {
+			slowest = step.Duration
+			slowestName = step.LogicalName()
+		}
+
+		name := step.LogicalName()
+		summary, exists := byName[name].(map[string]interface{})
+		if !exists {
+			summary = map[string]interface{}{"status": step.Status, "duration": step.Duration.Milliseconds(), "runs": 0}
+			byName[name] = summary
+		}
+		summary["runs"] = summary["runs"].(int) + 1
+		if summary["status"] != "failed" {
+			summary["status"] = step.Status
+		}
+		if duration := step.Duration.Milliseconds(); duration > summary["duration"].(int64) {
+			summary["duration"] = duration
+		}
+	}
+
+	totals["max_duration"] = slowest.Milliseconds()
+	totals["total_duration"] = total.Milliseconds()
+	totals["slowest"] = slowestName
+	return map[string]interface{}{"steps": totals, "step": byName}
+}
@@ -0,0 +1,466 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+func (n *literalNode) eval(env *Env) (interface{}, error) {
+	return n.value, nil
+}
+
+// Unknown variables evaluate to null so filters over rows with optional fields stay simple
+func (n *variableNode) eval(env *Env) (interface{}, error) {
+	if env.Resolve == nil {
+		return nil, nil
+	}
+	value, _ := env.Resolve(n.name)
+	return value, nil
+}
+
+func (n *listNode) eval(env *Env) (interface{}, error) {
+	items := make([]interface{}, 0, len(n.items))
+	for _, item := range n.items {
+		value, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, value)
+	}
+	return items, nil
+}
+
+func (n *unaryNode) eval(env *Env) (interface{}, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "!" {
+		return !Truthy(value), nil
+	}
+
+	number, ok := ToNumber(value)
+	if !ok {
+		return nil, fmt.Errorf("cannot negate %v", value)
+	}
+	return normalizeNumber(-number), nil
+}
+
+func (n *binaryNode) eval(env *Env) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	// Short-circuit logic
+	switch n.op {
+	case "&&":
+		if !Truthy(left) {
+			return false, nil
+		}
+		right, err := n.right.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		return Truthy(right), nil
+	case "||":
+		if Truthy(left) {
+			return true, nil
+		}
+		right, err := n.right.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		return Truthy(right), nil
+	}
+
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return Equal(left, right), nil
+	case "!=":
+		return !Equal(left, right), nil
+	case "<", "<=", ">", ">=":
+		cmp, err := Compare(left, right)
+		if err != nil {
+			return nil, err
+		}
+		switch n.op {
+		case "<":
+			return cmp < 0, nil
+		case "<=":
+			return cmp <= 0, nil
+		case ">":
+			return cmp > 0, nil
+		default:
+			return cmp >= 0, nil
+		}
+	case "in":
+		return contains(right, left), nil
+	case "not in":
+		return !contains(right, left), nil
+	case "+":
+		ln, lok := ToNumber(left)
+		rn, rok := ToNumber(right)
+		_, lstr := left.(string)
+		_, rstr := right.(string)
+		if lok && rok && !lstr && !rstr {
+			return normalizeNumber(ln + rn), nil
+		}
+		return ToString(left) + ToString(right), nil
+	default:
+		ln, lok := ToNumber(left)
+		rn, rok := ToNumber(right)
+		if !lok || !rok {
+			return nil, fmt.Errorf("operator %s needs numbers, got %v and %v", n.op, left, right)
+		}
+		switch n.op {
+		case "-":
+			return normalizeNumber(ln - rn), nil
+		case "*":
+			return normalizeNumber(ln * rn), nil
+		case "/":
+			if rn == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			return normalizeNumber(ln / rn), nil
+		case "%":
+			if rn == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			return normalizeNumber(math.Mod(ln, rn)), nil
+		}
+	}
+
+	return nil, fmt.Errorf("unsupported operator %s", n.op)
+}
+
+func (n *callNode) eval(env *Env) (interface{}, error) {
+	fn, exists := env.Funcs[n.name]
+	if !exists {
+		fn, exists = builtins[n.name]
+	}
+	if !exists {
+		return nil, fmt.Errorf("unknown function %s", n.name)
+	}
+
+	args := make([]interface{}, 0, len(n.args))
+	for _, arg := range n.args {
+		value, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, value)
+	}
+
+	result, err := fn(args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s(): %w", n.name, err)
+	}
+	return result, nil
+}
+
+// Only the chosen branch is evaluated, so it may guard against a missing value
+func (n *conditionalNode) eval(env *Env) (interface{}, error) {
+	condition, err := n.condition.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if Truthy(condition) {
+		return n.then.eval(env)
+	}
+	return n.otherwise.eval(env)
+}
+
+// Truthy reports whether a value counts as true: non-zero numbers, non-empty strings and
+// collections, and true (strings "false", "no", "off" and "0" are false)
+func Truthy(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "", "false", "no", "off", "0":
+			return false
+		}
+		return true
+	}
+
+	if number, ok := ToNumber(value); ok {
+		return number != 0
+	}
+
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return rv.Len() > 0
+	}
+	return true
+}
+
+// ToNumber converts numeric values and numeric strings to float64
+func ToNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case int8:
+		return float64(v), true
+	case int16:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint:
+		return float64(v), true
+	case uint8:
+		return float64(v), true
+	case uint16:
+		return float64(v), true
+	case uint32:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	case float32:
+		return float64(v), true
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// ToString renders a value the way it appears in interpolated strings
+func ToString(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// Equal compares values loosely: numbers by value (so 200 == "200"), everything else deeply
+func Equal(a, b interface{}) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	an, aok := ToNumber(a)
+	bn, bok := ToNumber(b)
+	if aok && bok {
+		return an == bn
+	}
+
+	if as, ok := a.(string); ok {
+		if bs, ok := b.(string); ok {
+			return as == bs
+		}
+	}
+	if ab, ok := a.(bool); ok {
+		return Truthy(b) == ab && isBoolLike(b)
+	}
+	if bb, ok := b.(bool); ok {
+		return Truthy(a) == bb && isBoolLike(a)
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+func isBoolLike(value interface{}) bool {
+	switch v := value.(type) {
+	case bool:
+		return true
+	case string:
+		switch strings.ToLower(v) {
+		case "true", "false":
+			return true
+		}
+	}
+	return false
+}
+
+// Compare orders numbers numerically and everything else as strings
+func Compare(a, b interface{}) (int, error) {
+	an, aok := ToNumber(a)
+	bn, bok := ToNumber(b)
+	if aok && bok {
+		switch {
+		case an < bn:
+			return -1, nil
+		case an > bn:
+			return 1, nil
+		}
+		return 0, nil
+	}
+
+	as, aok := a.(string)
+	bs, bok := b.(string)
+	if aok && bok {
+		return strings.Compare(as, bs), nil
+	}
+
+	return 0, fmt.Errorf("cannot compare %v and %v", a, b)
+}
+
+func contains(collection, item interface{}) bool {
+	switch c := collection.(type) {
+	case nil:
+		return false
+	case string:
+		return strings.Contains(c, ToString(item))
+	case map[string]interface{}:
+		_, exists := c[ToString(item)]
+		return exists
+	}
+
+	rv := reflect.ValueOf(collection)
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		for i := 0; i < rv.Len(); i++ {
+			if Equal(rv.Index(i).Interface(), item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// normalizeNumber keeps whole numbers as int so they render and compare like YAML integers
+func normalizeNumber(f float64) interface{} {
+	if f == math.Trunc(f) && math.Abs(f) < 1<<53 {
+		return int(f)
+	}
+	return f
+}
+
+var builtins = map[string]Func{
+	"len": func(args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expects 1 argument")
+		}
+		if s, ok := args[0].(string); ok {
+			return len([]rune(s)), nil
+		}
+		rv := reflect.ValueOf(args[0])
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Map, reflect.Array:
+			return rv.Len(), nil
+		}
+		return 0, nil
+	},
+	"lower": stringFunc(strings.ToLower),
+	"upper": stringFunc(strings.ToUpper),
+	"trim":  stringFunc(strings.TrimSpace),
+	"contains": func(args ...interface{}) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("expects 2 arguments")
+		}
+		return contains(args[0], args[1]), nil
+	},
+	"startsWith": func(args ...interface{}) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("expects 2 arguments")
+		}
+		return strings.HasPrefix(ToString(args[0]), ToString(args[1])), nil
+	},
+	"endsWith": func(args ...interface{}) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("expects 2 arguments")
+		}
+		return strings.HasSuffix(ToString(args[0]), ToString(args[1])), nil
+	},
+	"matches": func(args ...interface{}) (interface{}, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("expects 2 arguments")
+		}
+		re, err := regexp.Compile(ToString(args[1]))
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString(ToString(args[0])), nil
+	},
+	"int": func(args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expects 1 argument")
+		}
+		number, ok := ToNumber(args[0])
+		if !ok {
+			return nil, fmt.Errorf("%v is not a number", args[0])
+		}
+		return int(number), nil
+	},
+	"float": func(args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expects 1 argument")
+		}
+		number, ok := ToNumber(args[0])
+		if !ok {
+			return nil, fmt.Errorf("%v is not a number", args[0])
+		}
+		return number, nil
+	},
+	"string": func(args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expects 1 argument")
+		}
+		return ToString(args[0]), nil
+	},
+	"abs": func(args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expects 1 argument")
+		}
+		number, ok := ToNumber(args[0])
+		if !ok {
+			return nil, fmt.Errorf("%v is not a number", args[0])
+		}
+		return normalizeNumber(math.Abs(number)), nil
+	},
+	"min": numberReducer(math.Min),
+	"max": numberReducer(math.Max),
+}
+
+func stringFunc(fn func(string) string) Func {
+	return func(args ...interface{}) (interface{}, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("expects 1 argument")
+		}
+		return fn(ToString(args[0])), nil
+	}
+}
+
+func numberReducer(fn func(a, b float64) float64) Func {
+	return func(args ...interface{}) (interface{}, error) {
+		if len(args) == 0 {
+			return nil, fmt.Errorf("expects at least 1 argument")
+		}
+		var result float64
+		for i, arg := range args {
+			number, ok := ToNumber(arg)
+			if !ok {
+				return nil, fmt.Errorf("%v is not a number", arg)
+			}
+			if i == 0 {
+				result = number
+			} else {
+				result = fn(result, number)
+			}
+		}
+		return normalizeNumber(result), nil
+	}
+}
@@ -0,0 +1,217 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Resolver looks up a variable by its (possibly dotted) name
+type Resolver func(name string) (interface{}, bool)
+
+// Func is a function callable from expressions
+type Func func(args ...interface{}) (interface{}, error)
+
+// Env provides variables and functions to an evaluation
+type Env struct {
+	Resolve Resolver
+	// Funcs extends or overrides the built-in functions
+	Funcs map[string]Func
+}
+
+// Expr is a compiled expression
+type Expr struct {
+	source string
+	root   node
+}
+
+// Compile parses an expression such as `age >= 18 && country in ['DE', 'FR']`.
+//
+// Supported: number, string ('..' or ".."), true/false/null literals and [list] literals;
+// dotted variable paths (user.address.city, items.0.id); arithmetic + - * / %; comparisons
+// == != < <= > >=; logic && || ! (also and, or, not); `in` for list membership, substrings and
+// map keys; conditionals `cond ? a : b`; and function calls such as len(x) or lower(name).
+func Compile(source string) (*Expr, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseConditional()
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %q: %w", source, err)
+	}
+	if p.peek().kind != tokenEOF {
+		return nil, fmt.Errorf("invalid expression %q: unexpected %q", source, p.peek().text)
+	}
+
+	return &Expr{source: source, root: root}, nil
+}
+
+// Eval evaluates the expression
+func (e *Expr) Eval(env Env) (interface{}, error) {
+	value, err := e.root.eval(&env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate %q: %w", e.source, err)
+	}
+	return value, nil
+}
+
+// Variables returns the dotted names of the variables the expression refers to, in order of
+// appearance and without duplicates
+func (e *Expr) Variables() []string {
+	var names []string
+	collectVariables(e.root, &names)
+
+	seen := make(map[string]bool, len(names))
+	unique := names[:0]
+	for _, name := range names {
+		if !seen[name] {
+			seen[name] = true
+			unique = append(unique, name)
+		}
+	}
+	return unique
+}
+
+// String returns the source of the expression
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Eval compiles and evaluates an expression in one go
+func Eval(source string, env Env) (interface{}, error) {
+	compiled, err := Compile(source)
+	if err != nil {
+		return nil, err
+	}
+	return compiled.Eval(env)
+}
+
+// EvalBool evaluates an expression and reports whether the result is truthy
+func EvalBool(source string, env Env) (bool, error) {
+	value, err := Eval(source, env)
+	if err != nil {
+		return false, err
+	}
+	return Truthy(value), nil
+}
+
+// MapResolver resolves dotted names against a map, descending into nested maps and lists
+func MapResolver(values map[string]interface{}) Resolver {
+	return func(name string) (interface{}, bool) {
+		return Lookup(values, name)
+	}
+}
+
+// Lookup walks a dotted path (user.tags.0) through nested maps and lists
+func Lookup(value interface{}, path string) (interface{}, bool) {
+	current := value
+	for _, part := range strings.Split(path, ".") {
+		switch v := current.(type) {
+		case map[string]interface{}:
+			next, exists := v[part]
+			if !exists {
+				return nil, false
+			}
+			current = next
+		case map[string]string:
+			next, exists := v[part]
+			if !exists {
+				return nil, false
+			}
+			current = next
+		case []interface{}:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			current = v[index]
+		case []map[string]interface{}:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 || index >= len(v) {
+				return nil, false
+			}
+			current = v[index]
+		default:
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenNumber
+	tokenString
+	tokenIdent
+	tokenOperator
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "(", ")", "[", "]", ",", "!", "<", ">", "+", "-", "*", "/", "%", "?", ":"}
+
+func tokenize(source string) ([]token, error) {
+	var tokens []token
+	runes := []rune(source)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.' || runes[i] == 'e' || runes[i] == 'E') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, text: string(runes[start:i]), pos: start})
+		case r == '\'' || r == '"':
+			start := i
+			var b strings.Builder
+			i++
+			for i < len(runes) && runes[i] != r {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				b.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			i++
+			tokens = append(tokens, token{kind: tokenString, text: b.String(), pos: start})
+		case unicode.IsLetter(r) || r == '_' || r == '$':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), pos: start})
+		default:
+			matched := false
+			for _, op := range operators {
+				if strings.HasPrefix(string(runes[i:]), op) {
+					tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+					i += len([]rune(op))
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("unexpected character %q at position %d", r, i)
+			}
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
@@ -0,0 +1,280 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type node interface {
+	eval(env *Env) (interface{}, error)
+}
+
+type literalNode struct{ value interface{} }
+
+type variableNode struct{ name string }
+
+type listNode struct{ items []node }
+
+type unaryNode struct {
+	op      string
+	operand node
+}
+
+type binaryNode struct {
+	op          string
+	left, right node
+}
+
+type callNode struct {
+	name string
+	args []node
+}
+
+type conditionalNode struct {
+	condition, then, otherwise node
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) expect(op string) error {
+	t := p.next()
+	if t.kind != tokenOperator || t.text != op {
+		if t.kind == tokenEOF {
+			return fmt.Errorf("expected %q at end of expression", op)
+		}
+		return fmt.Errorf("expected %q at position %d, got %q", op, t.pos, t.text)
+	}
+	return nil
+}
+
+// binaryOperator returns the normalized operator at the current position and its precedence
+func (p *parser) binaryOperator() (string, int, int) {
+	t := p.peek()
+	op := t.text
+	width := 1
+
+	if t.kind == tokenIdent {
+		switch op {
+		case "and":
+			op = "&&"
+		case "or":
+			op = "||"
+		case "in":
+		case "not":
+			if following := p.tokens[p.pos+1]; following.kind == tokenIdent && following.text == "in" {
+				op = "not in"
+				width = 2
+			} else {
+				return "", 0, 0
+			}
+		default:
+			return "", 0, 0
+		}
+	} else if t.kind != tokenOperator {
+		return "", 0, 0
+	}
+
+	switch op {
+	case "||":
+		return op, 1, width
+	case "&&":
+		return op, 2, width
+	case "==", "!=":
+		return op, 3, width
+	case "<", "<=", ">", ">=", "in", "not in":
+		return op, 4, width
+	case "+", "-":
+		return op, 5, width
+	case "*", "/", "%":
+		return op, 6, width
+	}
+	return "", 0, 0
+}
+
+func (p *parser) parseExpression(minPrecedence int) (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		op, precedence, width := p.binaryOperator()
+		if precedence == 0 || precedence <= minPrecedence {
+			return left, nil
+		}
+		p.pos += width
+
+		right, err := p.parseExpression(precedence)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryNode{op: op, left: left, right: right}
+	}
+}
+
+// parseConditional parses `condition ? then : otherwise`, which binds looser than any binary
+// operator
+func (p *parser) parseConditional() (node, error) {
+	condition, err := p.parseExpression(0)
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenOperator || t.text != "?" {
+		return condition, nil
+	}
+	p.next()
+
+	then, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+	otherwise, err := p.parseConditional()
+	if err != nil {
+		return nil, err
+	}
+	return &conditionalNode{condition: condition, then: then, otherwise: otherwise}, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	t := p.peek()
+	if (t.kind == tokenOperator && (t.text == "!" || t.text == "-")) || (t.kind == tokenIdent && t.text == "not") {
+		p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		op := t.text
+		if op == "not" {
+			op = "!"
+		}
+		return &unaryNode{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenNumber:
+		if i, err := strconv.ParseInt(t.text, 10, 64); err == nil {
+			return &literalNode{value: int(i)}, nil
+		}
+		f, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q at position %d", t.text, t.pos)
+		}
+		return &literalNode{value: f}, nil
+	case tokenString:
+		return &literalNode{value: t.text}, nil
+	case tokenIdent:
+		switch t.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null", "nil":
+			return &literalNode{value: nil}, nil
+		}
+
+		if next := p.peek(); next.kind == tokenOperator && next.text == "(" {
+			p.next()
+			args, err := p.parseList(")")
+			if err != nil {
+				return nil, err
+			}
+			return &callNode{name: t.text, args: args}, nil
+		}
+		return &variableNode{name: t.text}, nil
+	case tokenOperator:
+		switch t.text {
+		case "(":
+			inner, err := p.parseConditional()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		case "[":
+			items, err := p.parseList("]")
+			if err != nil {
+				return nil, err
+			}
+			return &listNode{items: items}, nil
+		}
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+
+	return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+}
+
+// parseList parses comma separated expressions up to the closing operator
+func (p *parser) parseList(closing string) ([]node, error) {
+	var items []node
+	if t := p.peek(); t.kind == tokenOperator && t.text == closing {
+		p.next()
+		return items, nil
+	}
+
+	for {
+		item, err := p.parseConditional()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+
+		t := p.next()
+		if t.kind == tokenOperator && t.text == closing {
+			return items, nil
+		}
+		if t.kind != tokenOperator || t.text != "," {
+			return nil, fmt.Errorf("expected \",\" or %q at position %d", closing, t.pos)
+		}
+	}
+}
+
+// collectVariables appends the names of the variables an expression refers to
+func collectVariables(n node, names *[]string) {
+	switch n := n.(type) {
+	case *variableNode:
+		*names = append(*names, n.name)
+	case *listNode:
+		for _, item := range n.items {
+			collectVariables(item, names)
+		}
+	case *unaryNode:
+		collectVariables(n.operand, names)
+	case *binaryNode:
+		collectVariables(n.left, names)
+		collectVariables(n.right, names)
+	case *callNode:
+		for _, arg := range n.args {
+			collectVariables(arg, names)
+		}
+	case *conditionalNode:
+		collectVariables(n.condition, names)
+		collectVariables(n.then, names)
+		collectVariables(n.otherwise, names)
+	}
+}
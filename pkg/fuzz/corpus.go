@@ -0,0 +1,62 @@
+package fuzz
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Payload is a value substituted into a fuzzed field
+type Payload struct {
+	Corpus string      `json:"corpus"`
+	Value  interface{} `json:"value"` // numbers stay numbers in JSON bodies
+}
+
+// corpora are the built-in payloads by corpus name
+var corpora = map[string][]interface{}{
+	"numbers": {
+		0, -1, 1, 2147483647, 2147483648, -2147483649, int64(9223372036854775807),
+		"9223372036854775808", "-9223372036854775809", 1e308, -1e308, 0.1, "1e309", "NaN", "Infinity", "0x10", "1_000",
+	},
+	"strings": {
+		"", " ", strings.Repeat("A", 256), strings.Repeat("A", 4096), strings.Repeat("A", 65536),
+		"null", "true", "[]", "{}", "\x00", "‮evil", "😀😀😀", "Ω≈ç√∫", "%s%s%s%n", "\r\nX-Injected: 1",
+	},
+	"injection": {
+		"' OR '1'='1", "1; DROP TABLE users--", `" OR ""="`, "<script>alert(1)</script>", `"><img src=x onerror=alert(1)>`,
+		"{{7*7}}", "${7*7}", "../../../../etc/passwd", "..%2f..%2f..%2fetc%2fpasswd", "; cat /etc/passwd", "$(id)", "`id`",
+		`{"$gt": ""}`, "*)(uid=*))(|(uid=*", "%00",
+	},
+}
+
+// Corpora returns the payloads of the named corpora, or of all of them when none are named
+func Corpora(names ...string) ([]Payload, error) {
+	if len(names) == 0 {
+		for name := range corpora {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+	}
+
+	var payloads []Payload
+	for _, name := range names {
+		values, exists := corpora[name]
+		if !exists {
+			return nil, fmt.Errorf("unknown corpus %s (available: %s)", name, strings.Join(CorpusNames(), ", "))
+		}
+		for _, value := range values {
+			payloads = append(payloads, Payload{Corpus: name, Value: value})
+		}
+	}
+	return payloads, nil
+}
+
+// CorpusNames lists the built-in corpora
+func CorpusNames() []string {
+	names := make([]string, 0, len(corpora))
+	for name := range corpora {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
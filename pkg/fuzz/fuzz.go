@@ -0,0 +1,134 @@
+// Package fuzz mutates fields of a scenario step with built-in corpora (boundary numbers, long
+// and unusual strings, injection payloads) and flags responses that deviate from a baseline run:
+// server errors, transport errors such as timeouts, and JSON schema violations.
+package fuzz
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+)
+
+// Target is a scenario cut down to what one step needs: setup and before steps, the steps before
+// the target in its group, the target itself and the teardown
+type Target struct {
+	scenario *scenario.Scenario
+	steps    []scenario.Step // the slice holding the target, owned by the Target
+	index    int
+}
+
+// NewTarget finds a step by name in the top-level steps or a test group of a scenario
+func NewTarget(sc *scenario.Scenario, step string) (*Target, error) {
+	cut := *sc
+	cut.Steps, cut.Tests = nil, nil
+
+	if index := stepIndex(sc.Steps, step); index >= 0 {
+		cut.Steps = append([]scenario.Step(nil), sc.Steps[:index+1]...)
+		return &Target{scenario: &cut, steps: cut.Steps, index: index}, nil
+	}
+	for name, group := range sc.Tests {
+		if index := stepIndex(group.Steps, step); index >= 0 {
+			cutGroup := *group
+			cutGroup.Steps = append([]scenario.Step(nil), group.Steps[:index+1]...)
+			cutGroup.OnFailure, cutGroup.OnSuccess = nil, nil
+			cut.Tests = map[string]*scenario.TestGroup{name: &cutGroup}
+			return &Target{scenario: &cut, steps: cutGroup.Steps, index: index}, nil
+		}
+	}
+	return nil, fmt.Errorf("scenario %s has no step named %s", sc.Name, step)
+}
+
+func stepIndex(steps []scenario.Step, name string) int {
+	for i := range steps {
+		if steps[i].Name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// Step returns the target step as written in the scenario
+func (t *Target) Step() scenario.Step {
+	return t.steps[t.index]
+}
+
+// Baseline returns the scenario with the target step unchanged
+func (t *Target) Baseline() *scenario.Scenario {
+	return t.With(t.Step())
+}
+
+// With returns the scenario with the target step replaced
+func (t *Target) With(step scenario.Step) *scenario.Scenario {
+	steps := append([]scenario.Step(nil), t.steps...)
+	steps[t.index] = step
+
+	sc := *t.scenario
+	if sc.Tests == nil {
+		sc.Steps = steps
+		return &sc
+	}
+	sc.Tests = make(map[string]*scenario.TestGroup, 1)
+	for name, group := range t.scenario.Tests {
+		withStep := *group
+		withStep.Steps = steps
+		sc.Tests[name] = &withStep
+	}
+	return &sc
+}
+
+// Observation is what a run of the target step returned
+type Observation struct {
+	Status      int    `json:"status,omitempty"`
+	Error       string `json:"error,omitempty"` // transport errors: timeouts, refused or reset connections
+	SchemaValid bool   `json:"schema_valid"`
+}
+
+// Observe finds the results of the target step in a report; data-driven steps have several
+func Observe(report *reporting.Report, step string) []Observation {
+	var observations []Observation
+	for _, sc := range report.Scenarios {
+		for _, result := range sc.Steps {
+			if result.LogicalName() != step || result.Status == "skipped" {
+				continue
+			}
+			observation := Observation{SchemaValid: true}
+			if response, ok := result.Response.(map[string]interface{}); ok {
+				observation.Status, _ = response["status_code"].(int)
+			} else {
+				observation.Error = result.Error
+			}
+			for _, assertion := range result.Assertions {
+				if assertion.Assertion != nil && assertion.Assertion.Type == "json_schema" && !assertion.Passed {
+					observation.SchemaValid = false
+				}
+			}
+			observations = append(observations, observation)
+		}
+	}
+	return observations
+}
+
+// Deviation describes how an observation departs from the baseline, or returns "" when it does
+// not. Client errors are the expected answer to bad input and never deviate, and their bodies
+// are not held to the schema of successful responses.
+func Deviation(baseline, observed Observation) string {
+	switch {
+	case observed.Error != "" && baseline.Error == "":
+		return "request failed: " + observed.Error
+	case observed.Status >= 500 && baseline.Status < 500:
+		return fmt.Sprintf("server error %d %s", observed.Status, http.StatusText(observed.Status))
+	case !observed.SchemaValid && baseline.SchemaValid && observed.Status < 400:
+		return "response violates its JSON schema"
+	}
+	return ""
+}
+
+// Finding is a payload whose response deviated from the baseline
+type Finding struct {
+	Field     string      `json:"field"`
+	Payload   Payload     `json:"payload"`
+	Observed  Observation `json:"observed"`
+	Deviation string      `json:"deviation"`
+}
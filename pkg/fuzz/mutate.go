@@ -0,0 +1,116 @@
+package fuzz
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+)
+
+// Mutate returns a copy of an HTTP step with one field replaced by value. Fields are
+// query.NAME, headers.NAME, path_params.NAME, or json.PATH / body.PATH for a dotted path into
+// a JSON body (list elements by index). The step itself is left unchanged.
+func Mutate(step scenario.Step, field string, value interface{}) (scenario.Step, error) {
+	section, name, found := strings.Cut(field, ".")
+	if !found || name == "" {
+		return step, fmt.Errorf("field %s must be query.NAME, headers.NAME, path_params.NAME, json.PATH or body.PATH", field)
+	}
+
+	text := fmt.Sprint(value)
+	if step.HTTP != nil {
+		http := *step.HTTP
+		step.HTTP = &http
+		switch section {
+		case "query":
+			http.Query = withParam(http.Query, name, text)
+		case "headers":
+			http.Headers = withParam(http.Headers, name, text)
+		case "path_params":
+			http.PathParams = withParam(http.PathParams, name, text)
+		case "json", "body":
+			body := &http.Body
+			if http.JSON != nil {
+				body = &http.JSON
+			}
+			mutated, err := withPath(*body, strings.Split(name, "."), value)
+			if err != nil {
+				return step, fmt.Errorf("field %s: %w", field, err)
+			}
+			*body = mutated
+		default:
+			return step, fmt.Errorf("field %s: unknown section %s", field, section)
+		}
+		return step, nil
+	}
+
+	if step.Type != "http" {
+		return step, fmt.Errorf("step %s is not an HTTP step", step.Name)
+	}
+	switch section {
+	case "query":
+		step.Request.Query = withParam(step.Request.Query, name, text)
+	case "headers":
+		step.Request.Headers = withParam(step.Request.Headers, name, text)
+	case "path_params":
+		step.Request.PathParams = withParam(step.Request.PathParams, name, text)
+	case "json", "body":
+		mutated, err := withPath(step.Request.Body, strings.Split(name, "."), value)
+		if err != nil {
+			return step, fmt.Errorf("field %s: %w", field, err)
+		}
+		step.Request.Body = mutated
+	default:
+		return step, fmt.Errorf("field %s: unknown section %s", field, section)
+	}
+	return step, nil
+}
+
+// withParam copies params with one value replaced
+func withParam(params map[string]string, name, value string) map[string]string {
+	copied := make(map[string]string, len(params)+1)
+	for key, existing := range params {
+		copied[key] = existing
+	}
+	copied[name] = value
+	return copied
+}
+
+// withPath copies the maps and lists along path and sets the value at its end; missing map keys
+// are added so fuzzing can also send unexpected fields
+func withPath(node interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+	switch typed := node.(type) {
+	case nil:
+		child, err := withPath(nil, path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{path[0]: child}, nil
+	case map[string]interface{}:
+		copied := make(map[string]interface{}, len(typed)+1)
+		for key, existing := range typed {
+			copied[key] = existing
+		}
+		child, err := withPath(typed[path[0]], path[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		copied[path[0]] = child
+		return copied, nil
+	case []interface{}:
+		index, err := strconv.Atoi(path[0])
+		if err != nil || index < 0 || index >= len(typed) {
+			return nil, fmt.Errorf("%s is not an index of a %d element list", path[0], len(typed))
+		}
+		copied := append([]interface{}(nil), typed...)
+		if copied[index], err = withPath(typed[index], path[1:], value); err != nil {
+			return nil, err
+		}
+		return copied, nil
+	default:
+		return nil, fmt.Errorf("%s is inside a %T, not a JSON object or list", path[0], node)
+	}
+}
@@ -0,0 +1,77 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+)
+
+// edgeStyles are the Graphviz attributes of each edge kind
+var edgeStyles = map[string]string{
+	EdgeFlow:      `color="#444444"`,
+	EdgeBranch:    `style=dashed, color="#b35900", fontcolor="#b35900"`,
+	EdgeDependsOn: `style=bold, color="#7a3db8", fontcolor="#7a3db8", constraint=false`,
+	EdgeCapture:   `style=dotted, color="#1f6fb2", fontcolor="#1f6fb2", constraint=false`,
+}
+
+// DOT renders the graph in the Graphviz DOT language
+func (g *Graph) DOT() string {
+	var out strings.Builder
+	fmt.Fprintf(&out, "digraph %s {\n", quote(g.Name))
+	fmt.Fprintf(&out, "  label=%s;\n  labelloc=t;\n  rankdir=TB;\n", quote(g.Name))
+	out.WriteString("  node [shape=box, style=\"rounded,filled\", fillcolor=\"#ffffff\", fontname=\"Helvetica\", fontsize=11];\n")
+	out.WriteString("  edge [fontname=\"Helvetica\", fontsize=9];\n")
+
+	for i, group := range g.Groups {
+		label := group.Name
+		if group.Parallel {
+			label += " (parallel)"
+		}
+		fmt.Fprintf(&out, "  subgraph cluster_%d {\n    label=%s;\n    style=rounded;\n    color=\"#bbbbbb\";\n", i, quote(label))
+		for n := group.First; n <= group.Last; n++ {
+			fmt.Fprintf(&out, "    %s\n", g.nodeDOT(n))
+		}
+		out.WriteString("  }\n")
+	}
+
+	for _, edge := range g.Edges {
+		attributes := edgeStyles[edge.Kind]
+		if edge.Label != "" {
+			attributes += ", label=" + quote(edge.Label)
+		}
+		fmt.Fprintf(&out, "  n%d -> n%d [%s];\n", edge.From, edge.To, attributes)
+	}
+	out.WriteString("}\n")
+	return out.String()
+}
+
+func (g *Graph) nodeDOT(index int) string {
+	node := g.Nodes[index]
+	lines := []string{node.Name}
+	if node.Detail != "" {
+		lines = append(lines, node.Detail)
+	}
+	if len(node.Notes) > 0 {
+		lines = append(lines, strings.Join(node.Notes, " · "))
+	}
+	if len(node.Capture) > 0 {
+		lines = append(lines, "captures "+strings.Join(node.Capture, ", "))
+	}
+	escaped := make([]string, len(lines))
+	for i, line := range lines {
+		escaped[i] = escape(line)
+	}
+
+	attributes := ""
+	if node.Branch != "" {
+		attributes = `, fillcolor="#fff4e5"`
+	}
+	return fmt.Sprintf("n%d [label=\"%s\"%s];", index, strings.Join(escaped, `\n`), attributes)
+}
+
+func quote(s string) string {
+	return `"` + escape(s) + `"`
+}
+
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,280 @@
+// Package graph turns a scenario into a diagram of its steps: the order they run in, the groups
+// they belong to, on_failure/on_success/cleanup branches, depends_on edges and the variables
+// captured by one step and used by a later one. It renders as Graphviz DOT or as a standalone
+// SVG that needs no Graphviz.
+package graph
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"gopkg.in/yaml.v3"
+)
+
+// Edge kinds
+const (
+	EdgeFlow      = "flow"       // the next step to run
+	EdgeBranch    = "branch"     // on_failure, on_success or cleanup of a step
+	EdgeDependsOn = "depends_on" // a step declares it needs another
+	EdgeCapture   = "capture"    // a step uses a variable another step captured
+)
+
+// Graph is the steps of one scenario and the edges between them
+type Graph struct {
+	Name   string
+	Nodes  []Node
+	Groups []Group
+	Edges  []Edge
+}
+
+// Node is a step; nodes are in the order the engine runs them, branches right after their step
+type Node struct {
+	Name    string
+	Detail  string // request line or step type
+	Notes   []string
+	Branch  string // on_failure, on_success or cleanup; empty for steps of a group
+	Depth   int    // nesting of branches
+	Capture []string
+}
+
+// Group is a phase of the scenario (setup, steps, a test group, ...) spanning nodes First to Last
+type Group struct {
+	Name        string
+	First, Last int
+	Parallel    bool // runs alongside the other parallel groups
+}
+
+// Edge connects two nodes by index
+type Edge struct {
+	From, To int
+	Kind     string
+	Label    string
+}
+
+// Build lays out the steps of a scenario
+func Build(sc *scenario.Scenario) *Graph {
+	g := &Graph{Name: sc.Name}
+	b := &builder{graph: g}
+
+	var parallel []Group
+	addGroup := func(name string, steps []scenario.Step, concurrent bool) {
+		if len(steps) == 0 {
+			return
+		}
+		group := Group{Name: name, First: len(g.Nodes), Parallel: concurrent}
+		for i := range steps {
+			b.addStep(&steps[i], "", 0, -1)
+		}
+		group.Last = len(g.Nodes) - 1
+		g.Groups = append(g.Groups, group)
+		if concurrent {
+			parallel = append(parallel, group)
+			return
+		}
+		b.chain(group, parallel)
+		parallel = nil
+	}
+
+	if sc.Before != nil {
+		addGroup("before", sc.Before.Steps, false)
+	}
+	addGroup("setup", sc.Setup, false)
+	addGroup("steps", sc.Steps, false)
+	names := make([]string, 0, len(sc.Tests))
+	for name := range sc.Tests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	concurrent := sc.Config != nil && sc.Config.Parallel && len(names) > 1
+	for _, name := range names {
+		if group := sc.Tests[name]; group != nil && !group.Skip {
+			addGroup("test: "+name, group.Steps, concurrent)
+		}
+	}
+	addGroup("teardown", sc.Teardown, false)
+	if sc.After != nil {
+		addGroup("after", sc.After.Steps, false)
+	}
+	b.chain(Group{First: -1}, parallel)
+
+	b.dependencies()
+	b.captures()
+	return g
+}
+
+type builder struct {
+	graph *Graph
+	steps []*scenario.Step
+	// previous holds the last nodes of the flow so far, more than one after parallel groups
+	previous []int
+}
+
+func (b *builder) addStep(step *scenario.Step, branch string, depth, parent int) {
+	g := b.graph
+	index := len(g.Nodes)
+	g.Nodes = append(g.Nodes, Node{Name: step.Name, Detail: detail(step), Notes: notes(step), Branch: branch, Depth: depth, Capture: sortedKeys(step.Capture)})
+	b.steps = append(b.steps, step)
+	if parent >= 0 {
+		g.Edges = append(g.Edges, Edge{From: parent, To: index, Kind: EdgeBranch, Label: strings.ReplaceAll(branch, "_", " ")})
+	}
+
+	for i := range step.OnFailure {
+		b.addStep(&step.OnFailure[i], "on_failure", depth+1, index)
+	}
+	for i := range step.OnSuccess {
+		b.addStep(&step.OnSuccess[i], "on_success", depth+1, index)
+	}
+	if step.Cleanup != nil {
+		b.addStep(step.Cleanup, "cleanup", depth+1, index)
+	}
+}
+
+// chain adds flow edges through the steps of group, after the parallel groups before it; a
+// group with First -1 only closes the parallel groups
+func (b *builder) chain(group Group, parallel []Group) {
+	if len(parallel) > 0 {
+		var lasts []int
+		for _, lane := range parallel {
+			lasts = append(lasts, b.link(b.previous, lane)...)
+		}
+		b.previous = lasts
+	}
+	if group.First >= 0 {
+		b.previous = b.link(b.previous, group)
+	}
+}
+
+// link chains the main steps of group after the nodes in from and returns its last step
+func (b *builder) link(from []int, group Group) []int {
+	g := b.graph
+	for i := group.First; i <= group.Last; i++ {
+		if g.Nodes[i].Branch != "" {
+			continue
+		}
+		for _, previous := range from {
+			g.Edges = append(g.Edges, Edge{From: previous, To: i, Kind: EdgeFlow})
+		}
+		from = []int{i}
+	}
+	return from
+}
+
+func (b *builder) dependencies() {
+	g := b.graph
+	byName := make(map[string]int)
+	for i := len(g.Nodes) - 1; i >= 0; i-- {
+		if g.Nodes[i].Branch == "" {
+			byName[g.Nodes[i].Name] = i
+		}
+	}
+	for i, step := range b.steps {
+		for _, name := range step.DependsOn {
+			if from, exists := byName[name]; exists {
+				g.Edges = append(g.Edges, Edge{From: from, To: i, Kind: EdgeDependsOn, Label: "depends on"})
+			}
+		}
+	}
+}
+
+// captures adds an edge from the step that last captured a variable before a step to the step
+// when it refers to the variable
+func (b *builder) captures() {
+	g := b.graph
+	for i, step := range b.steps {
+		text := referenceText(step)
+		if text == "" {
+			continue
+		}
+		used := make(map[int][]string)
+		for name, pattern := range b.capturedBefore(i) {
+			if pattern.re.MatchString(text) {
+				used[pattern.node] = append(used[pattern.node], name)
+			}
+		}
+		producers := make([]int, 0, len(used))
+		for producer := range used {
+			producers = append(producers, producer)
+		}
+		sort.Ints(producers)
+		for _, producer := range producers {
+			sort.Strings(used[producer])
+			g.Edges = append(g.Edges, Edge{From: producer, To: i, Kind: EdgeCapture, Label: strings.Join(used[producer], ", ")})
+		}
+	}
+}
+
+type capturedVariable struct {
+	node int
+	re   *regexp.Regexp
+}
+
+func (b *builder) capturedBefore(index int) map[string]capturedVariable {
+	captured := make(map[string]capturedVariable)
+	for i := 0; i < index; i++ {
+		for _, name := range b.graph.Nodes[i].Capture {
+			captured[name] = capturedVariable{node: i, re: regexp.MustCompile(`\{\{-?\s*(captures\.)?` + regexp.QuoteMeta(name) + `([^A-Za-z0-9_]|$)`)}
+		}
+	}
+	return captured
+}
+
+// referenceText is the step without its branches and captures, where variables are referenced
+func referenceText(step *scenario.Step) string {
+	own := *step
+	own.OnFailure, own.OnSuccess, own.Cleanup, own.Capture = nil, nil, nil, nil
+	data, err := yaml.Marshal(own)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+func detail(step *scenario.Step) string {
+	switch {
+	case step.HTTP != nil:
+		return requestLine(step.HTTP.Method, step.HTTP.URL)
+	case step.Type == "http":
+		return requestLine(step.Request.Method, step.Request.URL)
+	case step.Type != "":
+		return step.Type
+	case len(step.Variables) > 0:
+		return "set " + strings.Join(sortedKeys(step.Variables), ", ")
+	}
+	return ""
+}
+
+func requestLine(method, url string) string {
+	if method == "" {
+		method = "GET"
+	}
+	return strings.ToUpper(method) + " " + url
+}
+
+func notes(step *scenario.Step) []string {
+	var notes []string
+	if step.Condition != "" {
+		notes = append(notes, "if "+step.Condition)
+	}
+	if step.DataDriven != nil {
+		notes = append(notes, "data-driven")
+	}
+	if step.Loop != nil {
+		notes = append(notes, "loop")
+	}
+	if step.Retry != nil {
+		notes = append(notes, fmt.Sprintf("retry %d", step.Retry.Count))
+	}
+	return notes
+}
+
+func sortedKeys[V any](values map[string]V) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
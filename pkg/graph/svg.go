@@ -0,0 +1,186 @@
+package graph
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+)
+
+// SVG layout, in pixels: steps are stacked in run order with branches indented under their step;
+// flow edges run down the left of the boxes, depends_on edges arc on the left and captured
+// variables arc on the right
+const (
+	svgLeft       = 190
+	svgBoxWidth   = 300
+	svgIndent     = 32
+	svgLineHeight = 15
+	svgGap        = 22
+	svgGroupHead  = 26
+	svgLabelRoom  = 200
+	svgMaxChars   = 46
+)
+
+var edgeColors = map[string]string{
+	EdgeFlow:      "#444444",
+	EdgeBranch:    "#b35900",
+	EdgeDependsOn: "#7a3db8",
+	EdgeCapture:   "#1f6fb2",
+}
+
+type box struct {
+	x, y, height int
+	lines        []string
+}
+
+// SVG renders the graph as a standalone SVG document
+func (g *Graph) SVG() string {
+	boxes, height := g.layout()
+	maxDepth := 0
+	for _, node := range g.Nodes {
+		if node.Depth > maxDepth {
+			maxDepth = node.Depth
+		}
+	}
+	width := svgLeft + svgBoxWidth + maxDepth*svgIndent + svgLabelRoom
+
+	var out strings.Builder
+	fmt.Fprintf(&out, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="Helvetica, Arial, sans-serif">`+"\n", width, height, width, height)
+	out.WriteString("<defs>\n")
+	for _, kind := range []string{EdgeFlow, EdgeBranch, EdgeDependsOn, EdgeCapture} {
+		fmt.Fprintf(&out, `<marker id="arrow-%s" viewBox="0 0 10 10" refX="9" refY="5" markerWidth="7" markerHeight="7" orient="auto-start-reverse"><path d="M0,0 L10,5 L0,10 z" fill="%s"/></marker>`+"\n", kind, edgeColors[kind])
+	}
+	out.WriteString("</defs>\n")
+	fmt.Fprintf(&out, `<rect width="100%%" height="100%%" fill="#ffffff"/>`+"\n")
+	fmt.Fprintf(&out, `<text x="%d" y="26" font-size="16" font-weight="bold">%s</text>`+"\n", svgLeft-14, html.EscapeString(g.Name))
+
+	for _, group := range g.Groups {
+		top := boxes[group.First].y - svgGroupHead + 4
+		bottom := boxes[group.Last].y + boxes[group.Last].height + 10
+		label := group.Name
+		if group.Parallel {
+			label += " (parallel)"
+		}
+		fmt.Fprintf(&out, `<rect x="%d" y="%d" width="%d" height="%d" rx="8" fill="#f6f6f6" stroke="#bbbbbb"/>`+"\n", svgLeft-14, top, svgBoxWidth+maxDepth*svgIndent+28, bottom-top)
+		fmt.Fprintf(&out, `<text x="%d" y="%d" font-size="11" fill="#666666">%s</text>`+"\n", svgLeft-6, top+15, html.EscapeString(label))
+	}
+
+	leftArcs, rightArcs := 0, 0
+	for _, edge := range g.Edges {
+		from, to := boxes[edge.From], boxes[edge.To]
+		color := edgeColors[edge.Kind]
+		marker := fmt.Sprintf(`marker-end="url(#arrow-%s)"`, edge.Kind)
+		switch {
+		case edge.Kind == EdgeBranch:
+			x := from.x + 22
+			fmt.Fprintf(&out, `<path d="M%d,%d V%d H%d" fill="none" stroke="%s" stroke-dasharray="5,3" %s/>`+"\n", x, from.y+from.height, to.y+to.height/2, to.x, color, marker)
+		case edge.Kind == EdgeFlow && g.adjacent(edge.From, edge.To):
+			x := from.x + 14
+			fmt.Fprintf(&out, `<path d="M%d,%d V%d" fill="none" stroke="%s" %s/>`+"\n", x, from.y+from.height, to.y, color, marker)
+		case edge.Kind == EdgeCapture:
+			offset := 26 + 14*(rightArcs%6)
+			rightArcs++
+			x1, x2 := from.x+svgBoxWidth, to.x+svgBoxWidth
+			y1, y2 := from.y+from.height/2, to.y+to.height/2
+			peak := max(x1, x2) + offset
+			fmt.Fprintf(&out, `<path d="M%d,%d C%d,%d %d,%d %d,%d" fill="none" stroke="%s" stroke-dasharray="2,3" %s/>`+"\n", x1, y1, peak, y1, peak, y2, x2, y2, color, marker)
+			fmt.Fprintf(&out, `<text x="%d" y="%d" font-size="10" fill="%s">%s</text>`+"\n", peak-offset/4+4, (y1+y2)/2+3, color, html.EscapeString(edge.Label))
+		default:
+			offset := 26 + 14*(leftArcs%6)
+			leftArcs++
+			x1, x2 := from.x, to.x
+			y1, y2 := from.y+from.height/2, to.y+to.height/2
+			peak := min(x1, x2) - offset
+			width := ""
+			if edge.Kind == EdgeDependsOn {
+				width = ` stroke-width="2"`
+			}
+			fmt.Fprintf(&out, `<path d="M%d,%d C%d,%d %d,%d %d,%d" fill="none" stroke="%s"%s %s/>`+"\n", x1, y1, peak, y1, peak, y2, x2, y2, color, width, marker)
+			if edge.Label != "" {
+				fmt.Fprintf(&out, `<text x="%d" y="%d" font-size="10" fill="%s" text-anchor="end">%s</text>`+"\n", peak+offset/4-4, (y1+y2)/2+3, color, html.EscapeString(edge.Label))
+			}
+		}
+	}
+
+	for i, node := range g.Nodes {
+		b := boxes[i]
+		fill := "#ffffff"
+		if node.Branch != "" {
+			fill = "#fff4e5"
+		}
+		fmt.Fprintf(&out, `<rect x="%d" y="%d" width="%d" height="%d" rx="6" fill="%s" stroke="#555555"/>`+"\n", b.x, b.y, svgBoxWidth, b.height, fill)
+		nameLine := 0
+		if node.Branch != "" {
+			nameLine = 1
+		}
+		for l, line := range b.lines {
+			style := `font-size="11" fill="#333333"`
+			switch {
+			case l < nameLine:
+				style = `font-size="10" font-style="italic" fill="#b35900"`
+			case l == nameLine:
+				style = `font-size="12" font-weight="bold" fill="#111111"`
+			}
+			fmt.Fprintf(&out, `<text x="%d" y="%d" %s>%s</text>`+"\n", b.x+10, b.y+18+l*svgLineHeight, style, html.EscapeString(line))
+		}
+	}
+
+	out.WriteString("</svg>\n")
+	return out.String()
+}
+
+// layout places the nodes top to bottom and returns their boxes and the height of the drawing
+func (g *Graph) layout() ([]box, int) {
+	firsts, lasts := make(map[int]bool), make(map[int]bool)
+	for _, group := range g.Groups {
+		firsts[group.First], lasts[group.Last] = true, true
+	}
+
+	boxes := make([]box, len(g.Nodes))
+	y := 44
+	for i, node := range g.Nodes {
+		if firsts[i] {
+			y += svgGroupHead
+		}
+		var lines []string
+		if node.Branch != "" {
+			lines = append(lines, strings.ReplaceAll(node.Branch, "_", " "))
+		}
+		lines = append(lines, truncate(node.Name))
+		if node.Detail != "" {
+			lines = append(lines, truncate(node.Detail))
+		}
+		if len(node.Notes) > 0 {
+			lines = append(lines, truncate(strings.Join(node.Notes, " · ")))
+		}
+		if len(node.Capture) > 0 {
+			lines = append(lines, truncate("captures "+strings.Join(node.Capture, ", ")))
+		}
+		boxes[i] = box{x: svgLeft + node.Depth*svgIndent, y: y, height: 10 + len(lines)*svgLineHeight, lines: lines}
+		y += boxes[i].height + svgGap
+		if lasts[i] {
+			y += 10
+		}
+	}
+	return boxes, y + 10
+}
+
+// adjacent reports whether no step of a group lies between two steps, so a straight line joins them
+func (g *Graph) adjacent(from, to int) bool {
+	if from > to || g.Nodes[from].Depth != 0 || g.Nodes[to].Depth != 0 {
+		return false
+	}
+	for i := from + 1; i < to; i++ {
+		if g.Nodes[i].Depth == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func truncate(s string) string {
+	if utf8.RuneCountInString(s) <= svgMaxChars {
+		return s
+	}
+	return string([]rune(s)[:svgMaxChars-1]) + "…"
+}
@@ -0,0 +1,221 @@
+package history
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/reporting"
+)
+
+// DefaultPath is the results store used when none is configured
+const DefaultPath = ".fuego/history.jsonl"
+
+// Record is the persisted outcome of one run
+type Record struct {
+	ID          string           `json:"id"`
+	Time        time.Time        `json:"time"`
+	Environment string           `json:"environment,omitempty"`
+	Duration    time.Duration    `json:"duration"`
+	PassRate    float64          `json:"pass_rate"`
+	Scenarios   []ScenarioRecord `json:"scenarios"`
+}
+
+// ScenarioRecord is the outcome of one scenario within a run
+type ScenarioRecord struct {
+	Name     string        `json:"name"`
+	Status   string        `json:"status"`
+	Duration time.Duration `json:"duration"`
+	Steps    []StepRecord  `json:"steps,omitempty"`
+}
+
+// StepRecord holds the average latency of one logical step within a run
+type StepRecord struct {
+	Name    string        `json:"name"`
+	Status  string        `json:"status"`
+	Latency time.Duration `json:"latency"`
+}
+
+// Store persists run records
+type Store interface {
+	Append(record Record) error
+	Load() ([]Record, error)
+}
+
+// Open returns an HTTP store for http(s) URLs and a JSON lines file store otherwise
+func Open(location string) Store {
+	if location == "" {
+		location = DefaultPath
+	}
+	if strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://") {
+		return &HTTPStore{URL: location, Client: &http.Client{Timeout: 30 * time.Second}}
+	}
+	return &FileStore{Path: location}
+}
+
+// NewRecord summarizes a report into a history record
+func NewRecord(report *reporting.Report, environment string) Record {
+	record := Record{
+		ID:          report.StartTime.UTC().Format("20060102T150405.000Z"),
+		Time:        report.StartTime,
+		Environment: environment,
+		Duration:    report.Duration,
+		PassRate:    report.Summary.PassRate,
+	}
+
+	for _, result := range report.Scenarios {
+		scenarioRecord := ScenarioRecord{
+			Status:   result.Status,
+			Duration: result.Duration,
+		}
+		if result.Scenario != nil {
+			scenarioRecord.Name = result.Scenario.Name
+		}
+
+		var order []string
+		latencies := make(map[string][]time.Duration)
+		statuses := make(map[string]string)
+		for _, step := range result.Steps {
+			if step.Step == nil || step.Status == "skipped" {
+				continue
+			}
+			name := step.LogicalName()
+			if _, seen := latencies[name]; !seen {
+				order = append(order, name)
+				statuses[name] = step.Status
+			}
+			latencies[name] = append(latencies[name], step.Latency())
+			if step.Status == "failed" {
+				statuses[name] = "failed"
+			}
+		}
+		for _, name := range order {
+			stats := reporting.NewLatencyStats(name, latencies[name])
+			scenarioRecord.Steps = append(scenarioRecord.Steps, StepRecord{Name: name, Status: statuses[name], Latency: stats.Avg})
+		}
+
+		record.Scenarios = append(record.Scenarios, scenarioRecord)
+	}
+
+	return record
+}
+
+// FileStore keeps one JSON record per line in a local file
+type FileStore struct {
+	Path string
+}
+
+func (s *FileStore) Append(record Record) error {
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0755); err != nil {
+		return fmt.Errorf("failed to create history directory: %w", err)
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history record: %w", err)
+	}
+
+	file, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history file %s: %w", s.Path, err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write history file %s: %w", s.Path, err)
+	}
+	return nil
+}
+
+func (s *FileStore) Load() ([]Record, error) {
+	file, err := os.Open(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history file %s: %w", s.Path, err)
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, fmt.Errorf("failed to parse history file %s line %d: %w", s.Path, lineNumber, err)
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history file %s: %w", s.Path, err)
+	}
+
+	return sortByTime(records), nil
+}
+
+// HTTPStore posts each record to an endpoint and reads the history back as a JSON array from it
+type HTTPStore struct {
+	URL    string
+	Client *http.Client
+}
+
+func (s *HTTPStore) Append(record Record) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal history record: %w", err)
+	}
+
+	resp, err := s.Client.Post(s.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to send history record: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("history endpoint returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (s *HTTPStore) Load() ([]Record, error) {
+	resp, err := s.Client.Get(s.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch history: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("history endpoint returned %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	var records []Record
+	if err := json.Unmarshal(body, &records); err != nil {
+		return nil, fmt.Errorf("failed to parse history: %w", err)
+	}
+	return sortByTime(records), nil
+}
+
+func sortByTime(records []Record) []Record {
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+	return records
+}
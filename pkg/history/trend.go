@@ -0,0 +1,130 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Trend summarizes one scenario across the stored runs
+type Trend struct {
+	Scenario     string        `json:"scenario"`
+	Runs         int           `json:"runs"`
+	PassRate     float64       `json:"pass_rate"`
+	AvgDuration  time.Duration `json:"avg_duration"`
+	LastDuration time.Duration `json:"last_duration"`
+	LastStatus   string        `json:"last_status"`
+	Statuses     []string      `json:"statuses"` // oldest first
+}
+
+// Regression is a scenario or step that got worse compared to the baseline run
+type Regression struct {
+	Scenario string `json:"scenario"`
+	Step     string `json:"step,omitempty"`
+	Kind     string `json:"kind"` // status, latency
+	Message  string `json:"message"`
+}
+
+// Trends aggregates pass rate and duration per scenario, ordered by scenario name
+func Trends(records []Record) []Trend {
+	byScenario := make(map[string]*Trend)
+	totals := make(map[string]time.Duration)
+	passed := make(map[string]int)
+
+	for _, record := range records {
+		for _, sc := range record.Scenarios {
+			trend, exists := byScenario[sc.Name]
+			if !exists {
+				trend = &Trend{Scenario: sc.Name}
+				byScenario[sc.Name] = trend
+			}
+
+			trend.Runs++
+			trend.LastDuration = sc.Duration
+			trend.LastStatus = sc.Status
+			trend.Statuses = append(trend.Statuses, sc.Status)
+			totals[sc.Name] += sc.Duration
+			if sc.Status != "failed" {
+				passed[sc.Name]++
+			}
+		}
+	}
+
+	trends := make([]Trend, 0, len(byScenario))
+	for name, trend := range byScenario {
+		trend.AvgDuration = totals[name] / time.Duration(trend.Runs)
+		trend.PassRate = float64(passed[name]) / float64(trend.Runs) * 100
+		trends = append(trends, *trend)
+	}
+	sort.Slice(trends, func(i, j int) bool { return trends[i].Scenario < trends[j].Scenario })
+
+	return trends
+}
+
+// Compare reports scenarios and steps that started failing, and steps whose average latency grew
+// by more than threshold (0.2 = 20%) compared to the baseline run
+func Compare(baseline, current Record, threshold float64) []Regression {
+	baselineScenarios := make(map[string]ScenarioRecord, len(baseline.Scenarios))
+	for _, sc := range baseline.Scenarios {
+		baselineScenarios[sc.Name] = sc
+	}
+
+	var regressions []Regression
+	for _, sc := range current.Scenarios {
+		before, exists := baselineScenarios[sc.Name]
+		if !exists {
+			continue
+		}
+
+		if sc.Status == "failed" && before.Status != "failed" {
+			regressions = append(regressions, Regression{
+				Scenario: sc.Name,
+				Kind:     "status",
+				Message:  fmt.Sprintf("%s in baseline, failed now", before.Status),
+			})
+		}
+
+		baselineSteps := make(map[string]StepRecord, len(before.Steps))
+		for _, step := range before.Steps {
+			baselineSteps[step.Name] = step
+		}
+
+		for _, step := range sc.Steps {
+			beforeStep, exists := baselineSteps[step.Name]
+			if !exists {
+				continue
+			}
+
+			if step.Status == "failed" && beforeStep.Status != "failed" {
+				regressions = append(regressions, Regression{
+					Scenario: sc.Name,
+					Step:     step.Name,
+					Kind:     "status",
+					Message:  fmt.Sprintf("%s in baseline, failed now", beforeStep.Status),
+				})
+			}
+
+			if beforeStep.Latency > 0 && float64(step.Latency) > float64(beforeStep.Latency)*(1+threshold) {
+				regressions = append(regressions, Regression{
+					Scenario: sc.Name,
+					Step:     step.Name,
+					Kind:     "latency",
+					Message: fmt.Sprintf("latency %v vs %v in baseline (+%.0f%%)", step.Latency, beforeStep.Latency,
+						(float64(step.Latency)/float64(beforeStep.Latency)-1)*100),
+				})
+			}
+		}
+	}
+
+	return regressions
+}
+
+// Find returns the record with the given ID
+func Find(records []Record, id string) (Record, bool) {
+	for _, record := range records {
+		if record.ID == id {
+			return record, true
+		}
+	}
+	return Record{}, false
+}
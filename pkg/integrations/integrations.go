@@ -0,0 +1,179 @@
+// Package integrations pushes run results to test-management systems (TestRail, Xray, Zephyr
+// Scale). Scenarios are mapped to test cases through a metadata label, e.g.
+//
+//	metadata:
+//	  labels:
+//	    testrail_id: C1234, C1235
+package integrations
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+)
+
+// CaseResult is the outcome of one test case, merged over every scenario run mapped to it
+type CaseResult struct {
+	ID        string
+	Passed    bool
+	Comment   string
+	Duration  time.Duration
+	StartTime time.Time
+	EndTime   time.Time
+}
+
+// Sinks returns a reporting sink for every configured integration
+func Sinks(cfg config.IntegrationsConfig) []reporting.Sink {
+	client := &http.Client{Timeout: 30 * time.Second}
+
+	var sinks []reporting.Sink
+	if cfg.TestRail != nil {
+		sinks = append(sinks, &TestRail{Config: *cfg.TestRail, Client: client})
+	}
+	if cfg.Xray != nil {
+		sinks = append(sinks, &Xray{Config: *cfg.Xray, Client: client})
+	}
+	if cfg.Zephyr != nil {
+		sinks = append(sinks, &Zephyr{Config: *cfg.Zephyr, Client: client})
+	}
+	return sinks
+}
+
+// CollectResults maps the scenarios of a report to test cases through a metadata label holding
+// one or more comma-separated case IDs. A case fails if any scenario run mapped to it failed;
+// skipped scenarios are not reported.
+func CollectResults(report *reporting.Report, label string) []CaseResult {
+	byID := make(map[string]*CaseResult)
+	for _, scenario := range report.Scenarios {
+		if scenario.Status == "skipped" || scenario.Scenario == nil {
+			continue
+		}
+
+		for _, id := range strings.Split(scenario.Scenario.Metadata.Labels[label], ",") {
+			id = strings.TrimSpace(id)
+			if id == "" {
+				continue
+			}
+
+			result, exists := byID[id]
+			if !exists {
+				result = &CaseResult{ID: id, Passed: true, StartTime: scenario.StartTime}
+				byID[id] = result
+			}
+			result.Duration += scenario.Duration
+			if scenario.EndTime.After(result.EndTime) {
+				result.EndTime = scenario.EndTime
+			}
+			if scenario.Status == "failed" {
+				result.Passed = false
+				result.Comment = joinLines(result.Comment, scenarioFailure(scenario))
+			}
+		}
+	}
+
+	results := make([]CaseResult, 0, len(byID))
+	for _, result := range byID {
+		if result.Passed {
+			result.Comment = "Passed in fuego"
+		}
+		results = append(results, *result)
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].ID < results[j].ID })
+	return results
+}
+
+func scenarioFailure(scenario reporting.ScenarioResult) string {
+	lines := []string{}
+	if scenario.Error != "" {
+		lines = append(lines, scenario.Scenario.Name+": "+scenario.Error)
+	}
+	for _, step := range scenario.Steps {
+		if step.Status != "failed" {
+			continue
+		}
+		reason := step.Error
+		for _, assertion := range step.Assertions {
+			if !assertion.Passed {
+				reason = joinLines(reason, assertion.Message)
+			}
+		}
+		if reason == "" {
+			reason = "failed"
+		}
+		lines = append(lines, fmt.Sprintf("%s / %s: %s", scenario.Scenario.Name, step.Step.Name, reason))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, scenario.Scenario.Name+": failed")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func joinLines(existing, more string) string {
+	if existing == "" {
+		return more
+	}
+	return existing + "\n" + more
+}
+
+func labelOrDefault(label, fallback string) string {
+	if label != "" {
+		return label
+	}
+	return fallback
+}
+
+// doJSON sends payload as JSON and decodes a JSON response into out when it is not nil
+func doJSON(client *http.Client, req *http.Request, payload, out interface{}) error {
+	if payload != nil {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request: %w", err)
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s %s returned %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(body)))
+	}
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+	return nil
+}
+
+func newRequest(method, url string) (*http.Request, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL: %w", err)
+	}
+	return req, nil
+}
+
+// secret expands ${VAR} references so credentials can stay in the environment
+func secret(value string) string {
+	return os.ExpandEnv(value)
+}
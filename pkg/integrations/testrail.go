@@ -0,0 +1,120 @@
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+)
+
+// TestRail statuses used for results
+const (
+	testRailPassed = 1
+	testRailFailed = 5
+)
+
+// TestRail adds the results of a run to a TestRail test run, creating the run when no run_id is
+// configured
+type TestRail struct {
+	Config config.TestRailConfig
+	Client *http.Client
+}
+
+type testRailResult struct {
+	CaseID   int    `json:"case_id"`
+	StatusID int    `json:"status_id"`
+	Comment  string `json:"comment,omitempty"`
+	Elapsed  string `json:"elapsed,omitempty"`
+}
+
+func (t *TestRail) ScenarioCompleted(reporting.ScenarioResult) error {
+	return nil
+}
+
+func (t *TestRail) RunCompleted(report *reporting.Report) error {
+	cases := CollectResults(report, labelOrDefault(t.Config.Label, "testrail_id"))
+	if len(cases) == 0 {
+		return nil
+	}
+
+	results := make([]testRailResult, 0, len(cases))
+	caseIDs := make([]int, 0, len(cases))
+	for _, c := range cases {
+		id, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(c.ID), "C"))
+		if err != nil {
+			return fmt.Errorf("testrail: invalid case ID %q", c.ID)
+		}
+		status := testRailPassed
+		if !c.Passed {
+			status = testRailFailed
+		}
+		results = append(results, testRailResult{CaseID: id, StatusID: status, Comment: c.Comment, Elapsed: testRailElapsed(c.Duration)})
+		caseIDs = append(caseIDs, id)
+	}
+
+	runID := t.Config.RunID
+	if runID == 0 {
+		created, err := t.addRun(caseIDs, report.StartTime)
+		if err != nil {
+			return err
+		}
+		runID = created
+	}
+
+	req, err := t.request(fmt.Sprintf("add_results_for_cases/%d", runID))
+	if err != nil {
+		return err
+	}
+	if err := doJSON(t.Client, req, map[string]interface{}{"results": results}, nil); err != nil {
+		return fmt.Errorf("testrail: failed to add results: %w", err)
+	}
+	return nil
+}
+
+func (t *TestRail) addRun(caseIDs []int, started time.Time) (int, error) {
+	name := t.Config.RunName
+	if name == "" {
+		name = "fuego " + started.Format("2006-01-02 15:04")
+	}
+	payload := map[string]interface{}{"name": name, "include_all": false, "case_ids": caseIDs}
+	if t.Config.SuiteID != 0 {
+		payload["suite_id"] = t.Config.SuiteID
+	}
+
+	req, err := t.request(fmt.Sprintf("add_run/%d", t.Config.ProjectID))
+	if err != nil {
+		return 0, err
+	}
+	var run struct {
+		ID int `json:"id"`
+	}
+	if err := doJSON(t.Client, req, payload, &run); err != nil {
+		return 0, fmt.Errorf("testrail: failed to create run: %w", err)
+	}
+	return run.ID, nil
+}
+
+func (t *TestRail) request(endpoint string) (*http.Request, error) {
+	req, err := newRequest(http.MethodPost, strings.TrimSuffix(t.Config.URL, "/")+"/index.php?/api/v2/"+endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("testrail: %w", err)
+	}
+	req.SetBasicAuth(secret(t.Config.User), secret(t.Config.APIKey))
+	return req, nil
+}
+
+// testRailElapsed formats a duration as TestRail timespan ("1m 5s"); TestRail rejects 0s
+func testRailElapsed(d time.Duration) string {
+	seconds := int(d.Round(time.Second).Seconds())
+	if seconds < 1 {
+		seconds = 1
+	}
+	if seconds < 60 {
+		return fmt.Sprintf("%ds", seconds)
+	}
+	return fmt.Sprintf("%dm %ds", seconds/60, seconds%60)
+}
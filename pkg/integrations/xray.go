@@ -0,0 +1,110 @@
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+)
+
+const defaultXrayURL = "https://xray.cloud.getxray.app"
+
+// Xray imports the results of a run into a Jira test execution through the Xray cloud API
+type Xray struct {
+	Config config.XrayConfig
+	Client *http.Client
+}
+
+type xrayExecution struct {
+	TestExecutionKey string        `json:"testExecutionKey,omitempty"`
+	Info             *xrayInfo     `json:"info,omitempty"`
+	Tests            []xrayTestRun `json:"tests"`
+}
+
+type xrayInfo struct {
+	Project    string `json:"project"`
+	Summary    string `json:"summary"`
+	StartDate  string `json:"startDate"`
+	FinishDate string `json:"finishDate"`
+}
+
+type xrayTestRun struct {
+	TestKey string `json:"testKey"`
+	Start   string `json:"start"`
+	Finish  string `json:"finish"`
+	Status  string `json:"status"` // PASSED or FAILED
+	Comment string `json:"comment,omitempty"`
+}
+
+func (x *Xray) ScenarioCompleted(reporting.ScenarioResult) error {
+	return nil
+}
+
+func (x *Xray) RunCompleted(report *reporting.Report) error {
+	cases := CollectResults(report, labelOrDefault(x.Config.Label, "xray_test"))
+	if len(cases) == 0 {
+		return nil
+	}
+
+	execution := xrayExecution{TestExecutionKey: x.Config.TestExecution}
+	if execution.TestExecutionKey == "" {
+		execution.Info = &xrayInfo{
+			Project:    x.Config.ProjectKey,
+			Summary:    "fuego run " + report.StartTime.Format("2006-01-02 15:04"),
+			StartDate:  report.StartTime.Format(time.RFC3339),
+			FinishDate: report.EndTime.Format(time.RFC3339),
+		}
+	}
+	for _, c := range cases {
+		status := "PASSED"
+		if !c.Passed {
+			status = "FAILED"
+		}
+		execution.Tests = append(execution.Tests, xrayTestRun{
+			TestKey: c.ID,
+			Start:   c.StartTime.Format(time.RFC3339),
+			Finish:  c.EndTime.Format(time.RFC3339),
+			Status:  status,
+			Comment: c.Comment,
+		})
+	}
+
+	token, err := x.authenticate()
+	if err != nil {
+		return err
+	}
+
+	req, err := newRequest(http.MethodPost, x.baseURL()+"/api/v2/import/execution")
+	if err != nil {
+		return fmt.Errorf("xray: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if err := doJSON(x.Client, req, execution, nil); err != nil {
+		return fmt.Errorf("xray: failed to import results: %w", err)
+	}
+	return nil
+}
+
+func (x *Xray) authenticate() (string, error) {
+	req, err := newRequest(http.MethodPost, x.baseURL()+"/api/v2/authenticate")
+	if err != nil {
+		return "", fmt.Errorf("xray: %w", err)
+	}
+	credentials := map[string]string{"client_id": secret(x.Config.ClientID), "client_secret": secret(x.Config.ClientSecret)}
+
+	var token string
+	if err := doJSON(x.Client, req, credentials, &token); err != nil {
+		return "", fmt.Errorf("xray: failed to authenticate: %w", err)
+	}
+	return token, nil
+}
+
+func (x *Xray) baseURL() string {
+	if x.Config.URL == "" {
+		return defaultXrayURL
+	}
+	return strings.TrimSuffix(x.Config.URL, "/")
+}
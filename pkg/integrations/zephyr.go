@@ -0,0 +1,72 @@
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+)
+
+const defaultZephyrURL = "https://api.zephyrscale.smartbear.com/v2"
+
+// Zephyr creates a Zephyr Scale test execution in the configured test cycle for every mapped case
+type Zephyr struct {
+	Config config.ZephyrConfig
+	Client *http.Client
+}
+
+type zephyrExecution struct {
+	ProjectKey    string `json:"projectKey"`
+	TestCaseKey   string `json:"testCaseKey"`
+	TestCycleKey  string `json:"testCycleKey"`
+	StatusName    string `json:"statusName"` // Pass or Fail
+	ExecutionTime int64  `json:"executionTime"`
+	Comment       string `json:"comment,omitempty"`
+}
+
+func (z *Zephyr) ScenarioCompleted(reporting.ScenarioResult) error {
+	return nil
+}
+
+func (z *Zephyr) RunCompleted(report *reporting.Report) error {
+	var failures []string
+	for _, c := range CollectResults(report, labelOrDefault(z.Config.Label, "zephyr_test")) {
+		status := "Pass"
+		if !c.Passed {
+			status = "Fail"
+		}
+
+		req, err := newRequest(http.MethodPost, z.baseURL()+"/testexecutions")
+		if err != nil {
+			return fmt.Errorf("zephyr: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+secret(z.Config.Token))
+
+		execution := zephyrExecution{
+			ProjectKey:    z.Config.ProjectKey,
+			TestCaseKey:   c.ID,
+			TestCycleKey:  z.Config.TestCycle,
+			StatusName:    status,
+			ExecutionTime: c.Duration.Milliseconds(),
+			Comment:       strings.ReplaceAll(c.Comment, "\n", "<br>"),
+		}
+		if err := doJSON(z.Client, req, execution, nil); err != nil {
+			// Keep publishing the other cases; one bad key should not drop the whole run
+			failures = append(failures, fmt.Sprintf("%s: %v", c.ID, err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("zephyr: failed to publish results: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+func (z *Zephyr) baseURL() string {
+	if z.Config.URL == "" {
+		return defaultZephyrURL
+	}
+	return strings.TrimSuffix(z.Config.URL, "/")
+}
@@ -0,0 +1,163 @@
+package k8s
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// MaxBundleSize is what a ConfigMap holds (etcd limits objects to 1 MiB)
+const MaxBundleSize = 1 << 20
+
+// Bundle is a scenario set packaged for a ConfigMap. Directories are packaged with every file
+// they contain, so includes and data files come along, and the layout relative to the common
+// parent of the paths is kept.
+type Bundle struct {
+	// Files maps slash-separated paths in the bundle to their content
+	Files map[string][]byte
+	// Paths are the scenario files and directories to run, as paths in the bundle
+	Paths []string
+	// Config is the fuego config file, when one is packaged
+	Config []byte
+}
+
+// NewBundle packages scenario files and directories and, when configFile is set, the config
+func NewBundle(paths []string, configFile string) (*Bundle, error) {
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("no scenario paths to package")
+	}
+	absolute := make([]string, len(paths))
+	for i, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return nil, err
+		}
+		absolute[i] = abs
+	}
+	root, err := commonParent(absolute)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := &Bundle{Files: make(map[string][]byte)}
+	size := 0
+	add := func(path string) error {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		bundle.Files[filepath.ToSlash(rel)] = content
+		size += len(content)
+		return nil
+	}
+
+	for _, abs := range absolute {
+		info, err := os.Stat(abs)
+		if err != nil {
+			return nil, err
+		}
+		rel, err := filepath.Rel(root, abs)
+		if err != nil {
+			return nil, err
+		}
+		bundle.Paths = append(bundle.Paths, filepath.ToSlash(rel))
+
+		if !info.IsDir() {
+			if err := add(abs); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		err = filepath.WalkDir(abs, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			// Hidden directories are VCS metadata or tool state, never scenarios
+			if entry.IsDir() && path != abs && strings.HasPrefix(entry.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if !entry.Type().IsRegular() {
+				return nil
+			}
+			return add(path)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to package %s: %w", abs, err)
+		}
+	}
+
+	if configFile != "" {
+		content, err := os.ReadFile(configFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config: %w", err)
+		}
+		bundle.Config = content
+		size += len(content)
+	}
+
+	if size > MaxBundleSize {
+		return nil, fmt.Errorf("scenario set is %d KiB, more than the %d KiB a ConfigMap holds", size/1024, MaxBundleSize/1024)
+	}
+	return bundle, nil
+}
+
+// commonParent returns the deepest directory containing every path
+func commonParent(paths []string) (string, error) {
+	var root string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", err
+		}
+		dir := path
+		if !info.IsDir() {
+			dir = filepath.Dir(path)
+		}
+		if root == "" {
+			root = dir
+			continue
+		}
+		for !within(root, dir) {
+			parent := filepath.Dir(root)
+			if parent == root {
+				break
+			}
+			root = parent
+		}
+	}
+	return root, nil
+}
+
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+var invalidKeyChars = regexp.MustCompile(`[^-._a-zA-Z0-9]+`)
+
+// keys returns the bundle paths in order with the ConfigMap key of each; keys only allow
+// [-._a-zA-Z0-9], so they are numbered to stay unique
+func (b *Bundle) keys() ([]string, map[string]string) {
+	paths := make([]string, 0, len(b.Files))
+	for path := range b.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	keys := make(map[string]string, len(paths))
+	for i, path := range paths {
+		key := fmt.Sprintf("%d-%s", i, invalidKeyChars.ReplaceAllString(path, "_"))
+		if len(key) > 253 {
+			key = key[:253]
+		}
+		keys[path] = key
+	}
+	return paths, keys
+}
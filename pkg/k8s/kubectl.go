@@ -0,0 +1,142 @@
+package k8s
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/reporting"
+)
+
+// Kubectl drives a cluster through the kubectl binary
+type Kubectl struct {
+	// Path of the binary (default kubectl from PATH)
+	Path string
+	// Context and Namespace override the current kubeconfig context and its namespace
+	Context   string
+	Namespace string
+}
+
+// JobStatus is the outcome of a finished job
+type JobStatus struct {
+	Succeeded bool
+	// Reason and Message explain a failure, e.g. DeadlineExceeded
+	Reason  string
+	Message string
+}
+
+func (k *Kubectl) run(ctx context.Context, stdin []byte, args ...string) ([]byte, error) {
+	command := args[0]
+	path := k.Path
+	if path == "" {
+		path = "kubectl"
+	}
+	if k.Context != "" {
+		args = append([]string{"--context", k.Context}, args...)
+	}
+	if k.Namespace != "" {
+		args = append([]string{"--namespace", k.Namespace}, args...)
+	}
+
+	cmd := exec.CommandContext(ctx, path, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	if stdin != nil {
+		cmd.Stdin = bytes.NewReader(stdin)
+	}
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("kubectl %s: %s", command, message)
+		}
+		return nil, fmt.Errorf("kubectl %s: %w", command, err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// Apply creates or updates the objects
+func (k *Kubectl) Apply(ctx context.Context, objects []Object) error {
+	list, err := json.Marshal(Object{"apiVersion": "v1", "kind": "List", "items": objects})
+	if err != nil {
+		return fmt.Errorf("failed to encode manifests: %w", err)
+	}
+	_, err = k.run(ctx, list, "apply", "-f", "-")
+	return err
+}
+
+// WaitJob polls a job until it completes or fails
+func (k *Kubectl) WaitJob(ctx context.Context, name string, interval time.Duration) (JobStatus, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		output, err := k.run(ctx, nil, "get", "job", name, "-o", "json")
+		if err != nil {
+			return JobStatus{}, err
+		}
+		var job struct {
+			Status struct {
+				Conditions []struct {
+					Type    string `json:"type"`
+					Status  string `json:"status"`
+					Reason  string `json:"reason"`
+					Message string `json:"message"`
+				} `json:"conditions"`
+			} `json:"status"`
+		}
+		if err := json.Unmarshal(output, &job); err != nil {
+			return JobStatus{}, fmt.Errorf("failed to parse job %s: %w", name, err)
+		}
+		for _, condition := range job.Status.Conditions {
+			if condition.Status != "True" {
+				continue
+			}
+			switch condition.Type {
+			case "Complete":
+				return JobStatus{Succeeded: true}, nil
+			case "Failed":
+				return JobStatus{Reason: condition.Reason, Message: condition.Message}, nil
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return JobStatus{}, ctx.Err()
+		}
+	}
+}
+
+// Logs returns the output of the pod of a job
+func (k *Kubectl) Logs(ctx context.Context, job string) ([]byte, error) {
+	return k.run(ctx, nil, "logs", "job/"+job)
+}
+
+// Delete removes the objects named name of the given kinds, with the pods of jobs
+func (k *Kubectl) Delete(ctx context.Context, name string, kinds ...string) error {
+	_, err := k.run(ctx, nil, "delete", strings.Join(kinds, ","), name, "--ignore-not-found", "--cascade=background")
+	return err
+}
+
+// ReportFromLogs extracts the JSON report fuego printed among the other output of a container
+func ReportFromLogs(logs []byte) (*reporting.Report, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(logs))
+	scanner.Buffer(make([]byte, 64*1024), len(logs)+1)
+	offset := 0
+	for scanner.Scan() {
+		line := scanner.Text()
+		// The indented report starts with a line holding only its opening brace
+		if strings.TrimRight(line, "\r") == "{" {
+			var report reporting.Report
+			if err := json.NewDecoder(bytes.NewReader(logs[offset:])).Decode(&report); err != nil {
+				return nil, fmt.Errorf("failed to parse report: %w", err)
+			}
+			return &report, nil
+		}
+		offset += len(line) + 1
+	}
+	return nil, fmt.Errorf("no report in the job output")
+}
@@ -0,0 +1,207 @@
+// Package k8s runs scenario sets inside a Kubernetes cluster: the scenarios are packaged into a
+// ConfigMap mounted into a Job (or a CronJob for scheduled runs) with the fuego image, and the
+// JSON report the container prints is read back from its logs. The cluster is driven through
+// kubectl, so kubeconfig contexts and auth plugins work as they do on the command line.
+package k8s
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MountPath is where the scenario set is mounted; it is the working directory of the container
+const MountPath = "/fuego"
+
+// configPath is where a packaged config file is mounted, relative to MountPath
+const configPath = ".fuego/config.yaml"
+
+// ImageRepository is where release images of fuego are published
+const ImageRepository = "ghcr.io/nulln0ne/fuego"
+
+var releaseVersion = regexp.MustCompile(`^v?\d+\.\d+\.\d+$`)
+
+// DefaultImage is the image of a fuego release, so the cluster runs the version of the CLI;
+// development builds use latest
+func DefaultImage(version string) string {
+	if releaseVersion.MatchString(version) {
+		return ImageRepository + ":" + version
+	}
+	return ImageRepository + ":latest"
+}
+
+// Object is a Kubernetes manifest
+type Object map[string]interface{}
+
+// Spec describes the workload running a bundle
+type Spec struct {
+	// Name of the ConfigMap and the Job or CronJob
+	Name      string
+	Namespace string
+	Image     string
+	// Schedule in cron syntax creates a CronJob instead of a Job
+	Schedule string
+	// Args are added to "fuego run", e.g. --env staging
+	Args []string
+	// Secrets are exposed to the container as environment variables, e.g. for ${VAR} credentials
+	// in the config
+	Secrets []string
+	// Timeout is the active deadline of a run (none when zero)
+	Timeout time.Duration
+}
+
+var validName = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
+
+// Validate checks the spec before anything is sent to the cluster
+func (s Spec) Validate() error {
+	// CronJobs append an 11 character suffix to the names of their jobs, which are limited to 63
+	if len(s.Name) > 52 || !validName.MatchString(s.Name) {
+		return fmt.Errorf("invalid name %q: use at most 52 lowercase letters, digits and '-'", s.Name)
+	}
+	if s.Image == "" {
+		return fmt.Errorf("an image is required")
+	}
+	return nil
+}
+
+// Manifests returns the ConfigMap holding the bundle and the Job or CronJob running it
+func (s Spec) Manifests(bundle *Bundle) []Object {
+	workload := s.job(bundle)
+	if s.Schedule != "" {
+		workload = s.cronJob(bundle)
+	}
+	return []Object{s.configMap(bundle), workload}
+}
+
+func (s Spec) metadata() Object {
+	metadata := Object{
+		"name": s.Name,
+		"labels": Object{
+			"app.kubernetes.io/name":       "fuego",
+			"app.kubernetes.io/instance":   s.Name,
+			"app.kubernetes.io/managed-by": "fuego",
+		},
+	}
+	if s.Namespace != "" {
+		metadata["namespace"] = s.Namespace
+	}
+	return metadata
+}
+
+func (s Spec) configMap(bundle *Bundle) Object {
+	data, binary := Object{}, Object{}
+	paths, keys := bundle.keys()
+	for _, path := range paths {
+		content := bundle.Files[path]
+		if utf8.Valid(content) {
+			data[keys[path]] = string(content)
+		} else {
+			binary[keys[path]] = base64.StdEncoding.EncodeToString(content)
+		}
+	}
+	if bundle.Config != nil {
+		data["config"] = string(bundle.Config)
+	}
+
+	configMap := Object{"apiVersion": "v1", "kind": "ConfigMap", "metadata": s.metadata(), "data": data}
+	if len(binary) > 0 {
+		configMap["binaryData"] = binary
+	}
+	return configMap
+}
+
+// podTemplate mounts the bundle with its original layout and runs it, printing the JSON report
+func (s Spec) podTemplate(bundle *Bundle) Object {
+	paths, keys := bundle.keys()
+	items := make([]Object, 0, len(paths)+1)
+	for _, path := range paths {
+		items = append(items, Object{"key": keys[path], "path": path})
+	}
+
+	args := []string{"run", "--quiet", "--no-progress", "--format", "json"}
+	if bundle.Config != nil {
+		items = append(items, Object{"key": "config", "path": configPath})
+		args = append(args, "--config", configPath)
+	}
+	args = append(args, s.Args...)
+	args = append(args, bundle.Paths...)
+
+	container := Object{
+		"name":       "fuego",
+		"image":      s.Image,
+		"command":    []string{"fuego"},
+		"args":       args,
+		"workingDir": MountPath,
+		"volumeMounts": []Object{
+			{"name": "scenarios", "mountPath": MountPath, "readOnly": true},
+		},
+	}
+	if len(s.Secrets) > 0 {
+		var envFrom []Object
+		for _, secret := range s.Secrets {
+			envFrom = append(envFrom, Object{"secretRef": Object{"name": secret}})
+		}
+		container["envFrom"] = envFrom
+	}
+
+	return Object{
+		"metadata": Object{"labels": s.metadata()["labels"]},
+		"spec": Object{
+			"restartPolicy": "Never",
+			"containers":    []Object{container},
+			"volumes": []Object{
+				{"name": "scenarios", "configMap": Object{"name": s.Name, "items": items}},
+			},
+		},
+	}
+}
+
+// jobSpec does not retry: a failing scenario fails the job, and its report is in the logs
+func (s Spec) jobSpec(bundle *Bundle) Object {
+	spec := Object{"backoffLimit": 0, "template": s.podTemplate(bundle)}
+	if s.Timeout > 0 {
+		spec["activeDeadlineSeconds"] = int64(s.Timeout.Seconds())
+	}
+	return spec
+}
+
+func (s Spec) job(bundle *Bundle) Object {
+	return Object{"apiVersion": "batch/v1", "kind": "Job", "metadata": s.metadata(), "spec": s.jobSpec(bundle)}
+}
+
+func (s Spec) cronJob(bundle *Bundle) Object {
+	return Object{
+		"apiVersion": "batch/v1",
+		"kind":       "CronJob",
+		"metadata":   s.metadata(),
+		"spec": Object{
+			"schedule":                   s.Schedule,
+			"concurrencyPolicy":          "Forbid",
+			"successfulJobsHistoryLimit": 3,
+			"failedJobsHistoryLimit":     3,
+			"jobTemplate":                Object{"spec": s.jobSpec(bundle)},
+		},
+	}
+}
+
+// WriteYAML writes manifests as a multi-document YAML stream, e.g. for kubectl apply -f
+func WriteYAML(objects []Object) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, object := range objects {
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		encoder := yaml.NewEncoder(&buf)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(map[string]interface{}(object)); err != nil {
+			return nil, fmt.Errorf("failed to encode %v: %w", object["kind"], err)
+		}
+		encoder.Close()
+	}
+	return buf.Bytes(), nil
+}
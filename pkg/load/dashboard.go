@@ -0,0 +1,133 @@
+package load
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// NewDashboard serves a live web UI of a load run: the page at / polls /metrics (a JSON Snapshot)
+// every second, and POST /stop calls stop so an obviously failing run can be aborted early
+func NewDashboard(metrics *Metrics, stop func()) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		io.WriteString(w, dashboardPage)
+	})
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(metrics.Snapshot())
+	})
+	mux.HandleFunc("/stop", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "use POST to stop the run", http.StatusMethodNotAllowed)
+			return
+		}
+		stop()
+		w.WriteHeader(http.StatusAccepted)
+	})
+	return mux
+}
+
+// RenderTerminal draws a snapshot as a table, redrawing the screen from the top
+func RenderTerminal(w io.Writer, header []string, snapshot Snapshot) {
+	fmt.Fprint(w, "\033[H\033[2J")
+	for _, line := range header {
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintf(w, "\nLive (stage %s, %v elapsed): %.1f req/s, %.1f%% errors\n\n",
+		snapshot.Stage, snapshot.Elapsed.Round(time.Second), snapshot.RPS, snapshot.ErrorRate*100)
+	fmt.Fprintf(w, "%-32s %9s %8s %10s %10s %10s %9s\n", "STEP", "REQ/S", "ERRORS", "P50", "P95", "P99", "TOTAL")
+	for _, step := range snapshot.Steps {
+		errors := fmt.Sprintf("%.1f%%", step.ErrorRate*100)
+		if step.ErrorRate > 0 {
+			errors = "\033[31m" + fmt.Sprintf("%7s", errors) + "\033[0m"
+		}
+		fmt.Fprintf(w, "%-32s %9.1f %8s %10v %10v %10v %9d\n", truncate(step.Step, 32), step.RPS, errors,
+			step.P50.Round(time.Millisecond), step.P95.Round(time.Millisecond), step.P99.Round(time.Millisecond), step.Total)
+	}
+	fmt.Fprintln(w, "\nPress Ctrl+C to stop the run")
+}
+
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+const dashboardPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>fuego load</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", sans-serif; margin: 2em; color: #222; }
+.totals span { display: inline-block; margin-right: 2em; font-size: 1.4em; }
+table { border-collapse: collapse; margin-top: 1em; min-width: 60em; }
+th, td { padding: 0.4em 0.8em; border-bottom: 1px solid #ddd; text-align: right; }
+th:first-child, td:first-child { text-align: left; }
+.errors { color: #c0392b; font-weight: bold; }
+button { background: #c0392b; color: white; border: none; padding: 0.6em 1.2em; font-size: 1em; cursor: pointer; }
+#status { color: #777; margin-left: 1em; }
+</style>
+</head>
+<body>
+<h1>fuego load</h1>
+<div class="totals"><span id="stage"></span><span id="rps"></span><span id="errors"></span><span id="elapsed"></span></div>
+<table>
+<thead><tr><th>Step</th><th>req/s</th><th>Errors</th><th>p50</th><th>p95</th><th>p99</th><th>Total</th><th>Failed</th></tr></thead>
+<tbody id="steps"></tbody>
+</table>
+<p><button id="stop">Stop run</button><span id="status"></span></p>
+<script>
+function ms(ns) { return (ns / 1e6).toFixed(1) + " ms"; }
+function pct(rate) { return (rate * 100).toFixed(1) + "%"; }
+function cell(text, className) {
+  var td = document.createElement("td");
+  td.textContent = text;
+  if (className) td.className = className;
+  return td;
+}
+function refresh() {
+  fetch("metrics").then(function (r) { return r.json(); }).then(function (s) {
+    document.getElementById("stage").textContent = "Stage " + (s.stage || "-");
+    document.getElementById("rps").textContent = s.rps.toFixed(1) + " req/s";
+    document.getElementById("errors").textContent = pct(s.error_rate) + " errors";
+    document.getElementById("elapsed").textContent = Math.round(s.elapsed / 1e9) + " s";
+    var body = document.getElementById("steps");
+    body.innerHTML = "";
+    (s.steps || []).forEach(function (step) {
+      var row = document.createElement("tr");
+      row.appendChild(cell(step.step));
+      row.appendChild(cell(step.rps.toFixed(1)));
+      row.appendChild(cell(pct(step.error_rate), step.error_rate > 0 ? "errors" : ""));
+      row.appendChild(cell(ms(step.p50)));
+      row.appendChild(cell(ms(step.p95)));
+      row.appendChild(cell(ms(step.p99)));
+      row.appendChild(cell(step.total));
+      row.appendChild(cell(step.failed));
+      body.appendChild(row);
+    });
+  }).catch(function () {
+    document.getElementById("status").textContent = "run finished";
+  });
+}
+document.getElementById("stop").onclick = function () {
+  fetch("stop", { method: "POST" }).then(function () {
+    document.getElementById("status").textContent = "stopping...";
+  });
+};
+refresh();
+setInterval(refresh, 1000);
+</script>
+</body>
+</html>
+`
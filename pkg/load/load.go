@@ -0,0 +1,214 @@
+// Package load runs scenarios repeatedly at a controlled rate. A profile is a list of stages, each
+// starting iterations at a fixed rate for a duration; stop conditions end the run after a stage
+// whose error rate or latency is too high. Results are reported stage by stage.
+package load
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/reporting"
+)
+
+// Stage starts iterations at a fixed rate for a duration
+type Stage struct {
+	Name     string        `json:"name"`
+	Rate     float64       `json:"rate"` // iterations started per second
+	Duration time.Duration `json:"duration"`
+}
+
+// StopConditions end a run after the first stage that violates them; zero values are not checked
+type StopConditions struct {
+	MaxErrorRate float64       `json:"max_error_rate,omitempty"` // share of failed iterations, 0-1
+	MaxP95       time.Duration `json:"max_p95,omitempty"`        // 95th percentile iteration duration
+}
+
+// Profile is a named sequence of stages
+type Profile struct {
+	Name   string         `json:"name"`
+	Stages []Stage        `json:"stages"`
+	Stop   StopConditions `json:"stop"`
+}
+
+// Validate checks that every stage has a positive rate and duration
+func (p Profile) Validate() error {
+	if len(p.Stages) == 0 {
+		return fmt.Errorf("profile %s has no stages", p.Name)
+	}
+	for _, stage := range p.Stages {
+		if stage.Rate <= 0 || stage.Duration <= 0 {
+			return fmt.Errorf("stage %s needs a positive rate and duration", stage.Name)
+		}
+	}
+	if p.Stop.MaxErrorRate < 0 || p.Stop.MaxErrorRate > 1 {
+		return fmt.Errorf("max error rate must be between 0 and 1")
+	}
+	return nil
+}
+
+// Iteration runs one iteration (typically every scenario once) and reports whether it passed
+type Iteration func(ctx context.Context) bool
+
+// StageResult is the outcome of one stage
+type StageResult struct {
+	Stage      Stage         `json:"stage"`
+	Iterations int           `json:"iterations"`
+	Failed     int           `json:"failed"`
+	Dropped    int           `json:"dropped,omitempty"` // not started because too many were in flight
+	ErrorRate  float64       `json:"error_rate"`
+	Rate       float64       `json:"rate"` // achieved iterations per second
+	P50        time.Duration `json:"p50"`
+	P95        time.Duration `json:"p95"`
+	Max        time.Duration `json:"max"`
+	Violation  string        `json:"violation,omitempty"` // stop condition violated in this stage
+}
+
+// Result is the outcome of a run
+type Result struct {
+	Profile   string        `json:"profile"`
+	Stages    []StageResult `json:"stages"`
+	StoppedBy string        `json:"stopped_by,omitempty"` // stop condition that ended the run early
+	Duration  time.Duration `json:"duration"`
+}
+
+// Passed reports whether the run finished without violating a stop condition or failing an
+// iteration
+func (r *Result) Passed() bool {
+	if r.StoppedBy != "" {
+		return false
+	}
+	for _, stage := range r.Stages {
+		if stage.Failed > 0 || stage.Dropped > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Options controls how a profile is run
+type Options struct {
+	// MaxInFlight caps the iterations running at once (unlimited when 0); ticks beyond that are
+	// counted as dropped
+	MaxInFlight int
+	// StageStarted and StageFinished, when set, are called as each stage starts and ends
+	StageStarted  func(Stage)
+	StageFinished func(StageResult)
+}
+
+// Run executes the stages of a profile in order. Each stage waits for its iterations to finish
+// before the stop conditions are checked and the next stage starts.
+func Run(ctx context.Context, profile Profile, iterate Iteration, options Options) (*Result, error) {
+	if err := profile.Validate(); err != nil {
+		return nil, err
+	}
+
+	started := time.Now()
+	result := &Result{Profile: profile.Name}
+	for _, stage := range profile.Stages {
+		if ctx.Err() != nil {
+			break
+		}
+
+		if options.StageStarted != nil {
+			options.StageStarted(stage)
+		}
+		stageResult := runStage(ctx, stage, iterate, options.MaxInFlight)
+		stageResult.Violation = profile.Stop.violation(stageResult)
+		result.Stages = append(result.Stages, stageResult)
+		if options.StageFinished != nil {
+			options.StageFinished(stageResult)
+		}
+
+		if stageResult.Violation != "" {
+			result.StoppedBy = fmt.Sprintf("stage %s: %s", stage.Name, stageResult.Violation)
+			break
+		}
+	}
+	result.Duration = time.Since(started)
+
+	return result, ctx.Err()
+}
+
+func runStage(ctx context.Context, stage Stage, iterate Iteration, maxInFlight int) StageResult {
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		durations []time.Duration
+		failed    int
+		dropped   int
+		inFlight  int
+	)
+
+	interval := time.Duration(float64(time.Second) / stage.Rate)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	deadline := time.NewTimer(stage.Duration)
+	defer deadline.Stop()
+
+	start := func() {
+		mu.Lock()
+		if maxInFlight > 0 && inFlight >= maxInFlight {
+			dropped++
+			mu.Unlock()
+			return
+		}
+		inFlight++
+		mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			iterationStarted := time.Now()
+			passed := iterate(ctx)
+			elapsed := time.Since(iterationStarted)
+
+			mu.Lock()
+			defer mu.Unlock()
+			inFlight--
+			durations = append(durations, elapsed)
+			if !passed {
+				failed++
+			}
+		}()
+	}
+
+	stageStarted := time.Now()
+	start()
+ticks:
+	for {
+		select {
+		case <-ctx.Done():
+			break ticks
+		case <-deadline.C:
+			break ticks
+		case <-ticker.C:
+			start()
+		}
+	}
+	elapsed := time.Since(stageStarted)
+	wg.Wait()
+
+	result := StageResult{Stage: stage, Iterations: len(durations), Failed: failed, Dropped: dropped}
+	if result.Iterations > 0 {
+		result.ErrorRate = float64(failed) / float64(result.Iterations)
+		result.Rate = float64(result.Iterations) / elapsed.Seconds()
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		result.P50 = reporting.Percentile(durations, 50)
+		result.P95 = reporting.Percentile(durations, 95)
+		result.Max = durations[len(durations)-1]
+	}
+	return result
+}
+
+func (s StopConditions) violation(stage StageResult) string {
+	if s.MaxErrorRate > 0 && stage.ErrorRate > s.MaxErrorRate {
+		return fmt.Sprintf("error rate %.1f%% above %.1f%%", stage.ErrorRate*100, s.MaxErrorRate*100)
+	}
+	if s.MaxP95 > 0 && stage.P95 > s.MaxP95 {
+		return fmt.Sprintf("p95 %v above %v", stage.P95, s.MaxP95)
+	}
+	return ""
+}
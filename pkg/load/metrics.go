@@ -0,0 +1,161 @@
+package load
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/reporting"
+)
+
+// DefaultWindow is the span live rates and percentiles are computed over
+const DefaultWindow = 10 * time.Second
+
+// Metrics collects the step results of a load run for live display. Rates and percentiles cover
+// a sliding window; totals cover the whole run. It is safe for concurrent use.
+type Metrics struct {
+	window  time.Duration
+	started time.Time
+
+	mu      sync.Mutex
+	stage   string
+	samples []sample
+	totals  map[string]*stepTotal
+	order   []string // steps in the order they were first seen
+}
+
+type sample struct {
+	at       time.Time
+	step     string
+	duration time.Duration
+	failed   bool
+}
+
+type stepTotal struct {
+	count  int
+	failed int
+}
+
+// StepMetrics are the live metrics of one step
+type StepMetrics struct {
+	Step      string        `json:"step"`
+	RPS       float64       `json:"rps"`
+	ErrorRate float64       `json:"error_rate"`
+	P50       time.Duration `json:"p50"`
+	P95       time.Duration `json:"p95"`
+	P99       time.Duration `json:"p99"`
+	Total     int           `json:"total"`
+	Failed    int           `json:"failed"`
+}
+
+// Snapshot is the state of a load run at one moment
+type Snapshot struct {
+	Stage     string        `json:"stage"`
+	Elapsed   time.Duration `json:"elapsed"`
+	RPS       float64       `json:"rps"`
+	ErrorRate float64       `json:"error_rate"`
+	Steps     []StepMetrics `json:"steps"`
+}
+
+// NewMetrics returns a collector computing live values over window (DefaultWindow when 0)
+func NewMetrics(window time.Duration) *Metrics {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Metrics{window: window, started: time.Now(), totals: make(map[string]*stepTotal)}
+}
+
+// SetStage records the stage being run; it fits Options.StageStarted
+func (m *Metrics) SetStage(stage Stage) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stage = stage.Name
+}
+
+// Record adds a finished request of a step
+func (m *Metrics) Record(step string, duration time.Duration, failed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.samples = append(m.samples, sample{at: now, step: step, duration: duration, failed: failed})
+	m.prune(now)
+
+	total, exists := m.totals[step]
+	if !exists {
+		total = &stepTotal{}
+		m.totals[step] = total
+		m.order = append(m.order, step)
+	}
+	total.count++
+	if failed {
+		total.failed++
+	}
+}
+
+// Snapshot returns the live metrics per step and over all steps
+func (m *Metrics) Snapshot() Snapshot {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	m.prune(now)
+
+	// Rates are over the window, or over the run while it is younger than the window
+	span := m.window
+	if elapsed := now.Sub(m.started); elapsed < span {
+		span = elapsed
+	}
+	seconds := span.Seconds()
+	if seconds <= 0 {
+		seconds = 1
+	}
+
+	byStep := make(map[string][]sample)
+	failed := 0
+	for _, s := range m.samples {
+		byStep[s.step] = append(byStep[s.step], s)
+		if s.failed {
+			failed++
+		}
+	}
+
+	snapshot := Snapshot{Stage: m.stage, Elapsed: now.Sub(m.started), RPS: float64(len(m.samples)) / seconds}
+	if len(m.samples) > 0 {
+		snapshot.ErrorRate = float64(failed) / float64(len(m.samples))
+	}
+	for _, step := range m.order {
+		metrics := StepMetrics{Step: step, Total: m.totals[step].count, Failed: m.totals[step].failed}
+		samples := byStep[step]
+		if len(samples) > 0 {
+			durations := make([]time.Duration, len(samples))
+			stepFailed := 0
+			for i, s := range samples {
+				durations[i] = s.duration
+				if s.failed {
+					stepFailed++
+				}
+			}
+			sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+			metrics.RPS = float64(len(samples)) / seconds
+			metrics.ErrorRate = float64(stepFailed) / float64(len(samples))
+			metrics.P50 = reporting.Percentile(durations, 50)
+			metrics.P95 = reporting.Percentile(durations, 95)
+			metrics.P99 = reporting.Percentile(durations, 99)
+		}
+		snapshot.Steps = append(snapshot.Steps, metrics)
+	}
+	return snapshot
+}
+
+// prune drops samples older than the window
+func (m *Metrics) prune(now time.Time) {
+	cutoff := now.Add(-m.window)
+	keep := 0
+	for keep < len(m.samples) && m.samples[keep].at.Before(cutoff) {
+		keep++
+	}
+	if keep > 0 {
+		m.samples = append(m.samples[:0], m.samples[keep:]...)
+	}
+}
@@ -0,0 +1,84 @@
+package load
+
+import (
+	"fmt"
+	"time"
+)
+
+// ProfileNames lists the built-in profiles
+var ProfileNames = []string{"constant", "soak", "stress", "spike"}
+
+// Settings parameterize the built-in profiles; each profile uses the fields it needs
+type Settings struct {
+	Rate          float64       // constant and soak rate; stress start rate; spike base rate
+	Duration      time.Duration // constant and soak duration
+	Step          float64       // stress: rate added per stage
+	MaxRate       float64       // stress: last stage rate; spike: burst rate
+	StageDuration time.Duration // stress stages; spike warm-up and recovery
+	SpikeDuration time.Duration // spike burst
+	Stop          StopConditions
+}
+
+// NewProfile builds a built-in profile by name
+func NewProfile(name string, settings Settings) (Profile, error) {
+	switch name {
+	case "constant":
+		return Constant(settings.Rate, settings.Duration, settings.Stop), nil
+	case "soak":
+		return Soak(settings.Rate, settings.Duration, settings.Stop), nil
+	case "stress":
+		return Stress(settings.Rate, settings.Step, settings.MaxRate, settings.StageDuration, settings.Stop), nil
+	case "spike":
+		return Spike(settings.Rate, settings.MaxRate, settings.StageDuration, settings.SpikeDuration, settings.Stop), nil
+	default:
+		return Profile{}, fmt.Errorf("unknown load profile %q (expected constant, soak, stress or spike)", name)
+	}
+}
+
+// Constant runs one stage at a fixed rate
+func Constant(rate float64, duration time.Duration, stop StopConditions) Profile {
+	return Profile{Name: "constant", Stages: []Stage{{Name: "constant", Rate: rate, Duration: duration}}, Stop: stop}
+}
+
+// Soak runs a constant, usually low, rate for a long time to surface leaks and slow degradation.
+// The run is split into hourly stages (or one stage for shorter runs) so degradation shows up
+// stage by stage.
+func Soak(rate float64, duration time.Duration, stop StopConditions) Profile {
+	profile := Profile{Name: "soak", Stop: stop}
+	for elapsed, hour := time.Duration(0), 1; elapsed < duration; hour++ {
+		length := time.Hour
+		if remaining := duration - elapsed; remaining < length {
+			length = remaining
+		}
+		profile.Stages = append(profile.Stages, Stage{Name: fmt.Sprintf("hour %d", hour), Rate: rate, Duration: length})
+		elapsed += length
+	}
+	return profile
+}
+
+// Stress raises the rate by step every stage, from start up to maxRate, until a stop condition
+// (typically the error rate) is violated, showing where the system breaks
+func Stress(start, step, maxRate float64, stageDuration time.Duration, stop StopConditions) Profile {
+	profile := Profile{Name: "stress", Stop: stop}
+	if step <= 0 {
+		step = start
+	}
+	for rate := start; rate <= maxRate && len(profile.Stages) < 1000; rate += step {
+		profile.Stages = append(profile.Stages, Stage{Name: fmt.Sprintf("%g/s", rate), Rate: rate, Duration: stageDuration})
+	}
+	return profile
+}
+
+// Spike holds a base rate, bursts to peak for spikeDuration, then returns to the base rate to
+// show whether the system recovers
+func Spike(base, peak float64, stageDuration, spikeDuration time.Duration, stop StopConditions) Profile {
+	return Profile{
+		Name: "spike",
+		Stages: []Stage{
+			{Name: "warm-up", Rate: base, Duration: stageDuration},
+			{Name: "spike", Rate: peak, Duration: spikeDuration},
+			{Name: "recovery", Rate: base, Duration: stageDuration},
+		},
+		Stop: stop,
+	}
+}
@@ -0,0 +1,222 @@
+// Package manpage renders cobra commands as roff man pages
+package manpage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Header is the part of a man page title that is the same for all commands
+type Header struct {
+	Section string    // default "1"
+	Source  string    // e.g. "fuego v1.4.0"
+	Manual  string    // e.g. "Fuego Manual"
+	Date    time.Time // default now
+}
+
+// Name returns the man page name of a command, e.g. fuego-export-curl for `fuego export curl`
+func Name(cmd *cobra.Command) string {
+	return strings.ReplaceAll(cmd.CommandPath(), " ", "-")
+}
+
+// WriteTree writes one page per available command under root into dir, as <name>.<section>
+func WriteTree(root *cobra.Command, header Header, dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	var written []string
+	var walk func(cmd *cobra.Command) error
+	walk = func(cmd *cobra.Command) error {
+		if !documented(cmd) {
+			return nil
+		}
+		path := filepath.Join(dir, Name(cmd)+"."+header.section())
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		err = Write(file, cmd, header)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		written = append(written, path)
+
+		for _, child := range cmd.Commands() {
+			if err := walk(child); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return written, walk(root)
+}
+
+// Write renders the man page of one command
+func Write(w io.Writer, cmd *cobra.Command, header Header) error {
+	date := header.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, ".TH \"%s\" \"%s\" \"%s\" \"%s\" \"%s\"\n",
+		escape(strings.ToUpper(Name(cmd))), header.section(), date.Format("Jan 2006"), escape(header.Source), escape(header.Manual))
+	b.WriteString(".SH NAME\n")
+	fmt.Fprintf(&b, "%s \\- %s\n", escape(Name(cmd)), escape(cmd.Short))
+
+	b.WriteString(".SH SYNOPSIS\n")
+	if cmd.Runnable() {
+		fmt.Fprintf(&b, ".B %s\n", escape(cmd.CommandPath()))
+		if _, args, found := strings.Cut(cmd.Use, " "); found {
+			b.WriteString(escape(args) + "\n")
+		}
+		if cmd.HasAvailableFlags() {
+			b.WriteString("[flags]\n")
+		}
+		if cmd.HasAvailableSubCommands() {
+			b.WriteString(".br\n")
+		}
+	}
+	if cmd.HasAvailableSubCommands() {
+		fmt.Fprintf(&b, ".B %s\n\\fIcommand\\fP [flags]\n", escape(cmd.CommandPath()))
+	}
+
+	b.WriteString(".SH DESCRIPTION\n")
+	description := cmd.Long
+	if description == "" {
+		description = cmd.Short
+	}
+	b.WriteString(text(description))
+	if cmd.Example != "" {
+		b.WriteString(".SH EXAMPLES\n")
+		b.WriteString(text(cmd.Example))
+	}
+
+	if cmd.HasAvailableLocalFlags() {
+		b.WriteString(".SH OPTIONS\n")
+		b.WriteString(flags(cmd.NonInheritedFlags()))
+	}
+	if cmd.HasAvailableInheritedFlags() {
+		b.WriteString(".SH OPTIONS INHERITED FROM PARENT COMMANDS\n")
+		b.WriteString(flags(cmd.InheritedFlags()))
+	}
+
+	var related []string
+	if cmd.HasParent() {
+		related = append(related, Name(cmd.Parent()))
+	}
+	for _, child := range cmd.Commands() {
+		if documented(child) {
+			related = append(related, Name(child))
+		}
+	}
+	if len(related) > 0 {
+		b.WriteString(".SH SEE ALSO\n")
+		for i, name := range related {
+			if i > 0 {
+				b.WriteString(",\n")
+			}
+			fmt.Fprintf(&b, "\\fB%s\\fP(%s)", escape(name), header.section())
+		}
+		b.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func (h Header) section() string {
+	if h.Section == "" {
+		return "1"
+	}
+	return h.Section
+}
+
+// documented reports whether a command gets a page: help and shell completion internals do not
+func documented(cmd *cobra.Command) bool {
+	return cmd.IsAvailableCommand() || cmd == cmd.Root()
+}
+
+// text renders help text: lines indented by two spaces or more, like the example lists in Long
+// texts, keep their layout and blank lines separate paragraphs
+func text(s string) string {
+	var b strings.Builder
+	preformatted := false
+	for _, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		indented := strings.HasPrefix(line, "  ")
+		switch {
+		case indented && !preformatted:
+			b.WriteString(".PP\n.RS 4\n.nf\n")
+			preformatted = true
+		case !indented && preformatted && strings.TrimSpace(line) != "":
+			b.WriteString(".fi\n.RE\n")
+			preformatted = false
+		}
+		switch {
+		case preformatted:
+			b.WriteString(escapeLine(strings.TrimPrefix(line, "  ")) + "\n")
+		case strings.TrimSpace(line) == "":
+			b.WriteString(".PP\n")
+		default:
+			b.WriteString(escapeLine(line) + "\n")
+		}
+	}
+	if preformatted {
+		b.WriteString(".fi\n.RE\n")
+	}
+	return b.String()
+}
+
+func flags(set *pflag.FlagSet) string {
+	var b strings.Builder
+	set.VisitAll(func(flag *pflag.Flag) {
+		if flag.Hidden {
+			return
+		}
+		b.WriteString(".TP\n")
+		if flag.Shorthand != "" && flag.ShorthandDeprecated == "" {
+			fmt.Fprintf(&b, "\\fB\\-%s\\fP, ", escape(flag.Shorthand))
+		}
+		fmt.Fprintf(&b, "\\fB\\-\\-%s\\fP", escape(flag.Name))
+		if kind := flag.Value.Type(); kind != "bool" {
+			if flag.NoOptDefVal != "" {
+				fmt.Fprintf(&b, "[=\\fI%s\\fP]", escape(kind))
+			} else {
+				fmt.Fprintf(&b, "=\\fI%s\\fP", escape(kind))
+			}
+		}
+		b.WriteString("\n")
+		usage := flag.Usage
+		if flag.DefValue != "" && flag.DefValue != "false" && flag.DefValue != "[]" && flag.DefValue != "0" {
+			usage += fmt.Sprintf(" (default %s)", flag.DefValue)
+		}
+		b.WriteString(escapeLine(usage) + "\n")
+	})
+	return b.String()
+}
+
+// escape makes text safe inside a roff line
+func escape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	return strings.ReplaceAll(s, "-", `\-`)
+}
+
+// escapeLine escapes a whole line, which must not start with a roff control character
+func escapeLine(s string) string {
+	s = escape(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = `\&` + s
+	}
+	return s
+}
@@ -0,0 +1,83 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// WriteMetrics writes the statistics in the Prometheus text exposition format
+func (m *Monitor) WriteMetrics(w io.Writer) error {
+	stats := m.Stats()
+	m.mu.Lock()
+	checkErrors := m.errors
+	m.mu.Unlock()
+
+	metrics := []struct {
+		name, kind, help string
+		value            func(Stats) float64
+	}{
+		{"fuego_monitor_checks_total", "counter", "Checks of the scenario", func(s Stats) float64 { return float64(s.Checks) }},
+		{"fuego_monitor_failures_total", "counter", "Failed checks of the scenario", func(s Stats) float64 { return float64(s.Failures) }},
+		{"fuego_monitor_up", "gauge", "Whether the last check of the scenario passed", func(s Stats) float64 { return boolValue(s.Up) }},
+		{"fuego_monitor_consecutive_failures", "gauge", "Failed checks of the scenario since it last passed", func(s Stats) float64 { return float64(s.ConsecutiveFailures) }},
+		{"fuego_monitor_alerting", "gauge", "Whether an alert is firing for the scenario", func(s Stats) float64 { return boolValue(s.Alerting) }},
+		{"fuego_monitor_success_ratio", "gauge", "Share of passed checks in the rolling window", func(s Stats) float64 { return s.SuccessRate }},
+		{"fuego_monitor_duration_seconds", "gauge", "Duration of the last check of the scenario", func(s Stats) float64 { return s.LastDuration.Seconds() }},
+		{"fuego_monitor_last_check_timestamp_seconds", "gauge", "Time of the last check of the scenario", func(s Stats) float64 {
+			if s.LastCheck.IsZero() {
+				return 0
+			}
+			return float64(s.LastCheck.UnixNano()) / 1e9
+		}},
+	}
+
+	var b strings.Builder
+	for _, metric := range metrics {
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", metric.name, metric.help, metric.name, metric.kind)
+		for _, s := range stats {
+			fmt.Fprintf(&b, "%s{scenario=\"%s\"} %g\n", metric.name, escapeLabel(s.Scenario), metric.value(s))
+		}
+	}
+
+	fmt.Fprintf(&b, "# HELP fuego_monitor_window_duration_seconds Duration percentiles of the passed checks in the rolling window\n")
+	fmt.Fprintf(&b, "# TYPE fuego_monitor_window_duration_seconds gauge\n")
+	for _, s := range stats {
+		fmt.Fprintf(&b, "fuego_monitor_window_duration_seconds{scenario=\"%s\",quantile=\"0.5\"} %g\n", escapeLabel(s.Scenario), s.P50.Seconds())
+		fmt.Fprintf(&b, "fuego_monitor_window_duration_seconds{scenario=\"%s\",quantile=\"0.95\"} %g\n", escapeLabel(s.Scenario), s.P95.Seconds())
+	}
+
+	fmt.Fprintf(&b, "# HELP fuego_monitor_errors_total Checks that could not run\n# TYPE fuego_monitor_errors_total counter\n")
+	fmt.Fprintf(&b, "fuego_monitor_errors_total %d\n", checkErrors)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Handler serves the metrics at /metrics and the statistics as JSON at /status
+func (m *Monitor) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		m.WriteMetrics(w)
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(m.Stats())
+	})
+	return mux
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// escapeLabel escapes a label value as the exposition format requires
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,277 @@
+// Package monitor runs scenarios on an interval as synthetic checks. It keeps rolling success and
+// latency statistics per scenario, exposes them in the Prometheus text format and sends an alert
+// when a scenario fails several checks in a row, and another once it recovers.
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/reporting"
+)
+
+// Alert statuses
+const (
+	AlertFiring   = "firing"
+	AlertResolved = "resolved"
+)
+
+// Check runs the scenarios once
+type Check func(ctx context.Context) (*reporting.Report, error)
+
+// Notifier delivers alerts, e.g. a reporting.WebhookSink listing the alert event
+type Notifier interface {
+	Notify(event string, payload interface{}) error
+}
+
+// Options configures a monitor
+type Options struct {
+	Interval time.Duration
+	// Window is the number of recent checks per scenario the success rate and latency
+	// percentiles are computed over (default 20)
+	Window int
+	// AlertAfter is the number of consecutive failed checks that fire an alert (default 3)
+	AlertAfter int
+	Notifiers  []Notifier
+}
+
+// Alert is sent when a scenario starts failing and when it recovers
+type Alert struct {
+	Status              string `json:"status"` // firing or resolved
+	Scenario            string `json:"scenario"`
+	ConsecutiveFailures int    `json:"consecutive_failures"`
+	// Failures are the messages of the last failed check
+	Failures []string  `json:"failures,omitempty"`
+	Since    time.Time `json:"since"` // first failure of the incident
+	Time     time.Time `json:"time"`
+}
+
+// Result is the outcome of one check, passed to the callback of Run
+type Result struct {
+	Time   time.Time
+	Report *reporting.Report
+	// Err is set when the check could not run, which counts as a failure of every scenario
+	Err    error
+	Alerts []Alert
+	// NotifyErrors are alerts that could not be delivered
+	NotifyErrors []error
+}
+
+// Stats are the statistics of one scenario
+type Stats struct {
+	Scenario            string        `json:"scenario"`
+	Checks              int64         `json:"checks"`
+	Failures            int64         `json:"failures"`
+	Up                  bool          `json:"up"`
+	ConsecutiveFailures int           `json:"consecutive_failures"`
+	Alerting            bool          `json:"alerting"`
+	SuccessRate         float64       `json:"success_rate"` // over the window
+	P50                 time.Duration `json:"p50"`
+	P95                 time.Duration `json:"p95"`
+	LastDuration        time.Duration `json:"last_duration"`
+	LastCheck           time.Time     `json:"last_check"`
+	LastError           string        `json:"last_error,omitempty"`
+}
+
+// Monitor aggregates check results. It is safe for concurrent use, so metrics can be served
+// while checks run.
+type Monitor struct {
+	options Options
+
+	mu        sync.Mutex
+	scenarios map[string]*scenarioState
+	errors    int64
+}
+
+type scenarioState struct {
+	stats  Stats
+	since  time.Time
+	recent []sample
+}
+
+type sample struct {
+	duration time.Duration
+	failed   bool
+}
+
+// New returns a monitor of the named scenarios; they are reported down until their first check
+func New(scenarios []string, options Options) *Monitor {
+	if options.Window <= 0 {
+		options.Window = 20
+	}
+	if options.AlertAfter <= 0 {
+		options.AlertAfter = 3
+	}
+	m := &Monitor{options: options, scenarios: make(map[string]*scenarioState)}
+	for _, name := range scenarios {
+		m.state(name)
+	}
+	return m
+}
+
+// Run checks right away and then every interval until ctx is cancelled. Checks never overlap: a
+// check taking longer than the interval delays the next one.
+func (m *Monitor) Run(ctx context.Context, check Check, done func(Result)) error {
+	if m.options.Interval <= 0 {
+		return fmt.Errorf("interval must be positive")
+	}
+	ticker := time.NewTicker(m.options.Interval)
+	defer ticker.Stop()
+	for ctx.Err() == nil {
+		started := time.Now()
+		report, err := check(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		result := Result{Time: started, Report: report, Err: err}
+		result.Alerts = m.Record(started, report, err)
+		for _, alert := range result.Alerts {
+			for _, notifier := range m.options.Notifiers {
+				if err := notifier.Notify("alert", alert); err != nil {
+					result.NotifyErrors = append(result.NotifyErrors, err)
+				}
+			}
+		}
+		if done != nil {
+			done(result)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// Record adds the outcome of a check to the statistics and returns the alerts it raises. A run
+// error, or a report of an unavailable environment, fails every known scenario.
+func (m *Monitor) Record(at time.Time, report *reporting.Report, err error) []Alert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err == nil && report != nil && report.Status == reporting.StatusEnvironmentUnavailable {
+		err = fmt.Errorf("environment unavailable: preflight checks failed")
+	}
+	var alerts []Alert
+	if err != nil {
+		m.errors++
+		for _, name := range m.names() {
+			if alert := m.observe(m.scenarios[name], at, 0, []string{err.Error()}); alert != nil {
+				alerts = append(alerts, *alert)
+			}
+		}
+		return alerts
+	}
+
+	for _, result := range report.Scenarios {
+		if result.Scenario == nil || result.Status == "skipped" {
+			continue
+		}
+		var failures []string
+		if result.Status == "failed" {
+			single := reporting.Report{Scenarios: []reporting.ScenarioResult{result}}
+			if failures = single.Failures(); len(failures) == 0 {
+				failures = []string{result.Scenario.Name + ": failed"}
+			}
+		}
+		if alert := m.observe(m.state(result.Scenario.Name), at, result.Duration, failures); alert != nil {
+			alerts = append(alerts, *alert)
+		}
+	}
+	return alerts
+}
+
+// observe records one check of a scenario, failed when it has failures; m.mu is held
+func (m *Monitor) observe(state *scenarioState, at time.Time, duration time.Duration, failures []string) *Alert {
+	stats := &state.stats
+	failed := len(failures) > 0
+	stats.Checks++
+	stats.LastCheck, stats.LastDuration = at, duration
+	stats.Up = !failed
+
+	state.recent = append(state.recent, sample{duration: duration, failed: failed})
+	if len(state.recent) > m.options.Window {
+		state.recent = state.recent[len(state.recent)-m.options.Window:]
+	}
+	m.summarize(state)
+
+	if !failed {
+		stats.LastError = ""
+		stats.ConsecutiveFailures = 0
+		if !stats.Alerting {
+			return nil
+		}
+		stats.Alerting = false
+		return &Alert{Status: AlertResolved, Scenario: stats.Scenario, Since: state.since, Time: at}
+	}
+
+	stats.Failures++
+	stats.LastError = failures[0]
+	stats.ConsecutiveFailures++
+	if stats.ConsecutiveFailures == 1 {
+		state.since = at
+	}
+	if stats.Alerting || stats.ConsecutiveFailures < m.options.AlertAfter {
+		return nil
+	}
+	stats.Alerting = true
+	return &Alert{
+		Status:              AlertFiring,
+		Scenario:            stats.Scenario,
+		ConsecutiveFailures: stats.ConsecutiveFailures,
+		Failures:            failures,
+		Since:               state.since,
+		Time:                at,
+	}
+}
+
+// summarize computes the window statistics; latency percentiles only count passed checks, as
+// failed ones are often timeouts or immediate connection errors
+func (m *Monitor) summarize(state *scenarioState) {
+	var durations []time.Duration
+	for _, s := range state.recent {
+		if !s.failed {
+			durations = append(durations, s.duration)
+		}
+	}
+	state.stats.SuccessRate = float64(len(durations)) / float64(len(state.recent))
+	state.stats.P50, state.stats.P95 = 0, 0
+	if len(durations) > 0 {
+		sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+		state.stats.P50, state.stats.P95 = reporting.Percentile(durations, 50), reporting.Percentile(durations, 95)
+	}
+}
+
+// Stats returns the statistics of every scenario, sorted by name
+func (m *Monitor) Stats() []Stats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := make([]Stats, 0, len(m.scenarios))
+	for _, name := range m.names() {
+		stats = append(stats, m.scenarios[name].stats)
+	}
+	return stats
+}
+
+func (m *Monitor) state(name string) *scenarioState {
+	state, ok := m.scenarios[name]
+	if !ok {
+		state = &scenarioState{stats: Stats{Scenario: name}}
+		m.scenarios[name] = state
+	}
+	return state
+}
+
+func (m *Monitor) names() []string {
+	names := make([]string, 0, len(m.scenarios))
+	for name := range m.scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,106 @@
+package objectstore
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/streaming"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blockblob"
+)
+
+// AzureCredentials authorize requests to Azure Blob Storage with a storage account key (Shared
+// Key) or a SAS token. Empty fields fall back to the AZURE_STORAGE_ACCOUNT, AZURE_STORAGE_KEY
+// and AZURE_STORAGE_SAS_TOKEN environment variables.
+type AzureCredentials struct {
+	Account  string
+	Key      string // base64 account key
+	SASToken string
+	// Endpoint replaces https://<account>.blob.core.windows.net, e.g.
+	// http://127.0.0.1:10000/devstoreaccount1 for Azurite
+	Endpoint string
+}
+
+func (c AzureCredentials) withDefaults() AzureCredentials {
+	if c.Account == "" {
+		c.Account = os.Getenv("AZURE_STORAGE_ACCOUNT")
+	}
+	if c.Key == "" {
+		c.Key = os.Getenv("AZURE_STORAGE_KEY")
+	}
+	if c.SASToken == "" {
+		c.SASToken = os.Getenv("AZURE_STORAGE_SAS_TOKEN")
+	}
+	c.SASToken = strings.TrimPrefix(c.SASToken, "?")
+	return c
+}
+
+// IsAzureURL reports whether a location is an azblob://container/blob URL
+func IsAzureURL(location string) bool {
+	return strings.HasPrefix(location, "azblob://")
+}
+
+// uploadAzure puts body into a block blob at an azblob://container/blob URL with the Azure SDK
+func uploadAzure(location string, body []byte, contentType string, credentials AzureCredentials) error {
+	container, blobName, err := splitURL(location, "azblob")
+	if err != nil {
+		return err
+	}
+	credentials = credentials.withDefaults()
+
+	endpoint := credentials.Endpoint
+	if endpoint == "" {
+		if credentials.Account == "" {
+			return fmt.Errorf("azure storage account is not set, use AZURE_STORAGE_ACCOUNT")
+		}
+		endpoint = fmt.Sprintf("https://%s.blob.core.windows.net", credentials.Account)
+	}
+	target := strings.TrimSuffix(endpoint, "/") + "/" + container + "/" + escapePath(blobName)
+	if credentials.SASToken != "" {
+		target += "?" + credentials.SASToken
+	}
+
+	// Put reports failures itself, so the SDK neither retries nor sends through its own client
+	options := &blockblob.ClientOptions{ClientOptions: azcore.ClientOptions{
+		Transport: Client,
+		Retry:     policy.RetryOptions{MaxRetries: -1},
+	}}
+	var client *blockblob.Client
+	if credentials.SASToken == "" && credentials.Key != "" {
+		var sharedKey *blob.SharedKeyCredential
+		if sharedKey, err = blob.NewSharedKeyCredential(credentials.Account, credentials.Key); err != nil {
+			return fmt.Errorf("invalid azure storage key: %w", err)
+		}
+		client, err = blockblob.NewClientWithSharedKeyCredential(target, sharedKey, options)
+	} else {
+		client, err = blockblob.NewClientWithNoCredential(target, options)
+	}
+	if err != nil {
+		return err
+	}
+
+	upload := &blockblob.UploadOptions{}
+	if contentType != "" {
+		upload.HTTPHeaders = &blob.HTTPHeaders{BlobContentType: &contentType}
+	}
+	_, err = client.Upload(context.Background(), streaming.NopCloser(bytes.NewReader(body)), upload)
+	var responseErr *azcore.ResponseError
+	if errors.As(err, &responseErr) && responseErr.RawResponse != nil {
+		message, _ := runtime.Payload(responseErr.RawResponse)
+		if len(message) > 512 {
+			message = message[:512]
+		}
+		return fmt.Errorf("failed to upload %s: %s %s", location, responseErr.RawResponse.Status, strings.TrimSpace(string(message)))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", location, err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+// Package objectstore reaches files in object stores such as S3 over plain HTTP
+package objectstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// S3Credentials sign requests to S3 or an S3-compatible store. Empty fields fall back to the
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN and AWS_REGION environment
+// variables; requests without an access key are sent unsigned, for public buckets.
+type S3Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+	Region          string // defaults to us-east-1
+	// Endpoint is the base URL of an S3-compatible store such as MinIO, addressed path-style
+	// (endpoint/bucket/key); AWS buckets are addressed as bucket.s3.region.amazonaws.com
+	Endpoint string
+}
+
+// withDefaults fills in the credentials the environment provides
+func (c S3Credentials) withDefaults() S3Credentials {
+	fallback := func(value *string, names ...string) {
+		for _, name := range names {
+			if *value == "" {
+				*value = os.Getenv(name)
+			}
+		}
+	}
+	fallback(&c.AccessKeyID, "AWS_ACCESS_KEY_ID")
+	fallback(&c.SecretAccessKey, "AWS_SECRET_ACCESS_KEY")
+	fallback(&c.SessionToken, "AWS_SESSION_TOKEN")
+	fallback(&c.Region, "AWS_REGION", "AWS_DEFAULT_REGION")
+	if c.Region == "" {
+		c.Region = "us-east-1"
+	}
+	return c
+}
+
+// IsS3URL reports whether a location is an s3://bucket/key URL
+func IsS3URL(location string) bool {
+	return strings.HasPrefix(location, "s3://")
+}
+
+// NewS3Request builds a signed request for the object at an s3://bucket/key URL
+func NewS3Request(method, location string, body []byte, credentials S3Credentials) (*http.Request, error) {
+	parsed, err := url.Parse(location)
+	if err != nil || parsed.Scheme != "s3" || parsed.Host == "" || strings.Trim(parsed.Path, "/") == "" {
+		return nil, fmt.Errorf("invalid S3 URL %q, expected s3://bucket/key", location)
+	}
+	credentials = credentials.withDefaults()
+
+	bucket, key := parsed.Host, strings.TrimPrefix(parsed.Path, "/")
+	var target string
+	if credentials.Endpoint != "" {
+		target = strings.TrimSuffix(credentials.Endpoint, "/") + "/" + bucket + "/" + escapePath(key)
+	} else {
+		target = fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucket, credentials.Region, escapePath(key))
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = strings.NewReader(string(body))
+	}
+	req, err := http.NewRequest(method, target, reader)
+	if err != nil {
+		return nil, err
+	}
+	if credentials.AccessKeyID != "" {
+		if err := SignS3(req, body, credentials, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+	return req, nil
+}
+
+// SignS3 adds an AWS Signature Version 4 to a request for the s3 service with the AWS SDK
+// signer. The headers already set on the request are signed along with host,
+// x-amz-date and x-amz-content-sha256.
+func SignS3(req *http.Request, body []byte, credentials S3Credentials, now time.Time) error {
+	payloadHash := sha256Hex(body)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	awsCredentials := aws.Credentials{
+		AccessKeyID:     credentials.AccessKeyID,
+		SecretAccessKey: credentials.SecretAccessKey,
+		SessionToken:    credentials.SessionToken,
+	}
+	// S3 signs the path as sent instead of escaping it a second time
+	err := v4.NewSigner().SignHTTP(context.Background(), awsCredentials, req, payloadHash, "s3", credentials.Region, now,
+		func(options *v4.SignerOptions) { options.DisableURIPathEscaping = true })
+	if err != nil {
+		return fmt.Errorf("failed to sign S3 request: %w", err)
+	}
+	return nil
+}
+
+// escapePath encodes a path the way SigV4 expects: everything but unreserved characters and
+// slashes
+func escapePath(path string) string {
+	return escape(path, true)
+}
+
+func escape(value string, keepSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if (c == '/' && keepSlash) || c == '-' || c == '_' || c == '.' || c == '~' ||
+			('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,68 @@
+package objectstore
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Credentials authorize requests to each supported store
+type Credentials struct {
+	S3    S3Credentials
+	GCS   GCSCredentials
+	Azure AzureCredentials
+}
+
+// IsURL reports whether a location is an s3://, gs:// or azblob:// URL
+func IsURL(location string) bool {
+	return IsS3URL(location) || IsGCSURL(location) || IsAzureURL(location)
+}
+
+// Client sends the requests of Put; it times out after a minute
+var Client = &http.Client{Timeout: time.Minute}
+
+// Put uploads body to the object at an s3://bucket/key, gs://bucket/key or
+// azblob://container/blob URL, replacing any object already there
+func Put(location string, body []byte, contentType string, credentials Credentials) error {
+	var req *http.Request
+	var err error
+	switch {
+	case IsS3URL(location):
+		req, err = NewS3Request(http.MethodPut, location, body, credentials.S3)
+	case IsGCSURL(location):
+		req, err = newGCSUpload(location, body, contentType, credentials.GCS)
+	case IsAzureURL(location):
+		return uploadAzure(location, body, contentType, credentials.Azure)
+	default:
+		err = fmt.Errorf("unsupported object store URL %q, expected s3://, gs:// or azblob://", location)
+	}
+	if err != nil {
+		return err
+	}
+	if contentType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", location, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to upload %s: %s %s", location, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// splitURL returns the bucket (or container) and key of a scheme://bucket/key URL
+func splitURL(location, scheme string) (string, string, error) {
+	rest := strings.TrimPrefix(location, scheme+"://")
+	bucket, key, _ := strings.Cut(rest, "/")
+	if rest == location || bucket == "" || key == "" {
+		return "", "", fmt.Errorf("invalid URL %q, expected %s://bucket/key", location, scheme)
+	}
+	return bucket, key, nil
+}
@@ -0,0 +1,241 @@
+package openapi
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+)
+
+// Drift kinds reported by CheckContract
+const (
+	DriftUnknownPath      = "unknown-path"
+	DriftUnknownMethod    = "unknown-method"
+	DriftUnknownParameter = "unknown-parameter"
+	DriftUndocumentedCode = "undocumented-status"
+)
+
+// Drift is a place where a scenario and the spec disagree
+type Drift struct {
+	Kind     string `json:"kind"`
+	Scenario string `json:"scenario"`
+	Step     string `json:"step"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message"`
+}
+
+// String renders a drift as file:line: scenario / step: message
+func (d Drift) String() string {
+	location := d.File
+	if d.Line > 0 {
+		location = fmt.Sprintf("%s:%d", d.File, d.Line)
+	}
+	if location != "" {
+		location += ": "
+	}
+	return fmt.Sprintf("%s%s / %s: %s", location, d.Scenario, d.Step, d.Message)
+}
+
+// CheckContract cross-references the HTTP steps of scenarios with a spec without running them:
+// paths and methods the spec does not have, query parameters it does not declare, and status
+// codes asserted with eq that the operation does not document. Templated path segments
+// ({{order_id}}, :id, {id}) match any parameter of the spec, and a leading {{base_url}} is
+// ignored.
+func CheckContract(spec *Spec, scenarios []*scenario.Scenario) []Drift {
+	var drifts []Drift
+	for _, sc := range scenarios {
+		for _, step := range contractSteps(sc) {
+			method, rawURL, query := requestOf(step)
+			if rawURL == "" {
+				continue
+			}
+			drift := func(kind, format string, args ...interface{}) {
+				drifts = append(drifts, Drift{Kind: kind, Scenario: sc.Name, Step: step.Name, File: sc.SourceFile, Line: step.Line, Message: fmt.Sprintf(format, args...)})
+			}
+
+			path, urlQuery := templatePath(rawURL)
+			endpoint, found := spec.Match(method, path)
+			if !found {
+				drift(DriftUnknownPath, "%s %s matches no path of the spec", method, displayPath(path))
+				continue
+			}
+			if endpoint.Operation == nil {
+				drift(DriftUnknownMethod, "%s is not documented for %s", method, endpoint.Path)
+				continue
+			}
+
+			declared := make(map[string]bool)
+			for _, parameter := range endpoint.Parameters {
+				if parameter.In == "query" {
+					declared[parameter.Name] = true
+				}
+			}
+			for _, name := range sortedNames(query, urlQuery) {
+				if !declared[name] {
+					drift(DriftUnknownParameter, "query parameter %s is not a parameter of %s", name, endpoint.Name())
+				}
+			}
+
+			for _, code := range assertedStatuses(step) {
+				if !documented(endpoint.Operation, code) {
+					drift(DriftUndocumentedCode, "status %d is asserted but %s does not document it", code, endpoint.Name())
+				}
+			}
+		}
+	}
+	return drifts
+}
+
+// contractSteps lists every step of a scenario including branches and cleanups
+func contractSteps(sc *scenario.Scenario) []*scenario.Step {
+	var steps []*scenario.Step
+	var add func(list []scenario.Step)
+	add = func(list []scenario.Step) {
+		for i := range list {
+			step := &list[i]
+			steps = append(steps, step)
+			add(step.OnFailure)
+			add(step.OnSuccess)
+			if step.Cleanup != nil {
+				add([]scenario.Step{*step.Cleanup})
+			}
+		}
+	}
+	addGroup := func(group *scenario.TestGroup) {
+		if group != nil {
+			add(group.Steps)
+			add(group.OnFailure)
+			add(group.OnSuccess)
+		}
+	}
+
+	addGroup(sc.Before)
+	add(sc.Setup)
+	add(sc.Steps)
+	names := make([]string, 0, len(sc.Tests))
+	for name := range sc.Tests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		addGroup(sc.Tests[name])
+	}
+	add(sc.Teardown)
+	addGroup(sc.After)
+	return steps
+}
+
+// requestOf returns the method, URL and query parameters of an HTTP step in either format
+func requestOf(step *scenario.Step) (string, string, map[string]string) {
+	method, rawURL, query := "", "", map[string]string(nil)
+	switch {
+	case step.HTTP != nil:
+		method, rawURL, query = step.HTTP.Method, step.HTTP.URL, step.HTTP.Query
+	case step.Type == "http":
+		method, rawURL, query = step.Request.Method, step.Request.URL, step.Request.Query
+	}
+	if method == "" {
+		method = "GET"
+	}
+	return strings.ToUpper(method), rawURL, query
+}
+
+// templatePath returns the path of a step URL with templated segments replaced by anySegment,
+// and the names of the query parameters written into the URL
+func templatePath(rawURL string) (string, map[string]string) {
+	path, query := requestPath(rawURL)
+	segments := splitPath(path)
+	for i, segment := range segments {
+		if strings.Contains(segment, "{{") || strings.HasPrefix(segment, ":") || isParameterSegment(segment) {
+			segments[i] = anySegment
+		}
+	}
+	return "/" + strings.Join(segments, "/"), query
+}
+
+// requestPath returns the path of a URL without scheme, host or a leading {{base_url}}, and the
+// names of its query parameters
+func requestPath(rawURL string) (string, map[string]string) {
+	rawURL, rawQuery, _ := strings.Cut(rawURL, "?")
+	query := make(map[string]string)
+	if values, err := url.ParseQuery(rawQuery); err == nil {
+		for name := range values {
+			query[name] = ""
+		}
+	}
+
+	path := rawURL
+	if _, rest, found := strings.Cut(rawURL, "://"); found {
+		path = "/"
+		if slash := strings.Index(rest, "/"); slash >= 0 {
+			path = rest[slash:]
+		}
+	} else if strings.HasPrefix(rawURL, "{{") {
+		// A leading {{base_url}} holds scheme, host and base path
+		path = "/"
+		if end := strings.Index(rawURL, "}}"); end >= 0 {
+			path = "/" + strings.TrimPrefix(rawURL[end+2:], "/")
+		}
+	}
+	return "/" + strings.Trim(path, "/"), query
+}
+
+func displayPath(path string) string {
+	return strings.ReplaceAll(path, anySegment, "{…}")
+}
+
+func sortedNames(maps ...map[string]string) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, values := range maps {
+		for name, value := range values {
+			if !seen[name] && value != scenario.Unset {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// assertedStatuses returns the status codes a step expects with equality checks
+func assertedStatuses(step *scenario.Step) []int {
+	var codes []int
+	add := func(value interface{}) {
+		switch code := value.(type) {
+		case int:
+			codes = append(codes, code)
+		case float64:
+			codes = append(codes, int(code))
+		}
+	}
+	add(step.Check["status"])
+	if step.HTTP != nil {
+		add(step.HTTP.Check["status"])
+	}
+	for _, assertion := range step.Assertions {
+		if assertion.Type != "status" && assertion.Type != "status_code" {
+			continue
+		}
+		switch assertion.Operator {
+		case "", "eq", "equals", "==":
+			add(assertion.Value)
+		}
+	}
+	return codes
+}
+
+// documented reports whether an operation lists a status code, its range (4XX) or a default
+func documented(operation *Operation, code int) bool {
+	for key := range operation.Responses {
+		key = strings.ToUpper(key)
+		if key == "DEFAULT" || key == fmt.Sprint(code) || key == fmt.Sprintf("%dXX", code/100) {
+			return true
+		}
+	}
+	return false
+}
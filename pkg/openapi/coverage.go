@@ -0,0 +1,137 @@
+package openapi
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Coverage counts the requests of a run per operation of a spec. Without a spec it counts them
+// per method and URL as written in the scenarios, such as GET /orders/{{order_id}}. It is safe
+// for concurrent use.
+type Coverage struct {
+	spec      *Spec
+	mu        sync.Mutex
+	hits      map[string]*OperationCoverage
+	unmatched map[string]int
+}
+
+// OperationCoverage is the requests a run sent to one operation
+type OperationCoverage struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	OperationID string `json:"operation_id,omitempty"`
+	Requests    int    `json:"requests"`
+	Statuses    []int  `json:"statuses,omitempty"` // distinct response statuses, sorted
+}
+
+// Name is the operation ID, or the method and path when the operation has none
+func (o OperationCoverage) Name() string {
+	if o.OperationID != "" {
+		return o.OperationID
+	}
+	return o.Method + " " + o.Path
+}
+
+// CoverageReport summarizes which operations a run exercised
+type CoverageReport struct {
+	Spec       bool                `json:"spec"` // false when operations are the URLs of the scenarios
+	Total      int                 `json:"total"`
+	Covered    int                 `json:"covered"`
+	Percent    float64             `json:"percent"`
+	Operations []OperationCoverage `json:"operations"`
+	Untested   []string            `json:"untested,omitempty"`
+	Unmatched  []string            `json:"unmatched,omitempty"` // requests to paths the spec does not have
+}
+
+// NewCoverage starts counting requests against spec, which may be nil
+func NewCoverage(spec *Spec) *Coverage {
+	return &Coverage{spec: spec, hits: make(map[string]*OperationCoverage), unmatched: make(map[string]int)}
+}
+
+// Record counts a request. With a spec, rawURL is the URL that was sent; without one it is the
+// URL of the step as written.
+func (c *Coverage) Record(method, rawURL string, status int) {
+	method = strings.ToUpper(method)
+	if method == "" {
+		method = "GET"
+	}
+
+	operation := OperationCoverage{Method: method}
+	if c.spec == nil {
+		operation.Path, _ = requestPath(rawURL)
+	} else {
+		path, _ := templatePath(rawURL)
+		endpoint, found := c.spec.Match(method, path)
+		if !found || endpoint.Operation == nil {
+			c.mu.Lock()
+			c.unmatched[method+" "+displayPath(path)]++
+			c.mu.Unlock()
+			return
+		}
+		operation.Path, operation.OperationID = endpoint.Path, endpoint.Operation.OperationID
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := operation.Method + " " + operation.Path
+	hit := c.hits[key]
+	if hit == nil {
+		hit = &operation
+		c.hits[key] = hit
+	}
+	hit.Requests++
+	if status > 0 {
+		at := sort.SearchInts(hit.Statuses, status)
+		if at == len(hit.Statuses) || hit.Statuses[at] != status {
+			hit.Statuses = append(hit.Statuses[:at], append([]int{status}, hit.Statuses[at:]...)...)
+		}
+	}
+}
+
+// Report lists every operation with its requests, the operations no request reached and the
+// requests that matched no operation
+func (c *Coverage) Report() CoverageReport {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	report := CoverageReport{Spec: c.spec != nil}
+	if c.spec != nil {
+		for _, endpoint := range c.spec.Endpoints() {
+			operation := OperationCoverage{Method: endpoint.Method, Path: endpoint.Path, OperationID: endpoint.Operation.OperationID}
+			if hit := c.hits[endpoint.Method+" "+endpoint.Path]; hit != nil {
+				operation = *hit
+			} else {
+				report.Untested = append(report.Untested, endpoint.Name())
+			}
+			report.Operations = append(report.Operations, operation)
+		}
+	} else {
+		for _, hit := range c.hits {
+			report.Operations = append(report.Operations, *hit)
+		}
+		sort.Slice(report.Operations, func(i, j int) bool {
+			a, b := report.Operations[i], report.Operations[j]
+			if a.Path != b.Path {
+				return a.Path < b.Path
+			}
+			return a.Method < b.Method
+		})
+	}
+
+	for request := range c.unmatched {
+		report.Unmatched = append(report.Unmatched, request)
+	}
+	sort.Strings(report.Unmatched)
+
+	report.Total = len(report.Operations)
+	for _, operation := range report.Operations {
+		if operation.Requests > 0 {
+			report.Covered++
+		}
+	}
+	if report.Total > 0 {
+		report.Percent = float64(report.Covered) * 100 / float64(report.Total)
+	}
+	return report
+}
@@ -0,0 +1,90 @@
+package openapi
+
+import (
+	"net/url"
+	"strings"
+)
+
+// Match finds the endpoint a request path belongs to. Path segments of the spec written as
+// {param} match any value; literal segments win over parameters, so /orders/latest matches
+// /orders/latest before /orders/{id}. Paths may carry the path of a server URL (/v1/orders for
+// a server https://api.example.com/v1). It returns false when no path matches; when the path
+// matches but the method does not, the returned endpoint has a nil Operation.
+func (s *Spec) Match(method, path string) (Endpoint, bool) {
+	method = strings.ToUpper(method)
+	for _, candidate := range s.candidatePaths(path) {
+		template, found := s.matchPath(candidate)
+		if !found {
+			continue
+		}
+		for _, endpoint := range s.Endpoints() {
+			if endpoint.Path == template && endpoint.Method == method {
+				return endpoint, true
+			}
+		}
+		return Endpoint{Method: method, Path: template}, true
+	}
+	return Endpoint{}, false
+}
+
+// candidatePaths is the path itself followed by the path without each server's base path
+func (s *Spec) candidatePaths(path string) []string {
+	candidates := []string{path}
+	for _, server := range s.Servers {
+		parsed, err := url.Parse(server.URL)
+		if err != nil {
+			continue
+		}
+		base := strings.TrimSuffix(parsed.Path, "/")
+		if base != "" && strings.HasPrefix(path, base+"/") {
+			candidates = append(candidates, strings.TrimPrefix(path, base))
+		}
+	}
+	return candidates
+}
+
+// matchPath returns the spec path whose segments fit path best: literal segments that are
+// equal, and parameters for the values only known at run time
+func (s *Spec) matchPath(path string) (string, bool) {
+	segments := splitPath(path)
+	best, bestLiterals := "", -1
+	for template := range s.Paths {
+		templateSegments := splitPath(template)
+		if len(templateSegments) != len(segments) {
+			continue
+		}
+		literals := 0
+		matched := true
+		for i, segment := range templateSegments {
+			switch {
+			case isParameterSegment(segment):
+				if segments[i] == anySegment {
+					literals++
+				}
+			case segment == segments[i]:
+				literals++
+			case segments[i] == anySegment:
+			default:
+				matched = false
+			}
+			if !matched {
+				break
+			}
+		}
+		if matched && (literals > bestLiterals || (literals == bestLiterals && template < best)) {
+			best, bestLiterals = template, literals
+		}
+	}
+	return best, bestLiterals >= 0
+}
+
+// anySegment stands for a path segment whose value is only known at run time
+const anySegment = "\x00"
+
+func splitPath(path string) []string {
+	return strings.Split(strings.Trim(path, "/"), "/")
+}
+
+func isParameterSegment(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
@@ -0,0 +1,312 @@
+package openapi
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/nulln0ne/fuego/pkg/scenariobuilder"
+)
+
+// NegativeCase is a request to an endpoint that is valid except for one broken constraint
+type NegativeCase struct {
+	Name       string
+	PathParams map[string]string
+	Query      map[string]string
+	Headers    map[string]string
+	Body       interface{} // nil sends no body
+}
+
+// invalid is a value that breaks a constraint of a schema
+type invalid struct {
+	reason string
+	value  interface{}
+}
+
+// NegativeCases derives requests that an endpoint must reject from its parameters and JSON body:
+// missing required values, wrong types, out-of-range numbers and lengths, values outside an enum
+// and malformed formats. Path parameters are never left out, as that changes the path.
+func NegativeCases(endpoint Endpoint) []NegativeCase {
+	valid := NegativeCase{PathParams: map[string]string{}, Query: map[string]string{}, Headers: map[string]string{}}
+	var parameters []*Parameter
+	for _, parameter := range endpoint.Parameters {
+		target := valid.params(parameter.In)
+		if target == nil {
+			continue // cookie parameters
+		}
+		parameters = append(parameters, parameter)
+		if parameter.Required || parameter.In == "path" {
+			target[parameter.Name] = fmt.Sprint(Example(parameter.Schema))
+		}
+	}
+	bodySchema := endpoint.Operation.JSONBody()
+	if bodySchema != nil {
+		valid.Body = Example(bodySchema)
+	}
+
+	var cases []NegativeCase
+	for _, parameter := range parameters {
+		label := parameter.In + " parameter " + parameter.Name
+		if parameter.Required && parameter.In != "path" {
+			broken := valid.clone()
+			delete(broken.params(parameter.In), parameter.Name)
+			broken.Name = "missing required " + label
+			cases = append(cases, broken)
+		}
+		for _, bad := range invalidValues(parameter.Schema, true) {
+			broken := valid.clone()
+			broken.params(parameter.In)[parameter.Name] = fmt.Sprint(bad.value)
+			broken.Name = label + ": " + bad.reason
+			cases = append(cases, broken)
+		}
+	}
+
+	if bodySchema != nil {
+		if endpoint.Operation.RequestBody.Required {
+			broken := valid.clone()
+			broken.Body = nil
+			broken.Name = "missing required body"
+			cases = append(cases, broken)
+		}
+		for _, bad := range invalidBodies(bodySchema, valid.Body, "body", 0) {
+			broken := valid.clone()
+			broken.Body = bad.value
+			broken.Name = bad.reason
+			cases = append(cases, broken)
+		}
+	}
+	return cases
+}
+
+func (c *NegativeCase) params(in string) map[string]string {
+	switch in {
+	case "path":
+		return c.PathParams
+	case "query":
+		return c.Query
+	case "header":
+		return c.Headers
+	}
+	return nil
+}
+
+func (c NegativeCase) clone() NegativeCase {
+	cloned := c
+	cloned.PathParams, cloned.Query, cloned.Headers = copyParams(c.PathParams), copyParams(c.Query), copyParams(c.Headers)
+	return cloned
+}
+
+func copyParams(params map[string]string) map[string]string {
+	copied := make(map[string]string, len(params))
+	for key, value := range params {
+		copied[key] = value
+	}
+	return copied
+}
+
+// invalidBodies breaks the body at path one property at a time, returning whole bodies. valid is
+// the valid value at this point of the body.
+func invalidBodies(schema *Schema, valid interface{}, path string, depth int) []invalid {
+	schema = flatten(schema)
+	var cases []invalid
+	for _, bad := range invalidValues(schema, false) {
+		cases = append(cases, invalid{reason: path + ": " + bad.reason, value: bad.value})
+	}
+	// A null body would be no body at all, so only properties are set to null
+	if depth > 0 && typeOf(schema) != "" && !schema.Nullable && !schema.Type.Is("null") {
+		cases = append(cases, invalid{reason: path + ": null although not nullable", value: nil})
+	}
+
+	if depth > 3 {
+		return cases
+	}
+
+	// Items are broken through the first one
+	if list, isList := valid.([]interface{}); isList && len(list) > 0 && schema.Items != nil {
+		for _, bad := range invalidBodies(schema.Items, list[0], path+".0", depth+1) {
+			with := append([]interface{}(nil), list...)
+			with[0] = bad.value
+			cases = append(cases, invalid{reason: bad.reason, value: with})
+		}
+		return cases
+	}
+
+	object, isObject := valid.(map[string]interface{})
+	if !isObject {
+		return cases
+	}
+
+	names := make([]string, 0, len(schema.Properties))
+	for name := range schema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	required := make(map[string]bool)
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	for _, name := range names {
+		property := schema.Properties[name]
+		if property.ReadOnly {
+			continue
+		}
+		if required[name] {
+			without := copyObject(object)
+			delete(without, name)
+			cases = append(cases, invalid{reason: path + ": missing required " + name, value: without})
+		}
+
+		// Optional properties are broken without their nested properties
+		value, present := object[name]
+		if !present {
+			value = nil
+		}
+		for _, bad := range invalidBodies(property, value, path+"."+name, depth+1) {
+			with := copyObject(object)
+			with[name] = bad.value
+			cases = append(cases, invalid{reason: bad.reason, value: with})
+		}
+	}
+	return cases
+}
+
+func copyObject(object map[string]interface{}) map[string]interface{} {
+	copied := make(map[string]interface{}, len(object))
+	for key, value := range object {
+		copied[key] = value
+	}
+	return copied
+}
+
+// invalidValues lists values that break one constraint of a schema. Parameters travel as text,
+// so a string parameter cannot have the wrong type.
+func invalidValues(schema *Schema, parameter bool) []invalid {
+	schema = flatten(schema)
+	var cases []invalid
+	kind := typeOf(schema)
+
+	switch kind {
+	case "integer":
+		cases = append(cases, invalid{"wrong type (text instead of integer)", "not-a-number"}, invalid{"not an integer", 1.5})
+	case "number":
+		cases = append(cases, invalid{"wrong type (text instead of number)", "not-a-number"})
+	case "boolean":
+		cases = append(cases, invalid{"wrong type (text instead of boolean)", "not-a-boolean"})
+	case "string":
+		if !parameter {
+			cases = append(cases, invalid{"wrong type (number instead of string)", 12345})
+		}
+	case "object":
+		cases = append(cases, invalid{"wrong type (text instead of object)", "not-an-object"})
+	case "array":
+		cases = append(cases, invalid{"wrong type (text instead of array)", "not-an-array"})
+	}
+	step := 1.0
+	if kind == "number" {
+		step = 0.5
+	}
+	if schema.Minimum != nil {
+		cases = append(cases, invalid{fmt.Sprintf("below minimum %v", *schema.Minimum), number(*schema.Minimum-step, kind)})
+	}
+	if schema.Maximum != nil {
+		cases = append(cases, invalid{fmt.Sprintf("above maximum %v", *schema.Maximum), number(*schema.Maximum+step, kind)})
+	}
+
+	if kind == "string" {
+		if schema.MinLength != nil && *schema.MinLength > 0 {
+			cases = append(cases, invalid{fmt.Sprintf("shorter than %d characters", *schema.MinLength), strings.Repeat("x", *schema.MinLength-1)})
+		}
+		if schema.MaxLength != nil {
+			cases = append(cases, invalid{fmt.Sprintf("longer than %d characters", *schema.MaxLength), strings.Repeat("x", *schema.MaxLength+1)})
+		}
+		if len(schema.Enum) > 0 && !inEnum(schema.Enum, "not-in-enum") {
+			cases = append(cases, invalid{"not one of the enum values", "not-in-enum"})
+		}
+		switch schema.Format {
+		case "uuid", "date", "date-time", "email":
+			cases = append(cases, invalid{"malformed " + schema.Format, "not-a-" + schema.Format})
+		}
+	}
+
+	if kind == "array" && !parameter {
+		item := Example(schema.Items)
+		if schema.MinItems != nil && *schema.MinItems > 0 {
+			cases = append(cases, invalid{fmt.Sprintf("fewer than %d items", *schema.MinItems), repeatItem(item, *schema.MinItems-1)})
+		}
+		if schema.MaxItems != nil {
+			cases = append(cases, invalid{fmt.Sprintf("more than %d items", *schema.MaxItems), repeatItem(item, *schema.MaxItems+1)})
+		}
+	}
+	return cases
+}
+
+func number(value float64, kind string) interface{} {
+	if kind == "integer" && value == math.Trunc(value) {
+		return int64(value)
+	}
+	return value
+}
+
+func inEnum(enum []interface{}, value string) bool {
+	for _, allowed := range enum {
+		if fmt.Sprint(allowed) == value {
+			return true
+		}
+	}
+	return false
+}
+
+func repeatItem(item interface{}, count int) []interface{} {
+	items := make([]interface{}, count)
+	for i := range items {
+		items[i] = item
+	}
+	return items
+}
+
+// NegativeScenario builds a scenario with one step per negative case of an endpoint, each
+// expecting a 4xx response. baseURL is put in front of the spec path; leave it empty to use the
+// base_url of the config. It returns nil when the endpoint has nothing to break.
+func NegativeScenario(endpoint Endpoint, baseURL string) (*scenario.Scenario, error) {
+	cases := NegativeCases(endpoint)
+	if len(cases) == 0 {
+		return nil, nil
+	}
+
+	builder := scenariobuilder.NewScenario().
+		Name("Negative: "+endpoint.Name()).
+		Description(fmt.Sprintf("Generated from the OpenAPI constraints of %s %s; every request breaks one constraint and must be rejected with 4xx", endpoint.Method, endpoint.Path)).
+		Tags("negative", "generated")
+	for _, negative := range cases {
+		builder.Step(negative.Name).HTTP(endpoint.Method, strings.TrimSuffix(baseURL, "/")+endpoint.Path)
+		for _, name := range sortedKeys(negative.PathParams) {
+			builder.PathParam(name, negative.PathParams[name])
+		}
+		for _, name := range sortedKeys(negative.Query) {
+			builder.Query(name, negative.Query[name])
+		}
+		for _, name := range sortedKeys(negative.Headers) {
+			builder.Header(name, negative.Headers[name])
+		}
+		if negative.Body != nil {
+			builder.JSONBody(negative.Body)
+		}
+		builder.Check(
+			scenariobuilder.Assertion(scenario.Assertion{Type: "status", Operator: "gte", Value: 400}),
+			scenariobuilder.Assertion(scenario.Assertion{Type: "status", Operator: "lt", Value: 500}),
+		)
+	}
+	return builder.Build()
+}
+
+func sortedKeys(params map[string]string) []string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,137 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+)
+
+// resolve replaces every reference with the component it points at, so the rest of the package
+// never sees $ref. Recursive schemas become cyclic pointers.
+func (s *Spec) resolve() error {
+	r := &resolver{spec: s, seen: make(map[*Schema]bool)}
+	for _, schema := range s.Components.Schemas {
+		if err := r.schema(&schema); err != nil {
+			return err
+		}
+	}
+	for path, item := range s.Paths {
+		if item == nil {
+			return fmt.Errorf("path %s has no operations", path)
+		}
+		if err := r.parameters(item.Parameters); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		for _, operation := range []*Operation{item.Get, item.Put, item.Post, item.Delete, item.Options, item.Head, item.Patch, item.Trace} {
+			if operation == nil {
+				continue
+			}
+			if err := r.parameters(operation.Parameters); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			if err := r.requestBody(&operation.RequestBody); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+		}
+	}
+	return nil
+}
+
+type resolver struct {
+	spec *Spec
+	seen map[*Schema]bool
+}
+
+// component looks up a local reference such as #/components/schemas/Order
+func component[T any](ref, kind string, components map[string]*T) (*T, error) {
+	prefix := "#/components/" + kind + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		return nil, fmt.Errorf("unsupported reference %s, only #/components/%s/... is", ref, kind)
+	}
+	name := strings.NewReplacer("~1", "/", "~0", "~").Replace(strings.TrimPrefix(ref, prefix))
+	found, exists := components[name]
+	if !exists || found == nil {
+		return nil, fmt.Errorf("reference %s points at nothing", ref)
+	}
+	return found, nil
+}
+
+func (r *resolver) parameters(parameters []*Parameter) error {
+	for i, parameter := range parameters {
+		for depth := 0; parameter.Ref != ""; depth++ {
+			if depth > 32 {
+				return fmt.Errorf("reference %s loops", parameter.Ref)
+			}
+			found, err := component(parameter.Ref, "parameters", r.spec.Components.Parameters)
+			if err != nil {
+				return err
+			}
+			parameter = found
+		}
+		parameters[i] = parameter
+		if err := r.schema(&parameter.Schema); err != nil {
+			return fmt.Errorf("parameter %s: %w", parameter.Name, err)
+		}
+	}
+	return nil
+}
+
+func (r *resolver) requestBody(body **RequestBody) error {
+	for depth := 0; *body != nil && (*body).Ref != ""; depth++ {
+		if depth > 32 {
+			return fmt.Errorf("reference %s loops", (*body).Ref)
+		}
+		found, err := component((*body).Ref, "requestBodies", r.spec.Components.RequestBodies)
+		if err != nil {
+			return err
+		}
+		*body = found
+	}
+	if *body == nil {
+		return nil
+	}
+	for _, content := range (*body).Content {
+		if content == nil {
+			continue
+		}
+		if err := r.schema(&content.Schema); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *resolver) schema(schema **Schema) error {
+	for depth := 0; *schema != nil && (*schema).Ref != ""; depth++ {
+		if depth > 32 {
+			return fmt.Errorf("reference %s loops", (*schema).Ref)
+		}
+		found, err := component((*schema).Ref, "schemas", r.spec.Components.Schemas)
+		if err != nil {
+			return err
+		}
+		*schema = found
+	}
+	if *schema == nil || r.seen[*schema] {
+		return nil
+	}
+	r.seen[*schema] = true
+
+	current := *schema
+	for name, property := range current.Properties {
+		if err := r.schema(&property); err != nil {
+			return err
+		}
+		current.Properties[name] = property
+	}
+	if err := r.schema(&current.Items); err != nil {
+		return err
+	}
+	for _, list := range [][]*Schema{current.AllOf, current.OneOf, current.AnyOf} {
+		for i := range list {
+			if err := r.schema(&list[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
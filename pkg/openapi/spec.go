@@ -0,0 +1,245 @@
+// Package openapi reads OpenAPI 3 specifications (YAML or JSON) far enough to test against them:
+// paths, operations, parameters, JSON request bodies, response codes and the schemas they use.
+// References are resolved within the document; references to other files are not supported.
+package openapi
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Spec is an OpenAPI document
+type Spec struct {
+	OpenAPI    string               `yaml:"openapi"`
+	Servers    []Server             `yaml:"servers"`
+	Paths      map[string]*PathItem `yaml:"paths"`
+	Components Components           `yaml:"components"`
+}
+
+// Server is a base URL the paths are relative to
+type Server struct {
+	URL string `yaml:"url"`
+}
+
+// Components holds the reusable definitions references point at
+type Components struct {
+	Schemas       map[string]*Schema      `yaml:"schemas"`
+	Parameters    map[string]*Parameter   `yaml:"parameters"`
+	RequestBodies map[string]*RequestBody `yaml:"requestBodies"`
+}
+
+// PathItem holds the operations of a path
+type PathItem struct {
+	Parameters []*Parameter `yaml:"parameters"`
+	Get        *Operation   `yaml:"get"`
+	Put        *Operation   `yaml:"put"`
+	Post       *Operation   `yaml:"post"`
+	Delete     *Operation   `yaml:"delete"`
+	Options    *Operation   `yaml:"options"`
+	Head       *Operation   `yaml:"head"`
+	Patch      *Operation   `yaml:"patch"`
+	Trace      *Operation   `yaml:"trace"`
+}
+
+// Operation is one method of a path
+type Operation struct {
+	OperationID string                 `yaml:"operationId"`
+	Summary     string                 `yaml:"summary"`
+	Parameters  []*Parameter           `yaml:"parameters"`
+	RequestBody *RequestBody           `yaml:"requestBody"`
+	Responses   map[string]interface{} `yaml:"responses"` // keyed by status code, range (4XX) or default
+}
+
+// Parameter is a path, query, header or cookie parameter
+type Parameter struct {
+	Ref      string  `yaml:"$ref"`
+	Name     string  `yaml:"name"`
+	In       string  `yaml:"in"`
+	Required bool    `yaml:"required"`
+	Schema   *Schema `yaml:"schema"`
+}
+
+// RequestBody is the body of an operation by media type
+type RequestBody struct {
+	Ref      string                `yaml:"$ref"`
+	Required bool                  `yaml:"required"`
+	Content  map[string]*MediaType `yaml:"content"`
+}
+
+// MediaType is the schema of one media type of a body
+type MediaType struct {
+	Schema *Schema `yaml:"schema"`
+}
+
+// Schema is the subset of JSON Schema that describes and constrains values
+type Schema struct {
+	Ref        string             `yaml:"$ref"`
+	Type       Types              `yaml:"type"`
+	Format     string             `yaml:"format"`
+	Properties map[string]*Schema `yaml:"properties"`
+	Required   []string           `yaml:"required"`
+	Items      *Schema            `yaml:"items"`
+	AllOf      []*Schema          `yaml:"allOf"`
+	OneOf      []*Schema          `yaml:"oneOf"`
+	AnyOf      []*Schema          `yaml:"anyOf"`
+	Enum       []interface{}      `yaml:"enum"`
+	Minimum    *float64           `yaml:"minimum"`
+	Maximum    *float64           `yaml:"maximum"`
+	MinLength  *int               `yaml:"minLength"`
+	MaxLength  *int               `yaml:"maxLength"`
+	MinItems   *int               `yaml:"minItems"`
+	MaxItems   *int               `yaml:"maxItems"`
+	Pattern    string             `yaml:"pattern"`
+	Nullable   bool               `yaml:"nullable"`
+	Example    interface{}        `yaml:"example"`
+	Default    interface{}        `yaml:"default"`
+	ReadOnly   bool               `yaml:"readOnly"`
+}
+
+// Types is the type of a schema: one name in OpenAPI 3.0, a list such as [string, "null"] in 3.1
+type Types []string
+
+// UnmarshalYAML accepts a single type name or a list
+func (t *Types) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*t = Types{node.Value}
+		return nil
+	}
+	var types []string
+	if err := node.Decode(&types); err != nil {
+		return err
+	}
+	*t = types
+	return nil
+}
+
+// Is reports whether the schema allows a type
+func (t Types) Is(name string) bool {
+	for _, candidate := range t {
+		if candidate == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Primary is the type of a schema other than null, or "" when it has none
+func (t Types) Primary() string {
+	for _, candidate := range t {
+		if candidate != "null" {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// Load reads an OpenAPI 3 document and resolves its references
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read spec %s: %w", path, err)
+	}
+	spec, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// Parse reads an OpenAPI 3 document from YAML or JSON and resolves its references
+func Parse(data []byte) (*Spec, error) {
+	var spec Spec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		return nil, fmt.Errorf("only OpenAPI 3 documents are supported, found openapi: %q", spec.OpenAPI)
+	}
+	if err := spec.resolve(); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// Endpoint is an operation with the parameters of its path merged in
+type Endpoint struct {
+	Method     string // upper case
+	Path       string // as written in the spec, e.g. /orders/{id}
+	Operation  *Operation
+	Parameters []*Parameter
+}
+
+// Name identifies the endpoint by operationId, or by method and path
+func (e Endpoint) Name() string {
+	if e.Operation.OperationID != "" {
+		return e.Operation.OperationID
+	}
+	return e.Method + " " + e.Path
+}
+
+// Endpoints lists every operation, sorted by path and method
+func (s *Spec) Endpoints() []Endpoint {
+	paths := make([]string, 0, len(s.Paths))
+	for path := range s.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var endpoints []Endpoint
+	for _, path := range paths {
+		item := s.Paths[path]
+		for _, method := range []struct {
+			name      string
+			operation *Operation
+		}{
+			{"GET", item.Get}, {"PUT", item.Put}, {"POST", item.Post}, {"DELETE", item.Delete},
+			{"OPTIONS", item.Options}, {"HEAD", item.Head}, {"PATCH", item.Patch}, {"TRACE", item.Trace},
+		} {
+			if method.operation == nil {
+				continue
+			}
+			endpoints = append(endpoints, Endpoint{
+				Method:     method.name,
+				Path:       path,
+				Operation:  method.operation,
+				Parameters: mergeParameters(item.Parameters, method.operation.Parameters),
+			})
+		}
+	}
+	return endpoints
+}
+
+// mergeParameters lets operation parameters override path parameters of the same name and location
+func mergeParameters(pathParameters, operationParameters []*Parameter) []*Parameter {
+	merged := append([]*Parameter(nil), operationParameters...)
+	for _, parameter := range pathParameters {
+		overridden := false
+		for _, own := range operationParameters {
+			if own.Name == parameter.Name && own.In == parameter.In {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			merged = append(merged, parameter)
+		}
+	}
+	return merged
+}
+
+// JSONBody returns the schema of the JSON request body of an operation, or nil
+func (o *Operation) JSONBody() *Schema {
+	if o.RequestBody == nil {
+		return nil
+	}
+	for mediaType, content := range o.RequestBody.Content {
+		if (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) && content.Schema != nil {
+			return content.Schema
+		}
+	}
+	return nil
+}
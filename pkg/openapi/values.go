@@ -0,0 +1,162 @@
+package openapi
+
+import (
+	"strings"
+)
+
+// flatten merges the allOf parts of a schema into one; oneOf and anyOf are represented by their
+// first alternative
+func flatten(schema *Schema) *Schema {
+	if schema == nil {
+		return &Schema{}
+	}
+	if len(schema.AllOf) == 0 && len(schema.OneOf) == 0 && len(schema.AnyOf) == 0 {
+		return schema
+	}
+
+	merged := *schema
+	merged.AllOf, merged.OneOf, merged.AnyOf = nil, nil, nil
+	merged.Properties = make(map[string]*Schema, len(schema.Properties))
+	for name, property := range schema.Properties {
+		merged.Properties[name] = property
+	}
+	merged.Required = append([]string(nil), schema.Required...)
+
+	parts := schema.AllOf
+	if len(schema.OneOf) > 0 {
+		parts = append(parts, schema.OneOf[0])
+	}
+	if len(schema.AnyOf) > 0 {
+		parts = append(parts, schema.AnyOf[0])
+	}
+	for _, part := range parts {
+		part = flatten(part)
+		if len(merged.Type) == 0 {
+			merged.Type = part.Type
+		}
+		for name, property := range part.Properties {
+			merged.Properties[name] = property
+		}
+		merged.Required = append(merged.Required, part.Required...)
+		if merged.Items == nil {
+			merged.Items = part.Items
+		}
+	}
+	if len(merged.Properties) == 0 {
+		merged.Properties = nil
+	}
+	return &merged
+}
+
+// typeOf is the type of a schema, inferred from its properties or items when not declared
+func typeOf(schema *Schema) string {
+	if primary := schema.Type.Primary(); primary != "" {
+		return primary
+	}
+	switch {
+	case schema.Properties != nil:
+		return "object"
+	case schema.Items != nil:
+		return "array"
+	}
+	return ""
+}
+
+// Example returns a value that satisfies a schema: its example, default or first enum value when
+// given, otherwise a value built from its type, format and bounds. Objects hold their required
+// properties only, without read-only ones.
+func Example(schema *Schema) interface{} {
+	return example(schema, 0)
+}
+
+func example(schema *Schema, depth int) interface{} {
+	schema = flatten(schema)
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	}
+
+	switch typeOf(schema) {
+	case "object":
+		object := make(map[string]interface{})
+		if depth > 8 {
+			return object
+		}
+		for _, name := range schema.Required {
+			property, exists := schema.Properties[name]
+			switch {
+			case !exists:
+				object[name] = "value"
+			case !property.ReadOnly:
+				object[name] = example(property, depth+1)
+			}
+		}
+		return object
+	case "array":
+		count := 1
+		if schema.MinItems != nil && *schema.MinItems > count {
+			count = *schema.MinItems
+		}
+		if depth > 8 || (schema.MaxItems != nil && *schema.MaxItems == 0) {
+			count = 0
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			items[i] = example(schema.Items, depth+1)
+		}
+		return items
+	case "integer":
+		return int64(numberInRange(schema, 1))
+	case "number":
+		return numberInRange(schema, 1.5)
+	case "boolean":
+		return true
+	case "string":
+		return exampleString(schema)
+	}
+	return "value"
+}
+
+func numberInRange(schema *Schema, preferred float64) float64 {
+	value := preferred
+	if schema.Minimum != nil && value < *schema.Minimum {
+		value = *schema.Minimum
+	}
+	if schema.Maximum != nil && value > *schema.Maximum {
+		value = *schema.Maximum
+	}
+	return value
+}
+
+func exampleString(schema *Schema) string {
+	var value string
+	switch schema.Format {
+	case "uuid":
+		value = "3fa85f64-5717-4562-b3fc-2c963f66afa6"
+	case "date":
+		value = "2024-01-15"
+	case "date-time":
+		value = "2024-01-15T10:30:00Z"
+	case "email":
+		value = "user@example.com"
+	case "uri", "url":
+		value = "https://example.com"
+	case "ipv4":
+		value = "192.0.2.1"
+	case "ipv6":
+		value = "2001:db8::1"
+	default:
+		value = "string"
+	}
+	if schema.MinLength != nil && len(value) < *schema.MinLength {
+		value += strings.Repeat("x", *schema.MinLength-len(value))
+	}
+	if schema.MaxLength != nil && len(value) > *schema.MaxLength {
+		value = value[:*schema.MaxLength]
+	}
+	return value
+}
@@ -0,0 +1,157 @@
+package protobuf
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// Decode decodes a binary message into a map keyed by field name, shaped like the JSON of the
+// message: enums are names, bytes are base64 and fields that are not set have their default value.
+// Unknown fields, wire types that do not match the definition and invalid UTF-8 in strings are
+// errors, so a successful decode means the payload matches the definition.
+func (s *Schema) Decode(message *Message, data []byte) (map[string]interface{}, error) {
+	decoded := dynamicpb.NewMessage(message.desc)
+	if err := proto.Unmarshal(data, decoded); err != nil {
+		return nil, fmt.Errorf("%s: %w", message.Name, err)
+	}
+	if err := checkUnknown(decoded); err != nil {
+		return nil, err
+	}
+	return messageValue(decoded), nil
+}
+
+// UnframeGRPC returns the message of a unary gRPC or gRPC-Web response body, which is prefixed
+// with a compression flag and a length
+func UnframeGRPC(body []byte) ([]byte, error) {
+	if len(body) < 5 {
+		return nil, fmt.Errorf("gRPC frame is %d bytes, shorter than its 5 byte header", len(body))
+	}
+	if body[0] != 0 {
+		return nil, fmt.Errorf("compressed gRPC messages are not supported")
+	}
+	length := binary.BigEndian.Uint32(body[1:5])
+	if uint64(len(body)-5) < uint64(length) {
+		return nil, fmt.Errorf("gRPC frame announces %d bytes but holds %d", length, len(body)-5)
+	}
+	return body[5 : 5+length], nil
+}
+
+// checkUnknown rejects the fields the unmarshaler kept as unknown: field numbers the definition
+// does not have, and fields whose wire type does not match their type
+func checkUnknown(message protoreflect.Message) error {
+	desc := message.Descriptor()
+	if unknown := message.GetUnknown(); len(unknown) > 0 {
+		number, wireType, n := protowire.ConsumeTag(unknown)
+		if n < 0 {
+			return fmt.Errorf("%s: invalid field key", desc.FullName())
+		}
+		if field := desc.Fields().ByNumber(number); field != nil {
+			return fmt.Errorf("%s: wire type %d does not match type %s", field.FullName(), wireType, typeName(field))
+		}
+		return fmt.Errorf("%s: unknown field number %d", desc.FullName(), number)
+	}
+
+	var err error
+	message.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		switch {
+		case field.IsMap():
+			if isMessage(field.MapValue()) {
+				value.Map().Range(func(_ protoreflect.MapKey, entry protoreflect.Value) bool {
+					err = checkUnknown(entry.Message())
+					return err == nil
+				})
+			}
+		case field.IsList():
+			if isMessage(field) {
+				list := value.List()
+				for i := 0; i < list.Len() && err == nil; i++ {
+					err = checkUnknown(list.Get(i).Message())
+				}
+			}
+		case isMessage(field):
+			err = checkUnknown(value.Message())
+		}
+		return err == nil
+	})
+	return err
+}
+
+// messageValue converts a message to a map; fields that are not set get their default value,
+// except messages and oneof members
+func messageValue(message protoreflect.Message) map[string]interface{} {
+	decoded := make(map[string]interface{})
+	fields := message.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		field := fields.Get(i)
+		if !message.Has(field) && (field.ContainingOneof() != nil || (isMessage(field) && !field.IsList() && !field.IsMap())) {
+			continue
+		}
+		value := message.Get(field)
+		switch {
+		case field.IsMap():
+			entries := make(map[string]interface{})
+			value.Map().Range(func(key protoreflect.MapKey, entry protoreflect.Value) bool {
+				entries[key.String()] = singularValue(field.MapValue(), entry)
+				return true
+			})
+			decoded[string(field.Name())] = entries
+		case field.IsList():
+			list := value.List()
+			elements := make([]interface{}, 0, list.Len())
+			for j := 0; j < list.Len(); j++ {
+				elements = append(elements, singularValue(field, list.Get(j)))
+			}
+			decoded[string(field.Name())] = elements
+		default:
+			decoded[string(field.Name())] = singularValue(field, value)
+		}
+	}
+	return decoded
+}
+
+func singularValue(field protoreflect.FieldDescriptor, value protoreflect.Value) interface{} {
+	switch field.Kind() {
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return value.Int()
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind, protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return value.Uint()
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return value.Float()
+	case protoreflect.BoolKind:
+		return value.Bool()
+	case protoreflect.StringKind:
+		return value.String()
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(value.Bytes())
+	case protoreflect.EnumKind:
+		// Values missing from the definition are kept as numbers, like open enums
+		if enumValue := field.Enum().Values().ByNumber(value.Enum()); enumValue != nil {
+			return string(enumValue.Name())
+		}
+		return int64(value.Enum())
+	default:
+		return messageValue(value.Message())
+	}
+}
+
+func isMessage(field protoreflect.FieldDescriptor) bool {
+	return field.Kind() == protoreflect.MessageKind || field.Kind() == protoreflect.GroupKind
+}
+
+// typeName is the type of a field as written in a .proto file
+func typeName(field protoreflect.FieldDescriptor) string {
+	switch {
+	case isMessage(field):
+		return string(field.Message().FullName())
+	case field.Kind() == protoreflect.EnumKind:
+		return string(field.Enum().FullName())
+	}
+	return field.Kind().String()
+}
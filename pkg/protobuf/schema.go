@@ -0,0 +1,91 @@
+// Package protobuf decodes binary protobuf messages against the message definitions of .proto
+// files, without generated code
+package protobuf
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Schema holds the messages of a .proto file and the files it imports
+type Schema struct {
+	messages map[string]*Message
+}
+
+// Message is a message definition; Name is fully qualified (package.Outer.Inner)
+type Message struct {
+	Name string
+	desc protoreflect.MessageDescriptor
+}
+
+// Load compiles a .proto file and the files it imports, relative to its directory. The
+// google/protobuf well-known types are built in.
+func Load(path string) (*Schema, error) {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			ImportPaths: []string{filepath.Dir(path)},
+		}),
+	}
+	files, err := compiler.Compile(context.Background(), filepath.Base(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+
+	schema := &Schema{messages: make(map[string]*Message)}
+	seen := make(map[string]bool)
+	for _, file := range files {
+		schema.addFile(file, seen)
+	}
+	return schema, nil
+}
+
+// Message returns a message definition by fully qualified name, or by short name when that is
+// unambiguous
+func (s *Schema) Message(name string) (*Message, error) {
+	name = strings.TrimPrefix(name, ".")
+	if message, exists := s.messages[name]; exists {
+		return message, nil
+	}
+
+	var found *Message
+	for fullName, message := range s.messages {
+		if strings.HasSuffix(fullName, "."+name) {
+			if found != nil {
+				return nil, fmt.Errorf("message %s is ambiguous, use its full name", name)
+			}
+			found = message
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("message %s is not defined", name)
+	}
+	return found, nil
+}
+
+func (s *Schema) addFile(file protoreflect.FileDescriptor, seen map[string]bool) {
+	if seen[file.Path()] {
+		return
+	}
+	seen[file.Path()] = true
+	s.addMessages(file.Messages())
+	imports := file.Imports()
+	for i := 0; i < imports.Len(); i++ {
+		s.addFile(imports.Get(i).FileDescriptor, seen)
+	}
+}
+
+func (s *Schema) addMessages(messages protoreflect.MessageDescriptors) {
+	for i := 0; i < messages.Len(); i++ {
+		desc := messages.Get(i)
+		if desc.IsMapEntry() {
+			continue
+		}
+		s.messages[string(desc.FullName())] = &Message{Name: string(desc.FullName()), desc: desc}
+		s.addMessages(desc.Messages())
+	}
+}
@@ -0,0 +1,34 @@
+package protocols
+
+import (
+	"crypto/tls"
+	"time"
+)
+
+// Certificate describes the certificate a server presented during the TLS handshake
+type Certificate struct {
+	Subject   string    `json:"subject"`
+	Issuer    string    `json:"issuer"`
+	SANs      []string  `json:"sans"` // DNS names and IP addresses
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+}
+
+// leafCertificate returns the server's own certificate, or nil for plain HTTP
+func leafCertificate(state *tls.ConnectionState) *Certificate {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	leaf := state.PeerCertificates[0]
+	certificate := &Certificate{
+		Subject:   leaf.Subject.String(),
+		Issuer:    leaf.Issuer.String(),
+		SANs:      append([]string(nil), leaf.DNSNames...),
+		NotBefore: leaf.NotBefore,
+		NotAfter:  leaf.NotAfter,
+	}
+	for _, ip := range leaf.IPAddresses {
+		certificate.SANs = append(certificate.SANs, ip.String())
+	}
+	return certificate
+}
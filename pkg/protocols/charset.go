@@ -0,0 +1,72 @@
+package protocols
+
+import (
+	"bytes"
+	"mime"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/encoding/unicode"
+)
+
+// decodeBody converts a response body to UTF-8 text according to the charset declared in its
+// Content-Type, or its byte order mark when none is declared. It returns the text and the
+// charset: the declared one in lower case, or the detected one ("utf-8" for bodies without a BOM).
+// Bodies in an unknown charset, or that fail to decode, are returned unchanged.
+func decodeBody(body []byte, contentType string) (string, string) {
+	name := strings.ToLower(declaredCharset(contentType))
+	if name == "" {
+		name = sniffCharset(body)
+	}
+
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return string(body), name
+	}
+	if canonical, _ := htmlindex.Name(enc); canonical == "utf-8" {
+		return string(bytes.TrimPrefix(body, utf8BOM)), name
+	}
+
+	decoded, err := decoderFor(enc).Bytes(body)
+	if err != nil {
+		return string(body), name
+	}
+	return string(decoded), name
+}
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+func declaredCharset(contentType string) string {
+	if contentType == "" {
+		return ""
+	}
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return strings.Trim(strings.TrimSpace(params["charset"]), `"'`)
+}
+
+// sniffCharset detects UTF-16 from a byte order mark; everything else is treated as UTF-8
+func sniffCharset(body []byte) string {
+	switch {
+	case bytes.HasPrefix(body, []byte{0xFE, 0xFF}):
+		return "utf-16be"
+	case bytes.HasPrefix(body, []byte{0xFF, 0xFE}):
+		return "utf-16le"
+	}
+	return "utf-8"
+}
+
+// decoderFor honours a byte order mark on UTF-16 bodies, which override the declared endianness
+func decoderFor(enc encoding.Encoding) *encoding.Decoder {
+	name, _ := htmlindex.Name(enc)
+	switch name {
+	case "utf-16be":
+		return unicode.UTF16(unicode.BigEndian, unicode.UseBOM).NewDecoder()
+	case "utf-16le":
+		return unicode.UTF16(unicode.LittleEndian, unicode.UseBOM).NewDecoder()
+	}
+	return enc.NewDecoder()
+}
@@ -0,0 +1,77 @@
+package protocols
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+)
+
+// Curl renders an interpolated HTTP step as an equivalent curl command line
+func (c *HTTPClient) Curl(step *scenario.Step) (string, error) {
+	return c.curl(step, false)
+}
+
+// RedactedCurl renders the step like Curl with credentials masked as in --trace output, for
+// commands that end up in reports
+func (c *HTTPClient) RedactedCurl(step *scenario.Step) (string, error) {
+	return c.curl(step, true)
+}
+
+func (c *HTTPClient) curl(step *scenario.Step, redact bool) (string, error) {
+	req, err := c.buildRequest(step, false)
+	if err != nil {
+		return "", fmt.Errorf("failed to build request: %w", err)
+	}
+
+	parts := []string{"curl"}
+	if !c.verifySSL {
+		parts = append(parts, "-k")
+	}
+	if c.followRedirects {
+		parts = append(parts, "-L")
+	}
+	if req.Method != "GET" || req.Body != nil {
+		parts = append(parts, "-X", req.Method)
+	}
+	target := req.URL.String()
+	if redact {
+		target = Redact(target)
+	}
+	parts = append(parts, shellQuote(target))
+
+	headerNames := make([]string, 0, len(req.Header))
+	for name := range req.Header {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+	for _, name := range headerNames {
+		for _, value := range req.Header[name] {
+			if redact && sensitiveName.MatchString(name) {
+				value = redacted
+			}
+			parts = append(parts, "-H", shellQuote(name+": "+value))
+		}
+	}
+
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return "", fmt.Errorf("failed to read request body: %w", err)
+		}
+		data := string(body)
+		if redact {
+			data = Redact(data)
+		}
+		parts = append(parts, "--data-raw", shellQuote(data))
+	}
+
+	return strings.Join(parts, " "), nil
+}
+
+// shellQuote wraps a value in single quotes, escaping embedded single quotes for POSIX shells
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
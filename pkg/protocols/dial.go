@@ -0,0 +1,103 @@
+package protocols
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// unixScheme addresses a unix domain socket: unix:///var/run/app.sock:/v1/status sends
+// GET /v1/status over /var/run/app.sock. On Windows the socket can be unix://C:\run\app.sock.
+const unixScheme = "unix://"
+
+// IsAbsoluteURL reports whether a step URL names its own target rather than a path relative to
+// a base URL
+func IsAbsoluteURL(rawURL string) bool {
+	return strings.HasPrefix(rawURL, "http://") || strings.HasPrefix(rawURL, "https://") ||
+		strings.HasPrefix(rawURL, unixScheme)
+}
+
+// JoinURL resolves a relative step URL against a base URL; a unix socket base is separated from
+// the HTTP path by a colon
+func JoinURL(base, path string) string {
+	if strings.HasPrefix(base, unixScheme) {
+		if _, _, found := splitUnixSocket(strings.TrimPrefix(base, unixScheme)); !found {
+			return base + ":/" + strings.TrimPrefix(path, "/")
+		}
+	}
+	return strings.TrimSuffix(base, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// dialer connects to TCP addresses, restricted to one IP version when network is tcp4 or tcp6,
+// and to the unix sockets that unix:// URLs were rewritten to
+type dialer struct {
+	net.Dialer
+	network string
+	sockets sync.Map // host standing in for the socket -> socket path
+}
+
+func newDialer(ipVersion int) *dialer {
+	d := &dialer{Dialer: net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}}
+	switch ipVersion {
+	case 4:
+		d.network = "tcp4"
+	case 6:
+		d.network = "tcp6"
+	}
+	return d
+}
+
+func (d *dialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		if socket, ok := d.sockets.Load(host); ok {
+			return d.Dialer.DialContext(ctx, "unix", socket.(string))
+		}
+	}
+	if d.network != "" && strings.HasPrefix(network, "tcp") {
+		network = d.network
+	}
+	return d.Dialer.DialContext(ctx, network, addr)
+}
+
+// rewriteUnixURL turns unix:///path/app.sock:/http/path into an http URL whose host stands in
+// for the socket, so connections to different sockets are pooled separately
+func (d *dialer) rewriteUnixURL(rawURL string) (string, error) {
+	socket, path, found := splitUnixSocket(strings.TrimPrefix(rawURL, unixScheme))
+	if socket == "" {
+		return "", fmt.Errorf("unix URL %s has no socket path", rawURL)
+	}
+	if !found || path == "" {
+		path = "/"
+	}
+	if !strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("unix URL %s: the HTTP path after the socket must start with /", rawURL)
+	}
+
+	hash := fnv.New32a()
+	hash.Write([]byte(socket))
+	host := fmt.Sprintf("unix-%08x.sock", hash.Sum32())
+	d.sockets.Store(host, socket)
+
+	u, err := url.Parse("http://" + host + path)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL: %w", err)
+	}
+	return u.String(), nil
+}
+
+// splitUnixSocket separates the socket of a unix URL from the HTTP path. The colon of a Windows
+// drive letter (C:\run\app.sock or C:/run/app.sock) belongs to the socket.
+func splitUnixSocket(rest string) (socket, path string, found bool) {
+	drive := 0
+	if len(rest) >= 3 && rest[1] == ':' && (rest[2] == '\\' || rest[2] == '/') &&
+		('a' <= rest[0] && rest[0] <= 'z' || 'A' <= rest[0] && rest[0] <= 'Z') {
+		drive = 2
+	}
+	socket, path, found = strings.Cut(rest[drive:], ":")
+	return rest[:drive] + socket, path, found
+}
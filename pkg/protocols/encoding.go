@@ -0,0 +1,68 @@
+package protocols
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// requestCompression asks for gzip when the request does not choose its own encodings, as the
+// transport would; the client decodes responses itself so the encoded size stays known
+func requestCompression(req *http.Request) {
+	if req.Header.Get("Accept-Encoding") == "" && req.Header.Get("Range") == "" && req.Method != http.MethodHead {
+		req.Header.Set("Accept-Encoding", "gzip")
+	}
+}
+
+// decompressBody undoes the gzip and deflate codings of a response body, listed in the order
+// they were applied. Bodies in other codings, such as br, are returned as they are.
+func decompressBody(body []byte, contentEncoding string) ([]byte, error) {
+	codings := strings.Split(contentEncoding, ",")
+	for i := len(codings) - 1; i >= 0; i-- {
+		coding := strings.ToLower(strings.TrimSpace(codings[i]))
+		var err error
+		switch coding {
+		case "", "identity":
+			continue
+		case "gzip", "x-gzip":
+			body, err = gunzip(body)
+		case "deflate":
+			body, err = inflate(body)
+		default:
+			return body, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s body: %w", coding, err)
+		}
+	}
+	return body, nil
+}
+
+func gunzip(body []byte) ([]byte, error) {
+	if len(body) == 0 {
+		return body, nil
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// inflate reads zlib-wrapped deflate as the RFC asks, and raw deflate as some servers send it
+func inflate(body []byte) ([]byte, error) {
+	if len(body) == 0 {
+		return body, nil
+	}
+	if reader, err := zlib.NewReader(bytes.NewReader(body)); err == nil {
+		defer reader.Close()
+		return io.ReadAll(reader)
+	}
+	return io.ReadAll(flate.NewReader(bytes.NewReader(body)))
+}
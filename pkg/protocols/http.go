@@ -2,6 +2,7 @@ package protocols
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"encoding/json"
 	"fmt"
@@ -15,30 +16,54 @@ import (
 )
 
 type HTTPClient struct {
-	client  *http.Client
-	baseURL string
-	headers map[string]string
+	client          *http.Client
+	dialer          *dialer
+	baseURL         string
+	headers         map[string]string
+	query           map[string]string
+	verifySSL       bool
+	followRedirects bool
+	middleware      []string
+	counters        clientCounters
 }
 
 type HTTPResponse struct {
 	StatusCode int                 `json:"status_code"`
 	Headers    map[string][]string `json:"headers"`
 	Body       []byte              `json:"body"`
-	BodyText   string              `json:"body_text"`
+	BodyText   string              `json:"body_text"` // decoded to UTF-8 from the response charset
+	Charset    string              `json:"charset"`
 	Duration   time.Duration       `json:"duration"`
-	Size       int64               `json:"size"`
+	Size       int64               `json:"size"` // of the body after decompression
+	// ContentEncoding and EncodedSize describe the body as it was sent; EncodedSize equals Size
+	// for bodies that were not compressed
+	ContentEncoding string `json:"content_encoding,omitempty"`
+	EncodedSize     int64  `json:"encoded_size"`
+	// Certificate is the server certificate of HTTPS responses
+	Certificate *Certificate `json:"certificate,omitempty"`
+	// Stream describes how the body arrived when the step reads it as a stream
+	Stream *StreamStats `json:"stream,omitempty"`
 }
 
 func NewHTTPClient(config HTTPClientConfig) *HTTPClient {
+	dialer := newDialer(config.IPVersion)
 	transport := &http.Transport{
+		DialContext:        dialer.DialContext,
+		DisableCompression: true,
 		TLSClientConfig: &tls.Config{
 			InsecureSkipVerify: !config.VerifySSL,
 		},
 	}
 
+	var roundTripper http.RoundTripper = transport
+	if config.Trace != nil {
+		roundTripper = &traceTransport{next: transport, out: config.Trace}
+	}
+	roundTripper = newResilientTransport(roundTripper, config.NetworkRetries, config.NetworkRetryMethods, config.RetryDelay, config.CircuitBreaker)
+
 	client := &http.Client{
 		Timeout:   config.Timeout,
-		Transport: transport,
+		Transport: roundTripper,
 	}
 
 	if !config.FollowRedirects {
@@ -48,18 +73,39 @@ func NewHTTPClient(config HTTPClientConfig) *HTTPClient {
 	}
 
 	return &HTTPClient{
-		client:  client,
-		baseURL: config.BaseURL,
-		headers: config.Headers,
+		client:          client,
+		dialer:          dialer,
+		baseURL:         config.BaseURL,
+		headers:         config.Headers,
+		query:           config.Query,
+		verifySSL:       config.VerifySSL,
+		followRedirects: config.FollowRedirects,
+		middleware:      config.Middleware,
 	}
 }
 
 type HTTPClientConfig struct {
-	BaseURL         string
-	Headers         map[string]string
+	BaseURL string
+	Headers map[string]string
+	// Query parameters are added to every request unless the URL or step sets them
+	Query           map[string]string
 	Timeout         time.Duration
 	VerifySSL       bool
 	FollowRedirects bool
+	// Trace receives a redacted dump of every request and response when set
+	Trace io.Writer
+	// Middleware names the registered request middleware run on every request, before the
+	// step's own
+	Middleware []string
+	// NetworkRetries resends requests that failed with a connection error (refused, reset,
+	// timed out) when their method is in NetworkRetryMethods (idempotent methods by default),
+	// waiting RetryDelay in between
+	NetworkRetries      int
+	NetworkRetryMethods []string
+	RetryDelay          time.Duration
+	CircuitBreaker      CircuitBreakerConfig
+	// IPVersion restricts connections to IPv4 (4) or IPv6 (6); either is used when 0
+	IPVersion int
 }
 
 func (c *HTTPClient) Execute(step *scenario.Step) (*HTTPResponse, error) {
@@ -70,54 +116,103 @@ func (c *HTTPClient) Execute(step *scenario.Step) (*HTTPResponse, error) {
 	startTime := time.Now()
 
 	// Build request
-	req, err := c.buildRequest(step)
+	req, err := c.buildRequest(step, true)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build request: %w", err)
 	}
 
+	if step.Timeout > 0 {
+		ctx, cancel := context.WithTimeout(req.Context(), step.Timeout)
+		defer cancel()
+		req = req.WithContext(ctx)
+	}
+
 	// Execute request
-	resp, err := c.client.Do(req)
+	resp, err := c.client.Do(c.countRequest(req))
 	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
+		return nil, NewTransportError(fmt.Errorf("failed to execute request: %w", err))
 	}
 	defer resp.Body.Close()
 
 	duration := time.Since(startTime)
 
 	// Read response body
-	body, err := io.ReadAll(resp.Body)
+	var body []byte
+	var stream *StreamStats
+	if step.Request.Stream != nil {
+		body, stream, err = readStream(resp.Body, step.Request.Stream, startTime)
+	} else {
+		body, err = io.ReadAll(resp.Body)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, NewTransportError(fmt.Errorf("failed to read response body: %w", err))
 	}
 
+	c.counters.bytesReceived.Add(int64(len(body)))
+	encodedSize := int64(len(body))
+	contentEncoding := resp.Header.Get("Content-Encoding")
+	body, err = decompressBody(body, contentEncoding)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress response body: %w", err)
+	}
+	bodyText, charset := decodeBody(body, resp.Header.Get("Content-Type"))
+
 	httpResp := &HTTPResponse{
-		StatusCode: resp.StatusCode,
-		Headers:    resp.Header,
-		Body:       body,
-		BodyText:   string(body),
-		Duration:   duration,
-		Size:       int64(len(body)),
+		StatusCode:      resp.StatusCode,
+		Headers:         resp.Header,
+		Body:            body,
+		BodyText:        bodyText,
+		Charset:         charset,
+		Duration:        duration,
+		Size:            int64(len(body)),
+		ContentEncoding: contentEncoding,
+		EncodedSize:     encodedSize,
+		Certificate:     leafCertificate(resp.TLS),
+		Stream:          stream,
 	}
 
 	return httpResp, nil
 }
 
-func (c *HTTPClient) buildRequest(step *scenario.Step) (*http.Request, error) {
+// buildRequest builds the request of a step; compress asks for gzip like the transport would,
+// before middleware runs so a signature covers the header too
+func (c *HTTPClient) buildRequest(step *scenario.Step, compress bool) (*http.Request, error) {
 	// Build URL
 	requestURL := step.Request.URL
-	if !strings.HasPrefix(requestURL, "http") && c.baseURL != "" {
-		requestURL = strings.TrimSuffix(c.baseURL, "/") + "/" + strings.TrimPrefix(requestURL, "/")
+	if !IsAbsoluteURL(requestURL) && c.baseURL != "" {
+		requestURL = JoinURL(c.baseURL, requestURL)
+	}
+	unixSocket := strings.HasPrefix(requestURL, unixScheme)
+	if unixSocket {
+		rewritten, err := c.dialer.rewriteUnixURL(requestURL)
+		if err != nil {
+			return nil, err
+		}
+		requestURL = rewritten
 	}
 
 	// Add query parameters
-	if len(step.Request.Query) > 0 {
+	if len(step.Request.Query) > 0 || len(c.query) > 0 {
 		u, err := url.Parse(requestURL)
 		if err != nil {
 			return nil, fmt.Errorf("invalid URL: %w", err)
 		}
 
 		q := u.Query()
+		for key, value := range c.query {
+			if _, inURL := q[key]; inURL {
+				continue
+			}
+			if _, inStep := step.Request.Query[key]; inStep {
+				continue
+			}
+			q.Set(key, value)
+		}
 		for key, value := range step.Request.Query {
+			if value == scenario.Unset {
+				q.Del(key)
+				continue
+			}
 			q.Add(key, value)
 		}
 		u.RawQuery = q.Encode()
@@ -126,8 +221,10 @@ func (c *HTTPClient) buildRequest(step *scenario.Step) (*http.Request, error) {
 
 	// Build request body
 	var body io.Reader
+	var bodyBytes []byte
 	if step.Request.Body != nil {
-		bodyBytes, err := c.buildRequestBody(step.Request.Body)
+		var err error
+		bodyBytes, err = c.buildRequestBody(step.Request.Body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to build request body: %w", err)
 		}
@@ -140,6 +237,10 @@ func (c *HTTPClient) buildRequest(step *scenario.Step) (*http.Request, error) {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	if unixSocket {
+		req.Host = "localhost"
+	}
+
 	// Add headers
 	c.addHeaders(req, step)
 
@@ -156,6 +257,16 @@ func (c *HTTPClient) buildRequest(step *scenario.Step) (*http.Request, error) {
 		})
 	}
 
+	if compress {
+		requestCompression(req)
+	}
+
+	// Middleware sees the finished request, so signatures cover every header and the body
+	middleware := append(append([]string(nil), c.middleware...), step.Request.Middleware...)
+	if err := applyMiddleware(req, bodyBytes, middleware); err != nil {
+		return nil, err
+	}
+
 	return req, nil
 }
 
@@ -178,8 +289,12 @@ func (c *HTTPClient) addHeaders(req *http.Request, step *scenario.Step) {
 		req.Header.Set(key, value)
 	}
 
-	// Add step-specific headers
+	// Add step-specific headers; null values remove a global header
 	for key, value := range step.Request.Headers {
+		if value == scenario.Unset {
+			req.Header.Del(key)
+			continue
+		}
 		req.Header.Set(key, value)
 	}
 
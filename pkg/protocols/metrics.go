@@ -0,0 +1,50 @@
+package protocols
+
+import (
+	"net/http"
+	"net/http/httptrace"
+	"sync/atomic"
+)
+
+// ClientMetrics counts the traffic of an HTTP client. Bytes are request and response bodies;
+// connections are counted per attempt, so network retries open or reuse one each.
+type ClientMetrics struct {
+	Requests          int64
+	BytesSent         int64
+	BytesReceived     int64
+	NewConnections    int64
+	ReusedConnections int64
+}
+
+// clientCounters are updated concurrently by parallel steps
+type clientCounters struct {
+	requests, bytesSent, bytesReceived, newConnections, reusedConnections atomic.Int64
+}
+
+// Metrics returns the traffic of the client so far
+func (c *HTTPClient) Metrics() ClientMetrics {
+	return ClientMetrics{
+		Requests:          c.counters.requests.Load(),
+		BytesSent:         c.counters.bytesSent.Load(),
+		BytesReceived:     c.counters.bytesReceived.Load(),
+		NewConnections:    c.counters.newConnections.Load(),
+		ReusedConnections: c.counters.reusedConnections.Load(),
+	}
+}
+
+// countRequest records a request about to be sent and traces which connections it gets
+func (c *HTTPClient) countRequest(req *http.Request) *http.Request {
+	c.counters.requests.Add(1)
+	if req.ContentLength > 0 {
+		c.counters.bytesSent.Add(req.ContentLength)
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				c.counters.reusedConnections.Add(1)
+			} else {
+				c.counters.newConnections.Add(1)
+			}
+		},
+	}))
+}
@@ -0,0 +1,59 @@
+package protocols
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+// RequestMiddleware changes an outgoing HTTP request right before it is sent, e.g. to add a
+// signature or checksum header computed from the body. body holds the bytes the request will
+// send; a middleware that replaces the body sets req.Body and req.ContentLength itself.
+type RequestMiddleware interface {
+	Handle(req *http.Request, body []byte) error
+}
+
+// RequestMiddlewareFunc adapts a function to the RequestMiddleware interface
+type RequestMiddlewareFunc func(req *http.Request, body []byte) error
+
+func (f RequestMiddlewareFunc) Handle(req *http.Request, body []byte) error {
+	return f(req, body)
+}
+
+var (
+	middlewareMu sync.RWMutex
+	middleware   = make(map[string]RequestMiddleware)
+)
+
+// RegisterRequestMiddleware adds a middleware that requests opt into by name, through
+// global.middleware or a service in the config, or `middleware` on a step
+func RegisterRequestMiddleware(name string, m RequestMiddleware) error {
+	if name == "" || m == nil {
+		return fmt.Errorf("middleware name and implementation are required")
+	}
+
+	middlewareMu.Lock()
+	defer middlewareMu.Unlock()
+	if _, exists := middleware[name]; exists {
+		return fmt.Errorf("request middleware %s is already registered", name)
+	}
+	middleware[name] = m
+
+	return nil
+}
+
+// applyMiddleware runs the named middleware in order, stopping at the first error
+func applyMiddleware(req *http.Request, body []byte, names []string) error {
+	for _, name := range names {
+		middlewareMu.RLock()
+		m, exists := middleware[name]
+		middlewareMu.RUnlock()
+		if !exists {
+			return fmt.Errorf("unknown request middleware %s", name)
+		}
+		if err := m.Handle(req, body); err != nil {
+			return fmt.Errorf("request middleware %s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package protocols
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+)
+
+// StepExecutor runs steps of a custom type. The returned response should use the same keys as HTTP
+// responses where they apply (status_code, headers, body, body_text, charset, duration, size) so checks,
+// assertions and captures work on it unchanged.
+type StepExecutor interface {
+	Execute(ctx context.Context, step *scenario.Step) (map[string]interface{}, error)
+}
+
+// StepExecutorFunc adapts a function to the StepExecutor interface
+type StepExecutorFunc func(ctx context.Context, step *scenario.Step) (map[string]interface{}, error)
+
+func (f StepExecutorFunc) Execute(ctx context.Context, step *scenario.Step) (map[string]interface{}, error) {
+	return f(ctx, step)
+}
+
+var (
+	stepTypesMu sync.RWMutex
+	stepTypes   = make(map[string]StepExecutor)
+)
+
+// RegisterStepType adds an executor for steps with the given `type`. The step's `config` block is
+// interpolated before the executor is called. The built-in http type cannot be replaced.
+func RegisterStepType(name string, executor StepExecutor) error {
+	if name == "" || executor == nil {
+		return fmt.Errorf("step type name and executor are required")
+	}
+	if name == "http" {
+		return fmt.Errorf("step type %s is built in", name)
+	}
+
+	stepTypesMu.Lock()
+	defer stepTypesMu.Unlock()
+	if _, exists := stepTypes[name]; exists {
+		return fmt.Errorf("step type %s is already registered", name)
+	}
+	stepTypes[name] = executor
+	scenario.AllowStepType(name)
+
+	return nil
+}
+
+// LookupStepType returns the executor registered for a step type
+func LookupStepType(name string) (StepExecutor, bool) {
+	stepTypesMu.RLock()
+	defer stepTypesMu.RUnlock()
+	executor, exists := stepTypes[name]
+	return executor, exists
+}
@@ -0,0 +1,142 @@
+package protocols
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrHostUnavailable is returned without sending when the circuit breaker of a host is open
+var ErrHostUnavailable = errors.New("host unavailable")
+
+// idempotentMethods are retried after network errors unless other methods are configured
+var idempotentMethods = []string{"GET", "HEAD", "OPTIONS", "PUT", "DELETE", "TRACE"}
+
+// CircuitBreakerConfig short-circuits requests to a host after consecutive connection failures
+type CircuitBreakerConfig struct {
+	// Failures is the number of consecutive failed requests that opens the circuit; 0 disables it
+	Failures int
+	// Cooldown is how long an open circuit rejects requests before one is let through to probe
+	// the host again; 30s when 0
+	Cooldown time.Duration
+}
+
+// resilientTransport retries idempotent requests whose connection failed and keeps a circuit
+// breaker per host. Only transport errors count: any HTTP response, 5xx included, is a success.
+type resilientTransport struct {
+	next    http.RoundTripper
+	retries int
+	methods map[string]bool
+	delay   time.Duration
+	breaker *circuitBreaker
+}
+
+func newResilientTransport(next http.RoundTripper, retries int, methods []string, delay time.Duration, breaker CircuitBreakerConfig) http.RoundTripper {
+	if retries <= 0 && breaker.Failures <= 0 {
+		return next
+	}
+	if len(methods) == 0 {
+		methods = idempotentMethods
+	}
+	t := &resilientTransport{next: next, retries: retries, methods: make(map[string]bool, len(methods)), delay: delay}
+	for _, method := range methods {
+		t.methods[strings.ToUpper(method)] = true
+	}
+	if breaker.Failures > 0 {
+		if breaker.Cooldown <= 0 {
+			breaker.Cooldown = 30 * time.Second
+		}
+		t.breaker = &circuitBreaker{config: breaker, hosts: make(map[string]*hostCircuit)}
+	}
+	return t
+}
+
+func (t *resilientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	host := req.URL.Host
+	if err := t.breaker.allow(host); err != nil {
+		return nil, err
+	}
+
+	resp, err := t.next.RoundTrip(req)
+	for attempt := 1; err != nil && attempt <= t.retries && t.retryable(req); attempt++ {
+		time.Sleep(t.delay)
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				break
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+		resp, err = t.next.RoundTrip(req)
+	}
+
+	if err != nil && errors.Is(err, context.Canceled) {
+		return nil, err
+	}
+	t.breaker.record(host, err == nil)
+	return resp, err
+}
+
+// retryable reports whether a failed request can be sent again: its method is idempotent, its
+// body can be replayed and the run has not given up on it
+func (t *resilientTransport) retryable(req *http.Request) bool {
+	if req.Context().Err() != nil || !t.methods[req.Method] {
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+type circuitBreaker struct {
+	config CircuitBreakerConfig
+	mu     sync.Mutex
+	hosts  map[string]*hostCircuit
+}
+
+type hostCircuit struct {
+	failures  int
+	openUntil time.Time
+}
+
+// allow rejects requests to a host whose circuit is open; once the cooldown has passed, requests
+// go through again and the next failure reopens the circuit
+func (b *circuitBreaker) allow(host string) error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	circuit := b.hosts[host]
+	if circuit == nil || circuit.failures < b.config.Failures || time.Now().After(circuit.openUntil) {
+		return nil
+	}
+	return fmt.Errorf("%w: %s (%d consecutive connection failures, next attempt in %v)",
+		ErrHostUnavailable, host, circuit.failures, time.Until(circuit.openUntil).Round(time.Second))
+}
+
+func (b *circuitBreaker) record(host string, succeeded bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if succeeded {
+		delete(b.hosts, host)
+		return
+	}
+	circuit := b.hosts[host]
+	if circuit == nil {
+		circuit = &hostCircuit{}
+		b.hosts[host] = circuit
+	}
+	circuit.failures++
+	if circuit.failures >= b.config.Failures {
+		circuit.openUntil = time.Now().Add(b.config.Cooldown)
+	}
+}
@@ -0,0 +1,78 @@
+package protocols
+
+import (
+	"bytes"
+	"io"
+	"sync/atomic"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+)
+
+// StreamStats describes a response body read as it arrived. Data that arrives together, such as
+// one flushed chunk of a chunked response, counts as one chunk.
+type StreamStats struct {
+	FirstChunk time.Duration `json:"first_chunk"` // from sending the request to the first chunk
+	Duration   time.Duration `json:"duration"`    // from sending the request to the end of reading
+	Chunks     int           `json:"chunks"`
+	Contents   []string      `json:"contents,omitempty"` // the first chunks, as many as the step keeps
+	Ended      bool          `json:"ended"`              // the body ended rather than reading being stopped
+}
+
+// readStream reads body chunk by chunk until it ends, stream.Until shows up in it or
+// stream.MaxDuration has passed since started
+func readStream(body io.ReadCloser, stream *scenario.Stream, started time.Time) ([]byte, *StreamStats, error) {
+	keep := stream.KeepChunks
+	if keep == 0 {
+		keep = scenario.DefaultKeepChunks
+	}
+	until := []byte(stream.Until)
+
+	// Closing the body is the only way to interrupt a read that waits for the next chunk
+	var expired atomic.Bool
+	if stream.MaxDuration > 0 {
+		timer := time.AfterFunc(time.Until(started.Add(stream.MaxDuration)), func() {
+			expired.Store(true)
+			body.Close()
+		})
+		defer timer.Stop()
+	}
+
+	stats := &StreamStats{}
+	var data []byte
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := body.Read(buf)
+		if n > 0 {
+			if stats.Chunks == 0 {
+				stats.FirstChunk = time.Since(started)
+			}
+			stats.Chunks++
+			if len(stats.Contents) < keep {
+				stats.Contents = append(stats.Contents, string(buf[:n]))
+			}
+
+			searchFrom := len(data) - len(until)
+			if searchFrom < 0 {
+				searchFrom = 0
+			}
+			data = append(data, buf[:n]...)
+			if len(until) > 0 && bytes.Contains(data[searchFrom:], until) {
+				break
+			}
+		}
+		if err == io.EOF {
+			stats.Ended = true
+			break
+		}
+		if err != nil {
+			if expired.Load() {
+				break
+			}
+			return nil, nil, err
+		}
+	}
+
+	stats.Duration = time.Since(started)
+	return data, stats, nil
+}
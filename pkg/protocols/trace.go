@@ -0,0 +1,118 @@
+package protocols
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const redacted = "[REDACTED]"
+
+var (
+	sensitiveName  = regexp.MustCompile(`(?i)(authorization|cookie|password|passwd|secret|token|api[-_]?key|session)`)
+	sensitiveJSON  = regexp.MustCompile(`(?i)("[^"]*(?:password|passwd|secret|token|api[-_]?key)[^"]*"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	sensitiveForm  = regexp.MustCompile(`(?i)((?:^|[?&\s])[^=&\s]*(?:password|passwd|secret|token|api[-_]?key)[^=&\s]*=)[^&\s]*`)
+	headerLine     = regexp.MustCompile(`^([A-Za-z0-9-]+):\s*(.*)$`)
+	traceSeparator = strings.Repeat("-", 60)
+)
+
+// SyncWriter serializes writes so trace output of concurrent steps does not interleave mid-dump
+type SyncWriter struct {
+	mu  sync.Mutex
+	out io.Writer
+}
+
+// NewSyncWriter wraps out for concurrent use
+func NewSyncWriter(out io.Writer) *SyncWriter {
+	return &SyncWriter{out: out}
+}
+
+func (w *SyncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.out.Write(p)
+}
+
+// traceTransport dumps every request and response on the wire, including redirects, with
+// credentials redacted
+type traceTransport struct {
+	next http.RoundTripper
+	out  io.Writer
+}
+
+func (t *traceTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var b strings.Builder
+
+	if dump, err := httputil.DumpRequestOut(req, true); err == nil {
+		fmt.Fprintf(&b, "> %s %s\n%s\n", req.Method, Redact(req.URL.String()), prefixLines(Redact(string(dump)), "> "))
+	} else {
+		fmt.Fprintf(&b, "> %s %s (request dump failed: %v)\n", req.Method, Redact(req.URL.String()), err)
+	}
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	elapsed := time.Since(start)
+
+	if err != nil {
+		fmt.Fprintf(&b, "! %v (after %v)\n%s\n", err, elapsed, traceSeparator)
+		_, _ = io.WriteString(t.out, b.String())
+		return nil, err
+	}
+
+	if dump, dumpErr := dumpResponse(resp); dumpErr == nil {
+		fmt.Fprintf(&b, "< %s (%v)\n%s\n", resp.Status, elapsed, prefixLines(Redact(dump), "< "))
+	} else {
+		fmt.Fprintf(&b, "< %s (%v, response dump failed: %v)\n", resp.Status, elapsed, dumpErr)
+	}
+	b.WriteString(traceSeparator + "\n")
+
+	_, _ = io.WriteString(t.out, b.String())
+	return resp, nil
+}
+
+// dumpResponse dumps the head of a response and its body decoded from gzip or deflate, since the
+// transport leaves decoding to the client. The body is put back as it was read.
+func dumpResponse(resp *http.Response) (string, error) {
+	head, err := httputil.DumpResponse(resp, false)
+	if err != nil {
+		return "", err
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+
+	if decoded, err := decompressBody(body, resp.Header.Get("Content-Encoding")); err == nil {
+		body = decoded
+	}
+	return string(head) + string(body), nil
+}
+
+// Redact masks credentials in wire data: values of sensitive headers (Authorization, Cookie,
+// *-Token, X-Api-Key, ...), sensitive query/form parameters and sensitive JSON fields
+func Redact(dump string) string {
+	lines := strings.Split(dump, "\n")
+	for i, line := range lines {
+		match := headerLine.FindStringSubmatch(strings.TrimRight(line, "\r"))
+		if match != nil && sensitiveName.MatchString(match[1]) {
+			lines[i] = match[1] + ": " + redacted
+		}
+	}
+
+	dump = strings.Join(lines, "\n")
+	dump = sensitiveJSON.ReplaceAllString(dump, `${1}"`+redacted+`"`)
+	return sensitiveForm.ReplaceAllString(dump, "${1}"+redacted)
+}
+
+func prefixLines(text, prefix string) string {
+	text = strings.TrimRight(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	return prefix + strings.ReplaceAll(text, "\n", "\n"+prefix)
+}
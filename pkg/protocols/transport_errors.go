@@ -0,0 +1,82 @@
+package protocols
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// Transport error kinds
+const (
+	ErrorTimeout           = "timeout"
+	ErrorConnectionRefused = "connection_refused"
+	ErrorConnectionReset   = "connection_reset"
+	ErrorDNS               = "dns"
+	ErrorTLS               = "tls"
+	ErrorHostUnavailable   = "host_unavailable"
+	ErrorDropped           = "dropped"
+	ErrorOther             = "other"
+)
+
+// TransportError is a request that got no HTTP response: the connection could not be made, was
+// cut or timed out. Steps can assert on it instead of failing.
+type TransportError struct {
+	Kind string
+	Err  error
+}
+
+// NewTransportError wraps err with the kind of failure it describes
+func NewTransportError(err error) *TransportError {
+	return &TransportError{Kind: errorKind(err), Err: err}
+}
+
+func (e *TransportError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// Timeout reports whether the request ran out of time
+func (e *TransportError) Timeout() bool {
+	return e.Kind == ErrorTimeout
+}
+
+func errorKind(err error) string {
+	var netErr net.Error
+	var dnsErr *net.DNSError
+	var verifyErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var authorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+
+	switch {
+	case errors.Is(err, ErrHostUnavailable):
+		return ErrorHostUnavailable
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorTimeout
+	case errors.As(err, &dnsErr):
+		return ErrorDNS
+	case errors.As(err, &verifyErr), errors.As(err, &recordErr), errors.As(err, &authorityErr),
+		errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return ErrorTLS
+	}
+
+	// Windows reports connection errors with its own codes, so the message is checked too
+	message := err.Error()
+	switch {
+	case errors.Is(err, syscall.ECONNREFUSED), strings.Contains(message, "connection refused"),
+		strings.Contains(message, "actively refused"):
+		return ErrorConnectionRefused
+	case errors.Is(err, syscall.ECONNRESET), strings.Contains(message, "connection reset"),
+		strings.Contains(message, "forcibly closed"), strings.Contains(message, "EOF"):
+		return ErrorConnectionReset
+	}
+	return ErrorOther
+}
@@ -0,0 +1,244 @@
+// Package record captures HTTP traffic through a local forward proxy and turns it into fuego
+// scenarios. Point a client or browser at the proxy (HTTP_PROXY=http://localhost:8888), exercise
+// the API, and write the recording as a scenario file.
+//
+// HTTPS requests are tunnelled through CONNECT without being decrypted, so only plain HTTP
+// traffic is recorded; record against an HTTP endpoint of the API under test.
+package record
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/scenariobuilder"
+)
+
+// DefaultDropHeaders are request headers left out of recorded steps: hop-by-hop headers, headers
+// the HTTP client sets itself and browser noise
+var DefaultDropHeaders = []string{
+	"Accept-Encoding", "Connection", "Content-Length", "Cookie", "Host", "Keep-Alive",
+	"Proxy-Authorization", "Proxy-Connection", "Te", "Trailer", "Transfer-Encoding", "Upgrade",
+	"User-Agent", "Sec-Ch-Ua", "Sec-Ch-Ua-Mobile", "Sec-Ch-Ua-Platform", "Sec-Fetch-Dest",
+	"Sec-Fetch-Mode", "Sec-Fetch-Site", "Sec-Fetch-User", "Upgrade-Insecure-Requests",
+}
+
+// hopByHop headers are never forwarded by the proxy
+var hopByHop = []string{"Connection", "Keep-Alive", "Proxy-Authorization", "Proxy-Connection", "Te", "Trailer", "Transfer-Encoding", "Upgrade"}
+
+// Options controls what is recorded
+type Options struct {
+	// Hosts limits recording to these hosts (with or without port); all hosts when empty
+	Hosts []string
+	// DropHeaders are removed from recorded steps in addition to DefaultDropHeaders
+	DropHeaders []string
+	// KeepDuplicates records repeated identical requests instead of only the first one
+	KeepDuplicates bool
+}
+
+// Exchange is one recorded request with the status it received
+type Exchange struct {
+	Method      string
+	URL         string
+	Headers     map[string]string
+	Body        []byte
+	ContentType string
+	Status      int
+}
+
+// Recorder is a forward proxy recording the requests it relays. It is safe for concurrent use.
+type Recorder struct {
+	options   Options
+	transport http.RoundTripper
+	drop      map[string]bool
+
+	mu        sync.Mutex
+	exchanges []Exchange
+	seen      map[string]bool
+}
+
+// NewRecorder returns a recording proxy
+func NewRecorder(options Options) *Recorder {
+	drop := make(map[string]bool)
+	for _, name := range append(append([]string{}, DefaultDropHeaders...), options.DropHeaders...) {
+		drop[http.CanonicalHeaderKey(name)] = true
+	}
+	return &Recorder{
+		options: options,
+		// Never chain to the proxy configured in the environment, which is likely this one
+		transport: &http.Transport{Proxy: nil, ResponseHeaderTimeout: 60 * time.Second},
+		drop:      drop,
+		seen:      make(map[string]bool),
+	}
+}
+
+// ServeHTTP relays a proxied request and records it
+func (r *Recorder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method == http.MethodConnect {
+		r.tunnel(w, req)
+		return
+	}
+	if !req.URL.IsAbs() {
+		http.Error(w, "fuego record is a forward proxy; send absolute URLs (set HTTP_PROXY)", http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to read request: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	outbound, err := http.NewRequestWithContext(req.Context(), req.Method, req.URL.String(), bytes.NewReader(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	outbound.Header = req.Header.Clone()
+	for _, name := range hopByHop {
+		outbound.Header.Del(name)
+	}
+
+	resp, err := r.transport.RoundTrip(outbound)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("upstream request failed: %v", err), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	for name, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(name, value)
+		}
+	}
+	for _, name := range hopByHop {
+		w.Header().Del(name)
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+
+	r.record(req, body, resp.StatusCode)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte, status int) {
+	if !r.wantsHost(req.URL.Host) {
+		return
+	}
+
+	headers := make(map[string]string)
+	for name, values := range req.Header {
+		if r.drop[http.CanonicalHeaderKey(name)] || len(values) == 0 {
+			continue
+		}
+		headers[name] = strings.Join(values, ", ")
+	}
+
+	exchange := Exchange{
+		Method:      req.Method,
+		URL:         req.URL.String(),
+		Headers:     headers,
+		Body:        body,
+		ContentType: req.Header.Get("Content-Type"),
+		Status:      status,
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := exchange.Method + " " + exchange.URL + "\n" + string(body)
+	if r.seen[key] && !r.options.KeepDuplicates {
+		return
+	}
+	r.seen[key] = true
+	r.exchanges = append(r.exchanges, exchange)
+}
+
+func (r *Recorder) wantsHost(host string) bool {
+	if len(r.options.Hosts) == 0 {
+		return true
+	}
+	hostname := host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		hostname = h
+	}
+	for _, wanted := range r.options.Hosts {
+		if strings.EqualFold(wanted, host) || strings.EqualFold(wanted, hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+// tunnel relays a CONNECT request (HTTPS) without recording it
+func (r *Recorder) tunnel(w http.ResponseWriter, req *http.Request) {
+	upstream, err := net.DialTimeout("tcp", req.Host, 10*time.Second)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to connect to %s: %v", req.Host, err), http.StatusBadGateway)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		upstream.Close()
+		http.Error(w, "tunnelling is not supported", http.StatusInternalServerError)
+		return
+	}
+	client, _, err := hijacker.Hijack()
+	if err != nil {
+		upstream.Close()
+		return
+	}
+	client.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n"))
+
+	go func() {
+		defer upstream.Close()
+		defer client.Close()
+		io.Copy(upstream, client)
+	}()
+	go func() {
+		defer upstream.Close()
+		defer client.Close()
+		io.Copy(client, upstream)
+	}()
+}
+
+// Exchanges returns the recorded requests in the order they were made
+func (r *Recorder) Exchanges() []Exchange {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Exchange(nil), r.exchanges...)
+}
+
+// Scenario turns the recorded requests into a scenario with one HTTP step per request, each
+// checking the status code that was recorded
+func (r *Recorder) Scenario(name string) *scenariobuilder.Builder {
+	builder := scenariobuilder.NewScenario().Name(name)
+	for _, exchange := range r.Exchanges() {
+		builder.HTTP(exchange.Method, exchange.URL)
+		for header, value := range exchange.Headers {
+			if strings.EqualFold(header, "Content-Type") && isJSON(exchange.ContentType) {
+				continue // implied by the JSON body
+			}
+			builder.Header(header, value)
+		}
+		if len(exchange.Body) > 0 {
+			var parsed interface{}
+			if isJSON(exchange.ContentType) && json.Unmarshal(exchange.Body, &parsed) == nil {
+				builder.JSONBody(parsed)
+			} else {
+				builder.Body(string(exchange.Body))
+			}
+		}
+		builder.Check(scenariobuilder.Status(exchange.Status))
+	}
+	return builder
+}
+
+func isJSON(contentType string) bool {
+	return strings.Contains(strings.ToLower(contentType), "json")
+}
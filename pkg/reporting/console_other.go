@@ -0,0 +1,10 @@
+//go:build !windows
+
+package reporting
+
+import "os"
+
+// enableVirtualTerminal is only needed on Windows; other terminals handle escape sequences
+func enableVirtualTerminal(f *os.File) bool {
+	return true
+}
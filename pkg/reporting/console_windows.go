@@ -0,0 +1,26 @@
+//go:build windows
+
+package reporting
+
+import (
+	"os"
+	"syscall"
+)
+
+const enableVirtualTerminalProcessing = 0x0004
+
+var procSetConsoleMode = syscall.NewLazyDLL("kernel32.dll").NewProc("SetConsoleMode")
+
+// enableVirtualTerminal turns on escape sequence handling for a Windows console. Consoles older
+// than Windows 10 do not support it and print the sequences as text.
+func enableVirtualTerminal(f *os.File) bool {
+	var mode uint32
+	if err := syscall.GetConsoleMode(syscall.Handle(f.Fd()), &mode); err != nil {
+		return false
+	}
+	if mode&enableVirtualTerminalProcessing != 0 {
+		return true
+	}
+	ok, _, _ := procSetConsoleMode.Call(f.Fd(), uintptr(mode|enableVirtualTerminalProcessing))
+	return ok != 0
+}
@@ -0,0 +1,100 @@
+package reporting
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// csvHeader names the columns of the CSV report
+var csvHeader = []string{"scenario", "group", "step", "status", "duration_ms", "status_code", "error"}
+
+// generateCSVReport writes one row per step, for pivot tables over large data-driven runs in
+// spreadsheets
+func (r *Reporter) generateCSVReport() error {
+	output, err := r.generateCSVContent()
+	if err != nil {
+		return err
+	}
+
+	if r.config.OutputFile != "" {
+		return r.writeOutput(output)
+	}
+
+	fmt.Print(string(output))
+	return nil
+}
+
+func (r *Reporter) generateCSVContent() ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if err := writer.Write(csvHeader); err != nil {
+		return nil, err
+	}
+
+	for _, scenario := range r.report.Scenarios {
+		for _, step := range scenario.Steps {
+			name := ""
+			if step.Step != nil {
+				name = step.Step.Name
+			}
+			row := []string{
+				csvCell(scenarioLabel(scenario)),
+				csvCell(step.Group),
+				csvCell(name),
+				step.Status,
+				strconv.FormatFloat(float64(step.Duration.Microseconds())/1000, 'f', 3, 64),
+				csvStatusCode(step.Response),
+				csvCell(csvError(step)),
+			}
+			if err := writer.Write(row); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to write CSV report: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// csvCell keeps spreadsheets from running text as a formula: cells starting with =, +, -, @ (or
+// a tab or carriage return) get a leading ', as CSV injection guidance recommends. Errors often
+// quote server responses, so their text is not trusted.
+func csvCell(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// csvError returns the step error, or the messages of its failed assertions when it failed on
+// those
+func csvError(step StepResult) string {
+	if step.Error != "" {
+		return step.Error
+	}
+	var failed []string
+	for _, assertion := range step.Assertions {
+		if !assertion.Passed {
+			failed = append(failed, assertion.Message)
+		}
+	}
+	return strings.Join(failed, "; ")
+}
+
+// csvStatusCode returns the HTTP status of a step response, empty for steps without one
+func csvStatusCode(response interface{}) string {
+	responseMap, ok := response.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if code, exists := responseMap["status_code"]; exists && code != nil {
+		return fmt.Sprint(code)
+	}
+	return ""
+}
@@ -0,0 +1,21 @@
+package reporting
+
+import "time"
+
+// ScenarioDurations maps scenario names (matrix combinations included) to how long they took,
+// averaged over repeated runs, for balancing shards of the next run
+func (r *Report) ScenarioDurations() map[string]time.Duration {
+	totals := make(map[string]time.Duration)
+	counts := make(map[string]int)
+	for _, result := range r.Scenarios {
+		if result.Scenario == nil || result.Status == "skipped" {
+			continue
+		}
+		totals[result.Scenario.Name] += result.Duration
+		counts[result.Scenario.Name]++
+	}
+	for name, total := range totals {
+		totals[name] = total / time.Duration(counts[name])
+	}
+	return totals
+}
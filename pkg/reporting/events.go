@@ -0,0 +1,135 @@
+package reporting
+
+import "time"
+
+// EventType identifies what happened during a run
+type EventType string
+
+const (
+	EventRunStarted       EventType = "run_started"
+	EventScenarioStarted  EventType = "scenario_started"
+	EventStepFinished     EventType = "step_finished"
+	EventScenarioFinished EventType = "scenario_finished"
+	EventRunFinished      EventType = "run_finished"
+)
+
+// Event is one entry of the stream a run publishes through Reporter.Emit. Only the fields
+// relevant to the event type are set.
+type Event struct {
+	Type EventType `json:"type"`
+	Time time.Time `json:"time"`
+
+	// Scenario and Run identify the scenario of scenario and step events
+	Scenario string `json:"scenario,omitempty"`
+	Run      int    `json:"run,omitempty"`
+
+	// Scenarios is the number of scenarios a run will execute, including repetitions and
+	// matrix combinations (run_started)
+	Scenarios int `json:"scenarios,omitempty"`
+	// Steps is the number of steps a scenario declares (scenario_started)
+	Steps int `json:"steps,omitempty"`
+
+	Step   *StepResult     `json:"step,omitempty"`   // step_finished
+	Result *ScenarioResult `json:"result,omitempty"` // scenario_finished
+	Report *Report         `json:"report,omitempty"` // run_finished
+}
+
+// Compact drops what an event repeats from earlier ones: the steps of a scenario_finished result
+// (each came with its step_finished event) and the scenarios of the run_finished report, so
+// streams stay small however large the run
+func (e Event) Compact() Event {
+	switch e.Type {
+	case EventScenarioFinished:
+		if e.Result != nil {
+			result := *e.Result
+			result.Steps = nil
+			e.Result = &result
+		}
+	case EventRunFinished:
+		if e.Report != nil {
+			report := *e.Report
+			report.Scenarios = nil
+			e.Report = &report
+		}
+	}
+	return e
+}
+
+// EventHandler receives the events of a run. Handlers are called outside the reporter's lock, so
+// they may call back into the Reporter, and each goroutine's events arrive in the order it emitted
+// them. Scenarios or test groups running in parallel call handlers concurrently, so handlers with
+// state of their own must lock it.
+type EventHandler func(Event)
+
+// Subscribe registers a handler for every event emitted from now on
+func (r *Reporter) Subscribe(handler EventHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers = append(r.handlers, handler)
+}
+
+// Emit publishes an event to the subscribers. A scenario_finished event also adds its result to
+// the report. Response bodies are removed from step and scenario results unless
+// ReportConfig.IncludeBody is set. Emit is safe for concurrent use, e.g. by scenarios or test
+// groups running in parallel; sinks and handlers run after the report is updated and the lock
+// released, so a slow webhook does not hold up other steps.
+func (r *Reporter) Emit(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	// Response bodies are dropped as early as possible so huge runs do not keep them in memory
+	if !r.config.IncludeBody {
+		if event.Step != nil {
+			event.Step = &withoutBodies([]StepResult{*event.Step})[0]
+		}
+		if event.Result != nil {
+			result := *event.Result
+			result.Steps = withoutBodies(result.Steps)
+			event.Result = &result
+		}
+	}
+
+	r.mu.Lock()
+	var sinks []Sink
+	if event.Type == EventScenarioFinished && event.Result != nil {
+		r.report.Scenarios = append(r.report.Scenarios, *event.Result)
+		sinks = append(sinks, r.sinks...)
+	}
+	handlers := append([]EventHandler(nil), r.handlers...)
+	r.mu.Unlock()
+
+	for _, sink := range sinks {
+		if err := sink.ScenarioCompleted(*event.Result); err != nil {
+			r.addSinkError(err)
+		}
+	}
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+func (r *Reporter) addSinkError(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sinkErrors = append(r.sinkErrors, err.Error())
+}
+
+// HandleEvent draws the progress of a run from its event stream; subscribe it with
+// Reporter.Subscribe(progress.HandleEvent)
+func (p *Progress) HandleEvent(event Event) {
+	switch event.Type {
+	case EventRunStarted:
+		p.Begin(event.Scenarios)
+	case EventScenarioStarted:
+		p.StartScenario(event.Scenario, event.Steps)
+	case EventStepFinished:
+		if event.Step != nil {
+			p.StepDone(event.Step.Status)
+		}
+	case EventScenarioFinished:
+		if event.Result != nil {
+			p.FinishScenario(*event.Result)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package reporting
+
+// StepFlakiness summarizes how often a step passed across repeated runs of its scenario
+type StepFlakiness struct {
+	Scenario  string  `json:"scenario"`
+	Step      string  `json:"step"`
+	Runs      int     `json:"runs"`
+	Passed    int     `json:"passed"`
+	Failed    int     `json:"failed"`
+	PassRatio float64 `json:"pass_ratio"`
+}
+
+// Flaky reports whether the step both passed and failed across runs
+func (f StepFlakiness) Flaky() bool {
+	return f.Passed > 0 && f.Failed > 0
+}
+
+// ComputeFlakiness returns pass ratios for every step of scenarios that ran more than once,
+// listing only steps that failed at least once. Steps are kept in order of first appearance.
+func ComputeFlakiness(results []ScenarioResult) []StepFlakiness {
+	type key struct{ scenario, step string }
+
+	runs := make(map[string]int)
+	for _, result := range results {
+		if result.Run > 0 {
+			runs[result.Scenario.Name]++
+		}
+	}
+
+	var order []key
+	stats := make(map[key]*StepFlakiness)
+	for _, result := range results {
+		if runs[result.Scenario.Name] < 2 {
+			continue
+		}
+
+		for _, step := range result.Steps {
+			if step.Step == nil || step.Status == "skipped" {
+				continue
+			}
+
+			k := key{result.Scenario.Name, step.Step.Name}
+			entry, exists := stats[k]
+			if !exists {
+				entry = &StepFlakiness{Scenario: k.scenario, Step: k.step}
+				stats[k] = entry
+				order = append(order, k)
+			}
+
+			entry.Runs++
+			if step.Status == "failed" {
+				entry.Failed++
+			} else {
+				entry.Passed++
+			}
+		}
+	}
+
+	var flakiness []StepFlakiness
+	for _, k := range order {
+		entry := stats[k]
+		if entry.Failed == 0 {
+			continue
+		}
+		entry.PassRatio = float64(entry.Passed) / float64(entry.Runs)
+		flakiness = append(flakiness, *entry)
+	}
+
+	return flakiness
+}
@@ -0,0 +1,142 @@
+package reporting
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// generateGitHubReport emits GitHub Actions workflow commands so failures show up inline on the
+// scenario file in pull requests. When GITHUB_STEP_SUMMARY is set, the Markdown report is also
+// appended to the job summary.
+func (r *Reporter) generateGitHubReport() error {
+	output := r.generateGitHubAnnotations()
+
+	if r.config.OutputFile != "" {
+		if err := r.writeOutput([]byte(output)); err != nil {
+			return err
+		}
+	} else {
+		fmt.Print(output)
+	}
+
+	if summaryPath := os.Getenv("GITHUB_STEP_SUMMARY"); summaryPath != "" {
+		file, err := os.OpenFile(summaryPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open GitHub step summary: %w", err)
+		}
+		defer file.Close()
+
+		if _, err := file.WriteString(r.generateMarkdownContent()); err != nil {
+			return fmt.Errorf("failed to write GitHub step summary: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *Reporter) generateGitHubAnnotations() string {
+	var b strings.Builder
+
+	for _, result := range r.report.Scenarios {
+		file := ""
+		name := ""
+		if result.Scenario != nil {
+			file = annotationPath(result.Scenario.SourceFile)
+			name = result.Scenario.Name
+		}
+
+		annotated := false
+		for _, step := range result.Steps {
+			if step.Step == nil {
+				continue
+			}
+
+			level := ""
+			message := step.Error
+			switch step.Status {
+			case "failed":
+				level = "error"
+				for _, assertion := range step.Assertions {
+					if !assertion.Passed {
+						message = joinMessage(message, assertion.Message)
+					}
+				}
+			case "unexpected_pass":
+				level = "warning"
+				message = fmt.Sprintf("step passed but is marked as known failure %s", step.KnownFailure)
+			default:
+				continue
+			}
+
+			if message == "" {
+				message = "step failed"
+			}
+			writeAnnotation(&b, level, file, step.Step.Line, fmt.Sprintf("%s / %s", name, step.Step.Name), message)
+			annotated = annotated || level == "error"
+		}
+
+		for _, warning := range result.Warnings {
+			writeAnnotation(&b, "warning", file, 0, name, warning)
+		}
+
+		// Scenario-level failures (setup errors, SLO violations) have no failing step to point at
+		if result.Status == "failed" && result.Error != "" && !annotated {
+			writeAnnotation(&b, "error", file, 0, name, result.Error)
+		}
+	}
+
+	fmt.Fprintf(&b, "%d passed, %d failed, %d skipped (%.2f%%) in %v\n",
+		r.report.Summary.Passed, r.report.Summary.Failed, r.report.Summary.Skipped, r.report.Summary.PassRate, r.report.Duration)
+
+	return b.String()
+}
+
+func writeAnnotation(b *strings.Builder, level, file string, line int, title, message string) {
+	var properties []string
+	if file != "" {
+		properties = append(properties, "file="+escapeProperty(file))
+		if line > 0 {
+			properties = append(properties, fmt.Sprintf("line=%d", line))
+		}
+	}
+	properties = append(properties, "title="+escapeProperty(title))
+
+	fmt.Fprintf(b, "::%s %s::%s\n", level, strings.Join(properties, ","), escapeData(message))
+}
+
+// annotationPath makes the scenario path relative to the working directory, which GitHub expects
+// to be the repository root
+func annotationPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	if filepath.IsAbs(path) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+				path = rel
+			}
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
+
+func joinMessage(message, addition string) string {
+	if message == "" {
+		return addition
+	}
+	return message + "\n" + addition
+}
+
+func escapeData(value string) string {
+	value = strings.ReplaceAll(value, "%", "%25")
+	value = strings.ReplaceAll(value, "\r", "%0D")
+	return strings.ReplaceAll(value, "\n", "%0A")
+}
+
+func escapeProperty(value string) string {
+	value = escapeData(value)
+	value = strings.ReplaceAll(value, ":", "%3A")
+	return strings.ReplaceAll(value, ",", "%2C")
+}
@@ -0,0 +1,118 @@
+package reporting
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/nulln0ne/fuego/pkg/objectstore"
+)
+
+// jsonlStream writes the events of a run as JSON lines while it runs, so huge data-driven runs
+// can be consumed without waiting for (or holding) the complete report. Steps are written as
+// they finish; scenario lines carry the result without steps and the final line the report
+// without scenarios. Object store URLs cannot be appended to, so their lines are buffered and
+// uploaded when the run finishes.
+type jsonlStream struct {
+	mu      sync.Mutex
+	path    string
+	storage objectstore.Credentials
+	out     io.Writer
+	file    *os.File
+	upload  *bytes.Buffer
+	buf     *bufio.Writer
+	err     error
+}
+
+func newJSONLStream(path string, storage objectstore.Credentials) *jsonlStream {
+	return &jsonlStream{path: path, storage: storage}
+}
+
+func (s *jsonlStream) handle(event Event) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return
+	}
+	if s.buf == nil {
+		if err := s.open(); err != nil {
+			s.err = err
+			return
+		}
+	}
+
+	line, err := json.Marshal(event.Compact())
+	if err != nil {
+		s.err = fmt.Errorf("failed to marshal %s event: %w", event.Type, err)
+		return
+	}
+	if _, err := s.buf.Write(append(line, '\n')); err != nil {
+		s.err = err
+		return
+	}
+
+	if event.Type == EventRunFinished {
+		s.err = s.close()
+	}
+}
+
+// error returns the first failure to write the stream
+func (s *jsonlStream) error() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+func (s *jsonlStream) open() error {
+	s.out = os.Stdout
+	if objectstore.IsURL(s.path) {
+		s.upload = &bytes.Buffer{}
+		s.out = s.upload
+	} else if s.path != "" {
+		file, err := os.Create(s.path)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", s.path, err)
+		}
+		s.file, s.out = file, file
+	}
+	s.buf = bufio.NewWriter(s.out)
+	return nil
+}
+
+func (s *jsonlStream) close() error {
+	if err := s.buf.Flush(); err != nil {
+		return err
+	}
+	if s.upload != nil {
+		return writeReportFile(s.path, s.upload.Bytes(), s.storage)
+	}
+	if s.file != nil {
+		return s.file.Close()
+	}
+	return nil
+}
+
+// withoutBodies returns the steps with response bodies dropped from their response maps, keeping
+// status, headers, timing and size
+func withoutBodies(steps []StepResult) []StepResult {
+	stripped := make([]StepResult, len(steps))
+	for i, step := range steps {
+		stripped[i] = step
+		response, ok := step.Response.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		trimmed := make(map[string]interface{}, len(response))
+		for key, value := range response {
+			if key != "body" && key != "body_text" {
+				trimmed[key] = value
+			}
+		}
+		stripped[i].Response = trimmed
+	}
+	return stripped
+}
@@ -0,0 +1,132 @@
+package reporting
+
+import (
+	"encoding/xml"
+	"fmt"
+	"time"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+	SystemErr string          `xml:"system-err,omitempty"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr,omitempty"`
+}
+
+// generateJUnitReport writes JUnit XML with one test suite per scenario and one test case per
+// step, the format most CI systems render natively
+func (r *Reporter) generateJUnitReport() error {
+	output, err := r.generateJUnitContent()
+	if err != nil {
+		return err
+	}
+
+	if r.config.OutputFile != "" {
+		return r.writeOutput(output)
+	}
+
+	fmt.Println(string(output))
+	return nil
+}
+
+func (r *Reporter) generateJUnitContent() ([]byte, error) {
+	suites := junitTestSuites{Name: "fuego", Time: junitSeconds(r.report.Duration)}
+
+	for _, scenario := range r.report.Scenarios {
+		suite := junitTestSuite{
+			Name:      scenarioLabel(scenario),
+			Time:      junitSeconds(scenario.Duration),
+			Timestamp: scenario.StartTime.UTC().Format("2006-01-02T15:04:05"),
+			SystemErr: scenario.Error,
+		}
+
+		for _, step := range scenario.Steps {
+			testCase := junitTestCase{
+				Name:      step.Step.Name,
+				Classname: suite.Name,
+				Time:      junitSeconds(step.Duration),
+			}
+
+			switch step.Status {
+			case "failed", "unexpected_pass":
+				message := step.Error
+				if step.Status == "unexpected_pass" {
+					message = fmt.Sprintf("passed although marked as known failure %s", step.KnownFailure)
+				}
+				if message == "" {
+					message = "step failed"
+				}
+				testCase.Failure = &junitFailure{Message: message, Text: junitFailureDetails(step)}
+				suite.Failures++
+			case "skipped":
+				testCase.Skipped = &junitSkipped{Message: step.Error}
+				suite.Skipped++
+			case "expected_failure":
+				testCase.SystemOut = fmt.Sprintf("expected failure (%s): %s", step.KnownFailure, step.Error)
+			}
+
+			suite.Cases = append(suite.Cases, testCase)
+			suite.Tests++
+		}
+
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	output, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal report to JUnit XML: %w", err)
+	}
+	return append([]byte(xml.Header), output...), nil
+}
+
+func junitFailureDetails(step StepResult) string {
+	details := ""
+	for _, assertion := range step.Assertions {
+		if !assertion.Passed {
+			details += assertion.Message + "\n"
+		}
+	}
+	if step.Curl != "" {
+		details += "Reproduce: " + step.Curl + "\n"
+	}
+	return details
+}
+
+func junitSeconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
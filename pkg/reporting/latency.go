@@ -0,0 +1,155 @@
+package reporting
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LatencyStats aggregates response times of one logical step across all of its iterations
+type LatencyStats struct {
+	Step       string        `json:"step"`
+	Count      int           `json:"count"`
+	Min        time.Duration `json:"min"`
+	Avg        time.Duration `json:"avg"`
+	P50        time.Duration `json:"p50"`
+	P90        time.Duration `json:"p90"`
+	P95        time.Duration `json:"p95"`
+	P99        time.Duration `json:"p99"`
+	Max        time.Duration `json:"max"`
+	Violations []string      `json:"violations,omitempty"`
+}
+
+// LogicalName returns the step name without the per-iteration suffix
+func (s StepResult) LogicalName() string {
+	if s.Step == nil {
+		return ""
+	}
+	if s.Iteration > 0 {
+		return strings.TrimSuffix(s.Step.Name, fmt.Sprintf(" (data %d)", s.Iteration))
+	}
+	return s.Step.Name
+}
+
+// Latency returns the network time of the step, falling back to the whole step duration
+func (s StepResult) Latency() time.Duration {
+	if response, ok := s.Response.(map[string]interface{}); ok {
+		if duration, ok := response["duration"].(time.Duration); ok {
+			return duration
+		}
+	}
+	return s.Duration
+}
+
+// ComputeLatency groups executed steps by logical name, in order of first appearance.
+// Only steps that ran more than once or declare an SLO are included.
+func ComputeLatency(steps []StepResult) []LatencyStats {
+	var order []string
+	samples := make(map[string][]time.Duration)
+	hasSLO := make(map[string]bool)
+
+	for _, step := range steps {
+		if step.Step == nil || step.Response == nil || step.Status == "skipped" {
+			continue
+		}
+
+		name := step.LogicalName()
+		if _, seen := samples[name]; !seen {
+			order = append(order, name)
+		}
+		samples[name] = append(samples[name], step.Latency())
+		if len(step.Step.SLO) > 0 {
+			hasSLO[name] = true
+		}
+	}
+
+	var stats []LatencyStats
+	for _, name := range order {
+		if len(samples[name]) < 2 && !hasSLO[name] {
+			continue
+		}
+		stats = append(stats, NewLatencyStats(name, samples[name]))
+	}
+
+	return stats
+}
+
+// NewLatencyStats computes distribution statistics for a set of samples
+func NewLatencyStats(name string, samples []time.Duration) LatencyStats {
+	stats := LatencyStats{Step: name, Count: len(samples)}
+	if len(samples) == 0 {
+		return stats
+	}
+
+	sorted := append([]time.Duration(nil), samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, sample := range sorted {
+		total += sample
+	}
+
+	stats.Min = sorted[0]
+	stats.Max = sorted[len(sorted)-1]
+	stats.Avg = total / time.Duration(len(sorted))
+	stats.P50 = Percentile(sorted, 50)
+	stats.P90 = Percentile(sorted, 90)
+	stats.P95 = Percentile(sorted, 95)
+	stats.P99 = Percentile(sorted, 99)
+
+	return stats
+}
+
+// Stat returns the named statistic (min, avg, p50, p90, p95, p99, max)
+func (l LatencyStats) Stat(name string) (time.Duration, bool) {
+	switch strings.ToLower(name) {
+	case "min":
+		return l.Min, true
+	case "avg", "mean":
+		return l.Avg, true
+	case "p50", "median":
+		return l.P50, true
+	case "p90":
+		return l.P90, true
+	case "p95":
+		return l.P95, true
+	case "p99":
+		return l.P99, true
+	case "max":
+		return l.Max, true
+	default:
+		return 0, false
+	}
+}
+
+// CheckSLO records a violation for every statistic exceeding its threshold
+func (l *LatencyStats) CheckSLO(slo map[string]time.Duration) {
+	names := make([]string, 0, len(slo))
+	for name := range slo {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		threshold := slo[name]
+		value, ok := l.Stat(name)
+		if !ok {
+			l.Violations = append(l.Violations, fmt.Sprintf("unknown latency statistic %q", name))
+			continue
+		}
+		if value > threshold {
+			l.Violations = append(l.Violations, fmt.Sprintf("%s %v exceeds %v over %d iteration(s)", name, value, threshold, l.Count))
+		}
+	}
+}
+
+// Percentile uses the nearest-rank method on an already sorted, non-empty slice
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p * float64(len(sorted)) / 100))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
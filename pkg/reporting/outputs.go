@@ -0,0 +1,28 @@
+package reporting
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Formats lists the report formats a Reporter can write
+var Formats = []string{"console", "json", "jsonl", "html", "markdown", "github", "junit", "csv", "template", "none"}
+
+// ReportOutput is an additional report written from the same run
+type ReportOutput struct {
+	Format string `json:"format"`
+	File   string `json:"file,omitempty"` // standard output when empty
+}
+
+// ParseReportOutput parses a --report value: format=path, or a format alone for standard output
+func ParseReportOutput(spec string) (ReportOutput, error) {
+	format, file, _ := strings.Cut(spec, "=")
+	output := ReportOutput{Format: strings.TrimSpace(format), File: strings.TrimSpace(file)}
+
+	for _, known := range Formats {
+		if output.Format == known {
+			return output, nil
+		}
+	}
+	return output, fmt.Errorf("unknown report format %q in %q (expected one of %s)", output.Format, spec, strings.Join(Formats, ", "))
+}
@@ -0,0 +1,151 @@
+package reporting
+
+import (
+	"sort"
+	"strings"
+)
+
+// Metadata labels used for ownership and triage
+const (
+	LabelOwner     = "owner"
+	LabelComponent = "component"
+	LabelSeverity  = "severity"
+)
+
+// severityRanks orders severities from most to least severe; unknown severities sort last
+var severityRanks = map[string]int{
+	"blocker":  0,
+	"critical": 1,
+	"high":     2,
+	"major":    2,
+	"medium":   3,
+	"normal":   3,
+	"low":      4,
+	"minor":    4,
+	"trivial":  5,
+}
+
+// SeverityRank returns the position of a severity from most (0) to least severe
+func SeverityRank(severity string) int {
+	if rank, ok := severityRanks[strings.ToLower(severity)]; ok {
+		return rank
+	}
+	return len(severityRanks)
+}
+
+// IsSeverity reports whether severity is one of the known severities
+func IsSeverity(severity string) bool {
+	_, ok := severityRanks[strings.ToLower(severity)]
+	return ok
+}
+
+// Blocks reports whether a failure of the given severity fails the run when only failures at
+// least as severe as failOn should; an empty failOn blocks on every failure
+func Blocks(severity, failOn string) bool {
+	if failOn == "" {
+		return true
+	}
+	return SeverityRank(severity) <= SeverityRank(failOn)
+}
+
+// ScenarioFailure is a failed scenario with the ownership labels of its metadata
+type ScenarioFailure struct {
+	Scenario  string   `json:"scenario"`
+	Run       int      `json:"run,omitempty"`
+	Owner     string   `json:"owner,omitempty"`
+	Component string   `json:"component,omitempty"`
+	Severity  string   `json:"severity,omitempty"`
+	Failures  []string `json:"failures"`
+	// Blocking is false for failures below the fail-on severity, which are only warnings
+	Blocking bool `json:"blocking"`
+}
+
+// ComputeFailures lists failed scenarios ordered by severity, then owner, component and name;
+// failures below the failOn severity are marked as non-blocking
+func ComputeFailures(results []ScenarioResult, failOn string) []ScenarioFailure {
+	var failures []ScenarioFailure
+	for _, result := range results {
+		if result.Status != "failed" || result.Scenario == nil {
+			continue
+		}
+
+		labels := result.Scenario.Metadata.Labels
+		single := Report{Scenarios: []ScenarioResult{result}}
+		failures = append(failures, ScenarioFailure{
+			Scenario:  result.Scenario.Name,
+			Run:       result.Run,
+			Owner:     labels[LabelOwner],
+			Component: labels[LabelComponent],
+			Severity:  labels[LabelSeverity],
+			Failures:  single.Failures(),
+			Blocking:  Blocks(labels[LabelSeverity], failOn),
+		})
+	}
+
+	sort.SliceStable(failures, func(i, j int) bool {
+		a, b := failures[i], failures[j]
+		if rankA, rankB := SeverityRank(a.Severity), SeverityRank(b.Severity); rankA != rankB {
+			return rankA < rankB
+		}
+		if a.Owner != b.Owner {
+			return a.Owner < b.Owner
+		}
+		if a.Component != b.Component {
+			return a.Component < b.Component
+		}
+		return a.Scenario < b.Scenario
+	})
+	return failures
+}
+
+// hasOwnership reports whether any failure carries ownership labels worth grouping by
+func hasOwnership(failures []ScenarioFailure) bool {
+	for _, failure := range failures {
+		if failure.Owner != "" || failure.Component != "" || failure.Severity != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// FailuresByOwner groups failures by owner, keeping severity order within and across groups;
+// failures without an owner are grouped under "unowned"
+func FailuresByOwner(failures []ScenarioFailure) (owners []string, groups map[string][]ScenarioFailure) {
+	groups = make(map[string][]ScenarioFailure)
+	for _, failure := range failures {
+		owner := failure.Owner
+		if owner == "" {
+			owner = "unowned"
+		}
+		if _, exists := groups[owner]; !exists {
+			owners = append(owners, owner)
+		}
+		groups[owner] = append(groups[owner], failure)
+	}
+	return owners, groups
+}
+
+// failureTriage renders the severity and component of a failure as "[critical] payments: "
+func failureTriage(failure ScenarioFailure) string {
+	triage := ""
+	if failure.Severity != "" {
+		triage += "[" + failure.Severity + "] "
+	}
+	if failure.Component != "" {
+		triage += failure.Component + ": "
+	}
+	return triage
+}
+
+// SeverityGate splits failed scenarios into those failing the run and warnings below the
+// Config.FailOn severity
+func (r *Report) SeverityGate() (blocking, warnings []ScenarioFailure) {
+	for _, failure := range r.FailedScenarios {
+		if failure.Blocking {
+			blocking = append(blocking, failure)
+		} else {
+			warnings = append(warnings, failure)
+		}
+	}
+	return blocking, warnings
+}
@@ -0,0 +1,164 @@
+package reporting
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	colorReset  = "\033[0m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorGray   = "\033[90m"
+	colorBold   = "\033[1m"
+
+	progressBarWidth = 24
+)
+
+// IsTerminal reports whether f is an interactive terminal that can render progress bars. On
+// Windows this enables escape sequences in the console.
+func IsTerminal(f *os.File) bool {
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	return enableVirtualTerminal(f)
+}
+
+// ColorEnabled reports whether colored output should be written to f: it is a terminal and
+// NO_COLOR (https://no-color.org) is not set
+func ColorEnabled(f *os.File) bool {
+	return os.Getenv("NO_COLOR") == "" && IsTerminal(f)
+}
+
+// statusColor returns the color used for a step or scenario status
+func statusColor(status string) string {
+	switch status {
+	case "passed":
+		return colorGreen
+	case "failed":
+		return colorRed
+	case "expected_failure", "unexpected_pass", "warning":
+		return colorYellow
+	default:
+		return colorGray
+	}
+}
+
+func paint(enabled bool, color, s string) string {
+	if !enabled || color == "" {
+		return s
+	}
+	return color + s + colorReset
+}
+
+// Progress draws a live progress bar with step counters for the running scenario. Each finished
+// scenario leaves one line behind. It is safe for concurrent use by parallel test groups.
+type Progress struct {
+	mu  sync.Mutex
+	out io.Writer
+
+	scenarios int // scenarios in the run, including repetitions and matrix combinations
+	current   int
+	name      string
+	planned   int // steps declared by the scenario; data-driven rows can add more
+	counts    map[string]int
+	started   time.Time
+}
+
+// NewProgress returns a progress display writing terminal escape sequences to out
+func NewProgress(out io.Writer) *Progress {
+	return &Progress{out: out, counts: make(map[string]int)}
+}
+
+// Begin sets the number of scenarios the run will execute
+func (p *Progress) Begin(scenarios int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.scenarios = scenarios
+	p.current = 0
+}
+
+// StartScenario starts a new bar for a scenario with the given number of declared steps
+func (p *Progress) StartScenario(name string, steps int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current++
+	p.name = name
+	p.planned = steps
+	p.counts = make(map[string]int)
+	p.started = time.Now()
+	p.render()
+}
+
+// StepDone counts a finished step and redraws the bar
+func (p *Progress) StepDone(status string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.counts[status]++
+	p.render()
+}
+
+// FinishScenario replaces the bar with the scenario outcome
+func (p *Progress) FinishScenario(result ScenarioResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	mark := "✓"
+	switch result.Status {
+	case "failed":
+		mark = "✗"
+	case "skipped":
+		mark = "⊖"
+	}
+	fmt.Fprintf(p.out, "\r\033[K%s %s %s %s %s\n",
+		paint(true, statusColor(result.Status), mark),
+		p.position(),
+		result.Scenario.Name,
+		paint(true, colorGray, fmt.Sprintf("(%v)", result.Duration.Round(time.Millisecond))),
+		p.counters())
+}
+
+func (p *Progress) render() {
+	done := 0
+	for _, count := range p.counts {
+		done += count
+	}
+	total := p.planned
+	if done > total {
+		total = done
+	}
+
+	filled := progressBarWidth
+	if total > 0 {
+		filled = done * progressBarWidth / total
+	}
+	bar := paint(true, colorGreen, strings.Repeat("█", filled)) + paint(true, colorGray, strings.Repeat("░", progressBarWidth-filled))
+
+	fmt.Fprintf(p.out, "\r\033[K%s %s %s %d/%d %s %s",
+		p.position(), bar, p.name, done, total, p.counters(),
+		paint(true, colorGray, time.Since(p.started).Round(100*time.Millisecond).String()))
+}
+
+func (p *Progress) position() string {
+	if p.scenarios == 0 {
+		return ""
+	}
+	return paint(true, colorBold, fmt.Sprintf("[%d/%d]", p.current, p.scenarios))
+}
+
+func (p *Progress) counters() string {
+	passed := p.counts["passed"] + p.counts["expected_failure"]
+	return fmt.Sprintf("%s %s %s",
+		paint(true, colorGreen, fmt.Sprintf("✓ %d", passed)),
+		paint(true, colorRed, fmt.Sprintf("✗ %d", p.counts["failed"]+p.counts["unexpected_pass"])),
+		paint(true, colorGray, fmt.Sprintf("⊖ %d", p.counts["skipped"])))
+}
@@ -3,68 +3,163 @@ package reporting
 import (
 	"encoding/json"
 	"fmt"
-	"os"
+	"html/template"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/nulln0ne/fuego/pkg/assertions"
+	"github.com/nulln0ne/fuego/pkg/objectstore"
 	"github.com/nulln0ne/fuego/pkg/scenario"
 )
 
 type Report struct {
 	Summary   Summary          `json:"summary"`
 	Scenarios []ScenarioResult `json:"scenarios"`
+	Flakiness []StepFlakiness  `json:"flakiness,omitempty"`
 	StartTime time.Time        `json:"start_time"`
 	EndTime   time.Time        `json:"end_time"`
 	Duration  time.Duration    `json:"duration"`
+	Seed      int64            `json:"seed,omitempty"` // rerun with --seed to reproduce random choices
 	Config    ReportConfig     `json:"config,omitempty"`
+
+	// FailedScenarios lists failures with their owner, component and severity labels, most
+	// severe first
+	FailedScenarios []ScenarioFailure `json:"failed_scenarios,omitempty"`
+
+	// Status is environment_unavailable when a preflight check failed and no scenario ran
+	Status    string            `json:"status,omitempty"`
+	Preflight []PreflightResult `json:"preflight,omitempty"`
+
+	// Client is the traffic of the HTTP client, to spot connection churn
+	Client *ClientStats `json:"client,omitempty"`
+}
+
+// ClientStats counts the HTTP traffic of a run; bytes are request and response bodies
+type ClientStats struct {
+	Requests          int64   `json:"requests"`
+	BytesSent         int64   `json:"bytes_sent"`
+	BytesReceived     int64   `json:"bytes_received"`
+	NewConnections    int64   `json:"new_connections"`
+	ReusedConnections int64   `json:"reused_connections"`
+	ReuseRate         float64 `json:"reuse_rate"` // share of requests sent on a reused connection, 0-1
+}
+
+// StatusEnvironmentUnavailable marks a run stopped by failed preflight checks
+const StatusEnvironmentUnavailable = "environment_unavailable"
+
+// PreflightResult is the outcome of one preflight check
+type PreflightResult struct {
+	Name       string        `json:"name"`
+	URL        string        `json:"url"`
+	Passed     bool          `json:"passed"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Duration   time.Duration `json:"duration"`
+	Error      string        `json:"error,omitempty"`
 }
 
 type Summary struct {
-	Total    int     `json:"total"`
-	Passed   int     `json:"passed"`
-	Failed   int     `json:"failed"`
-	Skipped  int     `json:"skipped"`
-	PassRate float64 `json:"pass_rate"`
+	Total            int     `json:"total"`
+	Passed           int     `json:"passed"`
+	Failed           int     `json:"failed"`
+	Skipped          int     `json:"skipped"`
+	PassRate         float64 `json:"pass_rate"`
+	ExpectedFailures int     `json:"expected_failures,omitempty"`
+	UnexpectedPasses int     `json:"unexpected_passes,omitempty"`
 }
 
 type ScenarioResult struct {
 	Scenario  *scenario.Scenario     `json:"scenario"`
-	Status    string                 `json:"status"` // passed, failed, skipped
+	Run       int                    `json:"run,omitempty"` // 1-based repetition when running with --repeat
+	Status    string                 `json:"status"`        // passed, failed, skipped
 	StartTime time.Time              `json:"start_time"`
 	EndTime   time.Time              `json:"end_time"`
 	Duration  time.Duration          `json:"duration"`
 	Steps     []StepResult           `json:"steps"`
 	Error     string                 `json:"error,omitempty"`
+	Latency   []LatencyStats         `json:"latency,omitempty"`
+	Warnings  []string               `json:"warnings,omitempty"`
 	Variables map[string]interface{} `json:"variables,omitempty"`
 }
 
 type StepResult struct {
-	Step       *scenario.Step         `json:"step"`
-	Status     string                 `json:"status"` // passed, failed, skipped
-	StartTime  time.Time              `json:"start_time"`
-	EndTime    time.Time              `json:"end_time"`
-	Duration   time.Duration          `json:"duration"`
-	Request    interface{}            `json:"request,omitempty"`
-	Response   interface{}            `json:"response,omitempty"`
-	Assertions []assertions.Result    `json:"assertions,omitempty"`
-	Error      string                 `json:"error,omitempty"`
-	Variables  map[string]interface{} `json:"variables,omitempty"`
+	Step          *scenario.Step         `json:"step"`
+	Status        string                 `json:"status"` // passed, failed, skipped, expected_failure, unexpected_pass
+	StartTime     time.Time              `json:"start_time"`
+	EndTime       time.Time              `json:"end_time"`
+	Duration      time.Duration          `json:"duration"`
+	Iteration     int                    `json:"iteration,omitempty"` // 1-based data item for data-driven steps
+	Group         string                 `json:"group,omitempty"`     // test group or hook (before, setup, teardown, after) the step ran in
+	Request       interface{}            `json:"request,omitempty"`
+	Response      interface{}            `json:"response,omitempty"`
+	Assertions    []assertions.Result    `json:"assertions,omitempty"`
+	Error         string                 `json:"error,omitempty"`
+	Curl          string                 `json:"curl,omitempty"` // reproduction command for failed HTTP steps
+	CorrelationID string                 `json:"correlation_id,omitempty"`
+	KnownFailure  string                 `json:"known_failure,omitempty"`
+	Variables     map[string]interface{} `json:"variables,omitempty"` // variables the step set or changed
+	Artifacts     []Artifact             `json:"artifacts,omitempty"`
+	Timing        *StepTiming            `json:"timing,omitempty"`
+	Attempts      int                    `json:"attempts,omitempty"` // requests sent, including retries
+	Faults        []string               `json:"faults,omitempty"`   // injected faults, e.g. "attempt 1: status 503"
+}
+
+// StepTiming splits the duration of a step so a slow server can be told apart from slow
+// assertions or captures
+type StepTiming struct {
+	Interpolation time.Duration `json:"interpolation"` // resolving variables in the request
+	Request       time.Duration `json:"request"`       // sending the request and reading the response
+	Assertions    time.Duration `json:"assertions"`
+	Captures      time.Duration `json:"captures"` // captures and extracted variables
+}
+
+// Artifact is a file kept for a step, such as a saved response body
+type Artifact struct {
+	Name        string `json:"name"`
+	Path        string `json:"path"`
+	ContentType string `json:"content_type,omitempty"`
+	Size        int64  `json:"size"`
 }
 
 type ReportConfig struct {
-	Format      string `json:"format"` // console, json, html, markdown
+	Format      string `json:"format"` // console, json, jsonl, html, markdown, github, junit, csv, template, none
 	OutputFile  string `json:"output_file,omitempty"`
 	Verbose     bool   `json:"verbose"`
-	IncludeBody bool   `json:"include_body"`
+	IncludeBody bool   `json:"include_body"`    // keep response bodies in step results
+	Color       bool   `json:"color,omitempty"` // colored console output with a final summary table
+	Quiet       bool   `json:"quiet,omitempty"` // console output lists only the summary and failures
+	CI          bool   `json:"ci,omitempty"`    // plain markers, scenarios sorted by name, absolute timestamps
+
+	// Outputs are written in addition to Format, e.g. json and junit files next to console output
+	Outputs []ReportOutput `json:"outputs,omitempty"`
+	// Template is the Go template file rendered by the template format
+	Template string `json:"template,omitempty"`
+	// FailOn is the least severe metadata severity whose failures fail the run; failures of
+	// less severe scenarios are reported as warnings. Empty fails on every failure.
+	FailOn string `json:"fail_on,omitempty"`
+	// Storage authorizes uploads when OutputFile (or an output file) is an s3://, gs:// or
+	// azblob:// URL
+	Storage objectstore.Credentials `json:"-"`
 }
 
+// Reporter aggregates the event stream of a run into a Report. All methods are safe for
+// concurrent use.
 type Reporter struct {
+	mu     sync.Mutex
 	config ReportConfig
 	report *Report
+
+	handlers []EventHandler
+	streams  []*jsonlStream
+	sinks    []Sink
+	// sinkErrors collects delivery failures, reported once the report is generated
+	sinkErrors []string
 }
 
 func NewReporter(config ReportConfig) *Reporter {
-	return &Reporter{
+	r := &Reporter{
 		config: config,
 		report: &Report{
 			Summary:   Summary{},
@@ -73,20 +168,80 @@ func NewReporter(config ReportConfig) *Reporter {
 			Config:    config,
 		},
 	}
+
+	// JSON lines reports are written while the run progresses rather than at the end
+	if config.Format == "jsonl" {
+		r.streams = append(r.streams, newJSONLStream(config.OutputFile, config.Storage))
+	}
+	for _, output := range config.Outputs {
+		if output.Format == "jsonl" {
+			r.streams = append(r.streams, newJSONLStream(output.File, config.Storage))
+		}
+	}
+	for _, stream := range r.streams {
+		r.handlers = append(r.handlers, stream.handle)
+	}
+	return r
+}
+
+// SetSeed records the random seed of the run
+func (r *Reporter) SetSeed(seed int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Seed = seed
+}
+
+// SetPreflight records the preflight checks; when one failed the run is marked
+// environment_unavailable
+func (r *Reporter) SetPreflight(results []PreflightResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.report.Preflight = results
+	for _, result := range results {
+		if !result.Passed {
+			r.report.Status = StatusEnvironmentUnavailable
+		}
+	}
+}
+
+// SetClientStats records the HTTP traffic of the run and derives the connection reuse rate
+func (r *Reporter) SetClientStats(stats ClientStats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if connections := stats.NewConnections + stats.ReusedConnections; connections > 0 {
+		stats.ReuseRate = float64(stats.ReusedConnections) / float64(connections)
+	}
+	r.report.Client = &stats
 }
 
 func (r *Reporter) Start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.report.StartTime = time.Now()
 }
 
 func (r *Reporter) End() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	r.report.EndTime = time.Now()
 	r.report.Duration = r.report.EndTime.Sub(r.report.StartTime)
 	r.calculateSummary()
 }
 
+// AddScenarioResult adds a finished scenario to the report, as a scenario_finished event
 func (r *Reporter) AddScenarioResult(result ScenarioResult) {
-	r.report.Scenarios = append(r.report.Scenarios, result)
+	name := ""
+	if result.Scenario != nil {
+		name = result.Scenario.Name
+	}
+	r.Emit(Event{Type: EventScenarioFinished, Scenario: name, Run: result.Run, Result: &result})
+}
+
+// AddSink registers a sink that receives scenario results and the final report
+func (r *Reporter) AddSink(sink Sink) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sinks = append(r.sinks, sink)
 }
 
 func (r *Reporter) calculateSummary() {
@@ -94,6 +249,8 @@ func (r *Reporter) calculateSummary() {
 	passed := 0
 	failed := 0
 	skipped := 0
+	expectedFailures := 0
+	unexpectedPasses := 0
 
 	for _, scenario := range r.report.Scenarios {
 		switch scenario.Status {
@@ -104,6 +261,15 @@ func (r *Reporter) calculateSummary() {
 		case "skipped":
 			skipped++
 		}
+
+		for _, step := range scenario.Steps {
+			switch step.Status {
+			case "expected_failure":
+				expectedFailures++
+			case "unexpected_pass":
+				unexpectedPasses++
+			}
+		}
 	}
 
 	passRate := 0.0
@@ -117,16 +283,72 @@ func (r *Reporter) calculateSummary() {
 		Failed:   failed,
 		Skipped:  skipped,
 		PassRate: passRate,
+
+		ExpectedFailures: expectedFailures,
+		UnexpectedPasses: unexpectedPasses,
 	}
+	r.report.Flakiness = ComputeFlakiness(r.report.Scenarios)
+	r.report.FailedScenarios = ComputeFailures(r.report.Scenarios, r.config.FailOn)
 }
 
 func (r *Reporter) GetReport() *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
 	return r.report
 }
 
+// GenerateReport finishes the run: it emits run_finished, writes the reports and delivers the
+// report to the sinks. Files, uploads and sinks are written without holding the reporter's lock.
 func (r *Reporter) GenerateReport() error {
 	r.End()
+	r.Emit(Event{Type: EventRunFinished, Report: r.GetReport()})
+
+	r.mu.Lock()
+	streams := append([]*jsonlStream(nil), r.streams...)
+	sinks := append([]Sink(nil), r.sinks...)
+	r.mu.Unlock()
+
+	for _, stream := range streams {
+		if err := stream.error(); err != nil {
+			return fmt.Errorf("failed to write jsonl report: %w", err)
+		}
+	}
+
+	if err := r.generate(); err != nil {
+		return err
+	}
+
+	// Additional outputs render the same report, so one run feeds several consumers
+	for _, output := range r.config.Outputs {
+		config := r.config
+		config.Format, config.OutputFile, config.Outputs = output.Format, output.File, nil
+		extra := &Reporter{config: config, report: r.report}
+		if err := extra.generate(); err != nil {
+			return fmt.Errorf("failed to write %s report: %w", output.Format, err)
+		}
+	}
 
+	for _, sink := range sinks {
+		if err := sink.RunCompleted(r.report); err != nil {
+			r.addSinkError(err)
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.sinkErrors) > 0 {
+		return fmt.Errorf("failed to deliver results: %s", strings.Join(r.sinkErrors, "; "))
+	}
+	return nil
+}
+
+// WriteReport renders a finished report, e.g. one read back from a remote run, in config.Format
+func WriteReport(report *Report, config ReportConfig) error {
+	r := &Reporter{config: config, report: report}
+	return r.generate()
+}
+
+func (r *Reporter) generate() error {
 	switch r.config.Format {
 	case "json":
 		return r.generateJSONReport()
@@ -134,66 +356,319 @@ func (r *Reporter) GenerateReport() error {
 		return r.generateHTMLReport()
 	case "markdown":
 		return r.generateMarkdownReport()
+	case "github":
+		return r.generateGitHubReport()
+	case "junit":
+		return r.generateJUnitReport()
+	case "csv":
+		return r.generateCSVReport()
+	case "template":
+		return r.generateTemplateReport()
+	case "jsonl", "none":
+		// jsonl is streamed while the run progresses
+		return nil
 	default:
 		return r.generateConsoleReport()
 	}
 }
 
 func (r *Reporter) generateConsoleReport() error {
-	// Print summary
+	if r.report.Status == StatusEnvironmentUnavailable {
+		r.printPreflight()
+		return nil
+	}
+
+	// Interactive output ends with a summary table instead of starting with the totals
+	if !r.config.Color {
+		r.printConsoleSummary()
+	}
+	r.printConsoleDetails()
+	if r.config.Color {
+		r.printSummaryTable()
+	}
+	return nil
+}
+
+func (r *Reporter) printConsoleSummary() {
 	fmt.Printf("\n=== Test Results Summary ===\n")
 	fmt.Printf("Total Scenarios: %d\n", r.report.Summary.Total)
 	fmt.Printf("Passed: %d\n", r.report.Summary.Passed)
 	fmt.Printf("Failed: %d\n", r.report.Summary.Failed)
 	fmt.Printf("Skipped: %d\n", r.report.Summary.Skipped)
 	fmt.Printf("Pass Rate: %.2f%%\n", r.report.Summary.PassRate)
+	if r.report.Summary.ExpectedFailures > 0 || r.report.Summary.UnexpectedPasses > 0 {
+		fmt.Printf("Expected Failures: %d\n", r.report.Summary.ExpectedFailures)
+		fmt.Printf("Unexpected Passes: %d\n", r.report.Summary.UnexpectedPasses)
+	}
+	if r.config.CI {
+		fmt.Printf("Started: %s\n", ciTimestamp(r.report.StartTime))
+		fmt.Printf("Finished: %s\n", ciTimestamp(r.report.EndTime))
+	}
+	if r.config.FailOn != "" {
+		blocking, warnings := r.report.SeverityGate()
+		fmt.Printf("Blocking Failures: %d (severity %s or higher)\n", len(blocking), r.config.FailOn)
+		fmt.Printf("Warnings: %d\n", len(warnings))
+	}
 	fmt.Printf("Duration: %v\n", r.report.Duration)
+	if r.report.Seed != 0 {
+		fmt.Printf("Seed: %d\n", r.report.Seed)
+	}
+	if r.config.Verbose {
+		r.printClientStats()
+	}
+}
 
-	// Print scenario details
-	for _, scenario := range r.report.Scenarios {
-		status := "✓"
-		switch scenario.Status {
-		case "failed":
-			status = "✗"
-		case "skipped":
-			status = "⊖"
+// printClientStats prints the HTTP traffic of the run
+func (r *Reporter) printClientStats() {
+	if client := r.report.Client; client != nil && client.Requests > 0 {
+		fmt.Printf("HTTP: %d requests, %d new and %d reused connections (%.1f%% reuse), %d bytes sent, %d received\n",
+			client.Requests, client.NewConnections, client.ReusedConnections, client.ReuseRate*100, client.BytesSent, client.BytesReceived)
+	}
+}
+
+var (
+	consoleMarks = map[string]string{"passed": "✓", "failed": "✗", "skipped": "⊖", "expected_failure": "⚠", "unexpected_pass": "!", "warning": "⚠"}
+	// ciMarks replace symbols that CI log viewers often render badly
+	ciMarks = map[string]string{"passed": "PASS", "failed": "FAIL", "skipped": "SKIP", "expected_failure": "XFAIL", "unexpected_pass": "XPASS", "warning": "WARN"}
+)
+
+// mark returns the console marker of a status
+func (r *Reporter) mark(status string) string {
+	marks := consoleMarks
+	if r.config.CI {
+		marks = ciMarks
+	}
+	mark, ok := marks[status]
+	if !ok {
+		mark = marks["passed"]
+	}
+	return paint(r.config.Color, statusColor(status), mark)
+}
+
+// ciTimestamp formats an absolute UTC time for --ci output
+func ciTimestamp(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+// consoleScenarios returns the scenarios in print order: run order, or sorted by name with --ci
+func (r *Reporter) consoleScenarios() []ScenarioResult {
+	if !r.config.CI {
+		return r.report.Scenarios
+	}
+	scenarios := append([]ScenarioResult(nil), r.report.Scenarios...)
+	sort.SliceStable(scenarios, func(i, j int) bool {
+		if scenarios[i].Scenario.Name != scenarios[j].Scenario.Name {
+			return scenarios[i].Scenario.Name < scenarios[j].Scenario.Name
 		}
+		return scenarios[i].Run < scenarios[j].Run
+	})
+	return scenarios
+}
 
-		fmt.Printf("\n%s %s (%v)\n", status, scenario.Scenario.Name, scenario.Duration)
+func failedStatus(status string) bool {
+	return status == "failed" || status == "unexpected_pass"
+}
 
-		if r.config.Verbose {
-			for _, step := range scenario.Steps {
-				stepStatus := "  ✓"
-				switch step.Status {
-				case "failed":
-					stepStatus = "  ✗"
-				case "skipped":
-					stepStatus = "  ⊖"
-				}
+func (r *Reporter) printConsoleDetails() {
+	for _, scenario := range r.consoleScenarios() {
+		// Quiet output only lists failures
+		if r.config.Quiet && scenario.Status != "failed" {
+			continue
+		}
 
-				fmt.Printf("%s %s (%v)\n", stepStatus, step.Step.Name, step.Duration)
+		prefix := ""
+		if r.config.CI {
+			prefix = "[" + ciTimestamp(scenario.StartTime) + "] "
+		}
+		fmt.Printf("\n%s%s %s (%v)\n", prefix, r.mark(scenario.Status), scenario.Scenario.Name, scenario.Duration)
 
-				if step.Error != "" {
-					fmt.Printf("    Error: %s\n", step.Error)
+		if r.config.Verbose || r.config.Quiet {
+			for _, step := range scenario.Steps {
+				if r.config.Quiet && !failedStatus(step.Status) {
+					continue
 				}
+				r.printConsoleStep(step)
+			}
+		}
 
-				// Print assertion results
-				for _, assertion := range step.Assertions {
-					assertionStatus := "    ✓"
-					if !assertion.Passed {
-						assertionStatus = "    ✗"
-					}
-					fmt.Printf("%s %s\n", assertionStatus, assertion.Message)
+		if len(scenario.Latency) > 0 {
+			if !r.config.Quiet {
+				fmt.Printf("  Latency:\n")
+			}
+			for _, stats := range scenario.Latency {
+				if r.config.Quiet && len(stats.Violations) == 0 {
+					continue
+				}
+				fmt.Printf("    %s: n=%d min=%v avg=%v p95=%v max=%v\n",
+					stats.Step, stats.Count, stats.Min, stats.Avg, stats.P95, stats.Max)
+				for _, violation := range stats.Violations {
+					fmt.Printf("      %s %s\n", r.mark("failed"), violation)
 				}
 			}
 		}
 
+		if !r.config.Quiet {
+			for _, warning := range scenario.Warnings {
+				fmt.Printf("  Warning: %s\n", warning)
+			}
+		}
+
 		if scenario.Error != "" {
 			fmt.Printf("  Error: %s\n", scenario.Error)
 		}
 	}
 
-	return nil
+	if hasOwnership(r.report.FailedScenarios) {
+		fmt.Printf("\n=== Failures by Owner ===\n")
+		owners, groups := FailuresByOwner(r.report.FailedScenarios)
+		for _, owner := range owners {
+			fmt.Printf("%s\n", owner)
+			for _, failure := range groups[owner] {
+				status := "failed"
+				if !failure.Blocking {
+					status = "warning"
+				}
+				fmt.Printf("  %s %s%s\n", r.mark(status), failureTriage(failure), failure.Scenario)
+				for _, message := range failure.Failures {
+					fmt.Printf("    %s\n", message)
+				}
+			}
+		}
+	}
+
+	if len(r.report.Flakiness) > 0 {
+		fmt.Printf("\n=== Flakiness ===\n")
+		for _, stats := range r.report.Flakiness {
+			marker := " "
+			if stats.Flaky() {
+				marker = "~"
+			} else if stats.Passed == 0 {
+				marker = r.mark("failed")
+			} else if r.config.Quiet {
+				continue
+			}
+			fmt.Printf("%s %s / %s: %d/%d passed (%.0f%%)\n", marker, stats.Scenario, stats.Step, stats.Passed, stats.Runs, stats.PassRatio*100)
+		}
+	}
+}
+
+func (r *Reporter) printConsoleStep(step StepResult) {
+	prefix := ""
+	if r.config.CI {
+		prefix = "[" + ciTimestamp(step.StartTime) + "] "
+	}
+	fmt.Printf("  %s%s %s (%v)\n", prefix, r.mark(step.Status), step.Step.Name, step.Duration)
+
+	if r.config.Verbose && step.Timing != nil {
+		fmt.Printf("    Timing: interpolation %v, request %v, assertions %v, captures %v\n",
+			step.Timing.Interpolation, step.Timing.Request, step.Timing.Assertions, step.Timing.Captures)
+	}
+
+	if step.Attempts > 1 {
+		fmt.Printf("    Attempts: %d\n", step.Attempts)
+	}
+	for _, fault := range step.Faults {
+		fmt.Printf("    Injected fault: %s\n", fault)
+	}
+
+	if step.KnownFailure != "" {
+		fmt.Printf("    Known failure: %s\n", step.KnownFailure)
+	}
+
+	if step.Error != "" {
+		fmt.Printf("    Error: %s\n", step.Error)
+	}
+
+	if step.Curl != "" {
+		fmt.Printf("    Reproduce: %s\n", step.Curl)
+	}
+
+	if step.CorrelationID != "" && step.Status == "failed" {
+		fmt.Printf("    Correlation ID: %s\n", step.CorrelationID)
+	}
+
+	for _, artifact := range step.Artifacts {
+		fmt.Printf("    Artifact: %s (%s)\n", artifact.Path, artifact.Name)
+	}
+
+	// Print assertion results
+	for _, assertion := range step.Assertions {
+		if r.config.Quiet && assertion.Passed {
+			continue
+		}
+		status := "passed"
+		if !assertion.Passed {
+			status = "failed"
+		}
+		fmt.Printf("    %s %s\n", r.mark(status), assertion.Message)
+	}
+}
+
+// printSummaryTable prints one colored row per scenario followed by the totals of the run
+func (r *Reporter) printSummaryTable() {
+	nameWidth := len("Scenario")
+	for _, scenario := range r.report.Scenarios {
+		if width := len([]rune(scenarioLabel(scenario))); width > nameWidth {
+			nameWidth = width
+		}
+	}
+
+	fmt.Printf("\n%s\n", paint(true, colorBold, "=== Test Results Summary ==="))
+	fmt.Printf("%s\n", paint(true, colorBold, fmt.Sprintf("%-*s  %-8s  %6s  %6s  %7s  %s", nameWidth, "Scenario", "Status", "Passed", "Failed", "Skipped", "Duration")))
+	for _, scenario := range r.report.Scenarios {
+		counts := make(map[string]int)
+		for _, step := range scenario.Steps {
+			counts[step.Status]++
+		}
+		fmt.Printf("%-*s  %s  %6d  %6d  %7d  %v\n",
+			nameWidth, scenarioLabel(scenario),
+			paint(true, statusColor(scenario.Status), fmt.Sprintf("%-8s", scenario.Status)),
+			counts["passed"]+counts["expected_failure"], counts["failed"]+counts["unexpected_pass"], counts["skipped"],
+			scenario.Duration.Round(time.Millisecond))
+	}
+
+	summary := r.report.Summary
+	totals := fmt.Sprintf("%d scenarios: %s, %s, %s (%.2f%% pass rate) in %v",
+		summary.Total,
+		paint(true, colorGreen, fmt.Sprintf("%d passed", summary.Passed)),
+		paint(true, colorRed, fmt.Sprintf("%d failed", summary.Failed)),
+		paint(true, colorGray, fmt.Sprintf("%d skipped", summary.Skipped)),
+		summary.PassRate, r.report.Duration.Round(time.Millisecond))
+	fmt.Printf("\n%s\n", totals)
+	if summary.ExpectedFailures > 0 || summary.UnexpectedPasses > 0 {
+		fmt.Printf("%s\n", paint(true, colorYellow, fmt.Sprintf("%d expected failures, %d unexpected passes", summary.ExpectedFailures, summary.UnexpectedPasses)))
+	}
+	if r.report.Seed != 0 {
+		fmt.Printf("Seed: %d\n", r.report.Seed)
+	}
+	if r.config.Verbose {
+		r.printClientStats()
+	}
+}
+
+// printPreflight explains why no scenario ran
+func (r *Reporter) printPreflight() {
+	fmt.Printf("\n%s\n", paint(r.config.Color, colorRed, "=== Environment Unavailable ==="))
+	fmt.Println("Preflight checks failed, no scenario was run:")
+	for _, check := range r.report.Preflight {
+		status := "passed"
+		detail := fmt.Sprintf("status %d", check.StatusCode)
+		if !check.Passed {
+			status = "failed"
+		}
+		if check.Error != "" {
+			detail = check.Error
+		}
+		fmt.Printf("  %s %s (%s, %v)\n", r.mark(status), check.Name, detail, check.Duration.Round(time.Millisecond))
+	}
+}
+
+// scenarioLabel names a scenario result, with the run number when repeating
+func scenarioLabel(scenario ScenarioResult) string {
+	if scenario.Run > 0 {
+		return fmt.Sprintf("%s #%d", scenario.Scenario.Name, scenario.Run)
+	}
+	return scenario.Scenario.Name
 }
 
 func (r *Reporter) generateJSONReport() error {
@@ -203,7 +678,7 @@ func (r *Reporter) generateJSONReport() error {
 	}
 
 	if r.config.OutputFile != "" {
-		return os.WriteFile(r.config.OutputFile, jsonData, 0644)
+		return r.writeOutput(jsonData)
 	}
 
 	fmt.Println(string(jsonData))
@@ -214,7 +689,7 @@ func (r *Reporter) generateHTMLReport() error {
 	html := r.generateHTMLContent()
 
 	if r.config.OutputFile != "" {
-		return os.WriteFile(r.config.OutputFile, []byte(html), 0644)
+		return r.writeOutput([]byte(html))
 	}
 
 	fmt.Println(html)
@@ -237,9 +712,17 @@ func (r *Reporter) generateHTMLContent() string {
         .step { margin: 10px 0; padding: 10px; border-left: 3px solid #ddd; }
         .step.passed { border-left-color: #28a745; }
         .step.failed { border-left-color: #dc3545; }
+        .step.expected_failure { border-left-color: #ffc107; }
+        .step.unexpected_pass { border-left-color: #fd7e14; }
+        .known-failure { color: #856404; font-size: 0.9em; }
+        .description, .docs { color: #555; font-size: 0.9em; }
         .assertions { margin-left: 20px; font-size: 0.9em; }
         .assertion.passed { color: #28a745; }
         .assertion.failed { color: #dc3545; }
+        .latency { margin: 0 15px 15px; border-collapse: collapse; font-size: 0.9em; }
+        .latency th, .latency td { border: 1px solid #ddd; padding: 4px 8px; text-align: left; }
+        .artifact { font-size: 0.9em; }
+        .curl { background: #f5f5f5; padding: 8px; white-space: pre-wrap; word-break: break-all; font-size: 0.85em; }
     </style>
 </head>
 <body>
@@ -285,29 +768,93 @@ func (r *Reporter) generateScenariosHTML() string {
 				assertionsHTML += fmt.Sprintf(`<div class="assertion %s">%s</div>`, status, assertion.Message)
 			}
 
+			curlHTML := ""
+			if step.Curl != "" {
+				curlHTML = fmt.Sprintf(`<pre class="curl">%s</pre>`, template.HTMLEscapeString(step.Curl))
+			}
+
+			artifactsHTML := ""
+			for _, artifact := range step.Artifacts {
+				artifactsHTML += fmt.Sprintf(`<div class="artifact"><a href="%s">%s</a> (%s, %d bytes)</div>`,
+					template.HTMLEscapeString(r.artifactLink(artifact.Path)), template.HTMLEscapeString(artifact.Name),
+					template.HTMLEscapeString(artifact.ContentType), artifact.Size)
+			}
+
+			// What the step verifies and where its contract lives help triage a failure
+			docsHTML := ""
+			if failedStatus(step.Status) {
+				if step.Step.Description != "" {
+					docsHTML += fmt.Sprintf(`<div class="description">%s</div>`, template.HTMLEscapeString(step.Step.Description))
+				}
+				if step.Step.DocsURL != "" {
+					docsHTML += fmt.Sprintf(`<div class="docs"><a href="%s">Documentation</a></div>`, template.HTMLEscapeString(step.Step.DocsURL))
+				}
+			}
+
+			knownFailureHTML := ""
+			if step.KnownFailure != "" {
+				knownFailureHTML = fmt.Sprintf(`<div class="known-failure">Known failure: %s</div>`, template.HTMLEscapeString(step.KnownFailure))
+			}
+
 			stepsHTML += fmt.Sprintf(`
 				<div class="step %s">
 					<strong>%s</strong> (%v)
+					%s
+					%s
 					<div class="assertions">%s</div>
+					%s
+					%s
 				</div>`,
-				step.Status, step.Step.Name, step.Duration, assertionsHTML)
+				step.Status, step.Step.Name, step.Duration, docsHTML, knownFailureHTML, assertionsHTML, curlHTML, artifactsHTML)
+		}
+
+		latencyHTML := ""
+		if len(scenario.Latency) > 0 {
+			rows := ""
+			for _, stats := range scenario.Latency {
+				rows += fmt.Sprintf(`<tr><td>%s</td><td>%d</td><td>%v</td><td>%v</td><td>%v</td><td>%v</td><td>%s</td></tr>`,
+					template.HTMLEscapeString(stats.Step), stats.Count, stats.Min, stats.Avg, stats.P95, stats.Max,
+					template.HTMLEscapeString(strings.Join(stats.Violations, "; ")))
+			}
+			latencyHTML = fmt.Sprintf(`<table class="latency"><tr><th>Step</th><th>Count</th><th>Min</th><th>Avg</th><th>P95</th><th>Max</th><th>SLO violations</th></tr>%s</table>`, rows)
 		}
 
 		html += fmt.Sprintf(`
 			<div class="scenario %s">
 				<div class="scenario-header">%s (%v)</div>
 				<div class="steps">%s</div>
+				%s
 			</div>`,
-			scenario.Status, scenario.Scenario.Name, scenario.Duration, stepsHTML)
+			scenario.Status, scenario.Scenario.Name, scenario.Duration, stepsHTML, latencyHTML)
 	}
 	return html
 }
 
+// artifactLink returns the artifact path relative to the HTML report so links work when both are
+// published together
+func (r *Reporter) artifactLink(path string) string {
+	if r.config.OutputFile == "" || objectstore.IsURL(r.config.OutputFile) {
+		return filepath.ToSlash(path)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	reportDir, err := filepath.Abs(filepath.Dir(r.config.OutputFile))
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+	if rel, err := filepath.Rel(reportDir, absPath); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(path)
+}
+
 func (r *Reporter) generateMarkdownReport() error {
 	markdown := r.generateMarkdownContent()
 
 	if r.config.OutputFile != "" {
-		return os.WriteFile(r.config.OutputFile, []byte(markdown), 0644)
+		return r.writeOutput([]byte(markdown))
 	}
 
 	fmt.Println(markdown)
@@ -337,10 +884,27 @@ func (r *Reporter) generateMarkdownContent() string {
 					stepStatus = "❌"
 				case "skipped":
 					stepStatus = "⏭️"
+				case "expected_failure":
+					stepStatus = "⚠️"
+				case "unexpected_pass":
+					stepStatus = "❗"
 				}
 
 				scenariosMarkdown += fmt.Sprintf("- %s **%s** (%v)\n", stepStatus, step.Step.Name, step.Duration)
 
+				if failedStatus(step.Status) {
+					if step.Step.Description != "" {
+						scenariosMarkdown += fmt.Sprintf("  - %s\n", step.Step.Description)
+					}
+					if step.Step.DocsURL != "" {
+						scenariosMarkdown += fmt.Sprintf("  - Docs: <%s>\n", step.Step.DocsURL)
+					}
+				}
+
+				if step.KnownFailure != "" {
+					scenariosMarkdown += fmt.Sprintf("  - Known failure: %s\n", step.KnownFailure)
+				}
+
 				if len(step.Assertions) > 0 {
 					for _, assertion := range step.Assertions {
 						assertionStatus := "✅"
@@ -350,11 +914,55 @@ func (r *Reporter) generateMarkdownContent() string {
 						scenariosMarkdown += fmt.Sprintf("  - %s %s\n", assertionStatus, assertion.Message)
 					}
 				}
+
+				if step.Curl != "" {
+					scenariosMarkdown += fmt.Sprintf("\n  ```sh\n  %s\n  ```\n", step.Curl)
+				}
+				for _, artifact := range step.Artifacts {
+					scenariosMarkdown += fmt.Sprintf("  - Artifact: [%s](%s)\n", artifact.Name, filepath.ToSlash(artifact.Path))
+				}
+			}
+			scenariosMarkdown += "\n"
+		}
+
+		if len(scenario.Latency) > 0 {
+			scenariosMarkdown += "### Latency\n\n"
+			scenariosMarkdown += "| Step | Count | Min | Avg | P95 | Max | SLO violations |\n"
+			scenariosMarkdown += "|------|-------|-----|-----|-----|-----|----------------|\n"
+			for _, stats := range scenario.Latency {
+				scenariosMarkdown += fmt.Sprintf("| %s | %d | %v | %v | %v | %v | %s |\n",
+					stats.Step, stats.Count, stats.Min, stats.Avg, stats.P95, stats.Max, strings.Join(stats.Violations, "; "))
 			}
 			scenariosMarkdown += "\n"
 		}
 	}
 
+	if hasOwnership(r.report.FailedScenarios) {
+		scenariosMarkdown += "## Failures by Owner\n\n"
+		scenariosMarkdown += "| Owner | Severity | Component | Scenario | Failures |\n"
+		scenariosMarkdown += "|-------|----------|-----------|----------|----------|\n"
+		owners, groups := FailuresByOwner(r.report.FailedScenarios)
+		for _, owner := range owners {
+			for _, failure := range groups[owner] {
+				scenariosMarkdown += fmt.Sprintf("| %s | %s | %s | %s | %s |\n",
+					owner, failure.Severity, failure.Component, failure.Scenario,
+					strings.ReplaceAll(strings.Join(failure.Failures, "<br>"), "|", "\\|"))
+			}
+		}
+		scenariosMarkdown += "\n"
+	}
+
+	if len(r.report.Flakiness) > 0 {
+		scenariosMarkdown += "## Flakiness\n\n"
+		scenariosMarkdown += "| Scenario | Step | Passed | Runs | Pass ratio |\n"
+		scenariosMarkdown += "|----------|------|--------|------|------------|\n"
+		for _, stats := range r.report.Flakiness {
+			scenariosMarkdown += fmt.Sprintf("| %s | %s | %d | %d | %.0f%% |\n",
+				stats.Scenario, stats.Step, stats.Passed, stats.Runs, stats.PassRatio*100)
+		}
+		scenariosMarkdown += "\n"
+	}
+
 	return fmt.Sprintf(`# Fuego Test Report
 
 ## Summary
@@ -0,0 +1,57 @@
+package reporting
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// LoadReport reads a report written with --format json
+func LoadReport(path string) (*Report, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %w", err)
+	}
+	var report Report
+	if err := json.Unmarshal(content, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse report %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// FailedSteps maps the name of every failed scenario to the logical names of its failed steps
+func (r *Report) FailedSteps() map[string][]string {
+	failures := make(map[string][]string)
+	for _, result := range r.Scenarios {
+		if result.Status != "failed" || result.Scenario == nil {
+			continue
+		}
+		steps := failures[result.Scenario.Name]
+		seen := make(map[string]bool)
+		for _, step := range result.Steps {
+			name := step.LogicalName()
+			if failedStatus(step.Status) && name != "" && !seen[name] {
+				seen[name] = true
+				steps = append(steps, name)
+			}
+		}
+		failures[result.Scenario.Name] = steps
+	}
+	return failures
+}
+
+// SourceFiles lists the files the failed scenarios were loaded from, in report order
+func (r *Report) SourceFiles() []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, result := range r.Scenarios {
+		if result.Status != "failed" || result.Scenario == nil || result.Scenario.SourceFile == "" {
+			continue
+		}
+		if !seen[result.Scenario.SourceFile] {
+			seen[result.Scenario.SourceFile] = true
+			files = append(files, result.Scenario.SourceFile)
+		}
+	}
+	return files
+}
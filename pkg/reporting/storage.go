@@ -0,0 +1,35 @@
+package reporting
+
+import (
+	"os"
+	"path"
+
+	"github.com/nulln0ne/fuego/pkg/objectstore"
+)
+
+// reportContentTypes are the content types uploaded reports are stored with, by file extension
+var reportContentTypes = map[string]string{
+	".json":  "application/json",
+	".jsonl": "application/x-ndjson",
+	".html":  "text/html; charset=utf-8",
+	".md":    "text/markdown; charset=utf-8",
+	".xml":   "application/xml",
+	".csv":   "text/csv; charset=utf-8",
+}
+
+// writeOutput writes a report to OutputFile, uploading it when that is an object store URL
+// (s3://, gs:// or azblob://) so CI jobs need no extra upload step
+func (r *Reporter) writeOutput(data []byte) error {
+	return writeReportFile(r.config.OutputFile, data, r.config.Storage)
+}
+
+func writeReportFile(location string, data []byte, storage objectstore.Credentials) error {
+	if !objectstore.IsURL(location) {
+		return os.WriteFile(location, data, 0644)
+	}
+	contentType, ok := reportContentTypes[path.Ext(location)]
+	if !ok {
+		contentType = "text/plain; charset=utf-8"
+	}
+	return objectstore.Put(location, data, contentType, storage)
+}
@@ -0,0 +1,197 @@
+package reporting
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+)
+
+// RunSummary is the compact outcome of a run, meant for CI gating without parsing the full report
+type RunSummary struct {
+	Passed           bool               `json:"passed"`
+	Total            int                `json:"total"`
+	PassedScenarios  int                `json:"passed_scenarios"`
+	FailedScenarios  int                `json:"failed_scenarios"`
+	Skipped          int                `json:"skipped"`
+	PassRate         float64            `json:"pass_rate"`
+	DurationMs       int64              `json:"duration_ms"`
+	ExpectedFailures int                `json:"expected_failures"`
+	UnexpectedPasses int                `json:"unexpected_passes"`
+	FailedSteps      []string           `json:"failed_steps"`
+	Warnings         []string           `json:"warnings,omitempty"` // failures below fail_on severity
+	Thresholds       []ThresholdVerdict `json:"thresholds"`
+	Seed             int64              `json:"seed,omitempty"`
+}
+
+// ThresholdVerdict is the outcome of one latency SLO of one step
+type ThresholdVerdict struct {
+	Scenario string        `json:"scenario"`
+	Step     string        `json:"step"`
+	Run      int           `json:"run,omitempty"`
+	Metric   string        `json:"metric"`
+	Limit    time.Duration `json:"limit"`
+	Actual   time.Duration `json:"actual"`
+	Passed   bool          `json:"passed"`
+	Blocking bool          `json:"blocking"`
+}
+
+// Summary builds the run summary from the collected scenario results
+func (r *Reporter) Summary() RunSummary {
+	summary := RunSummary{
+		Total:            r.report.Summary.Total,
+		PassedScenarios:  r.report.Summary.Passed,
+		FailedScenarios:  r.report.Summary.Failed,
+		Skipped:          r.report.Summary.Skipped,
+		PassRate:         r.report.Summary.PassRate,
+		DurationMs:       r.report.Duration.Milliseconds(),
+		ExpectedFailures: r.report.Summary.ExpectedFailures,
+		UnexpectedPasses: r.report.Summary.UnexpectedPasses,
+		FailedSteps:      []string{},
+		Thresholds:       []ThresholdVerdict{},
+		Seed:             r.report.Seed,
+	}
+
+	seen := make(map[string]bool)
+	for _, result := range r.report.Scenarios {
+		name := ""
+		if result.Scenario != nil {
+			name = result.Scenario.Name
+		}
+
+		severity := ""
+		if result.Scenario != nil {
+			severity = result.Scenario.Metadata.Labels[LabelSeverity]
+		}
+		blocking := Blocks(severity, r.config.FailOn)
+
+		slos := make(map[string]map[string]time.Duration)
+		for _, step := range result.Steps {
+			if step.Step == nil {
+				continue
+			}
+			if len(step.Step.SLO) > 0 {
+				slos[step.LogicalName()] = step.Step.SLO
+			}
+			if step.Status != "failed" {
+				continue
+			}
+
+			failed := fmt.Sprintf("%s / %s", name, step.LogicalName())
+			if !seen[failed] {
+				seen[failed] = true
+				summary.FailedSteps = append(summary.FailedSteps, failed)
+			}
+		}
+
+		for _, stats := range result.Latency {
+			summary.Thresholds = append(summary.Thresholds, thresholdVerdicts(name, result.Run, stats, slos[stats.Step], blocking)...)
+		}
+	}
+
+	_, warnings := r.report.SeverityGate()
+	for _, failure := range warnings {
+		summary.Warnings = append(summary.Warnings, fmt.Sprintf("%s failed (severity %s)", failure.Scenario, severityOrNone(failure.Severity)))
+	}
+
+	summary.Passed = summary.FailedScenarios == len(warnings)
+	for _, verdict := range summary.Thresholds {
+		if verdict.Passed {
+			continue
+		}
+		if verdict.Blocking {
+			summary.Passed = false
+		} else {
+			summary.Warnings = append(summary.Warnings, fmt.Sprintf("%s / %s: %s %v exceeds %v", verdict.Scenario, verdict.Step, verdict.Metric, verdict.Actual, verdict.Limit))
+		}
+	}
+
+	return summary
+}
+
+// WriteSummary writes the run summary as JSON to path
+func (r *Reporter) WriteSummary(path string) error {
+	jsonData, err := json.MarshalIndent(r.Summary(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal summary to JSON: %w", err)
+	}
+
+	if err := os.WriteFile(path, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write summary %s: %w", path, err)
+	}
+	return nil
+}
+
+func thresholdVerdicts(scenarioName string, run int, stats LatencyStats, slo map[string]time.Duration, blocking bool) []ThresholdVerdict {
+	metrics := make([]string, 0, len(slo))
+	for metric := range slo {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+
+	verdicts := make([]ThresholdVerdict, 0, len(metrics))
+	for _, metric := range metrics {
+		actual, ok := stats.Stat(metric)
+		verdicts = append(verdicts, ThresholdVerdict{
+			Scenario: scenarioName,
+			Step:     stats.Step,
+			Run:      run,
+			Metric:   metric,
+			Limit:    slo[metric],
+			Actual:   actual,
+			Passed:   ok && actual <= slo[metric],
+			Blocking: blocking,
+		})
+	}
+	return verdicts
+}
+
+func severityOrNone(severity string) string {
+	if severity == "" {
+		return "none"
+	}
+	return severity
+}
+
+// Passed reports whether every scenario passed
+func (r *Report) Passed() bool {
+	for _, result := range r.Scenarios {
+		if result.Status == "failed" {
+			return false
+		}
+	}
+	return true
+}
+
+// Failures describes every failed step and scenario-level error, one message each
+func (r *Report) Failures() []string {
+	var failures []string
+	for _, result := range r.Scenarios {
+		name := ""
+		if result.Scenario != nil {
+			name = result.Scenario.Name
+		}
+
+		stepFailed := false
+		for _, step := range result.Steps {
+			if step.Status != "failed" || step.Step == nil {
+				continue
+			}
+			stepFailed = true
+
+			message := step.Error
+			for _, assertion := range step.Assertions {
+				if !assertion.Passed {
+					message = joinMessage(message, assertion.Message)
+				}
+			}
+			failures = append(failures, fmt.Sprintf("%s / %s: %s", name, step.Step.Name, message))
+		}
+
+		if result.Status == "failed" && result.Error != "" && !stepFailed {
+			failures = append(failures, fmt.Sprintf("%s: %s", name, result.Error))
+		}
+	}
+	return failures
+}
@@ -0,0 +1,84 @@
+package reporting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateFuncs are available to user report templates in addition to the text/template builtins
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		data, err := json.MarshalIndent(v, "", "  ")
+		return string(data), err
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"join":  strings.Join,
+	"repeat": func(count int, s string) string {
+		return strings.Repeat(s, count)
+	},
+	"duration": func(d time.Duration) string {
+		return d.Round(time.Millisecond).String()
+	},
+	"percent": func(value float64) string {
+		return fmt.Sprintf("%.1f%%", value)
+	},
+	"timestamp": func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	},
+	"icon": func(status string) string {
+		return consoleMarks[status]
+	},
+}
+
+// generateTemplateReport renders the Go template at config.Template with the Report, for bespoke
+// summaries (Confluence pages, chat messages, ...) without changing the reporting package
+func (r *Reporter) generateTemplateReport() error {
+	output, err := r.renderTemplate()
+	if err != nil {
+		return err
+	}
+
+	if r.config.OutputFile != "" {
+		return r.writeOutput(output)
+	}
+
+	fmt.Print(string(output))
+	return nil
+}
+
+func (r *Reporter) renderTemplate() ([]byte, error) {
+	tmpl, err := ParseReportTemplate(r.config.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, r.report); err != nil {
+		return nil, fmt.Errorf("failed to render report template %s: %w", r.config.Template, err)
+	}
+	return b.Bytes(), nil
+}
+
+// ParseReportTemplate loads a report template so mistakes are reported before the run starts
+func ParseReportTemplate(path string) (*template.Template, error) {
+	if path == "" {
+		return nil, fmt.Errorf("no report template configured")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report template: %w", err)
+	}
+
+	tmpl, err := template.New(path).Funcs(templateFuncs).Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse report template: %w", err)
+	}
+	return tmpl, nil
+}
@@ -0,0 +1,101 @@
+package reporting
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Sink receives results while the run progresses, e.g. to push them to a dashboard or a
+// test-management system
+type Sink interface {
+	ScenarioCompleted(result ScenarioResult) error
+	RunCompleted(report *Report) error
+}
+
+// WebhookSink POSTs the final report ("run" event) and/or every scenario result as it completes
+// ("scenario" event) as JSON to a URL; fuego monitor also sends "alert" events. The
+// X-Fuego-Event header tells payloads apart.
+type WebhookSink struct {
+	URL     string
+	Headers map[string]string
+	Events  []string // defaults to run
+	Client  *http.Client
+}
+
+// NewWebhookSink returns a sink posting to url with the given headers (e.g. Authorization)
+func NewWebhookSink(url string, headers map[string]string, events []string, timeout time.Duration) *WebhookSink {
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	return &WebhookSink{
+		URL:     url,
+		Headers: headers,
+		Events:  events,
+		Client:  &http.Client{Timeout: timeout},
+	}
+}
+
+func (s *WebhookSink) ScenarioCompleted(result ScenarioResult) error {
+	if !s.wants("scenario") {
+		return nil
+	}
+	return s.post("scenario", result)
+}
+
+func (s *WebhookSink) RunCompleted(report *Report) error {
+	if !s.wants("run") {
+		return nil
+	}
+	return s.post("run", report)
+}
+
+// Notify POSTs any other payload, e.g. the alerts of fuego monitor, when the sink lists the event
+func (s *WebhookSink) Notify(event string, payload interface{}) error {
+	if !s.wants(event) {
+		return nil
+	}
+	return s.post(event, payload)
+}
+
+func (s *WebhookSink) wants(event string) bool {
+	if len(s.Events) == 0 {
+		return event == "run"
+	}
+	for _, wanted := range s.Events {
+		if wanted == event {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *WebhookSink) post(event string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s payload: %w", event, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook URL: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Fuego-Event", event)
+	for name, value := range s.Headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := s.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to post %s results to webhook: %w", event, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s returned %s", s.URL, resp.Status)
+	}
+	return nil
+}
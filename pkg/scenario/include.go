@@ -0,0 +1,88 @@
+package scenario
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/sops"
+	"gopkg.in/yaml.v3"
+)
+
+// includeTag replaces a node with the content of another YAML or JSON file, e.g.
+// `headers: !include shared/auth-headers.yaml`. Paths are relative to the including file.
+const includeTag = "!include"
+
+// includer resolves !include tags and remembers which file each included node came from
+type includer struct {
+	files map[*yaml.Node]string
+	stack []string // files being included, to catch cycles
+	// ageKeyFile decrypts encrypted includes, see LoadOptions
+	ageKeyFile string
+}
+
+func newIncluder(file, ageKeyFile string) *includer {
+	return &includer{files: make(map[*yaml.Node]string), stack: []string{file}, ageKeyFile: ageKeyFile}
+}
+
+// resolve replaces every !include node under node with the document it names
+func (inc *includer) resolve(node *yaml.Node, source string) error {
+	if node.Tag != includeTag {
+		for _, child := range node.Content {
+			if err := inc.resolve(child, source); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if node.Kind != yaml.ScalarNode || node.Value == "" {
+		return &FileError{File: source, Line: node.Line, Column: node.Column, Err: fmt.Errorf("%s needs a file path", includeTag)}
+	}
+	path := node.Value
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(inc.stack[len(inc.stack)-1]), path)
+	}
+	included, err := inc.load(path)
+	if err != nil {
+		return &FileError{File: source, Line: node.Line, Column: node.Column, Err: err}
+	}
+
+	inc.stack = append(inc.stack, path)
+	defer func() { inc.stack = inc.stack[:len(inc.stack)-1] }()
+	if err := inc.resolve(included, path); err != nil {
+		return err
+	}
+	*node = *included
+	inc.files[node] = path
+	return nil
+}
+
+// load reads and parses an included file, decrypting it when it is SOPS encrypted
+func (inc *includer) load(path string) (*yaml.Node, error) {
+	for _, file := range inc.stack {
+		if file == path {
+			return nil, fmt.Errorf("%s %s: include cycle through %s", includeTag, path, strings.Join(inc.stack, " -> "))
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", includeTag, err)
+	}
+	if sops.IsEncrypted(data) {
+		if data, err = sops.Decrypt(data, inc.ageKeyFile); err != nil {
+			return nil, fmt.Errorf("%s: failed to decrypt %s: %w", includeTag, path, err)
+		}
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("%s: failed to parse %s: %w", includeTag, path, err)
+	}
+	if len(document.Content) == 0 {
+		return nil, fmt.Errorf("%s: %s is empty", includeTag, path)
+	}
+	return document.Content[0], nil
+}
@@ -0,0 +1,206 @@
+package scenario
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/expr"
+	"github.com/nulln0ne/fuego/pkg/variables"
+	"gopkg.in/yaml.v3"
+)
+
+// Inventory describes a scenario without running it
+type Inventory struct {
+	Name   string            `json:"name"`
+	File   string            `json:"file,omitempty"`
+	Tags   []string          `json:"tags,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	Groups []GroupInventory  `json:"groups"`
+	Steps  int               `json:"steps"`            // steps of all groups, without on_failure/on_success/cleanup branches
+	Matrix int               `json:"matrix,omitempty"` // combinations the scenario runs as
+	// Variables are referenced by templates but set nowhere in the scenario, so they must come
+	// from the config, an environment or the command line
+	Variables []string `json:"variables,omitempty"`
+}
+
+// GroupInventory is a phase of a scenario: before, setup, steps, a test group, teardown or after
+type GroupInventory struct {
+	Name  string `json:"name"`
+	Steps int    `json:"steps"`
+	Skip  bool   `json:"skip,omitempty"`
+}
+
+// engineVariables are set by the engine while steps run
+var engineVariables = []string{"last_status", "last_response", "correlation_id", "tmpdir"}
+
+var templateReference = regexp.MustCompile(`\{\{([^}]+)\}\}`)
+
+// Inventorize lists the groups, step counts, tags and required variables of a scenario
+func Inventorize(sc *Scenario) Inventory {
+	inventory := Inventory{Name: sc.Name, File: sc.SourceFile, Tags: sc.Metadata.Tags, Labels: sc.Metadata.Labels}
+	if len(sc.Matrix) > 0 {
+		inventory.Matrix = len(sc.Matrix.Expand())
+	}
+
+	addGroup := func(name string, steps []Step, skip bool) {
+		if len(steps) > 0 {
+			inventory.Groups = append(inventory.Groups, GroupInventory{Name: name, Steps: len(steps), Skip: skip})
+			inventory.Steps += len(steps)
+		}
+	}
+	if sc.Before != nil {
+		addGroup("before", sc.Before.Steps, sc.Before.Skip)
+	}
+	addGroup("setup", sc.Setup, false)
+	addGroup("steps", sc.Steps, false)
+	names := make([]string, 0, len(sc.Tests))
+	for name := range sc.Tests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if group := sc.Tests[name]; group != nil {
+			addGroup("test: "+name, group.Steps, group.Skip)
+		}
+	}
+	addGroup("teardown", sc.Teardown, false)
+	if sc.After != nil {
+		addGroup("after", sc.After.Steps, sc.After.Skip)
+	}
+
+	inventory.Variables = requiredVariables(sc)
+	return inventory
+}
+
+// requiredVariables returns the roots of the template references in a scenario that none of
+// its variables, data sources, matrix values, captures or data-driven bindings define
+func requiredVariables(sc *Scenario) []string {
+	defined := make(map[string]bool)
+	for _, name := range engineVariables {
+		defined[name] = true
+	}
+	define := func(names ...string) {
+		for _, name := range names {
+			defined[name] = true
+		}
+	}
+	for name := range sc.Env {
+		define(name)
+	}
+	for name := range sc.Variables {
+		define(name)
+	}
+	for name := range sc.Data {
+		define(name)
+	}
+	for name := range sc.Matrix {
+		define(name)
+	}
+	defineGroup := func(group *TestGroup) {
+		if group == nil {
+			return
+		}
+		for name := range group.Env {
+			define(name)
+		}
+		for name := range group.Matrix {
+			define(name)
+		}
+		if group.DataDriven != nil {
+			define(group.DataDriven.Variable)
+		}
+	}
+	defineGroup(sc.Before)
+	defineGroup(sc.After)
+	for _, group := range sc.Tests {
+		defineGroup(group)
+	}
+	for _, step := range walkSteps(sc) {
+		for name := range step.Variables {
+			define(name)
+		}
+		for name := range step.Capture {
+			define(name)
+		}
+		if step.DataDriven != nil {
+			define(step.DataDriven.Variable)
+		}
+		if step.Loop != nil && step.Loop.Variable != "" {
+			define(step.Loop.Variable)
+		}
+	}
+
+	data, err := yaml.Marshal(sc)
+	if err != nil {
+		return nil
+	}
+	required := make(map[string]bool)
+	for _, match := range templateReference.FindAllStringSubmatch(string(data), -1) {
+		name := strings.TrimPrefix(strings.TrimSpace(match[1]), "env.")
+		references := []string{name}
+		if compiled, err := expr.Compile(name); err == nil && strings.ContainsAny(name, " +-*/%<>=!&|?:()[]'\"") {
+			references = compiled.Variables() // computed values such as price * quantity
+		} else if strings.Contains(name, "(") {
+			continue // time functions such as now(+1d)
+		}
+		for _, name := range references {
+			if namespace, rest, found := strings.Cut(name, "."); found && variables.IsNamespace(namespace) {
+				name = rest // {{captures.token}} needs token
+			}
+			if end := strings.IndexAny(name, ".[| "); end >= 0 {
+				name = name[:end]
+			}
+			if name == "" || defined[name] || variables.IsBuiltin(name) {
+				continue
+			}
+			required[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(required))
+	for name := range required {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// walkSteps returns every step of a scenario, including on_failure, on_success and cleanup
+// branches
+func walkSteps(sc *Scenario) []*Step {
+	var steps []*Step
+	var add func(list []Step)
+	add = func(list []Step) {
+		for i := range list {
+			step := &list[i]
+			steps = append(steps, step)
+			add(step.OnFailure)
+			add(step.OnSuccess)
+			if step.Cleanup != nil {
+				add([]Step{*step.Cleanup})
+			}
+		}
+	}
+	addGroup := func(group *TestGroup) {
+		if group != nil {
+			add(group.Steps)
+			add(group.OnFailure)
+			add(group.OnSuccess)
+		}
+	}
+	addGroup(sc.Before)
+	add(sc.Setup)
+	add(sc.Steps)
+	names := make([]string, 0, len(sc.Tests))
+	for name := range sc.Tests {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		addGroup(sc.Tests[name])
+	}
+	add(sc.Teardown)
+	addGroup(sc.After)
+	return steps
+}
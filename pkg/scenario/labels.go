@@ -0,0 +1,73 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LabelSelector matches scenarios by a metadata label: key=value[,value...] requires one of the
+// values, a bare key requires the label to be set
+type LabelSelector struct {
+	Key    string
+	Values []string
+}
+
+// ParseLabelSelector parses a --label value such as component=payments or severity=critical,high
+func ParseLabelSelector(spec string) (LabelSelector, error) {
+	key, values, hasValues := strings.Cut(spec, "=")
+	selector := LabelSelector{Key: strings.TrimSpace(key)}
+	if selector.Key == "" {
+		return selector, fmt.Errorf("invalid label selector %q, expected key=value", spec)
+	}
+	if hasValues {
+		for _, value := range strings.Split(values, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				selector.Values = append(selector.Values, value)
+			}
+		}
+		if len(selector.Values) == 0 {
+			return selector, fmt.Errorf("invalid label selector %q, expected key=value", spec)
+		}
+	}
+	return selector, nil
+}
+
+// Matches reports whether the scenario's labels satisfy the selector; values compare
+// case-insensitively
+func (s LabelSelector) Matches(sc *Scenario) bool {
+	value, exists := sc.Metadata.Labels[s.Key]
+	if !exists {
+		return false
+	}
+	if len(s.Values) == 0 {
+		return true
+	}
+	for _, wanted := range s.Values {
+		if strings.EqualFold(value, wanted) {
+			return true
+		}
+	}
+	return false
+}
+
+// FilterByLabels keeps the scenarios matching every selector
+func FilterByLabels(scenarios []*Scenario, selectors []LabelSelector) []*Scenario {
+	if len(selectors) == 0 {
+		return scenarios
+	}
+
+	var filtered []*Scenario
+	for _, sc := range scenarios {
+		matches := true
+		for _, selector := range selectors {
+			if !selector.Matches(sc) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			filtered = append(filtered, sc)
+		}
+	}
+	return filtered
+}
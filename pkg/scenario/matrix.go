@@ -0,0 +1,85 @@
+package scenario
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Matrix maps variable names to the values to run with; every combination is executed
+type Matrix map[string][]interface{}
+
+// MatrixCombination is one set of matrix values
+type MatrixCombination struct {
+	Values map[string]interface{}
+	// Label identifies the combination in reports, e.g. "[api_version=v1, region=eu]"
+	Label string
+}
+
+// Expand returns every combination of the matrix values, varying the last key (by name) fastest.
+// An empty matrix has no combinations.
+func (m Matrix) Expand() []MatrixCombination {
+	if len(m) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	combinations := []map[string]interface{}{{}}
+	for _, key := range keys {
+		var next []map[string]interface{}
+		for _, combination := range combinations {
+			for _, value := range m[key] {
+				expanded := make(map[string]interface{}, len(combination)+1)
+				for k, v := range combination {
+					expanded[k] = v
+				}
+				expanded[key] = value
+				next = append(next, expanded)
+			}
+		}
+		combinations = next
+	}
+
+	result := make([]MatrixCombination, 0, len(combinations))
+	for _, values := range combinations {
+		parts := make([]string, 0, len(keys))
+		for _, key := range keys {
+			parts = append(parts, fmt.Sprintf("%s=%v", key, values[key]))
+		}
+		result = append(result, MatrixCombination{Values: values, Label: "[" + strings.Join(parts, ", ") + "]"})
+	}
+
+	return result
+}
+
+// ExpandMatrix returns one copy of the scenario per matrix combination, named after the
+// combination and with its values added to the scenario variables. A scenario without a matrix
+// is returned as is.
+func ExpandMatrix(sc *Scenario) []*Scenario {
+	combinations := sc.Matrix.Expand()
+	if len(combinations) == 0 {
+		return []*Scenario{sc}
+	}
+
+	expanded := make([]*Scenario, 0, len(combinations))
+	for _, combination := range combinations {
+		copied := *sc
+		copied.Name = sc.Name + " " + combination.Label
+		copied.Matrix = nil
+		copied.Variables = make(map[string]any, len(sc.Variables)+len(combination.Values))
+		for k, v := range sc.Variables {
+			copied.Variables[k] = v
+		}
+		for k, v := range combination.Values {
+			copied.Variables[k] = v
+		}
+		expanded = append(expanded, &copied)
+	}
+
+	return expanded
+}
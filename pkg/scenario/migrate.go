@@ -0,0 +1,291 @@
+package scenario
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Migrate rewrites a scenario file to the current format: request steps become http steps whose
+// extracted variables are captures, setup steps move to before, teardown steps to after and
+// top-level steps to a "main" test group. Comments and formatting are kept. The notes list what
+// could not be migrated or behaves differently afterwards.
+func Migrate(content []byte) ([]byte, []string, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse scenario: %w", err)
+	}
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("scenario is not a mapping")
+	}
+	root := document.Content[0]
+	if mappingValue(root, "sops") != nil {
+		return nil, nil, fmt.Errorf("scenario is encrypted, decrypt it with sops first")
+	}
+
+	m := &migration{usesLastResponse: bytes.Contains(content, []byte("last_status")) || bytes.Contains(content, []byte("last_response"))}
+	m.steps(mappingValue(root, "setup"))
+	m.steps(mappingValue(root, "steps"))
+	m.steps(mappingValue(root, "teardown"))
+	for _, group := range []*yaml.Node{mappingValue(root, "before"), mappingValue(root, "after")} {
+		m.group(group)
+	}
+	if tests := mappingValue(root, "tests"); tests != nil && tests.Kind == yaml.MappingNode {
+		for i := 1; i < len(tests.Content); i += 2 {
+			m.group(tests.Content[i])
+		}
+	}
+
+	config := mappingValue(root, "config")
+	failFast := isTrue(mappingValue(config, "fail_fast"))
+
+	// setup runs after before, teardown before after
+	if setup := mappingValue(root, "setup"); setup != nil {
+		if !failFast {
+			m.note("setup steps moved to before, which stops the scenario when one of its steps fails")
+		}
+		moveSteps(root, "setup", "before", false)
+	}
+	if mappingValue(root, "teardown") != nil {
+		moveSteps(root, "teardown", "after", true)
+	}
+
+	if steps := mappingValue(root, "steps"); steps != nil {
+		if mappingValue(root, "tests") != nil {
+			m.note("top-level steps are kept: they run before the test groups, which a test group cannot")
+		} else {
+			group := mappingNode()
+			if !failFast {
+				// Top-level steps carry on after a failure unless fail_fast is set
+				setMappingValue(group, "continueOnFail", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+			}
+			setMappingValue(group, "steps", steps)
+			tests := mappingNode()
+			setMappingValue(tests, "main", group)
+			replaceMappingKey(root, "steps", "tests", tests)
+			if isTrue(mappingValue(config, "parallel")) {
+				m.note("config.parallel runs the main test group on a copy of the variables, so after steps no longer see its captures")
+			}
+		}
+	}
+
+	if version := mappingValue(root, "version"); version != nil {
+		version.Value, version.Tag, version.Style = CurrentVersion, "!!str", yaml.DoubleQuotedStyle
+	} else {
+		root.Content = append([]*yaml.Node{
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: "version"},
+			{Kind: yaml.ScalarNode, Tag: "!!str", Value: CurrentVersion, Style: yaml.DoubleQuotedStyle},
+		}, root.Content...)
+	}
+
+	var out bytes.Buffer
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return nil, nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, nil, err
+	}
+
+	if includes(root) {
+		// Included files are migrated on their own and only make sense in place
+		m.note("the scenario uses %s, so the migrated file is not checked; migrate the included files too", includeTag)
+		return out.Bytes(), m.notes, nil
+	}
+
+	var migrated Scenario
+	if err := yaml.Unmarshal(out.Bytes(), &migrated); err != nil {
+		return nil, nil, fmt.Errorf("migrated scenario does not parse: %w", err)
+	}
+	if err := validateScenario(&migrated); err != nil {
+		return nil, nil, fmt.Errorf("migrated scenario is invalid: %w", err)
+	}
+	return out.Bytes(), m.notes, nil
+}
+
+type migration struct {
+	notes            []string
+	usesLastResponse bool
+}
+
+func (m *migration) note(format string, args ...interface{}) {
+	m.notes = append(m.notes, fmt.Sprintf(format, args...))
+}
+
+func (m *migration) group(group *yaml.Node) {
+	for _, key := range []string{"steps", "on_failure", "on_success"} {
+		m.steps(mappingValue(group, key))
+	}
+}
+
+func (m *migration) steps(steps *yaml.Node) {
+	if steps == nil || steps.Kind != yaml.SequenceNode {
+		return
+	}
+	for _, step := range steps.Content {
+		m.step(step)
+	}
+}
+
+// legacyCaptures maps the extractor prefixes of request step variables to capture fields
+var legacyCaptures = map[string]string{"json:": "jsonpath", "header:": "header", "cookie:": "cookie"}
+
+func (m *migration) step(step *yaml.Node) {
+	if step.Kind != yaml.MappingNode {
+		return
+	}
+	m.steps(mappingValue(step, "on_failure"))
+	m.steps(mappingValue(step, "on_success"))
+	if cleanup := mappingValue(step, "cleanup"); cleanup != nil {
+		m.step(cleanup)
+	}
+
+	stepType, request := mappingValue(step, "type"), mappingValue(step, "request")
+	if stepType == nil || stepType.Value != "http" || request == nil || mappingValue(step, "http") != nil {
+		return
+	}
+	name := ""
+	if nameNode := mappingValue(step, "name"); nameNode != nil {
+		name = nameNode.Value
+	}
+
+	for _, key := range []string{"cookies", "files", "follow_redirect", "config"} {
+		if mappingValue(request, key) != nil {
+			m.note("step '%s' is kept as a request step: http steps have no %s", name, key)
+			return
+		}
+	}
+
+	// Request steps extract string variables that name an extractor from the response
+	captures := mappingNode()
+	var extracted []string
+	variables := mappingValue(step, "variables")
+	if variables != nil && variables.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(variables.Content); i += 2 {
+			value := variables.Content[i+1]
+			if value.Kind != yaml.ScalarNode || value.Tag != "!!str" {
+				continue
+			}
+			capture := mappingNode()
+			switch {
+			case value.Value == "links":
+				setMappingValue(capture, "links", &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "true"})
+			case strings.HasPrefix(value.Value, "status"), strings.HasPrefix(value.Value, "body"), value.Value == "charset":
+				m.note("step '%s' is kept as a request step: captures cannot extract %s", name, value.Value)
+				return
+			default:
+				for prefix, field := range legacyCaptures {
+					if strings.HasPrefix(value.Value, prefix) {
+						setMappingValue(capture, field, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: strings.TrimPrefix(value.Value, prefix)})
+					}
+				}
+			}
+			if len(capture.Content) > 0 {
+				setMappingValue(captures, variables.Content[i].Value, capture)
+				extracted = append(extracted, variables.Content[i].Value)
+			}
+		}
+	} else if m.usesLastResponse {
+		m.note("step '%s': http steps do not set last_status and last_response, capture what later steps use", name)
+	}
+
+	for _, variable := range extracted {
+		deleteMappingKey(variables, variable)
+	}
+	if variables != nil && len(variables.Content) == 0 {
+		deleteMappingKey(step, "variables")
+	}
+	if len(captures.Content) > 0 {
+		existing := mappingValue(step, "capture")
+		if existing == nil {
+			existing = mappingNode()
+			setMappingValue(step, "capture", existing)
+		}
+		existing.Content = append(existing.Content, captures.Content...)
+	}
+	replaceMappingKey(step, "request", "http", request)
+	deleteMappingKey(step, "type")
+}
+
+// moveSteps moves the steps under a deprecated key to the steps of a group, creating the group in
+// place of the key when it does not exist
+func moveSteps(root *yaml.Node, from, to string, prepend bool) {
+	steps := mappingValue(root, from)
+	group := mappingValue(root, to)
+	if group == nil {
+		group = mappingNode()
+		setMappingValue(group, "steps", steps)
+		replaceMappingKey(root, from, to, group)
+		return
+	}
+
+	existing := mappingValue(group, "steps")
+	if existing == nil {
+		setMappingValue(group, "steps", steps)
+	} else if prepend {
+		existing.Content = append(append([]*yaml.Node{}, steps.Content...), existing.Content...)
+	} else {
+		existing.Content = append(existing.Content, steps.Content...)
+	}
+	deleteMappingKey(root, from)
+}
+
+func mappingNode() *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value)
+}
+
+// replaceMappingKey renames a key and replaces its value, keeping its position and comments
+func replaceMappingKey(node *yaml.Node, key, newKey string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content[i].Value = newKey
+			node.Content[i+1] = value
+			return
+		}
+	}
+}
+
+func deleteMappingKey(node *yaml.Node, key string) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+func isTrue(node *yaml.Node) bool {
+	return node != nil && node.Kind == yaml.ScalarNode && node.Tag == "!!bool" && strings.EqualFold(node.Value, "true")
+}
+
+// includes reports whether node or one of its children is an !include tag
+func includes(node *yaml.Node) bool {
+	if node.Tag == includeTag {
+		return true
+	}
+	for _, child := range node.Content {
+		if includes(child) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package scenario
+
+import (
+	"fmt"
+)
+
+// MaxParallelRequests bounds the copies a parallel step sends at once
+const MaxParallelRequests = 1000
+
+// ParallelRequests sends copies of a step's request at the same moment, to find race conditions
+// such as double bookings or duplicate records. Each copy is checked on its own; assertions of
+// type parallel check the copies together.
+type ParallelRequests struct {
+	Count  int                      `yaml:"count,omitempty" json:"count,omitempty"`   // copies to send, the number of data bindings by default
+	Data   []map[string]interface{} `yaml:"data,omitempty" json:"data,omitempty"`     // variables of each copy, reused in turn when there are fewer than copies
+	Unique []string                 `yaml:"unique,omitempty" json:"unique,omitempty"` // JSON paths whose values must differ across the responses, e.g. $.id
+}
+
+// Copies is the number of requests to send
+func (p *ParallelRequests) Copies() int {
+	if p.Count > 0 {
+		return p.Count
+	}
+	return len(p.Data)
+}
+
+func (p *ParallelRequests) validate() error {
+	if p == nil {
+		return nil
+	}
+	if p.Count < 0 {
+		return fmt.Errorf("parallel: count must not be negative")
+	}
+	if p.Copies() < 2 {
+		return fmt.Errorf("parallel: at least 2 copies are needed, set count or data")
+	}
+	if p.Copies() > MaxParallelRequests {
+		return fmt.Errorf("parallel: at most %d copies can be sent", MaxParallelRequests)
+	}
+	for _, path := range p.Unique {
+		if path == "" {
+			return fmt.Errorf("parallel: unique paths must not be empty")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package scenario
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Unset marks a header or query parameter that a step removes from the inherited defaults
+const Unset = "\x00unset"
+
+// Params holds request headers or query parameters. A null value (`X-Api-Version: null`) removes
+// a default inherited from the config or scenario.
+type Params map[string]string
+
+// UnmarshalYAML decodes null values as Unset. Merge keys (`<<: *defaults`) add the merged
+// parameters that the mapping does not set itself.
+func (p *Params) UnmarshalYAML(node *yaml.Node) error {
+	if node.Tag == "!!null" {
+		return nil
+	}
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected a mapping of names to values", node.Line)
+	}
+
+	params := make(Params, len(node.Content)/2)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Tag != "!!merge" {
+			continue
+		}
+		merged := []*yaml.Node{node.Content[i+1]}
+		if merged[0].Kind == yaml.SequenceNode {
+			merged = merged[0].Content
+		}
+		for _, m := range merged {
+			var defaults Params
+			if err := defaults.UnmarshalYAML(m); err != nil {
+				return err
+			}
+			for name, value := range defaults {
+				if _, ok := params[name]; !ok {
+					params[name] = value
+				}
+			}
+		}
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		name, value := node.Content[i].Value, node.Content[i+1]
+		if node.Content[i].Tag == "!!merge" {
+			continue
+		}
+		if value.Tag == "!!null" {
+			params[name] = Unset
+			continue
+		}
+
+		var s string
+		if err := value.Decode(&s); err != nil {
+			return fmt.Errorf("line %d: %s: %w", value.Line, name, err)
+		}
+		params[name] = s
+	}
+
+	*p = params
+	return nil
+}
+
+// MarshalJSON renders removed parameters as null
+func (p Params) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte("null"), nil
+	}
+	return json.Marshal(p.values())
+}
+
+// MarshalYAML renders removed parameters as null
+func (p Params) MarshalYAML() (interface{}, error) {
+	if p == nil {
+		return nil, nil
+	}
+	return p.values(), nil
+}
+
+func (p Params) values() map[string]interface{} {
+	values := make(map[string]interface{}, len(p))
+	for name, value := range p {
+		if value == Unset {
+			values[name] = nil
+		} else {
+			values[name] = value
+		}
+	}
+	return values
+}
+
+// MergeHeaders overlays step headers on defaults; names match case-insensitively and Unset values
+// are kept so the HTTP client also drops its own global defaults
+func MergeHeaders(defaults, overrides map[string]string) map[string]string {
+	return mergeParams(defaults, overrides, strings.EqualFold)
+}
+
+// MergeQuery overlays step query parameters on defaults, keeping Unset values
+func MergeQuery(defaults, overrides map[string]string) map[string]string {
+	return mergeParams(defaults, overrides, func(a, b string) bool { return a == b })
+}
+
+func mergeParams(defaults, overrides map[string]string, sameName func(a, b string) bool) map[string]string {
+	if len(defaults) == 0 {
+		return overrides
+	}
+
+	merged := make(map[string]string, len(defaults)+len(overrides))
+	for name, value := range defaults {
+		merged[name] = value
+	}
+	for name, value := range overrides {
+		for existing := range merged {
+			if sameName(existing, name) {
+				delete(merged, existing)
+			}
+		}
+		merged[name] = value
+	}
+	return merged
+}
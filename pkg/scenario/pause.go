@@ -0,0 +1,44 @@
+package scenario
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// Pause is a wait between steps or iterations: a fixed duration ("500ms") or a random duration
+// within a range ("1s-3s")
+type Pause string
+
+// Range returns the shortest and longest wait; both are zero for an empty pause
+func (p Pause) Range() (min, max time.Duration, err error) {
+	value := strings.TrimSpace(string(p))
+	if value == "" {
+		return 0, 0, nil
+	}
+
+	low, high, isRange := strings.Cut(value, "-")
+	if min, err = time.ParseDuration(strings.TrimSpace(low)); err != nil {
+		return 0, 0, fmt.Errorf("invalid pause %q, expected a duration (500ms) or a range (1s-3s)", value)
+	}
+	max = min
+	if isRange {
+		if max, err = time.ParseDuration(strings.TrimSpace(high)); err != nil {
+			return 0, 0, fmt.Errorf("invalid pause %q, expected a duration (500ms) or a range (1s-3s)", value)
+		}
+	}
+	if min < 0 || max < min {
+		return 0, 0, fmt.Errorf("invalid pause %q, the range must not be negative or reversed", value)
+	}
+	return min, max, nil
+}
+
+// Duration picks the wait of one pause using r for ranges; invalid pauses do not wait
+func (p Pause) Duration(r *rand.Rand) time.Duration {
+	min, max, err := p.Range()
+	if err != nil || max == min {
+		return min
+	}
+	return min + time.Duration(r.Int63n(int64(max-min)+1))
+}
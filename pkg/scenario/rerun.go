@@ -0,0 +1,86 @@
+package scenario
+
+// FilterFailed keeps the scenarios that failed in a previous run, narrowed to the steps that
+// failed. failures maps scenario names (matrix combinations included) to the names of their
+// failed steps. Setup, before, teardown and after steps always run, and so do the steps a failed
+// step depends on (depends_on, followed transitively), so captures it needs are still made. A
+// scenario whose failed steps are unknown or no longer exist runs in full.
+func FilterFailed(scenarios []*Scenario, failures map[string][]string) []*Scenario {
+	var filtered []*Scenario
+	for _, sc := range scenarios {
+		var failedSteps []string
+		failed := false
+		for _, expanded := range ExpandMatrix(sc) {
+			if steps, ok := failures[expanded.Name]; ok {
+				failed = true
+				failedSteps = append(failedSteps, steps...)
+			}
+		}
+		if failed {
+			filtered = append(filtered, narrowToSteps(sc, failedSteps))
+		}
+	}
+	return filtered
+}
+
+// narrowToSteps returns a copy of the scenario whose steps and test groups hold only the named
+// steps and their dependencies
+func narrowToSteps(sc *Scenario, names []string) *Scenario {
+	byName := make(map[string]*Step)
+	for i := range sc.Steps {
+		byName[sc.Steps[i].Name] = &sc.Steps[i]
+	}
+	for _, group := range sc.Tests {
+		for i := range group.Steps {
+			byName[group.Steps[i].Name] = &group.Steps[i]
+		}
+	}
+
+	keep := make(map[string]bool)
+	pending := make([]string, 0, len(names))
+	for _, name := range names {
+		if byName[name] != nil {
+			pending = append(pending, name)
+		}
+	}
+	if len(pending) == 0 {
+		return sc
+	}
+	for len(pending) > 0 {
+		name := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if keep[name] {
+			continue
+		}
+		keep[name] = true
+		if step := byName[name]; step != nil {
+			pending = append(pending, step.DependsOn...)
+		}
+	}
+
+	narrowed := *sc
+	narrowed.Steps = keepSteps(sc.Steps, keep)
+	if sc.Tests != nil {
+		narrowed.Tests = make(map[string]*TestGroup)
+		for name, group := range sc.Tests {
+			steps := keepSteps(group.Steps, keep)
+			if len(steps) == 0 {
+				continue
+			}
+			narrowedGroup := *group
+			narrowedGroup.Steps = steps
+			narrowed.Tests[name] = &narrowedGroup
+		}
+	}
+	return &narrowed
+}
+
+func keepSteps(steps []Step, keep map[string]bool) []Step {
+	var kept []Step
+	for _, step := range steps {
+		if keep[step.Name] {
+			kept = append(kept, step)
+		}
+	}
+	return kept
+}
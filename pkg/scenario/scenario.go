@@ -1,11 +1,16 @@
 package scenario
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/nulln0ne/fuego/pkg/sops"
 	"gopkg.in/yaml.v3"
 )
 
@@ -16,6 +21,7 @@ type Scenario struct {
 	Env         map[string]any        `yaml:"env,omitempty" json:"env,omitempty"`
 	Variables   map[string]any        `yaml:"variables,omitempty" json:"variables,omitempty"`
 	Data        map[string]DataSource `yaml:"data,omitempty" json:"data,omitempty"`
+	Matrix      Matrix                `yaml:"matrix,omitempty" json:"matrix,omitempty"`
 	Config      *ScenarioConfig       `yaml:"config,omitempty" json:"config,omitempty"`
 	Before      *TestGroup            `yaml:"before,omitempty" json:"before,omitempty"`
 	Setup       []Step                `yaml:"setup,omitempty" json:"setup,omitempty"`
@@ -23,7 +29,9 @@ type Scenario struct {
 	Tests       map[string]*TestGroup `yaml:"tests,omitempty" json:"tests,omitempty"`
 	Teardown    []Step                `yaml:"teardown,omitempty" json:"teardown,omitempty"`
 	After       *TestGroup            `yaml:"after,omitempty" json:"after,omitempty"`
+	Verify      []Assertion           `yaml:"verify,omitempty" json:"verify,omitempty"` // checked after all steps, against variables and step results
 	Metadata    ScenarioMetadata      `yaml:"metadata,omitempty" json:"metadata,omitempty"`
+	SourceFile  string                `yaml:"-" json:"source_file,omitempty"` // file the scenario was loaded from
 }
 
 type TestGroup struct {
@@ -32,7 +40,11 @@ type TestGroup struct {
 	Skip           bool              `yaml:"skip,omitempty" json:"skip,omitempty"`
 	ContinueOnFail bool              `yaml:"continueOnFail,omitempty" json:"continueOnFail,omitempty"`
 	DataDriven     *DataDrivenConfig `yaml:"data_driven,omitempty" json:"data_driven,omitempty"`
+	Matrix         Matrix            `yaml:"matrix,omitempty" json:"matrix,omitempty"`
+	KnownFailure   string            `yaml:"known_failure,omitempty" json:"known_failure,omitempty"` // ticket for a known bug; failures are expected
 	Steps          []Step            `yaml:"steps" json:"steps"`
+	OnFailure      []Step            `yaml:"on_failure,omitempty" json:"on_failure,omitempty"` // run after the steps when one failed
+	OnSuccess      []Step            `yaml:"on_success,omitempty" json:"on_success,omitempty"` // run after the steps when all passed
 }
 
 type ScenarioConfig struct {
@@ -43,12 +55,22 @@ type ScenarioConfig struct {
 	Retries     int           `yaml:"retries,omitempty" json:"retries,omitempty"`
 	FailFast    bool          `yaml:"fail_fast,omitempty" json:"fail_fast,omitempty"`
 	Environment string        `yaml:"environment,omitempty" json:"environment,omitempty"`
+	// ThinkTime is waited between consecutive steps, e.g. "2s" or "1s-3s"
+	ThinkTime Pause `yaml:"think_time,omitempty" json:"think_time,omitempty"`
+	// Pacing is the least time between the starts of consecutive iterations (data items and
+	// repeated runs), so a run stays below an API's rate limit
+	Pacing Pause `yaml:"pacing,omitempty" json:"pacing,omitempty"`
 }
 
 type HTTPConfig struct {
 	Timeout         time.Duration `yaml:"timeout,omitempty" json:"timeout,omitempty"`
 	FollowRedirects bool          `yaml:"followRedirects,omitempty" json:"followRedirects,omitempty"`
 	VerifySSL       bool          `yaml:"verifySSL,omitempty" json:"verifySSL,omitempty"`
+	// Headers and Query are sent with every HTTP step of the scenario unless a step overrides them
+	Headers Params `yaml:"headers,omitempty" json:"headers,omitempty"`
+	Query   Params `yaml:"query,omitempty" json:"query,omitempty"`
+	// Faults are injected into the HTTP steps of the scenario that declare none of their own
+	Faults []Fault `yaml:"faults,omitempty" json:"faults,omitempty"`
 }
 
 type ScenarioMetadata struct {
@@ -61,46 +83,113 @@ type ScenarioMetadata struct {
 }
 
 type Step struct {
-	Name        string                 `yaml:"name" json:"name"`
-	Description string                 `yaml:"description,omitempty" json:"description,omitempty"`
-	Type        string                 `yaml:"type,omitempty" json:"type,omitempty"` // http, grpc, websocket, etc.
-	HTTP        *HTTPStep              `yaml:"http,omitempty" json:"http,omitempty"`
-	Request     Request                `yaml:"request,omitempty" json:"request,omitempty"`
-	Capture     map[string]Capture     `yaml:"capture,omitempty" json:"capture,omitempty"`
-	Check       map[string]interface{} `yaml:"check,omitempty" json:"check,omitempty"`
-	Assertions  []Assertion            `yaml:"assertions,omitempty" json:"assertions,omitempty"`
-	Variables   map[string]any         `yaml:"variables,omitempty" json:"variables,omitempty"`
-	Condition   string                 `yaml:"condition,omitempty" json:"condition,omitempty"`
-	Loop        *LoopConfig            `yaml:"loop,omitempty" json:"loop,omitempty"`
-	DataDriven  *DataDrivenConfig      `yaml:"data_driven,omitempty" json:"data_driven,omitempty"`
-	Retry       *RetryConfig           `yaml:"retry,omitempty" json:"retry,omitempty"`
-	Timeout     time.Duration          `yaml:"timeout,omitempty" json:"timeout,omitempty"`
-	DependsOn   []string               `yaml:"depends_on,omitempty" json:"depends_on,omitempty"`
-	Config      map[string]interface{} `yaml:"config,omitempty" json:"config,omitempty"`
+	Name         string                   `yaml:"name" json:"name"`
+	Description  string                   `yaml:"description,omitempty" json:"description,omitempty"`
+	DocsURL      string                   `yaml:"docs_url,omitempty" json:"docs_url,omitempty"` // API contract or runbook, linked from failures
+	Type         string                   `yaml:"type,omitempty" json:"type,omitempty"`         // http, grpc, websocket, etc.
+	HTTP         *HTTPStep                `yaml:"http,omitempty" json:"http,omitempty"`
+	Request      Request                  `yaml:"request,omitempty" json:"request,omitempty"`
+	Capture      map[string]Capture       `yaml:"capture,omitempty" json:"capture,omitempty"`
+	Check        map[string]interface{}   `yaml:"check,omitempty" json:"check,omitempty"`
+	Assertions   []Assertion              `yaml:"assertions,omitempty" json:"assertions,omitempty"`
+	Variables    map[string]any           `yaml:"variables,omitempty" json:"variables,omitempty"`
+	Condition    string                   `yaml:"condition,omitempty" json:"condition,omitempty"`
+	Loop         *LoopConfig              `yaml:"loop,omitempty" json:"loop,omitempty"`
+	DataDriven   *DataDrivenConfig        `yaml:"data_driven,omitempty" json:"data_driven,omitempty"`
+	Retry        *RetryConfig             `yaml:"retry,omitempty" json:"retry,omitempty"`
+	Parallel     *ParallelRequests        `yaml:"parallel,omitempty" json:"parallel,omitempty"`     // send copies of the request at once
+	Faults       []Fault                  `yaml:"faults,omitempty" json:"faults,omitempty"`         // client-side fault injection, overrides the scenario's
+	ThinkTime    Pause                    `yaml:"think_time,omitempty" json:"think_time,omitempty"` // wait before this step, overrides the scenario's
+	Cleanup      *Step                    `yaml:"cleanup,omitempty" json:"cleanup,omitempty"`       // undoes the step, run at the end of the scenario
+	OnFailure    []Step                   `yaml:"on_failure,omitempty" json:"on_failure,omitempty"` // run right after the step when it failed
+	OnSuccess    []Step                   `yaml:"on_success,omitempty" json:"on_success,omitempty"` // run right after the step when it passed
+	Timeout      time.Duration            `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+	SLO          map[string]time.Duration `yaml:"slo,omitempty" json:"slo,omitempty"`                     // min, avg, p50, p90, p95, p99, max over all iterations
+	DependsOn    []string                 `yaml:"depends_on,omitempty" json:"depends_on,omitempty"`       // steps whose captures this step uses
+	KnownFailure string                   `yaml:"known_failure,omitempty" json:"known_failure,omitempty"` // ticket for a known bug; failures are expected
+	Config       map[string]interface{}   `yaml:"config,omitempty" json:"config,omitempty"`
+	SaveResponse string                   `yaml:"save_response,omitempty" json:"save_response,omitempty"` // file to write the response body to
+	StoreAs      string                   `yaml:"store_as,omitempty" json:"store_as,omitempty"`           // name later compare assertions refer to the response by
+	Output       *StepOutput              `yaml:"output,omitempty" json:"output,omitempty"`               // appends a row per passing run to a file
+	Line         int                      `yaml:"-" json:"line,omitempty"`                                // source line the step starts on
+	Column       int                      `yaml:"-" json:"column,omitempty"`                              // source column the step starts at
+}
+
+// StepOutput appends selected values of every passing run of a step to a CSV file, or a JSON Lines
+// file for other extensions, that can be loaded again as a data source
+type StepOutput struct {
+	Path   string            `yaml:"path" json:"path"`
+	Fields map[string]string `yaml:"fields" json:"fields"` // column name: template, e.g. id: "{{user_id}}"
+}
+
+// UnmarshalYAML records the position the step starts at so failures can point back at the scenario file
+func (s *Step) UnmarshalYAML(node *yaml.Node) error {
+	type plain Step
+	if err := node.Decode((*plain)(s)); err != nil {
+		return err
+	}
+	s.Line, s.Column = node.Line, node.Column
+	return nil
 }
 
 type HTTPStep struct {
-	URL     string                 `yaml:"url" json:"url"`
-	Method  string                 `yaml:"method,omitempty" json:"method,omitempty"`
-	Headers map[string]string      `yaml:"headers,omitempty" json:"headers,omitempty"`
-	Query   map[string]string      `yaml:"query,omitempty" json:"query,omitempty"`
-	Body    interface{}            `yaml:"body,omitempty" json:"body,omitempty"`
-	JSON    interface{}            `yaml:"json,omitempty" json:"json,omitempty"`
-	Auth    *AuthConfig            `yaml:"auth,omitempty" json:"auth,omitempty"`
-	Check   map[string]interface{} `yaml:"check,omitempty" json:"check,omitempty"`
+	URL        string                 `yaml:"url" json:"url"`
+	Service    string                 `yaml:"service,omitempty" json:"service,omitempty"` // config service the URL is relative to
+	Method     string                 `yaml:"method,omitempty" json:"method,omitempty"`
+	Headers    Params                 `yaml:"headers,omitempty" json:"headers,omitempty"`
+	Query      Params                 `yaml:"query,omitempty" json:"query,omitempty"`
+	PathParams map[string]string      `yaml:"path_params,omitempty" json:"path_params,omitempty"` // values for :name or {name} in the URL path, escaped
+	Middleware []string               `yaml:"middleware,omitempty" json:"middleware,omitempty"`   // registered request middleware to run before sending
+	Protobuf   *ProtobufResponse      `yaml:"protobuf,omitempty" json:"protobuf,omitempty"`       // decode the binary response body
+	Revalidate bool                   `yaml:"revalidate,omitempty" json:"revalidate,omitempty"`   // repeat as conditional requests that must get 304
+	CORS       *CORSPreflight         `yaml:"cors,omitempty" json:"cors,omitempty"`               // send the CORS preflight of the request instead
+	Stability  *Stability             `yaml:"stability,omitempty" json:"stability,omitempty"`     // repeat and compare a value of the responses
+	Stream     *Stream                `yaml:"stream,omitempty" json:"stream,omitempty"`           // read the body as it arrives and record its chunks
+	Body       interface{}            `yaml:"body,omitempty" json:"body,omitempty"`
+	JSON       interface{}            `yaml:"json,omitempty" json:"json,omitempty"`
+	Auth       *AuthConfig            `yaml:"auth,omitempty" json:"auth,omitempty"`
+	Check      map[string]interface{} `yaml:"check,omitempty" json:"check,omitempty"`
+}
+
+// ProtobufResponse decodes a binary response body (gRPC-framed when the content type is
+// application/grpc...) as a message of a .proto file. The step fails when it does not decode
+// cleanly; otherwise checks, assertions and captures see the decoded message as JSON.
+type ProtobufResponse struct {
+	File    string `yaml:"file" json:"file"`       // relative to the working directory
+	Message string `yaml:"message" json:"message"` // full name (shop.v1.Order) or unambiguous short name
+}
+
+// CORSPreflight sends the OPTIONS preflight a browser on Origin would send before the step's
+// request, and fails the step unless the Access-Control-* headers of the response allow it
+type CORSPreflight struct {
+	Origin      string   `yaml:"origin" json:"origin"`
+	Method      string   `yaml:"method,omitempty" json:"method,omitempty"`           // defaults to the step method
+	Headers     []string `yaml:"headers,omitempty" json:"headers,omitempty"`         // defaults to the step headers browsers ask about
+	Credentials bool     `yaml:"credentials,omitempty" json:"credentials,omitempty"` // the request sends cookies or auth
+	MaxAge      int      `yaml:"max_age,omitempty" json:"max_age,omitempty"`         // minimum Access-Control-Max-Age in seconds
 }
 
 type Capture struct {
 	JSONPath string `yaml:"jsonpath,omitempty" json:"jsonpath,omitempty"`
 	Header   string `yaml:"header,omitempty" json:"header,omitempty"`
 	Regex    string `yaml:"regex,omitempty" json:"regex,omitempty"`
+	Cookie   string `yaml:"cookie,omitempty" json:"cookie,omitempty"` // name of a Set-Cookie to capture with its attributes
+	Links    bool   `yaml:"links,omitempty" json:"links,omitempty"`   // capture the Link headers as a rel to URL map
 }
 
 type Request struct {
 	Method         string                 `yaml:"method,omitempty" json:"method,omitempty"`
 	URL            string                 `yaml:"url,omitempty" json:"url,omitempty"`
-	Headers        map[string]string      `yaml:"headers,omitempty" json:"headers,omitempty"`
-	Query          map[string]string      `yaml:"query,omitempty" json:"query,omitempty"`
+	Service        string                 `yaml:"service,omitempty" json:"service,omitempty"` // config service the URL is relative to
+	Headers        Params                 `yaml:"headers,omitempty" json:"headers,omitempty"`
+	Query          Params                 `yaml:"query,omitempty" json:"query,omitempty"`
+	PathParams     map[string]string      `yaml:"path_params,omitempty" json:"path_params,omitempty"` // values for :name or {name} in the URL path, escaped
+	Middleware     []string               `yaml:"middleware,omitempty" json:"middleware,omitempty"`   // registered request middleware to run before sending
+	Protobuf       *ProtobufResponse      `yaml:"protobuf,omitempty" json:"protobuf,omitempty"`       // decode the binary response body
+	Revalidate     bool                   `yaml:"revalidate,omitempty" json:"revalidate,omitempty"`   // repeat as conditional requests that must get 304
+	CORS           *CORSPreflight         `yaml:"cors,omitempty" json:"cors,omitempty"`               // send the CORS preflight of the request instead
+	Stability      *Stability             `yaml:"stability,omitempty" json:"stability,omitempty"`     // repeat and compare a value of the responses
+	Stream         *Stream                `yaml:"stream,omitempty" json:"stream,omitempty"`           // read the body as it arrives and record its chunks
 	Body           interface{}            `yaml:"body,omitempty" json:"body,omitempty"`
 	Auth           *AuthConfig            `yaml:"auth,omitempty" json:"auth,omitempty"`
 	Cookies        map[string]string      `yaml:"cookies,omitempty" json:"cookies,omitempty"`
@@ -141,18 +230,115 @@ type RetryConfig struct {
 	Condition string        `yaml:"condition,omitempty" json:"condition,omitempty"`
 }
 
+// Fault is injected client-side into a percentage of the requests of a step to exercise retry and
+// timeout handling: a delay before sending, a dropped connection (the request is sent but its
+// response is lost) or a replaced response status and body
+type Fault struct {
+	Percent float64       `yaml:"percent" json:"percent"` // share of requests affected, 0-100
+	Delay   time.Duration `yaml:"delay,omitempty" json:"delay,omitempty"`
+	Drop    bool          `yaml:"drop,omitempty" json:"drop,omitempty"`
+	Status  int           `yaml:"status,omitempty" json:"status,omitempty"`
+	Body    string        `yaml:"body,omitempty" json:"body,omitempty"` // replaces the body when status is set
+}
+
+// String describes the fault for reports
+func (f Fault) String() string {
+	var parts []string
+	if f.Delay > 0 {
+		parts = append(parts, "delay "+f.Delay.String())
+	}
+	if f.Drop {
+		parts = append(parts, "dropped connection")
+	}
+	if f.Status != 0 {
+		parts = append(parts, fmt.Sprintf("status %d", f.Status))
+	}
+	return strings.Join(parts, ", ")
+}
+
+func validateFaults(faults []Fault) error {
+	total := 0.0
+	for i, fault := range faults {
+		if fault.Percent <= 0 || fault.Percent > 100 {
+			return fmt.Errorf("fault %d: percent must be between 0 and 100", i+1)
+		}
+		if fault.Delay <= 0 && !fault.Drop && fault.Status == 0 {
+			return fmt.Errorf("fault %d: set delay, drop or status", i+1)
+		}
+		total += fault.Percent
+	}
+	if total > 100 {
+		return fmt.Errorf("fault percentages add up to %g%%, more than 100%%", total)
+	}
+	return nil
+}
+
 type DataSource struct {
-	Type string      `yaml:"type" json:"type"` // csv, json, inline
-	Path string      `yaml:"path,omitempty" json:"path,omitempty"`
-	Data interface{} `yaml:"data,omitempty" json:"data,omitempty"`
+	Type   string            `yaml:"type" json:"type"` // csv, json, jsonl (ndjson), inline, generated
+	Path   string            `yaml:"path,omitempty" json:"path,omitempty"`
+	Data   interface{}       `yaml:"data,omitempty" json:"data,omitempty"`
+	Rows   int               `yaml:"rows,omitempty" json:"rows,omitempty"`     // Number of generated rows
+	Fields map[string]string `yaml:"fields,omitempty" json:"fields,omitempty"` // Generator expression per field
+	Seed   int64             `yaml:"seed,omitempty" json:"seed,omitempty"`     // Seed for generated values (0 = random)
+	CSV    *CSVOptions       `yaml:"csv,omitempty" json:"csv,omitempty"`       // Delimiter, quoting, header and columns of csv files
+	// Transforms, applied in this order
+	Join   *DataJoin         `yaml:"join,omitempty" json:"join,omitempty"`     // Combine rows with another source on a key
+	Filter string            `yaml:"filter,omitempty" json:"filter,omitempty"` // Keep rows for which the expression is true
+	Select []string          `yaml:"select,omitempty" json:"select,omitempty"` // Keep only these fields
+	Rename map[string]string `yaml:"rename,omitempty" json:"rename,omitempty"` // Rename fields, old: new
+}
+
+// DataJoin mirrors data.Join
+type DataJoin struct {
+	Source string `yaml:"source" json:"source"`
+	On     string `yaml:"on" json:"on"`                         // field of this source
+	Key    string `yaml:"key,omitempty" json:"key,omitempty"`   // field of the joined source, defaults to on
+	As     string `yaml:"as,omitempty" json:"as,omitempty"`     // nest the joined row under this field
+	Type   string `yaml:"type,omitempty" json:"type,omitempty"` // inner (default) or left
+}
+
+// CSVOptions mirrors data.CSVOptions
+type CSVOptions struct {
+	Delimiter string   `yaml:"delimiter,omitempty" json:"delimiter,omitempty"` // one character, or "tab"; default ","
+	Quote     string   `yaml:"quote,omitempty" json:"quote,omitempty"`         // one character, or "none"; default "
+	Escape    string   `yaml:"escape,omitempty" json:"escape,omitempty"`       // escapes the next character in quoted values
+	Header    []string `yaml:"header,omitempty" json:"header,omitempty"`       // column names of a file without a header row
+	NoHeader  bool     `yaml:"no_header,omitempty" json:"no_header,omitempty"` // no header row; columns are column1, column2, ...
+	Columns   []string `yaml:"columns,omitempty" json:"columns,omitempty"`     // keep only these columns
+	Exclude   []string `yaml:"exclude,omitempty" json:"exclude,omitempty"`     // drop these columns
+	Raw       bool     `yaml:"raw,omitempty" json:"raw,omitempty"`             // keep values as strings, e.g. IDs like 007
 }
 
 type DataDrivenConfig struct {
 	Source   string `yaml:"source" json:"source"`     // Name of data source from scenario.data
 	Variable string `yaml:"variable" json:"variable"` // Variable name to store current data item
+	// Filter keeps rows for which the expression is true, e.g. "country == 'DE' && age >= 18"
+	Filter  string `yaml:"filter,omitempty" json:"filter,omitempty"`
+	Sample  int    `yaml:"sample,omitempty" json:"sample,omitempty"`   // Run N randomly chosen rows
+	Shuffle bool   `yaml:"shuffle,omitempty" json:"shuffle,omitempty"` // Run rows in random order
+	Seed    int64  `yaml:"seed,omitempty" json:"seed,omitempty"`       // Fixed seed for sample/shuffle (0 = random)
+	// Retries runs a failed row again up to this many times; rows passing on a retry are
+	// reported as warnings
+	Retries int `yaml:"retries,omitempty" json:"retries,omitempty"`
+	// FailedRows is a CSV (.csv) or JSON file the rows that still fail are written to, so they can
+	// be fed into a targeted run
+	FailedRows string `yaml:"failed_rows,omitempty" json:"failed_rows,omitempty"`
+}
+
+// LoadOptions configures how scenario files are read
+type LoadOptions struct {
+	// AgeKeyFile decrypts SOPS-encrypted scenarios and includes in addition to the age keys of
+	// the environment, e.g. the encryption.age_key_file of the config
+	AgeKeyFile string
 }
 
 func LoadScenario(filename string) (*Scenario, error) {
+	return LoadScenarioWithOptions(filename, LoadOptions{})
+}
+
+// LoadScenarioWithOptions loads a scenario file like LoadScenario with the given options
+func LoadScenarioWithOptions(filename string, options LoadOptions) (*Scenario, error) {
+	source := filename
 	if !filepath.IsAbs(filename) {
 		wd, err := os.Getwd()
 		if err != nil {
@@ -166,19 +352,84 @@ func LoadScenario(filename string) (*Scenario, error) {
 		return nil, fmt.Errorf("failed to read scenario file %s: %w", filename, err)
 	}
 
+	if sops.IsEncrypted(data) {
+		if data, err = sops.Decrypt(data, options.AgeKeyFile); err != nil {
+			return nil, fmt.Errorf("failed to decrypt scenario file %s: %w", filename, err)
+		}
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario file: %w", parseError(source, err))
+	}
+	includes := newIncluder(filename, options.AgeKeyFile)
+	if err := includes.resolve(&document, source); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario file: %w", err)
+	}
+	if errs := unknownFields(source, &document, reflect.TypeOf(Scenario{}), "scenario", includes.files); len(errs) > 0 {
+		return nil, fmt.Errorf("failed to parse scenario file: %w", errors.Join(errs...))
+	}
+
 	var scenario Scenario
-	if err := yaml.Unmarshal(data, &scenario); err != nil {
-		return nil, fmt.Errorf("failed to parse scenario file %s: %w", filename, err)
+	if err := document.Decode(&scenario); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario file: %w", parseError(source, err))
 	}
 
 	if err := validateScenario(&scenario); err != nil {
-		return nil, fmt.Errorf("invalid scenario in %s: %w", filename, err)
+		fileErr := &FileError{File: source, Err: fmt.Errorf("invalid scenario: %w", err)}
+		var at *positioned
+		if errors.As(err, &at) {
+			fileErr.Line, fileErr.Column = at.line, at.column
+		}
+		return nil, fileErr
 	}
 
+	scenario.SourceFile = source
+
 	return &scenario, nil
 }
 
+// LoadScenarios loads every scenario from the given files and directories
+func LoadScenarios(paths ...string) ([]*Scenario, error) {
+	return LoadScenariosWithOptions(LoadOptions{}, paths...)
+}
+
+// LoadScenariosWithOptions loads scenarios like LoadScenarios with the given options
+func LoadScenariosWithOptions(options LoadOptions, paths ...string) ([]*Scenario, error) {
+	var scenarios []*Scenario
+	for _, path := range paths {
+		stat, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to access %s: %w", path, err)
+		}
+
+		if stat.IsDir() {
+			dirScenarios, err := loadScenariosFromDir(path, options)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load scenarios from directory %s: %w", path, err)
+			}
+			scenarios = append(scenarios, dirScenarios...)
+		} else {
+			sc, err := LoadScenarioWithOptions(path, options)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load scenario %s: %w", path, err)
+			}
+			scenarios = append(scenarios, sc)
+		}
+	}
+
+	if len(scenarios) == 0 {
+		return nil, fmt.Errorf("no scenarios found")
+	}
+
+	return scenarios, nil
+}
+
 func LoadScenariosFromDir(dir string) ([]*Scenario, error) {
+	return loadScenariosFromDir(dir, LoadOptions{})
+}
+
+func loadScenariosFromDir(dir string, options LoadOptions) ([]*Scenario, error) {
 	var scenarios []*Scenario
 
 	entries, err := os.ReadDir(dir)
@@ -197,7 +448,7 @@ func LoadScenariosFromDir(dir string) ([]*Scenario, error) {
 		}
 
 		scenarioPath := filepath.Join(dir, entry.Name())
-		scenario, err := LoadScenario(scenarioPath)
+		scenario, err := LoadScenarioWithOptions(scenarioPath, options)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load scenario %s: %w", scenarioPath, err)
 		}
@@ -208,11 +459,34 @@ func LoadScenariosFromDir(dir string) ([]*Scenario, error) {
 	return scenarios, nil
 }
 
+// Validate checks a scenario built in code the way loading checks scenario files
+func (s *Scenario) Validate() error {
+	return validateScenario(s)
+}
+
 func validateScenario(scenario *Scenario) error {
 	if scenario.Name == "" {
 		return fmt.Errorf("scenario name is required")
 	}
 
+	if err := validateVersion(scenario.Version); err != nil {
+		return err
+	}
+
+	if scenario.Config != nil {
+		if scenario.Config.HTTP != nil {
+			if err := validateFaults(scenario.Config.HTTP.Faults); err != nil {
+				return fmt.Errorf("config.http.faults: %w", err)
+			}
+		}
+		if _, _, err := scenario.Config.ThinkTime.Range(); err != nil {
+			return fmt.Errorf("config.think_time: %w", err)
+		}
+		if _, _, err := scenario.Config.Pacing.Range(); err != nil {
+			return fmt.Errorf("config.pacing: %w", err)
+		}
+	}
+
 	// Validate either steps (legacy) or tests (new format)
 	if len(scenario.Steps) == 0 && len(scenario.Tests) == 0 {
 		return fmt.Errorf("scenario must have either steps or tests")
@@ -245,6 +519,14 @@ func validateScenario(scenario *Scenario) error {
 		}
 	}
 
+	for i, assertion := range scenario.Verify {
+		switch assertion.Type {
+		case "variable", "steps", "step":
+		default:
+			return fmt.Errorf("verify %d: type must be variable, steps or step, not %q", i+1, assertion.Type)
+		}
+	}
+
 	return nil
 }
 
@@ -259,14 +541,70 @@ func validateTestGroup(group *TestGroup, name string) error {
 		}
 	}
 
+	return validateBranches(group.OnFailure, group.OnSuccess)
+}
+
+// validateBranches validates on_failure and on_success steps
+func validateBranches(onFailure, onSuccess []Step) error {
+	for i, step := range onFailure {
+		if err := validateStep(&step, i); err != nil {
+			return fmt.Errorf("on_failure step %d (%s): %w", i+1, step.Name, err)
+		}
+	}
+	for i, step := range onSuccess {
+		if err := validateStep(&step, i); err != nil {
+			return fmt.Errorf("on_success step %d (%s): %w", i+1, step.Name, err)
+		}
+	}
 	return nil
 }
 
-func validateStep(step *Step, index int) error {
+var (
+	stepTypesMu sync.RWMutex
+	stepTypes   = map[string]bool{"http": true, "grpc": true, "websocket": true, "trpc": true, "soap": true, "custom": true}
+)
+
+// AllowStepType makes a custom step type pass scenario validation
+func AllowStepType(name string) {
+	stepTypesMu.Lock()
+	defer stepTypesMu.Unlock()
+	stepTypes[name] = true
+}
+
+func isValidStepType(name string) bool {
+	stepTypesMu.RLock()
+	defer stepTypesMu.RUnlock()
+	return stepTypes[name]
+}
+
+func validateStep(step *Step, index int) (err error) {
+	defer func() { err = atStep(step, err) }()
+
 	if step.Name == "" {
 		return fmt.Errorf("step name is required")
 	}
 
+	if err := validateFaults(step.Faults); err != nil {
+		return err
+	}
+	if _, _, err := step.ThinkTime.Range(); err != nil {
+		return fmt.Errorf("think_time: %w", err)
+	}
+	if step.Cleanup != nil {
+		if step.Cleanup.Name == "" {
+			step.Cleanup.Name = "Clean up " + step.Name
+		}
+		if err := validateStep(step.Cleanup, index); err != nil {
+			return fmt.Errorf("cleanup: %w", err)
+		}
+	}
+	if err := validateBranches(step.OnFailure, step.OnSuccess); err != nil {
+		return err
+	}
+	if err := step.Parallel.validate(); err != nil {
+		return err
+	}
+
 	// Handle new HTTP step format
 	if step.HTTP != nil {
 		if step.HTTP.URL == "" {
@@ -275,6 +613,14 @@ func validateStep(step *Step, index int) error {
 		if step.HTTP.Method == "" {
 			step.HTTP.Method = "GET" // default to GET
 		}
+		if err := step.HTTP.Stream.validate(); err != nil {
+			return err
+		}
+		return step.HTTP.Stability.validate()
+	}
+
+	// Steps without a request only set variables or assert on them
+	if step.Type == "" && step.Request.URL == "" && (len(step.Variables) > 0 || len(step.Assertions) > 0) {
 		return nil
 	}
 
@@ -284,15 +630,7 @@ func validateStep(step *Step, index int) error {
 	}
 
 	// Validate step type
-	validTypes := []string{"http", "grpc", "websocket", "trpc", "soap", "custom"}
-	valid := false
-	for _, validType := range validTypes {
-		if step.Type == validType {
-			valid = true
-			break
-		}
-	}
-	if !valid {
+	if !isValidStepType(step.Type) {
 		return fmt.Errorf("invalid step type: %s", step.Type)
 	}
 
@@ -305,6 +643,10 @@ func validateStep(step *Step, index int) error {
 		if step.Request.URL == "" && step.HTTP == nil {
 			return fmt.Errorf("HTTP request URL is required")
 		}
+		if err := step.Request.Stream.validate(); err != nil {
+			return err
+		}
+		return step.Request.Stability.validate()
 	}
 
 	return nil
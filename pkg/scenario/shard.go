@@ -0,0 +1,119 @@
+package scenario
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Shard is one of Total parts of a suite, for spreading it across parallel CI jobs; Index is
+// 1-based
+type Shard struct {
+	Index int
+	Total int
+}
+
+// ParseShard parses a shard written as index/total, such as 2/5
+func ParseShard(spec string) (Shard, error) {
+	index, total, found := strings.Cut(spec, "/")
+	if !found {
+		return Shard{}, fmt.Errorf("invalid shard %q, expected index/total such as 2/5", spec)
+	}
+	var shard Shard
+	var err error
+	if shard.Index, err = strconv.Atoi(strings.TrimSpace(index)); err != nil {
+		return Shard{}, fmt.Errorf("invalid shard %q, expected index/total such as 2/5", spec)
+	}
+	if shard.Total, err = strconv.Atoi(strings.TrimSpace(total)); err != nil {
+		return Shard{}, fmt.Errorf("invalid shard %q, expected index/total such as 2/5", spec)
+	}
+	return shard, shard.Validate()
+}
+
+// Validate checks that the index lies within 1..Total
+func (s Shard) Validate() error {
+	if s.Total < 1 || s.Index < 1 || s.Index > s.Total {
+		return fmt.Errorf("invalid shard %d/%d, the index must be between 1 and the total", s.Index, s.Total)
+	}
+	return nil
+}
+
+func (s Shard) String() string {
+	return fmt.Sprintf("%d/%d", s.Index, s.Total)
+}
+
+// Select returns the scenarios of this shard, in their original order. Every job of a suite
+// computes the same split: scenarios are dealt longest first to the shard with the least total
+// duration so far. durations holds the duration of scenarios by name from a previous run (see
+// reporting.Report.ScenarioDurations); matrix scenarios add up their combinations, and scenarios
+// without a duration count as the average. Without durations the split is by count.
+func (s Shard) Select(scenarios []*Scenario, durations map[string]time.Duration) []*Scenario {
+	weights := make([]time.Duration, len(scenarios))
+	var known time.Duration
+	knownCount := 0
+	for i, sc := range scenarios {
+		weights[i] = scenarioDuration(sc, durations)
+		if weights[i] > 0 {
+			known += weights[i]
+			knownCount++
+		}
+	}
+	fallback := time.Duration(1)
+	if knownCount > 0 {
+		fallback = known / time.Duration(knownCount)
+	}
+	for i := range weights {
+		if weights[i] <= 0 {
+			weights[i] = fallback
+		}
+	}
+
+	order := make([]int, len(scenarios))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		i, j := order[a], order[b]
+		if weights[i] != weights[j] {
+			return weights[i] > weights[j]
+		}
+		// Slashes keep shards the same when CI runs on Windows and Linux agents
+		if fileI, fileJ := filepath.ToSlash(scenarios[i].SourceFile), filepath.ToSlash(scenarios[j].SourceFile); fileI != fileJ {
+			return fileI < fileJ
+		}
+		return scenarios[i].Name < scenarios[j].Name
+	})
+
+	loads := make([]time.Duration, s.Total)
+	selected := make([]bool, len(scenarios))
+	for _, i := range order {
+		lightest := 0
+		for shard := range loads {
+			if loads[shard] < loads[lightest] {
+				lightest = shard
+			}
+		}
+		loads[lightest] += weights[i]
+		selected[i] = lightest == s.Index-1
+	}
+
+	var shard []*Scenario
+	for i, sc := range scenarios {
+		if selected[i] {
+			shard = append(shard, sc)
+		}
+	}
+	return shard
+}
+
+// scenarioDuration is the recorded duration of a scenario, or of all its matrix combinations
+func scenarioDuration(sc *Scenario, durations map[string]time.Duration) time.Duration {
+	var total time.Duration
+	for _, expanded := range ExpandMatrix(sc) {
+		total += durations[expanded.Name]
+	}
+	return total
+}
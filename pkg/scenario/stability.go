@@ -0,0 +1,46 @@
+package scenario
+
+import (
+	"fmt"
+	"time"
+)
+
+// Stability modes
+const (
+	StabilityIdentical     = "identical"      // every call returns the same value
+	StabilityIncreasing    = "increasing"     // every call returns a greater value than the one before
+	StabilityNonDecreasing = "non_decreasing" // no call returns a smaller value than the one before
+)
+
+// Stability repeats a request and fails the step unless a JSON path of the responses keeps the
+// same value, or only grows, across the calls. It catches load balancer nodes or caches serving
+// different data for the same request.
+type Stability struct {
+	Calls    int           `yaml:"calls" json:"calls"`                           // requests in total, including the first
+	Path     string        `yaml:"path" json:"path"`                             // JSON path of the compared value, e.g. $.version
+	Mode     string        `yaml:"mode,omitempty" json:"mode,omitempty"`         // identical (default), increasing or non_decreasing
+	Interval time.Duration `yaml:"interval,omitempty" json:"interval,omitempty"` // wait between calls
+}
+
+func (s *Stability) validate() error {
+	if s == nil {
+		return nil
+	}
+	if s.Calls < 2 {
+		return fmt.Errorf("stability: calls must be at least 2")
+	}
+	if s.Path == "" {
+		return fmt.Errorf("stability: path is required")
+	}
+	switch s.Mode {
+	case "":
+		s.Mode = StabilityIdentical
+	case StabilityIdentical, StabilityIncreasing, StabilityNonDecreasing:
+	default:
+		return fmt.Errorf("stability: unknown mode %q (expected identical, increasing or non_decreasing)", s.Mode)
+	}
+	if s.Interval < 0 {
+		return fmt.Errorf("stability: interval must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package scenario
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultKeepChunks is the number of chunks whose content a stream keeps when not set
+const DefaultKeepChunks = 10
+
+// Stream reads the response body as it arrives and records its chunks, so assertions can check
+// when the first one came, how many there were and what the early ones held. Reading stops at
+// the end of the body, after MaxDuration or once the body contains Until, which lets long-polling
+// and endless streams be tested.
+type Stream struct {
+	KeepChunks  int           `yaml:"keep_chunks,omitempty" json:"keep_chunks,omitempty"`   // chunks whose content is kept, 10 by default
+	MaxDuration time.Duration `yaml:"max_duration,omitempty" json:"max_duration,omitempty"` // stop reading after this long
+	Until       string        `yaml:"until,omitempty" json:"until,omitempty"`               // stop reading once the body contains this text
+}
+
+func (s *Stream) validate() error {
+	if s == nil {
+		return nil
+	}
+	if s.KeepChunks < 0 {
+		return fmt.Errorf("stream: keep_chunks must not be negative")
+	}
+	if s.MaxDuration < 0 {
+		return fmt.Errorf("stream: max_duration must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package scenario
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileError is a problem in a scenario file at the line and column it was found, when known.
+// It prints as file:line:column: message, which editors and CI annotations can jump to.
+type FileError struct {
+	File   string
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *FileError) Error() string {
+	position := e.File
+	if e.Line > 0 {
+		position += ":" + strconv.Itoa(e.Line)
+		if e.Column > 0 {
+			position += ":" + strconv.Itoa(e.Column)
+		}
+	}
+	return position + ": " + e.Err.Error()
+}
+
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// positioned marks a validation error with the position of the step it was found in
+type positioned struct {
+	line, column int
+	err          error
+}
+
+func (e *positioned) Error() string { return e.err.Error() }
+func (e *positioned) Unwrap() error { return e.err }
+
+// atStep attaches the position of a step to a validation error unless a nested step already did
+func atStep(step *Step, err error) error {
+	var inner *positioned
+	if err == nil || step.Line == 0 || errors.As(err, &inner) {
+		return err
+	}
+	return &positioned{line: step.Line, column: step.Column, err: err}
+}
+
+// yamlLine matches the position yaml.v3 puts in front of its parse and type errors
+var yamlLine = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+// parseError turns a yaml.v3 error into file errors, one per problem it reports
+func parseError(file string, err error) error {
+	messages := []string{err.Error()}
+	var typeErr *yaml.TypeError
+	if errors.As(err, &typeErr) {
+		messages = typeErr.Errors
+	}
+
+	errs := make([]error, 0, len(messages))
+	for _, message := range messages {
+		fileErr := &FileError{File: file}
+		if match := yamlLine.FindStringSubmatch(message); match != nil {
+			fileErr.Line, _ = strconv.Atoi(match[1])
+			message = match[2]
+		}
+		fileErr.Err = errors.New(strings.TrimPrefix(message, "yaml: "))
+		errs = append(errs, fileErr)
+	}
+	return errors.Join(errs...)
+}
+
+var (
+	unmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	stepType        = reflect.TypeOf(Step{})
+)
+
+// unknownFields reports every mapping key under node that the matching field of t does not
+// declare, so a typo like `cheks:` fails loading instead of being dropped. Keys starting with
+// "x-" are left for YAML anchors and tooling. where names the section in messages; included
+// maps nodes pulled in with !include to their file.
+func unknownFields(file string, node *yaml.Node, t reflect.Type, where string, included map[*yaml.Node]string) []error {
+	for node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+	if source, ok := included[node]; ok {
+		file = source
+	}
+	if node.Kind == yaml.AliasNode {
+		// The anchored node is checked where it is defined
+		return nil
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// Types with their own decoding accept other shapes; steps only record their position
+	if t != stepType && reflect.PointerTo(t).Implements(unmarshalerType) {
+		return nil
+	}
+
+	var errs []error
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		if node.Kind == yaml.SequenceNode {
+			for _, item := range node.Content {
+				errs = append(errs, unknownFields(file, item, t.Elem(), where, included)...)
+			}
+		}
+	case reflect.Map:
+		if node.Kind == yaml.MappingNode {
+			for i := 1; i < len(node.Content); i += 2 {
+				errs = append(errs, unknownFields(file, node.Content[i], t.Elem(), where, included)...)
+			}
+		}
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return nil
+		}
+		if t == stepType {
+			where = "step"
+		}
+		fields, open := yamlFields(t)
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				errs = append(errs, unknownFields(file, value, t, where, included)...)
+				continue
+			}
+			field, ok := fields[key.Value]
+			if !ok {
+				if !open && !strings.HasPrefix(key.Value, "x-") {
+					errs = append(errs, &FileError{File: file, Line: key.Line, Column: key.Column, Err: unknownField(key.Value, where, fields)})
+				}
+				continue
+			}
+			errs = append(errs, unknownFields(file, value, field, key.Value, included)...)
+		}
+	}
+	return errs
+}
+
+// yamlFields maps the keys a struct decodes to their types; open reports an inlined map that
+// takes any other key
+func yamlFields(t reflect.Type) (fields map[string]reflect.Type, open bool) {
+	fields = make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(options, "inline") {
+			inline := field.Type
+			for inline.Kind() == reflect.Pointer {
+				inline = inline.Elem()
+			}
+			if inline.Kind() == reflect.Map {
+				open = true
+				continue
+			}
+			inlined, inlineOpen := yamlFields(inline)
+			for key, typ := range inlined {
+				fields[key] = typ
+			}
+			open = open || inlineOpen
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+	}
+	return fields, open
+}
+
+func unknownField(key, where string, fields map[string]reflect.Type) error {
+	if suggestion := closestField(key, fields); suggestion != "" {
+		return fmt.Errorf("unknown field %q in %s, did you mean %q?", key, where, suggestion)
+	}
+	return fmt.Errorf("unknown field %q in %s", key, where)
+}
+
+// closestField suggests the field within two edits of a misspelled key
+func closestField(key string, fields map[string]reflect.Type) string {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	best, bestDistance := "", 3
+	for _, name := range names {
+		if distance := editDistance(key, name); distance < bestDistance {
+			best, bestDistance = name, distance
+		}
+	}
+	return best
+}
+
+// editDistance is the Levenshtein distance between two keys
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
@@ -0,0 +1,52 @@
+package scenario
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CurrentVersion is the version of the scenario format. Version 1.0 is the original format of
+// setup, steps and teardown with request steps; 1.1 adds the before, tests and after groups and
+// http steps. Files of both versions load, but the 1.0 fields are deprecated and
+// `fuego migrate` rewrites them.
+const CurrentVersion = "1.1"
+
+// Versions are the supported values of a scenario's version; an empty version is the current one
+var Versions = []string{"1.0", "1.1"}
+
+// DeprecatedFields maps the deprecated fields of scenarios and steps to their replacement
+var DeprecatedFields = map[string]string{
+	"setup":    "before",
+	"teardown": "after",
+	"request":  "http",
+}
+
+func validateVersion(version string) error {
+	if version == "" {
+		return nil
+	}
+	for _, supported := range Versions {
+		if version == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported version %q (supported: %s)", version, strings.Join(Versions, ", "))
+}
+
+// Deprecations lists the deprecated fields the scenario uses, for warnings
+func (s *Scenario) Deprecations() []string {
+	var deprecations []string
+	if len(s.Setup) > 0 {
+		deprecations = append(deprecations, "setup is deprecated, use before (fuego migrate rewrites it)")
+	}
+	if len(s.Teardown) > 0 {
+		deprecations = append(deprecations, "teardown is deprecated, use after (fuego migrate rewrites it)")
+	}
+
+	for _, step := range walkSteps(s) {
+		if step.HTTP == nil && step.Type == "http" {
+			deprecations = append(deprecations, fmt.Sprintf("step '%s': request is deprecated, use http (fuego migrate rewrites it)", step.Name))
+		}
+	}
+	return deprecations
+}
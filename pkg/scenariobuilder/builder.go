@@ -0,0 +1,307 @@
+// Package scenariobuilder builds scenarios from Go with a fluent API and writes them in the YAML
+// or JSON scenario format, e.g.
+//
+//	sc, err := scenariobuilder.NewScenario().
+//		Name("Orders").
+//		Step("Create order").HTTP("POST", "/orders").JSONBody(order).
+//		Check(scenariobuilder.Status(201), scenariobuilder.JSONPath("$.status", "eq", "pending")).
+//		Capture("order_id", scenariobuilder.FromJSON("$.id")).
+//		HTTP("GET", "/orders/{{order_id}}").Check(scenariobuilder.Status(200)).
+//		Build()
+package scenariobuilder
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"gopkg.in/yaml.v3"
+)
+
+// Builder builds one scenario. Step methods (Header, Check, Capture, ...) apply to the step
+// added last; using them before any step records an error returned by Build.
+type Builder struct {
+	scenario *scenario.Scenario
+	err      error
+}
+
+// NewScenario starts an empty scenario
+func NewScenario() *Builder {
+	return &Builder{scenario: &scenario.Scenario{Version: "1.0"}}
+}
+
+// Name sets the scenario name
+func (b *Builder) Name(name string) *Builder {
+	b.scenario.Name = name
+	return b
+}
+
+// Description sets the scenario description
+func (b *Builder) Description(description string) *Builder {
+	b.scenario.Description = description
+	return b
+}
+
+// Variable sets a scenario variable
+func (b *Builder) Variable(name string, value interface{}) *Builder {
+	if b.scenario.Variables == nil {
+		b.scenario.Variables = make(map[string]any)
+	}
+	b.scenario.Variables[name] = value
+	return b
+}
+
+// Label sets a metadata label such as owner or severity
+func (b *Builder) Label(key, value string) *Builder {
+	if b.scenario.Metadata.Labels == nil {
+		b.scenario.Metadata.Labels = make(map[string]string)
+	}
+	b.scenario.Metadata.Labels[key] = value
+	return b
+}
+
+// Tags adds metadata tags
+func (b *Builder) Tags(tags ...string) *Builder {
+	b.scenario.Metadata.Tags = append(b.scenario.Metadata.Tags, tags...)
+	return b
+}
+
+// Step adds a named step; follow it with HTTP to send a request, or leave it as a
+// variable-setting step
+func (b *Builder) Step(name string) *Builder {
+	b.scenario.Steps = append(b.scenario.Steps, scenario.Step{Name: name})
+	return b
+}
+
+// HTTP sends a request from the step started with Step, or adds a new step named after the
+// method and URL
+func (b *Builder) HTTP(method, url string) *Builder {
+	step := b.current()
+	if step == nil || step.HTTP != nil || step.Type != "" {
+		b.scenario.Steps = append(b.scenario.Steps, scenario.Step{Name: strings.ToUpper(method) + " " + url})
+		step = b.current()
+	}
+	step.HTTP = &scenario.HTTPStep{Method: strings.ToUpper(method), URL: url}
+	return b
+}
+
+// Header sets a request header of the current step; scenario.Unset removes an inherited default
+func (b *Builder) Header(name, value string) *Builder {
+	if http := b.http("Header"); http != nil {
+		if http.Headers == nil {
+			http.Headers = make(scenario.Params)
+		}
+		http.Headers[name] = value
+	}
+	return b
+}
+
+// Query sets a query parameter of the current step
+func (b *Builder) Query(name, value string) *Builder {
+	if http := b.http("Query"); http != nil {
+		if http.Query == nil {
+			http.Query = make(scenario.Params)
+		}
+		http.Query[name] = value
+	}
+	return b
+}
+
+// PathParam sets the value substituted, escaped, for :name or {name} in the URL of the current step
+func (b *Builder) PathParam(name, value string) *Builder {
+	if http := b.http("PathParam"); http != nil {
+		if http.PathParams == nil {
+			http.PathParams = make(map[string]string)
+		}
+		http.PathParams[name] = value
+	}
+	return b
+}
+
+// Revalidate repeats the request of the current step with each validator of its response
+// (If-None-Match, If-Modified-Since) and fails the step unless each gets 304 Not Modified
+func (b *Builder) Revalidate() *Builder {
+	if http := b.http("Revalidate"); http != nil {
+		http.Revalidate = true
+	}
+	return b
+}
+
+// CORS sends the CORS preflight of the current step's request instead of the request, and
+// fails the step unless the response allows it
+func (b *Builder) CORS(cors scenario.CORSPreflight) *Builder {
+	if http := b.http("CORS"); http != nil {
+		http.CORS = &cors
+	}
+	return b
+}
+
+// Stability repeats the request of the current step and fails the step unless the value at a
+// JSON path stays the same, or grows, across the calls (see scenario.Stability)
+func (b *Builder) Stability(stability scenario.Stability) *Builder {
+	if http := b.http("Stability"); http != nil {
+		http.Stability = &stability
+	}
+	return b
+}
+
+// JSONBody sends value as the JSON body of the current step
+func (b *Builder) JSONBody(value interface{}) *Builder {
+	if http := b.http("JSONBody"); http != nil {
+		http.JSON = value
+	}
+	return b
+}
+
+// Body sends a raw body with the current step
+func (b *Builder) Body(body interface{}) *Builder {
+	if http := b.http("Body"); http != nil {
+		http.Body = body
+	}
+	return b
+}
+
+// Bearer authenticates the current step with a bearer token
+func (b *Builder) Bearer(token string) *Builder {
+	if http := b.http("Bearer"); http != nil {
+		http.Auth = &scenario.AuthConfig{Type: "bearer", Token: token}
+	}
+	return b
+}
+
+// BasicAuth authenticates the current step with a username and password
+func (b *Builder) BasicAuth(username, password string) *Builder {
+	if http := b.http("BasicAuth"); http != nil {
+		http.Auth = &scenario.AuthConfig{Type: "basic", Username: username, Password: password}
+	}
+	return b
+}
+
+// Check adds expectations to the current step
+func (b *Builder) Check(expectations ...Expectation) *Builder {
+	step := b.step("Check")
+	if step == nil {
+		return b
+	}
+	for _, expectation := range expectations {
+		if expectation.assertion != nil {
+			step.Assertions = append(step.Assertions, *expectation.assertion)
+			continue
+		}
+		if step.Check == nil {
+			step.Check = make(map[string]interface{})
+		}
+		step.Check[expectation.check] = expectation.value
+	}
+	return b
+}
+
+// Capture stores a value of the current step's response in a variable
+func (b *Builder) Capture(name string, capture scenario.Capture) *Builder {
+	if step := b.step("Capture"); step != nil {
+		if step.Capture == nil {
+			step.Capture = make(map[string]scenario.Capture)
+		}
+		step.Capture[name] = capture
+	}
+	return b
+}
+
+// Set sets a step variable, e.g. on a step started with Step
+func (b *Builder) Set(name string, value interface{}) *Builder {
+	if step := b.step("Set"); step != nil {
+		if step.Variables == nil {
+			step.Variables = make(map[string]any)
+		}
+		step.Variables[name] = value
+	}
+	return b
+}
+
+// Describe documents what the current step verifies; docsURL (optional) links its API contract
+func (b *Builder) Describe(description, docsURL string) *Builder {
+	if step := b.step("Describe"); step != nil {
+		step.Description = description
+		step.DocsURL = docsURL
+	}
+	return b
+}
+
+// Build validates and returns the scenario
+func (b *Builder) Build() (*scenario.Scenario, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if err := b.scenario.Validate(); err != nil {
+		return nil, err
+	}
+	return b.scenario, nil
+}
+
+// YAML renders the scenario in the YAML scenario format
+func (b *Builder) YAML() ([]byte, error) {
+	sc, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(sc)
+}
+
+// JSON renders the scenario in the JSON scenario format
+func (b *Builder) JSON() ([]byte, error) {
+	sc, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(sc, "", "  ")
+}
+
+// WriteFile writes the scenario as JSON for .json paths and as YAML otherwise
+func (b *Builder) WriteFile(path string) error {
+	render := b.YAML
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		render = b.JSON
+	}
+	data, err := render()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write scenario %s: %w", path, err)
+	}
+	return nil
+}
+
+func (b *Builder) current() *scenario.Step {
+	if len(b.scenario.Steps) == 0 {
+		return nil
+	}
+	return &b.scenario.Steps[len(b.scenario.Steps)-1]
+}
+
+// step returns the current step, recording an error when there is none
+func (b *Builder) step(method string) *scenario.Step {
+	step := b.current()
+	if step == nil && b.err == nil {
+		b.err = fmt.Errorf("%s called before any step was added", method)
+	}
+	return step
+}
+
+// http returns the request of the current step, recording an error when it has none
+func (b *Builder) http(method string) *scenario.HTTPStep {
+	step := b.step(method)
+	if step == nil {
+		return nil
+	}
+	if step.HTTP == nil {
+		if b.err == nil {
+			b.err = fmt.Errorf("step %q: %s called before HTTP", step.Name, method)
+		}
+		return nil
+	}
+	return step.HTTP
+}
@@ -0,0 +1,79 @@
+package scenariobuilder
+
+import (
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+)
+
+// Expectation is a check on a step's response, added with Builder.Check
+type Expectation struct {
+	// check and value become an entry of the step's check map; assertion is used instead when set
+	check     string
+	value     interface{}
+	assertion *scenario.Assertion
+}
+
+// Status expects the response status code
+func Status(code int) Expectation {
+	return Expectation{check: "status", value: code}
+}
+
+// Header expects a response header value
+func Header(name, value string) Expectation {
+	return assert("header", name, "eq", value)
+}
+
+// JSONPath compares the value at a JSON path with an operator such as eq, gt or contains
+func JSONPath(path, operator string, value interface{}) Expectation {
+	return assert("json_path", path, operator, value)
+}
+
+// BodyContains expects the response body to contain text
+func BodyContains(text string) Expectation {
+	return assert("body", "", "contains", text)
+}
+
+// BodyMatches expects the response body to match a regular expression
+func BodyMatches(pattern string) Expectation {
+	return assert("body", "", "matches", pattern)
+}
+
+// ResponseTimeBelow expects the response to arrive faster than limit
+func ResponseTimeBelow(limit time.Duration) Expectation {
+	return assert("response_time", "", "lt", limit.Milliseconds())
+}
+
+// Assertion adds an arbitrary assertion, e.g. a custom operator or a JSON schema
+func Assertion(assertion scenario.Assertion) Expectation {
+	return Expectation{assertion: &assertion}
+}
+
+func assert(assertionType, field, operator string, value interface{}) Expectation {
+	return Assertion(scenario.Assertion{Type: assertionType, Field: field, Operator: operator, Value: value})
+}
+
+// FromJSON captures the value at a JSON path of the response body
+func FromJSON(path string) scenario.Capture {
+	return scenario.Capture{JSONPath: path}
+}
+
+// FromHeader captures a response header
+func FromHeader(name string) scenario.Capture {
+	return scenario.Capture{Header: name}
+}
+
+// FromCookie captures a cookie set by the response, with its attributes
+func FromCookie(name string) scenario.Capture {
+	return scenario.Capture{Cookie: name}
+}
+
+// FromLinks captures the Link headers of the response as a map of rel to URL
+func FromLinks() scenario.Capture {
+	return scenario.Capture{Links: true}
+}
+
+// FromRegex captures the first group of a regular expression matched against the response body
+func FromRegex(pattern string) scenario.Capture {
+	return scenario.Capture{Regex: pattern}
+}
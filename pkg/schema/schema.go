@@ -0,0 +1,213 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+)
+
+const draft = "http://json-schema.org/draft-07/schema#"
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+	// preflightType is also written as a bare URL
+	preflightType = reflect.TypeOf(config.PreflightCheck{})
+	// paramsType values may be null to remove an inherited default
+	paramsType = reflect.TypeOf(scenario.Params{})
+)
+
+// Generator builds JSON Schema documents from Go struct definitions using their yaml tags
+type Generator struct {
+	// Required lists the yaml keys that must be present for a given struct type
+	Required map[reflect.Type][]string
+
+	defs map[string]map[string]interface{}
+}
+
+// NewGenerator creates a new schema generator
+func NewGenerator() *Generator {
+	return &Generator{
+		Required: make(map[reflect.Type][]string),
+		defs:     make(map[string]map[string]interface{}),
+	}
+}
+
+// Scenario returns the JSON Schema for scenario files
+func Scenario() map[string]interface{} {
+	g := NewGenerator()
+	// Mirror the checks performed by scenario validation
+	g.Required[reflect.TypeOf(scenario.Scenario{})] = []string{"name"}
+	g.Required[reflect.TypeOf(scenario.Step{})] = []string{"name"}
+	g.Required[reflect.TypeOf(scenario.TestGroup{})] = []string{"steps"}
+	g.Required[reflect.TypeOf(scenario.HTTPStep{})] = []string{"url"}
+	g.Required[reflect.TypeOf(scenario.Assertion{})] = []string{"type"}
+	g.Required[reflect.TypeOf(scenario.DataSource{})] = []string{"type"}
+	g.Required[reflect.TypeOf(scenario.DataDrivenConfig{})] = []string{"source", "variable"}
+	doc := g.Generate(reflect.TypeOf(scenario.Scenario{}), "Fuego scenario")
+
+	// Editors flag unknown versions and offer the replacement of deprecated fields
+	properties := doc["properties"].(map[string]interface{})
+	properties["version"] = map[string]interface{}{"type": "string", "enum": scenario.Versions}
+	stepProperties := g.defs["Step"]["properties"].(map[string]interface{})
+	for field, replacement := range scenario.DeprecatedFields {
+		target := properties
+		if _, isStepField := stepProperties[field]; isStepField {
+			target = stepProperties
+		}
+		if property, ok := target[field].(map[string]interface{}); ok {
+			property["deprecated"] = true
+			property["deprecationMessage"] = fmt.Sprintf("Deprecated, use %s (fuego migrate rewrites it)", replacement)
+		}
+	}
+	return doc
+}
+
+// Config returns the JSON Schema for .fuego.yaml configuration files
+func Config() map[string]interface{} {
+	return NewGenerator().Generate(reflect.TypeOf(config.Config{}), "Fuego configuration")
+}
+
+// Marshal renders a schema document as indented JSON
+func Marshal(doc map[string]interface{}) ([]byte, error) {
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// Generate builds a complete schema document for the given root type
+func (g *Generator) Generate(t reflect.Type, title string) map[string]interface{} {
+	root := g.typeSchema(t)
+
+	// Inline the root definition so editors see its properties at the top level
+	if ref, ok := root["$ref"].(string); ok {
+		root = g.defs[strings.TrimPrefix(ref, "#/definitions/")]
+	}
+
+	doc := map[string]interface{}{
+		"$schema": draft,
+		"title":   title,
+	}
+	for k, v := range root {
+		doc[k] = v
+	}
+	if len(g.defs) > 0 {
+		doc["definitions"] = g.defs
+	}
+
+	return doc
+}
+
+func (g *Generator) typeSchema(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case durationType:
+		// Durations are written as strings ("30s") but plain nanosecond integers are accepted too
+		return map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]interface{}{"type": "string", "pattern": `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`},
+				map[string]interface{}{"type": "integer"},
+			},
+		}
+	case timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	case paramsType:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": map[string]interface{}{"type": []interface{}{"string", "null"}},
+		}
+	case preflightType:
+		return map[string]interface{}{
+			"anyOf": []interface{}{
+				map[string]interface{}{"type": "string"},
+				g.structRef(t),
+			},
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": g.typeSchema(t.Elem()),
+		}
+	case reflect.Map:
+		schema := map[string]interface{}{"type": "object"}
+		if t.Elem().Kind() != reflect.Interface {
+			schema["additionalProperties"] = g.typeSchema(t.Elem())
+		}
+		return schema
+	case reflect.Struct:
+		return g.structRef(t)
+	default:
+		// interface{} values accept anything
+		return map[string]interface{}{}
+	}
+}
+
+func (g *Generator) structRef(t reflect.Type) map[string]interface{} {
+	name := t.Name()
+	ref := map[string]interface{}{"$ref": "#/definitions/" + name}
+
+	if _, exists := g.defs[name]; exists {
+		return ref
+	}
+
+	// Register a placeholder first so self-referencing types terminate
+	def := map[string]interface{}{}
+	g.defs[name] = def
+
+	properties := make(map[string]interface{})
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		fieldName, skip := yamlFieldName(field)
+		if skip {
+			continue
+		}
+
+		properties[fieldName] = g.typeSchema(field.Type)
+	}
+
+	def["type"] = "object"
+	def["properties"] = properties
+	def["additionalProperties"] = false
+	if required := g.Required[t]; len(required) > 0 {
+		def["required"] = required
+	}
+
+	return ref
+}
+
+func yamlFieldName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("yaml")
+	if tag == "-" {
+		return "", true
+	}
+
+	name := strings.Split(tag, ",")[0]
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+
+	return name, false
+}
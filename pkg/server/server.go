@@ -0,0 +1,443 @@
+// Package server runs scenarios on request over HTTP, so developer portals and other tools can
+// trigger suites and follow their progress without wrapping the CLI:
+//
+//	POST   /api/v1/runs             start a run (RunRequest), answers 202 with the Run
+//	GET    /api/v1/runs             list runs, newest first
+//	GET    /api/v1/runs/{id}        status of a run, with the report once finished
+//	GET    /api/v1/runs/{id}/events events of a run as Server-Sent Events, from the first one
+//	DELETE /api/v1/runs/{id}        cancel a run
+//	GET    /healthz                 liveness
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nulln0ne/fuego"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+)
+
+// Status is the state of a run
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusPassed    Status = "passed"
+	StatusFailed    Status = "failed"
+	StatusError     Status = "error" // the run could not complete, see Run.Error
+	StatusCancelled Status = "cancelled"
+)
+
+// Options configures a server
+type Options struct {
+	// Root is the directory scenario paths of requests are resolved in. A path, symlinks
+	// followed, must stay inside it; files that scenarios include or read data from are not
+	// checked.
+	Root string
+	// ConfigFile is loaded for every run, so config changes apply without a restart
+	ConfigFile string
+	// Token, when set, must be sent as "Authorization: Bearer <token>" on every API request
+	Token string
+	// MaxRuns is the number of runs executing at once; more are queued (default 2)
+	MaxRuns int
+	// History is the number of finished runs kept in memory (default 100)
+	History int
+}
+
+// RunRequest is the body of POST /api/v1/runs
+type RunRequest struct {
+	// Paths are scenario files or directories relative to the server root
+	Paths       []string `json:"paths"`
+	Labels      []string `json:"labels,omitempty"`
+	Environment string   `json:"environment,omitempty"`
+	FailOn      string   `json:"fail_on,omitempty"`
+	Seed        int64    `json:"seed,omitempty"`
+	Repeat      int      `json:"repeat,omitempty"`
+	IncludeBody bool     `json:"include_body,omitempty"`
+}
+
+// Run is a run as the API reports it
+type Run struct {
+	ID       string             `json:"id"`
+	Status   Status             `json:"status"`
+	Request  RunRequest         `json:"request"`
+	Created  time.Time          `json:"created"`
+	Started  *time.Time         `json:"started,omitempty"`
+	Finished *time.Time         `json:"finished,omitempty"`
+	Error    string             `json:"error,omitempty"`
+	Summary  *reporting.Summary `json:"summary,omitempty"`
+	// Report is only returned by GET /api/v1/runs/{id}
+	Report *reporting.Report `json:"report,omitempty"`
+}
+
+// Server executes runs and keeps their events for streaming. It is safe for concurrent use.
+type Server struct {
+	options Options
+	slots   chan struct{}
+
+	mu   sync.Mutex
+	runs map[string]*run
+}
+
+// run is the state of one submitted run; events only grow, and changed is closed and replaced
+// whenever they do, waking up every stream following the run
+type run struct {
+	mu      sync.Mutex
+	info    Run
+	report  *reporting.Report
+	events  []reporting.Event
+	done    bool
+	changed chan struct{}
+	cancel  context.CancelFunc
+}
+
+// New returns a server; call Handler to serve its API
+func New(options Options) *Server {
+	if options.Root == "" {
+		options.Root = "."
+	}
+	if options.MaxRuns <= 0 {
+		options.MaxRuns = 2
+	}
+	if options.History <= 0 {
+		options.History = 100
+	}
+	return &Server{
+		options: options,
+		slots:   make(chan struct{}, options.MaxRuns),
+		runs:    make(map[string]*run),
+	}
+}
+
+// Handler serves the API
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.Handle("/api/v1/runs", s.authorize(http.HandlerFunc(s.handleRuns)))
+	mux.Handle("/api/v1/runs/", s.authorize(http.HandlerFunc(s.handleRun)))
+	return mux
+}
+
+// Cancel stops every queued and running run, e.g. when shutting down
+func (s *Server) Cancel() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.runs {
+		r.cancel()
+	}
+}
+
+func (s *Server) authorize(next http.Handler) http.Handler {
+	if s.options.Token == "" {
+		return next
+	}
+	want := "Bearer " + s.options.Token
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(want)) != 1 {
+			writeError(w, http.StatusUnauthorized, "missing or invalid bearer token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleRuns(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.list())
+	case http.MethodPost:
+		var request RunRequest
+		decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&request); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid run request: %v", err))
+			return
+		}
+		info, err := s.submit(request)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		w.Header().Set("Location", "/api/v1/runs/"+info.ID)
+		writeJSON(w, http.StatusAccepted, info)
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, "use GET to list runs or POST to start one")
+	}
+}
+
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	id, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/v1/runs/"), "/")
+	s.mu.Lock()
+	current := s.runs[id]
+	s.mu.Unlock()
+	if current == nil {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("run %q not found", id))
+		return
+	}
+
+	switch {
+	case rest == "" && r.Method == http.MethodGet:
+		current.mu.Lock()
+		info := current.info
+		info.Report = current.report
+		current.mu.Unlock()
+		writeJSON(w, http.StatusOK, info)
+	case rest == "" && r.Method == http.MethodDelete:
+		current.cancel()
+		w.WriteHeader(http.StatusAccepted)
+	case rest == "events" && r.Method == http.MethodGet:
+		s.stream(w, r, current)
+	case rest == "" || rest == "events":
+		writeError(w, http.StatusMethodNotAllowed, fmt.Sprintf("%s is not supported here", r.Method))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// submit validates a request and queues its run
+func (s *Server) submit(request RunRequest) (Run, error) {
+	if len(request.Paths) == 0 {
+		return Run{}, errors.New("paths is required")
+	}
+	paths := make([]string, len(request.Paths))
+	for i, path := range request.Paths {
+		resolved, err := s.resolve(path)
+		if err != nil {
+			return Run{}, err
+		}
+		paths[i] = resolved
+	}
+
+	id, err := newID()
+	if err != nil {
+		return Run{}, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	current := &run{
+		info:    Run{ID: id, Status: StatusQueued, Request: request, Created: time.Now()},
+		changed: make(chan struct{}),
+		cancel:  cancel,
+	}
+
+	info := current.info
+	s.mu.Lock()
+	s.runs[id] = current
+	s.prune()
+	s.mu.Unlock()
+
+	go s.execute(ctx, current, fuego.Options{
+		Paths:       paths,
+		Labels:      request.Labels,
+		ConfigFile:  s.options.ConfigFile,
+		Environment: request.Environment,
+		FailOn:      request.FailOn,
+		Seed:        request.Seed,
+		Repeat:      request.Repeat,
+		IncludeBody: request.IncludeBody,
+		OnEvent:     current.publish,
+	})
+	return info, nil
+}
+
+// resolve turns a request path into a path inside the server root
+func (s *Server) resolve(path string) (string, error) {
+	if path == "" || filepath.IsAbs(path) || filepath.VolumeName(path) != "" {
+		return "", fmt.Errorf("path %q must be relative to the server root", path)
+	}
+	clean := filepath.Clean(filepath.FromSlash(path))
+	if !insideRoot(clean) {
+		return "", fmt.Errorf("path %q is outside the server root", path)
+	}
+
+	// A symlink under the root may point out of it
+	root, err := filepath.EvalSymlinks(s.options.Root)
+	if err != nil {
+		return "", fmt.Errorf("server root: %w", err)
+	}
+	target, err := filepath.EvalSymlinks(filepath.Join(root, clean))
+	if err != nil {
+		return "", fmt.Errorf("path %q does not exist in the server root", path)
+	}
+	if relative, err := filepath.Rel(root, target); err != nil || !insideRoot(relative) {
+		return "", fmt.Errorf("path %q is outside the server root", path)
+	}
+	return filepath.Join(s.options.Root, clean), nil
+}
+
+// insideRoot reports whether a cleaned relative path stays in the directory it is relative to
+func insideRoot(clean string) bool {
+	return clean != ".." && !strings.HasPrefix(clean, ".."+string(filepath.Separator))
+}
+
+func (s *Server) execute(ctx context.Context, current *run, options fuego.Options) {
+	defer current.cancel()
+
+	select {
+	case s.slots <- struct{}{}:
+		defer func() { <-s.slots }()
+	case <-ctx.Done():
+		current.finish(nil, ctx.Err())
+		return
+	}
+
+	current.mu.Lock()
+	started := time.Now()
+	current.info.Status, current.info.Started = StatusRunning, &started
+	current.mu.Unlock()
+
+	report, err := fuego.Run(ctx, options)
+	current.finish(report, err)
+}
+
+// prune forgets the oldest finished runs beyond the history size; s.mu is held
+func (s *Server) prune() {
+	var finished []*run
+	for _, r := range s.runs {
+		r.mu.Lock()
+		if r.done {
+			finished = append(finished, r)
+		}
+		r.mu.Unlock()
+	}
+	if len(finished) <= s.options.History {
+		return
+	}
+	sort.Slice(finished, func(i, j int) bool { return finished[i].info.Created.Before(finished[j].info.Created) })
+	for _, r := range finished[:len(finished)-s.options.History] {
+		delete(s.runs, r.info.ID)
+	}
+}
+
+// list returns the runs without reports, newest first
+func (s *Server) list() []Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	runs := make([]Run, 0, len(s.runs))
+	for _, r := range s.runs {
+		r.mu.Lock()
+		runs = append(runs, r.info)
+		r.mu.Unlock()
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].Created.After(runs[j].Created) })
+	return runs
+}
+
+// stream writes the events of a run as Server-Sent Events until the run finishes or the client
+// goes away. Every event is sent, including those emitted before the client connected; the id of
+// each is its index, and a reconnecting client resumes after Last-Event-ID.
+func (s *Server) stream(w http.ResponseWriter, r *http.Request, current *run) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+	next := 0
+	if last := r.Header.Get("Last-Event-ID"); last != "" {
+		fmt.Sscanf(last, "%d", &next)
+		next++
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		current.mu.Lock()
+		var events []reporting.Event
+		if next < len(current.events) {
+			events = current.events[next:]
+		}
+		done, changed := current.done, current.changed
+		current.mu.Unlock()
+
+		for _, event := range events {
+			data, err := json.Marshal(event.Compact())
+			if err != nil {
+				return
+			}
+			fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", next, event.Type, data)
+			next++
+		}
+		if done {
+			fmt.Fprintf(w, "event: end\ndata: {}\n\n")
+			flusher.Flush()
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-changed:
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// publish records an event of the run and wakes up its streams
+func (r *run) publish(event reporting.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// finish records the outcome of the run and ends its streams
+func (r *run) finish(report *reporting.Report, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	finished := time.Now()
+	r.info.Finished = &finished
+	r.report = report
+	if report != nil {
+		summary := report.Summary
+		r.info.Summary = &summary
+	}
+	switch {
+	case errors.Is(err, context.Canceled):
+		r.info.Status = StatusCancelled
+	case err != nil:
+		r.info.Status, r.info.Error = StatusError, err.Error()
+	case report.Passed():
+		r.info.Status = StatusPassed
+	default:
+		r.info.Status = StatusFailed
+	}
+	r.done = true
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+func newID() (string, error) {
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return "", fmt.Errorf("failed to generate run id: %w", err)
+	}
+	return hex.EncodeToString(id[:]), nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,123 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/diff"
+)
+
+// DefaultDir is the directory snapshots are stored in when none is configured
+const DefaultDir = "__snapshots__"
+
+var unsafeChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// reservedName matches file names Windows refuses to create, whatever the extension
+var reservedName = regexp.MustCompile(`(?i)^(con|prn|aux|nul|com[0-9]|lpt[0-9])(\.|$)`)
+
+// Store reads and writes response snapshots on disk
+type Store struct {
+	dir    string
+	update bool
+}
+
+// NewStore creates a snapshot store rooted at dir. When update is true, stored snapshots are
+// overwritten with the current response instead of being compared.
+func NewStore(dir string, update bool) *Store {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	return &Store{
+		dir:    dir,
+		update: update,
+	}
+}
+
+// Path returns the snapshot file for the given name segments (scenario, step, ...)
+func (s *Store) Path(segments ...string) string {
+	parts := make([]string, 0, len(segments))
+	for _, segment := range segments {
+		if segment == "" {
+			continue
+		}
+		part := strings.Trim(unsafeChars.ReplaceAllString(segment, "_"), "_")
+		if reservedName.MatchString(part) {
+			part = "_" + part
+		}
+		parts = append(parts, part)
+	}
+	return filepath.Join(s.dir, filepath.Join(parts...)+".snap")
+}
+
+// Match compares a response body with the stored snapshot, creating it on first use.
+// JSON bodies are normalized (sorted keys, ignore paths removed) before comparison.
+func (s *Store) Match(path string, body string, ignore []string) (bool, string, error) {
+	normalized := Normalize(body, ignore)
+
+	existing, err := os.ReadFile(path)
+	if os.IsNotExist(err) || s.update {
+		if err := s.write(path, normalized); err != nil {
+			return false, "", err
+		}
+		if os.IsNotExist(err) {
+			return true, fmt.Sprintf("snapshot created at %s", path), nil
+		}
+		return true, fmt.Sprintf("snapshot updated at %s", path), nil
+	}
+	if err != nil {
+		return false, "", fmt.Errorf("failed to read snapshot %s: %w", path, err)
+	}
+
+	// Git on Windows may check snapshots out with \r\n line endings
+	if strings.ReplaceAll(string(existing), "\r\n", "\n") == strings.ReplaceAll(normalized, "\r\n", "\n") {
+		return true, "response matches snapshot", nil
+	}
+
+	return false, fmt.Sprintf("response does not match snapshot %s: %s", path, describeMismatch(string(existing), normalized)), nil
+}
+
+// Normalize renders a body in its canonical snapshot form
+func Normalize(body string, ignore []string) string {
+	var parsed interface{}
+	if err := json.Unmarshal([]byte(body), &parsed); err != nil {
+		return body
+	}
+
+	normalized, err := json.MarshalIndent(diff.Remove(parsed, ignore), "", "  ")
+	if err != nil {
+		return body
+	}
+	return string(normalized) + "\n"
+}
+
+func (s *Store) write(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return fmt.Errorf("failed to write snapshot %s: %w", path, err)
+	}
+	return nil
+}
+
+func describeMismatch(expected, actual string) string {
+	differences, err := diff.CompareJSON([]byte(expected), []byte(actual), nil)
+	if err != nil {
+		return "body differs"
+	}
+
+	const maxShown = 5
+	messages := make([]string, 0, maxShown)
+	for i, difference := range differences {
+		if i == maxShown {
+			messages = append(messages, fmt.Sprintf("and %d more", len(differences)-maxShown))
+			break
+		}
+		messages = append(messages, difference.String())
+	}
+	return strings.Join(messages, "; ")
+}
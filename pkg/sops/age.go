@@ -0,0 +1,71 @@
+package sops
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+// loadIdentities reads the age keys the way sops does: SOPS_AGE_KEY holds keys, SOPS_AGE_KEY_FILE
+// names a key file, and $XDG_CONFIG_HOME/sops/age/keys.txt is read when it exists. keyFile, e.g.
+// the encryption.age_key_file of the config, is read too when set.
+func loadIdentities(keyFile string) ([]age.Identity, error) {
+	var sources []string
+	if keys := os.Getenv("SOPS_AGE_KEY"); keys != "" {
+		sources = append(sources, keys)
+	}
+
+	for _, path := range []string{os.Getenv("SOPS_AGE_KEY_FILE"), keyFile} {
+		if path == "" {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read age key file: %w", err)
+		}
+		sources = append(sources, string(content))
+	}
+
+	configDir := os.Getenv("XDG_CONFIG_HOME")
+	if configDir == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			configDir = filepath.Join(home, ".config")
+		}
+	}
+	if configDir != "" {
+		if content, err := os.ReadFile(filepath.Join(configDir, "sops", "age", "keys.txt")); err == nil {
+			sources = append(sources, string(content))
+		}
+	}
+
+	var identities []age.Identity
+	for _, source := range sources {
+		parsed, err := age.ParseIdentities(strings.NewReader(source))
+		if err != nil {
+			return nil, fmt.Errorf("invalid age key: %w", err)
+		}
+		identities = append(identities, parsed...)
+	}
+	return identities, nil
+}
+
+// decryptAge decrypts an age file, armored or binary, with the first identity that can unwrap its
+// file key
+func decryptAge(content []byte, identities []age.Identity) ([]byte, error) {
+	var encrypted io.Reader = bytes.NewReader(content)
+	if trimmed := bytes.TrimSpace(content); bytes.HasPrefix(trimmed, []byte(armor.Header)) {
+		encrypted = armor.NewReader(bytes.NewReader(trimmed))
+	}
+
+	decrypted, err := age.Decrypt(encrypted, identities...)
+	if err != nil {
+		return nil, err
+	}
+	return io.ReadAll(decrypted)
+}
@@ -0,0 +1,249 @@
+// Package sops decrypts YAML and JSON files encrypted with SOPS for age recipients, so suites can
+// keep sensitive payloads encrypted in the repository and decrypt them when they are loaded
+package sops
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	encryptedValue  = regexp.MustCompile(`^ENC\[AES256_GCM,data:([^,]*),iv:([^,]+),tag:([^,]+),type:([a-z]+)\]$`)
+	sopsKey         = regexp.MustCompile(`(?m)^sops:|"sops"\s*:`)
+	binaryEncrypted = regexp.MustCompile(`^\s*\{\s*"data"\s*:\s*"ENC\[AES256_GCM,`)
+)
+
+// metadata is the sops block of an encrypted file
+type metadata struct {
+	Age []struct {
+		Recipient string `yaml:"recipient"`
+		Enc       string `yaml:"enc"`
+	} `yaml:"age"`
+	LastModified     string `yaml:"lastmodified"`
+	MAC              string `yaml:"mac"`
+	MACOnlyEncrypted bool   `yaml:"mac_only_encrypted"`
+}
+
+// IsEncrypted reports whether a file holds SOPS-encrypted values
+func IsEncrypted(content []byte) bool {
+	return bytes.Contains(content, []byte("ENC[AES256_GCM,")) && sopsKey.Match(content)
+}
+
+// IsEncryptedBinary reports whether a file is any other file (CSV, JSON Lines, ...) encrypted
+// by SOPS as a whole, which it stores as a JSON document with a single data value
+func IsEncryptedBinary(content []byte) bool {
+	return binaryEncrypted.Match(content)
+}
+
+// Decrypt decrypts a SOPS-encrypted YAML or JSON file into the plain file, in the same format and
+// without the sops metadata. The MAC of the file is checked, so edits to encrypted files that did
+// not go through sops are errors. keyFile names an age key file used in addition to the keys of
+// the environment, such as the encryption.age_key_file of the config; it may be empty.
+func Decrypt(content []byte, keyFile string) ([]byte, error) {
+	var document yaml.Node
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse encrypted file: %w", err)
+	}
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("encrypted file is not a mapping")
+	}
+	root := document.Content[0]
+
+	var meta *metadata
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "sops" {
+			meta = &metadata{}
+			if err := root.Content[i+1].Decode(meta); err != nil {
+				return nil, fmt.Errorf("invalid sops metadata: %w", err)
+			}
+			root.Content = append(root.Content[:i], root.Content[i+2:]...)
+			break
+		}
+	}
+	if meta == nil {
+		return nil, fmt.Errorf("file has no sops metadata")
+	}
+
+	dataKey, err := meta.dataKey(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha512.New()
+	if err := decryptNode(root, nil, dataKey, func(value []byte, encrypted bool) {
+		if encrypted || !meta.MACOnlyEncrypted {
+			hash.Write(value)
+		}
+	}); err != nil {
+		return nil, err
+	}
+
+	if meta.MAC != "" {
+		mac, _, err := decryptValue(meta.MAC, dataKey, meta.LastModified)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decrypt sops MAC: %w", err)
+		}
+		if subtle.ConstantTimeCompare([]byte(mac), []byte(fmt.Sprintf("%X", hash.Sum(nil)))) != 1 {
+			return nil, fmt.Errorf("sops MAC does not match, the file was modified after it was encrypted")
+		}
+	}
+
+	if trimmed := bytes.TrimSpace(content); len(trimmed) > 0 && trimmed[0] == '{' {
+		var decoded interface{}
+		if err := document.Decode(&decoded); err != nil {
+			return nil, err
+		}
+		return json.MarshalIndent(decoded, "", "  ")
+	}
+	return yaml.Marshal(&document)
+}
+
+// DecryptBinary decrypts a file SOPS encrypted as a whole and returns its original content
+func DecryptBinary(content []byte, keyFile string) ([]byte, error) {
+	decrypted, err := Decrypt(content, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	var wrapper struct {
+		Data string `json:"data"`
+	}
+	if err := json.Unmarshal(decrypted, &wrapper); err != nil {
+		return nil, err
+	}
+	return []byte(wrapper.Data), nil
+}
+
+// dataKey decrypts the data key of the file with the available age identities
+func (m *metadata) dataKey(keyFile string) ([]byte, error) {
+	if len(m.Age) == 0 {
+		return nil, fmt.Errorf("file is not encrypted for age recipients, other sops key types are not supported")
+	}
+	identities, err := loadIdentities(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	if len(identities) == 0 {
+		return nil, fmt.Errorf("no age key to decrypt the file with, set SOPS_AGE_KEY_FILE, SOPS_AGE_KEY or encryption.age_key_file")
+	}
+
+	var recipients []string
+	for _, entry := range m.Age {
+		if key, err := decryptAge([]byte(entry.Enc), identities); err == nil {
+			return key, nil
+		}
+		recipients = append(recipients, entry.Recipient)
+	}
+	return nil, fmt.Errorf("none of the age keys is a recipient of the file (%s)", strings.Join(recipients, ", "))
+}
+
+// decryptNode decrypts the values of a node in place, in document order, and passes every value
+// to hash as sops computes its MAC. Encrypted comments are dropped.
+func decryptNode(node *yaml.Node, path []string, key []byte, hash func([]byte, bool)) error {
+	dropEncryptedComments(node)
+
+	switch node.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for _, child := range node.Content {
+			if err := decryptNode(child, path, key, hash); err != nil {
+				return err
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			dropEncryptedComments(node.Content[i])
+			childPath := append(append([]string{}, path...), node.Content[i].Value)
+			if err := decryptNode(node.Content[i+1], childPath, key, hash); err != nil {
+				return err
+			}
+		}
+	case yaml.ScalarNode:
+		if node.Tag == "!!str" && encryptedValue.MatchString(node.Value) {
+			value, valueType, err := decryptValue(node.Value, key, strings.Join(path, ":")+":")
+			if err != nil {
+				return fmt.Errorf("failed to decrypt %s: %w", strings.Join(path, "."), err)
+			}
+			node.Value, node.Style = value, 0
+			node.Tag = map[string]string{"int": "!!int", "float": "!!float", "bool": "!!bool"}[valueType]
+			if node.Tag == "" {
+				node.Tag = "!!str"
+			}
+			if node.Tag == "!!bool" {
+				node.Value = strings.ToLower(value)
+			}
+			hash([]byte(value), true)
+			return nil
+		}
+		hash(plainBytes(node), false)
+	}
+	return nil
+}
+
+func dropEncryptedComments(node *yaml.Node) {
+	for _, comment := range []*string{&node.HeadComment, &node.LineComment, &node.FootComment} {
+		if strings.Contains(*comment, "ENC[AES256_GCM,") {
+			*comment = ""
+		}
+	}
+}
+
+// plainBytes is the MAC input of a value that is not encrypted, which sops derives from its type
+func plainBytes(node *yaml.Node) []byte {
+	switch node.Tag {
+	case "!!int":
+		if value, err := strconv.ParseInt(node.Value, 0, 64); err == nil {
+			return []byte(strconv.FormatInt(value, 10))
+		}
+	case "!!float":
+		if value, err := strconv.ParseFloat(node.Value, 64); err == nil {
+			return []byte(strconv.FormatFloat(value, 'f', -1, 64))
+		}
+	case "!!bool":
+		if value, err := strconv.ParseBool(node.Value); err == nil && value {
+			return []byte("True")
+		}
+		return []byte("False")
+	case "!!null":
+		return nil
+	}
+	return []byte(node.Value)
+}
+
+// decryptValue decrypts an ENC[AES256_GCM,...] value, authenticating the path of the value as
+// additional data so values cannot be moved between keys
+func decryptValue(value string, key []byte, additionalData string) (string, string, error) {
+	match := encryptedValue.FindStringSubmatch(value)
+	if match == nil {
+		return "", "", fmt.Errorf("invalid encrypted value")
+	}
+	data, err1 := base64.StdEncoding.DecodeString(match[1])
+	iv, err2 := base64.StdEncoding.DecodeString(match[2])
+	tag, err3 := base64.StdEncoding.DecodeString(match[3])
+	if err1 != nil || err2 != nil || err3 != nil {
+		return "", "", fmt.Errorf("invalid encrypted value encoding")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return "", "", err
+	}
+	gcm, err := cipher.NewGCMWithNonceSize(block, len(iv))
+	if err != nil {
+		return "", "", err
+	}
+	plaintext, err := gcm.Open(nil, iv, append(data, tag...), []byte(additionalData))
+	if err != nil {
+		return "", "", fmt.Errorf("value authentication failed")
+	}
+	return string(plaintext), match[4], nil
+}
@@ -0,0 +1,159 @@
+package variables
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Clock returns the current time
+type Clock func() time.Time
+
+// FrozenClock always returns t, so every time builtin of a run sees the same instant
+func FrozenClock(t time.Time) Clock {
+	return func() time.Time { return t }
+}
+
+// SetClock replaces the clock used by time builtins; nil restores the wall clock
+func (c *Context) SetClock(clock Clock) {
+	c.clock = clock
+}
+
+// Now returns the current time of the context clock
+func (c *Context) Now() time.Time {
+	if c.clock != nil {
+		return c.clock()
+	}
+	return time.Now()
+}
+
+var builtinNames = []string{"timestamp", "timestamp_ms", "iso_timestamp", "date", "time"}
+
+// IsBuiltin reports whether name is one of the time variables added by AddBuiltins
+func IsBuiltin(name string) bool {
+	for _, builtin := range builtinNames {
+		if name == builtin {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Context) builtin(name string) (interface{}, bool) {
+	now := c.Now()
+	switch name {
+	case "timestamp":
+		return now.Unix(), true
+	case "timestamp_ms":
+		return now.UnixMilli(), true
+	case "iso_timestamp":
+		return now.Format(time.RFC3339), true
+	case "date":
+		return now.Format("2006-01-02"), true
+	case "time":
+		return now.Format("15:04:05"), true
+	}
+	return nil, false
+}
+
+var (
+	timeCall    = regexp.MustCompile(`^(now|today)\((.*)\)$`)
+	offsetPart  = regexp.MustCompile(`(\d+)(ms|s|m|h|d|w|M|y)`)
+	timeLayouts = map[string]string{
+		"RFC3339":     time.RFC3339,
+		"RFC3339Nano": time.RFC3339Nano,
+		"RFC1123":     time.RFC1123,
+		"RFC1123Z":    time.RFC1123Z,
+		"RFC822":      time.RFC822,
+		"RFC822Z":     time.RFC822Z,
+		"ANSIC":       time.ANSIC,
+		"Kitchen":     time.Kitchen,
+		"date":        "2006-01-02",
+		"time":        "15:04:05",
+		"datetime":    "2006-01-02 15:04:05",
+	}
+)
+
+// callTimeFunction evaluates now(offset, layout) and today(offset, layout). Both arguments are
+// optional: offsets start with + or - and combine units (ms, s, m, h, d, w, M, y), e.g. +1d12h;
+// layouts are names (RFC3339, RFC1123, date, unix, unix_ms, ...) or Go layouts. now defaults to
+// RFC 3339 and today, which starts at midnight, to 2006-01-02.
+func (c *Context) callTimeFunction(call string) (string, bool, error) {
+	match := timeCall.FindStringSubmatch(call)
+	if match == nil {
+		return "", false, nil
+	}
+
+	t := c.Now()
+	layout := time.RFC3339
+	if match[1] == "today" {
+		t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+		layout = "2006-01-02"
+	}
+
+	if args := strings.TrimSpace(match[2]); args != "" {
+		for _, arg := range strings.Split(args, ",") {
+			arg = strings.Trim(strings.TrimSpace(arg), `'"`)
+			if strings.HasPrefix(arg, "+") || strings.HasPrefix(arg, "-") {
+				shifted, err := applyOffset(t, arg)
+				if err != nil {
+					return "", true, err
+				}
+				t = shifted
+				continue
+			}
+			if named, ok := timeLayouts[arg]; ok {
+				layout = named
+			} else {
+				layout = arg
+			}
+		}
+	}
+
+	switch layout {
+	case "unix":
+		return strconv.FormatInt(t.Unix(), 10), true, nil
+	case "unix_ms":
+		return strconv.FormatInt(t.UnixMilli(), 10), true, nil
+	}
+	return t.Format(layout), true, nil
+}
+
+func applyOffset(t time.Time, offset string) (time.Time, error) {
+	sign := 1
+	if offset[0] == '-' {
+		sign = -1
+	}
+
+	rest := offset[1:]
+	parts := offsetPart.FindAllStringSubmatch(rest, -1)
+	if len(parts) == 0 || offsetPart.ReplaceAllString(rest, "") != "" {
+		return t, fmt.Errorf("invalid time offset %q", offset)
+	}
+
+	for _, part := range parts {
+		n, _ := strconv.Atoi(part[1])
+		n *= sign
+		switch part[2] {
+		case "ms":
+			t = t.Add(time.Duration(n) * time.Millisecond)
+		case "s":
+			t = t.Add(time.Duration(n) * time.Second)
+		case "m":
+			t = t.Add(time.Duration(n) * time.Minute)
+		case "h":
+			t = t.Add(time.Duration(n) * time.Hour)
+		case "d":
+			t = t.AddDate(0, 0, n)
+		case "w":
+			t = t.AddDate(0, 0, 7*n)
+		case "M":
+			t = t.AddDate(0, n, 0)
+		case "y":
+			t = t.AddDate(n, 0, 0)
+		}
+	}
+	return t, nil
+}
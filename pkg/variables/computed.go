@@ -0,0 +1,118 @@
+package variables
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nulln0ne/fuego/pkg/expr"
+)
+
+// maxComputedDepth bounds how deep computed variables may refer to each other, which also stops
+// a variable that is computed from itself
+const maxComputedDepth = 16
+
+// DeclareGlobal sets a variable of a variables block (config or scenario) whose templates are
+// evaluated each time it is used, so it follows the variables it is computed from
+func (c *Context) DeclareGlobal(key string, value interface{}) {
+	c.global[key] = value
+	c.declare(scopeGlobal, key, true)
+}
+
+// DeclareLocal is DeclareGlobal for local variables, e.g. those of a scenario or test group
+func (c *Context) DeclareLocal(key string, value interface{}) {
+	c.local[key] = value
+	c.declare(scopeLocal, key, true)
+}
+
+// DeclareNamespaced records a declared variable in a namespace, next to DeclareGlobal or
+// DeclareLocal
+func (c *Context) DeclareNamespaced(namespace, key string, value interface{}) {
+	c.SetNamespaced(namespace, key, value)
+	c.declare(namespace, key, true)
+}
+
+// declare marks whether a variable of a scope is computed; setting a literal over a declared
+// variable makes it literal
+func (c *Context) declare(scope, key string, computed bool) {
+	if !computed {
+		delete(c.declared[scope], key)
+		return
+	}
+	if c.declared[scope] == nil {
+		c.declared[scope] = make(map[string]bool)
+	}
+	c.declared[scope][key] = true
+}
+
+// resolve returns a variable, or evaluates the name as an expression such as price * quantity
+// when no variable has that name. An expression that refers to an unknown variable is not
+// resolved, like an unknown variable. Only declared variables are computed; captured and data
+// values are returned as they are, even when they contain templates.
+func (c *Context) resolve(name string, depth int) (interface{}, bool, error) {
+	if value, declared, exists := c.lookupNested(name); exists {
+		if !declared {
+			return value, true, nil
+		}
+		return c.computed(value, depth)
+	}
+	if !strings.ContainsAny(name, " +-*/%<>=!&|?:()[]'\"") {
+		return nil, false, nil
+	}
+	compiled, err := expr.Compile(name)
+	if err != nil {
+		return nil, false, nil // not an expression, left as written
+	}
+
+	missing := false
+	var resolveErr error
+	value, err := compiled.Eval(expr.Env{Resolve: func(path string) (interface{}, bool) {
+		value, exists, err := c.resolve(path, depth)
+		if err != nil && resolveErr == nil {
+			resolveErr = err
+		}
+		missing = missing || !exists
+		return value, exists
+	}})
+	switch {
+	case resolveErr != nil:
+		return nil, false, resolveErr
+	case missing:
+		return nil, false, nil
+	case err != nil:
+		return nil, false, err
+	}
+	if value == nil {
+		return "", true, nil
+	}
+	return value, true, nil
+}
+
+// computed evaluates the templates in a variable when it is used, so variables can be derived
+// from others (total: "{{price * quantity}}"). A value that is a single template keeps the type
+// of its result.
+func (c *Context) computed(value interface{}, depth int) (interface{}, bool, error) {
+	s, ok := value.(string)
+	if !ok || !strings.Contains(s, "{{") {
+		return value, true, nil
+	}
+	if depth >= maxComputedDepth {
+		return nil, false, fmt.Errorf("computed variables nest deeper than %d levels, is one computed from itself?", maxComputedDepth)
+	}
+
+	if match := templateRegex.FindStringIndex(s); match != nil && match[0] == 0 && match[1] == len(s) {
+		result, exists, err := c.resolveTemplate(templateName(s), depth+1)
+		if err != nil || !exists {
+			return s, true, err
+		}
+		return result, true, nil
+	}
+	interpolated, err := c.interpolate(s, depth+1)
+	return interpolated, true, err
+}
+
+// Value interpolates a template like InterpolateString, except that a template that is a single
+// variable or expression keeps the type of its value
+func (c *Context) Value(input string) (interface{}, error) {
+	value, _, err := c.computed(input, 0)
+	return value, err
+}
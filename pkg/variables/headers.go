@@ -0,0 +1,121 @@
+package variables
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+func responseHeaders(response map[string]interface{}) (http.Header, error) {
+	switch headers := response["headers"].(type) {
+	case map[string][]string:
+		return http.Header(headers), nil
+	case http.Header:
+		return headers, nil
+	default:
+		return nil, fmt.Errorf("response headers are not in expected format")
+	}
+}
+
+// extractCookie parses the Set-Cookie header that sets name into a map of its value and
+// attributes (path, domain, expires, max_age, secure, http_only, same_site)
+func extractCookie(response map[string]interface{}, name string) (interface{}, error) {
+	headers, err := responseHeaders(response)
+	if err != nil {
+		return nil, err
+	}
+
+	cookies := (&http.Response{Header: headers}).Cookies()
+	for i := len(cookies) - 1; i >= 0; i-- {
+		if cookies[i].Name == name {
+			return cookieMap(cookies[i]), nil
+		}
+	}
+	return nil, fmt.Errorf("cookie %s not set", name)
+}
+
+func cookieMap(cookie *http.Cookie) map[string]interface{} {
+	attributes := map[string]interface{}{
+		"name":      cookie.Name,
+		"value":     cookie.Value,
+		"path":      cookie.Path,
+		"domain":    cookie.Domain,
+		"max_age":   cookie.MaxAge,
+		"secure":    cookie.Secure,
+		"http_only": cookie.HttpOnly,
+		"same_site": "",
+		"expires":   "",
+	}
+	if !cookie.Expires.IsZero() {
+		attributes["expires"] = cookie.Expires.UTC().Format(time.RFC3339)
+	}
+	switch cookie.SameSite {
+	case http.SameSiteLaxMode:
+		attributes["same_site"] = "Lax"
+	case http.SameSiteStrictMode:
+		attributes["same_site"] = "Strict"
+	case http.SameSiteNoneMode:
+		attributes["same_site"] = "None"
+	}
+	return attributes
+}
+
+// extractLinks parses the Link headers (RFC 8288) into a map of rel to URL, so pagination can
+// follow {{links.next}}; a link with several space-separated rels is listed under each
+func extractLinks(response map[string]interface{}) (interface{}, error) {
+	headers, err := responseHeaders(response)
+	if err != nil {
+		return nil, err
+	}
+
+	values := headers.Values("Link")
+	if len(values) == 0 {
+		return nil, fmt.Errorf("header Link not found")
+	}
+
+	links := make(map[string]interface{})
+	for _, value := range values {
+		for _, link := range splitLinks(value) {
+			target, params, found := strings.Cut(link, ";")
+			target = strings.TrimSpace(target)
+			if !found || !strings.HasPrefix(target, "<") || !strings.HasSuffix(target, ">") {
+				continue
+			}
+			target = target[1 : len(target)-1]
+
+			for _, param := range strings.Split(params, ";") {
+				key, rel, _ := strings.Cut(strings.TrimSpace(param), "=")
+				if !strings.EqualFold(strings.TrimSpace(key), "rel") {
+					continue
+				}
+				for _, name := range strings.Fields(strings.Trim(strings.TrimSpace(rel), `"`)) {
+					links[strings.ToLower(name)] = target
+				}
+			}
+		}
+	}
+	return links, nil
+}
+
+// splitLinks splits a Link header on the commas between links, leaving commas inside <URL>
+// and quoted parameters alone
+func splitLinks(header string) []string {
+	var links []string
+	var inURL, inQuotes bool
+	start := 0
+	for i, r := range header {
+		switch {
+		case r == '<' && !inQuotes:
+			inURL = true
+		case r == '>' && !inQuotes:
+			inURL = false
+		case r == '"' && !inURL:
+			inQuotes = !inQuotes
+		case r == ',' && !inURL && !inQuotes:
+			links = append(links, header[start:i])
+			start = i + 1
+		}
+	}
+	return append(links, header[start:])
+}
@@ -0,0 +1,63 @@
+package variables
+
+import "strings"
+
+// Namespaces name where a variable came from. Templates such as {{captures.token}} reach a
+// variable through its namespace even when a variable from elsewhere shadows its name in the
+// flat lookup, which checks step, then local, then global variables.
+const (
+	NamespaceConfig   = "config"   // variables of the config file and its environments
+	NamespaceScenario = "scenario" // env and variables of the scenario
+	NamespaceTest     = "test"     // env and matrix values of the running test group
+	NamespaceStep     = "step"     // variables set by steps
+	NamespaceCaptures = "captures" // values captured from responses
+	NamespaceData     = "data"     // data sources and the current data-driven rows
+)
+
+var namespaceNames = []string{NamespaceConfig, NamespaceScenario, NamespaceTest, NamespaceStep, NamespaceCaptures, NamespaceData}
+
+// IsNamespace reports whether a name is one of the template namespaces
+func IsNamespace(name string) bool {
+	for _, namespace := range namespaceNames {
+		if name == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// SetNamespaced records a variable in a namespace. It does not change the flat lookup, so it is
+// called next to SetGlobal, SetLocal or SetStep.
+func (c *Context) SetNamespaced(namespace, key string, value interface{}) {
+	values := c.namespaces[namespace]
+	if values == nil {
+		values = make(map[string]interface{})
+		c.namespaces[namespace] = values
+	}
+	values[key] = value
+	c.declare(namespace, key, false)
+}
+
+// Namespaced returns a copy of the variables recorded in a namespace
+func (c *Context) Namespaced(namespace string) map[string]interface{} {
+	copied := make(map[string]interface{}, len(c.namespaces[namespace]))
+	for k, v := range c.namespaces[namespace] {
+		copied[k] = deepCopy(v)
+	}
+	return copied
+}
+
+// namespaced resolves namespace.name paths. Names the namespace does not define fall back to the
+// flat lookup, so variables that happen to be called data or config keep working.
+func (c *Context) namespaced(path string) (interface{}, bool) {
+	namespace, rest, _ := strings.Cut(path, ".")
+	if !IsNamespace(namespace) {
+		return nil, false
+	}
+	parts := strings.Split(rest, ".")
+	value, exists := c.namespaces[namespace][parts[0]]
+	if !exists {
+		return nil, false
+	}
+	return c.navigate(value, parts[1:])
+}
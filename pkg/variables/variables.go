@@ -1,18 +1,32 @@
 package variables
 
 import (
+	"crypto/rand"
 	"encoding/json"
 	"fmt"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
-	"time"
 )
 
 type Context struct {
 	global map[string]interface{}
 	local  map[string]interface{}
 	step   map[string]interface{}
+
+	// namespaces records the variables by origin, for templates such as {{captures.token}}
+	namespaces map[string]map[string]interface{}
+
+	// declared marks, by scope (global, local, step or a namespace), the variables declared in
+	// variables blocks, whose templates are evaluated when used. Captured and data values are
+	// literals.
+	declared map[string]map[string]bool
+
+	// clock provides the current time to time builtins; nil means the wall clock
+	clock Clock
+	// builtins enables the time variables (timestamp, date, ...) added by AddBuiltins
+	builtins bool
 }
 
 func NewContext() *Context {
@@ -20,39 +34,78 @@ func NewContext() *Context {
 		global: make(map[string]interface{}),
 		local:  make(map[string]interface{}),
 		step:   make(map[string]interface{}),
+
+		namespaces: make(map[string]map[string]interface{}),
+		declared:   make(map[string]map[string]bool),
 	}
 }
 
+// Scopes of the flat lookup, as recorded in declared
+const (
+	scopeGlobal = "global"
+	scopeLocal  = "local"
+	scopeStep   = "step"
+)
+
+// SetGlobal, SetLocal and SetStep set literal values, such as captures and data rows; templates
+// in them are kept as written
 func (c *Context) SetGlobal(key string, value interface{}) {
 	c.global[key] = value
+	c.declare(scopeGlobal, key, false)
 }
 
 func (c *Context) SetLocal(key string, value interface{}) {
 	c.local[key] = value
+	c.declare(scopeLocal, key, false)
 }
 
 func (c *Context) SetStep(key string, value interface{}) {
 	c.step[key] = value
+	c.declare(scopeStep, key, false)
 }
 
 func (c *Context) Get(key string) (interface{}, bool) {
-	// Check step scope first, then local, then global
+	value, _, exists := c.lookup(key)
+	return value, exists
+}
+
+// lookup returns a variable and the scope it was found in: step first, then local, then global
+func (c *Context) lookup(key string) (interface{}, string, bool) {
 	if value, exists := c.step[key]; exists {
-		return value, true
+		return value, scopeStep, true
 	}
 	if value, exists := c.local[key]; exists {
-		return value, true
+		return value, scopeLocal, true
 	}
 	if value, exists := c.global[key]; exists {
-		return value, true
+		return value, scopeGlobal, true
 	}
-	return nil, false
+	if c.builtins {
+		value, exists := c.builtin(key)
+		return value, "", exists
+	}
+	return nil, "", false
 }
 
 func (c *Context) GetNested(path string) (interface{}, bool) {
+	value, _, exists := c.lookupNested(path)
+	return value, exists
+}
+
+// lookupNested resolves a dotted path like GetNested and also reports whether its root is a
+// declared variable
+func (c *Context) lookupNested(path string) (interface{}, bool, bool) {
 	// If no dot notation, use regular Get
 	if !strings.Contains(path, ".") {
-		return c.Get(path)
+		value, scope, exists := c.lookup(path)
+		return value, c.declared[scope][path], exists
+	}
+
+	// Namespaced names such as captures.token skip the flat lookup
+	if value, exists := c.namespaced(path); exists {
+		namespace, rest, _ := strings.Cut(path, ".")
+		name, _, _ := strings.Cut(rest, ".")
+		return value, c.declared[namespace][name], true
 	}
 
 	// Split path into parts
@@ -60,13 +113,17 @@ func (c *Context) GetNested(path string) (interface{}, bool) {
 	rootKey := parts[0]
 
 	// Get the root object
-	current, exists := c.Get(rootKey)
+	current, scope, exists := c.lookup(rootKey)
 	if !exists {
-		return nil, false
+		return nil, false, false
 	}
+	value, exists := c.navigate(current, parts[1:])
+	return value, c.declared[scope][rootKey], exists
+}
 
-	// Navigate through the path
-	for _, part := range parts[1:] {
+// navigate follows the parts of a dotted path through nested values
+func (c *Context) navigate(current interface{}, parts []string) (interface{}, bool) {
+	for _, part := range parts {
 		switch v := current.(type) {
 		case map[string]interface{}:
 			if value, ok := v[part]; ok {
@@ -116,6 +173,12 @@ func (c *Context) accessJSONPath(data interface{}, key string) (interface{}, err
 func (c *Context) GetAll() map[string]interface{} {
 	result := make(map[string]interface{})
 
+	if c.builtins {
+		for _, name := range builtinNames {
+			result[name], _ = c.builtin(name)
+		}
+	}
+
 	// Add in order: global, local, step (later ones override earlier ones)
 	for k, v := range c.global {
 		result[k] = v
@@ -130,21 +193,77 @@ func (c *Context) GetAll() map[string]interface{} {
 	return result
 }
 
+// Snapshot returns the variables set so far, without builtins, for Changed to compare against
+func (c *Context) Snapshot() map[string]interface{} {
+	snapshot := make(map[string]interface{}, len(c.global)+len(c.local)+len(c.step))
+	for _, scope := range []map[string]interface{}{c.global, c.local, c.step} {
+		for k, v := range scope {
+			snapshot[k] = v
+		}
+	}
+	return snapshot
+}
+
+// Changed returns the variables that were set after snapshot was taken or now hold another value
+func (c *Context) Changed(snapshot map[string]interface{}) map[string]interface{} {
+	changed := make(map[string]interface{})
+	for k, v := range c.Snapshot() {
+		if before, existed := snapshot[k]; !existed || !reflect.DeepEqual(before, v) {
+			changed[k] = v
+		}
+	}
+	return changed
+}
+
+// StepVariables returns a copy of the variables of the current step
+func (c *Context) StepVariables() map[string]interface{} {
+	copied := make(map[string]interface{}, len(c.step))
+	for k, v := range c.step {
+		copied[k] = deepCopy(v)
+	}
+	return copied
+}
+
 func (c *Context) ClearStep() {
 	c.step = make(map[string]interface{})
+	delete(c.namespaces, NamespaceStep)
+	delete(c.declared, scopeStep)
+	delete(c.declared, NamespaceStep)
 }
 
 func (c *Context) Clone() *Context {
 	clone := NewContext()
+	clone.clock = c.clock
+	clone.builtins = c.builtins
 
-	// Deep copy global variables
+	// Nested maps and slices are copied too, so iterations and concurrent groups cannot change
+	// the values of each other through a shared reference
 	for k, v := range c.global {
-		clone.global[k] = v
+		clone.global[k] = deepCopy(v)
 	}
-
-	// Deep copy local variables
 	for k, v := range c.local {
-		clone.local[k] = v
+		clone.local[k] = deepCopy(v)
+	}
+	for namespace, values := range c.namespaces {
+		// The step namespace holds step variables, so it is left out like them
+		if namespace == NamespaceStep {
+			continue
+		}
+		copied := make(map[string]interface{}, len(values))
+		for k, v := range values {
+			copied[k] = deepCopy(v)
+		}
+		clone.namespaces[namespace] = copied
+	}
+	for scope, names := range c.declared {
+		if scope == scopeStep {
+			continue
+		}
+		copied := make(map[string]bool, len(names))
+		for k, v := range names {
+			copied[k] = v
+		}
+		clone.declared[scope] = copied
 	}
 
 	// Step variables are not cloned as they're step-specific
@@ -152,31 +271,133 @@ func (c *Context) Clone() *Context {
 	return clone
 }
 
+// deepCopy copies the maps and slices of a value recursively; other values are immutable or
+// pointers, which are shared as they are
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, string, bool, int, int64, float64:
+		return v
+	case map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		copied := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			copied[k] = deepCopy(item)
+		}
+		return copied
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		copied := make([]interface{}, len(v))
+		for i, item := range v {
+			copied[i] = deepCopy(item)
+		}
+		return copied
+	case []map[string]interface{}:
+		if v == nil {
+			return v
+		}
+		copied := make([]map[string]interface{}, len(v))
+		for i, item := range v {
+			copied[i], _ = deepCopy(item).(map[string]interface{})
+		}
+		return copied
+	}
+	return deepCopyValue(reflect.ValueOf(value)).Interface()
+}
+
+// deepCopyValue covers the map and slice types without a case in deepCopy, such as
+// map[string]string or []string
+func deepCopyValue(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Interface:
+		if value.IsNil() {
+			return value
+		}
+		copied := deepCopyValue(value.Elem())
+		wrapped := reflect.New(value.Type()).Elem()
+		wrapped.Set(copied)
+		return wrapped
+	case reflect.Map:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.MakeMapWithSize(value.Type(), value.Len())
+		iter := value.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), deepCopyValue(iter.Value()))
+		}
+		return copied
+	case reflect.Slice:
+		if value.IsNil() {
+			return value
+		}
+		copied := reflect.MakeSlice(value.Type(), value.Len(), value.Len())
+		for i := 0; i < value.Len(); i++ {
+			copied.Index(i).Set(deepCopyValue(value.Index(i)))
+		}
+		return copied
+	case reflect.Array:
+		copied := reflect.New(value.Type()).Elem()
+		for i := 0; i < value.Len(); i++ {
+			copied.Index(i).Set(deepCopyValue(value.Index(i)))
+		}
+		return copied
+	}
+	return value
+}
+
 // Template interpolation
 var templateRegex = regexp.MustCompile(`\$\{\{([^}]+)\}\}|\{\{([^}]+)\}\}`)
 
 func (c *Context) InterpolateString(input string) (string, error) {
-	return templateRegex.ReplaceAllStringFunc(input, func(match string) string {
-		var varName string
-		if strings.HasPrefix(match, "${{") {
-			// Extract variable name from ${{varname}}
-			varName = strings.TrimSpace(match[3 : len(match)-2])
-		} else {
-			// Extract variable name from {{varname}}
-			varName = strings.TrimSpace(match[2 : len(match)-2])
-		}
-
-		// Handle env.variable syntax
-		varName = strings.TrimPrefix(varName, "env.")
+	return c.interpolate(input, 0)
+}
 
-		// Handle dot notation for nested object access
-		value, exists := c.GetNested(varName)
+func (c *Context) interpolate(input string, depth int) (string, error) {
+	var firstErr error
+	result := templateRegex.ReplaceAllStringFunc(input, func(match string) string {
+		value, exists, err := c.resolveTemplate(templateName(match), depth)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return match
+		}
 		if !exists {
 			return match // Return original if variable not found
 		}
 
 		return fmt.Sprintf("%v", value)
-	}), nil
+	})
+	return result, firstErr
+}
+
+// templateName returns the trimmed content of a {{name}} or ${{name}} template
+func templateName(match string) string {
+	if strings.HasPrefix(match, "${{") {
+		return strings.TrimSpace(match[3 : len(match)-2])
+	}
+	return strings.TrimSpace(match[2 : len(match)-2])
+}
+
+// resolveTemplate returns the value of a template: a time function, a variable or an
+// expression over variables
+func (c *Context) resolveTemplate(varName string, depth int) (interface{}, bool, error) {
+	// Handle env.variable syntax
+	varName = strings.TrimPrefix(varName, "env.")
+
+	// Handle time functions such as now(+2h, RFC3339)
+	if value, handled, err := c.callTimeFunction(varName); handled {
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: %w", varName, err)
+		}
+		return value, true, nil
+	}
+
+	return c.resolve(varName, depth)
 }
 
 func (c *Context) InterpolateMap(input map[string]string) (map[string]string, error) {
@@ -243,10 +464,16 @@ func ExtractFromResponse(response map[string]interface{}, extractor string) (int
 		return extractJSONPath(response, strings.TrimPrefix(extractor, "json:"))
 	case strings.HasPrefix(extractor, "header:"):
 		return extractHeader(response, strings.TrimPrefix(extractor, "header:"))
+	case strings.HasPrefix(extractor, "cookie:"):
+		return extractCookie(response, strings.TrimPrefix(extractor, "cookie:"))
+	case extractor == "links":
+		return extractLinks(response)
 	case strings.HasPrefix(extractor, "status"):
 		return response["status_code"], nil
 	case strings.HasPrefix(extractor, "body"):
 		return response["body_text"], nil
+	case extractor == "charset":
+		return response["charset"], nil
 	default:
 		return nil, fmt.Errorf("unsupported extractor: %s", extractor)
 	}
@@ -281,6 +508,8 @@ func extractHeader(response map[string]interface{}, headerName string) (interfac
 }
 
 func getNestedValue(data interface{}, path string) (interface{}, error) {
+	// Accept JSONPath-style roots ("$.user.id")
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
 	parts := strings.Split(path, ".")
 	current := data
 
@@ -309,12 +538,17 @@ func getNestedValue(data interface{}, path string) (interface{}, error) {
 	return current, nil
 }
 
-// Built-in variable functions
+// AddBuiltins enables the time variables timestamp, timestamp_ms, iso_timestamp, date and time.
+// They are evaluated against the context clock on every use.
 func (c *Context) AddBuiltins() {
-	now := time.Now()
-	c.SetGlobal("timestamp", now.Unix())
-	c.SetGlobal("timestamp_ms", now.UnixMilli())
-	c.SetGlobal("iso_timestamp", now.Format(time.RFC3339))
-	c.SetGlobal("date", now.Format("2006-01-02"))
-	c.SetGlobal("time", now.Format("15:04:05"))
+	c.builtins = true
+}
+
+// NewUUID returns a random (version 4) UUID
+func NewUUID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
 }
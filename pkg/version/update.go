@@ -0,0 +1,273 @@
+package version
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultRepository is the GitHub repository releases are fetched from
+	DefaultRepository = "nulln0ne/fuego"
+	// DefaultAPIURL is the GitHub API; GitHub Enterprise serves it under https://<host>/api/v3
+	DefaultAPIURL = "https://api.github.com"
+	// ChecksumsAsset lists the SHA-256 of every binary of a release, as sha256sum writes it
+	ChecksumsAsset = "checksums.txt"
+)
+
+// Updater replaces the running binary with a release binary published on GitHub
+type Updater struct {
+	APIURL     string // default DefaultAPIURL
+	Repository string // owner/name, default DefaultRepository
+	Token      string // for private repositories, e.g. $GITHUB_TOKEN
+	Client     *http.Client
+}
+
+// Release is a published release
+type Release struct {
+	Tag    string  `json:"tag_name"`
+	Assets []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"url"` // API URL of the asset, which also serves private repositories
+}
+
+// AssetName is the name of the release binary for a platform, as `make release-build` names it
+func AssetName(goos, goarch string) string {
+	name := "fuego-" + goos + "-" + goarch
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// Release returns the release with the given tag, or the latest one when tag is empty
+func (u *Updater) Release(ctx context.Context, tag string) (*Release, error) {
+	path := "/releases/latest"
+	if tag != "" {
+		path = "/releases/tags/" + tag
+	}
+	body, err := u.get(ctx, u.apiURL()+"/repos/"+u.repository()+path, "application/vnd.github+json")
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	var release Release
+	if err := json.NewDecoder(body).Decode(&release); err != nil {
+		return nil, fmt.Errorf("failed to decode release: %w", err)
+	}
+	if release.Tag == "" {
+		return nil, fmt.Errorf("release has no tag")
+	}
+	return &release, nil
+}
+
+// Install downloads the release binary for the running platform and replaces executable with it.
+// The download is checked against the checksums of the release, which it must have.
+func (u *Updater) Install(ctx context.Context, release *Release, executable string) error {
+	name := AssetName(runtime.GOOS, runtime.GOARCH)
+	binary, checksums := release.asset(name), release.asset(ChecksumsAsset)
+	if binary == nil {
+		return fmt.Errorf("release %s has no binary %s", release.Tag, name)
+	}
+
+	if checksums == nil {
+		return fmt.Errorf("release %s has no %s to verify %s with", release.Tag, ChecksumsAsset, name)
+	}
+	sums, err := u.checksums(ctx, checksums)
+	if err != nil {
+		return err
+	}
+	expected := sums[name]
+	if expected == "" {
+		return fmt.Errorf("%s of release %s has no checksum for %s", ChecksumsAsset, release.Tag, name)
+	}
+
+	// The new binary is written next to the old one so the final rename stays on one file system
+	temp, err := os.CreateTemp(filepath.Dir(executable), ".fuego-update-*")
+	if err != nil {
+		return fmt.Errorf("failed to create the new binary: %w", err)
+	}
+	defer os.Remove(temp.Name())
+
+	body, err := u.get(ctx, binary.URL, "application/octet-stream")
+	if err != nil {
+		temp.Close()
+		return err
+	}
+	hash := sha256.New()
+	_, err = io.Copy(io.MultiWriter(temp, hash), body)
+	body.Close()
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", name, expected, actual)
+	}
+	if err := os.Chmod(temp.Name(), 0755); err != nil {
+		return err
+	}
+
+	// Windows cannot overwrite a running executable but can rename it
+	old := executable + ".old"
+	os.Remove(old)
+	if err := os.Rename(executable, old); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", executable, err)
+	}
+	if err := os.Rename(temp.Name(), executable); err != nil {
+		os.Rename(old, executable)
+		return fmt.Errorf("failed to replace %s: %w", executable, err)
+	}
+	os.Remove(old)
+	return nil
+}
+
+func (r *Release) asset(name string) *Asset {
+	for i := range r.Assets {
+		if r.Assets[i].Name == name {
+			return &r.Assets[i]
+		}
+	}
+	return nil
+}
+
+// checksums reads a sha256sum listing into file name -> hex digest; lines in any other form are
+// errors
+func (u *Updater) checksums(ctx context.Context, asset *Asset) (map[string]string, error) {
+	body, err := u.get(ctx, asset.URL, "application/octet-stream")
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(body)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if _, err := hex.DecodeString(fields[0]); err != nil || len(fields) != 2 {
+			return nil, fmt.Errorf("invalid %s: line %d is not a digest and a file name", ChecksumsAsset, line)
+		}
+		sums[strings.TrimPrefix(fields[1], "*")] = fields[0]
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", ChecksumsAsset, err)
+	}
+	return sums, nil
+}
+
+func (u *Updater) get(ctx context.Context, url, accept string) (io.ReadCloser, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Accept", accept)
+	request.Header.Set("User-Agent", "fuego/"+Get().Version)
+	if u.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+u.Token)
+	}
+
+	client := u.Client
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Minute}
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", url, err)
+	}
+	if response.StatusCode != http.StatusOK {
+		response.Body.Close()
+		return nil, fmt.Errorf("failed to fetch %s: %s", url, response.Status)
+	}
+	return response.Body, nil
+}
+
+func (u *Updater) apiURL() string {
+	if u.APIURL == "" {
+		return DefaultAPIURL
+	}
+	return strings.TrimSuffix(u.APIURL, "/")
+}
+
+func (u *Updater) repository() string {
+	if u.Repository == "" {
+		return DefaultRepository
+	}
+	return u.Repository
+}
+
+// IsNewer reports whether release version latest is newer than current. Versions that are not
+// dotted numbers, like dev builds, are always older.
+func IsNewer(current, latest string) bool {
+	currentParts, ok := parseVersion(current)
+	if !ok {
+		return true
+	}
+	latestParts, ok := parseVersion(latest)
+	if !ok {
+		return false
+	}
+	for i := 0; i < len(currentParts) || i < len(latestParts); i++ {
+		var c, l int
+		if i < len(currentParts) {
+			c = currentParts[i]
+		}
+		if i < len(latestParts) {
+			l = latestParts[i]
+		}
+		if c != l {
+			return l > c
+		}
+	}
+	return false
+}
+
+// parseVersion reads v1.2.3 as [1 2 3]; a pre-release or build suffix is ignored
+func parseVersion(version string) ([]int, bool) {
+	version = strings.TrimPrefix(version, "v")
+	if end := strings.IndexAny(version, "-+"); end >= 0 {
+		version = version[:end]
+	}
+	var parts []int
+	for _, field := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, false
+		}
+		parts = append(parts, n)
+	}
+	return parts, true
+}
+
+// PackageManager returns the package manager that installed executable, "brew" or "scoop", which
+// should update it instead of self-update
+func PackageManager(executable string) string {
+	path := filepath.ToSlash(executable)
+	switch {
+	case strings.Contains(path, "/Cellar/") || strings.Contains(path, "/homebrew/") || strings.Contains(path, "/linuxbrew/"):
+		return "brew"
+	case strings.Contains(strings.ToLower(path), "/scoop/apps/"):
+		return "scoop"
+	}
+	return ""
+}
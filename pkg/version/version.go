@@ -0,0 +1,86 @@
+// Package version reports the build of the fuego binary and updates it to a newer release
+package version
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+// Set at build time, e.g.
+//
+//	go build -ldflags "-X github.com/nulln0ne/fuego/pkg/version.Version=v1.4.0
+//	  -X github.com/nulln0ne/fuego/pkg/version.Commit=$(git rev-parse HEAD)
+//	  -X github.com/nulln0ne/fuego/pkg/version.Date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Builds without ldflags (go install) fall back to the module version and VCS stamp Go embeds.
+var (
+	Version = "dev"
+	Commit  = ""
+	Date    = ""
+)
+
+// Info describes the running build
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	Date      string `json:"date,omitempty"`
+	Modified  bool   `json:"modified,omitempty"` // built from a tree with uncommitted changes
+	GoVersion string `json:"go_version"`
+	Platform  string `json:"platform"` // GOOS/GOARCH
+}
+
+// Get returns the build information of the running binary
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		Date:      Date,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+	}
+
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+	if info.Version == "dev" && build.Main.Version != "" && build.Main.Version != "(devel)" {
+		info.Version = build.Main.Version
+	}
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			if info.Commit == "" {
+				info.Commit = setting.Value
+			}
+		case "vcs.time":
+			if info.Date == "" {
+				info.Date = setting.Value
+			}
+		case "vcs.modified":
+			info.Modified = setting.Value == "true"
+		}
+	}
+	return info
+}
+
+// String renders the build on one line, e.g. "fuego v1.4.0 (commit 1a2b3c4, built 2026-10-01T09:30:00Z, go1.24.6 linux/amd64)"
+func (i Info) String() string {
+	details := make([]string, 0, 3)
+	if i.Commit != "" {
+		commit := i.Commit
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+		if i.Modified {
+			commit += "-dirty"
+		}
+		details = append(details, "commit "+commit)
+	}
+	if i.Date != "" {
+		details = append(details, "built "+i.Date)
+	}
+	details = append(details, i.GoVersion+" "+i.Platform)
+	return fmt.Sprintf("fuego %s (%s)", i.Version, strings.Join(details, ", "))
+}
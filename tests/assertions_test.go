@@ -121,3 +121,38 @@ func TestVariableInterpolation(t *testing.T) {
 		t.Errorf("Expected assertion to pass, but it failed. Message: %s", result.Message)
 	}
 }
+
+func TestVariableAssertions(t *testing.T) {
+	varContext := variables.NewContext()
+	varContext.SetLocal("order_total", 42.5)
+	varContext.SetLocal("order", map[string]interface{}{
+		"items": []interface{}{map[string]interface{}{"sku": "A-1"}},
+	})
+
+	engine := assertions.NewEngine(varContext)
+
+	tests := []struct {
+		name      string
+		assertion scenario.Assertion
+		passed    bool
+	}{
+		{"captured value", scenario.Assertion{Type: "variable", Field: "order_total", Operator: "gt", Value: 0}, true},
+		{"nested value", scenario.Assertion{Type: "variable", Field: "order.items.0.sku", Operator: "eq", Value: "A-1"}, true},
+		{"wrong value", scenario.Assertion{Type: "variable", Field: "order_total", Operator: "lt", Value: 10}, false},
+		{"unset variable", scenario.Assertion{Type: "variable", Field: "missing", Operator: "eq", Value: 1}, false},
+		{"unset optional variable", scenario.Assertion{Type: "variable", Field: "missing", Optional: true}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Variable assertions do not need a response
+			results, err := engine.RunAssertions([]scenario.Assertion{tt.assertion}, nil)
+			if err != nil {
+				t.Fatalf("RunAssertions() error = %v", err)
+			}
+			if results[0].Passed != tt.passed {
+				t.Errorf("Passed = %v, want %v: %s", results[0].Passed, tt.passed, results[0].Message)
+			}
+		})
+	}
+}
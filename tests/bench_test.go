@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/bench"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func benchSamples(base time.Duration, count int) []time.Duration {
+	samples := make([]time.Duration, count)
+	for i := range samples {
+		// Small spread around the base so the distributions overlap only when bases are close
+		samples[i] = base + time.Duration(i%5)*time.Millisecond
+	}
+	return samples
+}
+
+func TestBenchCompareDetectsSignificantRegressions(t *testing.T) {
+	baseline := bench.Baseline{Steps: map[string][]time.Duration{
+		"Orders / List":   benchSamples(100*time.Millisecond, 20),
+		"Orders / Create": benchSamples(200*time.Millisecond, 20),
+		"Orders / Get":    benchSamples(50*time.Millisecond, 20),
+	}}
+	current := map[string][]time.Duration{
+		"Orders / List":   benchSamples(130*time.Millisecond, 20), // +30%
+		"Orders / Create": benchSamples(201*time.Millisecond, 20), // noise
+		"Orders / Get":    benchSamples(30*time.Millisecond, 20),  // faster
+		"Orders / Delete": benchSamples(10*time.Millisecond, 20),
+	}
+
+	comparisons := bench.Compare(baseline, current, 0.1, 0.05)
+	verdicts := make(map[string]string)
+	for _, c := range comparisons {
+		verdicts[c.Step] = c.Verdict
+	}
+	assert.Equal(t, map[string]string{
+		"Orders / List":   "regression",
+		"Orders / Create": "unchanged",
+		"Orders / Get":    "improvement",
+		"Orders / Delete": "new",
+	}, verdicts)
+
+	regressions := bench.Regressions(comparisons)
+	require.Len(t, regressions, 1)
+	assert.InDelta(t, 0.3, regressions[0].Change, 0.02)
+	assert.Less(t, regressions[0].PValue, 0.001)
+
+	// Too few samples are never significant
+	assert.Equal(t, 1.0, bench.MannWhitney(benchSamples(time.Second, 1), benchSamples(time.Millisecond, 10)))
+}
+
+func TestBenchBaselineRoundTrip(t *testing.T) {
+	report := &reporting.Report{Scenarios: []reporting.ScenarioResult{{
+		Scenario: &scenario.Scenario{Name: "Orders"},
+		Steps: []reporting.StepResult{
+			{Step: &scenario.Step{Name: "List"}, Status: "passed", Response: map[string]interface{}{"duration": 5 * time.Millisecond}},
+			{Step: &scenario.Step{Name: "List"}, Status: "passed", Response: map[string]interface{}{"duration": 7 * time.Millisecond}},
+			{Step: &scenario.Step{Name: "Skipped"}, Status: "skipped"},
+		},
+	}}}
+
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	require.NoError(t, bench.NewBaseline(report, 2).Save(path))
+
+	loaded, err := bench.LoadBaseline(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, loaded.Runs)
+	assert.Equal(t, map[string][]time.Duration{"Orders / List": {5 * time.Millisecond, 7 * time.Millisecond}}, loaded.Steps)
+}
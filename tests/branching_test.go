@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func resultNames(results []reporting.StepResult) []string {
+	names := make([]string, len(results))
+	for i, result := range results {
+		names[i] = result.Step.Name
+	}
+	return names
+}
+
+func TestStepBranches(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Branches",
+		Steps: []scenario.Step{
+			{
+				Name:  "Passes",
+				HTTP:  &scenario.HTTPStep{URL: server.URL + "/json"},
+				Check: map[string]interface{}{"status": 200},
+				OnSuccess: []scenario.Step{{
+					Name:    "Fetch user",
+					HTTP:    &scenario.HTTPStep{URL: server.URL + "/json"},
+					Capture: map[string]scenario.Capture{"user_id": {JSONPath: "user.id"}},
+				}},
+				OnFailure: []scenario.Step{{Name: "Not run on success", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}}},
+			},
+			{
+				Name:      "Fails",
+				HTTP:      &scenario.HTTPStep{URL: server.URL + "/json"},
+				Check:     map[string]interface{}{"status": 201},
+				OnFailure: []scenario.Step{{Name: "Diagnostics", HTTP: &scenario.HTTPStep{URL: server.URL + "/text"}}},
+				OnSuccess: []scenario.Step{{Name: "Not run on failure", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}}},
+			},
+			{
+				Name:      "Skipped",
+				Condition: "false",
+				HTTP:      &scenario.HTTPStep{URL: server.URL + "/json"},
+				OnSuccess: []scenario.Step{{Name: "Not run when skipped", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}}},
+			},
+			{
+				Name:  "Uses branch capture",
+				HTTP:  &scenario.HTTPStep{URL: server.URL + "/user/{{user_id}}"},
+				Check: map[string]interface{}{"status": 200},
+			},
+		},
+	}
+	require.NoError(t, sc.Validate())
+
+	report := runTestScenario(t, sc)
+	steps := report.Scenarios[0].Steps
+	assert.Equal(t, []string{"Passes", "Fetch user", "Fails", "Diagnostics", "Skipped", "Uses branch capture"}, resultNames(steps))
+	assert.Equal(t, "failed", steps[2].Status)
+	assert.Equal(t, "passed", steps[3].Status)
+	assert.Equal(t, "passed", steps[5].Status, steps[5].Error)
+}
+
+func TestGroupBranches(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Group branches",
+		Tests: map[string]*scenario.TestGroup{
+			"a failing": {
+				Steps: []scenario.Step{
+					{Name: "Fails", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}, Check: map[string]interface{}{"status": 500}},
+					{Name: "Not reached", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}},
+				},
+				OnFailure: []scenario.Step{{Name: "Collect logs", HTTP: &scenario.HTTPStep{URL: server.URL + "/text"}}},
+			},
+			"b passing": {
+				Steps:     []scenario.Step{{Name: "Passes", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}}},
+				OnSuccess: []scenario.Step{{Name: "Notify", HTTP: &scenario.HTTPStep{URL: server.URL + "/text"}}},
+				OnFailure: []scenario.Step{{Name: "Not run", HTTP: &scenario.HTTPStep{URL: server.URL + "/text"}}},
+			},
+		},
+	}
+	require.NoError(t, sc.Validate())
+
+	report := runTestScenario(t, sc)
+	result := report.Scenarios[0]
+	assert.Equal(t, "failed", result.Status)
+	assert.Equal(t, []string{"Fails", "Collect logs", "Passes", "Notify"}, resultNames(result.Steps))
+}
+
+func TestBranchStepsAreValidated(t *testing.T) {
+	sc := &scenario.Scenario{
+		Name: "Invalid branch",
+		Steps: []scenario.Step{{
+			Name:      "Step",
+			HTTP:      &scenario.HTTPStep{URL: "http://localhost"},
+			OnFailure: []scenario.Step{{HTTP: &scenario.HTTPStep{URL: "http://localhost"}}},
+		}},
+	}
+	err := sc.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "on_failure step 1")
+}
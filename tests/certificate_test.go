@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCertificateAssertions(t *testing.T) {
+	// The test certificate is issued by Acme Co for example.com, *.example.com, 127.0.0.1 and
+	// ::1 until 2084
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer plain.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Certificates",
+		Steps: []scenario.Step{
+			{
+				Name: "Valid",
+				HTTP: &scenario.HTTPStep{URL: server.URL},
+				Assertions: []scenario.Assertion{
+					{Type: "certificate", Field: "days_until_expiry", Operator: "gte", Value: 30},
+					{Type: "certificate", Field: "issuer", Operator: "contains", Value: "Acme Co"},
+					{Type: "certificate", Field: "sans", Operator: "contains", Value: "example.com"},
+					{Type: "certificate", Field: "covers", Value: "example.com"},
+					{Type: "certificate", Field: "covers", Value: "api.example.com"},
+					{Type: "certificate", Field: "covers", Value: "127.0.0.1"},
+				},
+			},
+			{
+				Name: "Not covered",
+				HTTP: &scenario.HTTPStep{URL: server.URL},
+				Assertions: []scenario.Assertion{
+					{Type: "certificate", Field: "covers", Value: "v1.api.example.com"},
+					{Type: "certificate", Field: "days_until_expiry", Operator: "gte", Value: 365 * 100},
+				},
+			},
+			{
+				Name:       "Plain HTTP",
+				HTTP:       &scenario.HTTPStep{URL: plain.URL},
+				Assertions: []scenario.Assertion{{Type: "certificate", Field: "issuer", Operator: "contains", Value: "Acme"}},
+			},
+		},
+	}
+
+	steps := runTestScenario(t, sc).Scenarios[0].Steps
+	assert.Equal(t, "passed", steps[0].Status, "%+v", steps[0].Assertions)
+
+	assert.Equal(t, "failed", steps[1].Status)
+	assert.False(t, steps[1].Assertions[0].Passed)
+	assert.Contains(t, steps[1].Assertions[0].Message, "does not cover v1.api.example.com")
+	assert.False(t, steps[1].Assertions[1].Passed)
+
+	assert.Equal(t, "failed", steps[2].Status)
+	assert.Contains(t, steps[2].Assertions[0].Message, "not received over TLS")
+}
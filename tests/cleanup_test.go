@@ -0,0 +1,115 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resourceServer creates numbered resources on POST and records the paths it is asked to DELETE
+func resourceServer() (*httptest.Server, func() []string) {
+	var (
+		mu      sync.Mutex
+		deleted []string
+		nextID  int32
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"id": %d}`, atomic.AddInt32(&nextID, 1))
+		case http.MethodDelete:
+			mu.Lock()
+			deleted = append(deleted, r.URL.Path)
+			mu.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), deleted...)
+	}
+}
+
+func createStep(server *httptest.Server, name, variable string) scenario.Step {
+	return scenario.Step{
+		Name:    name,
+		HTTP:    &scenario.HTTPStep{Method: "POST", URL: server.URL + "/things"},
+		Capture: map[string]scenario.Capture{variable: {JSONPath: "id"}},
+		Cleanup: &scenario.Step{
+			HTTP:  &scenario.HTTPStep{Method: "DELETE", URL: server.URL + "/things/{{" + variable + "}}"},
+			Check: map[string]interface{}{"status": 204},
+		},
+	}
+}
+
+func TestCleanupsRunInReverseOrder(t *testing.T) {
+	server, deleted := resourceServer()
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Cleanup",
+		Steps: []scenario.Step{
+			createStep(server, "Create first", "first"),
+			createStep(server, "Create second", "second"),
+			{Name: "Use", HTTP: &scenario.HTTPStep{Method: "GET", URL: server.URL + "/things/{{second}}"}, Check: map[string]interface{}{"status": 500}},
+		},
+	}
+	require.NoError(t, sc.Validate())
+
+	report := runTestScenario(t, sc)
+	result := report.Scenarios[0]
+	assert.Equal(t, "passed", result.Status, result.Error)
+	assert.Equal(t, []string{"/things/2", "/things/1"}, deleted())
+
+	require.Len(t, result.Steps, 5)
+	assert.Equal(t, "Clean up Create second", result.Steps[3].Step.Name)
+	assert.Equal(t, "Clean up Create first", result.Steps[4].Step.Name)
+}
+
+func TestCleanupsRunAfterFailure(t *testing.T) {
+	server, deleted := resourceServer()
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Cleanup after failure",
+		Steps: []scenario.Step{
+			createStep(server, "Create", "id"),
+			{Name: "Fail", HTTP: &scenario.HTTPStep{Method: "GET", URL: server.URL + "/things/{{id}}"}, Check: map[string]interface{}{"status": 200}},
+		},
+	}
+	require.NoError(t, sc.Validate())
+
+	report := runTestScenario(t, sc)
+	result := report.Scenarios[0]
+	assert.Equal(t, "failed", result.Status)
+	assert.Equal(t, []string{"/things/1"}, deleted())
+	assert.Equal(t, "passed", result.Steps[len(result.Steps)-1].Status)
+}
+
+func TestFailingCleanupFailsScenario(t *testing.T) {
+	server, _ := resourceServer()
+	defer server.Close()
+
+	step := createStep(server, "Create", "id")
+	step.Cleanup.Check = map[string]interface{}{"status": 200}
+	sc := &scenario.Scenario{Name: "Failing cleanup", Steps: []scenario.Step{step}}
+	require.NoError(t, sc.Validate())
+
+	report := runTestScenario(t, sc)
+	result := report.Scenarios[0]
+	assert.Equal(t, "failed", result.Status)
+	assert.True(t, strings.Contains(result.Error, "Clean up Create"), result.Error)
+}
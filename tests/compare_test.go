@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// profileServer serves a profile whose name is replaced by PUT; every response carries a new version
+func profileServer() *httptest.Server {
+	var (
+		mu      sync.Mutex
+		name    = "fuego"
+		version int32
+	)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodPut {
+			body, _ := io.ReadAll(r.Body)
+			name = string(body)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"name": %q, "version": %d}`, name, atomic.AddInt32(&version, 1))
+	}))
+}
+
+func TestCompareStoredResponses(t *testing.T) {
+	server := profileServer()
+	defer server.Close()
+
+	get := func(name string) scenario.Step {
+		return scenario.Step{Name: name, HTTP: &scenario.HTTPStep{URL: server.URL}}
+	}
+	before := get("Before")
+	before.StoreAs = "before"
+	again := get("Unchanged")
+	again.StoreAs = "unchanged"
+	again.Assertions = []scenario.Assertion{
+		{Type: "compare", Value: map[string]interface{}{"response": "before", "ignore": []interface{}{"version"}}},
+	}
+	after := get("After")
+	after.Assertions = []scenario.Assertion{
+		{Type: "compare", Operator: "ne", Value: "unchanged", Field: "name"},
+		{Type: "compare", Value: map[string]interface{}{"response": "before", "ignore": []interface{}{"version"}}},
+		{Type: "compare", Value: "missing"},
+	}
+
+	sc := &scenario.Scenario{
+		Name: "Compare",
+		Steps: []scenario.Step{
+			before,
+			again,
+			{Name: "Rename", HTTP: &scenario.HTTPStep{URL: server.URL, Method: "PUT", Body: "renamed"}},
+			after,
+		},
+	}
+	require.NoError(t, sc.Validate())
+
+	report := runTestScenario(t, sc)
+	steps := report.Scenarios[0].Steps
+	require.Len(t, steps, 4)
+	assert.Equal(t, "passed", steps[1].Status, steps[1].Error)
+
+	results := steps[3].Assertions
+	require.Len(t, results, 3)
+	assert.True(t, results[0].Passed, results[0].Message)
+	assert.False(t, results[1].Passed)
+	assert.Contains(t, results[1].Message, "name")
+	assert.NotContains(t, results[1].Message, "version")
+	assert.False(t, results[2].Passed)
+	assert.Contains(t, results[2].Message, "no response stored as missing")
+}
@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/openapi"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestContractCheckFindsDrift(t *testing.T) {
+	spec, err := openapi.Parse([]byte(ordersSpec))
+	require.NoError(t, err)
+
+	sc := &scenario.Scenario{
+		Name:       "Orders",
+		SourceFile: "orders.yaml",
+		Steps: []scenario.Step{
+			{Name: "List", Line: 4, HTTP: &scenario.HTTPStep{URL: "{{base_url}}/orders?limit=10", Query: map[string]string{"status": "open"}}, Check: map[string]interface{}{"status": 200}},
+			{Name: "Create", Line: 9, Type: "http", Request: scenario.Request{Method: "POST", URL: "https://api.example.com/orders"},
+				Assertions: []scenario.Assertion{{Type: "status", Value: 201}, {Type: "status", Operator: "gte", Value: 400}}},
+			{Name: "Delete", Line: 14, HTTP: &scenario.HTTPStep{Method: "DELETE", URL: "/orders/{{order_id}}"}, Check: map[string]interface{}{"status": 204}},
+			{Name: "Renamed filter", Line: 18, HTTP: &scenario.HTTPStep{URL: "/orders", Query: map[string]string{"limit": "1", "state": "paid"}}},
+			{Name: "Undocumented", Line: 22, HTTP: &scenario.HTTPStep{Method: "DELETE", URL: "/orders/:id"}, Check: map[string]interface{}{"status": 404}},
+			{Name: "Wrong method", Line: 26, HTTP: &scenario.HTTPStep{Method: "PUT", URL: "/orders/7"}},
+			{Name: "Removed", Line: 30, HTTP: &scenario.HTTPStep{URL: "/customers/{{id}}"},
+				OnFailure: []scenario.Step{{Name: "Retry", Line: 33, HTTP: &scenario.HTTPStep{URL: "/orders/7/items"}}}},
+		},
+	}
+
+	drifts := openapi.CheckContract(spec, []*scenario.Scenario{sc})
+	found := make(map[string]string)
+	for _, drift := range drifts {
+		assert.Equal(t, "orders.yaml", drift.File)
+		found[drift.Step] = drift.Kind
+	}
+	assert.Equal(t, map[string]string{
+		"Renamed filter": openapi.DriftUnknownParameter,
+		"Undocumented":   openapi.DriftUndocumentedCode,
+		"Wrong method":   openapi.DriftUnknownMethod,
+		"Removed":        openapi.DriftUnknownPath,
+		"Retry":          openapi.DriftUnknownPath,
+	}, found)
+	assert.Len(t, drifts, 5)
+	assert.Contains(t, drifts[0].String(), "orders.yaml:18: Orders / Renamed filter: query parameter state")
+}
+
+func TestContractCheckUsesServerBasePathAndRanges(t *testing.T) {
+	spec, err := openapi.Parse([]byte(`
+openapi: 3.1.0
+info: {title: Base, version: "1"}
+servers:
+  - url: https://api.example.com/v2
+paths:
+  /users/me:
+    get:
+      responses:
+        "200": {description: ok}
+        4XX: {description: client error}
+  /users/{id}:
+    get:
+      responses:
+        default: {description: any}
+`))
+	require.NoError(t, err)
+
+	sc := &scenario.Scenario{Name: "Users", Steps: []scenario.Step{
+		{Name: "Me", HTTP: &scenario.HTTPStep{URL: "https://api.example.com/v2/users/me"}, Check: map[string]interface{}{"status": 401}},
+		{Name: "Other", HTTP: &scenario.HTTPStep{URL: "{{api}}/v2/users/{{user_id}}"}, Check: map[string]interface{}{"status": 500}},
+		{Name: "Teapot", HTTP: &scenario.HTTPStep{URL: "/v2/users/me"}, Check: map[string]interface{}{"status": 503}},
+	}}
+
+	drifts := openapi.CheckContract(spec, []*scenario.Scenario{sc})
+	require.Len(t, drifts, 1)
+	assert.Equal(t, "Teapot", drifts[0].Step)
+	assert.Equal(t, openapi.DriftUndocumentedCode, drifts[0].Kind)
+}
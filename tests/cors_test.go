@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCORSPreflight(t *testing.T) {
+	var preflights []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodOptions {
+			t.Errorf("CORS steps must only send preflights, got %s", r.Method)
+			return
+		}
+		preflights = append(preflights, r.Header.Clone())
+		if r.Header.Get("Origin") == "https://app.example.com" {
+			w.Header().Set("Access-Control-Allow-Origin", "https://app.example.com")
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+		w.Header().Set("Access-Control-Allow-Methods", "GET, PUT")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, X-Request-Id")
+		w.Header().Set("Access-Control-Max-Age", "600")
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name:      "CORS",
+		Variables: map[string]interface{}{"app": "https://app.example.com"},
+		Steps: []scenario.Step{
+			{
+				Name: "Allowed",
+				HTTP: &scenario.HTTPStep{
+					Method:  "PUT",
+					URL:     server.URL + "/orders/1",
+					Headers: scenario.Params{"X-Request-Id": "42", "Accept": "application/json"},
+					JSON:    map[string]interface{}{"status": "paid"},
+					CORS:    &scenario.CORSPreflight{Origin: "{{app}}", Credentials: true, MaxAge: 300},
+				},
+				Check: map[string]interface{}{"status": 204},
+			},
+			{
+				Name: "Blocked",
+				HTTP: &scenario.HTTPStep{
+					Method: "DELETE",
+					URL:    server.URL + "/orders/1",
+					CORS:   &scenario.CORSPreflight{Origin: "https://evil.example.com", Headers: []string{"Authorization"}, MaxAge: 3600},
+				},
+			},
+		},
+	}
+
+	steps := runTestScenario(t, sc).Scenarios[0].Steps
+	assert.Equal(t, "passed", steps[0].Status, steps[0].Error)
+	assert.Equal(t, "https://app.example.com", preflights[0].Get("Origin"))
+	assert.Equal(t, "PUT", preflights[0].Get("Access-Control-Request-Method"))
+	assert.Equal(t, "content-type,x-request-id", preflights[0].Get("Access-Control-Request-Headers"))
+
+	assert.Equal(t, "failed", steps[1].Status)
+	assert.Contains(t, steps[1].Error, "no Access-Control-Allow-Origin")
+	assert.Contains(t, steps[1].Error, "method DELETE is not in Access-Control-Allow-Methods")
+	assert.Contains(t, steps[1].Error, "header Authorization is not in Access-Control-Allow-Headers")
+	assert.Contains(t, steps[1].Error, "Access-Control-Max-Age is 600, less than 3600")
+}
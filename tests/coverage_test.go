@@ -0,0 +1,95 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/execution"
+	"github.com/nulln0ne/fuego/pkg/openapi"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func runWithCoverage(t *testing.T, coverage *openapi.Coverage, sent bool, sc *scenario.Scenario) {
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "json", OutputFile: os.DevNull})
+	engine := execution.NewEngineWithOptions(&config.Config{}, reporter, execution.Options{
+		OnResponse: func(step, sentStep *scenario.Step, status int) {
+			if sent {
+				step = sentStep
+			}
+			coverage.Record(step.Request.Method, step.Request.URL, status)
+		},
+	})
+	require.NoError(t, engine.ExecuteScenarios([]*scenario.Scenario{sc}))
+}
+
+func TestCoverageAgainstSpec(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/orders/404" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	spec, err := openapi.Parse([]byte(ordersSpec))
+	require.NoError(t, err)
+	coverage := openapi.NewCoverage(spec)
+
+	runWithCoverage(t, coverage, true, &scenario.Scenario{
+		Name:      "Orders",
+		Variables: map[string]interface{}{"base": server.URL, "id": 7},
+		Steps: []scenario.Step{
+			{Name: "List", HTTP: &scenario.HTTPStep{URL: "{{base}}/orders?limit=5"}},
+			{Name: "Delete", HTTP: &scenario.HTTPStep{Method: "DELETE", URL: "{{base}}/orders/{{id}}"}},
+			{Name: "Delete missing", HTTP: &scenario.HTTPStep{Method: "DELETE", URL: "{{base}}/orders/404"}},
+			{Name: "Health", HTTP: &scenario.HTTPStep{URL: "{{base}}/health"}},
+		},
+	})
+
+	report := coverage.Report()
+	assert.True(t, report.Spec)
+	assert.Equal(t, 3, report.Total)
+	assert.Equal(t, 2, report.Covered)
+	assert.InDelta(t, 66.7, report.Percent, 0.1)
+	assert.Equal(t, []string{"createOrder"}, report.Untested)
+	assert.Equal(t, []string{"GET /health"}, report.Unmatched)
+
+	for _, operation := range report.Operations {
+		if operation.Path == "/orders/{id}" {
+			assert.Equal(t, 2, operation.Requests)
+			assert.Equal(t, []int{200, 404}, operation.Statuses)
+		}
+	}
+}
+
+func TestCoverageWithoutSpecGroupsWrittenURLs(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	coverage := openapi.NewCoverage(nil)
+	runWithCoverage(t, coverage, false, &scenario.Scenario{
+		Name:      "Users",
+		Variables: map[string]interface{}{"base_url": server.URL},
+		Steps: []scenario.Step{
+			{Name: "First", HTTP: &scenario.HTTPStep{URL: "{{base_url}}/users/{{timestamp_ms}}"}},
+			{Name: "Second", HTTP: &scenario.HTTPStep{URL: "{{base_url}}/users/{{timestamp_ms}}?expand=true"}},
+			{Name: "Create", HTTP: &scenario.HTTPStep{Method: "post", URL: "{{base_url}}/users"}},
+		},
+	})
+
+	report := coverage.Report()
+	assert.False(t, report.Spec)
+	require.Len(t, report.Operations, 2)
+	assert.Equal(t, openapi.OperationCoverage{Method: "POST", Path: "/users", Requests: 1, Statuses: []int{204}}, report.Operations[0])
+	assert.Equal(t, openapi.OperationCoverage{Method: "GET", Path: "/users/{{timestamp_ms}}", Requests: 2, Statuses: []int{204}}, report.Operations[1])
+	assert.Equal(t, 2, report.Covered)
+}
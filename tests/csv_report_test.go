@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/execution"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVReportHasOneRowPerStep(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	scenarios := []*scenario.Scenario{{
+		Name:   "Items",
+		Data:   itemsData(),
+		Before: &scenario.TestGroup{Steps: []scenario.Step{{Name: "Warm up", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}}}},
+		Tests: map[string]*scenario.TestGroup{
+			"items": {
+				ContinueOnFail: true,
+				DataDriven:     &scenario.DataDrivenConfig{Source: "items", Variable: "item"},
+				Steps: []scenario.Step{{
+					Name:  "Get item",
+					HTTP:  &scenario.HTTPStep{URL: server.URL + "/json"},
+					Check: map[string]interface{}{"status": "{{item.id == 2 ? 418 : 200}}"},
+				}},
+			},
+		},
+	}}
+
+	path := filepath.Join(t.TempDir(), "steps.csv")
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "csv", OutputFile: path})
+	assert.NoError(t, execution.NewEngine(&config.Config{}, reporter).ExecuteScenarios(scenarios))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 5)
+
+	assert.Equal(t, []string{"scenario", "group", "step", "status", "duration_ms", "status_code", "error"}, rows[0])
+	assert.Equal(t, []string{"Items", "before", "Warm up", "passed"}, rows[1][:4])
+	assert.Equal(t, []string{"Items", "items", "Get item (data 1)", "passed"}, rows[2][:4])
+	assert.Equal(t, []string{"Items", "items", "Get item (data 2)", "failed"}, rows[3][:4])
+	assert.Equal(t, "200", rows[3][5])
+	assert.Contains(t, rows[3][6], "418")
+	assert.Empty(t, rows[2][6])
+
+	duration, err := strconv.ParseFloat(rows[1][4], 64)
+	require.NoError(t, err)
+	assert.Greater(t, duration, 0.0)
+}
+
+func TestCSVReportFormatIsAvailableAsOutput(t *testing.T) {
+	output, err := reporting.ParseReportOutput("csv=steps.csv")
+	require.NoError(t, err)
+	assert.Equal(t, reporting.ReportOutput{Format: "csv", File: "steps.csv"}, output)
+}
+
+func TestCSVReportEscapesFormulas(t *testing.T) {
+	report := &reporting.Report{Scenarios: []reporting.ScenarioResult{{
+		Scenario: &scenario.Scenario{Name: "=Injected"},
+		Status:   "failed",
+		Steps: []reporting.StepResult{
+			{Step: &scenario.Step{Name: "@SUM(A1)"}, Status: "failed", Error: `=HYPERLINK("http://evil.example","click")`},
+			{Step: &scenario.Step{Name: "-1 day"}, Status: "failed", Error: "+cmd|' /C calc'!A0"},
+			{Step: &scenario.Step{Name: "Plain"}, Status: "passed", Response: map[string]interface{}{"status_code": 200}},
+		},
+	}}}
+
+	path := filepath.Join(t.TempDir(), "steps.csv")
+	require.NoError(t, reporting.WriteReport(report, reporting.ReportConfig{Format: "csv", OutputFile: path}))
+
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 4)
+
+	assert.Equal(t, "'=Injected", rows[1][0])
+	assert.Equal(t, "'@SUM(A1)", rows[1][2])
+	assert.Equal(t, `'=HYPERLINK("http://evil.example","click")`, rows[1][6])
+	assert.Equal(t, "'-1 day", rows[2][2])
+	assert.Equal(t, "'+cmd|' /C calc'!A0", rows[2][6])
+	assert.Equal(t, []string{"'=Injected", "", "Plain", "passed"}, rows[3][:4])
+	assert.Equal(t, "200", rows[3][5])
+}
@@ -1,11 +1,18 @@
 package tests
 
 import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/nulln0ne/fuego/pkg/data"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"gopkg.in/yaml.v3"
 )
 
 func TestCSVDataLoader(t *testing.T) {
@@ -226,3 +233,314 @@ func TestCSVValueParsing(t *testing.T) {
 		t.Errorf("Expected string_val=hello, got %v", firstRow["string_val"])
 	}
 }
+
+func TestGeneratedDataLoader(t *testing.T) {
+	loader := data.NewDataLoader(t.TempDir())
+	source := data.DataSource{
+		Type: "generated",
+		Rows: 50,
+		Seed: 42,
+		Fields: map[string]string{
+			"id":      "seq(1000, 10)",
+			"age":     "randomInt(18, 90)",
+			"country": "choice('DE', 'FR', 'US')",
+			"email":   "email()",
+			"login":   "'user' + row",
+			"ref":     "uuid()",
+		},
+	}
+
+	rows, err := loader.LoadData(source)
+	if err != nil {
+		t.Fatalf("Failed to generate data: %v", err)
+	}
+	if len(rows) != 50 {
+		t.Fatalf("Expected 50 rows, got %d", len(rows))
+	}
+
+	for i, row := range rows {
+		if row["id"] != 1000+i*10 {
+			t.Errorf("Row %d: expected id %d, got %v", i, 1000+i*10, row["id"])
+		}
+		if age := row["age"].(int); age < 18 || age > 90 {
+			t.Errorf("Row %d: age %d out of range", i, age)
+		}
+		if country := row["country"]; country != "DE" && country != "FR" && country != "US" {
+			t.Errorf("Row %d: unexpected country %v", i, country)
+		}
+		if row["login"] != fmt.Sprintf("user%d", i+1) {
+			t.Errorf("Row %d: unexpected login %v", i, row["login"])
+		}
+		if len(row["ref"].(string)) != 36 {
+			t.Errorf("Row %d: unexpected uuid %v", i, row["ref"])
+		}
+	}
+
+	again, err := loader.LoadData(source)
+	if err != nil {
+		t.Fatalf("Failed to generate data: %v", err)
+	}
+	if !reflect.DeepEqual(rows, again) {
+		t.Errorf("Expected the same rows for the same seed")
+	}
+
+	if _, err := loader.LoadData(data.DataSource{Type: "generated", Rows: 5, Fields: map[string]string{"x": "randomInt(5, 1)"}}); err == nil {
+		t.Errorf("Expected an error for an invalid range")
+	}
+	if _, err := loader.LoadData(data.DataSource{Type: "generated", Fields: map[string]string{"x": "row"}}); err == nil {
+		t.Errorf("Expected an error without rows")
+	}
+}
+
+func TestCSVLoaderOptions(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"semicolon.csv":  "id;name;password\n007;Bond;secret\n",
+		"headerless.tsv": "007\tBond\n008\tFairbanks\n",
+		"single.csv":     "id,note\n1,'it''s, quoted'\n2,'line\nbreak'\n",
+		"backslash.csv":  "id,note\n1,\"say \\\"hi\\\"\"\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	cases := []struct {
+		path     string
+		options  data.CSVOptions
+		expected []map[string]interface{}
+	}{
+		{
+			path:     "semicolon.csv",
+			options:  data.CSVOptions{Delimiter: ";", Exclude: []string{"password"}, Raw: true},
+			expected: []map[string]interface{}{{"id": "007", "name": "Bond"}},
+		},
+		{
+			path:     "semicolon.csv",
+			options:  data.CSVOptions{Delimiter: ";", Columns: []string{"id"}},
+			expected: []map[string]interface{}{{"id": 7}},
+		},
+		{
+			path:     "headerless.tsv",
+			options:  data.CSVOptions{Delimiter: "tab", Header: []string{"id", "name"}, Raw: true},
+			expected: []map[string]interface{}{{"id": "007", "name": "Bond"}, {"id": "008", "name": "Fairbanks"}},
+		},
+		{
+			path:     "headerless.tsv",
+			options:  data.CSVOptions{Delimiter: "tab", NoHeader: true, Columns: []string{"column2"}},
+			expected: []map[string]interface{}{{"column2": "Bond"}, {"column2": "Fairbanks"}},
+		},
+		{
+			path:     "single.csv",
+			options:  data.CSVOptions{Quote: "'"},
+			expected: []map[string]interface{}{{"id": 1, "note": "it's, quoted"}, {"id": 2, "note": "line\nbreak"}},
+		},
+		{
+			path:     "backslash.csv",
+			options:  data.CSVOptions{Escape: `\`},
+			expected: []map[string]interface{}{{"id": 1, "note": `say "hi"`}},
+		},
+	}
+
+	loader := data.NewDataLoader(tempDir)
+	for _, c := range cases {
+		options := c.options
+		result, err := loader.LoadData(data.DataSource{Type: "csv", Path: c.path, CSV: &options})
+		if err != nil {
+			t.Errorf("%s %+v: %v", c.path, c.options, err)
+		} else if !reflect.DeepEqual(result, c.expected) {
+			t.Errorf("%s %+v: expected %v, got %v", c.path, c.options, c.expected, result)
+		}
+	}
+
+	invalid := []data.CSVOptions{
+		{Delimiter: ";", Columns: []string{"email"}},
+		{Delimiter: ";;"},
+		{Quote: ";", Delimiter: ";"},
+	}
+	for _, options := range invalid {
+		options := options
+		if _, err := loader.LoadData(data.DataSource{Type: "csv", Path: "semicolon.csv", CSV: &options}); err == nil {
+			t.Errorf("Expected an error for %+v", options)
+		}
+	}
+}
+
+func TestWindowsDataFiles(t *testing.T) {
+	tempDir := t.TempDir()
+	bom := "\xef\xbb\xbf"
+	files := map[string]string{
+		"excel.csv":   bom + "id,note\r\n1,\"line\r\nbreak\"\r\n2,plain\r\n",
+		"single.csv":  bom + "id;note\r\n1;'line\r\nbreak'\r\n",
+		"users.jsonl": bom + "{\"id\": 1}\r\n{\"id\": 2}\r\n",
+		"users.json":  bom + "[{\"id\": 1}]\r\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	cases := []struct {
+		source   data.DataSource
+		expected []map[string]interface{}
+	}{
+		{
+			source:   data.DataSource{Type: "csv", Path: "excel.csv"},
+			expected: []map[string]interface{}{{"id": 1, "note": "line\nbreak"}, {"id": 2, "note": "plain"}},
+		},
+		{
+			source:   data.DataSource{Type: "csv", Path: "single.csv", CSV: &data.CSVOptions{Delimiter: ";", Quote: "'"}},
+			expected: []map[string]interface{}{{"id": 1, "note": "line\nbreak"}},
+		},
+		{
+			source:   data.DataSource{Type: "jsonl", Path: "users.jsonl"},
+			expected: []map[string]interface{}{{"id": float64(1)}, {"id": float64(2)}},
+		},
+		{
+			source:   data.DataSource{Type: "json", Path: "users.json"},
+			expected: []map[string]interface{}{{"id": float64(1)}},
+		},
+	}
+
+	loader := data.NewDataLoader(tempDir)
+	for _, c := range cases {
+		result, err := loader.LoadData(c.source)
+		if err != nil {
+			t.Errorf("%s: %v", c.source.Path, err)
+		} else if !reflect.DeepEqual(result, c.expected) {
+			t.Errorf("%s: expected %v, got %v", c.source.Path, c.expected, result)
+		}
+	}
+}
+
+func TestJSONLinesAndGzipDataLoader(t *testing.T) {
+	tempDir := t.TempDir()
+	lines := "{\"id\": 1, \"email\": \"ada@example.com\"}\n\n{\"id\": 2, \"email\": \"alan@example.com\"}\n"
+	csvContent := "id,email\n1,ada@example.com\n2,alan@example.com\n"
+
+	writeGzip := func(name, content string) {
+		var buffer bytes.Buffer
+		writer := gzip.NewWriter(&buffer)
+		writer.Write([]byte(content))
+		writer.Close()
+		if err := os.WriteFile(filepath.Join(tempDir, name), buffer.Bytes(), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(tempDir, "users.ndjson"), []byte(lines), 0644); err != nil {
+		t.Fatalf("Failed to create users.ndjson: %v", err)
+	}
+	writeGzip("users.jsonl.gz", lines)
+	writeGzip("export", lines)
+	writeGzip("users.csv.gz", csvContent)
+	writeGzip("users.json.gz", `[{"id": 1, "email": "ada@example.com"}, {"id": 2, "email": "alan@example.com"}]`)
+
+	expected := []map[string]interface{}{
+		{"id": float64(1), "email": "ada@example.com"},
+		{"id": float64(2), "email": "alan@example.com"},
+	}
+	sources := []data.DataSource{
+		{Type: "jsonl", Path: "users.ndjson"},
+		{Type: "json", Path: "users.ndjson"},
+		{Type: "json", Path: "users.jsonl.gz"},
+		{Type: "ndjson", Path: "export"},
+		{Type: "json", Path: "users.json.gz"},
+	}
+
+	loader := data.NewDataLoader(tempDir)
+	for _, source := range sources {
+		result, err := loader.LoadData(source)
+		if err != nil {
+			t.Errorf("%s %s: %v", source.Type, source.Path, err)
+		} else if !reflect.DeepEqual(result, expected) {
+			t.Errorf("%s %s: expected %v, got %v", source.Type, source.Path, expected, result)
+		}
+	}
+
+	result, err := loader.LoadData(data.DataSource{Type: "csv", Path: "users.csv.gz"})
+	if err != nil {
+		t.Fatalf("Failed to load gzipped CSV: %v", err)
+	}
+	if len(result) != 2 || result[1]["email"] != "alan@example.com" {
+		t.Errorf("Unexpected gzipped CSV rows %v", result)
+	}
+
+	if err := os.WriteFile(filepath.Join(tempDir, "broken.jsonl"), []byte("{\"id\": 1}\n[2]\n"), 0644); err != nil {
+		t.Fatalf("Failed to create broken.jsonl: %v", err)
+	}
+	if _, err := loader.LoadData(data.DataSource{Type: "jsonl", Path: "broken.jsonl"}); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Expected an error for line 2, got %v", err)
+	}
+}
+
+func TestDataSourceTransforms(t *testing.T) {
+	var join scenario.DataSource
+	if err := yaml.Unmarshal([]byte("join: {source: customers, on: customer_id, key: id, as: customer}"), &join); err != nil {
+		t.Fatalf("Failed to parse join: %v", err)
+	}
+	if join.Join == nil || join.Join.On != "customer_id" || join.Join.Key != "id" {
+		t.Fatalf("Unexpected join %+v", join.Join)
+	}
+
+	customers := data.DataSource{Type: "inline", Data: []interface{}{
+		map[string]interface{}{"id": 1, "name": "Ada", "tier": "gold"},
+		map[string]interface{}{"id": 2.0, "name": "Alan", "tier": "basic"},
+	}}
+	orders := []interface{}{
+		map[string]interface{}{"order": 10, "customer_id": 1, "total": 250},
+		map[string]interface{}{"order": 11, "customer_id": 2, "total": 40},
+		map[string]interface{}{"order": 12, "customer_id": 3, "total": 90},
+	}
+
+	loader := data.NewDataLoader(t.TempDir())
+	loaded, err := loader.LoadSources(map[string]data.DataSource{
+		"customers": customers,
+		"big_orders": {
+			Type:   "inline",
+			Data:   orders,
+			Join:   &data.Join{Source: "customers", On: "customer_id", Key: "id"},
+			Filter: "total > 50",
+			Select: []string{"order", "name"},
+			Rename: map[string]string{"name": "customer"},
+		},
+		"all_orders": {
+			Type: "inline",
+			Data: orders,
+			Join: &data.Join{Source: "customers", On: "customer_id", Key: "id", As: "customer", Type: "left"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Failed to load sources: %v", err)
+	}
+
+	expected := []map[string]interface{}{{"order": 10, "customer": "Ada"}}
+	if !reflect.DeepEqual(loaded["big_orders"], expected) {
+		t.Errorf("Expected %v, got %v", expected, loaded["big_orders"])
+	}
+	all := loaded["all_orders"]
+	if len(all) != 3 {
+		t.Fatalf("Expected 3 rows from the left join, got %v", all)
+	}
+	if customer, _ := all[1]["customer"].(map[string]interface{}); customer["name"] != "Alan" {
+		t.Errorf("Expected order 11 to be joined with Alan, got %v", all[1])
+	}
+	if _, joined := all[2]["customer"]; joined {
+		t.Errorf("Expected order 12 to have no customer, got %v", all[2])
+	}
+
+	invalid := map[string]map[string]data.DataSource{
+		"unknown source": {"orders": {Type: "inline", Data: orders, Join: &data.Join{Source: "missing", On: "id"}}},
+		"cycle": {
+			"a": {Type: "inline", Data: orders, Join: &data.Join{Source: "b", On: "order"}},
+			"b": {Type: "inline", Data: orders, Join: &data.Join{Source: "a", On: "order"}},
+		},
+		"bad filter": {"orders": {Type: "inline", Data: orders, Filter: "total >"}},
+	}
+	for name, sources := range invalid {
+		if _, err := loader.LoadSources(sources); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
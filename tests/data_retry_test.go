@@ -0,0 +1,138 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flakyItemServer fails item 2 on its first request and item 3 always
+func flakyItemServer() *httptest.Server {
+	var mu sync.Mutex
+	requests := make(map[string]int)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		count := requests[r.URL.Path]
+		mu.Unlock()
+
+		switch {
+		case r.URL.Path == "/items/3", r.URL.Path == "/items/2" && count == 1:
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+}
+
+func itemsData() map[string]scenario.DataSource {
+	return map[string]scenario.DataSource{"items": {Type: "inline", Data: []interface{}{
+		map[string]interface{}{"id": 1, "name": "one"},
+		map[string]interface{}{"id": 2, "name": "two"},
+		map[string]interface{}{"id": 3, "name": "three"},
+	}}}
+}
+
+func TestDataDrivenGroupRetriesFailedRows(t *testing.T) {
+	server := flakyItemServer()
+	defer server.Close()
+	failedRows := filepath.Join(t.TempDir(), "failed.csv")
+
+	sc := &scenario.Scenario{
+		Name: "Retried rows",
+		Data: itemsData(),
+		Tests: map[string]*scenario.TestGroup{
+			"items": {
+				ContinueOnFail: true,
+				DataDriven:     &scenario.DataDrivenConfig{Source: "items", Variable: "item", Retries: 2, FailedRows: failedRows},
+				Steps: []scenario.Step{{
+					Name:  "Get item",
+					HTTP:  &scenario.HTTPStep{URL: server.URL + "/items/{{item.id}}"},
+					Check: map[string]interface{}{"status": 200},
+				}},
+			},
+		},
+	}
+
+	report := runTestScenario(t, sc)
+	result := report.Scenarios[0]
+	require.Len(t, result.Steps, 3)
+	assert.Equal(t, "passed", result.Steps[0].Status)
+	assert.Equal(t, "passed", result.Steps[1].Status)
+	assert.Equal(t, "failed", result.Steps[2].Status)
+	assert.Contains(t, result.Warnings, "Test 'items' data item 2 passed on attempt 2")
+
+	content, err := os.ReadFile(failedRows)
+	require.NoError(t, err)
+	assert.Equal(t, "id,name\n3,three\n", string(content))
+}
+
+func TestDataDrivenGroupFailedRowsPathUsesScenarioVariables(t *testing.T) {
+	server := flakyItemServer()
+	defer server.Close()
+	dir := t.TempDir()
+
+	sc := &scenario.Scenario{
+		Name:      "Failed rows path",
+		Data:      itemsData(),
+		Variables: map[string]interface{}{"out": dir},
+		Tests: map[string]*scenario.TestGroup{
+			"items": {
+				ContinueOnFail: true,
+				DataDriven:     &scenario.DataDrivenConfig{Source: "items", Variable: "item", FailedRows: "{{out}}/failed.csv"},
+				Steps: []scenario.Step{{
+					Name:  "Get item",
+					HTTP:  &scenario.HTTPStep{URL: server.URL + "/items/{{item.id}}"},
+					Check: map[string]interface{}{"status": 200},
+				}},
+			},
+		},
+	}
+
+	report := runTestScenario(t, sc)
+	assert.Empty(t, report.Scenarios[0].Warnings)
+
+	content, err := os.ReadFile(filepath.Join(dir, "failed.csv"))
+	require.NoError(t, err)
+	assert.Equal(t, "id,name\n2,two\n3,three\n", string(content))
+}
+
+func TestDataDrivenStepWritesFailedRowsAsJSON(t *testing.T) {
+	server := flakyItemServer()
+	defer server.Close()
+	failedRows := filepath.Join(t.TempDir(), "rows", "failed.json")
+
+	sc := &scenario.Scenario{
+		Name: "Failed rows",
+		Data: itemsData(),
+		Steps: []scenario.Step{{
+			Name:       "Get item",
+			HTTP:       &scenario.HTTPStep{URL: server.URL + "/items/{{item.id}}"},
+			Check:      map[string]interface{}{"status": 200},
+			DataDriven: &scenario.DataDrivenConfig{Source: "items", Variable: "item", FailedRows: failedRows},
+		}},
+	}
+
+	report := runTestScenario(t, sc)
+	steps := report.Scenarios[0].Steps
+	require.Len(t, steps, 3)
+	assert.Equal(t, "failed", steps[1].Status)
+	assert.True(t, strings.HasSuffix(steps[2].Step.Name, "(data 3)"))
+
+	content, err := os.ReadFile(failedRows)
+	require.NoError(t, err)
+	var rows []map[string]interface{}
+	require.NoError(t, json.Unmarshal(content, &rows))
+	require.Len(t, rows, 2)
+	assert.Equal(t, "two", rows[0]["name"])
+	assert.Equal(t, "three", rows[1]["name"])
+}
@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/protocols"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serveOn answers every request on listener with its host and path
+func serveOn(listener net.Listener) *http.Server {
+	server := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "%s %s", r.Host, r.URL.RequestURI())
+	})}
+	go server.Serve(listener)
+	return server
+}
+
+func getStep(url string) *scenario.Step {
+	return &scenario.Step{Name: "Get", Type: "http", Request: scenario.Request{Method: "GET", URL: url}}
+}
+
+func TestUnixSocketRequests(t *testing.T) {
+	dir := t.TempDir()
+	var sockets []string
+	for _, name := range []string{"a.sock", "b.sock"} {
+		socket := filepath.Join(dir, name)
+		listener, err := net.Listen("unix", socket)
+		if err != nil {
+			t.Skipf("unix sockets unavailable: %v", err)
+		}
+		server := serveOn(listener)
+		defer server.Close()
+		sockets = append(sockets, socket)
+	}
+
+	client := protocols.NewHTTPClient(protocols.HTTPClientConfig{})
+	response, err := client.Execute(getStep("unix://" + sockets[0] + ":/v1/status?verbose=1"))
+	require.NoError(t, err)
+	assert.Equal(t, "localhost /v1/status?verbose=1", response.BodyText)
+
+	// A socket base URL joins relative step URLs after a colon
+	client = protocols.NewHTTPClient(protocols.HTTPClientConfig{BaseURL: "unix://" + sockets[1]})
+	response, err = client.Execute(getStep("/health"))
+	require.NoError(t, err)
+	assert.Equal(t, "localhost /health", response.BodyText)
+
+	assert.Equal(t, "unix:///run/app.sock:/health", protocols.JoinURL("unix:///run/app.sock", "health"))
+	assert.Equal(t, "http://api/v1/health", protocols.JoinURL("http://api/v1/", "/health"))
+	// The colon of a Windows drive letter is part of the socket path
+	assert.Equal(t, `unix://C:\run\app.sock:/health`, protocols.JoinURL(`unix://C:\run\app.sock`, "/health"))
+	assert.Equal(t, "unix://C:/run/app.sock:/v1/health", protocols.JoinURL("unix://C:/run/app.sock:/v1", "health"))
+}
+
+func TestIPVersionPreference(t *testing.T) {
+	listener, err := net.Listen("tcp6", "[::1]:0")
+	if err != nil {
+		t.Skipf("IPv6 loopback unavailable: %v", err)
+	}
+	server := serveOn(listener)
+	defer server.Close()
+	url := "http://" + listener.Addr().String() + "/"
+
+	response, err := protocols.NewHTTPClient(protocols.HTTPClientConfig{IPVersion: 6}).Execute(getStep(url))
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+
+	_, err = protocols.NewHTTPClient(protocols.HTTPClientConfig{IPVersion: 4}).Execute(getStep(url))
+	assert.Error(t, err)
+}
+
+func TestInvalidIPVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fuego.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("defaults:\n  ip_version: 5\n"), 0644))
+	_, err := config.LoadConfig(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ip_version")
+}
@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/diff"
+	"github.com/nulln0ne/fuego/pkg/execution"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompareIgnoresWildcardPaths(t *testing.T) {
+	a := map[string]interface{}{
+		"id":    1,
+		"items": []interface{}{map[string]interface{}{"name": "a", "updated_at": "t1"}},
+	}
+	b := map[string]interface{}{
+		"id":    2,
+		"items": []interface{}{map[string]interface{}{"name": "a", "updated_at": "t2"}},
+		"extra": true,
+	}
+
+	differences := diff.Compare(a, b, []string{"items.*.updated_at"})
+
+	require.Len(t, differences, 2)
+	assert.Equal(t, diff.Difference{Path: "extra", Kind: "added", B: true}, differences[0])
+	assert.Equal(t, "id", differences[1].Path)
+	assert.Equal(t, "changed", differences[1].Kind)
+}
+
+func TestDiffScenarioAcrossEnvironments(t *testing.T) {
+	newServer := func(version string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"version": %q, "user": {"id": 1, "seen_at": %q}}`, version, r.RemoteAddr)
+		}))
+	}
+	serverA := newServer("1.0")
+	defer serverA.Close()
+	serverB := newServer("1.1")
+	defer serverB.Close()
+
+	cfg := &config.Config{
+		Env: map[string]config.EnvConfig{
+			"a": {BaseURL: serverA.URL},
+			"b": {BaseURL: serverB.URL},
+		},
+	}
+
+	sc := &scenario.Scenario{
+		Name: "Parity",
+		Steps: []scenario.Step{
+			{Name: "Get info", Type: "http", Request: scenario.Request{Method: "GET", URL: "/info"}},
+			{Name: "Create", Type: "http", Request: scenario.Request{Method: "POST", URL: "/info"}},
+		},
+	}
+
+	reporter := reporting.NewReporter(reporting.ReportConfig{})
+	engineA := execution.NewEngine(cfg.MergeEnvironment("a"), reporter)
+	engineB := execution.NewEngine(cfg.MergeEnvironment("b"), reporter)
+
+	results, err := execution.DiffScenario(engineA, engineB, sc, []string{"user.seen_at"})
+	require.NoError(t, err)
+
+	// The POST step is never sent
+	require.Len(t, results, 1)
+	assert.Equal(t, 200, results[0].StatusA)
+	assert.False(t, results[0].Equal())
+	require.Len(t, results[0].Differences, 1)
+	assert.Equal(t, "version", results[0].Differences[0].Path)
+}
@@ -1,17 +1,27 @@
 package tests
 
 import (
+	"compress/gzip"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/nulln0ne/fuego/pkg/config"
 	"github.com/nulln0ne/fuego/pkg/execution"
+	"github.com/nulln0ne/fuego/pkg/protocols"
 	"github.com/nulln0ne/fuego/pkg/reporting"
 	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/nulln0ne/fuego/pkg/variables"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // setupTestServer creates a mock HTTP server for testing.
@@ -41,7 +51,7 @@ func runTestScenario(t *testing.T, sc *scenario.Scenario) *reporting.Report {
 	err := engine.ExecuteScenarios([]*scenario.Scenario{sc})
 	assert.NoError(t, err)
 
-	// Since the report is written to /dev/null, we need to access it from the reporter directly.
+	// Since the report is written to os.DevNull, we need to access it from the reporter directly.
 	// The reporter's GenerateReport method populates the report field.
 	return reporter.GetReport()
 }
@@ -187,3 +197,1096 @@ func TestRegexCapture(t *testing.T) {
 	finalVars := scenarioResult.Variables
 	assert.Equal(t, "abc-123", finalVars["session_id"])
 }
+
+func TestFailedStepIncludesCurlCommand(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Curl Reproduction Test",
+		Tests: map[string]*scenario.TestGroup{
+			"main": {
+				ContinueOnFail: true,
+				Steps: []scenario.Step{
+					{
+						Name: "Passing step",
+						HTTP: &scenario.HTTPStep{
+							Method: "GET",
+							URL:    server.URL + "/json",
+						},
+						Check: map[string]interface{}{"status": 200},
+					},
+					{
+						Name: "Failing step",
+						HTTP: &scenario.HTTPStep{
+							Method:  "POST",
+							URL:     server.URL + "/json",
+							Headers: map[string]string{"X-Trace": "it's"},
+							JSON:    map[string]interface{}{"name": "fuego"},
+						},
+						Check: map[string]interface{}{"status": 201},
+					},
+				},
+			},
+		},
+	}
+
+	report := runTestScenario(t, sc)
+
+	steps := report.Scenarios[0].Steps
+	assert.Len(t, steps, 2)
+	assert.Empty(t, steps[0].Curl)
+	assert.Contains(t, steps[1].Curl, "-X POST '"+server.URL+"/json'")
+	assert.Contains(t, steps[1].Curl, `-H 'X-Trace: it'\''s'`)
+	assert.Contains(t, steps[1].Curl, `--data-raw '{"name":"fuego"}'`)
+}
+
+func TestFailedStepCurlRedactsCredentials(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Curl redaction",
+		Steps: []scenario.Step{{
+			Name: "Failing step",
+			HTTP: &scenario.HTTPStep{
+				Method:  "POST",
+				URL:     server.URL + "/json?api_key=query-secret",
+				Headers: map[string]string{"Authorization": "Bearer header-secret", "X-Trace": "kept"},
+				JSON:    map[string]interface{}{"password": "body-secret", "name": "fuego"},
+			},
+			Check: map[string]interface{}{"status": 201},
+		}},
+	}
+
+	curl := runTestScenario(t, sc).Scenarios[0].Steps[0].Curl
+	require.NotEmpty(t, curl)
+	for _, secret := range []string{"header-secret", "query-secret", "body-secret"} {
+		assert.NotContains(t, curl, secret)
+	}
+	assert.Contains(t, curl, `-H 'Authorization: [REDACTED]'`)
+	assert.Contains(t, curl, `-H 'X-Trace: kept'`)
+	assert.Contains(t, curl, `"name":"fuego"`)
+}
+
+func TestExportCurl(t *testing.T) {
+	sc := &scenario.Scenario{
+		Name:      "Export Test",
+		Variables: map[string]interface{}{"base": "https://api.example.com"},
+		Tests: map[string]*scenario.TestGroup{
+			"users": {
+				Steps: []scenario.Step{
+					{
+						Name: "Get user",
+						HTTP: &scenario.HTTPStep{
+							Method: "GET",
+							URL:    "{{base}}/users/1",
+							Query:  map[string]string{"expand": "profile"},
+						},
+					},
+					{
+						Name: "Use capture",
+						HTTP: &scenario.HTTPStep{
+							Method: "DELETE",
+							URL:    "{{base}}/users/{{captured_id}}",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	engine := execution.NewEngine(&config.Config{}, reporting.NewReporter(reporting.ReportConfig{}))
+	commands, err := engine.ExportCurl(sc)
+	assert.NoError(t, err)
+	assert.Len(t, commands, 2)
+
+	assert.Equal(t, "users", commands[0].Group)
+	assert.Equal(t, "curl -k 'https://api.example.com/users/1?expand=profile'", commands[0].Command)
+	assert.Contains(t, commands[1].Command, "-X DELETE")
+	assert.Contains(t, commands[1].Command, "captured_id")
+}
+
+func TestSnapshotAssertion(t *testing.T) {
+	counter := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		counter++
+		w.Header().Set("Content-Type", "application/json")
+		name := "fuego"
+		if r.URL.Query().Get("rename") == "1" {
+			name = "changed"
+		}
+		fmt.Fprintf(w, `{"name": %q, "generated_at": %d}`, name, counter)
+	}))
+	defer server.Close()
+
+	snapshotDir := t.TempDir()
+	newScenario := func(query map[string]string) *scenario.Scenario {
+		return &scenario.Scenario{
+			Name: "Snapshot Test",
+			Tests: map[string]*scenario.TestGroup{
+				"main": {
+					Steps: []scenario.Step{
+						{
+							Name: "Get resource",
+							HTTP: &scenario.HTTPStep{Method: "GET", URL: server.URL, Query: query},
+							Check: map[string]interface{}{
+								"snapshot": map[string]interface{}{"ignore": []interface{}{"generated_at"}},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+	run := func(sc *scenario.Scenario, update bool) *reporting.Report {
+		reporter := reporting.NewReporter(reporting.ReportConfig{Format: "json", OutputFile: os.DevNull})
+		engine := execution.NewEngineWithOptions(&config.Config{}, reporter, execution.Options{
+			SnapshotDir:     snapshotDir,
+			UpdateSnapshots: update,
+		})
+		assert.NoError(t, engine.ExecuteScenarios([]*scenario.Scenario{sc}))
+		return reporter.GetReport()
+	}
+
+	// First run records the snapshot, second run matches despite the volatile field
+	report := run(newScenario(nil), false)
+	assert.Equal(t, "passed", report.Scenarios[0].Status)
+	assert.Contains(t, report.Scenarios[0].Steps[0].Assertions[0].Message, "snapshot created")
+	assert.FileExists(t, filepath.Join(snapshotDir, "Snapshot_Test", "Get_resource.snap"))
+
+	report = run(newScenario(nil), false)
+	assert.Equal(t, "passed", report.Scenarios[0].Status)
+
+	// A changed field fails until snapshots are updated
+	changed := newScenario(map[string]string{"rename": "1"})
+	report = run(changed, false)
+	assert.Equal(t, "failed", report.Scenarios[0].Status)
+	assert.Contains(t, report.Scenarios[0].Steps[0].Assertions[0].Message, "name: fuego != changed")
+
+	report = run(changed, true)
+	assert.Equal(t, "passed", report.Scenarios[0].Status)
+	report = run(changed, false)
+	assert.Equal(t, "passed", report.Scenarios[0].Status)
+}
+
+func TestStepResultsKeepTheirOwnStep(t *testing.T) {
+	sc := &scenario.Scenario{
+		Name: "Step Names",
+		Steps: []scenario.Step{
+			{Name: "first", Variables: map[string]interface{}{"a": 1}},
+			{Name: "second", Variables: map[string]interface{}{"b": 2}},
+		},
+	}
+
+	report := runTestScenario(t, sc)
+
+	steps := report.Scenarios[0].Steps
+	assert.Equal(t, "first", steps[0].Step.Name)
+	assert.Equal(t, "second", steps[1].Step.Name)
+}
+
+func TestLatencySLOAcrossDataRows(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(5 * time.Millisecond)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	newScenario := func(slo map[string]time.Duration) *scenario.Scenario {
+		return &scenario.Scenario{
+			Name: "Latency SLO",
+			Data: map[string]scenario.DataSource{
+				"orders": {Type: "inline", Data: []interface{}{
+					map[string]interface{}{"id": 1},
+					map[string]interface{}{"id": 2},
+					map[string]interface{}{"id": 3},
+				}},
+			},
+			Tests: map[string]*scenario.TestGroup{
+				"orders": {
+					DataDriven: &scenario.DataDrivenConfig{Source: "orders", Variable: "order"},
+					Steps: []scenario.Step{
+						{
+							Name:  "Create Order",
+							HTTP:  &scenario.HTTPStep{Method: "POST", URL: server.URL + "/orders/{{order.id}}"},
+							Check: map[string]interface{}{"status": 201},
+							SLO:   slo,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	report := runTestScenario(t, newScenario(map[string]time.Duration{"p95": 10 * time.Second}))
+	result := report.Scenarios[0]
+	assert.Equal(t, "passed", result.Status)
+	assert.Equal(t, "Create Order (data 2)", result.Steps[1].Step.Name)
+	assert.Equal(t, "Create Order", result.Steps[1].LogicalName())
+	assert.Len(t, result.Latency, 1)
+	assert.Equal(t, "Create Order", result.Latency[0].Step)
+	assert.Equal(t, 3, result.Latency[0].Count)
+	assert.GreaterOrEqual(t, result.Latency[0].Min, 5*time.Millisecond)
+
+	report = runTestScenario(t, newScenario(map[string]time.Duration{"p95": time.Millisecond}))
+	result = report.Scenarios[0]
+	assert.Equal(t, "failed", result.Status)
+	assert.Contains(t, result.Error, "Latency SLO for step 'Create Order' violated: p95")
+}
+
+func TestLatencyStatsPercentiles(t *testing.T) {
+	var samples []time.Duration
+	for i := 1; i <= 100; i++ {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+
+	stats := reporting.NewLatencyStats("step", samples)
+
+	assert.Equal(t, time.Millisecond, stats.Min)
+	assert.Equal(t, 100*time.Millisecond, stats.Max)
+	assert.Equal(t, 50*time.Millisecond, stats.P50)
+	assert.Equal(t, 95*time.Millisecond, stats.P95)
+	assert.Equal(t, 50500*time.Microsecond, stats.Avg)
+}
+
+func TestPercentileNearestRank(t *testing.T) {
+	var samples []time.Duration
+	for i := 1; i <= 20; i++ {
+		samples = append(samples, time.Duration(i)*time.Millisecond)
+	}
+
+	assert.Equal(t, 10*time.Millisecond, reporting.Percentile(samples, 50))
+	assert.Equal(t, 19*time.Millisecond, reporting.Percentile(samples, 95))
+	assert.Equal(t, 20*time.Millisecond, reporting.Percentile(samples, 99))
+	assert.Equal(t, time.Millisecond, reporting.Percentile(samples[:1], 95))
+}
+
+func TestRepeatReportsFlakySteps(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls%2 == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Flaky",
+		Steps: []scenario.Step{
+			{
+				Name:       "Sometimes fails",
+				Type:       "http",
+				Request:    scenario.Request{Method: "GET", URL: server.URL},
+				Assertions: []scenario.Assertion{{Type: "status", Value: 200}},
+			},
+		},
+	}
+
+	run := func(options execution.Options) *reporting.Report {
+		reporter := reporting.NewReporter(reporting.ReportConfig{Format: "json", OutputFile: os.DevNull})
+		engine := execution.NewEngineWithOptions(&config.Config{}, reporter, options)
+		assert.NoError(t, engine.ExecuteScenarios([]*scenario.Scenario{sc}))
+		return reporter.GetReport()
+	}
+
+	report := run(execution.Options{Repeat: 4})
+	assert.Len(t, report.Scenarios, 4)
+	assert.Equal(t, 4, report.Scenarios[3].Run)
+	assert.Len(t, report.Flakiness, 1)
+	assert.Equal(t, 2, report.Flakiness[0].Passed)
+	assert.Equal(t, 4, report.Flakiness[0].Runs)
+	assert.True(t, report.Flakiness[0].Flaky())
+
+	calls = 0
+	report = run(execution.Options{Repeat: 10, StopOnFailure: true})
+	assert.Len(t, report.Scenarios, 2)
+	assert.Equal(t, "failed", report.Scenarios[1].Status)
+}
+
+func TestKnownFailures(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Known failures",
+		Steps: []scenario.Step{
+			{
+				Name:         "Broken endpoint",
+				Type:         "http",
+				Request:      scenario.Request{Method: "GET", URL: server.URL + "/json"},
+				Assertions:   []scenario.Assertion{{Type: "status", Value: 500}},
+				KnownFailure: "JIRA-123",
+			},
+			{
+				Name:         "Already fixed",
+				Type:         "http",
+				Request:      scenario.Request{Method: "GET", URL: server.URL + "/json"},
+				Assertions:   []scenario.Assertion{{Type: "status", Value: 200}},
+				KnownFailure: "JIRA-124",
+			},
+		},
+		Tests: map[string]*scenario.TestGroup{
+			"fixed group": {
+				KnownFailure: "JIRA-125",
+				Steps: []scenario.Step{
+					{Name: "Works", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}, Check: map[string]interface{}{"status": 200}},
+				},
+			},
+		},
+	}
+
+	report := runTestScenario(t, sc)
+	result := report.Scenarios[0]
+
+	assert.Equal(t, "passed", result.Status)
+	assert.Equal(t, "expected_failure", result.Steps[0].Status)
+	assert.Equal(t, "JIRA-123", result.Steps[0].KnownFailure)
+	assert.Equal(t, "unexpected_pass", result.Steps[1].Status)
+	assert.Equal(t, "passed", result.Steps[2].Status)
+	assert.Len(t, result.Warnings, 1)
+	assert.Contains(t, result.Warnings[0], "JIRA-125")
+	assert.Equal(t, 1, report.Summary.ExpectedFailures)
+	assert.Equal(t, 1, report.Summary.UnexpectedPasses)
+}
+
+func TestRunSummary(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Summary",
+		Steps: []scenario.Step{
+			{
+				Name:       "Wrong status",
+				Type:       "http",
+				Request:    scenario.Request{Method: "GET", URL: server.URL + "/json"},
+				Assertions: []scenario.Assertion{{Type: "status", Value: 201}},
+			},
+			{
+				Name:    "Fast enough",
+				Type:    "http",
+				Request: scenario.Request{Method: "GET", URL: server.URL + "/json"},
+				SLO:     map[string]time.Duration{"max": time.Minute},
+			},
+		},
+	}
+
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "json", OutputFile: os.DevNull})
+	engine := execution.NewEngine(&config.Config{}, reporter)
+	assert.NoError(t, engine.ExecuteScenarios([]*scenario.Scenario{sc}))
+
+	path := filepath.Join(t.TempDir(), "summary.json")
+	assert.NoError(t, reporter.WriteSummary(path))
+
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	var summary reporting.RunSummary
+	assert.NoError(t, json.Unmarshal(content, &summary))
+	assert.False(t, summary.Passed)
+	assert.Equal(t, 1, summary.FailedScenarios)
+	assert.Equal(t, []string{"Summary / Wrong status"}, summary.FailedSteps)
+	assert.Len(t, summary.Thresholds, 1)
+	assert.Equal(t, "max", summary.Thresholds[0].Metric)
+	assert.True(t, summary.Thresholds[0].Passed)
+}
+
+type scriptedDebugger struct {
+	actions []execution.DebugAction
+	fixed   *scenario.Step
+	seen    []string
+}
+
+func (d *scriptedDebugger) AfterStep(step *scenario.Step, result reporting.StepResult, varContext *variables.Context) (execution.DebugAction, *scenario.Step) {
+	d.seen = append(d.seen, step.Name+":"+result.Status)
+	action := d.actions[0]
+	d.actions = d.actions[1:]
+	if action == execution.DebugRerun {
+		return action, d.fixed
+	}
+	return action, nil
+}
+
+func TestDebuggerRerunsAndAborts(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	broken := scenario.Step{Name: "Get", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}, Check: map[string]interface{}{"status": 500}}
+	fixed := broken
+	fixed.Check = map[string]interface{}{"status": 200}
+
+	sc := &scenario.Scenario{
+		Name: "Debugged",
+		Steps: []scenario.Step{
+			broken,
+			{Name: "Next", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}},
+			{Name: "Never", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}},
+		},
+	}
+
+	debugger := &scriptedDebugger{
+		actions: []execution.DebugAction{execution.DebugRerun, execution.DebugContinue, execution.DebugAbort},
+		fixed:   &fixed,
+	}
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "none"})
+	engine := execution.NewEngineWithOptions(&config.Config{}, reporter, execution.Options{Debugger: debugger})
+	assert.NoError(t, engine.ExecuteScenarios([]*scenario.Scenario{sc}))
+
+	assert.Equal(t, []string{"Get:failed", "Get:passed", "Next:passed"}, debugger.seen)
+	steps := reporter.GetReport().Scenarios[0].Steps
+	assert.Equal(t, "passed", steps[0].Status)
+	assert.Equal(t, "skipped", steps[2].Status)
+}
+
+// exclusiveDebugger aborts at its first step and records whether it was ever entered twice at once
+type exclusiveDebugger struct {
+	active     int32
+	overlapped bool
+	seen       []string
+}
+
+func (d *exclusiveDebugger) AfterStep(step *scenario.Step, result reporting.StepResult, varContext *variables.Context) (execution.DebugAction, *scenario.Step) {
+	if atomic.AddInt32(&d.active, 1) > 1 {
+		d.overlapped = true
+	}
+	defer atomic.AddInt32(&d.active, -1)
+
+	time.Sleep(20 * time.Millisecond)
+	d.seen = append(d.seen, step.Name)
+	return execution.DebugAbort, nil
+}
+
+func TestDebuggerWithParallelGroups(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	group := func(name string) *scenario.TestGroup {
+		return &scenario.TestGroup{Steps: []scenario.Step{
+			{Name: name + " first", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}},
+			{Name: name + " second", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}},
+		}}
+	}
+	sc := &scenario.Scenario{
+		Name:   "Debugged in parallel",
+		Config: &scenario.ScenarioConfig{Parallel: true},
+		Tests:  map[string]*scenario.TestGroup{"a": group("a"), "b": group("b"), "c": group("c")},
+	}
+
+	debugger := &exclusiveDebugger{}
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "none"})
+	engine := execution.NewEngineWithOptions(&config.Config{}, reporter, execution.Options{Debugger: debugger})
+	assert.NoError(t, engine.ExecuteScenarios([]*scenario.Scenario{sc}))
+
+	// The groups take turns at the debugger, and none asks it again once it aborted
+	assert.False(t, debugger.overlapped)
+	assert.Len(t, debugger.seen, 1)
+	steps := reporter.GetReport().Scenarios[0].Steps
+	require.Len(t, steps, 6)
+	for _, step := range steps {
+		if strings.HasSuffix(step.Step.Name, "second") {
+			assert.Equal(t, "skipped", step.Status, step.Step.Name)
+		}
+	}
+}
+
+func TestTraceDumpsRedactedWireData(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Traced",
+		Steps: []scenario.Step{
+			{
+				Name: "Login",
+				HTTP: &scenario.HTTPStep{
+					URL:     server.URL + "/json?api_key=abc123",
+					Method:  "POST",
+					Headers: map[string]string{"Authorization": "Bearer s3cret", "X-Request": "visible"},
+					JSON:    map[string]interface{}{"username": "alice", "password": "hunter2"},
+				},
+			},
+		},
+	}
+
+	var trace strings.Builder
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "none"})
+	engine := execution.NewEngineWithOptions(&config.Config{}, reporter, execution.Options{Trace: &trace})
+	assert.NoError(t, engine.ExecuteScenarios([]*scenario.Scenario{sc}))
+
+	output := trace.String()
+	assert.Contains(t, output, "=== Traced / Login ===")
+	assert.Contains(t, output, "> POST /json?api_key=[REDACTED] HTTP/1.1")
+	assert.Contains(t, output, "> X-Request: visible")
+	assert.Contains(t, output, "> Authorization: [REDACTED]")
+	assert.Contains(t, output, `"username":"alice"`)
+	assert.Contains(t, output, "< HTTP/1.1 200 OK")
+	for _, secret := range []string{"s3cret", "hunter2", "abc123"} {
+		assert.NotContains(t, output, secret)
+	}
+}
+
+func TestCompressedRequestsAreSignedAndTracedDecoded(t *testing.T) {
+	err := protocols.RegisterRequestMiddleware("sign-accept-encoding", protocols.RequestMiddlewareFunc(func(req *http.Request, body []byte) error {
+		req.Header.Set("X-Signed-Encoding", req.Header.Get("Accept-Encoding"))
+		return nil
+	}))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Signed-Encoding") != r.Header.Get("Accept-Encoding") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		writer := gzip.NewWriter(w)
+		writer.Write([]byte(`{"name":"widget"}`))
+		writer.Close()
+	}))
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Signed and compressed",
+		Steps: []scenario.Step{{
+			Name:  "Get",
+			HTTP:  &scenario.HTTPStep{URL: server.URL + "/widget", Middleware: []string{"sign-accept-encoding"}},
+			Check: map[string]interface{}{"status": 200},
+		}},
+	}
+
+	var trace strings.Builder
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "none"})
+	engine := execution.NewEngineWithOptions(&config.Config{}, reporter, execution.Options{Trace: &trace})
+	assert.NoError(t, engine.ExecuteScenarios([]*scenario.Scenario{sc}))
+
+	// Middleware saw the Accept-Encoding header that was sent
+	step := reporter.GetReport().Scenarios[0].Steps[0]
+	assert.Equal(t, "passed", step.Status, step.Error)
+	assert.Contains(t, trace.String(), "> X-Signed-Encoding: gzip")
+	assert.Contains(t, trace.String(), `< {"name":"widget"}`)
+}
+
+func TestMatrixExpansion(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name:   "Regions",
+		Matrix: scenario.Matrix{"region": {"eu", "us"}},
+		Tests: map[string]*scenario.TestGroup{
+			"versions": {
+				Matrix: scenario.Matrix{"version": {"v1", "v2"}},
+				Steps: []scenario.Step{
+					{Name: "Ping", HTTP: &scenario.HTTPStep{URL: server.URL + "/{{region}}/{{version}}"}},
+				},
+			},
+		},
+	}
+
+	report := runTestScenario(t, sc)
+	assert.Len(t, report.Scenarios, 2)
+	assert.Equal(t, "Regions [region=eu]", report.Scenarios[0].Scenario.Name)
+	assert.Equal(t, "Regions [region=us]", report.Scenarios[1].Scenario.Name)
+	assert.Equal(t, "Ping [version=v2]", report.Scenarios[0].Steps[1].Step.Name)
+	assert.Equal(t, []string{"/eu/v1", "/eu/v2", "/us/v1", "/us/v2"}, paths)
+	assert.Len(t, sc.Matrix.Expand(), 2)
+}
+
+func TestDataDrivenFilterAndSample(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	rows := []interface{}{}
+	for i := 1; i <= 10; i++ {
+		country := "DE"
+		if i%2 == 0 {
+			country = "FR"
+		}
+		rows = append(rows, map[string]interface{}{"id": i, "country": country})
+	}
+
+	run := func(dataDriven *scenario.DataDrivenConfig) []string {
+		paths = nil
+		sc := &scenario.Scenario{
+			Name:      "Users",
+			Variables: map[string]any{"min_id": 3},
+			Data:      map[string]scenario.DataSource{"users": {Type: "inline", Data: rows}},
+			Tests: map[string]*scenario.TestGroup{
+				"lookup": {
+					DataDriven: dataDriven,
+					Steps: []scenario.Step{
+						{Name: "Get user", HTTP: &scenario.HTTPStep{URL: server.URL + "/users/{{user.id}}"}},
+					},
+				},
+			},
+		}
+		report := runTestScenario(t, sc)
+		assert.Equal(t, "passed", report.Scenarios[0].Status)
+		return append([]string(nil), paths...)
+	}
+
+	filtered := run(&scenario.DataDrivenConfig{Source: "users", Variable: "user", Filter: "country == 'DE' && user.id >= min_id"})
+	assert.Equal(t, []string{"/users/3", "/users/5", "/users/7", "/users/9"}, filtered)
+
+	sampled := run(&scenario.DataDrivenConfig{Source: "users", Variable: "user", Sample: 3, Seed: 42})
+	assert.Len(t, sampled, 3)
+	assert.Equal(t, sampled, run(&scenario.DataDrivenConfig{Source: "users", Variable: "user", Sample: 3, Seed: 42}))
+	assert.IsIncreasing(t, userIDs(sampled))
+
+	shuffled := run(&scenario.DataDrivenConfig{Source: "users", Variable: "user", Shuffle: true, Seed: 7})
+	assert.Len(t, shuffled, 10)
+	assert.ElementsMatch(t, run(&scenario.DataDrivenConfig{Source: "users", Variable: "user"}), shuffled)
+}
+
+func userIDs(paths []string) []int {
+	ids := make([]int, 0, len(paths))
+	for _, path := range paths {
+		var id int
+		fmt.Sscanf(path, "/users/%d", &id)
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+func TestDataDrivenStepReportsEveryRow(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/items/2" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Items",
+		Data: map[string]scenario.DataSource{"items": {Type: "inline", Data: []interface{}{
+			map[string]interface{}{"id": 1},
+			map[string]interface{}{"id": 2},
+			map[string]interface{}{"id": 3},
+		}}},
+		Steps: []scenario.Step{
+			{
+				Name:       "Get item",
+				HTTP:       &scenario.HTTPStep{URL: server.URL + "/items/{{item.id}}"},
+				DataDriven: &scenario.DataDrivenConfig{Source: "items", Variable: "item"},
+				Check:      map[string]interface{}{"status": 200},
+			},
+		},
+	}
+
+	report := runTestScenario(t, sc)
+	steps := report.Scenarios[0].Steps
+	if assert.Len(t, steps, 3) {
+		assert.Equal(t, []string{"passed", "failed", "passed"}, []string{steps[0].Status, steps[1].Status, steps[2].Status})
+		assert.Equal(t, "Get item (data 2)", steps[1].Step.Name)
+		assert.Equal(t, 2, steps[1].Iteration)
+	}
+	assert.Equal(t, "failed", report.Scenarios[0].Status)
+}
+
+func TestStepResultsRecordChangedVariables(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name:      "Variable changes",
+		Variables: map[string]any{"base": server.URL, "page": 1},
+		Steps: []scenario.Step{
+			{Name: "Capture", HTTP: &scenario.HTTPStep{URL: "{{base}}/json"}, Capture: map[string]scenario.Capture{"user_id": {JSONPath: "user.id"}}},
+			{Name: "Set", Variables: map[string]any{"page": 2, "size": 10}},
+			{Name: "Recapture", HTTP: &scenario.HTTPStep{URL: "{{base}}/json"}, Capture: map[string]scenario.Capture{"user_id": {JSONPath: "user.id"}, "name": {JSONPath: "user.name"}}},
+		},
+	}
+
+	report := runTestScenario(t, sc)
+	steps := report.Scenarios[0].Steps
+	require.Len(t, steps, 3)
+	assert.Equal(t, map[string]interface{}{"user_id": float64(123)}, steps[0].Variables)
+	assert.Equal(t, map[string]interface{}{"page": 2, "size": 10}, steps[1].Variables)
+	// user_id was captured again with the same value, so only name is new
+	assert.Equal(t, map[string]interface{}{"name": "fuego"}, steps[2].Variables)
+}
+
+func TestScenarioTmpDir(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	run := func(keep bool) []reporting.ScenarioResult {
+		sc := &scenario.Scenario{
+			Name: "Downloads",
+			Steps: []scenario.Step{
+				{Name: "Download", HTTP: &scenario.HTTPStep{URL: server.URL + "/text"}, SaveResponse: "saved/greeting.txt"},
+				{Name: "Where", Variables: map[string]any{"location": "{{tmpdir}}/saved"}},
+			},
+		}
+		reporter := reporting.NewReporter(reporting.ReportConfig{Format: "none"})
+		engine := execution.NewEngineWithOptions(&config.Config{}, reporter, execution.Options{Repeat: 2, KeepTmpDirs: keep})
+		require.NoError(t, engine.ExecuteScenarios([]*scenario.Scenario{sc}))
+		return reporter.GetReport().Scenarios
+	}
+
+	results := run(true)
+	require.Len(t, results, 2)
+	var dirs []string
+	for _, result := range results {
+		assert.Equal(t, "passed", result.Status)
+		dir, _ := result.Variables["tmpdir"].(string)
+		require.NotEmpty(t, dir)
+		defer os.RemoveAll(dir)
+		dirs = append(dirs, dir)
+
+		// Relative save_response paths are written under the scenario's directory
+		require.Len(t, result.Steps[0].Artifacts, 1)
+		assert.Equal(t, filepath.Join(dir, "saved", "greeting.txt"), result.Steps[0].Artifacts[0].Path)
+		saved, err := os.ReadFile(result.Steps[0].Artifacts[0].Path)
+		require.NoError(t, err)
+		assert.Contains(t, string(saved), "Hello, Fuego!")
+	}
+	assert.NotEqual(t, dirs[0], dirs[1], "every run gets its own directory")
+
+	// Without KeepTmpDirs the directory is removed when the scenario ends, and the saved response
+	// is not listed as an artifact the reports would link to
+	results = run(false)
+	dir, _ := results[0].Variables["tmpdir"].(string)
+	require.NotEmpty(t, dir)
+	_, err := os.Stat(dir)
+	assert.True(t, os.IsNotExist(err))
+	assert.Equal(t, "passed", results[0].Steps[0].Status)
+	assert.Empty(t, results[0].Steps[0].Artifacts)
+}
+
+func TestResponseArtifacts(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	dir := t.TempDir()
+	sc := &scenario.Scenario{
+		Name: "Artifacts",
+		Steps: []scenario.Step{
+			{
+				Name:         "Download",
+				HTTP:         &scenario.HTTPStep{URL: server.URL + "/text"},
+				SaveResponse: filepath.Join(dir, "saved", "{{file_name}}.txt"),
+				Variables:    map[string]any{"file_name": "greeting"},
+			},
+			{Name: "Broken user", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}, Check: map[string]interface{}{"status": 500}},
+		},
+	}
+
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "none"})
+	engine := execution.NewEngineWithOptions(&config.Config{}, reporter, execution.Options{ArtifactsDir: filepath.Join(dir, "failures")})
+	assert.NoError(t, engine.ExecuteScenarios([]*scenario.Scenario{sc}))
+
+	steps := reporter.GetReport().Scenarios[0].Steps
+	saved, err := os.ReadFile(filepath.Join(dir, "saved", "greeting.txt"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(saved), "Hello, Fuego!")
+	if assert.Len(t, steps[0].Artifacts, 1) {
+		assert.Equal(t, "response", steps[0].Artifacts[0].Name)
+	}
+
+	if assert.Len(t, steps[1].Artifacts, 1) {
+		failing := steps[1].Artifacts[0]
+		assert.Equal(t, filepath.Join(dir, "failures", "Artifacts-Broken_user.json"), failing.Path)
+		body, err := os.ReadFile(failing.Path)
+		assert.NoError(t, err)
+		assert.Contains(t, string(body), `"fuego"`)
+	}
+}
+
+func TestHeaderAndQueryDefaults(t *testing.T) {
+	var mu sync.Mutex
+	received := map[string]*http.Request{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		received[r.URL.Path] = r
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "defaults.yaml")
+	content := `name: Defaults
+config:
+  http:
+    headers:
+      X-Tenant: acme
+    query:
+      locale: en
+steps:
+  - name: Inherits
+    http:
+      url: ` + server.URL + `/inherits
+  - name: Overrides
+    http:
+      url: ` + server.URL + `/overrides?page=2
+      headers:
+        x-api-version: null
+        X-Tenant: other
+      query:
+        api_key: null
+        locale: de
+`
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	sc, err := scenario.LoadScenario(path)
+	assert.NoError(t, err)
+
+	cfg := &config.Config{Global: config.GlobalConfig{
+		Headers: map[string]string{"X-Api-Version": "2"},
+		Query:   map[string]string{"api_key": "secret"},
+	}}
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "none"})
+	engine := execution.NewEngine(cfg, reporter)
+	assert.NoError(t, engine.ExecuteScenarios([]*scenario.Scenario{sc}))
+	assert.Equal(t, "passed", reporter.GetReport().Scenarios[0].Status)
+
+	inherits := received["/inherits"]
+	assert.Equal(t, "2", inherits.Header.Get("X-Api-Version"))
+	assert.Equal(t, "acme", inherits.Header.Get("X-Tenant"))
+	assert.Equal(t, "secret", inherits.URL.Query().Get("api_key"))
+	assert.Equal(t, "en", inherits.URL.Query().Get("locale"))
+
+	overrides := received["/overrides"]
+	assert.Empty(t, overrides.Header.Values("X-Api-Version"))
+	assert.Equal(t, []string{"other"}, overrides.Header.Values("X-Tenant"))
+	assert.NotContains(t, overrides.URL.Query(), "api_key")
+	assert.Equal(t, []string{"de"}, overrides.URL.Query()["locale"])
+	assert.Equal(t, "2", overrides.URL.Query().Get("page"))
+}
+
+func TestCorrelationIDInjection(t *testing.T) {
+	var mu sync.Mutex
+	var ids []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ids = append(ids, r.Header.Get("X-Correlation-ID"))
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	run := func(scope string) *reporting.Report {
+		ids = nil
+		cfg := &config.Config{Global: config.GlobalConfig{CorrelationID: config.CorrelationConfig{
+			Enabled: true, Header: "X-Correlation-ID", Scope: scope,
+		}}}
+		sc := &scenario.Scenario{
+			Name: "Correlated",
+			Steps: []scenario.Step{
+				{Name: "First", HTTP: &scenario.HTTPStep{URL: server.URL + "/a?id={{correlation_id}}"}},
+				{Name: "Second", HTTP: &scenario.HTTPStep{URL: server.URL + "/b"}},
+				{Name: "Own ID", HTTP: &scenario.HTTPStep{URL: server.URL + "/c", Headers: scenario.Params{"x-correlation-id": "fixed"}}},
+			},
+		}
+		reporter := reporting.NewReporter(reporting.ReportConfig{Format: "none"})
+		assert.NoError(t, execution.NewEngine(cfg, reporter).ExecuteScenarios([]*scenario.Scenario{sc}))
+		return reporter.GetReport()
+	}
+
+	steps := run("step").Scenarios[0].Steps
+	assert.Len(t, ids, 3)
+	assert.Len(t, ids[0], 36)
+	assert.NotEqual(t, ids[0], ids[1])
+	assert.Equal(t, "fixed", ids[2])
+	assert.Equal(t, ids[0], steps[0].CorrelationID)
+	assert.Equal(t, "fixed", steps[2].CorrelationID)
+
+	run("scenario")
+	assert.Equal(t, ids[0], ids[1])
+}
+
+func TestRunSeedReproducesRandomData(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	run := func(seed int64) ([]string, *reporting.Report) {
+		paths = nil
+		sc := &scenario.Scenario{
+			Name: "Seeded",
+			Data: map[string]scenario.DataSource{"users": {
+				Type:   "generated",
+				Rows:   20,
+				Fields: map[string]string{"id": "randomInt(1, 100000)"},
+			}},
+			Tests: map[string]*scenario.TestGroup{
+				"lookup": {
+					DataDriven: &scenario.DataDrivenConfig{Source: "users", Variable: "user", Sample: 5, Shuffle: true},
+					Steps: []scenario.Step{
+						{Name: "Get user", HTTP: &scenario.HTTPStep{URL: server.URL + "/users/{{user.id}}"}},
+					},
+				},
+			},
+		}
+
+		reporter := reporting.NewReporter(reporting.ReportConfig{Format: "json", OutputFile: os.DevNull})
+		engine := execution.NewEngineWithOptions(&config.Config{}, reporter, execution.Options{Seed: seed})
+		assert.NoError(t, engine.ExecuteScenarios([]*scenario.Scenario{sc}))
+		return append([]string(nil), paths...), reporter.GetReport()
+	}
+
+	first, report := run(1234)
+	assert.Len(t, first, 5)
+	assert.Equal(t, int64(1234), report.Seed)
+
+	second, _ := run(1234)
+	assert.Equal(t, first, second)
+
+	other, _ := run(99)
+	assert.NotEqual(t, first, other)
+
+	_, unseeded := run(0)
+	assert.NotZero(t, unseeded.Seed, "a run without --seed reports the seed it picked")
+}
+
+func TestResponseCharsetDecoding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/latin1":
+			w.Header().Set("Content-Type", "text/plain; charset=ISO-8859-1")
+			w.Write([]byte("Gr\xfc\xdfe aus K\xf6ln"))
+		case "/utf16":
+			// {"city":"Köln"} in UTF-16 with a byte order mark and no declared charset
+			w.Header().Set("Content-Type", "application/json")
+			body := []byte{0xFF, 0xFE}
+			for _, r := range `{"city":"Köln"}` {
+				body = append(body, byte(r), byte(r>>8))
+			}
+			w.Write(body)
+		case "/city":
+			if r.URL.Query().Get("name") != "Köln" {
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		}
+	}))
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Charsets",
+		Steps: []scenario.Step{
+			{
+				Name:  "Latin-1",
+				HTTP:  &scenario.HTTPStep{URL: server.URL + "/latin1"},
+				Check: map[string]interface{}{"charset": "iso-8859-1", "body": "Grüße aus Köln"},
+			},
+			{
+				Name:    "UTF-16",
+				HTTP:    &scenario.HTTPStep{URL: server.URL + "/utf16"},
+				Capture: map[string]scenario.Capture{"city": {JSONPath: "city"}},
+				Check:   map[string]interface{}{"charset": "utf-16le"},
+			},
+			{
+				Name:  "Use capture",
+				HTTP:  &scenario.HTTPStep{URL: server.URL + "/city", Query: map[string]string{"name": "{{city}}"}},
+				Check: map[string]interface{}{"status": 200},
+			},
+		},
+	}
+
+	report := runTestScenario(t, sc)
+	assert.Len(t, report.Scenarios[0].Steps, 3)
+	for _, step := range report.Scenarios[0].Steps {
+		assert.Equal(t, "passed", step.Status, "%s: %s", step.Step.Name, step.Error)
+	}
+}
+
+func TestAssertionOnlyStepChecksVariables(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Variable checks",
+		Steps: []scenario.Step{
+			{
+				Name:    "Capture",
+				HTTP:    &scenario.HTTPStep{URL: server.URL + "/json"},
+				Capture: map[string]scenario.Capture{"user_id": {JSONPath: "user.id"}},
+			},
+			{
+				Name: "Verify",
+				Assertions: []scenario.Assertion{
+					{Type: "variable", Field: "user_id", Operator: "eq", Value: 123},
+					{Type: "variable", Field: "user_id", Operator: "gt", Value: 1000},
+				},
+			},
+		},
+	}
+	assert.NoError(t, sc.Validate())
+
+	report := runTestScenario(t, sc)
+	verify := report.Scenarios[0].Steps[1]
+	assert.Equal(t, "failed", verify.Status)
+	if assert.Len(t, verify.Assertions, 2) {
+		assert.True(t, verify.Assertions[0].Passed, verify.Assertions[0].Message)
+		assert.False(t, verify.Assertions[1].Passed)
+	}
+}
+
+func TestCompressionAssertions(t *testing.T) {
+	payload := strings.Repeat(`{"id":1,"name":"widget"},`, 200)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gzip" && strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Header().Set("Content-Encoding", "gzip")
+			writer := gzip.NewWriter(w)
+			writer.Write([]byte(payload))
+			writer.Close()
+			return
+		}
+		w.Write([]byte(payload))
+	}))
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Compression",
+		Steps: []scenario.Step{
+			{
+				Name: "Compressed",
+				HTTP: &scenario.HTTPStep{URL: server.URL + "/gzip"},
+				Assertions: []scenario.Assertion{
+					{Type: "content_encoding", Operator: "eq", Value: "gzip"},
+					{Type: "compression_ratio", Operator: "gte", Value: 10},
+					{Type: "size", Operator: "eq", Value: len(payload)},
+					{Type: "body", Operator: "starts_with", Value: `{"id":1`},
+				},
+			},
+			{
+				Name: "Plain",
+				HTTP: &scenario.HTTPStep{URL: server.URL + "/plain"},
+				Assertions: []scenario.Assertion{
+					{Type: "content_encoding", Operator: "eq", Value: "gzip"},
+					{Type: "compression_ratio", Operator: "eq", Value: 1},
+				},
+			},
+		},
+	}
+
+	steps := runTestScenario(t, sc).Scenarios[0].Steps
+	assert.Equal(t, "passed", steps[0].Status, steps[0].Error)
+	assert.Equal(t, "failed", steps[1].Status)
+	assert.False(t, steps[1].Assertions[0].Passed)
+	assert.True(t, steps[1].Assertions[1].Passed)
+}
@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/expr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpressions(t *testing.T) {
+	env := expr.Env{Resolve: expr.MapResolver(map[string]interface{}{
+		"age":     21,
+		"country": "DE",
+		"name":    "Ada Lovelace",
+		"tags":    []interface{}{"admin", "beta"},
+		"user":    map[string]interface{}{"address": map[string]interface{}{"city": "Berlin"}},
+		"limit":   "100",
+	})}
+
+	cases := map[string]interface{}{
+		"age >= 18 && country in ['DE', 'FR']":             true,
+		"not (age < 18) and country != 'US'":               true,
+		"'beta' in tags":                                   true,
+		"'root' not in tags":                               true,
+		"user.address.city == 'Berlin'":                    true,
+		"tags.0":                                           "admin",
+		"missing == null":                                  true,
+		"1 + 2 * 3":                                        7,
+		"(1 + 2) * 3 - 10 / 4":                             6.5,
+		"limit > 99":                                       true,
+		"'id-' + age":                                      "id-21",
+		"len(tags) == 2 && upper(country)":                 true,
+		"startsWith(lower(name), 'ada')":                   true,
+		"matches(name, '^Ada [A-Z]')":                      true,
+		"max(age, 30, 5)":                                  30,
+		"-age % 4":                                         -1,
+		"age >= 18 ? 'adult' : 'minor'":                    "adult",
+		"age < 13 ? 'child' : age < 18 ? 'teen' : 'adult'": "adult",
+		"missing ? missing.id : 'none'":                    "none",
+		"[age > 30 ? 1 : 2, 3]":                            []interface{}{2, 3},
+	}
+
+	for source, expected := range cases {
+		value, err := expr.Eval(source, env)
+		if assert.NoError(t, err, source) {
+			assert.Equal(t, expected, value, source)
+		}
+	}
+
+	for _, invalid := range []string{"age >=", "(age", "'open", "age @ 3", "unknown(1)", "age / 0", "age ? 1", "age ? 1 :"} {
+		_, err := expr.Eval(invalid, env)
+		assert.Error(t, err, invalid)
+	}
+
+	compiled, err := expr.Compile("user.age >= limit ? upper(name) : name + country")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{"user.age", "limit", "name", "country"}, compiled.Variables())
+	}
+
+	assert.False(t, expr.Truthy("false"))
+	assert.False(t, expr.Truthy([]interface{}{}))
+	assert.True(t, expr.Truthy(0.5))
+}
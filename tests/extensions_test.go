@@ -0,0 +1,129 @@
+package tests
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/assertions"
+	"github.com/nulln0ne/fuego/pkg/protocols"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterOperator(t *testing.T) {
+	err := assertions.RegisterOperator("divisible_by", func(actual, expected interface{}) (bool, string) {
+		a, _ := actual.(int)
+		b, _ := expected.(int)
+		if b == 0 || a%b != 0 {
+			return false, fmt.Sprintf("%v is not divisible by %v", actual, expected)
+		}
+		return true, fmt.Sprintf("%v is divisible by %v", actual, expected)
+	})
+	assert.NoError(t, err)
+	assert.Error(t, assertions.RegisterOperator("divisible_by", func(actual, expected interface{}) (bool, string) { return true, "" }))
+	assert.Error(t, assertions.RegisterOperator("eq", func(actual, expected interface{}) (bool, string) { return true, "" }))
+
+	engine := assertions.NewEngine(nil)
+	results, err := engine.RunAssertions([]scenario.Assertion{
+		{Type: "status", Operator: "divisible_by", Value: 100},
+		{Type: "status", Operator: "divisible_by", Value: 3},
+	}, map[string]interface{}{"status_code": 200})
+	assert.NoError(t, err)
+	assert.True(t, results[0].Passed)
+	assert.False(t, results[1].Passed)
+}
+
+func TestRegisterStepType(t *testing.T) {
+	err := protocols.RegisterStepType("echo", protocols.StepExecutorFunc(func(ctx context.Context, step *scenario.Step) (map[string]interface{}, error) {
+		body, err := json.Marshal(step.Config)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{
+			"status_code": 200,
+			"body":        step.Config,
+			"body_text":   string(body),
+		}, nil
+	}))
+	assert.NoError(t, err)
+	assert.Error(t, protocols.RegisterStepType("http", protocols.StepExecutorFunc(nil)))
+
+	sc := &scenario.Scenario{
+		Name:      "Custom step type",
+		Variables: map[string]any{"greeting": "hello"},
+		Steps: []scenario.Step{
+			{
+				Name:       "Echo",
+				Type:       "echo",
+				Config:     map[string]interface{}{"message": "{{greeting}}"},
+				Assertions: []scenario.Assertion{{Type: "json_path", Field: "message", Value: "hello"}},
+				Capture:    map[string]scenario.Capture{"echoed": {JSONPath: "message"}},
+			},
+		},
+	}
+	report := runTestScenario(t, sc)
+
+	result := report.Scenarios[0]
+	assert.Equal(t, "passed", result.Status, result.Steps[0].Error)
+	assert.Equal(t, "hello", result.Variables["echoed"])
+}
+
+func TestRequestMiddleware(t *testing.T) {
+	sign := func(method, path string, body []byte) string {
+		mac := hmac.New(sha256.New, []byte("partner-secret"))
+		fmt.Fprintf(mac, "%s\n%s\n%s", method, path, body)
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+	err := protocols.RegisterRequestMiddleware("partner-hmac", protocols.RequestMiddlewareFunc(func(req *http.Request, body []byte) error {
+		req.Header.Set("X-Signature", sign(req.Method, req.URL.Path, body))
+		return nil
+	}))
+	assert.NoError(t, err)
+	assert.Error(t, protocols.RegisterRequestMiddleware("partner-hmac", protocols.RequestMiddlewareFunc(nil)))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body []byte
+		if r.Body != nil {
+			body, _ = io.ReadAll(r.Body)
+		}
+		if r.Header.Get("X-Signature") != sign(r.Method, r.URL.Path, body) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Signed requests",
+		Steps: []scenario.Step{
+			{
+				Name:  "Signed",
+				HTTP:  &scenario.HTTPStep{URL: server.URL + "/orders", Method: "POST", JSON: map[string]interface{}{"sku": "A-1"}, Middleware: []string{"partner-hmac"}},
+				Check: map[string]interface{}{"status": 200},
+			},
+			{
+				Name:  "Unsigned",
+				HTTP:  &scenario.HTTPStep{URL: server.URL + "/orders"},
+				Check: map[string]interface{}{"status": 401},
+			},
+			{
+				Name: "Unknown middleware",
+				HTTP: &scenario.HTTPStep{URL: server.URL + "/orders", Middleware: []string{"missing"}},
+			},
+		},
+	}
+	report := runTestScenario(t, sc)
+
+	steps := report.Scenarios[0].Steps
+	assert.Equal(t, "passed", steps[0].Status, steps[0].Error)
+	assert.Equal(t, "passed", steps[1].Status, steps[1].Error)
+	assert.Equal(t, "failed", steps[2].Status)
+	assert.Contains(t, steps[2].Error, "unknown request middleware missing")
+}
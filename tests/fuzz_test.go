@@ -0,0 +1,127 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/execution"
+	"github.com/nulln0ne/fuego/pkg/fuzz"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFuzzMutateCopiesTheStep(t *testing.T) {
+	step := scenario.Step{
+		Name: "Create",
+		HTTP: &scenario.HTTPStep{
+			Method: "POST",
+			URL:    "/orders",
+			Query:  scenario.Params{"dry_run": "false"},
+			JSON:   map[string]interface{}{"items": []interface{}{map[string]interface{}{"quantity": 1}}},
+		},
+	}
+
+	mutated, err := fuzz.Mutate(step, "json.items.0.quantity", -1)
+	require.NoError(t, err)
+	assert.Equal(t, -1, mutated.HTTP.JSON.(map[string]interface{})["items"].([]interface{})[0].(map[string]interface{})["quantity"])
+	assert.Equal(t, 1, step.HTTP.JSON.(map[string]interface{})["items"].([]interface{})[0].(map[string]interface{})["quantity"])
+
+	mutated, err = fuzz.Mutate(step, "query.dry_run", "' OR '1'='1")
+	require.NoError(t, err)
+	assert.Equal(t, "' OR '1'='1", mutated.HTTP.Query["dry_run"])
+	assert.Equal(t, "false", step.HTTP.Query["dry_run"])
+
+	_, err = fuzz.Mutate(step, "json.items.3.quantity", 1)
+	assert.Error(t, err)
+	_, err = fuzz.Mutate(step, "cookies.session", "x")
+	assert.Error(t, err)
+
+	_, err = fuzz.Corpora("numbers", "nope")
+	assert.ErrorContains(t, err, "unknown corpus nope")
+}
+
+func TestFuzzFindsDeviations(t *testing.T) {
+	var afterTarget int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			w.Write([]byte(`{"token":"t"}`))
+		case "/orders":
+			// Rejects ids that are not numbers, but overflows on large ones
+			id, err := strconv.ParseInt(r.URL.Query().Get("id"), 10, 64)
+			switch {
+			case err != nil && len(r.URL.Query().Get("id")) > 10000:
+				w.WriteHeader(http.StatusInternalServerError)
+			case err != nil:
+				w.WriteHeader(http.StatusBadRequest)
+			case id > 1<<31-1:
+				w.Write([]byte(`{"id":"overflow"}`))
+			default:
+				w.Write([]byte(`{"id":` + strconv.FormatInt(id, 10) + `}`))
+			}
+		default:
+			afterTarget++
+		}
+	}))
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Orders",
+		Steps: []scenario.Step{
+			{Name: "Login", HTTP: &scenario.HTTPStep{URL: server.URL + "/login"}, Capture: map[string]scenario.Capture{"token": {JSONPath: "token"}}},
+			{
+				Name: "Get order",
+				HTTP: &scenario.HTTPStep{URL: server.URL + "/orders", Query: scenario.Params{"id": "1"}, Headers: scenario.Params{"Authorization": "{{token}}"}},
+				Assertions: []scenario.Assertion{{
+					Type:     "json_schema",
+					Operator: "json_schema",
+					Value:    map[string]interface{}{"type": "object", "properties": map[string]interface{}{"id": map[string]interface{}{"type": "integer"}}},
+				}},
+			},
+			{Name: "Delete everything", HTTP: &scenario.HTTPStep{Method: "DELETE", URL: server.URL + "/all"}},
+		},
+	}
+
+	target, err := fuzz.NewTarget(sc, "Get order")
+	require.NoError(t, err)
+	_, err = fuzz.NewTarget(sc, "Missing")
+	assert.Error(t, err)
+
+	run := func(sc *scenario.Scenario) []fuzz.Observation {
+		reporter := reporting.NewReporter(reporting.ReportConfig{Format: "json", OutputFile: os.DevNull})
+		require.NoError(t, execution.NewEngine(&config.Config{}, reporter).ExecuteScenarios([]*scenario.Scenario{sc}))
+		return fuzz.Observe(reporter.GetReport(), "Get order")
+	}
+
+	baseline := run(target.Baseline())
+	require.Len(t, baseline, 1)
+	assert.Equal(t, fuzz.Observation{Status: 200, SchemaValid: true}, baseline[0])
+
+	deviations := make(map[string]string)
+	payloads, err := fuzz.Corpora()
+	require.NoError(t, err)
+	for _, payload := range payloads {
+		step, err := fuzz.Mutate(target.Step(), "query.id", payload.Value)
+		require.NoError(t, err)
+		for _, observed := range run(target.With(step)) {
+			if deviation := fuzz.Deviation(baseline[0], observed); deviation != "" {
+				value := fmt.Sprint(payload.Value)
+				deviations[payload.Corpus+":"+value[:min(len(value), 10)]] = deviation
+			}
+		}
+	}
+
+	assert.Equal(t, map[string]string{
+		"numbers:2147483648": "response violates its JSON schema",
+		"numbers:9223372036": "response violates its JSON schema",
+		"strings:AAAAAAAAAA": "server error 500 Internal Server Error",
+	}, deviations)
+	assert.Zero(t, afterTarget, "steps after the target must not run")
+}
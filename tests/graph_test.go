@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/graph"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func checkoutScenario() *scenario.Scenario {
+	return &scenario.Scenario{
+		Name:   "Checkout",
+		Config: &scenario.ScenarioConfig{Parallel: true},
+		Setup: []scenario.Step{
+			{Name: "Login", HTTP: &scenario.HTTPStep{Method: "POST", URL: "/login"}, Capture: map[string]scenario.Capture{"token": {JSONPath: "$.token"}}},
+		},
+		Steps: []scenario.Step{
+			{
+				Name:      "Create order",
+				HTTP:      &scenario.HTTPStep{Method: "POST", URL: "/orders", Headers: map[string]string{"Authorization": "Bearer {{token}}"}},
+				Capture:   map[string]scenario.Capture{"order_id": {JSONPath: "$.id"}},
+				OnFailure: []scenario.Step{{Name: "Dump logs", HTTP: &scenario.HTTPStep{URL: "/logs"}}},
+				Cleanup:   &scenario.Step{Name: "Delete order", HTTP: &scenario.HTTPStep{Method: "DELETE", URL: "/orders/{{order_id}}"}},
+			},
+			{Name: "Pay", DependsOn: []string{"Create order"}, HTTP: &scenario.HTTPStep{Method: "POST", URL: "/orders/{{ order_id }}/pay"}},
+		},
+		Tests: map[string]*scenario.TestGroup{
+			"a": {Steps: []scenario.Step{{Name: "Check A", HTTP: &scenario.HTTPStep{URL: "/orders/{{order_idx}}"}}}},
+			"b": {Steps: []scenario.Step{{Name: "Check B", HTTP: &scenario.HTTPStep{URL: "/orders"}}}},
+		},
+		Teardown: []scenario.Step{{Name: "Logout", Type: "http", Request: scenario.Request{Method: "POST", URL: "/logout"}}},
+	}
+}
+
+func TestGraphEdges(t *testing.T) {
+	g := graph.Build(checkoutScenario())
+
+	names := make([]string, len(g.Nodes))
+	for i, node := range g.Nodes {
+		names[i] = node.Name
+	}
+	assert.Equal(t, []string{"Login", "Create order", "Dump logs", "Delete order", "Pay", "Check A", "Check B", "Logout"}, names)
+	assert.Equal(t, "on_failure", g.Nodes[2].Branch)
+	assert.Equal(t, "cleanup", g.Nodes[3].Branch)
+	assert.Equal(t, "POST /orders", g.Nodes[1].Detail)
+
+	edges := make(map[string][]string)
+	for _, edge := range g.Edges {
+		edges[edge.Kind] = append(edges[edge.Kind], g.Nodes[edge.From].Name+" > "+g.Nodes[edge.To].Name+" "+edge.Label)
+	}
+	// Parallel test groups fan out from the last step and join at teardown
+	assert.Equal(t, []string{
+		"Login > Create order ", "Create order > Pay ",
+		"Pay > Check A ", "Pay > Check B ", "Check A > Logout ", "Check B > Logout ",
+	}, edges[graph.EdgeFlow])
+	assert.Equal(t, []string{"Create order > Dump logs on failure", "Create order > Delete order cleanup"}, edges[graph.EdgeBranch])
+	assert.Equal(t, []string{"Create order > Pay depends on"}, edges[graph.EdgeDependsOn])
+	// order_idx is not order_id
+	assert.Equal(t, []string{"Login > Create order token", "Create order > Delete order order_id", "Create order > Pay order_id"}, edges[graph.EdgeCapture])
+
+	require.Len(t, g.Groups, 5)
+	assert.Equal(t, graph.Group{Name: "test: a", First: 5, Last: 5, Parallel: true}, g.Groups[2])
+}
+
+func TestGraphRendering(t *testing.T) {
+	g := graph.Build(checkoutScenario())
+
+	dot := g.DOT()
+	assert.True(t, strings.HasPrefix(dot, `digraph "Checkout" {`))
+	assert.Contains(t, dot, `subgraph cluster_1 {`)
+	assert.Contains(t, dot, `n1 [label="Create order\nPOST /orders\ncaptures order_id"];`)
+	assert.Contains(t, dot, `n0 -> n1 [style=dotted`)
+
+	svg := g.SVG()
+	decoder := xml.NewDecoder(strings.NewReader(svg))
+	texts := 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		if start, ok := token.(xml.StartElement); ok && start.Name.Local == "text" {
+			texts++
+		}
+	}
+	assert.Greater(t, texts, len(g.Nodes))
+	assert.Contains(t, svg, "test: a (parallel)")
+}
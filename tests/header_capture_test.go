@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/nulln0ne/fuego/pkg/variables"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractCookieAndLinks(t *testing.T) {
+	response := map[string]interface{}{
+		"headers": map[string][]string{
+			"Set-Cookie": {
+				"theme=dark; Path=/",
+				"session=abc123; Path=/app; Domain=example.com; Max-Age=3600; Secure; HttpOnly; SameSite=Strict",
+			},
+			"Link": {
+				`<https://api.example.com/items?page=2&sort=a,b>; rel="next", <https://api.example.com/items?page=9>; rel="last"`,
+				`</items?page=1>; rel="first prev"`,
+			},
+		},
+	}
+
+	cookie, err := variables.ExtractFromResponse(response, "cookie:session")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"name": "session", "value": "abc123", "path": "/app", "domain": "example.com", "max_age": 3600,
+		"secure": true, "http_only": true, "same_site": "Strict", "expires": "",
+	}, cookie)
+
+	_, err = variables.ExtractFromResponse(response, "cookie:missing")
+	assert.Error(t, err)
+
+	links, err := variables.ExtractFromResponse(response, "links")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"next":  "https://api.example.com/items?page=2&sort=a,b",
+		"last":  "https://api.example.com/items?page=9",
+		"first": "/items?page=1",
+		"prev":  "/items?page=1",
+	}, links)
+}
+
+func TestCaptureCookieAndLinksInScenario(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("page") {
+		case "":
+			http.SetCookie(w, &http.Cookie{Name: "session", Value: "s3cret", Path: "/", HttpOnly: true})
+			w.Header().Set("Link", "<"+"http://"+r.Host+"/items?page=2>; rel=\"next\"")
+			w.WriteHeader(http.StatusOK)
+		case "2":
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Cookies and links",
+		Steps: []scenario.Step{
+			{
+				Name: "First page",
+				HTTP: &scenario.HTTPStep{URL: server.URL + "/items"},
+				Capture: map[string]scenario.Capture{
+					"session": {Cookie: "session"},
+					"links":   {Links: true},
+				},
+			},
+			{
+				Name:  "Next page",
+				HTTP:  &scenario.HTTPStep{URL: "{{links.next}}"},
+				Check: map[string]interface{}{"status": 200},
+				Assertions: []scenario.Assertion{
+					{Type: "variable", Field: "session.value", Operator: "eq", Value: "s3cret"},
+					{Type: "variable", Field: "session.http_only", Operator: "eq", Value: true},
+				},
+			},
+		},
+	}
+	require.NoError(t, sc.Validate())
+
+	report := runTestScenario(t, sc)
+	steps := report.Scenarios[0].Steps
+	require.Len(t, steps, 2)
+	assert.Equal(t, "passed", steps[1].Status, steps[1].Error)
+	for _, result := range steps[1].Assertions {
+		assert.True(t, result.Passed, result.Message)
+	}
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/history"
+	"github.com/stretchr/testify/assert"
+)
+
+func historyRecord(id string, status string, latency time.Duration) history.Record {
+	return history.Record{
+		ID:   id,
+		Time: time.Date(2024, 1, 1, 0, 0, len(id), 0, time.UTC),
+		Scenarios: []history.ScenarioRecord{
+			{
+				Name:     "Orders",
+				Status:   status,
+				Duration: latency,
+				Steps:    []history.StepRecord{{Name: "List", Status: status, Latency: latency}},
+			},
+		},
+	}
+}
+
+func TestHistoryFileStoreTrendsAndRegressions(t *testing.T) {
+	store := history.Open(filepath.Join(t.TempDir(), "nested", "history.jsonl"))
+
+	records, err := store.Load()
+	assert.NoError(t, err)
+	assert.Empty(t, records)
+
+	assert.NoError(t, store.Append(historyRecord("a", "passed", 100*time.Millisecond)))
+	assert.NoError(t, store.Append(historyRecord("bb", "passed", 110*time.Millisecond)))
+	assert.NoError(t, store.Append(historyRecord("ccc", "failed", 200*time.Millisecond)))
+
+	records, err = store.Load()
+	assert.NoError(t, err)
+	assert.Len(t, records, 3)
+
+	trends := history.Trends(records)
+	assert.Len(t, trends, 1)
+	assert.Equal(t, 3, trends[0].Runs)
+	assert.InDelta(t, 66.7, trends[0].PassRate, 0.1)
+	assert.Equal(t, []string{"passed", "passed", "failed"}, trends[0].Statuses)
+
+	regressions := history.Compare(records[1], records[2], 0.2)
+	kinds := make([]string, 0, len(regressions))
+	for _, regression := range regressions {
+		kinds = append(kinds, regression.Kind)
+	}
+	assert.Equal(t, []string{"status", "status", "latency"}, kinds)
+
+	assert.Empty(t, history.Compare(records[0], records[1], 0.2))
+}
+
+func TestHistoryHTTPStore(t *testing.T) {
+	var mu sync.Mutex
+	var stored []history.Record
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if r.Method == http.MethodPost {
+			var record history.Record
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&record))
+			stored = append(stored, record)
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(stored)
+	}))
+	defer server.Close()
+
+	store := history.Open(server.URL)
+	assert.NoError(t, store.Append(historyRecord("a", "passed", time.Millisecond)))
+
+	records, err := store.Load()
+	assert.NoError(t, err)
+	assert.Len(t, records, 1)
+	assert.Equal(t, "a", records[0].ID)
+}
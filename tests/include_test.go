@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestIncludeSharedFragments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" || r.Header.Get("X-Team") != "payments" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	defer server.Close()
+
+	dir := writeFiles(t, map[string]string{
+		"users.yaml": `name: Users
+variables:
+  base: ` + server.URL + `
+tests:
+  users:
+    steps:
+      - name: Get user
+        http:
+          url: "{{base}}/users/1"
+          headers:
+            <<: !include shared/auth.yaml
+            X-Team: payments
+        check: !include shared/checks/ok.yaml
+      - !include shared/steps/get-user.yaml
+`,
+		"shared/auth.yaml": `Authorization: Bearer secret
+`,
+		"shared/checks/ok.yaml": `status: 200
+`,
+		// Paths in included files are relative to the included file
+		"shared/steps/get-user.yaml": `name: Get user again
+http:
+  url: "{{base}}/users/1"
+  headers: !include ../auth-team.yaml
+check: !include ../checks/ok.yaml
+`,
+		"shared/auth-team.yaml": `Authorization: Bearer secret
+X-Team: payments
+`,
+	})
+
+	sc, err := scenario.LoadScenario(filepath.Join(dir, "users.yaml"))
+	require.NoError(t, err)
+	steps := sc.Tests["users"].Steps
+	require.Len(t, steps, 2)
+	assert.Equal(t, "Bearer secret", steps[0].HTTP.Headers["Authorization"])
+	assert.Equal(t, "payments", steps[0].HTTP.Headers["X-Team"])
+	assert.Equal(t, "Get user again", steps[1].Name)
+
+	report := runTestScenario(t, sc)
+	require.Len(t, report.Scenarios, 1)
+	assert.Equal(t, "passed", report.Scenarios[0].Status, "%+v", report.Scenarios[0].Steps)
+}
+
+func TestIncludeErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"missing.yaml": "name: Missing\nsteps:\n  - name: a\n    http: !include nowhere.yaml\n",
+		"cycle.yaml":   "name: Cycle\nsteps: !include a.yaml\n",
+		"a.yaml":       "- name: a\n  http: !include b.yaml\n",
+		"b.yaml":       "url: /x\nheaders: !include a.yaml\n",
+		"typo.yaml":    "name: Typo\nsteps:\n  - name: a\n    http: !include http.yaml\n",
+		"http.yaml":    "url: /x\nmetod: GET\n",
+	})
+
+	_, err := scenario.LoadScenario(filepath.Join(dir, "missing.yaml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), filepath.Join(dir, "missing.yaml")+":4:11: !include")
+
+	_, err = scenario.LoadScenario(filepath.Join(dir, "cycle.yaml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "include cycle")
+
+	// Unknown fields point at the included file
+	_, err = scenario.LoadScenario(filepath.Join(dir, "typo.yaml"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), filepath.Join(dir, "http.yaml")+`:2:1: unknown field "metod" in http`)
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/execution"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTestManagementIntegrations(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	var mu sync.Mutex
+	received := map[string]interface{}{}
+	var zephyr []map[string]interface{}
+	tms := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		key := r.URL.Path
+		if r.URL.RawQuery != "" {
+			key += "?" + r.URL.RawQuery
+		}
+
+		switch key {
+		case "/index.php?/api/v2/add_run/7":
+			user, password, _ := r.BasicAuth()
+			assert.Equal(t, "ci@example.com", user)
+			assert.Equal(t, "tr-key", password)
+			w.Write([]byte(`{"id": 99}`))
+		case "/api/v2/authenticate":
+			w.Write([]byte(`"xray-token"`))
+		case "/api/v2/import/execution":
+			assert.Equal(t, "Bearer xray-token", r.Header.Get("Authorization"))
+			w.Write([]byte(`{"key": "PROJ-100"}`))
+		case "/testexecutions":
+			zephyr = append(zephyr, body)
+		}
+		received[key] = body
+	}))
+	defer tms.Close()
+
+	t.Setenv("TESTRAIL_KEY", "tr-key")
+	cfg := &config.Config{Integrations: config.IntegrationsConfig{
+		TestRail: &config.TestRailConfig{URL: tms.URL, User: "ci@example.com", APIKey: "${TESTRAIL_KEY}", ProjectID: 7},
+		Xray:     &config.XrayConfig{URL: tms.URL, ClientID: "id", ClientSecret: "secret", ProjectKey: "PROJ"},
+		Zephyr:   &config.ZephyrConfig{URL: tms.URL, Token: "z", ProjectKey: "PROJ", TestCycle: "PROJ-R1"},
+	}}
+
+	labels := func(testrail, xray string) scenario.ScenarioMetadata {
+		return scenario.ScenarioMetadata{Labels: map[string]string{"testrail_id": testrail, "xray_test": xray, "zephyr_test": xray}}
+	}
+	scenarios := []*scenario.Scenario{
+		{Name: "Orders", Metadata: labels("C1, C2", "PROJ-1"), Steps: []scenario.Step{
+			{Name: "List", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}},
+		}},
+		{Name: "Users", Metadata: labels("C2", "PROJ-2"), Steps: []scenario.Step{
+			{Name: "Teapot", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}, Check: map[string]interface{}{"status": 418}},
+		}},
+		{Name: "Unmapped", Steps: []scenario.Step{{Name: "List", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}}}},
+	}
+
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "none"})
+	assert.NoError(t, execution.NewEngine(cfg, reporter).ExecuteScenarios(scenarios))
+
+	run := received["/index.php?/api/v2/add_run/7"].(map[string]interface{})
+	assert.Equal(t, []interface{}{1.0, 2.0}, run["case_ids"])
+
+	results := received["/index.php?/api/v2/add_results_for_cases/99"].(map[string]interface{})["results"].([]interface{})
+	if assert.Len(t, results, 2) {
+		assert.Equal(t, 1.0, results[0].(map[string]interface{})["status_id"])
+		// C2 is mapped to a passing and a failing scenario, so it fails
+		assert.Equal(t, 5.0, results[1].(map[string]interface{})["status_id"])
+		assert.Contains(t, results[1].(map[string]interface{})["comment"], "Users / Teapot")
+	}
+
+	tests := received["/api/v2/import/execution"].(map[string]interface{})["tests"].([]interface{})
+	if assert.Len(t, tests, 2) {
+		assert.Equal(t, "PASSED", tests[0].(map[string]interface{})["status"])
+		assert.Equal(t, "FAILED", tests[1].(map[string]interface{})["status"])
+	}
+
+	if assert.Len(t, zephyr, 2) {
+		assert.Equal(t, "PROJ-R1", zephyr[0]["testCycleKey"])
+		assert.Equal(t, "Fail", zephyr[1]["statusName"])
+	}
+}
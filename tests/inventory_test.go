@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestInventoryListsGroupsAndRequiredVariables(t *testing.T) {
+	var sc scenario.Scenario
+	require.NoError(t, yaml.Unmarshal([]byte(`
+name: Orders
+metadata:
+  tags: [orders, smoke]
+  labels: {component: orders}
+matrix:
+  region: [eu, us]
+variables:
+  page_size: 20
+  fetched: "{{ page_size * pages }}"
+data:
+  customers: {type: inline, data: [{id: 1}]}
+setup:
+  - name: Login
+    http:
+      url: "{{auth_url}}/login"
+      json: {key: "{{ env.api_key }}"}
+    capture:
+      token: {jsonpath: $.token}
+steps:
+  - name: List
+    http:
+      url: "{{base_url}}/orders?size={{page_size}}&at={{timestamp}}&since={{now(-1d)}}"
+      headers: {Authorization: "Bearer {{token}}", X-Region: "{{region}}"}
+    on_failure:
+      - name: Dump
+        http: {url: "{{debug_url}}/dump?status={{last_status}}"}
+tests:
+  by_customer:
+    data_driven: {source: customers, variable: customer}
+    steps:
+      - name: Get
+        http: {url: "{{base_url}}/customers/{{customer.id}}/orders/{{ order.id | upper }}"}
+      - name: Delete
+        http: {method: DELETE, url: "{{base_url}}/x?token={{captures.token}}"}
+  skipped:
+    skip: true
+    steps:
+      - name: Later
+        http: {url: "{{base_url}}/later"}
+`), &sc))
+	sc.SourceFile = "orders.yaml"
+
+	inventory := scenario.Inventorize(&sc)
+	assert.Equal(t, "Orders", inventory.Name)
+	assert.Equal(t, "orders.yaml", inventory.File)
+	assert.Equal(t, []string{"orders", "smoke"}, inventory.Tags)
+	assert.Equal(t, 2, inventory.Matrix)
+	assert.Equal(t, []scenario.GroupInventory{
+		{Name: "setup", Steps: 1},
+		{Name: "steps", Steps: 1},
+		{Name: "test: by_customer", Steps: 2},
+		{Name: "test: skipped", Steps: 1, Skip: true},
+	}, inventory.Groups)
+	assert.Equal(t, 5, inventory.Steps)
+	assert.Equal(t, []string{"api_key", "auth_url", "base_url", "debug_url", "order", "pages"}, inventory.Variables)
+}
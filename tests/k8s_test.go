@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/k8s"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestK8sBundleKeepsLayout(t *testing.T) {
+	dir := t.TempDir()
+	write := func(path, content string) {
+		full := filepath.Join(dir, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(full), 0755))
+		require.NoError(t, os.WriteFile(full, []byte(content), 0644))
+	}
+	write("suite/orders.yaml", "name: Orders\n")
+	write("suite/data/ids.csv", "id\n1\n")
+	write("suite/.git/HEAD", "ref: main\n")
+	write("shared/auth.yaml", "Authorization: token\n")
+	write("fuego.yaml", "global:\n  base_url: http://api\n")
+
+	bundle, err := k8s.NewBundle([]string{filepath.Join(dir, "suite"), filepath.Join(dir, "shared", "auth.yaml")}, filepath.Join(dir, "fuego.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"suite", "shared/auth.yaml"}, bundle.Paths)
+	assert.Len(t, bundle.Files, 3)
+	assert.Equal(t, "id\n1\n", string(bundle.Files["suite/data/ids.csv"]))
+	assert.NotContains(t, bundle.Files, "suite/.git/HEAD")
+
+	spec := k8s.Spec{Name: "smoke", Namespace: "qa", Image: "fuego:test", Args: []string{"--env", "staging"}, Secrets: []string{"api"}, Timeout: 10 * time.Minute}
+	require.NoError(t, spec.Validate())
+	manifests := spec.Manifests(bundle)
+	require.Len(t, manifests, 2)
+	assert.Equal(t, "ConfigMap", manifests[0]["kind"])
+	assert.Equal(t, "Job", manifests[1]["kind"])
+	assert.Contains(t, manifests[0]["data"], "config")
+
+	content, err := k8s.WriteYAML(manifests)
+	require.NoError(t, err)
+	manifest := string(content)
+	assert.Contains(t, manifest, "path: suite/data/ids.csv")
+	assert.Contains(t, manifest, "path: .fuego/config.yaml")
+	assert.Contains(t, manifest, "activeDeadlineSeconds: 600")
+	assert.Contains(t, manifest, "name: api")
+
+	pod := manifests[1]["spec"].(k8s.Object)["template"].(k8s.Object)["spec"].(k8s.Object)
+	container := pod["containers"].([]k8s.Object)[0]
+	assert.Equal(t, []string{"run", "--quiet", "--no-progress", "--format", "json", "--config", ".fuego/config.yaml", "--env", "staging", "suite", "shared/auth.yaml"}, container["args"])
+
+	spec.Schedule = "*/15 * * * *"
+	cron := spec.Manifests(bundle)[1]
+	assert.Equal(t, "CronJob", cron["kind"])
+	assert.Equal(t, "Forbid", cron["spec"].(k8s.Object)["concurrencyPolicy"])
+
+	assert.Error(t, k8s.Spec{Name: "Smoke_Tests", Image: "fuego"}.Validate())
+	assert.Equal(t, "ghcr.io/nulln0ne/fuego:v1.4.0", k8s.DefaultImage("v1.4.0"))
+	assert.Equal(t, "ghcr.io/nulln0ne/fuego:latest", k8s.DefaultImage("v1.4.0-3-gabc123-dirty"))
+}
+
+func TestK8sReportFromLogs(t *testing.T) {
+	logs := "Warning: something\n{\n  \"summary\": {\"total\": 2, \"passed\": 1, \"failed\": 1},\n  \"scenarios\": [{\"status\": \"passed\"}, {\"status\": \"failed\"}]\n}\nError: 1 scenario(s) failed\n"
+	report, err := k8s.ReportFromLogs([]byte(logs))
+	require.NoError(t, err)
+	assert.Equal(t, 2, report.Summary.Total)
+	assert.False(t, report.Passed())
+
+	_, err = k8s.ReportFromLogs([]byte("exec: fuego: not found\n"))
+	assert.Error(t, err)
+}
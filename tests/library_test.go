@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nulln0ne/fuego"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLibraryRun(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	scenarioPath := filepath.Join(t.TempDir(), "library.yaml")
+	content := `name: From file
+steps:
+  - name: Get JSON
+    http:
+      url: ` + server.URL + `/json
+    check:
+      status: 200
+`
+	assert.NoError(t, os.WriteFile(scenarioPath, []byte(content), 0644))
+
+	built := &scenario.Scenario{
+		Name: "Built in Go",
+		Steps: []scenario.Step{
+			{Name: "Wrong status", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}, Check: map[string]interface{}{"status": 404}},
+		},
+	}
+
+	report, err := fuego.Run(context.Background(), fuego.Options{
+		Paths:     []string{scenarioPath},
+		Scenarios: []*fuego.Scenario{built},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, report.Scenarios, 2)
+	assert.Equal(t, "passed", report.Scenarios[0].Status)
+	assert.False(t, report.Passed())
+	assert.Len(t, report.Failures(), 1)
+	assert.Contains(t, report.Failures()[0], "Built in Go / Wrong status")
+}
+
+func TestLibraryRunHonorsCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report, err := fuego.Run(ctx, fuego.Options{
+		Scenarios: []*fuego.Scenario{{Name: "Never runs", Steps: []scenario.Step{{Name: "noop"}}}},
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, report.Scenarios)
+}
+
+func TestLibraryRunFiltersByLabels(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	labeled := func(name string, labels map[string]string) *fuego.Scenario {
+		return &fuego.Scenario{
+			Name:     name,
+			Metadata: scenario.ScenarioMetadata{Labels: labels},
+			Steps:    []scenario.Step{{Name: "Get", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}}},
+		}
+	}
+	scenarios := []*fuego.Scenario{
+		labeled("Pay", map[string]string{"component": "payments", "severity": "critical"}),
+		labeled("Refund", map[string]string{"component": "payments", "severity": "minor"}),
+		labeled("Login", map[string]string{"component": "auth", "severity": "critical"}),
+		labeled("Unlabeled", nil),
+	}
+
+	report, err := fuego.Run(context.Background(), fuego.Options{
+		Scenarios: scenarios,
+		Labels:    []string{"component=payments", "severity=Critical,high"},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, report.Scenarios, 1) {
+		assert.Equal(t, "Pay", report.Scenarios[0].Scenario.Name)
+	}
+
+	report, err = fuego.Run(context.Background(), fuego.Options{Scenarios: scenarios, Labels: []string{"severity"}})
+	assert.NoError(t, err)
+	assert.Len(t, report.Scenarios, 3)
+
+	_, err = fuego.Run(context.Background(), fuego.Options{Scenarios: scenarios, Labels: []string{"owner=nobody"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,107 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/load"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadProfiles(t *testing.T) {
+	soak := load.Soak(1, 150*time.Minute, load.StopConditions{})
+	require.Len(t, soak.Stages, 3)
+	assert.Equal(t, "hour 3", soak.Stages[2].Name)
+	assert.Equal(t, 30*time.Minute, soak.Stages[2].Duration)
+
+	stress := load.Stress(10, 20, 70, time.Minute, load.StopConditions{})
+	var rates []float64
+	for _, stage := range stress.Stages {
+		rates = append(rates, stage.Rate)
+	}
+	assert.Equal(t, []float64{10, 30, 50, 70}, rates)
+
+	spike, err := load.NewProfile("spike", load.Settings{Rate: 1, MaxRate: 50, StageDuration: time.Minute, SpikeDuration: 10 * time.Second})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"warm-up", "spike", "recovery"}, []string{spike.Stages[0].Name, spike.Stages[1].Name, spike.Stages[2].Name})
+
+	_, err = load.NewProfile("ramp", load.Settings{})
+	assert.Error(t, err)
+}
+
+func TestLoadRunReportsStagesAndStops(t *testing.T) {
+	passing := func(ctx context.Context) bool { return true }
+	spike := load.Spike(50, 200, 100*time.Millisecond, 50*time.Millisecond, load.StopConditions{})
+
+	result, err := load.Run(context.Background(), spike, passing, load.Options{})
+	require.NoError(t, err)
+	require.Len(t, result.Stages, 3)
+	assert.True(t, result.Passed())
+	for _, stage := range result.Stages {
+		assert.Greater(t, stage.Iterations, 1, stage.Stage.Name)
+		assert.Zero(t, stage.Failed)
+	}
+
+	failing := func(ctx context.Context) bool { return false }
+	stress := load.Stress(50, 50, 200, 50*time.Millisecond, load.StopConditions{MaxErrorRate: 0.1})
+
+	var reported []load.StageResult
+	result, err = load.Run(context.Background(), stress, failing, load.Options{
+		StageFinished: func(stage load.StageResult) { reported = append(reported, stage) },
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Stages, 1, "the first stage over the error threshold stops the run")
+	assert.Equal(t, reported, result.Stages)
+	assert.Contains(t, result.StoppedBy, "error rate 100.0% above 10.0%")
+	assert.False(t, result.Passed())
+}
+
+func TestLoadMetricsAndDashboard(t *testing.T) {
+	metrics := load.NewMetrics(time.Minute)
+	metrics.SetStage(load.Stage{Name: "spike"})
+	for i := 1; i <= 10; i++ {
+		metrics.Record("GET /orders", time.Duration(i)*time.Millisecond, i == 10)
+	}
+	metrics.Record("POST /orders", 50*time.Millisecond, false)
+
+	snapshot := metrics.Snapshot()
+	assert.Equal(t, "spike", snapshot.Stage)
+	assert.InDelta(t, 1.0/11, snapshot.ErrorRate, 0.001)
+	require.Len(t, snapshot.Steps, 2)
+	orders := snapshot.Steps[0]
+	assert.Equal(t, "GET /orders", orders.Step)
+	assert.Equal(t, 10, orders.Total)
+	assert.Equal(t, 1, orders.Failed)
+	assert.Equal(t, 5*time.Millisecond, orders.P50)
+	assert.Equal(t, 10*time.Millisecond, orders.P95)
+	assert.Greater(t, orders.RPS, 0.0)
+
+	stopped := false
+	server := httptest.NewServer(load.NewDashboard(metrics, func() { stopped = true }))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/metrics")
+	require.NoError(t, err)
+	var served load.Snapshot
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&served))
+	resp.Body.Close()
+	assert.Len(t, served.Steps, 2)
+
+	resp, err = http.Get(server.URL + "/")
+	require.NoError(t, err)
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Contains(t, string(page), "Stop run")
+
+	resp, err = http.Post(server.URL+"/stop", "", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	assert.True(t, stopped)
+}
@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/manpage"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManPages(t *testing.T) {
+	root := &cobra.Command{Use: "fuego", Short: "API testing"}
+	root.PersistentFlags().BoolP("verbose", "v", false, "verbose output")
+	export := &cobra.Command{Use: "export", Short: "Export steps"}
+	curl := &cobra.Command{
+		Use:   "curl [scenario file]",
+		Short: "Export HTTP steps as curl commands",
+		Long: `Render every HTTP step as a curl command.
+.dotfiles and back\slashes are escaped.
+
+Examples:
+  fuego export curl --env staging test.yaml`,
+		Run: func(cmd *cobra.Command, args []string) {},
+	}
+	curl.Flags().StringP("env", "e", "", "environment to use")
+	curl.Flags().String("trace", "", "dump wire data")
+	curl.Flags().Lookup("trace").NoOptDefVal = "-"
+	curl.Flags().Int("retries", 3, "retries per step")
+	root.AddCommand(export)
+	export.AddCommand(curl)
+
+	var page bytes.Buffer
+	header := manpage.Header{Source: "fuego v1.4.0", Manual: "Fuego Manual", Date: time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)}
+	require.NoError(t, manpage.Write(&page, curl, header))
+	assert.Equal(t, `.TH "FUEGO\-EXPORT\-CURL" "1" "Oct 2026" "fuego v1.4.0" "Fuego Manual"
+.SH NAME
+fuego\-export\-curl \- Export HTTP steps as curl commands
+.SH SYNOPSIS
+.B fuego export curl
+[scenario file]
+[flags]
+.SH DESCRIPTION
+Render every HTTP step as a curl command.
+\&.dotfiles and back\eslashes are escaped.
+.PP
+Examples:
+.PP
+.RS 4
+.nf
+fuego export curl \-\-env staging test.yaml
+.fi
+.RE
+.SH OPTIONS
+.TP
+\fB\-e\fP, \fB\-\-env\fP=\fIstring\fP
+environment to use
+.TP
+\fB\-\-retries\fP=\fIint\fP
+retries per step (default 3)
+.TP
+\fB\-\-trace\fP[=\fIstring\fP]
+dump wire data
+.SH OPTIONS INHERITED FROM PARENT COMMANDS
+.TP
+\fB\-v\fP, \fB\-\-verbose\fP
+verbose output
+.SH SEE ALSO
+\fBfuego\-export\fP(1)
+`, page.String())
+
+	dir := t.TempDir()
+	written, err := manpage.WriteTree(root, header, dir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "fuego.1"),
+		filepath.Join(dir, "fuego-export.1"),
+		filepath.Join(dir, "fuego-export-curl.1"),
+	}, written)
+	content, err := os.ReadFile(filepath.Join(dir, "fuego-export.1"))
+	require.NoError(t, err)
+	assert.Contains(t, string(content), ".B fuego export\n\\fIcommand\\fP [flags]\n")
+	assert.Contains(t, string(content), "\\fBfuego\\fP(1),\n\\fBfuego\\-export\\-curl\\fP(1)\n")
+}
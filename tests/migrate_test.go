@@ -0,0 +1,111 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const legacyScenario = `version: "1.0"
+name: Orders
+setup:
+  - name: Login
+    type: http
+    request:
+      method: POST
+      url: /login
+    variables:
+      token: "json:access_token"
+      session: "header:X-Session"
+      region: eu   # a plain value stays a variable
+before:
+  steps:
+    - name: Health
+      http: {url: /health}
+steps:
+  - name: Upload
+    type: http
+    request:
+      method: POST
+      url: /uploads
+      files: {document: invoice.pdf}
+  - name: List orders
+    type: http
+    request:
+      url: /orders
+    assertions:
+      - type: status
+        value: 200
+teardown:
+  - name: Logout
+    type: http
+    request:
+      method: POST
+      url: /logout
+`
+
+func TestMigrateLegacyScenario(t *testing.T) {
+	migrated, notes, err := scenario.Migrate([]byte(legacyScenario))
+	require.NoError(t, err)
+	assert.Contains(t, string(migrated), "# a plain value stays a variable")
+
+	var sc scenario.Scenario
+	require.NoError(t, yaml.Unmarshal(migrated, &sc))
+	assert.Equal(t, scenario.CurrentVersion, sc.Version)
+	assert.Empty(t, sc.Setup)
+	assert.Empty(t, sc.Steps)
+	assert.Empty(t, sc.Teardown)
+
+	// setup runs after the before steps, teardown before the after steps
+	require.Len(t, sc.Before.Steps, 2)
+	assert.Equal(t, "Health", sc.Before.Steps[0].Name)
+	login := sc.Before.Steps[1]
+	require.NotNil(t, login.HTTP)
+	assert.Equal(t, "/login", login.HTTP.URL)
+	assert.Empty(t, login.Type)
+	assert.Equal(t, map[string]any{"region": "eu"}, login.Variables)
+	assert.Equal(t, scenario.Capture{JSONPath: "access_token"}, login.Capture["token"])
+	assert.Equal(t, scenario.Capture{Header: "X-Session"}, login.Capture["session"])
+	require.Len(t, sc.After.Steps, 1)
+	assert.Equal(t, "/logout", sc.After.Steps[0].HTTP.URL)
+
+	// Top-level steps carry on after failures, as they did
+	main := sc.Tests["main"]
+	require.NotNil(t, main)
+	assert.True(t, main.ContinueOnFail)
+	require.Len(t, main.Steps, 2)
+	assert.Nil(t, main.Steps[0].HTTP, "http steps cannot upload files")
+	assert.Equal(t, "/uploads", main.Steps[0].Request.URL)
+	assert.Equal(t, "/orders", main.Steps[1].HTTP.URL)
+	assert.Len(t, main.Steps[1].Assertions, 1)
+
+	assert.Equal(t, []string{
+		"step 'Upload' is kept as a request step: http steps have no files",
+		"setup steps moved to before, which stops the scenario when one of its steps fails",
+	}, notes)
+	assert.Equal(t, []string{"step 'Upload': request is deprecated, use http (fuego migrate rewrites it)"}, sc.Deprecations())
+
+	// Migrating again changes nothing
+	again, notes, err := scenario.Migrate(migrated)
+	require.NoError(t, err)
+	assert.Equal(t, string(migrated), string(again))
+	assert.Len(t, notes, 1)
+}
+
+func TestScenarioVersions(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "future.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("version: \"2.0\"\nname: Future\nsteps: []\n"), 0644))
+	_, err := scenario.LoadScenario(path)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unsupported version "2.0"`)
+
+	sc, err := scenario.LoadScenario("../examples/simple-api-test.yaml")
+	require.NoError(t, err)
+	assert.Len(t, sc.Deprecations(), 3)
+}
@@ -0,0 +1,122 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/monitor"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func monitorReport(statuses map[string]string, duration time.Duration) *reporting.Report {
+	report := &reporting.Report{}
+	for name, status := range statuses {
+		result := reporting.ScenarioResult{Scenario: &scenario.Scenario{Name: name}, Status: status, Duration: duration}
+		if status == "failed" {
+			result.Error = "status 503"
+		}
+		report.Scenarios = append(report.Scenarios, result)
+	}
+	return report
+}
+
+func TestMonitorAlertsOnConsecutiveFailures(t *testing.T) {
+	m := monitor.New([]string{"Checkout", "Login"}, monitor.Options{Interval: time.Minute, AlertAfter: 2, Window: 4})
+	at := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	check := func(checkout string, duration time.Duration) []monitor.Alert {
+		at = at.Add(time.Minute)
+		return m.Record(at, monitorReport(map[string]string{"Checkout": checkout, "Login": "passed"}, duration), nil)
+	}
+
+	assert.Empty(t, check("passed", 100*time.Millisecond))
+	assert.Empty(t, check("failed", time.Second))
+	alerts := check("failed", time.Second)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, monitor.AlertFiring, alerts[0].Status)
+	assert.Equal(t, "Checkout", alerts[0].Scenario)
+	assert.Equal(t, 2, alerts[0].ConsecutiveFailures)
+	assert.Equal(t, []string{"Checkout: status 503"}, alerts[0].Failures)
+	assert.Empty(t, check("failed", time.Second), "a firing alert is sent once")
+
+	// A check that cannot run fails every scenario
+	alerts = m.Record(at.Add(time.Minute), nil, errors.New("connection refused"))
+	require.Len(t, alerts, 0, "Login needs a second failure")
+
+	alerts = check("passed", 300*time.Millisecond)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, monitor.AlertResolved, alerts[0].Status)
+
+	stats := m.Stats()
+	require.Len(t, stats, 2)
+	assert.Equal(t, "Checkout", stats[0].Scenario)
+	assert.Equal(t, int64(6), stats[0].Checks)
+	assert.Equal(t, int64(4), stats[0].Failures)
+	assert.True(t, stats[0].Up)
+	assert.Equal(t, 0.25, stats[0].SuccessRate, "window of the last 4 checks")
+	assert.Equal(t, 300*time.Millisecond, stats[0].P95)
+	assert.Equal(t, int64(1), stats[1].Failures)
+	assert.Equal(t, 0.75, stats[1].SuccessRate)
+
+	var metrics strings.Builder
+	require.NoError(t, m.WriteMetrics(&metrics))
+	assert.Contains(t, metrics.String(), "# TYPE fuego_monitor_checks_total counter\n")
+	assert.Contains(t, metrics.String(), `fuego_monitor_failures_total{scenario="Checkout"} 4`)
+	assert.Contains(t, metrics.String(), `fuego_monitor_up{scenario="Login"} 1`)
+	assert.Contains(t, metrics.String(), `fuego_monitor_window_duration_seconds{scenario="Checkout",quantile="0.95"} 0.3`)
+	assert.Contains(t, metrics.String(), "fuego_monitor_errors_total 1\n")
+}
+
+func TestMonitorRunSendsAlertsToWebhooks(t *testing.T) {
+	var mu sync.Mutex
+	var received []monitor.Alert
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "alert", r.Header.Get("X-Fuego-Event"))
+		var alert monitor.Alert
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&alert))
+		mu.Lock()
+		received = append(received, alert)
+		mu.Unlock()
+	}))
+	defer hook.Close()
+
+	m := monitor.New(nil, monitor.Options{
+		Interval:   time.Millisecond,
+		AlertAfter: 1,
+		Notifiers: []monitor.Notifier{
+			reporting.NewWebhookSink(hook.URL, nil, []string{"alert"}, 0),
+			reporting.NewWebhookSink(hook.URL, nil, nil, 0), // only wants run reports
+		},
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	statuses := []string{"failed", "failed", "passed"}
+	checks := 0
+	err := m.Run(ctx, func(ctx context.Context) (*reporting.Report, error) {
+		status := statuses[checks]
+		checks++
+		return monitorReport(map[string]string{"Health": status}, time.Millisecond), nil
+	}, func(result monitor.Result) {
+		assert.Empty(t, result.NotifyErrors)
+		if checks == len(statuses) {
+			cancel()
+		}
+	})
+	require.NoError(t, err)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, received, 2)
+	assert.Equal(t, monitor.AlertFiring, received[0].Status)
+	assert.Equal(t, monitor.AlertResolved, received[1].Status)
+}
@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/nulln0ne/fuego/pkg/variables"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespacedVariables(t *testing.T) {
+	ctx := variables.NewContext()
+	ctx.SetGlobal("base_url", "https://config.example")
+	ctx.SetNamespaced(variables.NamespaceConfig, "base_url", "https://config.example")
+	ctx.SetLocal("base_url", "https://scenario.example")
+	ctx.SetNamespaced(variables.NamespaceScenario, "base_url", "https://scenario.example")
+	ctx.SetStep("row", map[string]interface{}{"email": "ada@example.com"})
+	ctx.SetNamespaced(variables.NamespaceData, "row", map[string]interface{}{"email": "ada@example.com"})
+	ctx.SetLocal("data", map[string]interface{}{"id": 7})
+
+	cases := map[string]string{
+		"{{base_url}}":          "https://scenario.example",
+		"{{config.base_url}}":   "https://config.example",
+		"{{scenario.base_url}}": "https://scenario.example",
+		"{{data.row.email}}":    "ada@example.com",
+		"{{data.id}}":           "7",
+		"{{captures.base_url}}": "{{captures.base_url}}",
+	}
+	for input, expected := range cases {
+		result, err := ctx.InterpolateString(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, result, input)
+	}
+
+	clone := ctx.Clone()
+	clone.SetNamespaced(variables.NamespaceCaptures, "token", "abc")
+	value, exists := clone.GetNested("captures.token")
+	assert.True(t, exists)
+	assert.Equal(t, "abc", value)
+	_, exists = ctx.GetNested("captures.token")
+	assert.False(t, exists)
+}
+
+func TestCapturesDoNotHideScenarioVariables(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.URL.RawQuery)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"user": "captured"}`))
+	}))
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name:      "Namespaces",
+		Variables: map[string]interface{}{"user": "configured"},
+		Steps: []scenario.Step{
+			{
+				Name:    "Login",
+				HTTP:    &scenario.HTTPStep{URL: server.URL + "/login"},
+				Capture: map[string]scenario.Capture{"user": {JSONPath: "user"}},
+			},
+			{
+				Name: "Profile",
+				HTTP: &scenario.HTTPStep{URL: server.URL + "/profile?flat={{user}}&scenario={{scenario.user}}&captured={{captures.user}}"},
+			},
+		},
+	}
+
+	report := runTestScenario(t, sc)
+	assert.Equal(t, "passed", report.Scenarios[0].Status, report.Scenarios[0].Error)
+	assert.Equal(t, []string{"", "flat=captured&scenario=configured&captured=captured"}, received)
+}
+
+func TestCapturedTemplatesAreNotEvaluated(t *testing.T) {
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("X-Name"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"name": "{{token}}", "template": "Hi {{ user.first }}!"}`))
+	}))
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name:      "Literal captures",
+		Variables: map[string]interface{}{"token": "secret-token"},
+		Steps: []scenario.Step{
+			{
+				Name:    "Fetch",
+				HTTP:    &scenario.HTTPStep{URL: server.URL},
+				Capture: map[string]scenario.Capture{"name": {JSONPath: "name"}, "template": {JSONPath: "template"}},
+			},
+			{
+				Name:  "Send back",
+				HTTP:  &scenario.HTTPStep{URL: server.URL, Headers: map[string]string{"X-Name": "{{name}}"}},
+				Check: map[string]interface{}{"status": 200},
+			},
+		},
+	}
+
+	report := runTestScenario(t, sc)
+	result := report.Scenarios[0]
+	assert.Equal(t, "passed", result.Status, result.Error)
+	assert.Equal(t, []string{"", "{{token}}"}, received)
+	assert.Equal(t, "{{token}}", result.Variables["name"])
+	assert.Equal(t, "Hi {{ user.first }}!", result.Variables["template"])
+}
+
+func TestCloneLeavesOutStepVariables(t *testing.T) {
+	ctx := variables.NewContext()
+	ctx.SetLocal("region", "scenario")
+	ctx.SetNamespaced(variables.NamespaceScenario, "region", "scenario")
+	ctx.SetStep("region", "step")
+	ctx.SetNamespaced(variables.NamespaceStep, "region", "step")
+
+	// Neither {{region}} nor {{step.region}} sees the step variable on the clone
+	clone := ctx.Clone()
+	cases := map[string]string{
+		"{{region}}":          "scenario",
+		"{{step.region}}":     "{{step.region}}",
+		"{{scenario.region}}": "scenario",
+	}
+	for input, expected := range cases {
+		result, err := clone.InterpolateString(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, expected, result, input)
+	}
+
+	ctx.ClearStep()
+	value, _ := ctx.Get("region")
+	assert.Equal(t, "scenario", value)
+	assert.Empty(t, ctx.Namespaced(variables.NamespaceStep))
+}
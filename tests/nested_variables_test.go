@@ -2,6 +2,7 @@ package tests
 
 import (
 	"testing"
+	"time"
 
 	"github.com/nulln0ne/fuego/pkg/variables"
 )
@@ -88,3 +89,112 @@ func TestVariableInterpolationWithDotNotation(t *testing.T) {
 		t.Errorf("Expected '%s', got '%s'", expected, result)
 	}
 }
+
+func TestTimeBuiltinsWithFrozenClock(t *testing.T) {
+	ctx := variables.NewContext()
+	ctx.AddBuiltins()
+	ctx.SetClock(variables.FrozenClock(time.Date(2024, 2, 28, 22, 30, 0, 0, time.UTC)))
+
+	cases := map[string]string{
+		"{{iso_timestamp}}":                "2024-02-28T22:30:00Z",
+		"{{date}} {{time}}":                "2024-02-28 22:30:00",
+		"{{timestamp}}":                    "1709159400",
+		"{{now()}}":                        "2024-02-28T22:30:00Z",
+		"{{now(+2h, RFC3339)}}":            "2024-02-29T00:30:00Z",
+		"{{now(-1d12h, datetime)}}":        "2024-02-27 10:30:00",
+		"{{today(-1d)}}":                   "2024-02-27",
+		"{{today(+1M, RFC3339)}}":          "2024-03-28T00:00:00Z",
+		"{{now(+90s, unix)}}":              "1709159490",
+		"{{now(+1w, '02/01/2006 15:04')}}": "06/03/2024 22:30",
+	}
+	for template, expected := range cases {
+		result, err := ctx.InterpolateString(template)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", template, err)
+		} else if result != expected {
+			t.Errorf("%s: expected %q, got %q", template, expected, result)
+		}
+	}
+
+	if _, err := ctx.InterpolateString("{{now(+2x)}}"); err == nil {
+		t.Errorf("Expected an error for an invalid offset")
+	}
+
+	// Builtins follow the clock instead of being fixed when they were added
+	ctx.SetClock(variables.FrozenClock(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)))
+	if date, _ := ctx.Clone().Get("date"); date != "2030-01-01" {
+		t.Errorf("Expected date from the new clock, got %v", date)
+	}
+}
+
+func TestComputedVariables(t *testing.T) {
+	ctx := variables.NewContext()
+	ctx.SetGlobal("currency", "EUR")
+	ctx.SetLocal("price", 12.5)
+	ctx.SetLocal("quantity", "4")
+	ctx.SetLocal("user", map[string]interface{}{"first": "Ada", "last": "Lovelace", "age": 36})
+	ctx.DeclareLocal("total", "{{price * quantity}}")
+	ctx.DeclareLocal("label", "{{total}} {{currency}}")
+	ctx.DeclareLocal("shipping", "{{ total >= 50 ? 0 : 4.9 }}")
+	ctx.DeclareLocal("loop", "{{loop}}")
+
+	cases := map[string]string{
+		"{{total}}":                        "50",
+		"{{label}}":                        "50 EUR",
+		"{{total + shipping}}":             "50",
+		"{{user.first + ' ' + user.last}}": "Ada Lovelace",
+		"{{ user.age >= 18 ? 'adult' : 'minor' }}": "adult",
+		"{{missing * 2}}":                          "{{missing * 2}}",
+		"{{not-defined}}":                          "{{not-defined}}",
+	}
+	for input, expected := range cases {
+		result, err := ctx.InterpolateString(input)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", input, err)
+		} else if result != expected {
+			t.Errorf("%s: expected %q, got %q", input, expected, result)
+		}
+	}
+
+	// The quantity changes after total was defined, total follows it
+	ctx.SetStep("quantity", 2)
+	if result, _ := ctx.InterpolateString("{{total}}"); result != "25" {
+		t.Errorf("Expected total to be computed when used, got %q", result)
+	}
+
+	if _, err := ctx.InterpolateString("{{loop}}"); err == nil {
+		t.Error("Expected an error for a variable computed from itself")
+	}
+	if _, err := ctx.InterpolateString("{{price / 0}}"); err == nil {
+		t.Error("Expected an error for a failing expression")
+	}
+}
+
+func TestCapturedAndDataValuesAreLiterals(t *testing.T) {
+	ctx := variables.NewContext()
+	ctx.DeclareGlobal("token", "secret-token")
+	ctx.SetLocal("name", "{{token}}")
+	ctx.SetNamespaced(variables.NamespaceCaptures, "name", "{{token}}")
+	ctx.SetStep("row", map[string]interface{}{"template": "Hello {{user.first}}"})
+
+	cases := map[string]string{
+		"{{name}}":          "{{token}}",
+		"{{captures.name}}": "{{token}}",
+		"{{row.template}}":  "Hello {{user.first}}",
+	}
+	for input, expected := range cases {
+		result, err := ctx.InterpolateString(input)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", input, err)
+		} else if result != expected {
+			t.Errorf("%s: expected %q, got %q", input, expected, result)
+		}
+	}
+
+	// A capture over a declared variable makes it literal, also in clones
+	ctx.DeclareLocal("greeting", "{{token}}")
+	ctx.SetLocal("greeting", "{{token}}")
+	if result, _ := ctx.Clone().InterpolateString("{{greeting}}"); result != "{{token}}" {
+		t.Errorf("Expected the captured greeting to stay literal, got %q", result)
+	}
+}
@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/openapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const ordersSpec = `
+openapi: 3.0.3
+info: {title: Orders, version: "1"}
+paths:
+  /orders:
+    get:
+      operationId: listOrders
+      parameters:
+        - $ref: '#/components/parameters/Limit'
+        - {name: status, in: query, schema: {type: string, enum: [open, paid]}}
+      responses:
+        "200": {description: ok}
+    post:
+      operationId: createOrder
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/NewOrder'}
+      responses:
+        "201": {description: created}
+  /orders/{id}:
+    parameters:
+      - {name: id, in: path, required: true, schema: {type: integer, minimum: 1}}
+    delete:
+      responses:
+        "204": {description: deleted}
+components:
+  parameters:
+    Limit: {name: limit, in: query, required: true, schema: {type: integer, minimum: 1, maximum: 100}}
+  schemas:
+    NewOrder:
+      type: object
+      required: [customer, items]
+      properties:
+        id: {type: string, readOnly: true}
+        customer: {type: string, format: email}
+        items:
+          type: array
+          minItems: 1
+          items: {$ref: '#/components/schemas/Item'}
+    Item:
+      type: object
+      required: [sku]
+      properties:
+        sku: {type: string, minLength: 3}
+`
+
+func TestOpenAPINegativeCases(t *testing.T) {
+	spec, err := openapi.Parse([]byte(ordersSpec))
+	require.NoError(t, err)
+
+	endpoints := spec.Endpoints()
+	require.Len(t, endpoints, 3)
+	assert.Equal(t, "listOrders", endpoints[0].Name())
+	assert.Equal(t, "DELETE /orders/{id}", endpoints[2].Name())
+
+	names := func(cases []openapi.NegativeCase) []string {
+		var names []string
+		for _, c := range cases {
+			names = append(names, c.Name)
+		}
+		return names
+	}
+
+	list := openapi.NegativeCases(endpoints[0])
+	assert.Equal(t, []string{
+		"missing required query parameter limit",
+		"query parameter limit: wrong type (text instead of integer)",
+		"query parameter limit: not an integer",
+		"query parameter limit: below minimum 1",
+		"query parameter limit: above maximum 100",
+		"query parameter status: not one of the enum values",
+	}, names(list))
+	assert.Equal(t, map[string]string{"limit": "101"}, list[4].Query)
+
+	create := openapi.NegativeCases(endpoints[1])
+	assert.Contains(t, names(create), "missing required body")
+	assert.Contains(t, names(create), "body: missing required customer")
+	assert.Contains(t, names(create), "body.customer: malformed email")
+	assert.Contains(t, names(create), "body.items: fewer than 1 items")
+	assert.Contains(t, names(create), "body.items.0.sku: shorter than 3 characters")
+	for _, c := range create {
+		if body, ok := c.Body.(map[string]interface{}); ok {
+			assert.NotContains(t, body, "id", "read-only properties are never sent")
+		}
+	}
+
+	remove := openapi.NegativeCases(endpoints[2])
+	assert.Equal(t, []string{"path parameter id: wrong type (text instead of integer)", "path parameter id: not an integer", "path parameter id: below minimum 1"}, names(remove))
+
+	_, err = openapi.Parse([]byte("swagger: \"2.0\"\npaths: {}\n"))
+	assert.ErrorContains(t, err, "only OpenAPI 3")
+	_, err = openapi.Parse([]byte("openapi: 3.0.0\npaths:\n  /a:\n    get:\n      parameters: [{$ref: '#/components/parameters/Nope'}]\n"))
+	assert.ErrorContains(t, err, "points at nothing")
+}
+
+func TestOpenAPINegativeScenarioRuns(t *testing.T) {
+	spec, err := openapi.Parse([]byte(ordersSpec))
+	require.NoError(t, err)
+
+	// Validates limit and status properly, but accepts any order body
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+		status := r.URL.Query().Get("status")
+		if err != nil || limit < 1 || limit > 100 || (status != "" && status != "open" && status != "paid") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte("[]"))
+	}))
+	defer server.Close()
+
+	endpoints := spec.Endpoints()
+	list, err := openapi.NegativeScenario(endpoints[0], server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, "Negative: listOrders", list.Name)
+	create, err := openapi.NegativeScenario(endpoints[1], server.URL+"/")
+	require.NoError(t, err)
+
+	report := runTestScenario(t, list)
+	for _, step := range report.Scenarios[0].Steps {
+		assert.Equal(t, "passed", step.Status, step.Step.Name)
+	}
+	report = runTestScenario(t, create)
+	for _, step := range report.Scenarios[0].Steps {
+		assert.Equal(t, "failed", step.Status, step.Step.Name)
+	}
+	assert.Equal(t, server.URL+"/orders", create.Steps[0].HTTP.URL)
+	assert.Equal(t, []string{"negative", "generated"}, create.Metadata.Tags)
+}
@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPauseRange(t *testing.T) {
+	min, max, err := scenario.Pause("1s-3s").Range()
+	require.NoError(t, err)
+	assert.Equal(t, time.Second, min)
+	assert.Equal(t, 3*time.Second, max)
+
+	min, max, err = scenario.Pause("500ms").Range()
+	require.NoError(t, err)
+	assert.Equal(t, 500*time.Millisecond, min)
+	assert.Equal(t, min, max)
+
+	_, _, err = scenario.Pause("3s-1s").Range()
+	assert.Error(t, err)
+	_, _, err = scenario.Pause("soon").Range()
+	assert.Error(t, err)
+}
+
+func TestThinkTimeAndPacing(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name:   "Paced",
+		Config: &scenario.ScenarioConfig{ThinkTime: "20ms-30ms", Pacing: "60ms"},
+		Data: map[string]scenario.DataSource{
+			"users": {Type: "inline", Data: []interface{}{
+				map[string]interface{}{"id": 1},
+				map[string]interface{}{"id": 2},
+			}},
+		},
+		Tests: map[string]*scenario.TestGroup{
+			"browse": {
+				DataDriven: &scenario.DataDrivenConfig{Source: "users", Variable: "user"},
+				Steps: []scenario.Step{
+					{Name: "Home", HTTP: &scenario.HTTPStep{Method: "GET", URL: server.URL + "/text"}},
+					{Name: "Profile", HTTP: &scenario.HTTPStep{Method: "GET", URL: server.URL + "/json"}, ThinkTime: "40ms"},
+				},
+			},
+		},
+	}
+	require.NoError(t, sc.Validate())
+
+	report := runTestScenario(t, sc)
+	steps := report.Scenarios[0].Steps
+	require.Len(t, steps, 4)
+
+	// The first step starts right away; the step's own think time wins over the scenario's
+	assert.GreaterOrEqual(t, steps[1].StartTime.Sub(steps[0].EndTime), 40*time.Millisecond)
+	// Iterations start at least the pacing apart, and think time still applies between them
+	assert.GreaterOrEqual(t, steps[2].StartTime.Sub(steps[0].StartTime), 60*time.Millisecond)
+	assert.GreaterOrEqual(t, steps[2].StartTime.Sub(steps[1].EndTime), 20*time.Millisecond)
+}
@@ -0,0 +1,132 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParallelRequests(t *testing.T) {
+	var mu sync.Mutex
+	var nextID int32
+	booked := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/bookings":
+			// Only one booking per seat succeeds
+			seat := body["seat"].(string)
+			mu.Lock()
+			taken := booked[seat]
+			booked[seat] = true
+			mu.Unlock()
+			if taken {
+				w.WriteHeader(http.StatusConflict)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": atomic.AddInt32(&nextID, 1), "seat": seat})
+		case "/racy":
+			// Hands out the same ID to everyone
+			json.NewEncoder(w).Encode(map[string]interface{}{"id": 7, "user": body["user"]})
+		}
+	}))
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Parallel",
+		Steps: []scenario.Step{
+			{
+				Name:     "Double booking",
+				HTTP:     &scenario.HTTPStep{Method: "POST", URL: server.URL + "/bookings", JSON: map[string]interface{}{"seat": "12A"}},
+				Parallel: &scenario.ParallelRequests{Count: 5},
+				Capture:  map[string]scenario.Capture{"booking_id": {JSONPath: "id"}},
+				Assertions: []scenario.Assertion{
+					{Type: "parallel", Field: "count", Value: 5},
+					{Type: "parallel", Field: "succeeded", Value: 1},
+					{Type: "parallel", Field: "status_counts.409", Value: 4},
+					{Type: "parallel", Field: "status_counts.500", Value: 0},
+				},
+			},
+			{
+				Name: "Different seats",
+				HTTP: &scenario.HTTPStep{Method: "POST", URL: server.URL + "/bookings", JSON: map[string]interface{}{"seat": "{{seat}}"}},
+				Parallel: &scenario.ParallelRequests{
+					Data:   []map[string]interface{}{{"seat": "1A"}, {"seat": "1B"}, {"seat": "1C"}},
+					Unique: []string{"$.id"},
+				},
+				Check:      map[string]interface{}{"status": 201},
+				Assertions: []scenario.Assertion{{Type: "parallel", Field: "succeeded", Value: 3}},
+			},
+			{
+				Name:     "Duplicate IDs",
+				HTTP:     &scenario.HTTPStep{Method: "POST", URL: server.URL + "/racy", JSON: map[string]interface{}{"user": "u{{parallel_index}}"}},
+				Parallel: &scenario.ParallelRequests{Count: 3, Unique: []string{"$.id", "$.user"}},
+				Check:    map[string]interface{}{"status": 200},
+			},
+		},
+	}
+
+	steps := runTestScenario(t, sc).Scenarios[0].Steps
+	require.Len(t, steps, 3)
+
+	assert.Equal(t, "passed", steps[0].Status, "%s %+v", steps[0].Error, steps[0].Assertions)
+	assert.Contains(t, steps[0].Variables, "booking_id")
+
+	assert.Equal(t, "passed", steps[1].Status, "%s %+v", steps[1].Error, steps[1].Assertions)
+	assert.Len(t, steps[1].Assertions, 4)
+	assert.Contains(t, steps[1].Assertions[0].Message, "copy 1: ")
+
+	assert.Equal(t, "failed", steps[2].Status)
+	assert.Equal(t, "parallel: duplicate $.id: 7 (copies 1, 2, 3)", steps[2].Error)
+}
+
+func TestParallelCopiesShareStepVariables(t *testing.T) {
+	var mu sync.Mutex
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.RawQuery)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Parallel step variables",
+		Steps: []scenario.Step{
+			{Name: "Set region", Variables: map[string]interface{}{"region": "eu"}},
+			{
+				Name:     "Copies",
+				HTTP:     &scenario.HTTPStep{URL: server.URL + "/regions?flat={{region}}&step={{step.region}}"},
+				Parallel: &scenario.ParallelRequests{Count: 2},
+				Check:    map[string]interface{}{"status": 200},
+			},
+		},
+	}
+
+	steps := runTestScenario(t, sc).Scenarios[0].Steps
+	require.Len(t, steps, 2)
+	assert.Equal(t, "passed", steps[1].Status, steps[1].Error)
+	assert.Equal(t, []string{"flat=eu&step=eu", "flat=eu&step=eu"}, queries)
+}
+
+func TestParallelValidation(t *testing.T) {
+	sc := &scenario.Scenario{
+		Name: "Invalid parallel",
+		Steps: []scenario.Step{{
+			Name:     "One copy",
+			HTTP:     &scenario.HTTPStep{URL: "/orders"},
+			Parallel: &scenario.ParallelRequests{Count: 1},
+		}},
+	}
+	assert.ErrorContains(t, sc.Validate(), "at least 2 copies")
+}
@@ -0,0 +1,68 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPathParamsAreEscaped(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		paths []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.EscapedPath()+"?"+r.URL.RawQuery)
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name:      "Path params",
+		Variables: map[string]interface{}{"file": "reports/2024 q1.pdf"},
+		Steps: []scenario.Step{
+			{
+				Name: "Colon style",
+				HTTP: &scenario.HTTPStep{
+					URL:        server.URL + "/users/:id/files/:file",
+					PathParams: map[string]string{"id": "a/b?c#d", "file": "{{file}}"},
+					Query:      scenario.Params{"at": "10:30"},
+				},
+			},
+			{
+				Name: "Brace style",
+				HTTP: &scenario.HTTPStep{
+					URL:        server.URL + "/users/{id}?keep={id}",
+					PathParams: map[string]string{"id": "x y"},
+				},
+			},
+			{
+				Name: "Unused parameter",
+				HTTP: &scenario.HTTPStep{
+					URL:        server.URL + "/users",
+					PathParams: map[string]string{"id": "1"},
+				},
+			},
+		},
+	}
+	require.NoError(t, sc.Validate())
+
+	report := runTestScenario(t, sc)
+	steps := report.Scenarios[0].Steps
+	require.Len(t, steps, 3)
+	assert.Equal(t, "passed", steps[0].Status, steps[0].Error)
+	assert.Equal(t, "passed", steps[1].Status, steps[1].Error)
+	assert.Equal(t, "failed", steps[2].Status)
+	assert.Contains(t, steps[2].Error, "path parameter id has no")
+
+	assert.Equal(t, []string{
+		"/users/a%2Fb%3Fc%23d/files/reports%2F2024%20q1.pdf?at=10%3A30",
+		"/users/x%20y?keep={id}",
+	}, paths)
+}
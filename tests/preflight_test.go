@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/execution"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/nulln0ne/fuego/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+func TestPreflightFailureStopsRun(t *testing.T) {
+	var scenarioRequests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/health":
+			w.WriteHeader(http.StatusOK)
+		case "/ready":
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			atomic.AddInt32(&scenarioRequests, 1)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		Global: config.GlobalConfig{BaseURL: server.URL},
+		Preflight: []config.PreflightCheck{
+			{URL: "/health"},
+			{Name: "Ready", URL: "/ready", Status: 200},
+		},
+	}
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "json", OutputFile: os.DevNull})
+	engine := execution.NewEngine(cfg, reporter)
+
+	sc := &scenario.Scenario{
+		Name:  "Never run",
+		Steps: []scenario.Step{{Name: "Request", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}}},
+	}
+	err := engine.ExecuteScenarios([]*scenario.Scenario{sc})
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, execution.ErrEnvironmentUnavailable), err.Error())
+	assert.Contains(t, err.Error(), "Ready")
+
+	report := reporter.GetReport()
+	assert.Equal(t, reporting.StatusEnvironmentUnavailable, report.Status)
+	assert.Empty(t, report.Scenarios)
+	require.Len(t, report.Preflight, 2)
+	assert.True(t, report.Preflight[0].Passed)
+	assert.Equal(t, "/health", report.Preflight[0].Name)
+	assert.False(t, report.Preflight[1].Passed)
+	assert.Equal(t, 503, report.Preflight[1].StatusCode)
+	assert.Zero(t, atomic.LoadInt32(&scenarioRequests))
+}
+
+func TestPreflightPassRunsScenarios(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	cfg := &config.Config{Preflight: []config.PreflightCheck{{URL: server.URL + "/json"}}}
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "json", OutputFile: os.DevNull})
+	engine := execution.NewEngine(cfg, reporter)
+
+	sc := &scenario.Scenario{
+		Name:  "Runs",
+		Steps: []scenario.Step{{Name: "Request", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}}},
+	}
+	require.NoError(t, engine.ExecuteScenarios([]*scenario.Scenario{sc}))
+
+	report := reporter.GetReport()
+	assert.Empty(t, report.Status)
+	assert.Len(t, report.Scenarios, 1)
+}
+
+func TestPreflightAcceptsBareURLs(t *testing.T) {
+	content := []byte(`
+preflight:
+  - https://api.example.com/health
+  - name: Auth
+    url: /auth/health
+    status: 204
+`)
+	var cfg config.Config
+	require.NoError(t, yaml.Unmarshal(content, &cfg))
+	require.Len(t, cfg.Preflight, 2)
+	assert.Equal(t, "https://api.example.com/health", cfg.Preflight[0].URL)
+	assert.Equal(t, 204, cfg.Preflight[1].Status)
+
+	var doc map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(content, &doc))
+	result, err := gojsonschema.Validate(gojsonschema.NewGoLoader(schema.Config()), gojsonschema.NewGoLoader(doc))
+	require.NoError(t, err)
+	assert.True(t, result.Valid(), "schema errors: %v", result.Errors())
+}
@@ -0,0 +1,244 @@
+package tests
+
+import (
+	"encoding/binary"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/protobuf"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const orderProto = `
+syntax = "proto3";
+package shop.v1;
+
+import "common.proto";
+
+// An order as returned by the orders service
+message Order {
+  enum Status {
+    STATUS_UNSPECIFIED = 0;
+    PENDING = 1;
+    SHIPPED = 2 [deprecated = true];
+  }
+  message Line {
+    string sku = 1;
+    uint32 quantity = 2;
+  }
+
+  int64 id = 1;
+  Status status = 2;
+  repeated Line lines = 3;
+  repeated sint32 adjustments = 4 [packed = true];
+  map<string, string> labels = 5;
+  double total = 6;
+  common.Money paid = 7;
+  oneof contact {
+    string email = 8;
+    string phone = 9;
+  }
+  bool gift = 10;
+  /* not set in the payloads below */
+  string note = 11;
+}
+`
+
+const commonProto = `
+syntax = "proto3";
+package common;
+
+message Money {
+  string currency = 1;
+  int64 cents = 2;
+}
+`
+
+// protoWriter encodes the few wire types the tests need
+type protoWriter []byte
+
+func (w *protoWriter) key(number, wireType int) {
+	*w = binary.AppendUvarint(*w, uint64(number<<3|wireType))
+}
+
+func (w *protoWriter) varint(number int, value uint64) {
+	w.key(number, 0)
+	*w = binary.AppendUvarint(*w, value)
+}
+
+func (w *protoWriter) bytes(number int, value []byte) {
+	w.key(number, 2)
+	*w = binary.AppendUvarint(*w, uint64(len(value)))
+	*w = append(*w, value...)
+}
+
+func (w *protoWriter) double(number int, value float64) {
+	w.key(number, 1)
+	*w = binary.LittleEndian.AppendUint64(*w, math.Float64bits(value))
+}
+
+func encodedOrder() []byte {
+	var line, entry, paid, packed, order protoWriter
+	line.bytes(1, []byte("BOOK-1"))
+	line.varint(2, 2)
+	entry.bytes(1, []byte("channel"))
+	entry.bytes(2, []byte("web"))
+	paid.bytes(1, []byte("EUR"))
+	paid.varint(2, 2599)
+	packed = binary.AppendUvarint(packed, 3) // sint32 -2
+	packed = binary.AppendUvarint(packed, 10)
+
+	order.varint(1, 42)
+	order.varint(2, 1)
+	order.bytes(3, line)
+	order.bytes(4, packed)
+	order.bytes(5, entry)
+	order.double(6, 25.99)
+	order.bytes(7, paid)
+	order.bytes(8, []byte("ada@example.com"))
+	return order
+}
+
+func writeProtos(t *testing.T) string {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "order.proto"), []byte(orderProto), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "common.proto"), []byte(commonProto), 0644))
+	return filepath.Join(dir, "order.proto")
+}
+
+func TestDecodeProtobuf(t *testing.T) {
+	schema, err := protobuf.Load(writeProtos(t))
+	require.NoError(t, err)
+	message, err := schema.Message("Order")
+	require.NoError(t, err)
+	assert.Equal(t, "shop.v1.Order", message.Name)
+
+	decoded, err := schema.Decode(message, encodedOrder())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"id":          int64(42),
+		"status":      "PENDING",
+		"lines":       []interface{}{map[string]interface{}{"sku": "BOOK-1", "quantity": uint64(2)}},
+		"adjustments": []interface{}{int64(-2), int64(5)},
+		"labels":      map[string]interface{}{"channel": "web"},
+		"total":       25.99,
+		"paid":        map[string]interface{}{"currency": "EUR", "cents": int64(2599)},
+		"email":       "ada@example.com",
+		"gift":        false,
+		"note":        "",
+	}, decoded)
+
+	var unknown protoWriter
+	unknown.varint(99, 1)
+	_, err = schema.Decode(message, unknown)
+	assert.ErrorContains(t, err, "unknown field number 99")
+
+	var mismatched protoWriter
+	mismatched.bytes(1, []byte("not an int"))
+	_, err = schema.Decode(message, mismatched)
+	assert.ErrorContains(t, err, "wire type 2 does not match type int64")
+}
+
+func TestDecodeProtobufWellKnownTypes(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "event.proto")
+	require.NoError(t, os.WriteFile(path, []byte(`
+syntax = "proto3";
+package events;
+
+import "google/protobuf/timestamp.proto";
+
+message Event {
+  string name = 1;
+  google.protobuf.Timestamp at = 2;
+}
+`), 0644))
+
+	schema, err := protobuf.Load(path)
+	require.NoError(t, err)
+	message, err := schema.Message("Event")
+	require.NoError(t, err)
+
+	var at, event protoWriter
+	at.varint(1, 1700000000)
+	event.bytes(1, []byte("created"))
+	event.bytes(2, at)
+	decoded, err := schema.Decode(message, event)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"name": "created",
+		"at":   map[string]interface{}{"seconds": int64(1700000000), "nanos": int64(0)},
+	}, decoded)
+
+	_, err = schema.Message("Missing")
+	assert.ErrorContains(t, err, "message Missing is not defined")
+}
+
+func TestProtobufResponses(t *testing.T) {
+	protoFile := writeProtos(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := encodedOrder()
+		switch r.URL.Path {
+		case "/grpc":
+			w.Header().Set("Content-Type", "application/grpc-web+proto")
+			frame := append([]byte{0}, binary.BigEndian.AppendUint32(nil, uint32(len(body)))...)
+			body = append(frame, body...)
+		case "/broken":
+			w.Header().Set("Content-Type", "application/x-protobuf")
+			body = body[:len(body)-3]
+		default:
+			w.Header().Set("Content-Type", "application/x-protobuf")
+		}
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	decode := &scenario.ProtobufResponse{File: protoFile, Message: "shop.v1.Order"}
+	sc := &scenario.Scenario{
+		Name: "Protobuf",
+		Steps: []scenario.Step{
+			{
+				Name:  "HTTP+protobuf",
+				HTTP:  &scenario.HTTPStep{URL: server.URL + "/orders/42", Protobuf: decode},
+				Check: map[string]interface{}{"status": 200},
+				Assertions: []scenario.Assertion{
+					{Type: "json_path", Field: "status", Operator: "eq", Value: "PENDING"},
+					{Type: "json_path", Field: "lines.0.sku", Operator: "eq", Value: "BOOK-1"},
+					{Type: "json_path", Field: "paid.cents", Operator: "eq", Value: 2599},
+				},
+				Capture: map[string]scenario.Capture{"order_id": {JSONPath: "id"}},
+			},
+			{
+				Name: "gRPC-Web",
+				HTTP: &scenario.HTTPStep{URL: server.URL + "/grpc", Protobuf: decode},
+				Assertions: []scenario.Assertion{
+					{Type: "json_path", Field: "labels.channel", Operator: "eq", Value: "web"},
+				},
+			},
+			{
+				Name: "Truncated",
+				HTTP: &scenario.HTTPStep{URL: server.URL + "/broken", Protobuf: decode},
+			},
+		},
+	}
+	require.NoError(t, sc.Validate())
+
+	report := runTestScenario(t, sc)
+	steps := report.Scenarios[0].Steps
+	require.Len(t, steps, 3)
+	for _, step := range steps[:2] {
+		assert.Equal(t, "passed", step.Status, step.Step.Name+": "+step.Error)
+		for _, result := range step.Assertions {
+			assert.True(t, result.Passed, result.Message)
+		}
+	}
+	assert.EqualValues(t, 42, report.Scenarios[0].Variables["order_id"])
+	assert.Equal(t, "failed", steps[2].Status)
+	assert.Contains(t, steps[2].Error, "response does not decode as shop.v1.Order")
+}
@@ -0,0 +1,65 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/record"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorderWritesDeduplicatedScenario(t *testing.T) {
+	upstream := setupTestServer()
+	defer upstream.Close()
+
+	recorder := record.NewRecorder(record.Options{DropHeaders: []string{"X-Session"}})
+	proxy := httptest.NewServer(recorder)
+	defer proxy.Close()
+
+	proxyURL, err := url.Parse(proxy.URL)
+	require.NoError(t, err)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	send := func(method, path, body string) *http.Response {
+		req, err := http.NewRequest(method, upstream.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("X-Session", "secret")
+		req.Header.Set("X-Trace", "abc")
+		if body != "" {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := send("GET", "/json", "")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "fuego-test", resp.Header.Get("X-Test-Header"))
+	send("GET", "/json", "")
+	send("POST", "/user/123", `{"name": "fuego"}`)
+	send("GET", "/missing", "")
+
+	exchanges := recorder.Exchanges()
+	require.Len(t, exchanges, 3, "identical requests are recorded once")
+	assert.Equal(t, "abc", exchanges[0].Headers["X-Trace"])
+	assert.NotContains(t, exchanges[0].Headers, "X-Session")
+	assert.NotContains(t, exchanges[0].Headers, "User-Agent")
+	assert.Equal(t, http.StatusNotFound, exchanges[2].Status)
+
+	sc, err := recorder.Scenario("Recorded").Build()
+	require.NoError(t, err)
+	require.Len(t, sc.Steps, 3)
+	assert.Equal(t, "POST", sc.Steps[1].HTTP.Method)
+	assert.Equal(t, map[string]interface{}{"name": "fuego"}, sc.Steps[1].HTTP.JSON)
+	assert.Equal(t, 404, sc.Steps[2].Check["status"])
+
+	report := runTestScenario(t, sc)
+	require.Len(t, report.Scenarios, 1)
+	assert.Equal(t, "passed", report.Scenarios[0].Status)
+}
@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/execution"
+	"github.com/nulln0ne/fuego/pkg/objectstore"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// uploadServer records the uploads it receives by request URI
+type uploadServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	uploads map[string]*http.Request
+	bodies  map[string]string
+}
+
+func newUploadServer(t *testing.T) *uploadServer {
+	s := &uploadServer{uploads: map[string]*http.Request{}, bodies: map[string]string{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.uploads[r.URL.RequestURI()], s.bodies[r.URL.RequestURI()] = r, string(body)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func storageReport() *reporting.Report {
+	return &reporting.Report{
+		Summary: reporting.Summary{Total: 1, Passed: 1},
+		Scenarios: []reporting.ScenarioResult{{
+			Scenario: &scenario.Scenario{Name: "Upload"},
+			Status:   "passed",
+		}},
+	}
+}
+
+func TestReportUploadsToS3(t *testing.T) {
+	server := newUploadServer(t)
+
+	err := reporting.WriteReport(storageReport(), reporting.ReportConfig{
+		Format:     "json",
+		OutputFile: "s3://ci-reports/runs/42/report.json",
+		Storage: objectstore.Credentials{S3: objectstore.S3Credentials{
+			AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret", Region: "eu-west-1", Endpoint: server.URL,
+		}},
+	})
+	require.NoError(t, err)
+
+	req := server.uploads["/ci-reports/runs/42/report.json"]
+	require.NotNil(t, req, "uploads: %v", server.uploads)
+	assert.Equal(t, http.MethodPut, req.Method)
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.True(t, strings.HasPrefix(req.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"))
+
+	var uploaded reporting.Report
+	require.NoError(t, json.Unmarshal([]byte(server.bodies["/ci-reports/runs/42/report.json"]), &uploaded))
+	assert.Equal(t, 1, uploaded.Summary.Passed)
+	assert.NotContains(t, server.bodies["/ci-reports/runs/42/report.json"], "secret")
+}
+
+func TestReportUploadsToGCSWithServiceAccount(t *testing.T) {
+	server := newUploadServer(t)
+
+	var assertion string
+	tokens := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "urn:ietf:params:oauth:grant-type:jwt-bearer", r.FormValue("grant_type"))
+		assertion = r.FormValue("assertion")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"ya29.token","token_type":"Bearer","expires_in":3600}`))
+	}))
+	defer tokens.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "service-account.json")
+	keyJSON, _ := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "ci@project.iam.gserviceaccount.com",
+		"private_key":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"token_uri":    tokens.URL,
+	})
+	require.NoError(t, os.WriteFile(keyFile, keyJSON, 0600))
+
+	err = reporting.WriteReport(storageReport(), reporting.ReportConfig{
+		Format:     "junit",
+		OutputFile: "gs://ci-reports/nightly/junit.xml",
+		Storage:    objectstore.Credentials{GCS: objectstore.GCSCredentials{KeyFile: keyFile, Endpoint: server.URL}},
+	})
+	require.NoError(t, err)
+
+	req := server.uploads["/upload/storage/v1/b/ci-reports/o?uploadType=media&name=nightly%2Fjunit.xml"]
+	require.NotNil(t, req, "uploads: %v", server.uploads)
+	assert.Equal(t, http.MethodPost, req.Method)
+	assert.Equal(t, "Bearer ya29.token", req.Header.Get("Authorization"))
+	assert.Equal(t, "application/xml", req.Header.Get("Content-Type"))
+
+	parts := strings.Split(assertion, ".")
+	require.Len(t, parts, 3)
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	assert.Contains(t, string(claims), `"iss":"ci@project.iam.gserviceaccount.com"`)
+	assert.Contains(t, string(claims), "devstorage.read_write")
+}
+
+func TestJSONLReportUploadsToAzureWhenRunFinishes(t *testing.T) {
+	server := newUploadServer(t)
+	target := setupTestServer()
+	defer target.Close()
+
+	reporter := reporting.NewReporter(reporting.ReportConfig{
+		Format:     "jsonl",
+		OutputFile: "azblob://reports/runs/results.jsonl",
+		Storage: objectstore.Credentials{Azure: objectstore.AzureCredentials{
+			Account: "fuegoci", Key: base64.StdEncoding.EncodeToString([]byte("account-key")), Endpoint: server.URL + "/fuegoci",
+		}},
+	})
+	scenarios := []*scenario.Scenario{{
+		Name:  "Upload",
+		Steps: []scenario.Step{{Name: "Get", HTTP: &scenario.HTTPStep{URL: target.URL + "/json"}}},
+	}}
+	require.NoError(t, execution.NewEngine(&config.Config{}, reporter).ExecuteScenarios(scenarios))
+
+	req := server.uploads["/fuegoci/reports/runs/results.jsonl"]
+	require.NotNil(t, req, "uploads: %v", server.uploads)
+	assert.Equal(t, http.MethodPut, req.Method)
+	assert.Equal(t, "BlockBlob", req.Header.Get("x-ms-blob-type"))
+	assert.Equal(t, "application/x-ndjson", req.Header.Get("x-ms-blob-content-type"))
+	assert.True(t, strings.HasPrefix(req.Header.Get("Authorization"), "SharedKey fuegoci:"), req.Header.Get("Authorization"))
+	_, err := time.Parse(http.TimeFormat, req.Header.Get("x-ms-date"))
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(server.bodies["/fuegoci/reports/runs/results.jsonl"]), "\n")
+	assert.Len(t, lines, 5)
+}
+
+func TestReportUploadFailureIsReported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	err := reporting.WriteReport(storageReport(), reporting.ReportConfig{
+		Format:     "markdown",
+		OutputFile: "azblob://reports/report.md",
+		Storage:    objectstore.Credentials{Azure: objectstore.AzureCredentials{SASToken: "?sv=2020-10-02&sig=abc", Endpoint: server.URL}},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "403 Forbidden")
+	assert.Contains(t, err.Error(), "AccessDenied")
+}
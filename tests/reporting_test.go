@@ -0,0 +1,674 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/execution"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubAnnotationsPointAtFailingStep(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	dir := t.TempDir()
+	scenarioPath := filepath.Join(dir, "annotated.yaml")
+	content := `name: Annotated
+steps:
+  - name: Healthy
+    http:
+      url: ` + server.URL + `/json
+    check:
+      status: 200
+  - name: Broken
+    http:
+      url: ` + server.URL + `/json
+    check:
+      status: 418
+`
+	assert.NoError(t, os.WriteFile(scenarioPath, []byte(content), 0644))
+
+	sc, err := scenario.LoadScenario(scenarioPath)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, sc.Steps[0].Line)
+	assert.Equal(t, 8, sc.Steps[1].Line)
+
+	t.Setenv("GITHUB_STEP_SUMMARY", "")
+	outputPath := filepath.Join(dir, "annotations.txt")
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "github", OutputFile: outputPath})
+	engine := execution.NewEngine(&config.Config{}, reporter)
+	assert.NoError(t, engine.ExecuteScenarios([]*scenario.Scenario{sc}))
+
+	output, err := os.ReadFile(outputPath)
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	assert.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "::error file="))
+	assert.Contains(t, lines[0], "annotated.yaml,line=8,title=Annotated / Broken::")
+	assert.Contains(t, lines[1], "1 failed")
+}
+
+func TestProgressShowsBarPerScenario(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	scenarios := []*scenario.Scenario{
+		{Name: "Healthy", Steps: []scenario.Step{
+			{Name: "One", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}},
+			{Name: "Two", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}},
+		}},
+		{Name: "Broken", Steps: []scenario.Step{
+			{Name: "Teapot", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}, Check: map[string]interface{}{"status": 418}},
+		}},
+	}
+
+	var out bytes.Buffer
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "none"})
+	engine := execution.NewEngineWithOptions(&config.Config{}, reporter, execution.Options{Progress: reporting.NewProgress(&out)})
+	assert.NoError(t, engine.ExecuteScenarios(scenarios))
+
+	var finished []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if idx := strings.LastIndex(line, "\r\033[K"); idx >= 0 && line != "" {
+			finished = append(finished, line[idx:])
+		}
+	}
+	if assert.Len(t, finished, 2) {
+		assert.Contains(t, finished[0], "[1/2]")
+		assert.Contains(t, finished[0], "Healthy")
+		assert.Contains(t, finished[0], "✓ 2")
+		assert.Contains(t, finished[1], "[2/2]")
+		assert.Contains(t, finished[1], "✗ 1")
+	}
+	assert.Contains(t, out.String(), "2/2", "the bar counts steps of the running scenario")
+}
+
+// captureStdout returns what fn prints to standard output
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	assert.NoError(t, err)
+
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestQuietAndCIConsoleOutput(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	scenarios := []*scenario.Scenario{
+		{Name: "Zeta", Steps: []scenario.Step{
+			{Name: "Healthy", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}},
+		}},
+		{Name: "Alpha", Steps: []scenario.Step{
+			{Name: "Fine", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}},
+			{Name: "Teapot", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}, Check: map[string]interface{}{"status": 418}},
+		}},
+	}
+
+	run := func(cfg reporting.ReportConfig) string {
+		cfg.Format = "console"
+		return captureStdout(t, func() {
+			engine := execution.NewEngine(&config.Config{}, reporting.NewReporter(cfg))
+			assert.NoError(t, engine.ExecuteScenarios(scenarios))
+		})
+	}
+
+	ci := run(reporting.ReportConfig{CI: true, Verbose: true})
+	assert.NotContains(t, ci, "✓")
+	assert.NotContains(t, ci, "✗")
+	assert.Contains(t, ci, "FAIL Alpha")
+	assert.Contains(t, ci, "PASS Fine")
+	assert.Contains(t, ci, "Started: ")
+	assert.Less(t, strings.Index(ci, "Alpha"), strings.Index(ci, "Zeta"), "scenarios are sorted by name")
+	assert.Regexp(t, `\[\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}\.\d{3}Z\] FAIL Alpha`, ci)
+
+	quiet := run(reporting.ReportConfig{Quiet: true})
+	assert.Contains(t, quiet, "Failed: 1")
+	assert.Contains(t, quiet, "✗ Alpha")
+	assert.Contains(t, quiet, "✗ Teapot")
+	assert.NotContains(t, quiet, "Fine")
+	assert.NotContains(t, quiet, "Zeta")
+}
+
+func TestMultipleReportOutputs(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	sc := &scenario.Scenario{Name: "Orders", Steps: []scenario.Step{
+		{Name: "List", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}},
+		{Name: "Teapot", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}, Check: map[string]interface{}{"status": 418}},
+	}}
+
+	dir := t.TempDir()
+	var outputs []reporting.ReportOutput
+	for _, spec := range []string{"json=" + filepath.Join(dir, "out.json"), "junit=" + filepath.Join(dir, "junit.xml")} {
+		output, err := reporting.ParseReportOutput(spec)
+		assert.NoError(t, err)
+		outputs = append(outputs, output)
+	}
+	_, err := reporting.ParseReportOutput("pdf=out.pdf")
+	assert.Error(t, err)
+
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "none", Outputs: outputs})
+	assert.NoError(t, execution.NewEngine(&config.Config{}, reporter).ExecuteScenarios([]*scenario.Scenario{sc}))
+
+	data, err := os.ReadFile(filepath.Join(dir, "out.json"))
+	assert.NoError(t, err)
+	var report reporting.Report
+	assert.NoError(t, json.Unmarshal(data, &report))
+	assert.Equal(t, 1, report.Summary.Failed)
+
+	data, err = os.ReadFile(filepath.Join(dir, "junit.xml"))
+	assert.NoError(t, err)
+	var junit struct {
+		Tests    int `xml:"tests,attr"`
+		Failures int `xml:"failures,attr"`
+		Suites   []struct {
+			Name  string `xml:"name,attr"`
+			Cases []struct {
+				Name    string    `xml:"name,attr"`
+				Failure *struct{} `xml:"failure"`
+			} `xml:"testcase"`
+		} `xml:"testsuite"`
+	}
+	assert.NoError(t, xml.Unmarshal(data, &junit))
+	assert.Equal(t, 2, junit.Tests)
+	assert.Equal(t, 1, junit.Failures)
+	if assert.Len(t, junit.Suites, 1) && assert.Len(t, junit.Suites[0].Cases, 2) {
+		assert.Equal(t, "Orders", junit.Suites[0].Name)
+		assert.Nil(t, junit.Suites[0].Cases[0].Failure)
+		assert.NotNil(t, junit.Suites[0].Cases[1].Failure)
+	}
+}
+
+func TestReportTemplate(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	dir := t.TempDir()
+	templatePath := filepath.Join(dir, "summary.tmpl")
+	content := `h1. API run: {{.Summary.Passed}}/{{.Summary.Total}} passed ({{percent .Summary.PassRate}})
+{{range .Scenarios}}* {{icon .Status}} {{.Scenario.Name | upper}}
+{{end}}{{range .Failures}}- {{.}}
+{{end}}`
+	assert.NoError(t, os.WriteFile(templatePath, []byte(content), 0644))
+
+	scenarios := []*scenario.Scenario{
+		{Name: "Orders", Steps: []scenario.Step{{Name: "List", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}}}},
+		{Name: "Users", Steps: []scenario.Step{
+			{Name: "Teapot", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}, Check: map[string]interface{}{"status": 418}},
+		}},
+	}
+
+	outputPath := filepath.Join(dir, "summary.txt")
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "template", Template: templatePath, OutputFile: outputPath})
+	assert.NoError(t, execution.NewEngine(&config.Config{}, reporter).ExecuteScenarios(scenarios))
+
+	output, err := os.ReadFile(outputPath)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if assert.Len(t, lines, 4) {
+		assert.Equal(t, "h1. API run: 1/2 passed (50.0%)", lines[0])
+		assert.Equal(t, "* ✓ ORDERS", lines[1])
+		assert.Equal(t, "* ✗ USERS", lines[2])
+		assert.Contains(t, lines[3], "- Users")
+	}
+
+	assert.NoError(t, os.WriteFile(templatePath, []byte("{{.Summary.Passed"), 0644))
+	_, err = reporting.ParseReportTemplate(templatePath)
+	assert.Error(t, err)
+}
+
+func TestWebhookSinkPostsResults(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	var mu sync.Mutex
+	var events []string
+	var auth []string
+	var final reporting.Report
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, r.Header.Get("X-Fuego-Event"))
+		auth = append(auth, r.Header.Get("Authorization"))
+		if r.Header.Get("X-Fuego-Event") == "run" {
+			json.NewDecoder(r.Body).Decode(&final)
+		}
+	}))
+	defer hook.Close()
+
+	t.Setenv("DASHBOARD_TOKEN", "secret")
+	cfg := &config.Config{Webhooks: []config.WebhookConfig{{
+		URL:     hook.URL,
+		Headers: map[string]string{"Authorization": "Bearer ${DASHBOARD_TOKEN}"},
+		Events:  []string{"scenario", "run"},
+	}}}
+
+	scenarios := []*scenario.Scenario{
+		{Name: "Orders", Steps: []scenario.Step{{Name: "List", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}}}},
+		{Name: "Users", Steps: []scenario.Step{{Name: "List", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}}}},
+	}
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "none"})
+	assert.NoError(t, execution.NewEngine(cfg, reporter).ExecuteScenarios(scenarios))
+
+	assert.Equal(t, []string{"scenario", "scenario", "run"}, events)
+	assert.Equal(t, []string{"Bearer secret", "Bearer secret", "Bearer secret"}, auth)
+	assert.Equal(t, 2, final.Summary.Passed)
+
+	hook.Close()
+	reporter = reporting.NewReporter(reporting.ReportConfig{Format: "none"})
+	err := execution.NewEngine(&config.Config{Webhooks: []config.WebhookConfig{{URL: hook.URL}}}, reporter).ExecuteScenarios(scenarios[:1])
+	assert.ErrorContains(t, err, "failed to deliver results")
+}
+
+func TestFailuresGroupedByOwnerAndSeverity(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	failing := func(name string, labels map[string]string) *scenario.Scenario {
+		return &scenario.Scenario{
+			Name:     name,
+			Metadata: scenario.ScenarioMetadata{Labels: labels},
+			Steps: []scenario.Step{
+				{Name: "Teapot", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}, Check: map[string]interface{}{"status": 418}},
+			},
+		}
+	}
+	scenarios := []*scenario.Scenario{
+		failing("Refund", map[string]string{"owner": "payments-team", "component": "refunds", "severity": "minor"}),
+		failing("Anonymous", nil),
+		failing("Login", map[string]string{"owner": "identity", "severity": "high"}),
+		failing("Pay", map[string]string{"owner": "payments-team", "component": "checkout", "severity": "critical"}),
+	}
+
+	var report *reporting.Report
+	output := captureStdout(t, func() {
+		reporter := reporting.NewReporter(reporting.ReportConfig{Format: "console"})
+		assert.NoError(t, execution.NewEngine(&config.Config{}, reporter).ExecuteScenarios(scenarios))
+		report = reporter.GetReport()
+	})
+
+	var order []string
+	for _, failure := range report.FailedScenarios {
+		order = append(order, failure.Scenario)
+	}
+	assert.Equal(t, []string{"Pay", "Login", "Refund", "Anonymous"}, order)
+	assert.Equal(t, "checkout", report.FailedScenarios[0].Component)
+	assert.Contains(t, report.FailedScenarios[0].Failures[0], "Pay / Teapot")
+
+	owners, groups := reporting.FailuresByOwner(report.FailedScenarios)
+	assert.Equal(t, []string{"payments-team", "identity", "unowned"}, owners)
+	assert.Len(t, groups["payments-team"], 2)
+
+	section := output[strings.Index(output, "=== Failures by Owner ==="):]
+	assert.Less(t, strings.Index(section, "payments-team"), strings.Index(section, "identity"))
+	assert.Contains(t, section, "✗ [critical] checkout: Pay")
+}
+
+func TestSeverityGateBlocksOnlyOnCriticalFailures(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	failing := func(name, severity string) *scenario.Scenario {
+		return &scenario.Scenario{
+			Name:     name,
+			Metadata: scenario.ScenarioMetadata{Labels: map[string]string{"severity": severity}},
+			Steps: []scenario.Step{
+				{Name: "Teapot", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}, Check: map[string]interface{}{"status": 418}},
+			},
+		}
+	}
+
+	run := func(failOn string, scenarios ...*scenario.Scenario) *reporting.Reporter {
+		reporter := reporting.NewReporter(reporting.ReportConfig{Format: "none", FailOn: failOn})
+		assert.NoError(t, execution.NewEngine(&config.Config{}, reporter).ExecuteScenarios(scenarios))
+		return reporter
+	}
+
+	reporter := run("critical", failing("Refund", "minor"), failing("Search", ""))
+	blocking, warnings := reporter.GetReport().SeverityGate()
+	assert.Empty(t, blocking)
+	assert.Len(t, warnings, 2)
+	summary := reporter.Summary()
+	assert.True(t, summary.Passed)
+	assert.Contains(t, summary.Warnings, "Refund failed (severity minor)")
+	assert.Contains(t, summary.Warnings, "Search failed (severity none)")
+
+	reporter = run("critical", failing("Refund", "minor"), failing("Pay", "Blocker"))
+	blocking, warnings = reporter.GetReport().SeverityGate()
+	if assert.Len(t, blocking, 1) {
+		assert.Equal(t, "Pay", blocking[0].Scenario)
+	}
+	assert.Len(t, warnings, 1)
+	assert.False(t, reporter.Summary().Passed)
+
+	// Without fail_on every failure blocks
+	reporter = run("", failing("Refund", "minor"))
+	blocking, _ = reporter.GetReport().SeverityGate()
+	assert.Len(t, blocking, 1)
+	assert.False(t, reporter.Summary().Passed)
+	assert.Empty(t, reporter.Summary().Warnings)
+
+	assert.True(t, reporting.IsSeverity("Critical"))
+	assert.False(t, reporting.IsSeverity("urgent"))
+}
+
+func TestReporterEventStream(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "none"})
+	var events []string
+	reporter.Subscribe(func(event reporting.Event) {
+		entry := string(event.Type)
+		if event.Step != nil {
+			entry += ":" + event.Step.Step.Name + ":" + event.Step.Status
+		} else if event.Scenario != "" {
+			entry += ":" + event.Scenario
+		}
+		events = append(events, entry)
+	})
+
+	scenarios := []*scenario.Scenario{{
+		Name: "Stream",
+		Steps: []scenario.Step{
+			{Name: "Ok", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}, Check: map[string]interface{}{"status": 200}},
+			{Name: "Teapot", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}, Check: map[string]interface{}{"status": 418}},
+		},
+	}}
+	assert.NoError(t, execution.NewEngine(&config.Config{}, reporter).ExecuteScenarios(scenarios))
+
+	assert.Equal(t, []string{
+		"run_started",
+		"scenario_started:Stream",
+		"step_finished:Ok:passed",
+		"step_finished:Teapot:failed",
+		"scenario_finished:Stream",
+		"run_finished",
+	}, events)
+}
+
+func TestReporterConcurrentScenarioResults(t *testing.T) {
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "none"})
+	var mu sync.Mutex
+	finished := 0
+	reporter.Subscribe(func(event reporting.Event) {
+		if event.Type == reporting.EventScenarioFinished {
+			mu.Lock()
+			finished++
+			mu.Unlock()
+		}
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			status := "passed"
+			if i%5 == 0 {
+				status = "failed"
+			}
+			reporter.AddScenarioResult(reporting.ScenarioResult{
+				Scenario: &scenario.Scenario{Name: fmt.Sprintf("Scenario %d", i)},
+				Status:   status,
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	assert.NoError(t, reporter.GenerateReport())
+	report := reporter.GetReport()
+	assert.Equal(t, 50, finished)
+	assert.Equal(t, 50, report.Summary.Total)
+	assert.Equal(t, 10, report.Summary.Failed)
+}
+
+func TestStepTimingBreakdown(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"id": 7}`))
+	}))
+	defer server.Close()
+
+	scenarios := []*scenario.Scenario{{
+		Name: "Timed",
+		Steps: []scenario.Step{{
+			Name:    "Slow server",
+			HTTP:    &scenario.HTTPStep{URL: server.URL + "/items/{{item}}"},
+			Check:   map[string]interface{}{"status": 200},
+			Capture: map[string]scenario.Capture{"id": {JSONPath: "$.id"}},
+		}},
+		Variables: map[string]interface{}{"item": 7},
+	}}
+
+	var report *reporting.Report
+	output := captureStdout(t, func() {
+		reporter := reporting.NewReporter(reporting.ReportConfig{Format: "console", Verbose: true})
+		assert.NoError(t, execution.NewEngine(&config.Config{}, reporter).ExecuteScenarios(scenarios))
+		report = reporter.GetReport()
+	})
+
+	step := report.Scenarios[0].Steps[0]
+	if assert.NotNil(t, step.Timing) {
+		assert.GreaterOrEqual(t, step.Timing.Request, 20*time.Millisecond)
+		assert.Less(t, step.Timing.Interpolation+step.Timing.Assertions+step.Timing.Captures, step.Timing.Request)
+		assert.LessOrEqual(t, step.Timing.Interpolation+step.Timing.Request+step.Timing.Assertions+step.Timing.Captures, step.Duration)
+	}
+	assert.Contains(t, output, "Timing: interpolation ")
+
+	data, err := json.Marshal(step)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"timing":{"interpolation":`)
+}
+
+func TestJSONLReportStreamsWithoutBodies(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	scenarios := []*scenario.Scenario{{
+		Name: "Stream",
+		Steps: []scenario.Step{
+			{Name: "First", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}},
+			{Name: "Second", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}, Check: map[string]interface{}{"status": 418}},
+		},
+	}}
+
+	path := filepath.Join(t.TempDir(), "results.jsonl")
+	reporter := reporting.NewReporter(reporting.ReportConfig{
+		Format:  "none",
+		Outputs: []reporting.ReportOutput{{Format: "jsonl", File: path}},
+	})
+	assert.NoError(t, execution.NewEngine(&config.Config{}, reporter).ExecuteScenarios(scenarios))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+
+	var types []reporting.EventType
+	var events []reporting.Event
+	for _, line := range lines {
+		var event reporting.Event
+		assert.NoError(t, json.Unmarshal([]byte(line), &event))
+		types = append(types, event.Type)
+		events = append(events, event)
+	}
+	assert.Equal(t, []reporting.EventType{
+		reporting.EventRunStarted, reporting.EventScenarioStarted, reporting.EventStepFinished,
+		reporting.EventStepFinished, reporting.EventScenarioFinished, reporting.EventRunFinished,
+	}, types)
+
+	response := events[2].Step.Response.(map[string]interface{})
+	assert.EqualValues(t, 200, response["status_code"])
+	assert.NotContains(t, response, "body")
+	assert.Empty(t, events[4].Result.Steps)
+	assert.Equal(t, 1, events[5].Report.Summary.Failed)
+	assert.Empty(t, events[5].Report.Scenarios)
+
+	// The in-memory report drops bodies too unless IncludeBody is set
+	stored := reporter.GetReport().Scenarios[0].Steps[0].Response.(map[string]interface{})
+	assert.NotContains(t, stored, "body_text")
+
+	reporter = reporting.NewReporter(reporting.ReportConfig{Format: "none", IncludeBody: true})
+	assert.NoError(t, execution.NewEngine(&config.Config{}, reporter).ExecuteScenarios(scenarios))
+	stored = reporter.GetReport().Scenarios[0].Steps[0].Response.(map[string]interface{})
+	assert.Contains(t, stored, "body_text")
+}
+
+func TestReportsShowStepDocsNextToFailures(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	scenarios := []*scenario.Scenario{{
+		Name: "Orders",
+		Steps: []scenario.Step{
+			{
+				Name:        "Create order",
+				Description: "Orders are created with status 201",
+				DocsURL:     "https://docs.example.com/orders#create",
+				HTTP:        &scenario.HTTPStep{URL: server.URL + "/json"},
+				Check:       map[string]interface{}{"status": 201},
+			},
+			{
+				Name:        "List orders",
+				Description: "Listing always works",
+				HTTP:        &scenario.HTTPStep{URL: server.URL + "/json"},
+			},
+		},
+	}}
+
+	dir := t.TempDir()
+	reporter := reporting.NewReporter(reporting.ReportConfig{
+		Format: "none",
+		Outputs: []reporting.ReportOutput{
+			{Format: "html", File: filepath.Join(dir, "report.html")},
+			{Format: "markdown", File: filepath.Join(dir, "report.md")},
+		},
+	})
+	assert.NoError(t, execution.NewEngine(&config.Config{}, reporter).ExecuteScenarios(scenarios))
+
+	html, err := os.ReadFile(filepath.Join(dir, "report.html"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(html), `<div class="description">Orders are created with status 201</div>`)
+	assert.Contains(t, string(html), `<a href="https://docs.example.com/orders#create">Documentation</a>`)
+	assert.NotContains(t, string(html), "Listing always works")
+
+	markdown, err := os.ReadFile(filepath.Join(dir, "report.md"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(markdown), "  - Orders are created with status 201\n  - Docs: <https://docs.example.com/orders#create>")
+	assert.NotContains(t, string(markdown), "Listing always works")
+}
+
+func TestReportCountsClientTraffic(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("pong"))
+	}))
+	defer server.Close()
+
+	sc := &scenario.Scenario{Name: "Traffic"}
+	for i := 0; i < 3; i++ {
+		sc.Steps = append(sc.Steps, scenario.Step{
+			Name: "Ping",
+			HTTP: &scenario.HTTPStep{Method: "POST", URL: server.URL, Body: "ping"},
+		})
+	}
+
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "json", OutputFile: os.DevNull})
+	engine := execution.NewEngine(&config.Config{}, reporter)
+	require.NoError(t, engine.ExecuteScenarios([]*scenario.Scenario{sc}))
+
+	client := reporter.GetReport().Client
+	require.NotNil(t, client)
+	assert.EqualValues(t, 3, client.Requests)
+	assert.EqualValues(t, 12, client.BytesSent)
+	assert.EqualValues(t, 12, client.BytesReceived)
+	// Keep-alive serves every step after the first on the same connection
+	assert.EqualValues(t, 1, client.NewConnections)
+	assert.EqualValues(t, 2, client.ReusedConnections)
+	assert.InDelta(t, 2.0/3, client.ReuseRate, 0.001)
+}
+
+// blockingSink holds every scenario result until released, like a webhook that hangs
+type blockingSink struct {
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *blockingSink) ScenarioCompleted(reporting.ScenarioResult) error {
+	s.entered <- struct{}{}
+	<-s.release
+	return nil
+}
+
+func (s *blockingSink) RunCompleted(*reporting.Report) error { return nil }
+
+func TestReporterDeliversOutsideItsLock(t *testing.T) {
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "none"})
+	sink := &blockingSink{entered: make(chan struct{}), release: make(chan struct{})}
+	reporter.AddSink(sink)
+
+	// Handlers may call back into the reporter
+	var mu sync.Mutex
+	var seen []int
+	reporter.Subscribe(func(event reporting.Event) {
+		if event.Type == reporting.EventStepFinished {
+			total := len(reporter.GetReport().Scenarios)
+			mu.Lock()
+			seen = append(seen, total)
+			mu.Unlock()
+		}
+	})
+
+	go reporter.AddScenarioResult(reporting.ScenarioResult{Scenario: &scenario.Scenario{Name: "Slow webhook"}, Status: "passed"})
+	<-sink.entered
+
+	// A step of another scenario is not held up by the hanging sink
+	emitted := make(chan struct{})
+	go func() {
+		reporter.Emit(reporting.Event{Type: reporting.EventStepFinished, Step: &reporting.StepResult{Step: &scenario.Step{Name: "Fast"}, Status: "passed"}})
+		close(emitted)
+	}()
+	select {
+	case <-emitted:
+	case <-time.After(5 * time.Second):
+		t.Fatal("step event blocked by a slow sink")
+	}
+
+	close(sink.release)
+	assert.NoError(t, reporter.GenerateReport())
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []int{1}, seen)
+}
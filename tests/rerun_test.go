@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/execution"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func stepNames(steps []scenario.Step) []string {
+	names := make([]string, len(steps))
+	for i, step := range steps {
+		names[i] = step.Name
+	}
+	return names
+}
+
+func TestFilterFailedKeepsDependencies(t *testing.T) {
+	scenarios := []*scenario.Scenario{
+		{
+			Name:  "Orders",
+			Setup: []scenario.Step{{Name: "Login"}},
+			Steps: []scenario.Step{
+				{Name: "Create customer"},
+				{Name: "Create order", DependsOn: []string{"Create customer"}},
+				{Name: "List orders"},
+				{Name: "Cancel order", DependsOn: []string{"Create order"}},
+			},
+			Tests: map[string]*scenario.TestGroup{
+				"refunds": {Steps: []scenario.Step{{Name: "Refund"}}},
+			},
+		},
+		{Name: "Unrelated", Steps: []scenario.Step{{Name: "Ping"}}},
+		{Name: "Unknown steps", Steps: []scenario.Step{{Name: "Renamed"}}},
+	}
+
+	filtered := scenario.FilterFailed(scenarios, map[string][]string{
+		"Orders":        {"Cancel order"},
+		"Unknown steps": {"Old name"},
+	})
+	require.Len(t, filtered, 2)
+
+	orders := filtered[0]
+	assert.Equal(t, []string{"Login"}, stepNames(orders.Setup))
+	assert.Equal(t, []string{"Create customer", "Create order", "Cancel order"}, stepNames(orders.Steps))
+	assert.Empty(t, orders.Tests)
+	assert.Len(t, scenarios[0].Steps, 4, "the loaded scenario is not modified")
+
+	assert.Equal(t, []string{"Renamed"}, stepNames(filtered[1].Steps))
+}
+
+func TestReportFailedStepsRoundTrip(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	reportPath := filepath.Join(t.TempDir(), "report.json")
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "json", OutputFile: reportPath})
+	engine := execution.NewEngine(&config.Config{}, reporter)
+
+	scenarios := []*scenario.Scenario{
+		{
+			Name:       "Mixed",
+			SourceFile: "mixed.yaml",
+			Data: map[string]scenario.DataSource{"items": {Type: "inline", Data: []interface{}{
+				map[string]interface{}{"path": "json"},
+				map[string]interface{}{"path": "missing"},
+			}}},
+			Steps: []scenario.Step{
+				{Name: "Passes", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}, Check: map[string]interface{}{"status": 200}},
+				{
+					Name:       "Fails on one item",
+					HTTP:       &scenario.HTTPStep{URL: server.URL + "/{{item.path}}"},
+					Check:      map[string]interface{}{"status": 200},
+					DataDriven: &scenario.DataDrivenConfig{Source: "items", Variable: "item"},
+				},
+			},
+		},
+		{Name: "Passing", SourceFile: "passing.yaml", Steps: []scenario.Step{
+			{Name: "Passes", HTTP: &scenario.HTTPStep{URL: server.URL + "/json"}, Check: map[string]interface{}{"status": 200}},
+		}},
+	}
+	require.NoError(t, engine.ExecuteScenarios(scenarios))
+
+	report, err := reporting.LoadReport(reportPath)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"Mixed": {"Fails on one item"}}, report.FailedSteps())
+	assert.Equal(t, []string{"mixed.yaml"}, report.SourceFiles())
+
+	_, err = reporting.LoadReport(filepath.Join(t.TempDir(), "missing.json"))
+	assert.Error(t, err)
+}
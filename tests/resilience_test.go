@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/execution"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryRecoversFromTransientFailures(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Retry",
+		Steps: []scenario.Step{{
+			Name:  "Eventually available",
+			HTTP:  &scenario.HTTPStep{Method: "GET", URL: server.URL},
+			Retry: &scenario.RetryConfig{Count: 3, Delay: time.Millisecond, Backoff: "exponential"},
+			Check: map[string]interface{}{"status": 200, "retries": 2},
+			Assertions: []scenario.Assertion{
+				{Type: "attempts", Operator: "lte", Value: 3},
+			},
+		}},
+	}
+
+	report := runTestScenario(t, sc)
+	step := report.Scenarios[0].Steps[0]
+	assert.Equal(t, "passed", step.Status)
+	assert.Equal(t, 3, step.Attempts)
+	assert.Len(t, step.Assertions, 3)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&requests))
+}
+
+func TestInjectedFaultsExerciseRetries(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Faults",
+		Steps: []scenario.Step{
+			{
+				Name:   "Always unavailable",
+				HTTP:   &scenario.HTTPStep{Method: "GET", URL: server.URL + "/json"},
+				Faults: []scenario.Fault{{Percent: 100, Status: 503, Body: `{"error": "unavailable"}`}},
+				Retry:  &scenario.RetryConfig{Count: 2, Delay: time.Millisecond},
+				Check:  map[string]interface{}{"status": 200, "faults": 3},
+			},
+			{
+				Name:   "Dropped",
+				HTTP:   &scenario.HTTPStep{Method: "GET", URL: server.URL + "/json"},
+				Faults: []scenario.Fault{{Percent: 100, Drop: true}},
+			},
+			{
+				Name:       "Slow",
+				HTTP:       &scenario.HTTPStep{Method: "GET", URL: server.URL + "/json"},
+				Faults:     []scenario.Fault{{Percent: 100, Delay: 30 * time.Millisecond}},
+				Assertions: []scenario.Assertion{{Type: "response_time", Operator: "gte", Value: 30}},
+			},
+		},
+	}
+	require.NoError(t, sc.Validate())
+
+	report := runTestScenario(t, sc)
+	steps := report.Scenarios[0].Steps
+	require.Len(t, steps, 3)
+
+	assert.Equal(t, "failed", steps[0].Status)
+	assert.Equal(t, 3, steps[0].Attempts)
+	assert.Equal(t, []string{"attempt 1: status 503", "attempt 2: status 503", "attempt 3: status 503"}, steps[0].Faults)
+	for _, result := range steps[0].Assertions {
+		if result.Assertion.Type == "faults" {
+			assert.True(t, result.Passed)
+		}
+	}
+
+	assert.Equal(t, "failed", steps[1].Status)
+	assert.Contains(t, steps[1].Error, "connection dropped (injected fault)")
+
+	assert.Equal(t, "passed", steps[2].Status, steps[2].Error)
+	assert.Equal(t, []string{"attempt 1: delay 30ms"}, steps[2].Faults)
+}
+
+func TestFaultValidation(t *testing.T) {
+	sc := &scenario.Scenario{
+		Name: "Invalid faults",
+		Steps: []scenario.Step{{
+			Name:   "Too many",
+			HTTP:   &scenario.HTTPStep{URL: "/json"},
+			Faults: []scenario.Fault{{Percent: 80, Drop: true}, {Percent: 40, Status: 500}},
+		}},
+	}
+	err := sc.Validate()
+	require.Error(t, err)
+	assert.True(t, strings.Contains(err.Error(), "more than 100%"), err.Error())
+}
+
+func runWithDefaults(t *testing.T, defaults config.DefaultConfig, sc *scenario.Scenario) []reporting.StepResult {
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "json", OutputFile: os.DevNull})
+	engine := execution.NewEngine(&config.Config{Defaults: defaults}, reporter)
+	require.NoError(t, engine.ExecuteScenarios([]*scenario.Scenario{sc}))
+	return reporter.GetReport().Scenarios[0].Steps
+}
+
+func TestNetworkRetriesResendIdempotentRequests(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The first two requests lose their connection before any response
+		if atomic.AddInt32(&requests, 1) <= 2 {
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Network retries",
+		Steps: []scenario.Step{
+			{Name: "Get", HTTP: &scenario.HTTPStep{URL: server.URL}, Check: map[string]interface{}{"status": 200}},
+		},
+	}
+	steps := runWithDefaults(t, config.DefaultConfig{NetworkRetries: 2}, sc)
+	assert.Equal(t, "passed", steps[0].Status, steps[0].Error)
+	assert.EqualValues(t, 3, atomic.LoadInt32(&requests))
+
+	// POST is not idempotent, so its lost connection fails the step
+	atomic.StoreInt32(&requests, 0)
+	sc.Steps[0].HTTP.Method = "POST"
+	steps = runWithDefaults(t, config.DefaultConfig{NetworkRetries: 2}, sc)
+	assert.Equal(t, "failed", steps[0].Status)
+	assert.EqualValues(t, 1, atomic.LoadInt32(&requests))
+}
+
+func TestCircuitBreakerShortCircuitsUnreachableHost(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	url := "http://" + listener.Addr().String()
+	listener.Close()
+
+	sc := &scenario.Scenario{Name: "Circuit breaker"}
+	for _, name := range []string{"First", "Second", "Third", "Fourth"} {
+		sc.Steps = append(sc.Steps, scenario.Step{Name: name, HTTP: &scenario.HTTPStep{URL: url}})
+	}
+	steps := runWithDefaults(t, config.DefaultConfig{CircuitBreaker: config.CircuitBreakerConfig{Failures: 2}}, sc)
+
+	require.Len(t, steps, 4)
+	for i, step := range steps {
+		assert.Equal(t, "failed", step.Status)
+		if i < 2 {
+			assert.NotContains(t, step.Error, "host unavailable")
+		} else {
+			assert.Contains(t, step.Error, "host unavailable: "+listener.Addr().String())
+		}
+	}
+}
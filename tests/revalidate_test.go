@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevalidateSendsEachValidator(t *testing.T) {
+	modified := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	var conditional []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		switch r.URL.Path {
+		case "/cached":
+			w.Header().Set("ETag", `"v1"`)
+			http.ServeContent(w, r, "", modified, strings.NewReader(`{"name":"widget"}`))
+			if match := r.Header.Get("If-None-Match"); match != "" {
+				conditional = append(conditional, "If-None-Match: "+match)
+			}
+			if since := r.Header.Get("If-Modified-Since"); since != "" {
+				conditional = append(conditional, "If-Modified-Since: "+since)
+			}
+		case "/etag-only":
+			// Ignores If-Modified-Since although it sends Last-Modified
+			w.Header().Set("ETag", `"v1"`)
+			w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+			if r.Header.Get("If-None-Match") == `"v1"` {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Write([]byte("body"))
+		case "/uncached":
+			w.Write([]byte("body"))
+		}
+	}))
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Revalidation",
+		Steps: []scenario.Step{
+			{
+				Name:    "Cached",
+				HTTP:    &scenario.HTTPStep{URL: server.URL + "/cached", Revalidate: true},
+				Check:   map[string]interface{}{"status": 200},
+				Capture: map[string]scenario.Capture{"name": {JSONPath: "name"}},
+			},
+			{Name: "ETag only", HTTP: &scenario.HTTPStep{URL: server.URL + "/etag-only", Revalidate: true}},
+			{Name: "Uncached", HTTP: &scenario.HTTPStep{URL: server.URL + "/uncached", Revalidate: true}},
+		},
+	}
+
+	report := runTestScenario(t, sc)
+	steps := report.Scenarios[0].Steps
+	assert.Equal(t, "passed", steps[0].Status, steps[0].Error)
+	assert.Equal(t, "widget", steps[0].Variables["name"])
+	assert.Equal(t, []string{`If-None-Match: "v1"`, "If-Modified-Since: " + modified.Format(http.TimeFormat)}, conditional)
+
+	assert.Equal(t, "failed", steps[1].Status)
+	assert.Contains(t, steps[1].Error, "revalidation with If-Modified-Since")
+	assert.Contains(t, steps[1].Error, "returned 200, expected 304")
+
+	assert.Equal(t, "failed", steps[2].Status)
+	assert.Contains(t, steps[2].Error, "neither")
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/nulln0ne/fuego/pkg/scenariobuilder"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScenarioBuilderRoundTrip(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	builder := scenariobuilder.NewScenario().
+		Name("Built in Go").
+		Label("owner", "api-team").
+		Variable("base", server.URL).
+		Step("Fetch user").HTTP("GET", "{{base}}/json").
+		Header("X-Api-Version", scenario.Unset).
+		Check(
+			scenariobuilder.Status(200),
+			scenariobuilder.Header("X-Test-Header", "fuego-test"),
+			scenariobuilder.JSONPath("$.user.name", "eq", "fuego"),
+			scenariobuilder.BodyContains(`"status": "ok"`),
+		).
+		Capture("user_id", scenariobuilder.FromJSON("$.user.id")).
+		HTTP("get", "{{base}}/user/{{user_id}}").
+		Check(scenariobuilder.Status(200))
+
+	dir := t.TempDir()
+	for _, name := range []string{"built.yaml", "built.json"} {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, builder.WriteFile(path))
+
+		loaded, err := scenario.LoadScenario(path)
+		if !assert.NoError(t, err, name) {
+			continue
+		}
+		assert.Equal(t, "Built in Go", loaded.Name)
+		assert.Equal(t, "GET {{base}}/user/{{user_id}}", loaded.Steps[1].Name)
+		assert.Equal(t, scenario.Unset, loaded.Steps[0].HTTP.Headers["X-Api-Version"])
+
+		report := runTestScenario(t, loaded)
+		if assert.Len(t, report.Scenarios, 1) {
+			assert.Equal(t, "passed", report.Scenarios[0].Status, report.Failures())
+		}
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "built.yaml"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "X-Api-Version: null")
+}
+
+func TestScenarioBuilderErrors(t *testing.T) {
+	_, err := scenariobuilder.NewScenario().Name("No step").Check(scenariobuilder.Status(200)).Build()
+	assert.EqualError(t, err, "Check called before any step was added")
+
+	_, err = scenariobuilder.NewScenario().Name("No request").Step("Variables").Header("A", "b").Build()
+	assert.EqualError(t, err, `step "Variables": Header called before HTTP`)
+
+	_, err = scenariobuilder.NewScenario().HTTP("GET", "/health").Build()
+	assert.EqualError(t, err, "scenario name is required")
+}
+
+func TestScenarioBuilderAssertionsAreEvaluated(t *testing.T) {
+	server := setupTestServer()
+	defer server.Close()
+
+	sc, err := scenariobuilder.NewScenario().
+		Name("Wrong expectations").
+		HTTP("GET", server.URL+"/json").
+		Check(
+			scenariobuilder.Status(200),
+			scenariobuilder.JSONPath("$.user.name", "eq", "someone else"),
+			scenariobuilder.Header("X-Test-Header", "fuego-test"),
+		).
+		Build()
+	assert.NoError(t, err)
+
+	report := runTestScenario(t, sc)
+	step := report.Scenarios[0].Steps[0]
+	assert.Equal(t, "failed", step.Status)
+	assert.Len(t, step.Assertions, 3)
+}
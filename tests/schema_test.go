@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/xeipuuv/gojsonschema"
+	"gopkg.in/yaml.v3"
+)
+
+func TestScenarioSchemaValidatesExamples(t *testing.T) {
+	schemaDoc := schema.Scenario()
+	schemaLoader := gojsonschema.NewGoLoader(schemaDoc)
+
+	files, err := filepath.Glob("../examples/*.yaml")
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		if strings.HasPrefix(filepath.Base(file), ".") {
+			// Dotfiles in examples are configuration, not scenarios
+			continue
+		}
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			content, err := os.ReadFile(file)
+			require.NoError(t, err)
+
+			var doc map[string]interface{}
+			require.NoError(t, yaml.Unmarshal(content, &doc))
+
+			result, err := gojsonschema.Validate(schemaLoader, gojsonschema.NewGoLoader(doc))
+			require.NoError(t, err)
+			assert.True(t, result.Valid(), "schema errors: %v", result.Errors())
+		})
+	}
+}
+
+func TestScenarioSchemaRejectsUnknownKeys(t *testing.T) {
+	schemaLoader := gojsonschema.NewGoLoader(schema.Scenario())
+
+	doc := map[string]interface{}{
+		"name": "Typo scenario",
+		"steps": []interface{}{
+			map[string]interface{}{
+				"name":  "step",
+				"cheks": map[string]interface{}{"status": 200},
+			},
+		},
+	}
+
+	result, err := gojsonschema.Validate(schemaLoader, gojsonschema.NewGoLoader(doc))
+	require.NoError(t, err)
+	assert.False(t, result.Valid())
+}
+
+func TestScenarioSchemaAcceptsNullParams(t *testing.T) {
+	schemaLoader := gojsonschema.NewGoLoader(schema.Scenario())
+
+	var doc map[string]interface{}
+	require.NoError(t, yaml.Unmarshal([]byte(`
+name: Legacy endpoint
+config:
+  http:
+    headers:
+      X-Tenant: acme
+steps:
+  - name: status
+    http:
+      url: /v1/status
+      headers:
+        X-Api-Version: null
+      query:
+        api_key: null
+`), &doc))
+
+	result, err := gojsonschema.Validate(schemaLoader, gojsonschema.NewGoLoader(doc))
+	require.NoError(t, err)
+	assert.True(t, result.Valid(), "schema errors: %v", result.Errors())
+
+	// Other values are still rejected
+	doc["steps"].([]interface{})[0].(map[string]interface{})["http"].(map[string]interface{})["headers"] = map[string]interface{}{"X-Api-Version": 2}
+	result, err = gojsonschema.Validate(schemaLoader, gojsonschema.NewGoLoader(doc))
+	require.NoError(t, err)
+	assert.False(t, result.Valid())
+}
+
+func TestConfigSchemaValidatesExampleConfig(t *testing.T) {
+	content, err := os.ReadFile("../examples/.fuego.yaml")
+	require.NoError(t, err)
+
+	var doc map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(content, &doc))
+
+	result, err := gojsonschema.Validate(gojsonschema.NewGoLoader(schema.Config()), gojsonschema.NewGoLoader(doc))
+	require.NoError(t, err)
+	assert.True(t, result.Valid(), "schema errors: %v", result.Errors())
+}
+
+func TestConfigSchemaHasTopLevelSections(t *testing.T) {
+	doc := schema.Config()
+
+	properties, ok := doc["properties"].(map[string]interface{})
+	require.True(t, ok)
+	for _, key := range []string{"global", "defaults", "environments", "secrets", "plugins"} {
+		assert.Contains(t, properties, key)
+	}
+}
@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/server"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeStreamsRunEvents(t *testing.T) {
+	target := setupTestServer()
+	defer target.Close()
+
+	root := t.TempDir()
+	content := `name: Served
+steps:
+  - name: Get JSON
+    http:
+      url: ` + target.URL + `/json
+    check:
+      status: 200
+`
+	require.NoError(t, os.WriteFile(filepath.Join(root, "served.yaml"), []byte(content), 0644))
+
+	api := httptest.NewServer(server.New(server.Options{Root: root, Token: "secret"}).Handler())
+	defer api.Close()
+
+	request := func(method, path, body string) *http.Response {
+		req, err := http.NewRequest(method, api.URL+path, strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := request(http.MethodPost, "/api/v1/runs", `{"paths": ["served.yaml"]}`)
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+	var submitted server.Run
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&submitted))
+	resp.Body.Close()
+	require.NotEmpty(t, submitted.ID)
+
+	// The stream replays events emitted before it was opened and ends with the run
+	resp = request(http.MethodGet, "/api/v1/runs/"+submitted.ID+"/events", "")
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+	var events []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		if name, ok := strings.CutPrefix(scanner.Text(), "event: "); ok {
+			events = append(events, name)
+		}
+	}
+	resp.Body.Close()
+	assert.Equal(t, []string{"run_started", "scenario_started", "step_finished", "scenario_finished", "run_finished", "end"}, events)
+
+	resp = request(http.MethodGet, "/api/v1/runs/"+submitted.ID, "")
+	var finished server.Run
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&finished))
+	resp.Body.Close()
+	assert.Equal(t, server.StatusPassed, finished.Status)
+	require.NotNil(t, finished.Report)
+	assert.Len(t, finished.Report.Scenarios, 1)
+	assert.Equal(t, 1, finished.Summary.Passed)
+}
+
+func TestServeRejectsInvalidRequests(t *testing.T) {
+	api := httptest.NewServer(server.New(server.Options{Root: t.TempDir(), Token: "secret"}).Handler())
+	defer api.Close()
+
+	resp, err := http.Post(api.URL+"/api/v1/runs", "application/json", strings.NewReader(`{"paths": ["a.yaml"]}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	for _, body := range []string{`{"paths": ["../secrets.yaml"]}`, `{"paths": ["/etc/passwd"]}`, `{}`, `{"path": "a.yaml"}`} {
+		req, _ := http.NewRequest(http.MethodPost, api.URL+"/api/v1/runs", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, body)
+	}
+
+	resp, err = http.Get(api.URL + "/healthz")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestServeRejectsSymlinksOutOfRoot(t *testing.T) {
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "secret.yaml"), []byte("name: Secret\nsteps: []\n"), 0644))
+	root := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(root, "linked")); err != nil {
+		t.Skipf("symlinks are not available: %v", err)
+	}
+	require.NoError(t, os.Symlink(filepath.Join(outside, "secret.yaml"), filepath.Join(root, "secret.yaml")))
+
+	api := httptest.NewServer(server.New(server.Options{Root: root}).Handler())
+	defer api.Close()
+
+	for _, body := range []string{`{"paths": ["linked"]}`, `{"paths": ["linked/secret.yaml"]}`, `{"paths": ["secret.yaml"]}`, `{"paths": ["missing.yaml"]}`} {
+		resp, err := http.Post(api.URL+"/api/v1/runs", "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusBadRequest, resp.StatusCode, body)
+	}
+}
@@ -0,0 +1,103 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/config"
+	"github.com/nulln0ne/fuego/pkg/execution"
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// echoServer reports its name, the request path and query, and the Authorization and X-Tenant
+// headers in response headers
+func echoServer(name string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Served-By", name)
+		w.Header().Set("X-Path", r.URL.Path+"?"+r.URL.RawQuery)
+		w.Header().Set("X-Auth", r.Header.Get("Authorization"))
+		w.Header().Set("X-Tenant", r.Header.Get("X-Tenant"))
+	}))
+}
+
+func TestStepsAddressServices(t *testing.T) {
+	billing, users, staging := echoServer("billing"), echoServer("users"), echoServer("staging-users")
+	defer billing.Close()
+	defer users.Close()
+	defer staging.Close()
+
+	cfg := &config.Config{
+		Services: map[string]config.ServiceConfig{
+			"billing": {
+				BaseURL: billing.URL + "/api/",
+				Headers: map[string]string{"X-Tenant": "acme"},
+				Query:   map[string]string{"v": "2"},
+				Auth:    &config.ServiceAuth{Type: "bearer", Token: "billing-token"},
+			},
+			"users": {BaseURL: users.URL},
+		},
+		Env: map[string]config.EnvConfig{
+			"staging": {Services: map[string]config.ServiceConfig{"users": {BaseURL: staging.URL}}},
+		},
+	}
+
+	header := func(name, value string) scenario.Assertion {
+		return scenario.Assertion{Type: "header", Field: name, Operator: "eq", Value: value}
+	}
+	sc := &scenario.Scenario{
+		Name: "Services",
+		Steps: []scenario.Step{
+			{
+				Name: "Invoice",
+				HTTP: &scenario.HTTPStep{Service: "billing", URL: "/invoices/{{invoice}}"},
+				Assertions: []scenario.Assertion{
+					header("X-Served-By", "billing"),
+					header("X-Path", "/api/invoices/7?v=2"),
+					header("X-Auth", "Bearer billing-token"),
+					header("X-Tenant", "acme"),
+				},
+			},
+			{
+				Name: "Step headers win",
+				HTTP: &scenario.HTTPStep{Service: "billing", URL: "invoices", Headers: scenario.Params{"X-Tenant": "other"}},
+				Assertions: []scenario.Assertion{
+					header("X-Tenant", "other"),
+				},
+			},
+			{
+				Name:       "User",
+				HTTP:       &scenario.HTTPStep{Service: "users", URL: "/users/1"},
+				Assertions: []scenario.Assertion{header("X-Served-By", "staging-users")},
+			},
+			{
+				Name: "Unknown",
+				HTTP: &scenario.HTTPStep{Service: "shipping", URL: "/parcels"},
+			},
+		},
+		Variables: map[string]interface{}{"invoice": 7},
+	}
+	require.NoError(t, sc.Validate())
+
+	reporter := reporting.NewReporter(reporting.ReportConfig{Format: "json", OutputFile: os.DevNull})
+	engine := execution.NewEngine(cfg.MergeEnvironment("staging"), reporter)
+	require.NoError(t, engine.ExecuteScenarios([]*scenario.Scenario{sc}))
+
+	steps := reporter.GetReport().Scenarios[0].Steps
+	require.Len(t, steps, 4)
+	for _, step := range steps[:3] {
+		assert.Equal(t, "passed", step.Status, step.Step.Name+": "+step.Error)
+		for _, result := range step.Assertions {
+			assert.True(t, result.Passed, step.Step.Name+": "+result.Message)
+		}
+	}
+	assert.Equal(t, "failed", steps[3].Status)
+	assert.Contains(t, steps[3].Error, "unknown service shipping")
+
+	// Merging an environment leaves the base config untouched
+	assert.Equal(t, users.URL, cfg.Services["users"].BaseURL)
+}
@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/reporting"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardsPartitionScenarios(t *testing.T) {
+	var scenarios []*scenario.Scenario
+	for i := 0; i < 11; i++ {
+		scenarios = append(scenarios, &scenario.Scenario{Name: fmt.Sprintf("Scenario %02d", i), SourceFile: fmt.Sprintf("s%02d.yaml", i)})
+	}
+
+	seen := make(map[string]int)
+	for index := 1; index <= 3; index++ {
+		shard := scenario.Shard{Index: index, Total: 3}.Select(scenarios, nil)
+		assert.InDelta(t, 11.0/3, len(shard), 1)
+		for i, sc := range shard {
+			seen[sc.Name]++
+			if i > 0 {
+				assert.Less(t, shard[i-1].Name, sc.Name, "scenarios keep their order")
+			}
+		}
+		assert.Equal(t, shard, scenario.Shard{Index: index, Total: 3}.Select(scenarios, nil), "the split is deterministic")
+	}
+	assert.Len(t, seen, 11)
+	for name, count := range seen {
+		assert.Equal(t, 1, count, name)
+	}
+}
+
+func TestShardsBalanceByDuration(t *testing.T) {
+	scenarios := []*scenario.Scenario{
+		{Name: "Slow"},
+		{Name: "Medium"},
+		{Name: "Fast 1"},
+		{Name: "Fast 2"},
+		{Name: "Unknown"},
+		{Name: "Regions", Matrix: scenario.Matrix{"region": {"eu", "us"}}},
+	}
+	durations := map[string]time.Duration{
+		"Slow":                60 * time.Second,
+		"Medium":              20 * time.Second,
+		"Fast 1":              5 * time.Second,
+		"Fast 2":              5 * time.Second,
+		"Regions [region=eu]": 20 * time.Second,
+		"Regions [region=us]": 20 * time.Second,
+	}
+
+	names := func(shard []*scenario.Scenario) []string {
+		var names []string
+		for _, sc := range shard {
+			names = append(names, sc.Name)
+		}
+		return names
+	}
+	// Regions takes 40s over its combinations and Unknown counts as the 26s average: 80s and 76s
+	assert.Equal(t, []string{"Slow", "Medium"}, names(scenario.Shard{Index: 1, Total: 2}.Select(scenarios, durations)))
+	assert.Equal(t, []string{"Fast 1", "Fast 2", "Unknown", "Regions"}, names(scenario.Shard{Index: 2, Total: 2}.Select(scenarios, durations)))
+}
+
+func TestParseShard(t *testing.T) {
+	shard, err := scenario.ParseShard("2/5")
+	require.NoError(t, err)
+	assert.Equal(t, scenario.Shard{Index: 2, Total: 5}, shard)
+
+	for _, spec := range []string{"2", "0/5", "6/5", "a/b", "1/0"} {
+		_, err := scenario.ParseShard(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestReportScenarioDurationsAverageRuns(t *testing.T) {
+	orders := &scenario.Scenario{Name: "Orders"}
+	report := &reporting.Report{Scenarios: []reporting.ScenarioResult{
+		{Scenario: orders, Run: 1, Status: "passed", Duration: 2 * time.Second},
+		{Scenario: orders, Run: 2, Status: "failed", Duration: 4 * time.Second},
+		{Scenario: &scenario.Scenario{Name: "Skipped"}, Status: "skipped"},
+	}}
+	assert.Equal(t, map[string]time.Duration{"Orders": 3 * time.Second}, report.ScenarioDurations())
+}
@@ -0,0 +1,31 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/snapshot"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotFilesOnWindows(t *testing.T) {
+	dir := t.TempDir()
+	store := snapshot.NewStore(dir, false)
+
+	// Device names cannot be file names on Windows
+	assert.Equal(t, filepath.Join(dir, "Users", "_CON.snap"), store.Path("Users", "CON"))
+	assert.Equal(t, filepath.Join(dir, "Users", "_nul.json.snap"), store.Path("Users", "nul.json"))
+	assert.Equal(t, filepath.Join(dir, "Users", "console.snap"), store.Path("Users", "console"))
+
+	// A snapshot checked out with \r\n line endings still matches
+	path := store.Path("Users", "get")
+	body := `{"id": 1, "name": "Ada"}`
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(strings.ReplaceAll(snapshot.Normalize(body, nil), "\n", "\r\n")), 0644))
+	matched, message, err := store.Match(path, body, nil)
+	require.NoError(t, err)
+	assert.True(t, matched, message)
+}
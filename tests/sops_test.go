@@ -0,0 +1,110 @@
+package tests
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/data"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Encrypted for the age key below, the way `sops --encrypt --age <recipient>` writes files
+const (
+	sopsAgeKey = `# public key: age1vs97qfklf7ckv7jw0vfkyhkn6etr9e9350pexapvpswras5km5cse5hj3q
+AGE-SECRET-KEY-1VE6K2EM0946X2UM594SKWEFDD9JX2MN5D968JTTNV43HYET5YYSS02WUDY
+`
+
+	sopsScenario = `name: Payments
+variables:
+    api_token: ENC[AES256_GCM,data:Nnu7BCdcnKd/jtun,iv:aXYtdmFyaWFibGVzOmFwaV90b2tlbjogICAgICAgICA=,tag:91W6klJ0xHmUsPHgEmoHbA==,type:str]
+    retries: ENC[AES256_GCM,data:tg==,iv:aXYtdmFyaWFibGVzOnJldHJpZXM6ICAgICAgICAgICA=,tag:q2PO5iu0N7yFwdvIxxdcEQ==,type:int]
+steps:
+    - name: Charge
+      http:
+        method: POST
+        url: /charges
+        headers:
+            Authorization: Bearer {{api_token}}
+sops:
+    age:
+        - recipient: age1vs97qfklf7ckv7jw0vfkyhkn6etr9e9350pexapvpswras5km5cse5hj3q
+          enc: |
+            -----BEGIN AGE ENCRYPTED FILE-----
+            YWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSA4UmFCeXU3MzMxUUdLNE5y
+            WUJ2ck1ISE9nR1RGRkFmMkFQbUwwYk0xOTNVCnlxMzM5VlNsWkdOREd2azVxOHpC
+            NnpQZzZtMGYzbGxCTVpMQVhvTG5qb1UKLS0tIHlkZm9HMjRMNDU0S3QwKzJEa1lh
+            MVozdnNQcWt4VG5hQlZOck84VnczZ0kKcGF5bG9hZC1ub25jZS0xNtzIcj4AvJN4
+            If5oUEYhgCOesH88aYJ3ilULbzIqj0Hh+QTWEefLcEOGorjZhqm8wA==
+            -----END AGE ENCRYPTED FILE-----
+    lastmodified: "2026-10-01T09:30:00Z"
+    mac: ENC[AES256_GCM,data:L+cdjU+p86bUJWCSgYdQDzLp3Tj2TnXYg5ByfqRFWCD6s3U6rWmHOXUdVtUj7O9g3xFhceEeo1FEwek5kfPTaF6fTiiC4s05qg9u3CXF0fpAa1BHEL0r/C2lXbj12QBBdToqMCtVYvqqtikFX/5Zc5Ce9LMBUaLeIctGNp+GW30=,iv:aXYtMjAyNi0xMC0wMVQwOTozMDowMFogICAgICAgICA=,tag:39els9bi4oINMyxknrIy0g==,type:str]
+    version: 3.9.0
+`
+
+	// cards.csv encrypted as a whole (sops --input-type binary)
+	sopsCards = `{
+	"data": "ENC[AES256_GCM,data:NVBRXNTIpeALRr+5X7tue/GIAdLlrFe8WRc8sPCTnNw=,iv:aXYtZGF0YTogICAgICAgICAgICAgICAgICAgICAgICA=,tag:UdlF95ihxdLjfYACsFc21g==,type:str]",
+	"sops": {
+		"age": [{"recipient": "age1vs97qfklf7ckv7jw0vfkyhkn6etr9e9350pexapvpswras5km5cse5hj3q", "enc": "-----BEGIN AGE ENCRYPTED FILE-----\nYWdlLWVuY3J5cHRpb24ub3JnL3YxCi0+IFgyNTUxOSA4UmFCeXU3MzMxUUdLNE5y\nWUJ2ck1ISE9nR1RGRkFmMkFQbUwwYk0xOTNVCnlxMzM5VlNsWkdOREd2azVxOHpC\nNnpQZzZtMGYzbGxCTVpMQVhvTG5qb1UKLS0tIHlkZm9HMjRMNDU0S3QwKzJEa1lh\nMVozdnNQcWt4VG5hQlZOck84VnczZ0kKcGF5bG9hZC1ub25jZS0xNtzIcj4AvJN4\nIf5oUEYhgCOesH88aYJ3ilULbzIqj0Hh+QTWEefLcEOGorjZhqm8wA==\n-----END AGE ENCRYPTED FILE-----\n"}],
+		"lastmodified": "2026-10-01T09:30:00Z",
+		"mac": "ENC[AES256_GCM,data:XJVp8E6pjtLXLG+V9vZQdEeTpUDyOHPWhex9fNZGLFb8vAlL3GmBSwplINFUlpgS0hkVBuId0lVAtu1KlPLXbFnuPFGD4ro13n4b2Vq30/w1GlA3Zc4piiLRKs31rnJBckhfRS4jZY/WwlpzWvUvc+HtgsYEWtXfVrwyNJ/3WAc=,iv:aXYtMjAyNi0xMC0wMVQwOTozMDowMFogICAgICAgICA=,tag:qQSjkdLxufFVf9qGA4kd+A==,type:str]",
+		"version": "3.9.0"
+	}
+}
+`
+)
+
+func TestEncryptedFiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("SOPS_AGE_KEY", "")
+	t.Setenv("SOPS_AGE_KEY_FILE", "")
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	scenarioPath := filepath.Join(dir, "payments.enc.yaml")
+	require.NoError(t, os.WriteFile(scenarioPath, []byte(sopsScenario), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cards.csv"), []byte(sopsCards), 0644))
+
+	_, err := scenario.LoadScenario(scenarioPath)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no age key")
+
+	// Keys come from the environment...
+	t.Setenv("SOPS_AGE_KEY", sopsAgeKey)
+	sc, err := scenario.LoadScenario(scenarioPath)
+	require.NoError(t, err)
+	assert.Equal(t, "Payments", sc.Name)
+	assert.Equal(t, "s3cr3t-token", sc.Variables["api_token"])
+	assert.Equal(t, 3, sc.Variables["retries"])
+	assert.Equal(t, "Bearer {{api_token}}", sc.Steps[0].HTTP.Headers["Authorization"])
+
+	// ...or from the key file of the config
+	t.Setenv("SOPS_AGE_KEY", "")
+	keyFile := filepath.Join(dir, "keys.txt")
+	require.NoError(t, os.WriteFile(keyFile, []byte(sopsAgeKey), 0600))
+	sc, err = scenario.LoadScenarioWithOptions(scenarioPath, scenario.LoadOptions{AgeKeyFile: keyFile})
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t-token", sc.Variables["api_token"])
+
+	loader := data.NewDataLoader(dir)
+	loader.SetAgeKeyFile(keyFile)
+	rows, err := loader.LoadData(data.DataSource{Type: "csv", Path: "cards.csv", CSV: &data.CSVOptions{Raw: true}})
+	require.NoError(t, err)
+	require.Len(t, rows, 1)
+	assert.Equal(t, "4111111111111111", rows[0]["card"])
+
+	// The key file applies only to the loads it is passed to
+	_, err = scenario.LoadScenario(scenarioPath)
+	assert.ErrorContains(t, err, "no age key")
+	_, err = data.NewDataLoader(dir).LoadData(data.DataSource{Type: "csv", Path: "cards.csv", CSV: &data.CSVOptions{Raw: true}})
+	assert.ErrorContains(t, err, "no age key")
+
+	// Values changed without sops fail the MAC check
+	tampered := strings.Replace(sopsScenario, "url: /charges", "url: /refunds", 1)
+	require.NoError(t, os.WriteFile(scenarioPath, []byte(tampered), 0644))
+	_, err = scenario.LoadScenarioWithOptions(scenarioPath, scenario.LoadOptions{AgeKeyFile: keyFile})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "MAC does not match")
+}
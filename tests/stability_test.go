@@ -0,0 +1,84 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStabilityComparesRepeatedCalls(t *testing.T) {
+	var flaky, counter, stale int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/stable":
+			fmt.Fprint(w, `{"config": {"version": "v7"}}`)
+		case "/flaky":
+			// Every third call hits a node with an old deployment
+			if atomic.AddInt64(&flaky, 1)%3 == 0 {
+				fmt.Fprint(w, `{"config": {"version": "v6"}}`)
+				return
+			}
+			fmt.Fprint(w, `{"config": {"version": "v7"}}`)
+		case "/counter":
+			fmt.Fprintf(w, `{"seq": %d, "at": "2026-10-01T12:00:0%dZ"}`, atomic.AddInt64(&counter, 1), atomic.LoadInt64(&counter))
+		case "/stale":
+			// A cache serves the second call from before the first
+			fmt.Fprintf(w, `{"seq": %d}`, map[int64]int{1: 5, 2: 4, 3: 6}[atomic.AddInt64(&stale, 1)])
+		}
+	}))
+	defer server.Close()
+
+	stability := func(calls int, path, mode string) *scenario.Stability {
+		return &scenario.Stability{Calls: calls, Path: path, Mode: mode}
+	}
+	sc := &scenario.Scenario{
+		Name: "Stability",
+		Steps: []scenario.Step{
+			{Name: "Stable", HTTP: &scenario.HTTPStep{URL: server.URL + "/stable", Stability: stability(3, "$.config.version", "")}},
+			{Name: "Flaky", HTTP: &scenario.HTTPStep{URL: server.URL + "/flaky", Stability: stability(6, "$.config.version", "identical")}},
+			{Name: "Counter", HTTP: &scenario.HTTPStep{URL: server.URL + "/counter", Stability: stability(4, "$.seq", "increasing")}},
+			{Name: "Timestamps", HTTP: &scenario.HTTPStep{URL: server.URL + "/counter", Stability: stability(3, "$.at", "non_decreasing")}},
+			{Name: "Stale", HTTP: &scenario.HTTPStep{URL: server.URL + "/stale", Stability: stability(3, "$.seq", "increasing")}},
+			{Name: "Missing", HTTP: &scenario.HTTPStep{URL: server.URL + "/stable", Stability: stability(2, "$.build", "")}},
+		},
+	}
+
+	report := runTestScenario(t, sc)
+	steps := report.Scenarios[0].Steps
+	require.Len(t, steps, 6)
+	assert.Equal(t, "passed", steps[0].Status, steps[0].Error)
+	assert.Equal(t, "failed", steps[1].Status)
+	assert.Equal(t, `stability of $.config.version: 6 calls returned 2 different values: "v7" (calls 1, 2, 4, 5), "v6" (calls 3, 6)`, steps[1].Error)
+	assert.Equal(t, "passed", steps[2].Status, steps[2].Error)
+	assert.Equal(t, "passed", steps[3].Status, steps[3].Error)
+	assert.Equal(t, "failed", steps[4].Status)
+	assert.Equal(t, "stability of $.seq: call 2 returned 4 after 5 from call 1, expected increasing values", steps[4].Error)
+	assert.Equal(t, "failed", steps[5].Status)
+	assert.Contains(t, steps[5].Error, "stability: call 1: $.build: key build not found")
+}
+
+func TestStabilityValidation(t *testing.T) {
+	dir := t.TempDir()
+	for name, stability := range map[string]string{
+		"calls must be at least 2": "{calls: 1, path: $.version}",
+		"path is required":         "{calls: 3}",
+		"unknown mode \"sorted\"":  "{calls: 3, path: $.version, mode: sorted}",
+	} {
+		path := filepath.Join(dir, "stability.yaml")
+		content := "name: Stability\nsteps:\n  - name: Get\n    http:\n      url: http://localhost/config\n      stability: " + stability + "\n"
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		_, err := scenario.LoadScenario(path)
+		if assert.Error(t, err, stability) {
+			assert.Contains(t, err.Error(), "stability: "+name)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/data"
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStepOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/users/")
+		if name == "broken" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"id": %d, "name": %q}`, len(name), name)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	newScenario := func(path string) *scenario.Scenario {
+		return &scenario.Scenario{
+			Name:      "Create users",
+			Variables: map[string]interface{}{"batch": "new-users"},
+			Data: map[string]scenario.DataSource{
+				"users": {Type: "inline", Data: []interface{}{
+					map[string]interface{}{"name": "ada"},
+					map[string]interface{}{"name": "broken"},
+					map[string]interface{}{"name": "grace"},
+				}},
+			},
+			Tests: map[string]*scenario.TestGroup{
+				"users": {
+					DataDriven:     &scenario.DataDrivenConfig{Source: "users", Variable: "user"},
+					ContinueOnFail: true,
+					Steps: []scenario.Step{{
+						Name:    "Create user",
+						HTTP:    &scenario.HTTPStep{Method: "POST", URL: server.URL + "/users/{{user.name}}"},
+						Check:   map[string]interface{}{"status": 201},
+						Capture: map[string]scenario.Capture{"user_id": {JSONPath: "id"}},
+						Output: &scenario.StepOutput{
+							Path:   path,
+							Fields: map[string]string{"id": "{{user_id}}", "name": "{{user.name}}", "status": "{{step.status_code}}", "latency": "{{step.duration_ms}}"},
+						},
+					}},
+				},
+			},
+		}
+	}
+
+	// Only passing iterations are written, and every run starts the file afresh
+	csvPath := filepath.Join(dir, "out", "{{batch}}.csv")
+	runTestScenario(t, newScenario(csvPath))
+	runTestScenario(t, newScenario(csvPath))
+	content, err := os.ReadFile(filepath.Join(dir, "out", "new-users.csv"))
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "id,latency,name,status", lines[0])
+	assert.True(t, strings.HasPrefix(lines[1], "3,"))
+	assert.True(t, strings.HasSuffix(lines[2], ",grace,201"))
+
+	// JSON Lines keep the types of single-template values and load again as a data source
+	jsonlPath := filepath.Join(dir, "users.jsonl")
+	runTestScenario(t, newScenario(jsonlPath))
+	rows, err := data.NewDataLoader(dir).LoadData(data.DataSource{Type: "jsonl", Path: "users.jsonl"})
+	require.NoError(t, err)
+	require.Len(t, rows, 2)
+	assert.Equal(t, "ada", rows[0]["name"])
+	assert.EqualValues(t, 5, rows[1]["id"])
+	assert.EqualValues(t, 201, rows[1]["status"])
+}
@@ -0,0 +1,92 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStreamAssertions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher := w.(http.Flusher)
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		time.Sleep(50 * time.Millisecond)
+		for i := 0; i < 3; i++ {
+			fmt.Fprintf(w, "{\"event\":%d}\n", i)
+			flusher.Flush()
+			time.Sleep(20 * time.Millisecond)
+		}
+		if r.URL.Path == "/endless" {
+			<-r.Context().Done()
+		}
+	}))
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Streaming",
+		Steps: []scenario.Step{
+			{
+				Name:  "Complete stream",
+				HTTP:  &scenario.HTTPStep{URL: server.URL, Stream: &scenario.Stream{KeepChunks: 2}},
+				Check: map[string]interface{}{"status": 200},
+				Assertions: []scenario.Assertion{
+					{Type: "stream", Field: "first_chunk", Operator: "gte", Value: 50},
+					{Type: "stream", Field: "duration", Operator: "gte", Value: 100},
+					{Type: "stream", Field: "chunk_count", Value: 3},
+					{Type: "stream", Field: "chunks", Operator: "length", Value: 2},
+					{Type: "stream", Field: "chunks.0", Value: "{\"event\":0}\n"},
+					{Type: "stream", Field: "chunks.1", Operator: "contains", Value: `"event":1`},
+					{Type: "stream", Field: "ended", Value: true},
+					{Type: "body", Operator: "contains", Value: `{"event":2}`},
+				},
+			},
+			{
+				Name: "Long poll",
+				HTTP: &scenario.HTTPStep{URL: server.URL + "/endless", Stream: &scenario.Stream{MaxDuration: 300 * time.Millisecond}},
+				Assertions: []scenario.Assertion{
+					{Type: "stream", Field: "chunk_count", Value: 3},
+					{Type: "stream", Field: "ended", Value: false},
+					{Type: "stream", Field: "duration", Operator: "lt", Value: 1000},
+				},
+			},
+			{
+				Name: "Until",
+				HTTP: &scenario.HTTPStep{URL: server.URL + "/endless", Stream: &scenario.Stream{Until: `"event":1`}},
+				Assertions: []scenario.Assertion{
+					{Type: "stream", Field: "chunk_count", Value: 2},
+					{Type: "stream", Field: "ended", Value: false},
+				},
+			},
+			{
+				Name:       "Not streamed",
+				HTTP:       &scenario.HTTPStep{URL: server.URL},
+				Assertions: []scenario.Assertion{{Type: "stream", Field: "chunk_count", Value: 3}},
+			},
+		},
+	}
+
+	steps := runTestScenario(t, sc).Scenarios[0].Steps
+	require.Len(t, steps, 4)
+	for _, step := range steps[:3] {
+		assert.Equal(t, "passed", step.Status, "%s: %s %+v", step.Step.Name, step.Error, step.Assertions)
+	}
+	assert.Equal(t, "failed", steps[3].Status)
+	assert.Contains(t, steps[3].Assertions[0].Message, "not read as a stream")
+}
+
+func TestStreamValidation(t *testing.T) {
+	sc := &scenario.Scenario{
+		Name: "Invalid stream",
+		Steps: []scenario.Step{{
+			Name: "Negative",
+			HTTP: &scenario.HTTPStep{URL: "/events", Stream: &scenario.Stream{MaxDuration: -time.Second}},
+		}},
+	}
+	assert.ErrorContains(t, sc.Validate(), "max_duration must not be negative")
+}
@@ -0,0 +1,77 @@
+package tests
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeScenario(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "scenario.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	return path
+}
+
+func TestStrictScenarioParsing(t *testing.T) {
+	t.Run("unknown fields", func(t *testing.T) {
+		path := writeScenario(t, `name: Users
+x-defaults: &defaults
+  method: GET
+tests:
+  users:
+    steps:
+      - name: Get user
+        http:
+          <<: *defaults
+          url: /users/1
+          metod: GET
+        cheks:
+          status: 200
+        capture:
+          id: {jsonpath: $.id, defualt: 0}
+`)
+		_, err := scenario.LoadScenario(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), path+`:11:11: unknown field "metod" in http, did you mean "method"?`)
+		assert.Contains(t, err.Error(), path+`:12:9: unknown field "cheks" in step, did you mean "check"?`)
+		assert.Contains(t, err.Error(), path+`:15:32: unknown field "defualt" in capture`)
+		assert.NotContains(t, err.Error(), "x-defaults")
+	})
+
+	t.Run("syntax and type errors", func(t *testing.T) {
+		_, err := scenario.LoadScenario(writeScenario(t, "name: Users\nsteps:\n  - name: [a\n"))
+		var fileErr *scenario.FileError
+		require.True(t, errors.As(err, &fileErr), err)
+		assert.Equal(t, 2, fileErr.Line)
+
+		_, err = scenario.LoadScenario(writeScenario(t, "name: Users\nsteps:\n  - name: a\n    timeout: soon\n"))
+		require.True(t, errors.As(err, &fileErr), err)
+		assert.Equal(t, 4, fileErr.Line)
+		assert.Contains(t, fileErr.Error(), "cannot unmarshal !!str `soon` into time.Duration")
+	})
+
+	t.Run("validation errors point at the step", func(t *testing.T) {
+		path := writeScenario(t, `name: Users
+tests:
+  users:
+    steps:
+      - name: List
+        http: {url: /users}
+      - name: Get user
+        http: {method: GET}
+`)
+		_, err := scenario.LoadScenario(path)
+		var fileErr *scenario.FileError
+		require.True(t, errors.As(err, &fileErr), err)
+		assert.Equal(t, path, fileErr.File)
+		assert.Equal(t, 7, fileErr.Line)
+		assert.Equal(t, 9, fileErr.Column)
+		assert.EqualError(t, err, path+":7:9: invalid scenario: test 'users': step 2 (Get user): HTTP step URL is required")
+	})
+}
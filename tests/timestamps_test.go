@@ -0,0 +1,78 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimestampAssertions(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skip("timezone database not available")
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		now := time.Now()
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"created_at":   now.In(berlin).Format(time.RFC3339Nano),
+			"updated_at":   now.Add(-2 * time.Second).UTC().Format("2006-01-02T15:04:05"),
+			"local_time":   now.In(berlin).Format("2006-01-02 15:04:05"),
+			"created_unix": now.Unix(),
+			"created_ms":   now.UnixMilli(),
+			"expires_at":   now.Add(time.Hour).UTC().Format(time.RFC3339),
+			"stale_at":     now.Add(-10 * time.Minute).Format(time.RFC3339),
+		})
+	}))
+	defer server.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Timestamps",
+		Steps: []scenario.Step{
+			{
+				Name:    "Fresh",
+				HTTP:    &scenario.HTTPStep{URL: server.URL},
+				Capture: map[string]scenario.Capture{"created": {JSONPath: "created_at"}},
+				Assertions: []scenario.Assertion{
+					{Type: "json_path", Field: "created_at", Operator: "within", Value: "5s"},
+					{Type: "json_path", Field: "updated_at", Operator: "within", Value: 5},
+					{Type: "json_path", Field: "local_time", Operator: "within", Value: map[string]interface{}{"max": "5s", "timezone": "Europe/Berlin"}},
+					{Type: "json_path", Field: "created_unix", Operator: "within", Value: "5s"},
+					{Type: "json_path", Field: "created_ms", Operator: "within", Value: "5s"},
+					{Type: "header", Field: "Date", Operator: "within", Value: "5s"},
+					{Type: "json_path", Field: "expires_at", Operator: "within", Value: map[string]interface{}{"max": "1h5s", "of": "{{created}}"}},
+					{Type: "json_path", Field: "created_at", Operator: "timezone", Value: "Europe/Berlin"},
+					{Type: "json_path", Field: "expires_at", Operator: "timezone", Value: "UTC"},
+				},
+			},
+			{
+				Name: "Stale",
+				HTTP: &scenario.HTTPStep{URL: server.URL},
+				Assertions: []scenario.Assertion{
+					{Type: "json_path", Field: "stale_at", Operator: "within", Value: "1m"},
+					{Type: "json_path", Field: "local_time", Operator: "within", Value: map[string]interface{}{"max": "5s", "timezone": "America/New_York"}},
+					{Type: "json_path", Field: "expires_at", Operator: "timezone", Value: "+02:00"},
+					{Type: "json_path", Field: "updated_at", Operator: "timezone", Value: "UTC"},
+					{Type: "json_path", Field: "created_at", Operator: "within", Value: "soon"},
+				},
+			},
+		},
+	}
+
+	steps := runTestScenario(t, sc).Scenarios[0].Steps
+	assert.Equal(t, "passed", steps[0].Status, "%+v", steps[0].Assertions)
+
+	assert.Equal(t, "failed", steps[1].Status)
+	for _, result := range steps[1].Assertions {
+		assert.False(t, result.Passed, result.Message)
+	}
+	assert.Contains(t, steps[1].Assertions[0].Message, "behind now, more than 1m0s")
+	assert.Contains(t, steps[1].Assertions[2].Message, "expected timestamp in timezone +02:00 (UTC+02:00) but got UTC+00:00")
+	assert.Contains(t, steps[1].Assertions[3].Message, "has no UTC offset")
+}
@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransportErrorAssertions(t *testing.T) {
+	// A listener that is closed again leaves a port nothing accepts connections on
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedURL := "http://" + listener.Addr().String()
+	listener.Close()
+
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(2 * time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer fast.Close()
+
+	sc := &scenario.Scenario{
+		Name: "Transport errors",
+		Steps: []scenario.Step{
+			{
+				Name:  "Port closed",
+				HTTP:  &scenario.HTTPStep{URL: closedURL},
+				Check: map[string]interface{}{"error_contains": "connection refused", "timeout": false},
+				Assertions: []scenario.Assertion{
+					{Type: "error", Field: "kind", Value: "connection_refused"},
+				},
+			},
+			{
+				Name:    "Timeout enforced",
+				HTTP:    &scenario.HTTPStep{URL: slow.URL},
+				Timeout: 100 * time.Millisecond,
+				Check:   map[string]interface{}{"timeout": true, "error": "timeout"},
+				Assertions: []scenario.Assertion{
+					{Type: "response_time", Operator: "lt", Value: 1500},
+				},
+			},
+			{
+				Name:  "Reachable",
+				HTTP:  &scenario.HTTPStep{URL: fast.URL},
+				Check: map[string]interface{}{"error": "connection_refused"},
+			},
+			{
+				Name:       "Wrong error",
+				HTTP:       &scenario.HTTPStep{URL: closedURL},
+				Assertions: []scenario.Assertion{{Type: "error", Operator: "contains", Value: "no such host"}},
+			},
+			{
+				Name: "Unexpected error",
+				HTTP: &scenario.HTTPStep{URL: closedURL},
+			},
+		},
+	}
+
+	steps := runTestScenario(t, sc).Scenarios[0].Steps
+	require.Len(t, steps, 5)
+	assert.Equal(t, "passed", steps[0].Status, "%+v", steps[0].Assertions)
+	assert.Equal(t, "passed", steps[1].Status, "%+v", steps[1].Assertions)
+
+	assert.Equal(t, "failed", steps[2].Status)
+	assert.Contains(t, steps[2].Assertions[0].Message, "expected a transport error but the request got a response with status 200")
+
+	assert.Equal(t, "failed", steps[3].Status)
+	assert.Contains(t, steps[3].Assertions[0].Message, "expected value to contain no such host")
+
+	assert.Equal(t, "failed", steps[4].Status)
+	assert.Contains(t, steps[4].Error, "connection refused")
+}
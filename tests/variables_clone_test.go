@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/nulln0ne/fuego/pkg/variables"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloneCopiesNestedValues(t *testing.T) {
+	ctx := variables.NewContext()
+	ctx.SetGlobal("account", map[string]interface{}{
+		"profile": map[string]interface{}{"region": "eu"},
+		"roles":   []interface{}{"reader"},
+	})
+	ctx.SetLocal("rows", []map[string]interface{}{{"id": 1}})
+	ctx.SetLocal("headers", map[string]string{"X-Tenant": "a"})
+	ctx.SetLocal("ids", []string{"a", "b"})
+
+	clone := ctx.Clone()
+	account, _ := clone.Get("account")
+	account.(map[string]interface{})["profile"].(map[string]interface{})["region"] = "us"
+	account.(map[string]interface{})["roles"].([]interface{})[0] = "admin"
+	rows, _ := clone.Get("rows")
+	rows.([]map[string]interface{})[0]["id"] = 2
+	headers, _ := clone.Get("headers")
+	headers.(map[string]string)["X-Tenant"] = "b"
+	ids, _ := clone.Get("ids")
+	ids.([]string)[0] = "z"
+
+	region, _ := ctx.GetNested("account.profile.region")
+	assert.Equal(t, "eu", region)
+	roles, _ := ctx.GetNested("account.roles")
+	assert.Equal(t, []interface{}{"reader"}, roles)
+	original, _ := ctx.Get("rows")
+	assert.Equal(t, []map[string]interface{}{{"id": 1}}, original)
+	original, _ = ctx.Get("headers")
+	assert.Equal(t, map[string]string{"X-Tenant": "a"}, original)
+	original, _ = ctx.Get("ids")
+	assert.Equal(t, []string{"a", "b"}, original)
+}
+
+func TestConcurrentClonesAreIsolated(t *testing.T) {
+	ctx := variables.NewContext()
+	ctx.SetLocal("cart", map[string]interface{}{"items": []interface{}{}})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			clone := ctx.Clone()
+			cart, _ := clone.Get("cart")
+			items := cart.(map[string]interface{})["items"].([]interface{})
+			cart.(map[string]interface{})["items"] = append(items, i)
+			count, _ := clone.GetNested("cart.items")
+			assert.Len(t, count, 1)
+		}(i)
+	}
+	wg.Wait()
+
+	items, _ := ctx.GetNested("cart.items")
+	assert.Empty(t, items)
+}
+
+func TestConcurrentDataDrivenGroups(t *testing.T) {
+	var mu sync.Mutex
+	seen := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		seen[r.URL.Path]++
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	group := func(prefix string) *scenario.TestGroup {
+		return &scenario.TestGroup{
+			DataDriven: &scenario.DataDrivenConfig{Source: "users", Variable: "user"},
+			Steps: []scenario.Step{{
+				Name:  "Get User",
+				HTTP:  &scenario.HTTPStep{Method: "GET", URL: server.URL + "/" + prefix + "/{{account.profile.region}}/{{user.address.city}}"},
+				Check: map[string]interface{}{"status": 200},
+			}},
+		}
+	}
+	sc := &scenario.Scenario{
+		Name:      "Concurrent Data",
+		Config:    &scenario.ScenarioConfig{Parallel: true},
+		Variables: map[string]interface{}{"account": map[string]interface{}{"profile": map[string]interface{}{"region": "eu"}}},
+		Data: map[string]scenario.DataSource{
+			"users": {Type: "inline", Data: []interface{}{
+				map[string]interface{}{"address": map[string]interface{}{"city": "berlin"}},
+				map[string]interface{}{"address": map[string]interface{}{"city": "paris"}},
+			}},
+		},
+		Tests: map[string]*scenario.TestGroup{"a": group("a"), "b": group("b"), "c": group("c")},
+	}
+
+	report := runTestScenario(t, sc)
+	assert.Equal(t, "passed", report.Scenarios[0].Status)
+	assert.Len(t, report.Scenarios[0].Steps, 6)
+	for _, prefix := range []string{"a", "b", "c"} {
+		assert.Equal(t, 1, seen["/"+prefix+"/eu/berlin"])
+		assert.Equal(t, 1, seen["/"+prefix+"/eu/paris"])
+	}
+}
+
+func TestParallelGroupsRunAtTheSameTime(t *testing.T) {
+	// Each request waits until all three groups sent theirs, which only happens when the groups
+	// really run concurrently
+	arrived := make(chan struct{}, 3)
+	all := make(chan struct{})
+	var once sync.Once
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		arrived <- struct{}{}
+		if len(arrived) == cap(arrived) {
+			once.Do(func() { close(all) })
+		}
+		select {
+		case <-all:
+		case <-time.After(5 * time.Second):
+			w.WriteHeader(http.StatusGatewayTimeout)
+		}
+	}))
+	defer server.Close()
+
+	group := func(name string) *scenario.TestGroup {
+		return &scenario.TestGroup{Steps: []scenario.Step{{
+			Name:  "Wait " + name,
+			HTTP:  &scenario.HTTPStep{URL: server.URL + "/" + name},
+			Check: map[string]interface{}{"status": 200},
+		}}}
+	}
+	sc := &scenario.Scenario{
+		Name:   "Parallel groups",
+		Config: &scenario.ScenarioConfig{Parallel: true},
+		Tests:  map[string]*scenario.TestGroup{"c": group("c"), "a": group("a"), "b": group("b")},
+	}
+
+	report := runTestScenario(t, sc)
+	result := report.Scenarios[0]
+	assert.Equal(t, "passed", result.Status, result.Error)
+	require.Len(t, result.Steps, 3)
+	// Results are merged in group name order
+	for i, name := range []string{"a", "b", "c"} {
+		assert.Equal(t, "Wait "+name, result.Steps[i].Step.Name)
+		assert.Equal(t, name, result.Steps[i].Group)
+	}
+}
@@ -0,0 +1,81 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/nulln0ne/fuego/pkg/scenario"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScenarioVerify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(60 * time.Millisecond)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"count": 3})
+	}))
+	defer server.Close()
+
+	steps := []scenario.Step{
+		{
+			Name:    "Create items",
+			HTTP:    &scenario.HTTPStep{Method: "POST", URL: server.URL + "/items"},
+			Capture: map[string]scenario.Capture{"created": {JSONPath: "count"}},
+		},
+		{
+			Name:    "Delete items",
+			HTTP:    &scenario.HTTPStep{Method: "DELETE", URL: server.URL + "/slow"},
+			Capture: map[string]scenario.Capture{"deleted": {JSONPath: "count"}},
+		},
+	}
+
+	sc := &scenario.Scenario{
+		Name:  "Invariants hold",
+		Steps: steps,
+		Verify: []scenario.Assertion{
+			{Type: "variable", Field: "created", Value: "{{deleted}}"},
+			{Type: "steps", Field: "failed", Value: 0},
+			{Type: "steps", Field: "max_duration", Operator: "lt", Value: 2000},
+			{Type: "steps", Field: "slowest", Value: "Delete items"},
+			{Type: "step", Field: "Delete items.status", Value: "passed"},
+			{Type: "step", Field: "Delete items.duration", Operator: "gte", Value: 60},
+		},
+	}
+	result := runTestScenario(t, sc).Scenarios[0]
+	require.Len(t, result.Steps, 3)
+	verify := result.Steps[2]
+	assert.Equal(t, "Verify", verify.Step.Name)
+	assert.Equal(t, "passed", verify.Status, "%+v", verify.Assertions)
+	assert.Len(t, verify.Assertions, 6)
+	assert.Equal(t, "passed", result.Status)
+
+	sc = &scenario.Scenario{
+		Name:  "Invariants broken",
+		Steps: steps,
+		Verify: []scenario.Assertion{
+			{Type: "steps", Field: "max_duration", Operator: "lt", Value: 10},
+			{Type: "step", Field: "Archive items.status", Value: "passed"},
+		},
+	}
+	result = runTestScenario(t, sc).Scenarios[0]
+	verify = result.Steps[2]
+	assert.Equal(t, "failed", verify.Status)
+	assert.Equal(t, "failed", result.Status)
+	assert.False(t, verify.Assertions[0].Passed)
+	assert.Contains(t, verify.Assertions[1].Message, `no step named "Archive items" ran`)
+}
+
+func TestScenarioVerifyValidation(t *testing.T) {
+	sc := &scenario.Scenario{
+		Name:   "Invalid verify",
+		Steps:  []scenario.Step{{Name: "Ping", HTTP: &scenario.HTTPStep{URL: "/ping"}}},
+		Verify: []scenario.Assertion{{Type: "status", Value: 200}},
+	}
+	assert.ErrorContains(t, sc.Validate(), `verify 1: type must be variable, steps or step, not "status"`)
+}
@@ -0,0 +1,102 @@
+package tests
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/nulln0ne/fuego/pkg/version"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionInfo(t *testing.T) {
+	info := version.Get()
+	assert.NotEmpty(t, info.Version)
+	assert.Equal(t, runtime.GOOS+"/"+runtime.GOARCH, info.Platform)
+
+	info = version.Info{Version: "v1.4.0", Commit: "1a2b3c4d5e6f", Date: "2026-10-01T09:30:00Z", GoVersion: "go1.24.6", Platform: "linux/amd64"}
+	assert.Equal(t, "fuego v1.4.0 (commit 1a2b3c4, built 2026-10-01T09:30:00Z, go1.24.6 linux/amd64)", info.String())
+
+	assert.True(t, version.IsNewer("v1.4.0", "v1.10.0"))
+	assert.True(t, version.IsNewer("1.4", "v1.4.1"))
+	assert.True(t, version.IsNewer("dev", "v0.1.0"))
+	assert.False(t, version.IsNewer("v1.4.0", "v1.4.0"))
+	assert.False(t, version.IsNewer("v1.4.0", "v1.3.9"))
+
+	assert.Equal(t, "fuego-windows-amd64.exe", version.AssetName("windows", "amd64"))
+	assert.Equal(t, "brew", version.PackageManager("/opt/homebrew/Cellar/fuego/1.4.0/bin/fuego"))
+	assert.Equal(t, "", version.PackageManager("/usr/local/bin/fuego"))
+}
+
+func TestSelfUpdate(t *testing.T) {
+	binary := []byte("#!/bin/sh\necho new fuego\n")
+	sum := sha256.Sum256(binary)
+	name := version.AssetName(runtime.GOOS, runtime.GOARCH)
+	checksums := hex.EncodeToString(sum[:]) + "  " + name + "\n"
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/acme/fuego/releases/latest", "/repos/acme/fuego/releases/tags/v2.0.0":
+			assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"tag_name": "v2.0.0",
+				"assets": []map[string]string{
+					{"name": name, "url": server.URL + "/assets/1"},
+					{"name": version.ChecksumsAsset, "url": server.URL + "/assets/2"},
+				},
+			})
+		case "/assets/1":
+			assert.Equal(t, "application/octet-stream", r.Header.Get("Accept"))
+			w.Write(binary)
+		case "/assets/2":
+			w.Write([]byte(checksums))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	updater := &version.Updater{APIURL: server.URL, Repository: "acme/fuego", Token: "token"}
+	release, err := updater.Release(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, "v2.0.0", release.Tag)
+
+	executable := filepath.Join(t.TempDir(), "fuego")
+	require.NoError(t, os.WriteFile(executable, []byte("old"), 0755))
+	require.NoError(t, updater.Install(context.Background(), release, executable))
+	content, err := os.ReadFile(executable)
+	require.NoError(t, err)
+	assert.Equal(t, binary, content)
+	entries, _ := os.ReadDir(filepath.Dir(executable))
+	assert.Len(t, entries, 1, "temporary and old binaries are removed")
+
+	// A download that does not match the checksums leaves the binary alone
+	checksums = "0000  " + name + "\n"
+	err = updater.Install(context.Background(), release, executable)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "checksum mismatch")
+	content, _ = os.ReadFile(executable)
+	assert.Equal(t, binary, content)
+
+	// Releases without checksums, or with checksums that cannot be read, are not installed
+	checksums = "<html>Not Found</html>\n"
+	err = updater.Install(context.Background(), release, executable)
+	assert.ErrorContains(t, err, "invalid checksums.txt")
+	unverified := &version.Release{Tag: release.Tag, Assets: release.Assets[:1]}
+	err = updater.Install(context.Background(), unverified, executable)
+	assert.ErrorContains(t, err, "has no checksums.txt")
+	content, _ = os.ReadFile(executable)
+	assert.Equal(t, binary, content)
+
+	_, err = updater.Release(context.Background(), "v9.9.9")
+	assert.Error(t, err)
+}